@@ -0,0 +1,227 @@
+// Package allow keeps a list of the queries an application runs, in
+// record mode every query compiled is added to the list and in enforce
+// mode only the queries already on the list are allowed
+package allow
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var ErrNotAllowed = errors.New("query not in allow list")
+
+// Mode is how a List treats queries that are not on it
+type Mode int
+
+const (
+	ModeRecord  Mode = iota // queries are added to the list
+	ModeEnforce             // queries are rejected
+)
+
+// Item is a query on the list
+type Item struct {
+	Hash  string `json:"hash"`
+	Name  string `json:"name,omitempty"` // operation name
+	Query string `json:"query"`
+	SQL   string `json:"sql,omitempty"` // statement the query compiled to
+
+	// Schema is the fingerprint of the schema the SQL was compiled for
+	Schema string `json:"schema,omitempty"`
+}
+
+// List is an allow list kept in a store, it is safe for concurrent use
+// when the store is
+type List struct {
+	store Store
+	mode  Mode
+	fp    func() string
+}
+
+// Option configures a List
+type Option func(*List)
+
+// WithFingerprint records the fingerprint of the schema with the SQL of
+// a query, the SQL of a query recorded for another schema is stale. It
+// is dropped by Get and replaced by the next Check
+//
+//	l := allow.New(store, allow.ModeEnforce, allow.WithFingerprint(s.Fingerprint))
+func WithFingerprint(fn func() string) Option {
+	return func(l *List) {
+		l.fp = fn
+	}
+}
+
+// New returns a list kept in the store
+func New(store Store, mode Mode, opts ...Option) *List {
+	l := &List{store: store, mode: mode}
+	for _, fn := range opts {
+		fn(l)
+	}
+	return l
+}
+
+// fingerprint returns the fingerprint of the current schema
+func (l *List) fingerprint() string {
+	if l.fp == nil {
+		return ""
+	}
+	return l.fp()
+}
+
+// Check allows an operation of a query document, in record mode the
+// query is added to the list with the SQL it compiled to and in enforce
+// mode ErrNotAllowed is returned unless the query is on the list
+func (l *List) Check(query []byte, opName, sql string) error {
+	h := Hash(query, opName)
+	fp := l.fingerprint()
+
+	it, ok, err := l.store.Get(h)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ok && (it.Schema == fp || sql == ""):
+		return nil
+	case ok:
+		it.SQL, it.Schema = sql, fp
+		return l.store.Put(it)
+	case l.mode == ModeEnforce:
+		return fmt.Errorf("%w: %s", ErrNotAllowed, h)
+	}
+	return l.store.Put(Item{Hash: h, Name: opName, Query: string(query), SQL: sql, Schema: fp})
+}
+
+// Get returns the query with a hash, it lets clients send the hash of
+// an allowed query instead of its text. The SQL of a query recorded for
+// another schema is left out
+func (l *List) Get(hash string) (Item, bool, error) {
+	it, ok, err := l.store.Get(hash)
+	if ok && it.Schema != l.fingerprint() {
+		it.SQL = ""
+	}
+	return it, ok, err
+}
+
+// Export writes the list as JSON sorted by hash so it can be reviewed
+// and diffed
+func (l *List) Export(w io.Writer) error {
+	items, err := l.store.List()
+	if err != nil {
+		return err
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Hash < items[j].Hash })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+// Import adds the queries of an exported list, the hash of each query
+// is checked against its text
+func (l *List) Import(r io.Reader) error {
+	var items []Item
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return fmt.Errorf("error reading allow list: %s", err)
+	}
+
+	for _, it := range items {
+		if h := Hash([]byte(it.Query), it.Name); h != it.Hash {
+			return fmt.Errorf("allow list item %s does not match its query", it.Hash)
+		}
+		if err := l.store.Put(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hash returns the hash of an operation of a query document, queries
+// that only differ in whitespace, commas or comments have the same hash
+func Hash(query []byte, opName string) string {
+	h := sha256.New()
+	h.Write([]byte(normalize(string(query))))
+	h.Write([]byte{0})
+	h.Write([]byte(opName))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// normalize returns the query with comments and commas removed and runs
+// of whitespace collapsed into a single space between names, strings
+// are kept as written
+func normalize(q string) string {
+	var sb strings.Builder
+	space := false
+
+	for i := 0; i < len(q); i++ {
+		ch := q[i]
+
+		switch {
+		case ch == '#':
+			for i < len(q) && q[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+
+		case ch == ' ', ch == '\t', ch == '\n', ch == '\r', ch == ',':
+			space = true
+			continue
+
+		case ch == '"':
+			end := stringEnd(q, i)
+			writeSpace(&sb, space, ch)
+			sb.WriteString(q[i:end])
+			i, space = end-1, false
+			continue
+		}
+
+		writeSpace(&sb, space, ch)
+		sb.WriteByte(ch)
+		space = false
+	}
+	return sb.String()
+}
+
+// writeSpace writes the space before a character when it separates two
+// names or values
+func writeSpace(sb *strings.Builder, space bool, next byte) {
+	if !space || sb.Len() == 0 {
+		return
+	}
+	s := sb.String()
+	if nameChar(s[len(s)-1]) && (nameChar(next) || next == '"' || next == '$') {
+		sb.WriteByte(' ')
+	}
+}
+
+func nameChar(ch byte) bool {
+	return ch == '_' || ch == '"' || (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+// stringEnd returns the index after the string or block string
+// starting at i
+func stringEnd(q string, i int) int {
+	if strings.HasPrefix(q[i:], `"""`) {
+		if n := strings.Index(q[i+3:], `"""`); n != -1 {
+			return i + 3 + n + 3
+		}
+		return len(q)
+	}
+
+	for j := i + 1; j < len(q); j++ {
+		switch q[j] {
+		case '\\':
+			j++
+		case '"', '\n':
+			return j + 1
+		}
+	}
+	return len(q)
+}
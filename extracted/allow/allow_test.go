@@ -0,0 +1,160 @@
+package allow
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+const usersQuery = `query getUsers { users { id email } }`
+
+func TestRecordEnforce(t *testing.T) {
+	store := NewMemoryStore()
+	l := New(store, ModeRecord)
+	if err := l.Check([]byte(usersQuery), "getUsers", `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	l = New(store, ModeEnforce)
+	if err := l.Check([]byte(usersQuery), "getUsers", `SELECT 1`); err != nil {
+		t.Error(err)
+	}
+	// the same query written differently
+	q := "query getUsers {\n  users { # all of them\n    id, email\n  }\n}"
+	if err := l.Check([]byte(q), "getUsers", ""); err != nil {
+		t.Error(err)
+	}
+	err := l.Check([]byte(`{ users { id } }`), "", "")
+	if !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("got error %v", err)
+	}
+
+	it, ok, err := l.Get(Hash([]byte(usersQuery), "getUsers"))
+	if err != nil || !ok {
+		t.Fatalf("got %v, %v", ok, err)
+	}
+	if it.Name != "getUsers" || it.Query != usersQuery || it.SQL != `SELECT 1` {
+		t.Errorf("got item %+v", it)
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	l := New(NewMemoryStore(), ModeRecord)
+	for _, q := range []string{usersQuery, `{ posts { id } }`} {
+		if err := l.Check([]byte(q), "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var b bytes.Buffer
+	if err := l.Export(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	l2 := New(NewMemoryStore(), ModeEnforce)
+	if err := l2.Import(bytes.NewReader(b.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if err := l2.Check([]byte(`{ posts { id } }`), "", ""); err != nil {
+		t.Error(err)
+	}
+
+	// the export is sorted so it diffs cleanly
+	var b2 bytes.Buffer
+	if err := l2.Export(&b2); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != b2.String() {
+		t.Errorf("got export\n%s\nwant\n%s", b2.String(), b.String())
+	}
+
+	bad := `[{"hash": "abc", "query": "{ users { id } }"}]`
+	if err := l2.Import(bytes.NewReader([]byte(bad))); err == nil {
+		t.Error("want an error for a hash not matching its query")
+	}
+}
+
+func TestHash(t *testing.T) {
+	same := []string{
+		`{ users(where: {name: {eq: "a  b"}}) { id } }`,
+		"{users(where:{name:{eq:\"a  b\"}}){id}}",
+		"{ users(where: {name: {eq: \"a  b\"}}) {\n\tid, # the id\n} }",
+	}
+	for _, q := range same[1:] {
+		if Hash([]byte(q), "") != Hash([]byte(same[0]), "") {
+			t.Errorf("%q: got another hash", q)
+		}
+	}
+
+	differ := []string{
+		`{ users(where: {name: {eq: "a b"}}) { id } }`,
+		`{ users(where: {name: {eq: "a  b"}}) { id email } }`,
+	}
+	for _, q := range differ {
+		if Hash([]byte(q), "") == Hash([]byte(same[0]), "") {
+			t.Errorf("%q: got the same hash", q)
+		}
+	}
+	if Hash([]byte(usersQuery), "a") == Hash([]byte(usersQuery), "b") {
+		t.Error("the operation name does not change the hash")
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "allow.json")
+	fs, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New(fs, ModeRecord).Check([]byte(usersQuery), "getUsers", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New(fs, ModeEnforce).Check([]byte(usersQuery), "getUsers", ""); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewFileStore(filepath.Join(t.TempDir(), "missing", "allow.json")); err != nil {
+		t.Errorf("a missing file is an empty list: %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := "a"
+	l := New(NewMemoryStore(), ModeRecord, WithFingerprint(func() string { return fp }))
+	h := Hash([]byte(usersQuery), "getUsers")
+
+	if err := l.Check([]byte(usersQuery), "getUsers", `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+	it, _, err := l.Get(h)
+	if err != nil || it.SQL != `SELECT 1` || it.Schema != "a" {
+		t.Fatalf("got item %+v, %v", it, err)
+	}
+
+	// the SQL of another schema is stale
+	fp = "b"
+	it, ok, err := l.Get(h)
+	if err != nil || !ok || it.SQL != "" || it.Query != usersQuery {
+		t.Errorf("got item %+v, %v", it, err)
+	}
+	if err := l.Check([]byte(usersQuery), "getUsers", `SELECT 2`); err != nil {
+		t.Fatal(err)
+	}
+	if it, _, _ := l.Get(h); it.SQL != `SELECT 2` || it.Schema != "b" {
+		t.Errorf("got item %+v", it)
+	}
+
+	// a check without the SQL keeps the recorded one
+	if err := New(l.store, ModeEnforce, WithFingerprint(func() string { return "c" })).
+		Check([]byte(usersQuery), "getUsers", ""); err != nil {
+		t.Error(err)
+	}
+	if it, _, _ := l.Get(h); it.SQL != `SELECT 2` {
+		t.Errorf("got item %+v", it)
+	}
+}
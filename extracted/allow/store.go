@@ -0,0 +1,120 @@
+package allow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store holds the queries of an allow list
+type Store interface {
+	Get(hash string) (Item, bool, error)
+	Put(it Item) error
+	List() ([]Item, error)
+}
+
+// MemoryStore is a store that keeps the queries in memory
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string]Item
+}
+
+// NewMemoryStore returns an empty memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string]Item)}
+}
+
+func (ms *MemoryStore) Get(hash string) (Item, bool, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	it, ok := ms.items[hash]
+	return it, ok, nil
+}
+
+func (ms *MemoryStore) Put(it Item) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.items[it.Hash] = it
+	return nil
+}
+
+func (ms *MemoryStore) List() ([]Item, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	items := make([]Item, 0, len(ms.items))
+	for _, it := range ms.items {
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// FileStore is a memory store saved to a JSON file on every change
+type FileStore struct {
+	MemoryStore
+	path string
+}
+
+// NewFileStore returns a store kept in a file, the queries already in
+// the file are loaded
+func NewFileStore(path string) (*FileStore, error) {
+	fs := &FileStore{MemoryStore: *NewMemoryStore(), path: path}
+
+	b, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return fs, nil
+	case err != nil:
+		return nil, fmt.Errorf("error reading allow list: %s", err)
+	}
+
+	var items []Item
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, fmt.Errorf("error reading allow list: %s", err)
+	}
+	for _, it := range items {
+		fs.items[it.Hash] = it
+	}
+	return fs, nil
+}
+
+// Put adds a query and saves the file, the file is replaced in one step
+// so a failed write leaves the old one
+func (fs *FileStore) Put(it Item) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if old, ok := fs.items[it.Hash]; ok && old == it {
+		return nil
+	}
+	fs.items[it.Hash] = it
+
+	items := make([]Item, 0, len(fs.items))
+	for _, v := range fs.items {
+		items = append(items, v)
+	}
+	b, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fs.path), ".allow-*")
+	if err != nil {
+		return fmt.Errorf("error saving allow list: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving allow list: %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving allow list: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), fs.path); err != nil {
+		return fmt.Errorf("error saving allow list: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package bench
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// baselines are the results of Run on the Configs as of the last
+// release, refreshed with `graphjin-schema bench -json > bench/baselines.json`
+//
+//go:embed baselines.json
+var baselines []byte
+
+// Baselines returns the published results of the Configs
+func Baselines() ([]Result, error) {
+	var rs []Result
+	if err := json.Unmarshal(baselines, &rs); err != nil {
+		return nil, fmt.Errorf("bench: invalid baselines: %w", err)
+	}
+	return rs, nil
+}
+
+// WriteJSON writes results in the format of the baselines
+func WriteJSON(w io.Writer, rs []Result) error {
+	b, err := json.MarshalIndent(rs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// Regression is a metric of a result worse than its baseline by more
+// than the tolerance
+type Regression struct {
+	Name     string
+	Metric   string // ns/op, B/op or allocs/op
+	Baseline int64
+	Got      int64
+}
+
+// String returns the regression with the change in percent
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s %d -> %d (%+.0f%%)", r.Name, r.Metric, r.Baseline, r.Got,
+		100*float64(r.Got-r.Baseline)/float64(r.Baseline))
+}
+
+// Compare returns the metrics of the results more than tolerance above
+// their baselines eg. 0.25 for 25%, results without a baseline are left
+// out. Allocations do not depend on the machine, the time of a result
+// does so a baseline of another machine needs a larger tolerance
+func Compare(results, base []Result, tolerance float64) []Regression {
+	idx := make(map[string]Result, len(base))
+	for _, b := range base {
+		idx[b.Name] = b
+	}
+
+	var out []Regression
+	for _, r := range results {
+		b, ok := idx[r.Name]
+		if !ok {
+			continue
+		}
+		for _, m := range []struct {
+			name      string
+			base, got int64
+		}{
+			{"ns/op", b.NsPerOp, r.NsPerOp},
+			{"B/op", b.BytesPerOp, r.BytesPerOp},
+			{"allocs/op", b.AllocsPerOp, r.AllocsPerOp},
+		} {
+			if m.base > 0 && float64(m.got) > float64(m.base)*(1+tolerance) {
+				out = append(out, Regression{Name: r.Name, Metric: m.name, Baseline: m.base, Got: m.got})
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+// Package bench measures how long building a schema and finding join
+// paths take on synthetic schemas of a given size and foreign key
+// density. The results are compared with the baselines kept with the
+// package so a slower or more allocating graph is caught before a
+// release, and the workloads can be run under a profiler
+//
+//	results, err := bench.Run(bench.Configs...)
+//	base, err := bench.Baselines()
+//	for _, r := range bench.Compare(results, base, 0.25) {
+//		fmt.Println(r)
+//	}
+//
+// The same workloads are the BenchmarkBuild and BenchmarkFindPath
+// benchmarks of go test -bench
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Config is the size of a synthetic schema
+type Config struct {
+	Name string
+
+	// Tables is the number of tables
+	Tables int
+
+	// Columns is the number of columns of a table besides its key and
+	// its foreign keys
+	Columns int
+
+	// Density is the average number of foreign keys of a table, a table
+	// references the tables before it
+	Density float64
+
+	// Seed of the foreign keys, the same seed gives the same schema
+	Seed int64
+}
+
+// Configs are the sizes of the baselines
+var Configs = []Config{
+	{Name: "small", Tables: 100, Columns: 10, Density: 1.5, Seed: 1},
+	{Name: "medium", Tables: 1000, Columns: 10, Density: 1.5, Seed: 1},
+	{Name: "large", Tables: 5000, Columns: 10, Density: 1.5, Seed: 1},
+}
+
+// String returns the name of the config, or its size when it has none
+func (c Config) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return fmt.Sprintf("%dx%g", c.Tables, c.Density)
+}
+
+// Synthetic returns the catalog of a synthetic schema, table_<n> has an
+// id, Columns text columns and foreign keys to tables before it. The
+// number of foreign keys of a table is Density on average
+func Synthetic(c Config) *schema.DBInfo {
+	r := rand.New(rand.NewSource(c.Seed))
+
+	var cols []schema.DBColumn
+	for i := 0; i < c.Tables; i++ {
+		name := tableName(i)
+		cols = append(cols, schema.DBColumn{
+			Schema: "public", Table: name, Name: "id", Type: "bigint",
+			PrimaryKey: true, NotNull: true,
+		})
+		for j := 0; j < c.Columns; j++ {
+			cols = append(cols, schema.DBColumn{
+				Schema: "public", Table: name, Name: fmt.Sprintf("col_%d", j), Type: "text",
+			})
+		}
+		if i == 0 {
+			continue
+		}
+
+		n := int(c.Density)
+		if r.Float64() < c.Density-float64(n) {
+			n++
+		}
+		for j := 0; j < n; j++ {
+			ref := tableName(r.Intn(i))
+			cols = append(cols, schema.DBColumn{
+				Schema: "public", Table: name, Name: fmt.Sprintf("%s_id_%d", ref, j), Type: "bigint",
+				FKeySchema: "public", FKeyTable: ref, FKeyCol: "id",
+			})
+		}
+	}
+	return schema.NewDBInfo("postgres", 140000, "public", "bench", cols, nil, nil)
+}
+
+// tableName returns the name of the nth table
+func tableName(n int) string {
+	return fmt.Sprintf("table_%d", n)
+}
@@ -0,0 +1,196 @@
+package bench
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// BenchmarkBuild is the build result of Run, NewDBSchema of the
+// synthetic catalog of each config
+func BenchmarkBuild(b *testing.B) {
+	for _, c := range Configs {
+		var di *schema.DBInfo
+		b.Run(c.String(), func(b *testing.B) {
+			if di == nil {
+				di = Synthetic(c)
+				b.ResetTimer()
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := Build(di); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFindPath is the findpath and findpath-cached results of Run,
+// an operation finds the paths of all the pairs
+func BenchmarkFindPath(b *testing.B) {
+	for _, c := range Configs {
+		for _, cached := range []bool{false, true} {
+			var opts []schema.Option
+			name := c.String()
+			if cached {
+				opts = append(opts, schema.WithPathCache())
+				name += "/cached"
+			}
+
+			// b.Run calls the function once per b.N, the schema is
+			// built by the first call only
+			var s *schema.DBSchema
+			var pairs [][2]string
+			b.Run(name, func(b *testing.B) {
+				if s == nil {
+					var err error
+					if s, err = Build(Synthetic(c), opts...); err != nil {
+						b.Fatal(err)
+					}
+					pairs = Pairs(s, pathPairs, c.Seed)
+					if err := FindPaths(s, pairs, 1); err != nil {
+						b.Fatal(err)
+					}
+					b.ResetTimer()
+				}
+
+				b.ReportAllocs()
+				if err := FindPaths(s, pairs, b.N); err != nil {
+					b.Fatal(err)
+				}
+			})
+		}
+	}
+}
+
+func TestBaselines(t *testing.T) {
+	base, err := Baselines()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make(map[string]bool, len(base))
+	for _, r := range base {
+		if r.NsPerOp <= 0 {
+			t.Errorf("%s: no time", r.Name)
+		}
+		names[r.Name] = true
+	}
+	for _, c := range Configs {
+		for _, op := range []string{"build", "findpath", "findpath-cached"} {
+			if !names[c.String()+"/"+op] {
+				t.Errorf("no baseline for %s/%s", c, op)
+			}
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	base := []Result{{Name: "small/build", NsPerOp: 100, AllocsPerOp: 10, BytesPerOp: 1000}}
+	results := []Result{
+		{Name: "small/build", NsPerOp: 120, AllocsPerOp: 20, BytesPerOp: 1000},
+		{Name: "other/build", NsPerOp: 1000},
+	}
+
+	regs := Compare(results, base, 0.25)
+	if len(regs) != 1 || regs[0].Metric != "allocs/op" || regs[0].Baseline != 10 || regs[0].Got != 20 {
+		t.Fatalf("unexpected regressions: %v", regs)
+	}
+	if got := regs[0].String(); got != "small/build: allocs/op 10 -> 20 (+100%)" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSynthetic(t *testing.T) {
+	c := Config{Tables: 50, Columns: 3, Density: 1.5, Seed: 1}
+	s, err := Build(Synthetic(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.Tables()); n != c.Tables {
+		t.Errorf("got %d tables, want %d", n, c.Tables)
+	}
+	if pairs := Pairs(s, 10, c.Seed); len(pairs) != 10 {
+		t.Errorf("got %d pairs, want 10", len(pairs))
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	base, err := Baselines()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := WriteJSON(&b, base); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(baselines) {
+		t.Errorf("got\n%s\nwant the baselines as they are kept", b.String())
+	}
+}
+
+// the same seed gives the same schema and the foreign keys reference
+// the tables before theirs
+func TestSyntheticSeed(t *testing.T) {
+	c := Config{Tables: 200, Columns: 1, Density: 1.5, Seed: 7}
+	a, b := Synthetic(c), Synthetic(c)
+	if !reflect.DeepEqual(a.Tables, b.Tables) {
+		t.Error("got other tables with the same seed")
+	}
+
+	fks := 0
+	for i, ti := range a.Tables {
+		for _, col := range ti.Columns {
+			if col.FKeyTable == "" {
+				continue
+			}
+			fks++
+			var n int
+			if _, err := fmt.Sscanf(col.FKeyTable, "table_%d", &n); err != nil || n >= i {
+				t.Errorf("%s.%s references %s", ti.Name, col.Name, col.FKeyTable)
+			}
+		}
+	}
+	if d := float64(fks) / float64(c.Tables); d < 1.3 || d > 1.7 {
+		t.Errorf("got a density of %.2f, want about %g", d, c.Density)
+	}
+}
+
+func TestCompareTolerance(t *testing.T) {
+	base := []Result{{Name: "small/findpath", NsPerOp: 100}}
+	if regs := Compare([]Result{{Name: "small/findpath", NsPerOp: 125}}, base, 0.25); len(regs) != 0 {
+		t.Errorf("got regressions %v within the tolerance", regs)
+	}
+	regs := Compare([]Result{{Name: "small/findpath", NsPerOp: 126}}, base, 0.25)
+	if len(regs) != 1 || regs[0].Metric != "ns/op" {
+		t.Errorf("got regressions %v", regs)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs each benchmark for a second")
+	}
+	results, err := Run(Config{Name: "tiny", Tables: 20, Columns: 1, Density: 1.5, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+		if r.NsPerOp <= 0 {
+			t.Errorf("%s: no time", r.Name)
+		}
+	}
+	if got := strings.Join(names, " "); got != "tiny/build tiny/findpath tiny/findpath-cached" {
+		t.Errorf("got results %s", got)
+	}
+
+	if _, err := Run(Config{Tables: 1}); err == nil {
+		t.Error("want an error for a schema without related tables")
+	}
+}
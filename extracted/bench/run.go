@@ -0,0 +1,170 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Result is the cost of an operation of a benchmark
+type Result struct {
+	Name        string `json:"name"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+}
+
+// String returns the result as the go test benchmarks print it
+func (r Result) String() string {
+	return fmt.Sprintf("%s\t%d ns/op\t%d B/op\t%d allocs/op", r.Name, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+}
+
+// pathPairs is the number of paths FindPaths looks up per operation
+const pathPairs = 100
+
+// Run runs the benchmarks of each config, they are named after the
+// config eg. "medium/build":
+//
+//   - build: NewDBSchema of the synthetic catalog
+//   - findpath: FindPath between pairs of related tables, per path
+//   - findpath-cached: the same with WithPathCache, per path
+func Run(configs ...Config) ([]Result, error) {
+	var out []Result
+	for _, c := range configs {
+		di := Synthetic(c)
+
+		s, err := Build(di)
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", c, err)
+		}
+		pairs := Pairs(s, pathPairs, c.Seed)
+		if len(pairs) == 0 {
+			return nil, fmt.Errorf("bench %s: no related tables", c)
+		}
+
+		// the cache is filled first so only hits are measured
+		cs, err := Build(di, schema.WithPathCache())
+		if err == nil {
+			err = FindPaths(cs, pairs, 1)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", c, err)
+		}
+
+		build, err := measure(c.String()+"/build", 1, func(n int) error {
+			for i := 0; i < n; i++ {
+				if _, err := Build(di); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", c, err)
+		}
+		find, err := measure(c.String()+"/findpath", len(pairs), func(n int) error {
+			return FindPaths(s, pairs, n)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", c, err)
+		}
+		cached, err := measure(c.String()+"/findpath-cached", len(pairs), func(n int) error {
+			return FindPaths(cs, pairs, n)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", c, err)
+		}
+		out = append(out, build, find, cached)
+	}
+	return out, nil
+}
+
+// benchTime is how long an operation is repeated for, as go test does
+// by default
+const benchTime = time.Second
+
+// measure runs fn with a growing number of iterations until it takes
+// benchTime and returns its cost per operation, n is the number of
+// operations of an iteration the costs are divided by
+func measure(name string, ops int, fn func(n int) error) (Result, error) {
+	for n := 1; ; {
+		runtime.GC()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		if err := fn(n); err != nil {
+			return Result{}, err
+		}
+		d := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if d >= benchTime || n >= 1e9 {
+			div := int64(n) * int64(max(ops, 1))
+			return Result{
+				Name:        name,
+				NsPerOp:     d.Nanoseconds() / div,
+				AllocsPerOp: int64(after.Mallocs-before.Mallocs) / div,
+				BytesPerOp:  int64(after.TotalAlloc-before.TotalAlloc) / div,
+			}, nil
+		}
+
+		// the iterations that would take benchTime with some margin,
+		// growing at most a hundred times
+		next := 100 * n
+		if d > 0 {
+			next = min(next, int(1.2*float64(n)*float64(benchTime)/float64(d)))
+		}
+		n = max(next, n+1)
+	}
+}
+
+// Build builds a schema from a copy of a catalog since NewDBSchema adds
+// the standard functions to the one it is given, it is the operation of
+// the build benchmark so it can be run under a profiler
+func Build(di *schema.DBInfo, opts ...schema.Option) (*schema.DBSchema, error) {
+	ic := *di
+	ic.Functions = append([]schema.DBFunction{}, di.Functions...)
+	return schema.NewDBSchema(&ic, nil, opts...)
+}
+
+// FindPaths finds the path of every pair n times, it is the operation
+// of the findpath benchmark
+//
+//	pprof.StartCPUProfile(f)
+//	err := bench.FindPaths(s, bench.Pairs(s, 100, 1), 1000)
+//	pprof.StopCPUProfile()
+func FindPaths(s *schema.DBSchema, pairs [][2]string, n int) error {
+	for i := 0; i < n; i++ {
+		for _, p := range pairs {
+			if _, err := s.FindPath(p[0], p[1], ""); err != nil {
+				return fmt.Errorf("path %s to %s: %w", p[0], p[1], err)
+			}
+		}
+	}
+	return nil
+}
+
+// Pairs returns up to n pairs of tables picked at random that have a
+// path between them
+func Pairs(s *schema.DBSchema, n int, seed int64) [][2]string {
+	tables := s.Tables()
+	if len(tables) < 2 {
+		return nil
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	var pairs [][2]string
+	for try := 0; try < n*10 && len(pairs) < n; try++ {
+		from, to := tables[r.Intn(len(tables))].Name, tables[r.Intn(len(tables))].Name
+		if from == to {
+			continue
+		}
+		if _, err := s.FindPath(from, to, ""); err == nil {
+			pairs = append(pairs, [2]string{from, to})
+		}
+	}
+	return pairs
+}
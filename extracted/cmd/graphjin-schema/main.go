@@ -0,0 +1,541 @@
+// Command graphjin-schema inspects the schema of a database and the join
+// paths found between its tables
+//
+//	graphjin-schema -dsn "postgres://localhost/app" tables
+//	graphjin-schema -dsn "postgres://localhost/app" rels users
+//	graphjin-schema -dsn "postgres://localhost/app" path comments users
+//	graphjin-schema -dsn "postgres://localhost/app" analyze
+//	graphjin-schema -dsn "postgres://localhost/app" lint -json
+//	graphjin-schema -dsn "postgres://localhost/app" dump -format dot > schema.dot
+//	graphjin-schema -dsn "postgres://localhost/app" dump -format jsonschema > rows.json
+//	graphjin-schema -dsn "postgres://localhost/app" models -format gorm > models.go
+//	graphjin-schema -dsn "postgres://localhost/app" models -format proto -lock models.proto.lock > models.proto
+//	graphjin-schema -dsn "postgres://localhost/app" seed -rows 100 > seed.sql
+//	graphjin-schema bench -check -cpuprofile cpu.out
+//
+// A schema dumped as JSON can be inspected without the database with
+// -info schema.json, a YAML fixture with -info schema.yaml. With -cache 10m
+// the schema read from the database is saved in the user cache directory
+// and reused for ten minutes
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "github.com/lib/pq" // postgres driver
+
+	"github.com/yourusername/graphjin-extracted/bench"
+	"github.com/yourusername/graphjin-extracted/codegen"
+	"github.com/yourusername/graphjin-extracted/lint"
+	"github.com/yourusername/graphjin-extracted/openapi"
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/sdl"
+	"github.com/yourusername/graphjin-extracted/seed"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+const usage = `usage: graphjin-schema [flags] <command> [args]
+
+commands:
+  dump [-format f]              print the schema as json, sdl, dot,
+                                jsonschema or openapi
+  tables                        list the tables
+  rels <table>                  list the relationships of a table
+  path [-through t] <from> <to> print the join path between two tables
+  analyze                       list foreign key cycles, disconnected
+                                groups of tables and orphan tables
+  lint [-json] [-rules r,...]   check the schema, fails on errors
+  models [-format db|gorm|ent|  print Go models or Protobuf messages
+         proto] [-package p]    for the tables, -lock keeps the field
+         [-json] [-lock file]   numbers of the messages in a file
+  seed [-rows n] [-seed s]      print statements inserting test rows
+                                in foreign key order
+  bench [-tables n] [-density   time building and finding paths in
+        d] [-check] [-json]     synthetic schemas, -check fails on a
+        [-cpuprofile f]         regression from the baselines
+        [-memprofile f]
+
+flags:
+`
+
+// errUsage is returned for invalid commands and arguments
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	dsn := flag.String("dsn", os.Getenv("DATABASE_URL"), "postgres connection string")
+	info := flag.String("info", "", "read the schema from a JSON dump or YAML fixture instead of the database")
+	block := flag.String("block", "", "comma separated list of tables to leave out")
+	timeout := flag.Duration("timeout", 30*time.Second, "schema discovery timeout")
+	cache := flag.Duration("cache", 0, "reuse the schema read from the database for this long")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var blockList []string
+	if *block != "" {
+		blockList = strings.Split(*block, ",")
+	}
+
+	var opts []schema.InfoOption
+	if *cache > 0 {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "graphjin-schema: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, schema.WithCache(filepath.Join(dir, "graphjin-schema"), *dsn, *cache))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	err := run(ctx, os.Stdout, *dsn, *info, blockList, flag.Args(), opts...)
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "graphjin-schema: %v\n\n", err)
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "graphjin-schema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the schema and runs a command
+func run(ctx context.Context, w io.Writer, dsn, info string, blockList, args []string, opts ...schema.InfoOption) error {
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "dump", "tables", "rels", "path", "models", "analyze", "lint", "seed":
+	case "bench":
+		return benchmark(w, args)
+	default:
+		return fmt.Errorf("%w: unknown command %s", errUsage, cmd)
+	}
+
+	di, err := loadInfo(ctx, dsn, info, blockList, opts...)
+	if err != nil {
+		return err
+	}
+
+	if cmd == "dump" {
+		fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+		format := fs.String("format", "json", "output format: json, sdl, dot, jsonschema or openapi")
+		if err := fs.Parse(args); err != nil {
+			return errUsage
+		}
+		if *format == "json" {
+			b, err := di.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", b)
+			return err
+		}
+		s, err := schema.NewDBSchema(di, nil)
+		if err != nil {
+			return err
+		}
+		return dump(w, s, *format)
+	}
+
+	if cmd == "lint" {
+		return lintInfo(w, di, args)
+	}
+
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		return err
+	}
+
+	switch cmd {
+	case "tables":
+		return tables(w, s)
+	case "models":
+		return models(w, s, args)
+	case "seed":
+		return seedRows(w, s, args)
+	case "analyze":
+		return analyze(w, s)
+	case "rels":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: rels takes a table", errUsage)
+		}
+		return rels(w, s, args[0])
+	}
+
+	fs := flag.NewFlagSet("path", flag.ContinueOnError)
+	through := fs.String("through", "", "join table the path must go through")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("%w: path takes two tables", errUsage)
+	}
+	return path(w, s, fs.Arg(0), fs.Arg(1), *through)
+}
+
+// loadInfo reads the schema from a JSON dump or a YAML fixture or
+// discovers it from the database
+func loadInfo(ctx context.Context, dsn, info string, blockList []string, opts ...schema.InfoOption) (*schema.DBInfo, error) {
+	if strings.HasSuffix(info, ".yaml") || strings.HasSuffix(info, ".yml") {
+		return schema.LoadFixtureFile(info)
+	}
+	if info != "" {
+		f, err := os.Open(info)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return schema.LoadDBInfo(f)
+	}
+
+	if dsn == "" {
+		return nil, fmt.Errorf("%w: -dsn or -info is required", errUsage)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return schema.GetDBInfo(ctx, db, "postgres", blockList, opts...)
+}
+
+// dump writes the schema as SDL, DOT, JSON Schema or OpenAPI
+func dump(w io.Writer, s *schema.DBSchema, format string) error {
+	var out string
+	var err error
+
+	switch format {
+	case "sdl":
+		out, err = sdl.Generate(s)
+	case "dot":
+		out, err = s.ToDOT(schema.DOTOptions{Columns: true})
+	case "jsonschema":
+		var b []byte
+		b, err = json.MarshalIndent(typemap.Default.Document(s.GetTables()), "", "  ")
+		out = string(b) + "\n"
+	case "openapi":
+		var b []byte
+		b, err = openapi.Generate(s)
+		out = string(b) + "\n"
+	default:
+		return fmt.Errorf("%w: unknown format %s", errUsage, format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
+
+// tables writes the tables with their type and number of columns
+func tables(w io.Writer, s *schema.DBSchema) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TABLE\tTYPE\tCOLUMNS\tPRIMARY KEY")
+	for _, t := range s.GetTables() {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", t.String(), t.Type, len(t.Columns), t.PrimaryCol.Name)
+	}
+	return tw.Flush()
+}
+
+// rels writes the relationships of a table as the compiler names them
+func rels(w io.Writer, s *schema.DBSchema, name string) error {
+	t, err := s.Find("", name)
+	if err != nil {
+		return err
+	}
+	rels, err := s.GetTableRels(t)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tTYPE\tTABLE\tJOIN")
+	for _, r := range rels {
+		rt := r.Right.Ti.String()
+		if r.Many {
+			rt = "[" + rt + "]"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s = %s\n", r.Name, r.Type, rt,
+			colName(r.Left.Ti, r.Left.Col), colName(r.Right.Ti, r.Right.Col))
+	}
+	return tw.Flush()
+}
+
+// path writes the hops of the join path between two tables
+func path(w io.Writer, s *schema.DBSchema, from, to, through string) error {
+	p, err := s.FindPath(from, to, through)
+	if err != nil {
+		return err
+	}
+	for i, h := range p {
+		join := colName(h.LT, h.LC) + " = " + colName(h.RT, h.RC)
+		if h.Rel == schema.RelManyToMany {
+			join += " through " + h.Through.Ti.String()
+		}
+		if _, err := fmt.Fprintf(w, "%d. %s -> %s (%s) on %s\n", i+1,
+			h.LT.String(), h.RT.String(), h.Rel, join); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// analyze writes the foreign key cycles, the groups of connected tables
+// when there is more than one and the orphan tables
+func analyze(w io.Writer, s *schema.DBSchema) error {
+	a := s.Analyze()
+
+	var sb strings.Builder
+	for _, c := range a.Cycles {
+		sb.WriteString("cycle: " + tableNames(c) + "\n")
+	}
+	if len(a.Components) > 1 {
+		for i, c := range a.Components {
+			fmt.Fprintf(&sb, "group %d: %s\n", i+1, tableNames(c))
+		}
+	}
+	for _, t := range a.Orphans {
+		sb.WriteString("orphan: " + t.String() + "\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// lintInfo writes the findings of the linter, an error is returned when
+// any of them is an error so CI builds fail
+func lintInfo(w io.Writer, di *schema.DBInfo, args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "write the findings as JSON")
+	rules := fs.String("rules", "", "comma separated list of rules to run: "+strings.Join(lint.Rules, ", "))
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	var opts []lint.Option
+	if *rules != "" {
+		opts = append(opts, lint.WithRules(strings.Split(*rules, ",")...))
+	}
+	findings, err := lint.Lint(di, opts...)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if *asJSON {
+		if findings == nil {
+			findings = []lint.Finding{}
+		}
+		b, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+			return err
+		}
+	} else {
+		for _, f := range findings {
+			if _, err := fmt.Fprintln(w, f.String()); err != nil {
+				return err
+			}
+		}
+	}
+
+	if lint.HasErrors(findings) {
+		return errors.New("lint found errors")
+	}
+	return nil
+}
+
+// tableNames returns the names of the tables separated by commas
+func tableNames(tables []schema.DBTable) string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// models writes the Go models of the tables
+func models(w io.Writer, s *schema.DBSchema, args []string) error {
+	fs := flag.NewFlagSet("models", flag.ContinueOnError)
+	format := fs.String("format", "db", "model format: db, gorm, ent or proto")
+	pkg := fs.String("package", "", "package name, models or schema for ent")
+	jsonTags := fs.Bool("json", false, "add json tags")
+	lockFile := fs.String("lock", "", "file keeping the field numbers of proto messages")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	f, err := codegen.ParseFormat(*format)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+	opts := codegen.Options{Format: f, Package: *pkg, JSON: *jsonTags}
+
+	if *lockFile != "" {
+		if opts.Lock, err = readLock(*lockFile); err != nil {
+			return err
+		}
+	}
+
+	b, err := codegen.Generate(s, opts)
+	if err != nil {
+		return err
+	}
+	if *lockFile != "" {
+		if err := writeLock(*lockFile, opts.Lock); err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// seedRows writes the statements inserting test rows in the tables
+func seedRows(w io.Writer, s *schema.DBSchema, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	rows := fs.Int("rows", seed.DefaultRows, "rows of each table")
+	rand := fs.Int64("seed", 1, "seed of the random values")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	tables, err := seed.Generate(s, seed.WithRows(*rows), seed.WithSeed(*rand))
+	if err != nil {
+		return err
+	}
+	return seed.WriteSQL(w, tables)
+}
+
+// benchmark runs the benchmarks of the baseline configs or of a
+// synthetic schema of the size given
+func benchmark(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	tables := fs.Int("tables", 0, "tables of the synthetic schema, zero runs the baseline sizes")
+	columns := fs.Int("columns", 10, "columns of a table besides its keys")
+	density := fs.Float64("density", 1.5, "average foreign keys of a table")
+	check := fs.Bool("check", false, "fail when a result is worse than its baseline")
+	tolerance := fs.Float64("tolerance", 0.25, "how much worse than the baseline a result can be")
+	asJSON := fs.Bool("json", false, "print the results as JSON, as the baselines are kept")
+	cpuProfile := fs.String("cpuprofile", "", "write a CPU profile to a file")
+	memProfile := fs.String("memprofile", "", "write a heap profile to a file")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	configs := bench.Configs
+	if *tables > 0 {
+		configs = []bench.Config{{Tables: *tables, Columns: *columns, Density: *density, Seed: 1}}
+	}
+
+	if *cpuProfile != "" {
+		f, err := os.Create(*cpuProfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	results, err := bench.Run(configs...)
+	if err != nil {
+		return err
+	}
+
+	if *memProfile != "" {
+		f, err := os.Create(*memProfile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			return err
+		}
+	}
+
+	if *asJSON {
+		if err := bench.WriteJSON(w, results); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, r := range results {
+			fmt.Fprintln(tw, r)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if !*check {
+		return nil
+	}
+	base, err := bench.Baselines()
+	if err != nil {
+		return err
+	}
+	regs := bench.Compare(results, base, *tolerance)
+	for _, r := range regs {
+		fmt.Fprintln(os.Stderr, r)
+	}
+	if len(regs) != 0 {
+		return fmt.Errorf("bench found %d regressions", len(regs))
+	}
+	return nil
+}
+
+// readLock reads a proto lock, a missing file is an empty lock
+func readLock(name string) (*codegen.ProtoLock, error) {
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return &codegen.ProtoLock{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return codegen.ReadProtoLock(f)
+}
+
+// writeLock saves a proto lock
+func writeLock(name string, l *codegen.ProtoLock) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := l.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// colName returns a column qualified with its table
+func colName(t schema.DBTable, c schema.DBColumn) string {
+	return t.String() + "." + c.Name
+}
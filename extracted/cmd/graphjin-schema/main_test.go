@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// writeInfo writes the JSON dump of a blog schema and returns its path
+func writeInfo(t *testing.T) string {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("posts", "id pk", "user_id notnull", "title text").
+		Table("comments", "id pk", "post_id notnull", "body text").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := di.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(p, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// runCmd runs a command on the JSON dump and returns its output
+func runCmd(t *testing.T, info string, args ...string) (string, error) {
+	t.Helper()
+	var b bytes.Buffer
+	err := run(context.Background(), &b, "", info, nil, args)
+	return b.String(), err
+}
+
+func TestRun(t *testing.T) {
+	info := writeInfo(t)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"tables"}, []string{"TABLE", "public.users", "public.comments"}},
+		{[]string{"rels", "posts"}, []string{"FIELD", "public.posts.user_id = public.users.id"}},
+		{[]string{"path", "comments", "users"}, []string{
+			"1. public.comments -> public.posts", "2. public.posts -> public.users"}},
+		{[]string{"dump"}, []string{`"Tables":[{"schema":"public","name":"users"`}},
+		{[]string{"dump", "-format", "sdl"}, []string{"type Users {"}},
+		{[]string{"dump", "-format", "dot"}, []string{"digraph"}},
+	}
+	for _, tt := range tests {
+		got, err := runCmd(t, info, tt.args...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.args, err)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%v: output without %q:\n%s", tt.args, want, got)
+			}
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	info := writeInfo(t)
+
+	for _, args := range [][]string{
+		{"missing"},
+		{"rels"},
+		{"path", "users"},
+		{"dump", "-format", "xml"},
+	} {
+		if _, err := runCmd(t, info, args...); !errors.Is(err, errUsage) {
+			t.Errorf("%v: got error %v, want a usage error", args, err)
+		}
+	}
+
+	if _, err := runCmd(t, "", "tables"); !errors.Is(err, errUsage) {
+		t.Errorf("got error %v without a dsn", err)
+	}
+	if _, err := runCmd(t, info, "path", "users", "missing"); err == nil || errors.Is(err, errUsage) {
+		t.Errorf("got error %v for a missing table", err)
+	}
+	if _, err := runCmd(t, filepath.Join(t.TempDir(), "missing.json"), "tables"); err == nil {
+		t.Error("want an error for a missing dump")
+	}
+}
+
+func TestRunFixture(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "schema.yaml")
+	doc := "tables:\n  - name: users\n    columns: [id pk]\n  - name: posts\n    columns: [id pk, user_id]\nrelationships:\n  - from: posts.user_id\n    to: users.id\n"
+	if err := os.WriteFile(p, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := runCmd(t, p, "path", "posts", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "1. public.posts -> public.users") {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRunAnalyze(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "manager_id").
+		Table("posts", "id pk", "user_id notnull").
+		Table("products", "id pk").
+		Table("prices", "id pk", "product_id notnull").
+		Table("settings", "id pk").
+		FK("users.manager_id", "users.id").
+		FK("posts.user_id", "users.id").
+		FK("prices.product_id", "products.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := di.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(info, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := runCmd(t, info, "analyze")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "cycle: public.users\n" +
+		"group 1: public.posts, public.users\n" +
+		"group 2: public.prices, public.products\n" +
+		"orphan: public.settings\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// a connected schema has no groups
+	if got, err := runCmd(t, writeInfo(t), "analyze"); err != nil || got != "" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
+
+func TestRunLint(t *testing.T) {
+	info := writeInfo(t)
+	if got, err := runCmd(t, info, "lint"); err != nil || got != "" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if got, err := runCmd(t, info, "lint", "-json"); err != nil || got != "[]\n" {
+		t.Errorf("got %q, %v", got, err)
+	}
+
+	di, err := schema.NewTestSchema().Table("events", "name text").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := di.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info = filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(info, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the errors fail the command
+	got, err := runCmd(t, info, "lint")
+	if err == nil || errors.Is(err, errUsage) {
+		t.Errorf("got error %v, want the lint errors", err)
+	}
+	if got != "error: public.events: table has no primary key [no-primary-key]\n" {
+		t.Errorf("got %q", got)
+	}
+	if _, err := runCmd(t, info, "lint", "-rules", "naming"); err != nil {
+		t.Errorf("got %v running the naming rule only", err)
+	}
+	if _, err := runCmd(t, info, "lint", "-rules", "missing"); !errors.Is(err, errUsage) {
+		t.Errorf("got %v, want a usage error", err)
+	}
+}
+
+func TestRunDumpJSONSchema(t *testing.T) {
+	got, err := runCmd(t, writeInfo(t), "dump", "-format", "jsonschema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"$defs": {`, `"$schema": "https://json-schema.org/draft/2020-12/schema"`, `"title": "comments"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output without %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunDumpOpenAPI(t *testing.T) {
+	got, err := runCmd(t, writeInfo(t), "dump", "-format", "openapi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"openapi": "3.1.0"`, `"/users/{id}/posts": {`, `"/comments/{id}/post": {`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output without %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestRunModelsProto(t *testing.T) {
+	info := writeInfo(t)
+	lock := filepath.Join(t.TempDir(), "models.proto.lock")
+
+	got, err := runCmd(t, info, "models", "-format", "proto", "-package", "blog", "-lock", lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "package blog;") || !strings.Contains(got, "message Comment {") {
+		t.Errorf("got\n%s", got)
+	}
+	b, err := os.ReadFile(lock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"Comment": {`) {
+		t.Errorf("got lock\n%s", b)
+	}
+
+	// the lock written is read on the next run
+	again, err := runCmd(t, info, "models", "-format", "proto", "-package", "blog", "-lock", lock)
+	if err != nil || again != got {
+		t.Errorf("got %v\n%s", err, again)
+	}
+	if err := os.WriteFile(lock, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runCmd(t, info, "models", "-format", "proto", "-lock", lock); err == nil {
+		t.Error("want an error for a broken lock")
+	}
+}
+
+func TestRunSeed(t *testing.T) {
+	info := writeInfo(t)
+	got, err := runCmd(t, info, "seed", "-rows", "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := strings.Index(got, `INSERT INTO "public"."users"`)
+	posts := strings.Index(got, `INSERT INTO "public"."posts"`)
+	if users == -1 || posts < users || strings.Count(got, ")") < 6 {
+		t.Errorf("got\n%s", got)
+	}
+	if again, _ := runCmd(t, info, "seed", "-rows", "2"); again != got {
+		t.Errorf("got other rows with the same seed\n%s", again)
+	}
+	if _, err := runCmd(t, info, "seed", "-rows", "x"); !errors.Is(err, errUsage) {
+		t.Errorf("got %v, want a usage error", err)
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	if _, err := runCmd(t, "", "bench", "-tables", "x"); !errors.Is(err, errUsage) {
+		t.Errorf("got %v, want a usage error", err)
+	}
+	if testing.Short() {
+		t.Skip("runs each benchmark for a second")
+	}
+
+	// the benchmarks need no schema
+	prof := filepath.Join(t.TempDir(), "cpu.out")
+	got, err := runCmd(t, "", "bench", "-tables", "20", "-json", "-cpuprofile", prof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"name": "20x1.5/findpath-cached"`) {
+		t.Errorf("got\n%s", got)
+	}
+	if fi, err := os.Stat(prof); err != nil || fi.Size() == 0 {
+		t.Errorf("got profile %v, %v", fi, err)
+	}
+}
@@ -0,0 +1,386 @@
+// Package codegen generates Go models from a DBSchema, plain structs
+// with db tags, GORM models or ent schemas, or Protobuf messages, with a
+// field for each column and for each relationship of the graph
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+// Format is the kind of models generated
+type Format int
+
+const (
+	FormatDB    Format = iota // structs with db tags
+	FormatGORM                // GORM models
+	FormatEnt                 // ent schema definitions
+	FormatProto               // Protobuf messages
+)
+
+// ParseFormat returns the format named db, gorm, ent or proto
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "db":
+		return FormatDB, nil
+	case "gorm":
+		return FormatGORM, nil
+	case "ent":
+		return FormatEnt, nil
+	case "proto":
+		return FormatProto, nil
+	}
+	return 0, fmt.Errorf("unknown model format: %s", name)
+}
+
+// Options configures the generated code
+type Options struct {
+	Format  Format
+	Package string // models unless set, schema for ent
+	JSON    bool   // add json tags to the struct fields
+
+	Types *typemap.Mapper // maps the columns to Go types, typemap.Default unless set
+
+	// Lock keeps the field numbers of Protobuf messages stable, it is
+	// updated with the fields added and removed
+	Lock *ProtoLock
+}
+
+// Generate returns a Go file with a model for each table of the schema,
+// the relationships of a table are fields of the related model or a
+// slice of them. The sensitive columns the policy of the schema omits
+// are left out, the ones it redacts are not written to JSON and are
+// sensitive ent fields
+func Generate(s *schema.DBSchema, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		opts.Package = "models"
+		if opts.Format == FormatEnt {
+			opts.Package = "schema"
+		}
+	}
+	if opts.Types == nil {
+		opts.Types = typemap.Default
+	}
+
+	g := &generator{
+		s:       s,
+		opts:    opts,
+		names:   make(map[string]string),
+		imports: make(map[string]struct{}),
+	}
+	g.addTables()
+
+	if opts.Format == FormatProto {
+		return g.writeProto()
+	}
+
+	var body bytes.Buffer
+	var err error
+	if opts.Format == FormatEnt {
+		err = g.writeEnt(&body)
+	} else {
+		err = g.writeStructs(&body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by graphjin codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", opts.Package)
+	if len(g.imports) != 0 {
+		// standard library packages first
+		imports := sortedKeys(g.imports)
+		sort.SliceStable(imports, func(i, j int) bool {
+			return !isThirdParty(imports[i]) && isThirdParty(imports[j])
+		})
+
+		buf.WriteString("import (\n")
+		for i, imp := range imports {
+			if i != 0 && isThirdParty(imp) && !isThirdParty(imports[i-1]) {
+				buf.WriteByte('\n')
+			}
+			fmt.Fprintf(&buf, "\t%q\n", imp)
+		}
+		buf.WriteString(")\n\n")
+	}
+	buf.Write(body.Bytes())
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("error formatting generated code: %w", err)
+	}
+	return out, nil
+}
+
+// generator holds the state of a single generation
+type generator struct {
+	s       *schema.DBSchema
+	opts    Options
+	tables  []schema.DBTable    // tables with a model sorted by type name
+	names   map[string]string   // table to type names
+	imports map[string]struct{} // packages the code uses
+}
+
+// addTables picks the tables that get a model, virtual, JSON, remote
+// and function tables do not have rows of their own
+func (g *generator) addTables() {
+	used := make(map[string]struct{})
+
+	for _, t := range g.s.GetTables() {
+		switch t.Type {
+		case "virtual", "json", "jsonb", "remote", "function":
+			continue
+		}
+		if t.Blocked {
+			continue
+		}
+
+		name := goName(schema.Singular(t.Name))
+		if t.Schema != "" && t.Schema != g.s.DBSchema() {
+			name = goName(t.Schema) + name
+		}
+		if _, ok := used[name]; ok {
+			name = goName(t.Schema + "_" + t.Name)
+		}
+		used[name] = struct{}{}
+
+		g.names[t.String()] = name
+		g.tables = append(g.tables, t)
+	}
+
+	sort.Slice(g.tables, func(i, j int) bool {
+		return g.names[g.tables[i].String()] < g.names[g.tables[j].String()]
+	})
+}
+
+// tableName returns the name of a table as used in SQL
+func (g *generator) tableName(t schema.DBTable) string {
+	if t.Schema == "" || t.Schema == g.s.DBSchema() {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
+
+// modelRels returns the relationships of a table to other tables with a
+// model, embedded, polymorphic and remote relationships are left out
+func (g *generator) modelRels(t schema.DBTable) ([]schema.TableRel, error) {
+	rels, err := g.s.GetTableRels(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []schema.TableRel
+	for _, r := range rels {
+		switch r.Type {
+		case schema.RelOneToOne, schema.RelOneToMany, schema.RelRecursive, schema.RelManyToMany:
+		default:
+			continue
+		}
+		if _, ok := g.names[r.Right.Ti.String()]; ok {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+
+// writeStructs writes a struct for each table with db or GORM tags
+func (g *generator) writeStructs(w *bytes.Buffer) error {
+	for _, t := range g.tables {
+		name := g.names[t.String()]
+		rels, err := g.modelRels(t)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "// %s is a row of the %s table\n", name, g.tableName(t))
+		writeComment(w, t.Comment)
+		fmt.Fprintf(w, "type %s struct {\n", name)
+
+		used := make(map[string]struct{})
+		for _, c := range t.Columns {
+			if c.Blocked || g.s.Handling(c).Omit {
+				continue
+			}
+			writeComment(w, c.Comment)
+			fmt.Fprintf(w, "\t%s %s %s\n", fieldName(used, c.Name), g.goType(c), g.columnTag(c))
+		}
+
+		for _, r := range rels {
+			rt := g.names[r.Right.Ti.String()]
+			if r.Many {
+				rt = "[]" + rt
+			} else {
+				rt = "*" + rt
+			}
+			fmt.Fprintf(w, "\t%s %s %s\n", fieldName(used, r.Name), rt, g.relTag(r))
+		}
+		w.WriteString("}\n\n")
+
+		if g.opts.Format == FormatGORM {
+			fmt.Fprintf(w, "// TableName returns the name of the %s table\n", g.tableName(t))
+			fmt.Fprintf(w, "func (%s) TableName() string {\n\treturn %q\n}\n\n", name, g.tableName(t))
+		}
+	}
+	return nil
+}
+
+// columnTag returns the struct tag of a column field
+func (g *generator) columnTag(c schema.DBColumn) string {
+	var tags []string
+
+	if g.opts.Format == FormatGORM {
+		v := "column:" + c.Name
+		switch {
+		case c.PrimaryKey:
+			v += ";primaryKey"
+		case c.UniqueKey:
+			v += ";unique"
+		}
+		if c.NotNull && !c.PrimaryKey {
+			v += ";not null"
+		}
+		tags = append(tags, `gorm:"`+v+`"`)
+	} else {
+		tags = append(tags, `db:"`+c.Name+`"`)
+	}
+
+	switch {
+	case g.opts.JSON && g.s.Handling(c).Redact:
+		// redacted values are not written to JSON
+		tags = append(tags, `json:"-"`)
+	case g.opts.JSON:
+		tags = append(tags, `json:"`+c.Name+`"`)
+	}
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// relTag returns the struct tag of a relationship field
+func (g *generator) relTag(r schema.TableRel) string {
+	var tags []string
+
+	if g.opts.Format == FormatGORM {
+		var v string
+		switch {
+		case r.Type == schema.RelManyToMany:
+			v = fmt.Sprintf("many2many:%s;joinForeignKey:%s;joinReferences:%s",
+				g.tableName(r.Through.Ti), goName(r.Through.ColL.Name), goName(r.Through.ColR.Name))
+		case isBelongsTo(r):
+			v = fmt.Sprintf("foreignKey:%s;references:%s", goName(r.Left.Col.Name), goName(r.Right.Col.Name))
+		default:
+			v = fmt.Sprintf("foreignKey:%s;references:%s", goName(r.Right.Col.Name), goName(r.Left.Col.Name))
+		}
+		tags = append(tags, `gorm:"`+v+`"`)
+	} else {
+		tags = append(tags, `db:"-"`)
+	}
+
+	if g.opts.JSON {
+		tags = append(tags, `json:"`+r.Name+`,omitempty"`)
+	}
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// isBelongsTo returns true when the table of the relationship holds the
+// foreign key to the related table
+func isBelongsTo(r schema.TableRel) bool {
+	c := r.Left.Col
+	return c.FKeyCol != "" && c.FKeyTable == r.Right.Ti.Name && c.FKeyCol == r.Right.Col.Name
+}
+
+// goType returns the Go type of a column, nullable columns are pointers
+func (g *generator) goType(c schema.DBColumn) string {
+	v, imp := g.opts.Types.Go(c)
+	if imp != "" {
+		g.imports[imp] = struct{}{}
+	}
+	return v
+}
+
+// typeName returns the lowercase name of a database type without its
+// parameters, modifiers or array suffix
+func typeName(dbType string) string {
+	t := strings.ToLower(strings.TrimSpace(dbType))
+	if i := strings.IndexAny(t, "( ["); i != -1 {
+		t = t[:i]
+	}
+	return t
+}
+
+// writeComment writes a comment from the database as a Go comment
+func writeComment(w *bytes.Buffer, comment string) {
+	for _, l := range strings.Split(strings.TrimSpace(comment), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			fmt.Fprintf(w, "// %s\n", l)
+		}
+	}
+}
+
+// initialisms are the words written in upper case in Go names
+var initialisms = map[string]struct{}{
+	"api": {}, "html": {}, "http": {}, "id": {}, "ip": {}, "json": {},
+	"sql": {}, "uri": {}, "url": {}, "uuid": {}, "xml": {},
+}
+
+// goName returns a name in Go style eg. reply_to_id -> ReplyToID
+func goName(s string) string {
+	var sb strings.Builder
+
+	words := strings.FieldsFunc(s, func(r rune) bool {
+		return r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r))
+	})
+	for _, w := range words {
+		if _, ok := initialisms[strings.ToLower(w)]; ok {
+			sb.WriteString(strings.ToUpper(w))
+			continue
+		}
+		sb.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+
+	v := sb.String()
+	if v == "" || unicode.IsDigit(rune(v[0])) {
+		return "X" + v
+	}
+	return v
+}
+
+// fieldName returns the Go name of a field, names already used in the
+// struct are numbered
+func fieldName(used map[string]struct{}, name string) string {
+	v := goName(name)
+	if _, ok := used[v]; ok {
+		for i := 2; ; i++ {
+			if _, ok := used[v+strconv.Itoa(i)]; !ok {
+				v += strconv.Itoa(i)
+				break
+			}
+		}
+	}
+	used[v] = struct{}{}
+	return v
+}
+
+// isThirdParty returns true if the import path is not of the standard
+// library
+func isThirdParty(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return strings.Contains(first, ".")
+}
+
+// sortedKeys returns the sorted keys of a map
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
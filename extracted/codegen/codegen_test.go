@@ -0,0 +1,218 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// blogSchema has users with their posts, a post has tags through a
+// join table and a nullable published time
+func blogSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique", "full_name varchar").
+		Table("posts", "id pk", "user_id notnull", "title text notnull", "meta jsonb", "published_at timestamptz", "score numeric").
+		Table("tags", "id pk", "name text notnull").
+		Table("post_tags", "post_id notnull", "tag_id notnull").
+		FK("posts.user_id", "users.id").
+		FK("post_tags.post_id", "posts.id").
+		FK("post_tags.tag_id", "tags.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range di.Tables[0].Columns {
+		if c.Name == "email" {
+			di.Tables[0].Columns[i].Comment = "Login of the user"
+		}
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerate(t *testing.T) {
+	for _, name := range []string{"db", "gorm", "ent"} {
+		t.Run(name, func(t *testing.T) {
+			f, err := ParseFormat(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := Generate(blogSchema(t), Options{Format: f, JSON: name == "db"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden.Check(t, name+".golden", string(b))
+		})
+	}
+}
+
+func TestGeneratePackage(t *testing.T) {
+	b, err := Generate(blogSchema(t), Options{Package: "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "\npackage db\n") || strings.Contains(string(b), "json:") {
+		t.Errorf("got\n%s", b)
+	}
+	if _, err := ParseFormat("sqlc"); err == nil {
+		t.Error("want an error for an unknown format")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for in, want := range map[string]string{
+		"users":      "Users",
+		"user_id":    "UserID",
+		"api_url":    "APIURL",
+		"full name":  "FullName",
+		"2fa":        "X2fa",
+		"created-at": "CreatedAt",
+	} {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestGenerateProto(t *testing.T) {
+	lock := &ProtoLock{}
+	b, err := Generate(blogSchema(t), Options{Format: FormatProto, Package: "blog.v1", Lock: lock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "proto.golden", string(b))
+
+	// the numbers of the lock are those of the file
+	b2, err := Generate(blogSchema(t), Options{Format: FormatProto, Package: "blog.v1", Lock: lock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != string(b2) {
+		t.Errorf("got another file with the lock\n%s", b2)
+	}
+}
+
+// userSchema has users with the columns and no relationships
+func userSchema(t *testing.T, cols ...string) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().Table("users", cols...).BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestProtoLock(t *testing.T) {
+	lock := &ProtoLock{}
+	if _, err := Generate(userSchema(t, "id pk", "email text", "name text"), Options{Format: FormatProto, Lock: lock}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a removed field is reserved and a new one takes the next number
+	b, err := Generate(userSchema(t, "id pk", "nick text", "email text"), Options{Format: FormatProto, Lock: lock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "message User {\n" +
+		"  reserved 3;\n" +
+		"  reserved \"name\";\n" +
+		"  int64 id = 1;\n" +
+		"  optional string nick = 4;\n" +
+		"  optional string email = 2;\n" +
+		"}\n"
+	if !strings.HasSuffix(string(b), want) {
+		t.Errorf("got\n%s\nwant the message\n%s", b, want)
+	}
+
+	// the lock is read back as written
+	var buf strings.Builder
+	if _, err := lock.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadProtoLock(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ml := got.Messages["User"]
+	if ml == nil || ml.Fields["nick"] != 4 || len(ml.Reserved) != 1 || ml.ReservedNames[0] != "name" {
+		t.Errorf("got lock %s", buf.String())
+	}
+	if _, err := ReadProtoLock(strings.NewReader("{")); err == nil {
+		t.Error("want an error for a broken lock")
+	}
+
+	// a reserved name used again gets a new number
+	b, err = Generate(userSchema(t, "id pk", "name text"), Options{Format: FormatProto, Lock: got})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "  optional string name = 5;\n") || !strings.Contains(string(b), "  reserved 2, 3, 4;\n") {
+		t.Errorf("got\n%s", b)
+	}
+}
+
+func TestProtoNumber(t *testing.T) {
+	ml := &MessageLock{Fields: map[string]int{"a": protoReservedMin - 1}}
+	if n := ml.number("b"); n != protoReservedMax+1 {
+		t.Errorf("got %d, want a number after the ones of Protobuf", n)
+	}
+}
+
+func TestProtoName(t *testing.T) {
+	used := make(map[string]struct{})
+	for _, tt := range []struct{ in, want string }{
+		{"userId", "userid"},
+		{"Full Name", "full_name"},
+		{"2fa", "x_2fa"},
+		{"full_name", "full_name_2"},
+	} {
+		if got := protoName(used, tt.in); got != tt.want {
+			t.Errorf("protoName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// the columns the policy omits are left out and those it redacts are
+// not written to JSON and are sensitive ent fields
+func TestGenerateSensitive(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull", "ssn text").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkSensitiveColumns(schema.SensitiveColumns{Columns: map[string][]string{
+		"email": {"pii"},
+		"ssn":   {"secret"},
+	}})
+	s, err := schema.NewDBSchema(di, nil, schema.WithPolicy(func(c schema.DBColumn) schema.Handling {
+		return schema.Handling{Redact: true, Omit: c.Sensitive[0] == "secret"}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{FormatDB, "Email string `db:\"email\" json:\"-\"`"},
+		{FormatEnt, `field.Text("email").Sensitive()`},
+		{FormatProto, "string email = 2;"},
+	}
+	for _, tt := range tests {
+		b, err := Generate(s, Options{Format: tt.format, JSON: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), tt.want) || strings.Contains(string(b), "ssn") {
+			t.Errorf("format %d: got\n%s\nwant %s without the ssn", tt.format, b, tt.want)
+		}
+	}
+}
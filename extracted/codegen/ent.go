@@ -0,0 +1,242 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// writeEnt writes an ent schema for each table, ent models need an id so
+// tables without a single column primary key are left out. Edges are
+// added for the foreign keys to primary keys, many-to-many relationships
+// are left to the join table
+func (g *generator) writeEnt(w *bytes.Buffer) error {
+	g.imports["entgo.io/ent"] = struct{}{}
+	g.imports["entgo.io/ent/dialect/entsql"] = struct{}{}
+	g.imports["entgo.io/ent/schema"] = struct{}{}
+	g.imports["entgo.io/ent/schema/field"] = struct{}{}
+
+	for _, t := range g.tables {
+		name := g.names[t.String()]
+		if !hasID(t) {
+			fmt.Fprintf(w, "// %s is left out, ent needs a single column primary key\n\n", g.tableName(t))
+			continue
+		}
+
+		fmt.Fprintf(w, "// %s holds the schema of the %s table\n", name, g.tableName(t))
+		writeComment(w, t.Comment)
+		fmt.Fprintf(w, "type %s struct {\n\tent.Schema\n}\n\n", name)
+
+		fmt.Fprintf(w, "// Annotations of %s\n", name)
+		fmt.Fprintf(w, "func (%s) Annotations() []schema.Annotation {\n", name)
+		fmt.Fprintf(w, "\treturn []schema.Annotation{\n\t\tentsql.Annotation{Table: %q},\n", t.Name)
+		if t.Schema != "" && t.Schema != g.s.DBSchema() {
+			fmt.Fprintf(w, "\t\tentsql.Schema(%q),\n", t.Schema)
+		}
+		w.WriteString("\t}\n}\n\n")
+
+		fmt.Fprintf(w, "// Fields of %s\n", name)
+		fmt.Fprintf(w, "func (%s) Fields() []ent.Field {\n\treturn []ent.Field{\n", name)
+		for _, c := range t.Columns {
+			if !c.Blocked && !g.s.Handling(c).Omit {
+				fmt.Fprintf(w, "\t\t%s,\n", g.entField(c))
+			}
+		}
+		w.WriteString("\t}\n}\n\n")
+
+		edges, err := g.entEdges(t)
+		if err != nil {
+			return err
+		}
+		if len(edges) == 0 {
+			continue
+		}
+		g.imports["entgo.io/ent/schema/edge"] = struct{}{}
+
+		fmt.Fprintf(w, "// Edges of %s\n", name)
+		fmt.Fprintf(w, "func (%s) Edges() []ent.Edge {\n\treturn []ent.Edge{\n", name)
+		for _, e := range edges {
+			fmt.Fprintf(w, "\t\t%s,\n", e)
+		}
+		w.WriteString("\t}\n}\n\n")
+	}
+	return nil
+}
+
+// hasID returns true if the table has a single column primary key
+func hasID(t schema.DBTable) bool {
+	n := 0
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			n++
+		}
+	}
+	return n == 1
+}
+
+// entField returns the field builder of a column
+func (g *generator) entField(c schema.DBColumn) string {
+	name := g.entFieldName(c)
+
+	var b string
+	gt := g.goType(c)
+	nillable := strings.HasPrefix(gt, "*")
+
+	// only the string, bytes and JSON fields of ent can be sensitive
+	sensitive := true
+
+	switch gt = strings.TrimPrefix(gt, "*"); gt {
+	case "int16", "int32", "int64", "float32", "bool", "string":
+		sensitive = gt == "string"
+		b = fmt.Sprintf("field.%s(%q)", strings.ToUpper(gt[:1])+gt[1:], name)
+		if gt == "string" && typeName(c.Type) == "text" && !c.PrimaryKey {
+			b = fmt.Sprintf("field.Text(%q)", name)
+		}
+	case "float64":
+		sensitive = false
+		b = fmt.Sprintf("field.Float(%q)", name)
+	case "time.Time":
+		sensitive = false
+		b = fmt.Sprintf("field.Time(%q)", name)
+	case "[]byte":
+		b = fmt.Sprintf("field.Bytes(%q)", name)
+	case "[]string":
+		b = fmt.Sprintf("field.Strings(%q)", name)
+	case "[]int64":
+		b = fmt.Sprintf("field.Ints(%q)", name)
+	case "[]float64":
+		b = fmt.Sprintf("field.Floats(%q)", name)
+	default:
+		if gt == "json.RawMessage" {
+			g.imports["encoding/json"] = struct{}{}
+		}
+		b = fmt.Sprintf("field.JSON(%q, %s{})", name, gt)
+	}
+
+	if name != c.Name {
+		b += fmt.Sprintf(".StorageKey(%q)", c.Name)
+	}
+	switch {
+	case c.PrimaryKey:
+	case !c.NotNull:
+		b += ".Optional()"
+		if nillable {
+			b += ".Nillable()"
+		}
+		if c.UniqueKey {
+			b += ".Unique()"
+		}
+	case c.UniqueKey:
+		b += ".Unique()"
+	}
+	if sensitive && g.s.Handling(c).Redact {
+		b += ".Sensitive()"
+	}
+	if c.Comment != "" {
+		b += ".Comment(" + strconv.Quote(c.Comment) + ")"
+	}
+	return b
+}
+
+// entEdges returns the edge builders of a table, an edge pairs the
+// relationship of the table holding the foreign key with the one of the
+// table it references
+func (g *generator) entEdges(t schema.DBTable) ([]string, error) {
+	rels, err := g.modelRels(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []string
+	for _, r := range rels {
+		rt := r.Right.Ti
+		if r.Type == schema.RelManyToMany || !hasID(rt) {
+			continue
+		}
+		self := rt.String() == t.String()
+
+		if isBelongsTo(r) {
+			if self || !r.Right.Col.PrimaryKey {
+				continue
+			}
+			prels, err := g.modelRels(rt)
+			if err != nil {
+				return nil, err
+			}
+			ref, ok := partner(prels, t, r.Left.Col.Name, false)
+			if !ok {
+				continue
+			}
+			e := fmt.Sprintf("edge.From(%q, %s.Type).Ref(%q).Field(%q).Unique()",
+				r.Name, g.names[rt.String()], ref, g.entFieldName(r.Left.Col))
+			if r.Left.Col.NotNull {
+				e += ".Required()"
+			}
+			edges = append(edges, e)
+			continue
+		}
+
+		if !r.Left.Col.PrimaryKey {
+			continue
+		}
+		e := fmt.Sprintf("edge.To(%q, %s.Type)", r.Name, g.names[rt.String()])
+		if !r.Many {
+			e += ".Unique()"
+		}
+		if self {
+			from, ok := partner(rels, t, r.Right.Col.Name, true)
+			if !ok {
+				continue
+			}
+			e += fmt.Sprintf(".From(%q).Unique().Field(%q)", from, g.entFieldName(r.Right.Col))
+		}
+		edges = append(edges, e)
+	}
+	return edges, nil
+}
+
+// entFieldName returns the name of the field of a column in snake case,
+// the primary key is the id field
+func (g *generator) entFieldName(c schema.DBColumn) string {
+	if c.PrimaryKey {
+		return "id"
+	}
+
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '_'
+	}, c.Name)
+
+	switch {
+	case name == "id":
+		return "id_column"
+	case name == "" || name[0] < 'a':
+		return "x" + name
+	}
+	return name
+}
+
+// partner returns the name of the relationship to the table t on the
+// column of t, belongsTo picks the side holding the foreign key
+func partner(rels []schema.TableRel, t schema.DBTable, col string, belongsTo bool) (string, bool) {
+	for _, r := range rels {
+		if r.Right.Ti.String() != t.String() || isBelongsTo(r) != belongsTo {
+			continue
+		}
+		if belongsTo && r.Left.Col.Name == col {
+			return r.Name, true
+		}
+		if !belongsTo && r.Right.Col.Name == col {
+			return r.Name, true
+		}
+	}
+	return "", false
+}
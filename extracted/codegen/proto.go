@@ -0,0 +1,235 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// ProtoLock holds the field numbers of the generated messages so a field
+// keeps its number across runs, the numbers and names of the fields of
+// removed columns are reserved and never used again. Generate updates it
+// and it is saved with the .proto file, eg. models.proto.lock
+type ProtoLock struct {
+	Messages map[string]*MessageLock `json:"messages"`
+}
+
+// MessageLock holds the field numbers of a message
+type MessageLock struct {
+	Fields        map[string]int `json:"fields"`
+	Reserved      []int          `json:"reserved,omitempty"`
+	ReservedNames []string       `json:"reserved_names,omitempty"`
+}
+
+// ReadProtoLock reads a lock written by WriteTo
+func ReadProtoLock(r io.Reader) (*ProtoLock, error) {
+	var l ProtoLock
+	if err := json.NewDecoder(r).Decode(&l); err != nil {
+		return nil, fmt.Errorf("error reading proto lock: %w", err)
+	}
+	return &l, nil
+}
+
+// WriteTo writes the lock as JSON
+func (l *ProtoLock) WriteTo(w io.Writer) (int64, error) {
+	b, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(b, '\n'))
+	return int64(n), err
+}
+
+// protoReservedMin and protoReservedMax are the field numbers Protobuf
+// keeps for itself
+const protoReservedMin, protoReservedMax = 19000, 19999
+
+// number returns the number of a field, a new field takes the number
+// after the highest used or reserved. A reserved name used again gets a
+// new number
+func (ml *MessageLock) number(name string) int {
+	if n, ok := ml.Fields[name]; ok {
+		return n
+	}
+	for i, v := range ml.ReservedNames {
+		if v == name {
+			ml.ReservedNames = append(ml.ReservedNames[:i], ml.ReservedNames[i+1:]...)
+			break
+		}
+	}
+
+	n := 0
+	for _, v := range ml.Fields {
+		n = max(n, v)
+	}
+	for _, v := range ml.Reserved {
+		n = max(n, v)
+	}
+	n++
+	if n >= protoReservedMin && n <= protoReservedMax {
+		n = protoReservedMax + 1
+	}
+
+	ml.Fields[name] = n
+	return n
+}
+
+// reserve moves the fields no longer in a message to its reserved ones
+func (ml *MessageLock) reserve(fields map[string]struct{}) {
+	for name, n := range ml.Fields {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		delete(ml.Fields, name)
+		ml.Reserved = append(ml.Reserved, n)
+		ml.ReservedNames = append(ml.ReservedNames, name)
+	}
+	sort.Ints(ml.Reserved)
+	sort.Strings(ml.ReservedNames)
+}
+
+// protoField is a field of a message
+type protoField struct {
+	name, typ, comment string
+}
+
+// writeProto writes a .proto file with a message for each table, the
+// relationships of a table are fields of the related message. Field
+// numbers come from the lock of the options, without one they follow
+// the order of the columns
+func (g *generator) writeProto() ([]byte, error) {
+	lock := g.opts.Lock
+	if lock == nil {
+		lock = &ProtoLock{}
+	}
+	if lock.Messages == nil {
+		lock.Messages = make(map[string]*MessageLock)
+	}
+
+	var body bytes.Buffer
+	for _, t := range g.tables {
+		name := g.names[t.String()]
+		fields, err := g.protoFields(t)
+		if err != nil {
+			return nil, err
+		}
+
+		ml, ok := lock.Messages[name]
+		if !ok {
+			ml = &MessageLock{}
+			lock.Messages[name] = ml
+		}
+		if ml.Fields == nil {
+			ml.Fields = make(map[string]int)
+		}
+		names := make(map[string]struct{}, len(fields))
+		for _, f := range fields {
+			names[f.name] = struct{}{}
+		}
+		ml.reserve(names)
+
+		nums := make([]int, len(fields))
+		for i, f := range fields {
+			nums[i] = ml.number(f.name)
+		}
+
+		fmt.Fprintf(&body, "// %s is a row of the %s table\n", name, g.tableName(t))
+		writeComment(&body, t.Comment)
+		fmt.Fprintf(&body, "message %s {\n", name)
+		if len(ml.Reserved) != 0 {
+			reserved := make([]string, len(ml.Reserved))
+			for i, n := range ml.Reserved {
+				reserved[i] = strconv.Itoa(n)
+			}
+			fmt.Fprintf(&body, "  reserved %s;\n", strings.Join(reserved, ", "))
+		}
+		if len(ml.ReservedNames) != 0 {
+			fmt.Fprintf(&body, "  reserved \"%s\";\n", strings.Join(ml.ReservedNames, `", "`))
+		}
+		for i, f := range fields {
+			for _, l := range strings.Split(strings.TrimSpace(f.comment), "\n") {
+				if l = strings.TrimSpace(l); l != "" {
+					fmt.Fprintf(&body, "  // %s\n", l)
+				}
+			}
+			fmt.Fprintf(&body, "  %s %s = %d;\n", f.typ, f.name, nums[i])
+		}
+		body.WriteString("}\n\n")
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by graphjin codegen. DO NOT EDIT.\n\n")
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	fmt.Fprintf(&buf, "package %s;\n\n", g.opts.Package)
+	for _, imp := range sortedKeys(g.imports) {
+		fmt.Fprintf(&buf, "import %q;\n", imp)
+	}
+	if len(g.imports) != 0 {
+		buf.WriteByte('\n')
+	}
+	buf.Write(bytes.TrimRight(body.Bytes(), "\n"))
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// protoFields returns the fields of the message of a table, a field for
+// each column and relationship
+func (g *generator) protoFields(t schema.DBTable) ([]protoField, error) {
+	rels, err := g.modelRels(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []protoField
+	used := make(map[string]struct{})
+	for _, c := range t.Columns {
+		if c.Blocked || g.s.Handling(c).Omit {
+			continue
+		}
+		typ, imp := g.opts.Types.Proto(c)
+		if imp != "" {
+			g.imports[imp] = struct{}{}
+		}
+		fields = append(fields, protoField{protoName(used, c.Name), typ, c.Comment})
+	}
+
+	for _, r := range rels {
+		typ := g.names[r.Right.Ti.String()]
+		if r.Many {
+			typ = "repeated " + typ
+		}
+		fields = append(fields, protoField{protoName(used, r.Name), typ, ""})
+	}
+	return fields, nil
+}
+
+// protoName returns the name of a field in snake case, names already
+// used in the message are numbered
+func protoName(used map[string]struct{}, name string) string {
+	v := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+		return unicode.ToLower(r)
+	}, name)
+	if v == "" || unicode.IsDigit(rune(v[0])) {
+		v = "x_" + v
+	}
+
+	n := v
+	for i := 2; ; i++ {
+		if _, ok := used[n]; !ok {
+			break
+		}
+		n = fmt.Sprintf("%s_%d", v, i)
+	}
+	used[n] = struct{}{}
+	return n
+}
@@ -0,0 +1,251 @@
+// Package explain runs EXPLAIN on compiled statements during development
+// to report their estimated cost, the tables read with sequential scans
+// and the relationships of a query joined without an index
+package explain
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Plan is the estimated cost of a statement as planned by the database
+type Plan struct {
+	StartupCost float64
+	TotalCost   float64
+	Rows        float64 // estimated rows returned
+	SeqScans    []SeqScan
+	Warnings    []string
+}
+
+// SeqScan is a table of the plan read with a sequential scan
+type SeqScan struct {
+	Table  string
+	Alias  string
+	Filter string  // condition the rows are filtered on
+	Rows   float64 // estimated rows returned by the scan
+}
+
+// Option configures an Explainer
+type Option func(*Explainer)
+
+// WithMaxCost adds a warning to the plans estimated to cost more
+func WithMaxCost(cost float64) Option {
+	return func(ex *Explainer) {
+		ex.maxCost = cost
+	}
+}
+
+// WithWarnFunc sets the function called with each warning of a plan and
+// with the errors of EXPLAIN
+func WithWarnFunc(fn func(msg string)) Option {
+	return func(ex *Explainer) {
+		ex.warn = fn
+	}
+}
+
+// Explainer explains statements on a Postgres database, it costs a round
+// trip for each statement so it is meant for development
+type Explainer struct {
+	db      schema.Querier
+	maxCost float64
+	warn    func(msg string)
+}
+
+// New returns an explainer for the database
+func New(db *sql.DB, opts ...Option) *Explainer {
+	return NewFrom(schema.SQLQuerier(db), opts...)
+}
+
+// NewFrom returns an explainer running EXPLAIN with a Querier
+func NewFrom(db schema.Querier, opts ...Option) *Explainer {
+	ex := &Explainer{db: db}
+	for _, fn := range opts {
+		fn(ex)
+	}
+	return ex
+}
+
+// Explain returns the plan of the statement compiled for the query with
+// the values of its placeholders
+func (ex *Explainer) Explain(ctx context.Context, qc *qcode.QCode, stmt string, args ...interface{}) (*Plan, error) {
+	var data []byte
+	err := ex.db.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+stmt, args...).Scan(&data)
+	if err != nil {
+		err = fmt.Errorf("error explaining statement: %w", err)
+		ex.report(err.Error())
+		return nil, err
+	}
+
+	p, err := Parse(data, qc)
+	if err != nil {
+		ex.report(err.Error())
+		return nil, err
+	}
+	if ex.maxCost > 0 && p.TotalCost > ex.maxCost {
+		p.Warnings = append(p.Warnings,
+			fmt.Sprintf("estimated cost %.2f is over %.2f", p.TotalCost, ex.maxCost))
+	}
+
+	for _, w := range p.Warnings {
+		ex.report(w)
+	}
+	return p, nil
+}
+
+func (ex *Explainer) report(msg string) {
+	if ex.warn != nil {
+		ex.warn(msg)
+	}
+}
+
+// node is a node of a plan in the JSON format of EXPLAIN
+type node struct {
+	NodeType    string  `json:"Node Type"`
+	Relation    string  `json:"Relation Name"`
+	Alias       string  `json:"Alias"`
+	StartupCost float64 `json:"Startup Cost"`
+	TotalCost   float64 `json:"Total Cost"`
+	Rows        float64 `json:"Plan Rows"`
+	Filter      string  `json:"Filter"`
+	JoinFilter  string  `json:"Join Filter"`
+	HashCond    string  `json:"Hash Cond"`
+	MergeCond   string  `json:"Merge Cond"`
+	Plans       []node  `json:"Plans"`
+}
+
+// Parse returns the plan of the JSON output of EXPLAIN, the sequential
+// scans of the tables a selection of the query is joined on are warned
+// about as unindexed joins. The query can be nil
+func Parse(data []byte, qc *qcode.QCode) (*Plan, error) {
+	var v []struct {
+		Plan node `json:"Plan"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("error parsing plan: %w", err)
+	}
+	if len(v) == 0 {
+		return nil, fmt.Errorf("empty plan")
+	}
+
+	root := v[0].Plan
+	p := &Plan{
+		StartupCost: root.StartupCost,
+		TotalCost:   root.TotalCost,
+		Rows:        root.Rows,
+	}
+	p.addScans(root, "")
+
+	if qc != nil {
+		for _, jc := range joinCols(qc) {
+			if ss, ok := p.scanOn(jc.col); ok {
+				p.Warnings = append(p.Warnings, fmt.Sprintf(
+					"%s is joined without an index for %s, sequential scan of %s (%.0f rows)",
+					jc.col.Table+"."+jc.col.Name, jc.path, ss.Table, ss.Rows))
+			}
+		}
+	}
+	return p, nil
+}
+
+// addScans adds the sequential scans of a node and its children, cond is
+// the condition of the join the node is under
+func (p *Plan) addScans(n node, cond string) {
+	if n.NodeType == "Seq Scan" {
+		f := n.Filter
+		if cond != "" {
+			f = strings.TrimSpace(f + " " + cond)
+		}
+		p.SeqScans = append(p.SeqScans, SeqScan{
+			Table:  n.Relation,
+			Alias:  n.Alias,
+			Filter: f,
+			Rows:   n.Rows,
+		})
+	}
+
+	if c := n.HashCond + n.MergeCond + n.JoinFilter; c != "" {
+		cond = c
+	}
+	for _, c := range n.Plans {
+		p.addScans(c, cond)
+	}
+}
+
+// scanOn returns the sequential scan of the table of a column filtered
+// on the column
+func (p *Plan) scanOn(c schema.DBColumn) (SeqScan, bool) {
+	// the column unqualified or qualified by the alias of its table,
+	// not a column of the same name of another table
+	re := regexp.MustCompile(`(^|[^.\w"])"?` + regexp.QuoteMeta(c.Name) + `"?($|[^\w"])`)
+
+	for _, ss := range p.SeqScans {
+		if ss.Table != c.Table {
+			continue
+		}
+		if re.MatchString(ss.Filter) || strings.Contains(ss.Filter, ss.Alias+"."+c.Name) {
+			return ss, true
+		}
+	}
+	return SeqScan{}, false
+}
+
+// joinCol is a column a selection is joined on
+type joinCol struct {
+	col  schema.DBColumn
+	path string // eg. users -> comments
+}
+
+// joinCols returns the columns the selections of a query are looked up
+// by for each row of their parent, the join table columns of
+// many-to-many relationships included
+func joinCols(qc *qcode.QCode) []joinCol {
+	var cols []joinCol
+	seen := make(map[string]struct{})
+
+	add := func(c schema.DBColumn, path string) {
+		k := c.Schema + ":" + c.Table + ":" + c.Name
+		if _, ok := seen[k]; ok {
+			return
+		}
+		seen[k] = struct{}{}
+		cols = append(cols, joinCol{col: c, path: path})
+	}
+
+	// a path goes from the parent table, the tables after it are
+	// joined on their columns
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+		for j, r := range sel.Path {
+			path := r.Left.Ti.Name + " -> " + r.Right.Ti.Name
+			if r.Type == schema.RelManyToMany {
+				add(r.Through.ColL, path)
+				add(r.Through.ColR, path)
+			} else {
+				for _, c := range relCols(r.Right.Col, r.Right.Cols) {
+					add(c, path)
+				}
+			}
+			if j != 0 {
+				for _, c := range relCols(r.Left.Col, r.Left.Cols) {
+					add(c, path)
+				}
+			}
+		}
+	}
+	return cols
+}
+
+// relCols returns the columns of a side of a relationship
+func relCols(col schema.DBColumn, cols []schema.DBColumn) []schema.DBColumn {
+	if len(cols) == 0 {
+		return []schema.DBColumn{col}
+	}
+	return cols
+}
@@ -0,0 +1,141 @@
+package explain
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// planQuerier returns the same plan for every statement and keeps the
+// last statement it ran
+type planQuerier struct {
+	plan string
+	err  error
+	stmt string
+}
+
+func (q *planQuerier) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+
+func (q *planQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	q.stmt = query
+	return planRow{q}
+}
+
+type planRow struct{ q *planQuerier }
+
+func (r planRow) Scan(dest ...interface{}) error {
+	if r.q.err != nil {
+		return r.q.err
+	}
+	*dest[0].(*[]byte) = []byte(r.q.plan)
+	return nil
+}
+
+// postsPlan joins users to their posts with a sequential scan of posts
+const postsPlan = `[{"Plan": {
+	"Node Type": "Hash Join", "Startup Cost": 1.5, "Total Cost": 42.25, "Plan Rows": 10,
+	"Hash Cond": "(posts_1.user_id = users_0.id)",
+	"Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "posts", "Alias": "posts_1", "Total Cost": 30, "Plan Rows": 1000},
+		{"Node Type": "Hash", "Plans": [
+			{"Node Type": "Index Scan", "Relation Name": "users", "Alias": "users_0", "Plan Rows": 10}
+		]}
+	]
+}}]`
+
+func compile(t *testing.T, query string) *qcode.QCode {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text").
+		Table("posts", "id pk", "user_id notnull", "title text").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	qc, err := qcode.NewCompiler(s).Compile([]byte(query), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return qc
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(postsPlan), compile(t, `{ users { id posts { id } } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.StartupCost != 1.5 || p.TotalCost != 42.25 || p.Rows != 10 {
+		t.Errorf("got costs %v %v and rows %v", p.StartupCost, p.TotalCost, p.Rows)
+	}
+	if len(p.SeqScans) != 1 {
+		t.Fatalf("got sequential scans %v", p.SeqScans)
+	}
+	if ss := p.SeqScans[0]; ss.Table != "posts" || ss.Alias != "posts_1" || !strings.Contains(ss.Filter, "posts_1.user_id") {
+		t.Errorf("got sequential scan %+v", ss)
+	}
+	if len(p.Warnings) != 1 || !strings.Contains(p.Warnings[0], "posts.user_id is joined without an index for users -> posts") {
+		t.Errorf("got warnings %q", p.Warnings)
+	}
+
+	// a scan of a table not joined on is not an unindexed join
+	p, err = Parse([]byte(postsPlan), compile(t, `{ posts { id } }`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.SeqScans) != 1 || len(p.Warnings) != 0 {
+		t.Errorf("got scans %v and warnings %q", p.SeqScans, p.Warnings)
+	}
+
+	// without the query the scans are reported without warnings
+	if p, err = Parse([]byte(postsPlan), nil); err != nil || len(p.Warnings) != 0 {
+		t.Errorf("got warnings %v, %v", p, err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, data := range []string{``, `{}`, `[]`} {
+		if _, err := Parse([]byte(data), nil); err == nil {
+			t.Errorf("%q: want an error", data)
+		}
+	}
+}
+
+func TestExplain(t *testing.T) {
+	var warnings []string
+	q := &planQuerier{plan: postsPlan}
+	ex := NewFrom(q, WithMaxCost(40), WithWarnFunc(func(msg string) {
+		warnings = append(warnings, msg)
+	}))
+
+	qc := compile(t, `{ users { id posts { id } } }`)
+	p, err := ex.Explain(context.Background(), qc, "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.stmt != "EXPLAIN (FORMAT JSON) SELECT 1" {
+		t.Errorf("got statement %s", q.stmt)
+	}
+	if len(p.Warnings) != 2 || p.Warnings[1] != "estimated cost 42.25 is over 40.00" {
+		t.Errorf("got warnings %q", p.Warnings)
+	}
+	if len(warnings) != 2 {
+		t.Errorf("got reported warnings %q", warnings)
+	}
+
+	// an error of EXPLAIN is reported and returned
+	warnings = nil
+	q.err = errors.New("syntax error")
+	if _, err := ex.Explain(context.Background(), qc, "SELECT"); err == nil {
+		t.Error("want an error")
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "syntax error") {
+		t.Errorf("got reported warnings %q", warnings)
+	}
+}
@@ -0,0 +1,202 @@
+// Package introspect answers GraphQL introspection queries (__schema and
+// __type) from the type system generated for a DBSchema, so tools such
+// as GraphiQL get autocomplete without a hand written schema file
+package introspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/sdl"
+)
+
+// Responder answers introspection queries, it is safe for concurrent use
+type Responder struct {
+	types map[string]*sdl.Type
+	names []string // type names in the order they are listed
+}
+
+// New returns a responder for the type system of a schema
+func New(s *schema.DBSchema) (*Responder, error) {
+	sc, err := sdl.Build(s)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromSDL(sc), nil
+}
+
+// NewFromSDL returns a responder for a generated type system, the built
+// in scalars and the introspection types are added to it
+func NewFromSDL(sc *sdl.Schema) *Responder {
+	r := &Responder{types: make(map[string]*sdl.Type)}
+
+	add := func(types []sdl.Type) {
+		for i := range types {
+			t := &types[i]
+			if _, ok := r.types[t.Name]; ok {
+				continue
+			}
+			r.types[t.Name] = t
+			r.names = append(r.names, t.Name)
+		}
+	}
+	add(append([]sdl.Type(nil), sc.Types...))
+	add(append([]sdl.Type(nil), builtinScalars...))
+	add(append([]sdl.Type(nil), introspectionTypes...))
+	return r
+}
+
+// IsIntrospection returns true when all the root fields of an operation
+// are __schema, __type or __typename
+func IsIntrospection(query []byte, opName string) bool {
+	sels, err := qcode.ParseSelections(query, opName, nil)
+	if err != nil || len(sels) == 0 {
+		return false
+	}
+	for _, sel := range sels {
+		switch sel.Field {
+		case "__schema", "__type", "__typename":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Respond returns the data of an introspection query as a JSON object
+// keyed by the root fields
+func (r *Responder) Respond(query []byte, opName string, vars json.RawMessage) (json.RawMessage, error) {
+	sels, err := qcode.ParseSelections(query, opName, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := r.writeObject(&buf, rootNode{r}, sels); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// node is an object of the introspection type system
+type node interface {
+	typename() string
+	field(name string, args map[string]interface{}) (interface{}, error)
+}
+
+// writeObject writes the selected fields of an object in the order they
+// were selected, fields selected more than once are merged
+func (r *Responder) writeObject(buf *bytes.Buffer, n node, sels []qcode.Selection) error {
+	buf.WriteByte('{')
+	for i, sel := range merge(n.typename(), sels) {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		k, _ := json.Marshal(sel.Name)
+		buf.Write(k)
+		buf.WriteByte(':')
+
+		var v interface{}
+		if sel.Field == "__typename" {
+			v = n.typename()
+		} else {
+			var err error
+			if v, err = n.field(sel.Field, sel.Args); err != nil {
+				return err
+			}
+		}
+		if err := r.writeValue(buf, v, sel.Fields); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeValue writes an object, a list of objects or a scalar
+func (r *Responder) writeValue(buf *bytes.Buffer, v interface{}, sels []qcode.Selection) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return nil
+
+	case node:
+		return r.writeObject(buf, v, sels)
+
+	case []node:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			if err := r.writeObject(buf, item, sels); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
+
+// merge returns the fields that apply to a type with the fields selected
+// more than once merged into the first one
+func merge(typename string, sels []qcode.Selection) []qcode.Selection {
+	var out []qcode.Selection
+	index := make(map[string]int, len(sels))
+
+	for _, sel := range sels {
+		if sel.On != "" && sel.On != typename {
+			continue
+		}
+		if i, ok := index[sel.Name]; ok {
+			out[i].Fields = append(out[i].Fields[:len(out[i].Fields):len(out[i].Fields)], sel.Fields...)
+			continue
+		}
+		index[sel.Name] = len(out)
+		out = append(out, sel)
+	}
+	return out
+}
+
+// rootNode is the Query type of an introspection query
+type rootNode struct {
+	r *Responder
+}
+
+func (n rootNode) typename() string { return "Query" }
+
+func (n rootNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "__schema":
+		return schemaNode(n), nil
+	case "__type":
+		tn, ok := args["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("__type requires a name argument")
+		}
+		if t := n.r.named(tn); t != nil {
+			return t, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown field: %s", name)
+}
+
+// named returns the named type or nil when there is none
+func (r *Responder) named(name string) node {
+	t, ok := r.types[name]
+	if !ok {
+		return nil
+	}
+	return &typeNode{r: r, kind: string(t.Kind), t: t}
+}
@@ -0,0 +1,123 @@
+package introspect
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func blogResponder(t *testing.T) *Responder {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("posts", "id pk", "user_id notnull", "title text", "meta jsonb").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// typeQuery is the type query GraphiQL runs, three levels of ofType
+const typeQuery = `query ($name: String!) {
+	__type(name: $name) { ...T }
+}
+fragment T on __Type {
+	kind name
+	fields(includeDeprecated: true) {
+		name
+		args { name type { kind name } }
+		type { kind name ofType { kind name ofType { kind name ofType { kind name } } } }
+	}
+}`
+
+func TestRespondType(t *testing.T) {
+	r := blogResponder(t)
+	got, err := r.Respond([]byte(typeQuery), "", json.RawMessage(`{"name": "Users"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := json.Indent(&b, got, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "users_type.json", b.String()+"\n")
+
+	// an unknown type is null
+	got, err = r.Respond([]byte(`{ __type(name: "Missing") { name } }`), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `{"__type":null}` {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRespondSchema(t *testing.T) {
+	r := blogResponder(t)
+	got, err := r.Respond([]byte(`{
+		__typename
+		s: __schema { queryType { name } mutationType { name } }
+		__schema { types { name } directives { name } }
+	}`), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res struct {
+		Typename string `json:"__typename"`
+		S        struct {
+			QueryType    struct{ Name string }
+			MutationType *struct{ Name string }
+		}
+		Schema struct {
+			Types      []struct{ Name string }
+			Directives []struct{ Name string }
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(got, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Typename != "Query" || res.S.QueryType.Name != "Query" || res.S.MutationType != nil {
+		t.Errorf("got %s", got)
+	}
+
+	names := make(map[string]bool)
+	for _, v := range res.Schema.Types {
+		names[v.Name] = true
+	}
+	for _, n := range []string{"Users", "Posts", "Query", "JSON", "String", "__Type", "__TypeKind"} {
+		if !names[n] {
+			t.Errorf("types without %s", n)
+		}
+	}
+	if len(res.Schema.Directives) != 2 {
+		t.Errorf("got directives %v", res.Schema.Directives)
+	}
+
+	if _, err := r.Respond([]byte(`{ __type { name } }`), "", nil); err == nil {
+		t.Error("want an error without the type name")
+	}
+}
+
+func TestIsIntrospection(t *testing.T) {
+	for query, want := range map[string]bool{
+		`{ __schema { types { name } } }`:                                        true,
+		`query IntrospectionQuery { __type(name: "Users") { name } __typename }`: true,
+		`{ __schema { types { name } } users { id } }`:                           false,
+		`{ users { id } }`: false,
+		`{ __schema `:      false,
+	} {
+		if got := IsIntrospection([]byte(query), ""); got != want {
+			t.Errorf("IsIntrospection(%s) = %v, want %v", query, got, want)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package introspect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/sdl"
+)
+
+// builtinScalars are the scalars every GraphQL schema has
+var builtinScalars = []sdl.Type{
+	{Kind: sdl.KindScalar, Name: "Boolean", Description: "The `Boolean` scalar type represents `true` or `false`."},
+	{Kind: sdl.KindScalar, Name: "Float", Description: "The `Float` scalar type represents signed double-precision fractional values."},
+	{Kind: sdl.KindScalar, Name: "ID", Description: "The `ID` scalar type represents a unique identifier."},
+	{Kind: sdl.KindScalar, Name: "Int", Description: "The `Int` scalar type represents non-fractional signed whole numeric values."},
+	{Kind: sdl.KindScalar, Name: "String", Description: "The `String` scalar type represents textual data."},
+}
+
+var includeDeprecated = []sdl.Arg{{Name: "includeDeprecated", Type: "Boolean"}}
+
+// introspectionTypes are the types of the introspection fields
+var introspectionTypes = []sdl.Type{
+	{Kind: sdl.KindObject, Name: "__Schema", Fields: []sdl.Field{
+		{Name: "description", Type: "String"},
+		{Name: "types", Type: "[__Type!]!"},
+		{Name: "queryType", Type: "__Type!"},
+		{Name: "mutationType", Type: "__Type"},
+		{Name: "subscriptionType", Type: "__Type"},
+		{Name: "directives", Type: "[__Directive!]!"},
+	}},
+	{Kind: sdl.KindObject, Name: "__Type", Fields: []sdl.Field{
+		{Name: "kind", Type: "__TypeKind!"},
+		{Name: "name", Type: "String"},
+		{Name: "description", Type: "String"},
+		{Name: "specifiedByURL", Type: "String"},
+		{Name: "fields", Type: "[__Field!]", Args: includeDeprecated},
+		{Name: "interfaces", Type: "[__Type!]"},
+		{Name: "possibleTypes", Type: "[__Type!]"},
+		{Name: "enumValues", Type: "[__EnumValue!]", Args: includeDeprecated},
+		{Name: "inputFields", Type: "[__InputValue!]", Args: includeDeprecated},
+		{Name: "ofType", Type: "__Type"},
+	}},
+	{Kind: sdl.KindObject, Name: "__Field", Fields: []sdl.Field{
+		{Name: "name", Type: "String!"},
+		{Name: "description", Type: "String"},
+		{Name: "args", Type: "[__InputValue!]!", Args: includeDeprecated},
+		{Name: "type", Type: "__Type!"},
+		{Name: "isDeprecated", Type: "Boolean!"},
+		{Name: "deprecationReason", Type: "String"},
+	}},
+	{Kind: sdl.KindObject, Name: "__InputValue", Fields: []sdl.Field{
+		{Name: "name", Type: "String!"},
+		{Name: "description", Type: "String"},
+		{Name: "type", Type: "__Type!"},
+		{Name: "defaultValue", Type: "String"},
+		{Name: "isDeprecated", Type: "Boolean!"},
+		{Name: "deprecationReason", Type: "String"},
+	}},
+	{Kind: sdl.KindObject, Name: "__EnumValue", Fields: []sdl.Field{
+		{Name: "name", Type: "String!"},
+		{Name: "description", Type: "String"},
+		{Name: "isDeprecated", Type: "Boolean!"},
+		{Name: "deprecationReason", Type: "String"},
+	}},
+	{Kind: sdl.KindObject, Name: "__Directive", Fields: []sdl.Field{
+		{Name: "name", Type: "String!"},
+		{Name: "description", Type: "String"},
+		{Name: "isRepeatable", Type: "Boolean!"},
+		{Name: "locations", Type: "[__DirectiveLocation!]!"},
+		{Name: "args", Type: "[__InputValue!]!", Args: includeDeprecated},
+	}},
+	{Kind: sdl.KindEnum, Name: "__TypeKind", Values: []string{
+		"SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL",
+	}},
+	{Kind: sdl.KindEnum, Name: "__DirectiveLocation", Values: []string{
+		"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION",
+		"FRAGMENT_SPREAD", "INLINE_FRAGMENT", "VARIABLE_DEFINITION", "SCHEMA",
+		"SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INTERFACE",
+		"UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION",
+	}},
+}
+
+// directive is a directive the compiler accepts
+type directive struct {
+	name      string
+	desc      string
+	locations []string
+	args      []sdl.Arg
+}
+
+var directives = []directive{
+	{
+		name:      "include",
+		desc:      "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:      []sdl.Arg{{Name: "if", Type: "Boolean!"}},
+	},
+	{
+		name:      "skip",
+		desc:      "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		args:      []sdl.Arg{{Name: "if", Type: "Boolean!"}},
+	},
+}
+
+// description returns a description or null when it is empty
+func description(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// schemaNode is the __Schema object
+type schemaNode rootNode
+
+func (n schemaNode) typename() string { return "__Schema" }
+
+func (n schemaNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "description", "mutationType", "subscriptionType":
+		return nil, nil
+	case "types":
+		list := make([]node, len(n.r.names))
+		for i, tn := range n.r.names {
+			list[i] = n.r.named(tn)
+		}
+		return list, nil
+	case "queryType":
+		return n.r.named("Query"), nil
+	case "directives":
+		list := make([]node, len(directives))
+		for i := range directives {
+			list[i] = directiveNode{r: n.r, d: &directives[i]}
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("unknown field: __Schema.%s", name)
+}
+
+// typeNode is a __Type object, lists and non null types wrap the type
+// in ofType and have no name
+type typeNode struct {
+	r      *Responder
+	kind   string
+	t      *sdl.Type
+	ofType node
+}
+
+// ref returns the type of a type reference eg. [Users!]!
+func (r *Responder) ref(s string) node {
+	switch {
+	case strings.HasSuffix(s, "!"):
+		return &typeNode{r: r, kind: "NON_NULL", ofType: r.ref(s[:len(s)-1])}
+	case strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]"):
+		return &typeNode{r: r, kind: "LIST", ofType: r.ref(s[1 : len(s)-1])}
+	}
+	if t := r.named(s); t != nil {
+		return t
+	}
+	return &typeNode{r: r, kind: string(sdl.KindScalar), t: &sdl.Type{Kind: sdl.KindScalar, Name: s}}
+}
+
+func (n *typeNode) typename() string { return "__Type" }
+
+func (n *typeNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	var kind sdl.Kind
+	if n.t != nil {
+		kind = n.t.Kind
+	}
+
+	switch name {
+	case "kind":
+		return n.kind, nil
+	case "name":
+		if n.t == nil {
+			return nil, nil
+		}
+		return n.t.Name, nil
+	case "description":
+		if n.t == nil {
+			return nil, nil
+		}
+		return description(n.t.Description), nil
+	case "specifiedByURL", "specifiedByUrl", "possibleTypes", "inputFields", "isOneOf":
+		return nil, nil
+
+	case "fields":
+		if kind != sdl.KindObject {
+			return nil, nil
+		}
+		list := make([]node, len(n.t.Fields))
+		for i := range n.t.Fields {
+			list[i] = fieldNode{r: n.r, f: &n.t.Fields[i]}
+		}
+		return list, nil
+
+	case "interfaces":
+		if kind != sdl.KindObject {
+			return nil, nil
+		}
+		return []node{}, nil
+
+	case "enumValues":
+		if kind != sdl.KindEnum {
+			return nil, nil
+		}
+		list := make([]node, len(n.t.Values))
+		for i, v := range n.t.Values {
+			list[i] = enumNode(v)
+		}
+		return list, nil
+
+	case "ofType":
+		if n.ofType == nil {
+			return nil, nil
+		}
+		return n.ofType, nil
+	}
+	return nil, fmt.Errorf("unknown field: __Type.%s", name)
+}
+
+// fieldNode is a __Field object
+type fieldNode struct {
+	r *Responder
+	f *sdl.Field
+}
+
+func (n fieldNode) typename() string { return "__Field" }
+
+func (n fieldNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return n.f.Name, nil
+	case "description":
+		return description(n.f.Description), nil
+	case "args":
+		return argNodes(n.r, n.f.Args), nil
+	case "type":
+		return n.r.ref(n.f.Type), nil
+	case "isDeprecated":
+		return false, nil
+	case "deprecationReason":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown field: __Field.%s", name)
+}
+
+// argNode is an __InputValue object
+type argNode struct {
+	r *Responder
+	a sdl.Arg
+}
+
+func argNodes(r *Responder, args []sdl.Arg) []node {
+	list := make([]node, len(args))
+	for i, a := range args {
+		list[i] = argNode{r: r, a: a}
+	}
+	return list
+}
+
+func (n argNode) typename() string { return "__InputValue" }
+
+func (n argNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return n.a.Name, nil
+	case "type":
+		return n.r.ref(n.a.Type), nil
+	case "isDeprecated":
+		return false, nil
+	case "description", "defaultValue", "deprecationReason":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown field: __InputValue.%s", name)
+}
+
+// enumNode is an __EnumValue object
+type enumNode string
+
+func (n enumNode) typename() string { return "__EnumValue" }
+
+func (n enumNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return string(n), nil
+	case "isDeprecated":
+		return false, nil
+	case "description", "deprecationReason":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown field: __EnumValue.%s", name)
+}
+
+// directiveNode is a __Directive object
+type directiveNode struct {
+	r *Responder
+	d *directive
+}
+
+func (n directiveNode) typename() string { return "__Directive" }
+
+func (n directiveNode) field(name string, args map[string]interface{}) (interface{}, error) {
+	switch name {
+	case "name":
+		return n.d.name, nil
+	case "description":
+		return n.d.desc, nil
+	case "isRepeatable":
+		return false, nil
+	case "locations":
+		return n.d.locations, nil
+	case "args":
+		return argNodes(n.r, n.d.args), nil
+	}
+	return nil, fmt.Errorf("unknown field: __Directive.%s", name)
+}
@@ -0,0 +1,445 @@
+// Package lint checks a discovered schema for foreign keys without an
+// index, nullable or of another type than the key they reference, tables
+// without a primary key, inconsistent names and sensitive data that is
+// not tagged or cannot be masked. The findings are meant to gate a CI
+// build after migrations
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// The rules of the linter
+const (
+	RuleUnindexedFK    = "unindexed-fk"       // foreign key not covered by an index
+	RuleNullableFK     = "nullable-fk"        // foreign key column that can be null
+	RuleFKTypeMismatch = "fk-type-mismatch"   // foreign key of another type than its key
+	RuleNoPrimaryKey   = "no-primary-key"     // table without a primary key
+	RuleNaming         = "naming"             // name out of line with the rest of the schema
+	RuleUntagged       = "untagged-sensitive" // column named like sensitive data without a tag
+	RuleMaskedKey      = "masked-key"         // key column the policy masks
+)
+
+// Rules are all the rules of the linter
+var Rules = []string{
+	RuleUnindexedFK,
+	RuleNullableFK,
+	RuleFKTypeMismatch,
+	RuleNoPrimaryKey,
+	RuleNaming,
+	RuleUntagged,
+	RuleMaskedKey,
+}
+
+// Severity is how serious a finding is
+type Severity int
+
+const (
+	Warning Severity = iota
+	Error
+)
+
+var severityNames = map[Severity]string{
+	Warning: "warning",
+	Error:   "error",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+// MarshalText writes the severity by name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads a severity by name
+func (s *Severity) UnmarshalText(b []byte) error {
+	for k, v := range severityNames {
+		if v == string(b) {
+			*s = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity: %s", b)
+}
+
+// Finding is a problem found by a rule
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Table    string   `json:"table"`            // schema qualified
+	Column   string   `json:"column,omitempty"` // the columns of a composite key separated by commas
+	Message  string   `json:"message"`
+}
+
+func (f Finding) String() string {
+	name := f.Table
+	if f.Column != "" {
+		name += "." + f.Column
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", f.Severity, name, f.Message, f.Rule)
+}
+
+// HasErrors returns true if any of the findings is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultSeverity is the severity of the findings of each rule unless set
+// with WithSeverity
+var defaultSeverity = map[string]Severity{
+	RuleUnindexedFK:    Warning,
+	RuleNullableFK:     Warning,
+	RuleFKTypeMismatch: Error,
+	RuleNoPrimaryKey:   Error,
+	RuleNaming:         Warning,
+	RuleUntagged:       Warning,
+	RuleMaskedKey:      Error,
+}
+
+// Option configures Lint
+type Option func(*linter)
+
+// WithRules runs only the rules listed
+func WithRules(rules ...string) Option {
+	return func(l *linter) {
+		l.rules = make(map[string]bool, len(rules))
+		for _, r := range rules {
+			l.rules[r] = true
+		}
+	}
+}
+
+// WithSeverity sets the severity of the findings of a rule
+func WithSeverity(rule string, s Severity) Option {
+	return func(l *linter) {
+		l.severity[rule] = s
+	}
+}
+
+// WithPolicy checks the sensitive columns against a policy instead of
+// schema.DefaultPolicy, it is the policy of schema.WithPolicy
+func WithPolicy(p schema.Policy) Option {
+	return func(l *linter) {
+		l.policy = p
+	}
+}
+
+type linter struct {
+	di       *schema.DBInfo
+	rules    map[string]bool
+	severity map[string]Severity
+	policy   schema.Policy
+	findings []Finding
+}
+
+// Lint returns the findings of the rules on the tables of a schema, views
+// and functions are not checked. The findings are sorted by table and
+// column
+func Lint(di *schema.DBInfo, opts ...Option) ([]Finding, error) {
+	l := &linter{di: di, severity: make(map[string]Severity, len(defaultSeverity))}
+	for k, v := range defaultSeverity {
+		l.severity[k] = v
+	}
+	for _, fn := range opts {
+		fn(l)
+	}
+	for r := range l.rules {
+		if _, ok := defaultSeverity[r]; !ok {
+			return nil, fmt.Errorf("unknown rule: %s", r)
+		}
+	}
+
+	var tables []schema.DBTable
+	indexed := false
+	for _, t := range di.Tables {
+		if t.Type != "" || t.Blocked {
+			continue
+		}
+		tables = append(tables, t)
+		indexed = indexed || len(t.Indexes) != 0
+	}
+
+	for i := range tables {
+		t := &tables[i]
+		l.checkPrimaryKey(t)
+		for _, fk := range foreignKeys(t) {
+			// without any index the indexes were not discovered
+			if indexed {
+				l.checkIndexed(t, fk)
+			}
+			l.checkNullable(t, fk)
+			l.checkTypes(t, fk)
+		}
+	}
+	l.checkNames(tables)
+	l.checkSensitive(tables)
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Column < b.Column
+	})
+	return l.findings, nil
+}
+
+// add adds a finding of a rule that is run
+func (l *linter) add(rule string, t *schema.DBTable, col, format string, args ...interface{}) {
+	if l.rules != nil && !l.rules[rule] {
+		return
+	}
+	l.findings = append(l.findings, Finding{
+		Rule:     rule,
+		Severity: l.severity[rule],
+		Table:    t.String(),
+		Column:   col,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// foreignKey is a single or composite foreign key of a table
+type foreignKey struct {
+	cols []schema.DBColumn
+}
+
+func (fk foreignKey) names() []string {
+	names := make([]string, len(fk.cols))
+	for i, c := range fk.cols {
+		names[i] = c.Name
+	}
+	return names
+}
+
+func (fk foreignKey) column() string {
+	return strings.Join(fk.names(), ",")
+}
+
+// foreignKeys returns the foreign keys of a table, the columns of a
+// composite key share their constraint name
+func foreignKeys(t *schema.DBTable) []foreignKey {
+	var fks []foreignKey
+	named := make(map[string]int)
+
+	for _, c := range t.Columns {
+		if c.FKeyTable == "" || c.FKeyCol == "" {
+			continue
+		}
+		if c.FKeyName != "" {
+			if i, ok := named[c.FKeyName]; ok {
+				fks[i].cols = append(fks[i].cols, c)
+				continue
+			}
+			named[c.FKeyName] = len(fks)
+		}
+		fks = append(fks, foreignKey{cols: []schema.DBColumn{c}})
+	}
+	return fks
+}
+
+func (l *linter) checkPrimaryKey(t *schema.DBTable) {
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			return
+		}
+	}
+	l.add(RuleNoPrimaryKey, t, "", "table has no primary key")
+}
+
+// checkIndexed adds a finding when no index starts with the columns of a
+// foreign key, deleting or updating the referenced row then scans the table
+func (l *linter) checkIndexed(t *schema.DBTable, fk foreignKey) {
+	names := fk.names()
+	if len(names) == 1 && t.IsIndexed(names[0]) {
+		return
+	}
+	for _, idx := range t.Indexes {
+		if idx.Predicate == "" && len(idx.Columns) >= len(names) && sameSet(idx.Columns[:len(names)], names) {
+			return
+		}
+	}
+	l.add(RuleUnindexedFK, t, fk.column(), "foreign key to %s has no index", refName(fk.cols[0]))
+}
+
+func (l *linter) checkNullable(t *schema.DBTable, fk foreignKey) {
+	for _, c := range fk.cols {
+		if !c.NotNull {
+			l.add(RuleNullableFK, t, c.Name, "foreign key to %s can be null", refName(c))
+		}
+	}
+}
+
+func (l *linter) checkTypes(t *schema.DBTable, fk foreignKey) {
+	for _, c := range fk.cols {
+		sn := c.FKeySchema
+		if sn == "" {
+			sn = t.Schema
+		}
+		rc, err := l.di.GetColumn(sn, c.FKeyTable, c.FKeyCol)
+		if err != nil {
+			continue
+		}
+		// the elements of an array column are compared with the key
+		if a, b := baseType(c.Type), baseType(rc.Type); a != b {
+			l.add(RuleFKTypeMismatch, t, c.Name, "foreign key of type %s references %s of type %s",
+				c.Type, refName(c), rc.Type)
+		}
+	}
+}
+
+var snakeCase = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+
+// checkNames adds findings for names that are not lower snake case, tables
+// named in the singular when most are plural or the other way around and
+// foreign keys to an id not named after it eg. user_id or author_id
+func (l *linter) checkNames(tables []schema.DBTable) {
+	var singular, plural []*schema.DBTable
+	for i := range tables {
+		t := &tables[i]
+		if !snakeCase.MatchString(t.Name) {
+			l.add(RuleNaming, t, "", "table name is not lower snake case")
+		}
+		for _, c := range t.Columns {
+			if !snakeCase.MatchString(c.Name) {
+				l.add(RuleNaming, t, c.Name, "column name is not lower snake case")
+			}
+			if c.FKeyTable != "" && c.FKeyCol == "id" && c.Name != "id" && !strings.HasSuffix(c.Name, "_id") {
+				l.add(RuleNaming, t, c.Name, "foreign key to %s is not named with an _id suffix", refName(c))
+			}
+		}
+
+		if i := strings.LastIndexByte(t.Name, '_'); schema.Singular(t.Name[i+1:]) == t.Name[i+1:] {
+			singular = append(singular, t)
+		} else {
+			plural = append(plural, t)
+		}
+	}
+
+	switch {
+	case len(plural) > len(singular):
+		for _, t := range singular {
+			l.add(RuleNaming, t, "", "table name is singular, most table names are plural")
+		}
+	case len(singular) > len(plural):
+		for _, t := range plural {
+			l.add(RuleNaming, t, "", "table name is plural, most table names are singular")
+		}
+	}
+}
+
+// sensitiveName matches the words of column names that hold sensitive
+// data, eg. password_hash or card_number
+var sensitiveName = regexp.MustCompile(`(^|_)(password|passwd|ssn|secret|token|api_key|cvv|iban|passport|card_number|credit_card|date_of_birth|tax_id)(_|$)`)
+
+// checkSensitive adds findings for columns named like sensitive data that
+// have no tag and for key columns the policy masks, the compiler joins on
+// keys so they cannot be masked
+func (l *linter) checkSensitive(tables []schema.DBTable) {
+	keys := make(map[string]bool)
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.FKeyTable == "" {
+				continue
+			}
+			sn := c.FKeySchema
+			if sn == "" {
+				sn = t.Schema
+			}
+			keys[sn+"."+c.FKeyTable+"."+c.FKeyCol] = true
+		}
+	}
+
+	for i := range tables {
+		t := &tables[i]
+		for _, c := range t.Columns {
+			if !c.IsSensitive() {
+				if sensitiveName.MatchString(strings.ToLower(c.Name)) {
+					l.add(RuleUntagged, t, c.Name, "column is named like sensitive data but has no sensitive tag")
+				}
+				continue
+			}
+			key := c.PrimaryKey || c.FKeyTable != "" || keys[t.Schema+"."+t.Name+"."+c.Name]
+			if h := l.policy.Handling(c); key && h.Mask != "" {
+				l.add(RuleMaskedKey, t, c.Name, "policy masks the key column with %s, keys cannot be masked", h.Mask)
+			}
+		}
+	}
+}
+
+// refName returns the table and column referenced by a foreign key column
+func refName(c schema.DBColumn) string {
+	return c.FKeyTable + "." + c.FKeyCol
+}
+
+// typeAliases maps the names of types to the name they are compared by
+var typeAliases = map[string]string{
+	"int":               "integer",
+	"int4":              "integer",
+	"serial":            "integer",
+	"serial4":           "integer",
+	"int8":              "bigint",
+	"serial8":           "bigint",
+	"bigserial":         "bigint",
+	"int2":              "smallint",
+	"smallserial":       "smallint",
+	"character varying": "varchar",
+	"bpchar":            "character",
+	"char":              "character",
+	"timestamptz":       "timestamp with time zone",
+	"timestamp":         "timestamp without time zone",
+	"bool":              "boolean",
+	"float8":            "double precision",
+	"float4":            "real",
+	"decimal":           "numeric",
+}
+
+// baseType returns the name a type is compared by, in lower case without
+// its parameters and array suffix
+func baseType(typ string) string {
+	t := strings.ToLower(strings.TrimSpace(typ))
+	for strings.HasSuffix(t, "[]") {
+		t = strings.TrimSpace(strings.TrimSuffix(t, "[]"))
+	}
+	for {
+		i := strings.IndexByte(t, '(')
+		j := strings.IndexByte(t, ')')
+		if i == -1 || j < i {
+			break
+		}
+		t = t[:i] + t[j+1:]
+	}
+	t = strings.Join(strings.Fields(t), " ")
+	if v, ok := typeAliases[t]; ok {
+		return v
+	}
+	return t
+}
+
+// sameSet returns true if both lists have the same names
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	set := make(map[string]bool, len(a))
+	for _, v := range a {
+		set[v] = true
+	}
+	for _, v := range b {
+		if !set[v] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package lint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// shopInfo has orders of users indexed on their user, order items not
+// indexed on their order and of another type than it, and events
+// without a primary key
+func shopInfo(t *testing.T) *schema.DBInfo {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("orders", "id pk", "user_id notnull", "coupon_id").
+		Table("order_items", "id pk", "order_id integer notnull").
+		Table("coupons", "id pk").
+		Table("events", "name text").
+		FK("orders.user_id", "users.id").
+		FK("orders.coupon_id", "coupons.id").
+		FK("order_items.order_id", "orders.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ti := range di.Tables {
+		if ti.Name == "orders" {
+			di.Tables[i].Indexes = []schema.DBIndex{
+				{Table: "orders", Name: "orders_user_id_idx", Columns: []string{"user_id", "id"}},
+			}
+		}
+	}
+	return di
+}
+
+func lintRule(t *testing.T, di *schema.DBInfo, rule string) []string {
+	t.Helper()
+	findings, err := Lint(di, WithRules(rule))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	return got
+}
+
+func TestLint(t *testing.T) {
+	di := shopInfo(t)
+
+	tests := []struct {
+		rule string
+		want []string
+	}{
+		{RuleUnindexedFK, []string{
+			"warning: public.order_items.order_id: foreign key to orders.id has no index [unindexed-fk]",
+			"warning: public.orders.coupon_id: foreign key to coupons.id has no index [unindexed-fk]",
+		}},
+		{RuleNullableFK, []string{
+			"warning: public.orders.coupon_id: foreign key to coupons.id can be null [nullable-fk]",
+		}},
+		{RuleFKTypeMismatch, []string{
+			"error: public.order_items.order_id: foreign key of type integer references orders.id of type bigint [fk-type-mismatch]",
+		}},
+		{RuleNoPrimaryKey, []string{
+			"error: public.events: table has no primary key [no-primary-key]",
+		}},
+		{RuleNaming, nil},
+	}
+	for _, tt := range tests {
+		got := lintRule(t, di, tt.rule)
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.rule, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestLintNaming(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "Email text").
+		Table("posts", "id pk", "author bigint").
+		Table("comment", "id pk").
+		Table("OrderItems", "id pk").
+		FK("posts.author", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"warning: public.OrderItems: table name is not lower snake case [naming]",
+		"warning: public.comment: table name is singular, most table names are plural [naming]",
+		"warning: public.posts.author: foreign key to users.id is not named with an _id suffix [naming]",
+		"warning: public.users.Email: column name is not lower snake case [naming]",
+	}
+	if got := lintRule(t, di, RuleNaming); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintOptions(t *testing.T) {
+	di := shopInfo(t)
+
+	findings, err := Lint(di, WithRules(RuleNoPrimaryKey), WithSeverity(RuleNoPrimaryKey, Warning))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Severity != Warning || HasErrors(findings) {
+		t.Errorf("got findings %v", findings)
+	}
+
+	if _, err := Lint(di, WithRules("missing")); err == nil {
+		t.Error("want an error for an unknown rule")
+	}
+
+	// without any index the indexes were not discovered
+	for i := range di.Tables {
+		di.Tables[i].Indexes = nil
+	}
+	if got := lintRule(t, di, RuleUnindexedFK); len(got) != 0 {
+		t.Errorf("got findings %q without indexes", got)
+	}
+}
+
+func TestFindingJSON(t *testing.T) {
+	f := Finding{Rule: RuleNoPrimaryKey, Severity: Error, Table: "public.events", Message: "table has no primary key"}
+	b, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rule":"no-primary-key","severity":"error","table":"public.events","message":"table has no primary key"}`
+	if string(b) != want {
+		t.Errorf("got %s, want %s", b, want)
+	}
+
+	var got Finding
+	if err := json.Unmarshal(b, &got); err != nil || got != f {
+		t.Errorf("got %+v, %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(`{"severity":"fatal"}`), &got); err == nil {
+		t.Error("want an error for an unknown severity")
+	}
+}
+
+func TestBaseType(t *testing.T) {
+	for typ, want := range map[string]string{
+		"INT4":                      "integer",
+		"bigint[]":                  "bigint",
+		"character varying(255)":    "varchar",
+		"numeric(10, 2)":            "numeric",
+		"timestamptz":               "timestamp with time zone",
+		"timestamp  with time zone": "timestamp with time zone",
+	} {
+		if got := baseType(typ); got != want {
+			t.Errorf("baseType(%q) = %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestLintSensitive(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull", "password_hash text", "api_token text").
+		Table("orders", "id pk", "user_id notnull").
+		FK("orders.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkSensitiveColumns(schema.SensitiveColumns{Columns: map[string][]string{
+		"users.email":     {"pii"},
+		"users.id":        {"pii"},
+		"users.api_token": {"secret"},
+		"orders.user_id":  {"pii"},
+	}})
+
+	want := []string{
+		"warning: public.users.password_hash: column is named like sensitive data but has no sensitive tag [untagged-sensitive]",
+	}
+	if got := lintRule(t, di, RuleUntagged); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// the default policy does not mask
+	if got := lintRule(t, di, RuleMaskedKey); len(got) != 0 {
+		t.Errorf("got findings %q", got)
+	}
+
+	mask := func(c schema.DBColumn) schema.Handling { return schema.Handling{Mask: schema.MaskNull} }
+	findings, err := Lint(di, WithRules(RuleMaskedKey), WithPolicy(mask))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	want = []string{
+		"error: public.orders.user_id: policy masks the key column with null, keys cannot be masked [masked-key]",
+		"error: public.users.id: policy masks the key column with null, keys cannot be masked [masked-key]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
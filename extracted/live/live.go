@@ -0,0 +1,279 @@
+// Package live runs queries as live queries, a query is executed again
+// on a poll interval or when a table it reads is reported as changed
+// and subscribers receive every new result along with a patch from the
+// result they received before
+package live
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultPollInterval is how often a live query runs unless the engine
+// is configured with WithPollInterval
+const DefaultPollInterval = 5 * time.Second
+
+// Option configures an Engine
+type Option func(*Engine)
+
+// WithPollInterval sets how often live queries run
+func WithPollInterval(d time.Duration) Option {
+	return func(e *Engine) {
+		e.interval = d
+	}
+}
+
+// Engine runs live queries against a database, subscriptions to the
+// same operation with the same variables share a single poll
+type Engine struct {
+	db       schema.Querier
+	qcc      *qcode.Compiler
+	pcc      *psql.Compiler
+	interval time.Duration
+
+	mu    sync.Mutex
+	polls map[string]*poll
+}
+
+// New returns an engine compiling queries with the compilers
+func New(db *sql.DB, qcc *qcode.Compiler, pcc *psql.Compiler, opts ...Option) *Engine {
+	return NewFrom(schema.SQLQuerier(db), qcc, pcc, opts...)
+}
+
+// NewFrom is New running the queries with a Querier, such as a pgx pool
+// adapted by the pgxdb package
+func NewFrom(db schema.Querier, qcc *qcode.Compiler, pcc *psql.Compiler, opts ...Option) *Engine {
+	e := &Engine{
+		db:       db,
+		qcc:      qcc,
+		pcc:      pcc,
+		interval: DefaultPollInterval,
+		polls:    make(map[string]*poll),
+	}
+	for _, fn := range opts {
+		fn(e)
+	}
+	return e
+}
+
+// Update is a result delivered to a subscriber
+type Update struct {
+	// Data is the whole result
+	Data json.RawMessage
+
+	// Patch is a JSON merge patch (RFC 7386) turning the previous
+	// result of the subscriber into this one, it is nil for the
+	// first result
+	Patch json.RawMessage
+
+	// Err is set when the query failed, the poll goes on and the
+	// next result is patched from the last one received
+	Err error
+}
+
+// Subscription receives the results of a live query until it is
+// closed or its context is done
+type Subscription struct {
+	// Updates is closed when the subscription is closed
+	Updates <-chan Update
+
+	ch   chan Update
+	p    *poll
+	e    *Engine
+	last json.RawMessage // last result delivered
+	done chan struct{}
+	once sync.Once
+}
+
+// Subscribe starts a live query for an operation of a query document,
+// mutations cannot be subscribed to. Results are only delivered when
+// they change, a subscriber that falls behind skips results and gets a
+// patch from the last one it received
+func (e *Engine) Subscribe(ctx context.Context, query []byte, opName string, vars map[string]json.RawMessage) (*Subscription, error) {
+	return e.subscribe(ctx, psql.Session{}, false, query, opName, vars)
+}
+
+// SubscribeSession is Subscribe as a session, compiled for its role and
+// tenant with the filter variables read from the session. Subscribers
+// share a poll only when their sessions bind the same values, sessions
+// with settings cannot subscribe as the polls run outside transactions
+func (e *Engine) SubscribeSession(
+	ctx context.Context,
+	sess psql.Session,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (*Subscription, error) {
+	if len(sess.Settings) != 0 {
+		return nil, fmt.Errorf("session settings cannot be used by live queries")
+	}
+	return e.subscribe(ctx, sess, true, query, opName, vars)
+}
+
+// subscribe starts a live query, the filter variables are read from the
+// session when bound is true and from the variables otherwise
+func (e *Engine) subscribe(
+	ctx context.Context,
+	sess psql.Session,
+	bound bool,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (*Subscription, error) {
+	qc, err := e.qcc.CompileRole(query, opName, sess.Role)
+	if err != nil {
+		return nil, err
+	}
+	if qc.Type == qcode.QTMutation {
+		return nil, fmt.Errorf("mutations cannot be subscribed to")
+	}
+
+	stmt, md, err := e.pcc.CompileTenantString(qc, sess.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []interface{}
+	if bound {
+		args, err = psql.SessionArgs(qc, md, vars, sess)
+	} else {
+		args, err = psql.Args(qc, md, vars)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := json.Marshal(append([]interface{}{stmt}, args...))
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Update, 1)
+	sub := &Subscription{Updates: ch, ch: ch, e: e, done: make(chan struct{})}
+
+	e.mu.Lock()
+	p, ok := e.polls[string(key)]
+	if !ok {
+		p = newPoll(string(key), stmt, args, readTables(qc))
+		e.polls[p.key] = p
+		go e.run(p)
+	}
+	sub.p = p
+	p.add(sub)
+	e.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Close()
+		case <-sub.done:
+		}
+	}()
+	return sub, nil
+}
+
+// Close stops the subscription and closes its Updates channel, the
+// poll stops with its last subscriber
+func (sub *Subscription) Close() {
+	sub.once.Do(func() {
+		e, p := sub.e, sub.p
+
+		e.mu.Lock()
+		if p.remove(sub) == 0 {
+			delete(e.polls, p.key)
+			close(p.done)
+		}
+		e.mu.Unlock()
+		close(sub.done)
+	})
+}
+
+// Notify runs the live queries reading any of the tables now instead
+// of at their next poll, tables are named 'table' or 'schema.table'.
+// It is meant to be called from a change feed such as a LISTEN
+// channel or a logical decoding consumer
+func (e *Engine) Notify(tables ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, p := range e.polls {
+		for _, t := range tables {
+			if _, ok := p.tables[t]; ok {
+				p.wake()
+				break
+			}
+		}
+	}
+}
+
+// run polls a live query until its last subscriber is gone
+func (e *Engine) run(p *poll) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-p.done
+		cancel()
+	}()
+
+	t := time.NewTicker(e.interval)
+	defer t.Stop()
+
+	for {
+		var data []byte
+		err := e.db.QueryRow(ctx, p.stmt, p.args...).Scan(&data)
+		if ctx.Err() != nil {
+			return
+		}
+		p.deliver(data, err)
+
+		select {
+		case <-t.C:
+		case <-p.notify:
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// deliver sends a result to the subscriber unless it is the last one
+// it received, the result is skipped when the subscriber has not taken
+// the one before
+func (sub *Subscription) deliver(data json.RawMessage, err error) {
+	u := Update{Data: data, Err: err}
+
+	if err == nil {
+		if bytes.Equal(sub.last, data) {
+			return
+		}
+		if sub.last != nil {
+			patch, changed, perr := mergePatch(sub.last, data)
+			switch {
+			case perr != nil:
+				u.Err = perr
+			case !changed:
+				sub.last = data
+				return
+			default:
+				u.Patch = patch
+			}
+		}
+	}
+
+	select {
+	case sub.ch <- u:
+		if u.Err == nil {
+			sub.last = data
+		}
+	default:
+	}
+}
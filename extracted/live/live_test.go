@@ -0,0 +1,156 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// fakeQuerier returns the same JSON for every query and keeps the
+// statements it ran with their arguments
+type fakeQuerier struct {
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	var sb strings.Builder
+	sb.WriteString(query)
+	sb.WriteString("\n")
+	for i, a := range args {
+		fmt.Fprintf(&sb, "-- $%d = %#v\n", i+1, a)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stmts = append(q.stmts, sb.String())
+	return fakeRow(`{"posts": []}`)
+}
+
+// statements returns the statements run so far
+func (q *fakeQuerier) statements() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]string(nil), q.stmts...)
+}
+
+type fakeRow string
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	*dest[0].(*[]byte) = []byte(r)
+	return nil
+}
+
+// newEngine returns an engine on a schema of posts belonging to users
+// that only polls when notified
+func newEngine(t *testing.T, q schema.Querier) *Engine {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("posts", "id pk", "user_id notnull", "title text notnull").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s), WithPollInterval(time.Hour))
+}
+
+// next returns the next update of a subscription
+func next(t *testing.T, sub *Subscription) Update {
+	t.Helper()
+	select {
+	case u := <-sub.Updates:
+		return u
+	case <-time.After(5 * time.Second):
+		t.Fatal("no update")
+	}
+	return Update{}
+}
+
+func TestSubscribeGolden(t *testing.T) {
+	const query = `subscription ($id: ID!) { posts(where: {user_id: {eq: $id}, title: {neq: "it's"}}) { id title user { email } } }`
+	vars := map[string]json.RawMessage{"id": json.RawMessage(`5`)}
+
+	q := &fakeQuerier{}
+	e := newEngine(t, q)
+
+	// subscriptions with the same variables share a poll
+	var subs []*Subscription
+	for i := 0; i < 2; i++ {
+		sub, err := e.Subscribe(context.Background(), []byte(query), "", vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		subs = append(subs, sub)
+	}
+	for _, sub := range subs {
+		if u := next(t, sub); u.Err != nil || string(u.Data) != `{"posts": []}` {
+			t.Errorf("got %s, %v", u.Data, u.Err)
+		}
+	}
+
+	stmts := q.statements()
+	if len(stmts) != 1 {
+		t.Fatalf("got %d statements, want 1", len(stmts))
+	}
+	golden.Check(t, "subscribe.sql", stmts[0])
+}
+
+// the subscribers of a role share a poll only when their sessions bind
+// the same filter values
+func TestSubscribeSession(t *testing.T) {
+	s, err := schema.NewTestSchema().
+		Table("posts", "id pk", "org_id notnull", "title text notnull").
+		BuildSchema(schema.WithRoles(schema.Role{Name: "member", Tables: []schema.RoleTable{{
+			Table:  "posts",
+			Filter: `{org_id: {eq: $org_id}}`,
+		}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := &fakeQuerier{}
+	e := NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s), WithPollInterval(time.Hour))
+	query := []byte(`subscription { posts { id title } }`)
+
+	for _, org := range []int{1, 1, 2} {
+		sess := psql.Session{Role: "member", Vars: map[string]interface{}{"org_id": org}}
+		sub, err := e.SubscribeSession(context.Background(), sess, query, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+		if u := next(t, sub); u.Err != nil {
+			t.Fatal(u.Err)
+		}
+	}
+	stmts := q.statements()
+	if len(stmts) != 2 {
+		t.Fatalf("got %d statements, want a poll per organization", len(stmts))
+	}
+	if !strings.Contains(stmts[0], `"org_id"`) || !strings.Contains(stmts[1], `"2"`) {
+		t.Errorf("got statements\n%s\n%s", stmts[0], stmts[1])
+	}
+
+	sess := psql.Session{Role: "member", Settings: map[string]string{"app.org_id": "1"}}
+	if _, err := e.SubscribeSession(context.Background(), sess, query, "", nil); err == nil {
+		t.Error("want an error for a session with settings")
+	}
+	if _, err := e.SubscribeSession(context.Background(), psql.Session{Role: "member"}, query, "", nil); err == nil {
+		t.Error("want an error for a session variable not set")
+	}
+}
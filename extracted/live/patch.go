@@ -0,0 +1,66 @@
+package live
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// mergePatch returns the JSON merge patch (RFC 7386) from one result to
+// the next, changed is false when the results only differ in layout.
+// Objects are patched key by key and any other changed value including
+// a list is replaced whole
+func mergePatch(from, to json.RawMessage) (json.RawMessage, bool, error) {
+	a, err := decode(from)
+	if err != nil {
+		return nil, false, err
+	}
+	b, err := decode(to)
+	if err != nil {
+		return nil, false, err
+	}
+
+	patch, changed := diff(a, b)
+	if !changed {
+		return nil, false, nil
+	}
+	v, err := json.Marshal(patch)
+	return v, true, err
+}
+
+// diff returns the patch between two decoded values
+func diff(a, b interface{}) (interface{}, bool) {
+	ao, aok := a.(map[string]interface{})
+	bo, bok := b.(map[string]interface{})
+	if !aok || !bok {
+		return b, !reflect.DeepEqual(a, b)
+	}
+
+	patch := make(map[string]interface{})
+	for k, av := range ao {
+		bv, ok := bo[k]
+		if !ok {
+			patch[k] = nil
+			continue
+		}
+		if v, changed := diff(av, bv); changed {
+			patch[k] = v
+		}
+	}
+	for k, bv := range bo {
+		if _, ok := ao[k]; !ok {
+			patch[k] = bv
+		}
+	}
+	return patch, len(patch) != 0
+}
+
+// decode decodes a result keeping numbers as written
+func decode(v json.RawMessage) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(v))
+	d.UseNumber()
+
+	var val interface{}
+	err := d.Decode(&val)
+	return val, err
+}
@@ -0,0 +1,89 @@
+package live
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// poll is a live query shared by the subscriptions to it
+type poll struct {
+	key    string
+	stmt   string
+	args   []interface{}
+	tables map[string]struct{} // tables read by the query
+	notify chan struct{}
+	done   chan struct{} // closed when the last subscriber is gone
+
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+	last json.RawMessage // last result, nil before the first run
+}
+
+func newPoll(key, stmt string, args []interface{}, tables map[string]struct{}) *poll {
+	return &poll{
+		key:    key,
+		stmt:   stmt,
+		args:   args,
+		tables: tables,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// add adds a subscriber, it gets the last result right away when the
+// query has already run
+func (p *poll) add(sub *Subscription) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subs[sub] = struct{}{}
+	if p.last != nil {
+		sub.deliver(p.last, nil)
+	}
+}
+
+// remove removes a subscriber, closes its channel and returns the
+// number of subscribers left
+func (p *poll) remove(sub *Subscription) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.subs[sub]; ok {
+		delete(p.subs, sub)
+		close(sub.ch)
+	}
+	return len(p.subs)
+}
+
+// wake asks for the query to run now, a pending request is enough
+func (p *poll) wake() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// deliver sends the result of a run to the subscribers
+func (p *poll) deliver(data json.RawMessage, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err == nil {
+		p.last = data
+	}
+	for sub := range p.subs {
+		sub.deliver(data, err)
+	}
+}
+
+// readTables returns the set of the tables a query reads
+func readTables(qc *qcode.QCode) map[string]struct{} {
+	tables := make(map[string]struct{})
+	for _, t := range qc.Tables() {
+		tables[t] = struct{}{}
+	}
+	return tables
+}
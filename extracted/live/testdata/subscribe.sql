@@ -0,0 +1,2 @@
+SELECT json_build_object('posts', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "posts_0"."id", 'title', "posts_0"."title", 'user', "__sj_1"."json") AS "json" FROM (SELECT "posts_0"."id", "posts_0"."title", "posts_0"."user_id" FROM "public"."posts" AS "posts_0" WHERE (("posts_0"."user_id" = $1::bigint) AND ("posts_0"."title" <> 'it''s'))) AS "posts_0" LEFT OUTER JOIN LATERAL (SELECT json_build_object('email', "users_1"."email") AS "json" FROM (SELECT "users_1"."email" FROM "public"."users" AS "users_1" WHERE ("posts_0"."user_id" = "users_1"."id") LIMIT 1) AS "users_1") AS "__sj_1" ON true) AS "__sr_0") AS "__sj_0" ON true
+-- $1 = "5"
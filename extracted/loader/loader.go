@@ -0,0 +1,425 @@
+// Package loader batches the lookups of related rows made by callers
+// resolving relationships one row at a time, the keys asked for within
+// a short wait are read with a single query instead of one each
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultWait is how long a batch collects keys unless the loader is
+// configured with WithWait
+const DefaultWait = time.Millisecond
+
+// DefaultMaxBatch is the number of keys a batch is run at unless the
+// loader is configured with WithMaxBatch
+const DefaultMaxBatch = 500
+
+// Option configures a Loader
+type Option func(*options)
+
+type options struct {
+	wait time.Duration
+	max  int
+	sess psql.Session
+}
+
+// WithWait sets how long a batch collects keys after its first one
+func WithWait(d time.Duration) Option {
+	return func(o *options) {
+		o.wait = d
+	}
+}
+
+// WithMaxBatch sets the number of keys a batch is run at without
+// waiting any longer
+func WithMaxBatch(n int) Option {
+	return func(o *options) {
+		o.max = n
+	}
+}
+
+// WithSession reads the rows as a session, with the access, filters and
+// masks of its role, from the schema of its tenant (see
+// schema.WithTenantTemplate) and with the filter variables it sets
+func WithSession(sess psql.Session) Option {
+	return func(o *options) {
+		o.sess = sess
+	}
+}
+
+// Loader reads the rows of the related table of a relationship by the
+// key of the table it is from, eg. the users of comments by user_id
+//
+//	rels, _ := s.GetTableRels(comments)
+//	users, err := loader.New[int64](db, qcc, pcc, rels[0].DBRel)
+//	rows, err := users.Load(ctx, comment.UserID)
+type Loader[K comparable] struct {
+	db   schema.Querier
+	qc   *qcode.QCode
+	md   psql.Metadata
+	stmt string
+	opts options
+
+	// root is the key of the selection in the result, key the field of
+	// its rows holding the key and nested the field holding the related
+	// row when the selection is of the join table
+	root, key, nested string
+
+	// poly is set with the type value of a polymorphic relationship
+	poly bool
+	typ  string
+
+	mu    sync.Mutex
+	batch *batch
+}
+
+// batch is the keys collected by a loader and the rows read for them
+type batch struct {
+	ctx  context.Context
+	keys []string
+	seen map[string]struct{}
+	once sync.Once
+
+	timer *time.Timer
+	done  chan struct{}
+	rows  map[string][]json.RawMessage
+	err   error
+}
+
+// New returns a loader for a relationship. The rows are read by a query
+// compiled with the compilers so they are the rows a selection of the
+// relationship reads: the filters of the table, the exclusion of its
+// soft deleted rows and the access, filter and masks of the role of the
+// session apply. The relationship is joined on a single column, the
+// join table of a many-to-many relationship is read along with the rows
+func New[K comparable](
+	db schema.Querier,
+	qcc *qcode.Compiler,
+	pcc *psql.Compiler,
+	rel schema.DBRel,
+	opts ...Option,
+) (*Loader[K], error) {
+	l := &Loader[K]{
+		db:   db,
+		opts: options{wait: DefaultWait, max: DefaultMaxBatch},
+	}
+	for _, fn := range opts {
+		fn(&l.opts)
+	}
+	if len(l.opts.sess.Settings) != 0 {
+		return nil, fmt.Errorf("session settings cannot be used by loaders")
+	}
+
+	query, err := l.loadQuery(qcc.Schema(), rel)
+	if err != nil {
+		return nil, err
+	}
+
+	sess := l.opts.sess
+	if l.qc, err = qcc.CompileRole([]byte(query), "", sess.Role); err != nil {
+		return nil, fmt.Errorf("relationship cannot be loaded: %s: %w", rel.String(), err)
+	}
+	if l.stmt, l.md, err = pcc.CompileTenantString(l.qc, sess.Tenant); err != nil {
+		return nil, fmt.Errorf("relationship cannot be loaded: %s: %w", rel.String(), err)
+	}
+	return l, nil
+}
+
+// Load returns the JSON of the related rows of a key, the key is batched
+// with the ones asked for by other calls within the wait
+func (l *Loader[K]) Load(ctx context.Context, key K) ([]json.RawMessage, error) {
+	k := keyString(key)
+	return l.add(ctx, k).get(ctx, k)
+}
+
+// LoadMany returns the related rows of each of the keys, the keys are
+// split over batches when there are more than the maximum
+func (l *Loader[K]) LoadMany(ctx context.Context, keys []K) ([][]json.RawMessage, error) {
+	ks := make([]string, len(keys))
+	bs := make([]*batch, len(keys))
+	for i, key := range keys {
+		ks[i] = keyString(key)
+		bs[i] = l.add(ctx, ks[i])
+	}
+
+	rows := make([][]json.RawMessage, len(keys))
+	for i, b := range bs {
+		v, err := b.get(ctx, ks[i])
+		if err != nil {
+			return nil, err
+		}
+		rows[i] = v
+	}
+	return rows, nil
+}
+
+// add adds a key to the current batch, a new batch is started when there
+// is none and run once it is full or the wait is over
+func (l *Loader[K]) add(ctx context.Context, k string) *batch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.batch
+	if b == nil {
+		// the batch outlives the call starting it
+		b = &batch{
+			ctx:  context.WithoutCancel(ctx),
+			seen: make(map[string]struct{}),
+			done: make(chan struct{}),
+		}
+		l.batch = b
+		b.timer = time.AfterFunc(l.opts.wait, func() { l.run(b) })
+	}
+
+	if _, ok := b.seen[k]; !ok {
+		b.seen[k] = struct{}{}
+		b.keys = append(b.keys, k)
+	}
+
+	if l.opts.max > 0 && len(b.keys) >= l.opts.max {
+		l.batch = nil
+		b.timer.Stop()
+		go l.run(b)
+	}
+	return b
+}
+
+// get returns the rows of a key once the batch is done
+func (b *batch) get(ctx context.Context, k string) ([]json.RawMessage, error) {
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.rows[k], nil
+}
+
+// run reads the rows of the keys of a batch
+func (l *Loader[K]) run(b *batch) {
+	b.once.Do(func() {
+		l.mu.Lock()
+		if l.batch == b {
+			l.batch = nil
+		}
+		l.mu.Unlock()
+
+		b.rows, b.err = l.query(b.ctx, b.keys)
+		close(b.done)
+	})
+}
+
+// query returns the rows of the keys grouped by key
+func (l *Loader[K]) query(ctx context.Context, keys []string) (map[string][]json.RawMessage, error) {
+	vars := map[string]json.RawMessage{keysVar: jsonStrings(keys)}
+	if l.poly {
+		b, _ := json.Marshal(l.typ)
+		vars[typeVar] = b
+	}
+
+	args, err := psql.SessionArgs(l.qc, l.md, vars, l.opts.sess)
+	if err != nil {
+		return nil, fmt.Errorf("error loading rows: %w", err)
+	}
+
+	var data []byte
+	if err := l.db.QueryRow(ctx, l.stmt, args...).Scan(&data); err != nil {
+		return nil, fmt.Errorf("error loading rows: %w", err)
+	}
+
+	var res map[string][]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, fmt.Errorf("error loading rows: %w", err)
+	}
+
+	out := make(map[string][]json.RawMessage, len(keys))
+	for _, row := range res[l.root] {
+		k, err := jsonKey(row[l.key])
+		if err != nil {
+			return nil, fmt.Errorf("error loading rows: %w", err)
+		}
+
+		if l.nested == "" {
+			b, err := json.Marshal(row)
+			if err != nil {
+				return nil, fmt.Errorf("error loading rows: %w", err)
+			}
+			out[k] = append(out[k], b)
+			continue
+		}
+		// the related row is null when its filters leave it out
+		if v := row[l.nested]; len(v) != 0 && string(v) != "null" {
+			out[k] = append(out[k], v)
+		}
+	}
+	return out, nil
+}
+
+// the variables of the keys and of the type value of a polymorphic
+// relationship in the queries of the loaders
+const (
+	keysVar = "loader_keys"
+	typeVar = "loader_type"
+)
+
+// loadQuery returns the query reading the related rows of the keys with
+// the key of each row. The rows are selected from the related table by
+// the column the relationship joins on, or from the join table of a
+// many-to-many relationship with their related row nested in them
+func (l *Loader[K]) loadQuery(s *schema.DBSchema, rel schema.DBRel) (string, error) {
+	switch rel.Type {
+	case schema.RelOneToOne, schema.RelOneToMany, schema.RelRecursive,
+		schema.RelPolymorphic, schema.RelManyToMany:
+	default:
+		return "", fmt.Errorf("relationship cannot be loaded: %s", rel.String())
+	}
+	if len(rel.Left.Cols) > 1 {
+		return "", fmt.Errorf("relationship with a composite key cannot be loaded: %s", rel.String())
+	}
+	rt, rc := rel.Right.Ti, rel.Right.Col
+	if rc.Array {
+		return "", fmt.Errorf("relationship on an array column cannot be loaded: %s", rel.String())
+	}
+
+	var where, fields string
+	vars := "$" + keysVar + ": [String!]!"
+
+	switch {
+	case rel.Type == schema.RelManyToMany:
+		jt := rel.Through.Ti
+		name, err := throughRel(s, rel)
+		if err != nil {
+			return "", err
+		}
+		cols, err := l.fields(s, rt, "")
+		if err != nil {
+			return "", err
+		}
+		l.root, l.key, l.nested = jt.Name, rel.Through.ColL.Name, name
+		where = l.key + ": {in: $" + keysVar + "}"
+		fields = l.key + " " + name + " { " + cols + " }"
+
+	case rel.Type == schema.RelPolymorphic:
+		// the type value of rows pointing to a table is in its rows, the
+		// type of the rows a key is read for is not known
+		tc := rel.Poly.TypeCol
+		if tc.Table != rt.Name || tc.Schema != rt.Schema {
+			return "", fmt.Errorf("polymorphic relationship cannot be loaded by key: %s", rel.String())
+		}
+		l.root, l.key, l.poly, l.typ = rt.Name, rc.Name, true, rel.Poly.TypeValue
+		where = l.key + ": {in: $" + keysVar + "}, " + tc.Name + ": {eq: $" + typeVar + "}"
+		vars += ", $" + typeVar + ": String!"
+
+	default:
+		l.root, l.key = rt.Name, rc.Name
+		where = l.key + ": {in: $" + keysVar + "}"
+	}
+
+	if l.nested == "" {
+		var err error
+		if fields, err = l.fields(s, rt, rc.Name); err != nil {
+			return "", err
+		}
+	}
+	if !validName(l.root) {
+		return "", fmt.Errorf("table cannot be loaded: %s", l.root)
+	}
+	return "query (" + vars + ") { " + l.root + "(where: {" + where + "}) { " + fields + " } }", nil
+}
+
+// fields returns the columns of a table the role of the loader reads,
+// the key column must be one of them
+func (l *Loader[K]) fields(s *schema.DBSchema, t schema.DBTable, key string) (string, error) {
+	rt, _, err := s.RoleTable(l.opts.sess.Role, t)
+	if err != nil {
+		return "", err
+	}
+	if rt.Blocked {
+		return "", fmt.Errorf("table cannot be loaded: %s", t.Name)
+	}
+
+	var cols []string
+	found := key == ""
+	for _, c := range rt.Columns {
+		if c.Blocked || !validName(c.Name) {
+			continue
+		}
+		cols = append(cols, c.Name)
+		found = found || c.Name == key
+	}
+	if !found {
+		return "", fmt.Errorf("key column cannot be read: %s.%s", t.Name, key)
+	}
+	if len(cols) == 0 {
+		return "", fmt.Errorf("no columns of %s can be read", t.Name)
+	}
+	return strings.Join(cols, " "), nil
+}
+
+// throughRel returns the name of the relationship of the join table of a
+// many-to-many relationship to its related table
+func throughRel(s *schema.DBSchema, rel schema.DBRel) (string, error) {
+	rels, err := s.GetTableRels(rel.Through.Ti)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rels {
+		if r.Left.Col.Name == rel.Through.ColR.Name && r.Right.Ti.Name == rel.Right.Ti.Name &&
+			r.Right.Ti.Schema == rel.Right.Ti.Schema && r.Right.Col.Name == rel.Right.Col.Name {
+			return r.Name, nil
+		}
+	}
+	return "", fmt.Errorf("relationship cannot be loaded: %s", rel.String())
+}
+
+// nameRe matches the names of GraphQL fields
+var nameRe = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+// validName reports whether a table or column can be selected by name
+func validName(s string) bool {
+	return nameRe.MatchString(s)
+}
+
+// keyString returns a key as it is written by Postgres as text
+func keyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
+
+// jsonStrings returns the keys as a JSON list of strings, the list is
+// passed as a single array parameter cast to the type of the key column
+func jsonStrings(keys []string) json.RawMessage {
+	b, _ := json.Marshal(keys)
+	return b
+}
+
+// jsonKey returns the key of a row as keyString writes it, from the JSON
+// of the value of its key column
+func jsonKey(v json.RawMessage) (string, error) {
+	var s string
+	if len(v) != 0 && v[0] == '"' {
+		if err := json.Unmarshal(v, &s); err != nil {
+			return "", err
+		}
+		return s, nil
+	}
+	if len(v) == 0 {
+		return "", fmt.Errorf("row without a key")
+	}
+	return string(v), nil
+}
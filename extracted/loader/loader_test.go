@@ -0,0 +1,317 @@
+package loader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// fakeQuerier returns the same JSON for every query and keeps the
+// statements it ran with their arguments
+type fakeQuerier struct {
+	mu   sync.Mutex
+	data string
+	runs []run
+}
+
+type run struct {
+	stmt string
+	args []interface{}
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.runs = append(q.runs, run{query, args})
+	return fakeRow(q.data)
+}
+
+type fakeRow string
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	*dest[0].(*[]byte) = []byte(r)
+	return nil
+}
+
+// blogSchema returns users with their comments, tags of the comments
+// through a join table and notes pointing to users by type and id
+func blogSchema(t *testing.T, opts ...schema.Option) *schema.DBSchema {
+	t.Helper()
+	opts = append(opts, schema.WithPolymorphicRels(schema.PolymorphicRel{
+		Table:      "notes",
+		IDColumn:   "owner_id",
+		TypeColumn: "owner_type",
+		Types:      map[string]string{"user": "users"},
+	}))
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull", "org_id notnull", "deleted_at timestamptz").
+		Table("comments", "id pk", "user_id notnull", "body text notnull").
+		Table("tags", "id pk", "name text notnull").
+		Table("comment_tags", "comment_id notnull", "tag_id notnull").
+		Table("notes", "id pk", "owner_id notnull", "owner_type text notnull", "body text").
+		FK("comments.user_id", "users.id").
+		FK("comment_tags.comment_id", "comments.id").
+		FK("comment_tags.tag_id", "tags.id").
+		BuildSchema(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// rel returns the relationship of a table to another
+func rel(t *testing.T, s *schema.DBSchema, from, to string) schema.DBRel {
+	t.Helper()
+	ft, err := s.Find("", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rels, err := s.GetTableRels(ft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rels {
+		if r.Right.Ti.Name == to {
+			return r.DBRel
+		}
+	}
+	t.Fatalf("no relationship of %s to %s", from, to)
+	return schema.DBRel{}
+}
+
+func newLoader(t *testing.T, q *fakeQuerier, s *schema.DBSchema, from, to string, opts ...Option) *Loader[int64] {
+	t.Helper()
+	l, err := New[int64](q, qcode.NewCompiler(s), psql.NewCompiler(s), rel(t, s, from, to), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLoadBatches(t *testing.T) {
+	q := &fakeQuerier{data: `{"comments": [
+		{"id": 1, "user_id": 1, "body": "a"},
+		{"id": 2, "user_id": 2, "body": "b"},
+		{"id": 3, "user_id": 1, "body": "c"}]}`}
+	l := newLoader(t, q, blogSchema(t), "users", "comments")
+
+	rows, err := l.LoadMany(context.Background(), []int64{1, 2, 3, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.runs) != 1 {
+		t.Fatalf("got %d queries, want 1", len(q.runs))
+	}
+	if got := fmt.Sprint(q.runs[0].args); got != `[{"1","2","3"}]` {
+		t.Errorf("got arguments %s", got)
+	}
+
+	var n []int
+	for _, r := range rows {
+		n = append(n, len(r))
+	}
+	if fmt.Sprint(n) != "[2 1 0 2]" {
+		t.Errorf("got rows per key %v, want [2 1 0 2]", n)
+	}
+	if string(rows[1][0]) != `{"body":"b","id":2,"user_id":2}` {
+		t.Errorf("got row %s", rows[1][0])
+	}
+}
+
+func TestLoadFilters(t *testing.T) {
+	s := blogSchema(t,
+		schema.WithTableFilters(schema.TableFilter{Table: "users", Filter: `{org_id: {eq: 7}}`}),
+		schema.WithSoftDeletes(schema.SoftDelete{Table: "users"}),
+		schema.WithRoles(schema.Role{Name: "member", Tables: []schema.RoleTable{{
+			Table:  "users",
+			Filter: `{org_id: {eq: $org_id}}`,
+			Masks:  []schema.ColumnMask{{Column: "email", Mask: schema.MaskHash}},
+		}}}))
+
+	q := &fakeQuerier{data: `{"users": [{"id": 5, "email": "x"}]}`}
+	sess := psql.Session{Role: "member", Vars: map[string]interface{}{"org_id": 9}}
+	l := newLoader(t, q, s, "comments", "users", WithSession(sess))
+
+	rows, err := l.Load(context.Background(), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+
+	stmt := q.runs[0].stmt
+	for _, want := range []string{`"deleted_at"`, `"org_id"`, `= 7`, `sha256`} {
+		if !strings.Contains(stmt, want) {
+			t.Errorf("statement without %s:\n%s", want, stmt)
+		}
+	}
+	if got := fmt.Sprint(q.runs[0].args); !strings.Contains(got, "9") {
+		t.Errorf("got arguments %s without the session variable", got)
+	}
+
+	// the session variables of the role filter must be set
+	l = newLoader(t, q, s, "comments", "users", WithSession(psql.Session{Role: "member"}))
+	if _, err := l.Load(context.Background(), 5); err == nil {
+		t.Error("want an error without the session variable")
+	}
+}
+
+func TestLoadBlocked(t *testing.T) {
+	s := blogSchema(t, schema.WithRoles(schema.Role{Name: "anon", Tables: []schema.RoleTable{
+		{Table: "users", Block: true},
+		{Table: "comments", BlockColumns: []string{"user_id"}},
+	}}))
+	sess := WithSession(psql.Session{Role: "anon"})
+
+	for _, tc := range [][2]string{{"comments", "users"}, {"users", "comments"}} {
+		_, err := New[int64](&fakeQuerier{}, qcode.NewCompiler(s), psql.NewCompiler(s), rel(t, s, tc[0], tc[1]), sess)
+		if err == nil {
+			t.Errorf("%s -> %s: want an error", tc[0], tc[1])
+		}
+	}
+}
+
+func TestLoadPolymorphic(t *testing.T) {
+	s := blogSchema(t)
+	q := &fakeQuerier{data: `{"notes": [{"id": 1, "owner_id": 4, "owner_type": "user", "body": null}]}`}
+	l := newLoader(t, q, s, "users", "notes")
+
+	rows, err := l.Load(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	if !strings.Contains(q.runs[0].stmt, `"owner_type"`) {
+		t.Errorf("statement without the type column:\n%s", q.runs[0].stmt)
+	}
+	if got := fmt.Sprint(q.runs[0].args); !strings.Contains(got, "user") {
+		t.Errorf("got arguments %s without the type value", got)
+	}
+
+	// the type of the notes the keys are of is not known
+	if _, err := New[int64](q, qcode.NewCompiler(s), psql.NewCompiler(s), rel(t, s, "notes", "users")); err == nil {
+		t.Error("want an error loading by the polymorphic column")
+	}
+}
+
+func TestLoadManyToMany(t *testing.T) {
+	q := &fakeQuerier{data: `{"comment_tags": [
+		{"comment_id": 1, "tag": {"id": 1, "name": "go"}},
+		{"comment_id": 1, "tag": null},
+		{"comment_id": 2, "tag": {"id": 1, "name": "go"}}]}`}
+	s := blogSchema(t)
+	r := rel(t, s, "comments", "tags")
+	if r.Type != schema.RelManyToMany {
+		t.Fatalf("got relationship %s", r.Type)
+	}
+	l := newLoader(t, q, s, "comments", "tags")
+
+	rows, err := l.LoadMany(context.Background(), []int64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows[0]) != 1 || len(rows[1]) != 1 {
+		t.Fatalf("got rows %v", rows)
+	}
+	if string(rows[0][0]) != `{"id": 1, "name": "go"}` {
+		t.Errorf("got row %s", rows[0][0])
+	}
+}
+
+func TestLoadWide(t *testing.T) {
+	cols := []string{"id pk", "user_id notnull"}
+	for i := 0; i < 60; i++ {
+		cols = append(cols, fmt.Sprintf("c%d text", i))
+	}
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		Table("events", cols...).
+		FK("events.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := &fakeQuerier{data: `{"events": []}`}
+	l := newLoader(t, q, s, "users", "events")
+	if _, err := l.Load(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if n := maxArgs(q.runs[0].stmt); n > 100 {
+		t.Errorf("got a call with %d arguments:\n%s", n, q.runs[0].stmt)
+	}
+}
+
+// maxArgs returns the most arguments of a json_build_object call
+func maxArgs(stmt string) int {
+	max := 0
+	for i := 0; ; {
+		j := strings.Index(stmt[i:], "json_build_object(")
+		if j < 0 {
+			return max
+		}
+		i += j + len("json_build_object(")
+
+		n, depth := 1, 0
+		for k := i; k < len(stmt) && depth >= 0; k++ {
+			switch stmt[k] {
+			case '(':
+				depth++
+			case ')':
+				depth--
+			case ',':
+				if depth == 0 {
+					n++
+				}
+			}
+		}
+		if n > max {
+			max = n
+		}
+	}
+}
+
+func TestJSONKey(t *testing.T) {
+	for v, want := range map[string]string{`"a\"b"`: `a"b`, `12`: `12`, `true`: `true`} {
+		got, err := jsonKey(json.RawMessage(v))
+		if err != nil || got != want {
+			t.Errorf("jsonKey(%s) = %q, %v, want %q", v, got, err, want)
+		}
+	}
+	if _, err := jsonKey(nil); err == nil {
+		t.Error("want an error without a key")
+	}
+}
+
+func TestLoadTenant(t *testing.T) {
+	s := blogSchema(t, schema.WithTenantTemplate("public"))
+	q := &fakeQuerier{data: `{"comment_tags": []}`}
+	l := newLoader(t, q, s, "comments", "tags", WithSession(psql.Session{Tenant: "tenant_1"}))
+
+	if _, err := l.Load(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	stmt := q.runs[0].stmt
+	for _, want := range []string{`"tenant_1"."tags"`, `"tenant_1"."comment_tags"`} {
+		if !strings.Contains(stmt, want) {
+			t.Errorf("statement without %s:\n%s", want, stmt)
+		}
+	}
+	if strings.Contains(stmt, `"public"`) {
+		t.Errorf("got the template schema in:\n%s", stmt)
+	}
+}
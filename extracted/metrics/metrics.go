@@ -0,0 +1,132 @@
+// Package metrics exports the metrics of the schema layer to Prometheus,
+// the size of the graph, the FindPath latency and path cache hits, the
+// introspection duration and the schema reloads
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Prometheus is a schema.Metrics and a prometheus.Collector
+//
+//	m := metrics.NewPrometheus("graphjin")
+//	prometheus.MustRegister(m)
+//	info, err := schema.GetDBInfo(ctx, db, "postgres", nil, schema.WithInfoMetrics(m))
+//	s, err := schema.NewDBSchema(info, nil, schema.WithMetrics(m))
+type Prometheus struct {
+	tables        prometheus.Gauge
+	relationships prometheus.Gauge
+	findPath      prometheus.Histogram
+	pathCache     *prometheus.CounterVec
+	introspection *prometheus.HistogramVec
+	reloads       *prometheus.CounterVec
+}
+
+var _ schema.Metrics = (*Prometheus)(nil)
+
+// NewPrometheus returns the metrics with names prefixed by the namespace
+// eg. graphjin_schema_tables
+func NewPrometheus(namespace string) *Prometheus {
+	const sub = "schema"
+
+	return &Prometheus{
+		tables: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name: "tables",
+			Help: "Tables in the relationship graph.",
+		}),
+		relationships: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name: "relationships",
+			Help: "Edges of the relationship graph.",
+		}),
+		findPath: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name:    "find_path_duration_seconds",
+			Help:    "Time taken by FindPath.",
+			Buckets: prometheus.ExponentialBuckets(0.000001, 4, 10),
+		}),
+		pathCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name: "path_cache_requests_total",
+			Help: "FindPath calls by path cache result, a miss when the cache is off.",
+		}, []string{"result"}),
+		introspection: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name:    "introspection_duration_seconds",
+			Help:    "Time taken by GetDBInfo by database type and result.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}, []string{"db_type", "result"}),
+		reloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: sub,
+			Name: "reloads_total",
+			Help: "Schema watcher reloads by result.",
+		}, []string{"result"}),
+	}
+}
+
+// Describe sends the descriptors of the metrics
+func (m *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	m.tables.Describe(ch)
+	m.relationships.Describe(ch)
+	m.findPath.Describe(ch)
+	m.pathCache.Describe(ch)
+	m.introspection.Describe(ch)
+	m.reloads.Describe(ch)
+}
+
+// Collect sends the current values of the metrics
+func (m *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	m.tables.Collect(ch)
+	m.relationships.Collect(ch)
+	m.findPath.Collect(ch)
+	m.pathCache.Collect(ch)
+	m.introspection.Collect(ch)
+	m.reloads.Collect(ch)
+}
+
+// Introspected observes the duration of GetDBInfo, the result is
+// cached for a DBInfo read from the cache
+func (m *Prometheus) Introspected(dbType string, d time.Duration, cacheHit bool, err error) {
+	res := "ok"
+	switch {
+	case err != nil:
+		res = "error"
+	case cacheHit:
+		res = "cached"
+	}
+	m.introspection.WithLabelValues(dbType, res).Observe(d.Seconds())
+}
+
+// GraphChanged sets the size of the graph
+func (m *Prometheus) GraphChanged(tables, relationships int) {
+	m.tables.Set(float64(tables))
+	m.relationships.Set(float64(relationships))
+}
+
+// PathFound observes the FindPath latency and counts the path cache hit
+// or miss
+func (m *Prometheus) PathFound(d time.Duration, cacheHit bool, err error) {
+	m.findPath.Observe(d.Seconds())
+	if cacheHit {
+		m.pathCache.WithLabelValues("hit").Inc()
+	} else {
+		m.pathCache.WithLabelValues("miss").Inc()
+	}
+}
+
+// Reloaded counts a watcher reload as changed, unchanged or error
+func (m *Prometheus) Reloaded(changed bool, err error) {
+	res := "unchanged"
+	switch {
+	case err != nil:
+		res = "error"
+	case changed:
+		res = "changed"
+	}
+	m.reloads.WithLabelValues(res).Inc()
+}
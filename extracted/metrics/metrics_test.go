@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheus(t *testing.T) {
+	m := NewPrometheus("app")
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatal(err)
+	}
+
+	m.GraphChanged(3, 8)
+	m.PathFound(time.Millisecond, false, nil)
+	m.PathFound(time.Microsecond, true, nil)
+	m.PathFound(time.Microsecond, true, nil)
+	m.Introspected("postgres", time.Second, false, nil)
+	m.Introspected("postgres", time.Millisecond, true, nil)
+	m.Introspected("mysql", time.Second, false, errors.New("timeout"))
+	m.Reloaded(true, nil)
+	m.Reloaded(false, nil)
+	m.Reloaded(false, errors.New("timeout"))
+
+	want := `
+# HELP app_schema_path_cache_requests_total FindPath calls by path cache result, a miss when the cache is off.
+# TYPE app_schema_path_cache_requests_total counter
+app_schema_path_cache_requests_total{result="hit"} 2
+app_schema_path_cache_requests_total{result="miss"} 1
+# HELP app_schema_relationships Edges of the relationship graph.
+# TYPE app_schema_relationships gauge
+app_schema_relationships 8
+# HELP app_schema_reloads_total Schema watcher reloads by result.
+# TYPE app_schema_reloads_total counter
+app_schema_reloads_total{result="changed"} 1
+app_schema_reloads_total{result="error"} 1
+app_schema_reloads_total{result="unchanged"} 1
+# HELP app_schema_tables Tables in the relationship graph.
+# TYPE app_schema_tables gauge
+app_schema_tables 3
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"app_schema_path_cache_requests_total", "app_schema_relationships",
+		"app_schema_reloads_total", "app_schema_tables")
+	if err != nil {
+		t.Error(err)
+	}
+
+	if n := testutil.CollectAndCount(m, "app_schema_introspection_duration_seconds"); n != 3 {
+		t.Errorf("got %d introspection series, want ok, cached and error", n)
+	}
+	if n := testutil.CollectAndCount(m, "app_schema_find_path_duration_seconds"); n != 1 {
+		t.Errorf("got %d find path series", n)
+	}
+}
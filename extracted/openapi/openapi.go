@@ -0,0 +1,289 @@
+// Package openapi generates an OpenAPI 3.1 document for a DBSchema with
+// CRUD endpoints for each table and nested endpoints for the related
+// rows of each relationship, eg. /users/{id}/comments
+package openapi
+
+import (
+	"encoding/json"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+// Version is the OpenAPI version of the documents
+const Version = "3.1.0"
+
+// Option configures the generated document
+type Option func(*generator)
+
+// WithTypes maps the columns to JSON Schema types with a mapper holding
+// overrides instead of typemap.Default
+func WithTypes(m *typemap.Mapper) Option {
+	return func(g *generator) {
+		g.types = m
+	}
+}
+
+// WithInfo sets the title and version of the API, they default to the
+// name of the database and 1.0.0
+func WithInfo(title, version string) Option {
+	return func(g *generator) {
+		g.title, g.version = title, version
+	}
+}
+
+// generator holds the state of a single generation
+type generator struct {
+	s              *schema.DBSchema
+	types          *typemap.Mapper
+	title, version string
+	names          map[string]string // tables to their names in paths
+}
+
+// Generate returns the OpenAPI document of the schema as JSON
+func Generate(s *schema.DBSchema, opts ...Option) ([]byte, error) {
+	doc, err := Build(s, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Build returns the OpenAPI document of the schema. Tables and views get
+// a collection at /{table} and, with a primary key, a row at
+// /{table}/{id}, only tables can be written. The rows related to a row
+// are at /{table}/{id}/{relationship} named like the GraphQL fields of
+// GetTableRels. Tables outside the default schema are named schema_table
+func Build(s *schema.DBSchema, opts ...Option) (map[string]any, error) {
+	g := &generator{
+		s:       s,
+		types:   typemap.Default,
+		title:   s.DBName(),
+		version: "1.0.0",
+		names:   make(map[string]string),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+
+	var tables []schema.DBTable
+	for _, t := range s.GetTables() {
+		switch {
+		case t.Blocked, t.Type == "virtual", t.Type == "remote", t.Type == "function":
+			continue
+		}
+		tables = append(tables, t)
+		g.names[t.String()] = g.tableName(t)
+	}
+
+	paths := make(map[string]any)
+	schemas := make(map[string]any)
+
+	for _, t := range tables {
+		name := g.names[t.String()]
+		schemas[name] = g.rowSchema(t)
+		if writable(t) {
+			schemas[name+"Input"] = g.inputSchema(t)
+			update := g.inputSchema(t)
+			update["title"] = t.Name + " update"
+			delete(update, "required")
+			schemas[name+"Update"] = update
+		}
+
+		paths["/"+name] = g.collection(t)
+		if t.PrimaryCol.Name == "" {
+			continue
+		}
+		paths["/"+name+"/{id}"] = g.row(t)
+
+		rels, err := s.GetTableRels(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rels {
+			rn, ok := g.names[r.Right.Ti.String()]
+			if !ok || !nested(r) {
+				continue
+			}
+			paths["/"+name+"/{id}/"+r.Name] = g.related(t, r, rn)
+		}
+	}
+
+	title := g.title
+	if title == "" {
+		title = "API"
+	}
+	return map[string]any{
+		"openapi":    Version,
+		"info":       map[string]any{"title": title, "version": g.version},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}, nil
+}
+
+// tableName returns the name of a table in paths and components
+func (g *generator) tableName(t schema.DBTable) string {
+	if t.Schema == "" || t.Schema == g.s.DBSchema() {
+		return t.Name
+	}
+	return t.Schema + "_" + t.Name
+}
+
+// writable returns true if rows can be written to a table
+func writable(t schema.DBTable) bool {
+	return t.Type == "" || t.Type == "table" || t.Type == "foreign"
+}
+
+// nested returns true if a relationship has an endpoint under the rows
+// of its table, relationships the rows cannot be joined on by a key
+// are left out
+func nested(r schema.TableRel) bool {
+	switch r.Type {
+	case schema.RelOneToOne, schema.RelOneToMany, schema.RelManyToMany, schema.RelRecursive:
+		return true
+	}
+	return false
+}
+
+// rowSchema returns the schema of a row
+func (g *generator) rowSchema(t schema.DBTable) map[string]any {
+	v := g.types.TableSchema(t)
+	delete(v, "required")
+	return v
+}
+
+// inputSchema returns the schema of the body of a create, the columns
+// the database always sets are left out. An update takes it without the
+// required columns
+func (g *generator) inputSchema(t schema.DBTable) map[string]any {
+	v := g.types.TableSchema(t)
+	props := v["properties"].(map[string]any)
+	for name, p := range props {
+		if ro, _ := p.(map[string]any)["readOnly"].(bool); ro {
+			delete(props, name)
+		}
+	}
+	v["title"] = t.Name + " input"
+	return v
+}
+
+// collection returns the operations of the rows of a table
+func (g *generator) collection(t schema.DBTable) map[string]any {
+	name := g.names[t.String()]
+	ops := map[string]any{
+		"get": map[string]any{
+			"operationId": name + ".list",
+			"tags":        []string{name},
+			"parameters":  pageParams(),
+			"responses":   responses("200", "the rows", list(ref(name))),
+		},
+	}
+	if writable(t) {
+		ops["post"] = map[string]any{
+			"operationId": name + ".create",
+			"tags":        []string{name},
+			"requestBody": body(ref(name + "Input")),
+			"responses":   responses("201", "the row created", ref(name)),
+		}
+	}
+	return ops
+}
+
+// row returns the operations of a row of a table by its primary key
+func (g *generator) row(t schema.DBTable) map[string]any {
+	name := g.names[t.String()]
+	ops := map[string]any{
+		"parameters": []any{g.idParam(t)},
+		"get": map[string]any{
+			"operationId": name + ".get",
+			"tags":        []string{name},
+			"responses":   notFound(responses("200", "the row", ref(name))),
+		},
+	}
+	if writable(t) {
+		ops["patch"] = map[string]any{
+			"operationId": name + ".update",
+			"tags":        []string{name},
+			"requestBody": body(ref(name + "Update")),
+			"responses":   notFound(responses("200", "the row updated", ref(name))),
+		}
+		ops["delete"] = map[string]any{
+			"operationId": name + ".delete",
+			"tags":        []string{name},
+			"responses":   notFound(responses("200", "the row deleted", ref(name))),
+		}
+	}
+	return ops
+}
+
+// related returns the operation of the rows related to a row, a list
+// of them or the one row
+func (g *generator) related(t schema.DBTable, r schema.TableRel, rn string) map[string]any {
+	name := g.names[t.String()]
+	op := map[string]any{
+		"operationId": name + "." + r.Name,
+		"tags":        []string{name},
+	}
+	if r.Many {
+		op["parameters"] = pageParams()
+		op["responses"] = notFound(responses("200", "the related rows", list(ref(rn))))
+	} else {
+		op["responses"] = notFound(responses("200", "the related row", ref(rn)))
+	}
+	return map[string]any{
+		"parameters": []any{g.idParam(t)},
+		"get":        op,
+	}
+}
+
+// idParam returns the path parameter of the primary key of a table
+func (g *generator) idParam(t schema.DBTable) map[string]any {
+	return map[string]any{
+		"name":     "id",
+		"in":       "path",
+		"required": true,
+		"schema":   g.types.JSONSchema(t.PrimaryCol),
+	}
+}
+
+// pageParams returns the parameters paging a list of rows
+func pageParams() []any {
+	return []any{
+		map[string]any{"name": "limit", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 0}},
+		map[string]any{"name": "offset", "in": "query", "schema": map[string]any{"type": "integer", "minimum": 0}},
+	}
+}
+
+// responses returns the responses of an operation with a JSON body
+func responses(code, desc string, v map[string]any) map[string]any {
+	return map[string]any{
+		code: map[string]any{
+			"description": desc,
+			"content":     map[string]any{"application/json": map[string]any{"schema": v}},
+		},
+	}
+}
+
+// notFound adds the response of a row that does not exist
+func notFound(resp map[string]any) map[string]any {
+	resp["404"] = map[string]any{"description": "no row with the id"}
+	return resp
+}
+
+// body returns a required JSON request body
+func body(v map[string]any) map[string]any {
+	return map[string]any{
+		"required": true,
+		"content":  map[string]any{"application/json": map[string]any{"schema": v}},
+	}
+}
+
+// ref returns a reference to a schema of the components
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// list returns the schema of a list of items
+func list(v map[string]any) map[string]any {
+	return map[string]any{"type": "array", "items": v}
+}
@@ -0,0 +1,186 @@
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// blogSchema has posts of users with an identity, events of users in
+// the audit schema, logs without a primary key and a view of the active
+// users
+func blogSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("posts", "id pk", "user_id notnull", "title text").
+		Table("audit.events", "id pk", "user_id").
+		Table("logs", "msg text").
+		Table("active_users", "id pk", "email text").
+		FK("posts.user_id", "users.id").
+		FK("audit.events.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ti := range di.Tables {
+		switch ti.Name {
+		case "users":
+			di.Tables[i].Columns[0].Identity = "always"
+		case "active_users":
+			di.Tables[i].Type = "view"
+		}
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// methods returns the paths of a document with their methods
+func methods(doc map[string]any) []string {
+	var got []string
+	for p, v := range doc["paths"].(map[string]any) {
+		var ms []string
+		for m := range v.(map[string]any) {
+			if m != "parameters" {
+				ms = append(ms, m)
+			}
+		}
+		sort.Strings(ms)
+		got = append(got, p+" "+strings.Join(ms, ","))
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestBuild(t *testing.T) {
+	doc, err := Build(blogSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/active_users get",
+		"/active_users/{id} get",
+		"/audit_events get,post",
+		"/audit_events/{id} delete,get,patch",
+		"/audit_events/{id}/user get",
+		"/logs get,post",
+		"/posts get,post",
+		"/posts/{id} delete,get,patch",
+		"/posts/{id}/user get",
+		"/users get,post",
+		"/users/{id} delete,get,patch",
+		"/users/{id}/events get",
+		"/users/{id}/posts get",
+	}
+	if got := methods(doc); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if doc["openapi"] != Version {
+		t.Errorf("got version %v", doc["openapi"])
+	}
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	if _, ok := schemas["active_usersInput"]; ok {
+		t.Error("got an input of a view")
+	}
+	in := schemas["usersInput"].(map[string]any)
+	if _, ok := in["properties"].(map[string]any)["id"]; ok {
+		t.Error("got the identity in the input")
+	}
+	if req, _ := in["required"].([]string); len(req) != 1 || req[0] != "email" {
+		t.Errorf("got required %v", in["required"])
+	}
+	if _, ok := schemas["usersUpdate"].(map[string]any)["required"]; ok {
+		t.Error("got required columns in the update")
+	}
+	if _, ok := schemas["users"].(map[string]any)["required"]; ok {
+		t.Error("got required columns in the row")
+	}
+}
+
+func TestRelated(t *testing.T) {
+	doc, err := Build(blogSchema(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := doc["paths"].(map[string]any)
+
+	tests := []struct {
+		path, schema string
+	}{
+		{"/users/{id}/posts", `{"items":{"$ref":"#/components/schemas/posts"},"type":"array"}`},
+		{"/posts/{id}/user", `{"$ref":"#/components/schemas/users"}`},
+	}
+	for _, tt := range tests {
+		op := paths[tt.path].(map[string]any)["get"].(map[string]any)
+		resp := op["responses"].(map[string]any)
+		if _, ok := resp["404"]; !ok {
+			t.Errorf("%s: no response of a missing row", tt.path)
+		}
+		ok := resp["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+		b, err := json.Marshal(ok["schema"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tt.schema {
+			t.Errorf("%s: got %s, want %s", tt.path, b, tt.schema)
+		}
+	}
+
+	// the related rows are keyed by the id of the row
+	params := paths["/users/{id}/posts"].(map[string]any)["parameters"].([]any)
+	id := params[0].(map[string]any)
+	if id["name"] != "id" || id["in"] != "path" || id["required"] != true {
+		t.Errorf("got parameter %v", id)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	s := blogSchema(t)
+	b, err := Generate(s, WithInfo("Blog", "2.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Info struct {
+			Title, Version string
+		}
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Info.Title != "Blog" || doc.Info.Version != "2.0.0" {
+		t.Errorf("got info %+v", doc.Info)
+	}
+
+	// blocked tables are left out
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		Table("secrets", "id pk").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ti := range di.Tables {
+		if ti.Name == "secrets" {
+			di.Tables[i].Blocked = true
+		}
+	}
+	s, err = schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc2, err := Build(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := methods(doc2); len(got) != 2 || !strings.HasPrefix(got[0], "/users") {
+		t.Errorf("got paths %v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package pgxdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// ListenDDL listens on schema.DDLNotifyChannel on a connection given to
+// it alone and returns a channel receiving a value after the
+// notifications of DDL commands, for WatcherConfig.Notify. The
+// notifications sent while a reload runs are merged into one. Listening
+// stops when the context is cancelled or the connection fails, onError
+// is called with the error of the connection and can be nil
+//
+//	conn, err := pgx.Connect(ctx, "postgres://localhost/app")
+//	notify, err := pgxdb.ListenDDL(ctx, conn, nil)
+//	w, err := schema.NewWatcher(ctx, db, schema.WatcherConfig{DBType: "postgres", Notify: notify})
+func ListenDDL(ctx context.Context, conn *pgx.Conn, onError func(error)) (<-chan struct{}, error) {
+	stmt := "LISTEN " + pgx.Identifier{schema.DDLNotifyChannel}.Sanitize()
+	if _, err := conn.Exec(ctx, stmt); err != nil {
+		return nil, fmt.Errorf("error listening on %s: %w", schema.DDLNotifyChannel, err)
+	}
+
+	// the channel is never closed, Watcher.Run would reload in a loop
+	ch := make(chan struct{}, 1)
+	go func() {
+		for {
+			if _, err := conn.WaitForNotification(ctx); err != nil {
+				if ctx.Err() == nil && onError != nil {
+					onError(fmt.Errorf("error waiting on %s: %w", schema.DDLNotifyChannel, err))
+				}
+				return
+			}
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,52 @@
+// Package pgxdb runs the catalog queries and compiled statements on the
+// native interface of pgx, a pool, connection or transaction, so they
+// use its binary protocol and statement cache instead of database/sql
+package pgxdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Conn is the part of *pgxpool.Pool, *pgx.Conn and pgx.Tx used
+type Conn interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// New returns a Querier running on a pgx connection
+//
+//	pool, err := pgxpool.New(ctx, "postgres://localhost/app")
+//	info, err := schema.GetDBInfoFrom(ctx, pgxdb.New(pool), "postgres", nil)
+func New(c Conn) schema.Querier {
+	return querier{c}
+}
+
+type querier struct {
+	c Conn
+}
+
+func (q querier) Query(ctx context.Context, sql string, args ...interface{}) (schema.Rows, error) {
+	rows, err := q.c.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	return pgxRows{rows}, nil
+}
+
+func (q querier) QueryRow(ctx context.Context, sql string, args ...interface{}) schema.Row {
+	return q.c.QueryRow(ctx, sql, args...)
+}
+
+// pgxRows are rows of pgx, their Close returns the error of the query
+type pgxRows struct {
+	pgx.Rows
+}
+
+func (r pgxRows) Close() error {
+	r.Rows.Close()
+	return r.Rows.Err()
+}
@@ -0,0 +1,116 @@
+package pgxdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// fakeConn returns rows of a single int and keeps the statements it ran
+type fakeConn struct {
+	vals  []int
+	err   error // error of the rows once read
+	stmts []string
+}
+
+func (c *fakeConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c.stmts = append(c.stmts, sql)
+	if sql == "bad" {
+		return nil, errors.New("syntax error")
+	}
+	return &fakeRows{vals: c.vals, err: c.err, i: -1}, nil
+}
+
+func (c *fakeConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c.stmts = append(c.stmts, sql)
+	return &fakeRows{vals: c.vals}
+}
+
+// fakeRows are the pgx rows of the values, the methods not used panic
+type fakeRows struct {
+	pgx.Rows
+	vals   []int
+	err    error
+	i      int
+	closed bool
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i < len(r.vals)
+}
+
+func (r *fakeRows) Scan(dest ...any) error {
+	if r.i < 0 {
+		r.i = 0
+	}
+	if r.i >= len(r.vals) {
+		return pgx.ErrNoRows
+	}
+	*dest[0].(*int) = r.vals[r.i]
+	return nil
+}
+
+func (r *fakeRows) Err() error { return r.err }
+func (r *fakeRows) Close()     { r.closed = true }
+
+func TestQuery(t *testing.T) {
+	c := &fakeConn{vals: []int{1, 2}}
+	q := New(c)
+
+	rows, err := q.Query(context.Background(), "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, n)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[1] != 2 {
+		t.Errorf("got values %v", got)
+	}
+	if !rows.(pgxRows).Rows.(*fakeRows).closed {
+		t.Error("rows not closed")
+	}
+
+	var n int
+	if err := q.QueryRow(context.Background(), "SELECT 1").Scan(&n); err != nil || n != 1 {
+		t.Errorf("got %d, %v", n, err)
+	}
+	if len(c.stmts) != 2 || c.stmts[1] != "SELECT 1" {
+		t.Errorf("got statements %q", c.stmts)
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	c := &fakeConn{err: errors.New("connection reset")}
+	q := New(c)
+
+	if _, err := q.Query(context.Background(), "bad"); err == nil {
+		t.Error("want the error of the query")
+	}
+
+	// the error of reading the rows is returned by Close
+	rows, err := q.Query(context.Background(), "SELECT n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	if err := rows.Close(); err == nil || err.Error() != "connection reset" {
+		t.Errorf("got %v, want the error of the rows", err)
+	}
+
+	if err := q.QueryRow(context.Background(), "SELECT n").Scan(new(int)); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("got %v, want no rows", err)
+	}
+}
@@ -0,0 +1,122 @@
+package prepared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// Request is a query of a batch, its result is under Label in the
+// result of the batch
+type Request struct {
+	Label  string
+	Query  []byte
+	OpName string
+	Vars   map[string]json.RawMessage
+}
+
+// batchPart is a compiled query of a batch
+type batchPart struct {
+	qc *qcode.QCode
+	md psql.Metadata
+}
+
+// QueryBatch runs independent queries as a single statement and returns
+// an object with the result of each under its label, like a dashboard
+// making many queries in one roundtrip. The statement of a batch is
+// cached like that of a query, by the shapes of its requests
+func (c *Cache) QueryBatch(ctx context.Context, reqs []Request) (json.RawMessage, error) {
+	return c.queryBatch(ctx, psql.Session{}, false, reqs)
+}
+
+// QueryBatchSession is QueryBatch as a session, see QuerySession
+func (c *Cache) QueryBatchSession(ctx context.Context, sess psql.Session, reqs []Request) (json.RawMessage, error) {
+	return c.queryBatch(ctx, sess, true, reqs)
+}
+
+// queryBatch runs a batch, the filter variables are read from the
+// session when bound is true and from the variables otherwise
+func (c *Cache) queryBatch(ctx context.Context, sess psql.Session, bound bool, reqs []Request) (json.RawMessage, error) {
+	set, setArgs := psql.SessionSQL(sess)
+	if set != "" && c.db == nil {
+		return nil, fmt.Errorf("session settings require a cache made with New")
+	}
+
+	shapes := make([]string, len(reqs))
+	for i, r := range reqs {
+		shapes[i] = strconv.Quote(r.Label) + "=" + Shape(r.Query, r.OpName, r.Vars)
+	}
+	key := c.key(sess, "batch "+strings.Join(shapes, " "))
+
+	e, err := c.get(key, func() (*entry, error) {
+		return c.prepareBatch(key, sess, reqs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.release(e)
+
+	var args []interface{}
+	for i, p := range e.batch {
+		var a []interface{}
+		if bound {
+			a, err = psql.SessionArgs(p.qc, p.md, reqs[i].Vars, sess)
+		} else {
+			a, err = psql.Args(p.qc, p.md, reqs[i].Vars)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("batch query %s: %w", reqs[i].Label, err)
+		}
+		args = append(args, a...)
+	}
+	return c.exec(ctx, e, set, setArgs, args)
+}
+
+// prepareBatch compiles the queries of a batch for the role and tenant
+// of a session and prepares their statement
+func (c *Cache) prepareBatch(key string, sess psql.Session, reqs []Request) (*entry, error) {
+	e := &entry{key: key, batch: make([]batchPart, len(reqs))}
+	batch := make([]psql.BatchQuery, len(reqs))
+	tables := make(map[string]struct{})
+
+	for i, r := range reqs {
+		qc, err := c.qcc.CompileRole(r.Query, r.OpName, sess.Role)
+		if err != nil {
+			return nil, fmt.Errorf("batch query %s: %w", r.Label, err)
+		}
+		batch[i] = psql.BatchQuery{Label: r.Label, QC: qc}
+		e.batch[i].qc = qc
+
+		for _, t := range qc.Tables() {
+			tables[t] = struct{}{}
+		}
+	}
+
+	stmt, mds, err := c.pcc.CompileBatchString(batch, sess.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	for i, md := range mds {
+		e.batch[i].md = md
+	}
+	e.sql = stmt
+
+	for t := range tables {
+		e.tables = append(e.tables, t)
+	}
+	sort.Strings(e.tables)
+
+	if c.db != nil {
+		if e.stmt, err = c.db.Prepare(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
@@ -0,0 +1,46 @@
+package prepared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+)
+
+func TestQueryBatchGolden(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	c := newCache(t, q)
+
+	reqs := []Request{
+		{Label: "users", Query: []byte(`{ users { id } }`)},
+		{Label: "it's", Query: []byte(`query ($id: ID!) { posts(where: {user_id: {eq: $id}}) { id } }`),
+			Vars: map[string]json.RawMessage{"id": json.RawMessage(`7`)}},
+	}
+	if _, err := c.QueryBatch(context.Background(), reqs); err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "batch.sql", q.stmts[0])
+}
+
+// a batch of more queries than json_build_object takes keys is spliced
+// from several objects
+func TestQueryBatchWide(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	c := newCache(t, q)
+
+	var reqs []Request
+	for i := 0; i < 60; i++ {
+		reqs = append(reqs, Request{Label: fmt.Sprintf("q%d", i), Query: []byte(`{ users { id } }`)})
+	}
+	if _, err := c.QueryBatch(context.Background(), reqs); err != nil {
+		t.Fatal(err)
+	}
+	sql := q.stmts[0]
+	if !strings.HasPrefix(sql, "SELECT (left(json_build_object('q0', ") ||
+		!strings.Contains(sql, `::text, -1) || ', ' || substr(json_build_object('q50', `) {
+		t.Errorf("batch object is not spliced after 50 keys")
+	}
+}
@@ -0,0 +1,518 @@
+// Package prepared runs queries as prepared statements, the SQL of a
+// query is compiled and prepared once per shape and the statement is
+// reused by every request with the same shape
+package prepared
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/yourusername/graphjin-extracted/allow"
+	"github.com/yourusername/graphjin-extracted/explain"
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/router"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultSize is the number of statements a cache keeps unless it is
+// configured with WithSize
+const DefaultSize = 500
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithSize sets the number of statements kept, the least recently used
+// statement is closed when a new one is prepared over the limit
+func WithSize(n int) Option {
+	return func(c *Cache) {
+		c.size = n
+	}
+}
+
+// WithExplain explains each statement the first time it runs with the
+// values of that request, its plan is in the metadata returned by Plan.
+// It is meant for development
+func WithExplain(ex *explain.Explainer) Option {
+	return func(c *Cache) {
+		c.ex = ex
+	}
+}
+
+// Stats are the counters of a cache
+type Stats struct {
+	Hits      uint64 // requests run with a cached statement
+	Misses    uint64 // requests that compiled and prepared a statement
+	Evictions uint64 // statements closed to make room
+	Errors    uint64 // compiles or prepares that failed
+	Size      int    // statements cached
+
+	// ResultHits and ResultMisses count the queries answered from the
+	// results of WithResults and those that ran
+	ResultHits   uint64
+	ResultMisses uint64
+}
+
+// Cache is an LRU cache of prepared statements. A statement is prepared
+// on the pool and database/sql prepares it again on each connection the
+// first time it runs there, so every connection plans it once. A cache
+// made with NewFrom keeps the compiled SQL and leaves preparing it to
+// the Querier, pgx has a statement cache of its own on each connection
+type Cache struct {
+	db   *sql.DB
+	q    schema.Querier
+	qcc  *qcode.Compiler
+	pcc  *psql.Compiler
+	size int
+	ex   *explain.Explainer
+
+	results ResultStore // nil without WithResults
+
+	mu    sync.Mutex
+	fp    string     // fingerprint of the schema the statements are for
+	lru   *list.List // of *entry, most recently used first
+	items map[string]*list.Element
+	group singleflight.Group
+
+	hits, misses, evictions, errors atomic.Uint64
+	resultHits, resultMisses        atomic.Uint64
+}
+
+// entry is a cached statement with the compiled query it runs, or the
+// queries of a batch
+type entry struct {
+	key   string
+	qc    *qcode.QCode // nil for a batch
+	md    psql.Metadata
+	batch []batchPart
+	sql   string
+	stmt  *sql.Stmt // nil without a *sql.DB
+
+	// tables are read by a query and written by a mutation
+	tables []string
+
+	// plan is set once the statement is explained
+	explained sync.Once
+	plan      atomic.Pointer[explain.Plan]
+
+	// refs is the number of requests running the statement, an
+	// evicted statement is closed once the last of them is done
+	refs    int
+	evicted bool
+}
+
+// New returns a cache preparing statements on the database
+func New(db *sql.DB, qcc *qcode.Compiler, pcc *psql.Compiler, opts ...Option) *Cache {
+	c := NewFrom(schema.SQLQuerier(db), qcc, pcc, opts...)
+	c.db = db
+	return c
+}
+
+// NewFrom returns a cache running the statements with a Querier, such as
+// a pgx pool adapted by the pgxdb package
+func NewFrom(q schema.Querier, qcc *qcode.Compiler, pcc *psql.Compiler, opts ...Option) *Cache {
+	c := &Cache{
+		q:     q,
+		qcc:   qcc,
+		pcc:   pcc,
+		size:  DefaultSize,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}
+	for _, fn := range opts {
+		fn(c)
+	}
+	return c
+}
+
+// Query runs an operation of a query document with the variables and
+// returns its JSON result
+func (c *Cache) Query(ctx context.Context, query []byte, opName string, vars map[string]json.RawMessage) (json.RawMessage, error) {
+	return c.QueryTenant(ctx, "", query, opName, vars)
+}
+
+// QueryTenant runs an operation for a tenant, the tables of the tenant
+// template are read from the schema of the tenant. The statements of
+// the tenants are cached apart
+func (c *Cache) QueryTenant(
+	ctx context.Context,
+	tenant string,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (json.RawMessage, error) {
+	return c.query(ctx, psql.Session{Tenant: tenant}, false, query, opName, vars)
+}
+
+// QuerySession runs an operation as a session, compiled for its role
+// and tenant with the filter variables read from the session and not
+// from the variables of the request. The settings of the session are
+// set in a transaction run by the statement, a cache made with NewFrom
+// cannot run it
+func (c *Cache) QuerySession(
+	ctx context.Context,
+	sess psql.Session,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (json.RawMessage, error) {
+	return c.query(ctx, sess, true, query, opName, vars)
+}
+
+// query runs an operation, the filter variables are read from the
+// session when bound is true and from the variables otherwise
+func (c *Cache) query(
+	ctx context.Context,
+	sess psql.Session,
+	bound bool,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (json.RawMessage, error) {
+	set, setArgs := psql.SessionSQL(sess)
+	if set != "" && c.db == nil {
+		return nil, fmt.Errorf("session settings require a cache made with New")
+	}
+
+	key := c.key(sess, Shape(query, opName, vars))
+	e, err := c.get(key, func() (*entry, error) {
+		return c.prepare(key, sess, query, opName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	defer c.release(e)
+
+	// a Querier routing reads to replicas runs mutations on the primary
+	if e.qc.Type == qcode.QTMutation {
+		ctx = router.WithPrimary(ctx)
+	}
+
+	var args []interface{}
+	if bound {
+		args, err = psql.SessionArgs(e.qc, e.md, vars, sess)
+	} else {
+		args, err = psql.Args(e.qc, e.md, vars)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ex != nil {
+		e.explained.Do(func() {
+			if p, err := c.ex.Explain(ctx, e.qc, e.sql, args...); err == nil {
+				e.plan.Store(p)
+			}
+		})
+	}
+
+	return c.exec(ctx, e, set, setArgs, args)
+}
+
+// exec runs a statement unless its result is cached with WithResults,
+// a mutation drops the results of the tables it writes
+func (c *Cache) exec(ctx context.Context, e *entry, set string, setArgs, args []interface{}) (json.RawMessage, error) {
+	if c.results == nil {
+		return c.run(ctx, e, set, setArgs, args)
+	}
+
+	if e.qc != nil && e.qc.Type == qcode.QTMutation {
+		data, err := c.run(ctx, e, set, setArgs, args)
+		if err != nil {
+			return nil, err
+		}
+		return data, c.results.Invalidate(ctx, e.tables...)
+	}
+
+	rkey, err := resultKey(e, setArgs, args)
+	if err != nil {
+		return nil, err
+	}
+	if data, ok, err := c.results.Get(ctx, rkey); err != nil {
+		return nil, err
+	} else if ok {
+		c.resultHits.Add(1)
+		return data, nil
+	}
+	c.resultMisses.Add(1)
+
+	data, err := c.run(ctx, e, set, setArgs, args)
+	if err != nil {
+		return nil, err
+	}
+	return data, c.results.Set(ctx, rkey, data, e.tables)
+}
+
+// run runs a statement with the values, in a transaction when there are
+// settings to set
+func (c *Cache) run(ctx context.Context, e *entry, set string, setArgs, args []interface{}) (json.RawMessage, error) {
+	if set != "" {
+		return c.querySettings(ctx, e, set, setArgs, args)
+	}
+
+	var row schema.Row
+	if e.stmt != nil {
+		row = e.stmt.QueryRowContext(ctx, args...)
+	} else {
+		row = c.q.QueryRow(ctx, e.sql, args...)
+	}
+
+	var data []byte
+	err := row.Scan(&data)
+	return data, err
+}
+
+// querySettings runs a statement in a transaction after setting the
+// settings of a session
+func (c *Cache) querySettings(ctx context.Context, e *entry, set string, setArgs, args []interface{}) (json.RawMessage, error) {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, set, setArgs...); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if err := tx.StmtContext(ctx, e.stmt).QueryRowContext(ctx, args...).Scan(&data); err != nil {
+		return nil, err
+	}
+	return data, tx.Commit()
+}
+
+// Plan returns the metadata of the cached statement of a request with
+// its plan once it has run with WithExplain
+func (c *Cache) Plan(query []byte, opName string, vars map[string]json.RawMessage) (psql.Metadata, bool) {
+	c.mu.Lock()
+	el, ok := c.items[c.fp+" "+Shape(query, opName, vars)]
+	c.mu.Unlock()
+	if !ok {
+		return psql.Metadata{}, false
+	}
+
+	e := el.Value.(*entry)
+	md := e.md
+	md.Plan = e.plan.Load()
+	return md, true
+}
+
+// key returns the cache key of a request, its shape prefixed with the
+// fingerprint of the schema and the role and tenant if any. The
+// statements prepared for an older schema are closed the first time a
+// new fingerprint is seen
+func (c *Cache) key(sess psql.Session, shape string) string {
+	fp := c.qcc.Schema().Fingerprint()
+
+	c.mu.Lock()
+	if fp != c.fp {
+		for c.lru.Len() != 0 {
+			c.remove(c.lru.Front())
+		}
+		c.fp = fp
+	}
+	c.mu.Unlock()
+
+	if sess.Role != "" || sess.Tenant != "" {
+		return fp + " " + sess.Role + "/" + sess.Tenant + " " + shape
+	}
+	return fp + " " + shape
+}
+
+// get returns the statement of a key to be released once run, it is
+// prepared by prep when it is not cached. Concurrent requests for a key
+// that is not cached wait on a single prepare
+func (c *Cache) get(key string, prep func() (*entry, error)) (*entry, error) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry)
+		e.refs++
+		c.lru.MoveToFront(el)
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return e, nil
+	}
+	c.mu.Unlock()
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		c.misses.Add(1)
+		e, err := prep()
+		if err != nil {
+			c.errors.Add(1)
+			return nil, err
+		}
+		c.add(e)
+		return e, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the statement may have been evicted and closed before it
+	// was taken, it is prepared again
+	e := v.(*entry)
+	c.mu.Lock()
+	if e.evicted {
+		c.mu.Unlock()
+		return c.get(key, prep)
+	}
+	e.refs++
+	c.mu.Unlock()
+	return e, nil
+}
+
+// release ends a request running a statement
+func (c *Cache) release(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e.refs--
+	if e.evicted && e.refs == 0 {
+		e.close()
+	}
+}
+
+// prepare compiles a query for the role and tenant of a session and
+// prepares its statement
+func (c *Cache) prepare(key string, sess psql.Session, query []byte, opName string) (*entry, error) {
+	qc, err := c.qcc.CompileRole(query, opName, sess.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt, md, err := c.pcc.CompileTenantString(qc, sess.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{key: key, qc: qc, md: md, sql: stmt}
+	if qc.Type == qcode.QTMutation {
+		e.tables = qc.WriteTables()
+	} else {
+		e.tables = qc.Tables()
+	}
+	if c.db != nil {
+		if e.stmt, err = c.db.Prepare(stmt); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+// add caches a statement and closes the least recently used ones over
+// the size of the cache
+func (c *Cache) add(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[e.key] = c.lru.PushFront(e)
+
+	for c.size > 0 && c.lru.Len() > c.size {
+		el := c.lru.Back()
+		c.remove(el)
+		c.evictions.Add(1)
+	}
+}
+
+// remove drops a statement from the cache, it is closed now unless a
+// request is running it
+func (c *Cache) remove(el *list.Element) {
+	e := el.Value.(*entry)
+	c.lru.Remove(el)
+	delete(c.items, e.key)
+
+	e.evicted = true
+	if e.refs == 0 {
+		e.close()
+	}
+}
+
+// close closes the statement of an entry
+func (e *entry) close() {
+	if e.stmt != nil {
+		e.stmt.Close()
+	}
+}
+
+// Stats returns the counters of the cache
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	n := c.lru.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Errors:    c.errors.Load(),
+		Size:      n,
+
+		ResultHits:   c.resultHits.Load(),
+		ResultMisses: c.resultMisses.Load(),
+	}
+}
+
+// Close closes every cached statement
+func (c *Cache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for c.lru.Len() != 0 {
+		c.remove(c.lru.Front())
+	}
+	return nil
+}
+
+// Shape returns the cache key of a request, the hash of the operation
+// and the JSON type of each of its variables. Requests with the same
+// shape compile to the same statement
+func Shape(query []byte, opName string, vars map[string]json.RawMessage) string {
+	names := make([]string, 0, len(vars))
+	for k := range vars {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(allow.Hash(query, opName))
+	for _, k := range names {
+		sb.WriteByte(' ')
+		sb.WriteString(k)
+		sb.WriteByte(':')
+		sb.WriteString(jsonType(vars[k]))
+	}
+	return sb.String()
+}
+
+// jsonType returns the type of a JSON value
+func jsonType(v json.RawMessage) string {
+	v = json.RawMessage(strings.TrimSpace(string(v)))
+	if len(v) == 0 {
+		return "null"
+	}
+
+	switch v[0] {
+	case '"':
+		return "string"
+	case '[':
+		return "list"
+	case '{':
+		return "object"
+	case 't', 'f':
+		return "bool"
+	case 'n':
+		return "null"
+	}
+	return "number"
+}
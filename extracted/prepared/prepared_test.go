@@ -0,0 +1,235 @@
+package prepared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/explain"
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/router"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// fakeQuerier returns the same JSON for every query and keeps the
+// statements it ran with their arguments
+type fakeQuerier struct {
+	data  string
+	stmts []string
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	return nil, fmt.Errorf("unexpected query: %s", query)
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	var sb strings.Builder
+	sb.WriteString(query)
+	sb.WriteString("\n")
+	for i, a := range args {
+		fmt.Fprintf(&sb, "-- $%d = %#v\n", i+1, a)
+	}
+	q.stmts = append(q.stmts, sb.String())
+	return fakeRow(q.data)
+}
+
+type fakeRow string
+
+func (r fakeRow) Scan(dest ...interface{}) error {
+	*dest[0].(*[]byte) = []byte(r)
+	return nil
+}
+
+// newCache returns a cache on a schema of posts belonging to users
+func newCache(t *testing.T, q schema.Querier) *Cache {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("posts", "id pk", "user_id notnull", "title text notnull").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s))
+}
+
+func TestQueryGolden(t *testing.T) {
+	tests := []struct {
+		name, query string
+		vars        map[string]json.RawMessage
+	}{
+		{"query", `{ users(where: {email: {eq: "it's"}}) { id posts { title } } }`, nil},
+		{"query_vars", `query ($id: ID!, $t: String) { posts(where: {user_id: {eq: $id}, title: {neq: $t}}, limit: 5) { id } }`,
+			map[string]json.RawMessage{"id": json.RawMessage(`5`), "t": json.RawMessage(`"say \"hi\""`)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &fakeQuerier{data: `{}`}
+			c := newCache(t, q)
+
+			// the second run reuses the statement of the first
+			for i := 0; i < 2; i++ {
+				if _, err := c.Query(context.Background(), []byte(tt.query), "", tt.vars); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if st := c.Stats(); st.Misses != 1 || st.Hits != 1 {
+				t.Errorf("got %+v, want a miss and a hit", st)
+			}
+			if q.stmts[0] != q.stmts[1] {
+				t.Errorf("statements differ:\n%s\n%s", q.stmts[0], q.stmts[1])
+			}
+			golden.Check(t, tt.name+".sql", q.stmts[0])
+		})
+	}
+}
+
+// the statements of a schema are not run once it changes
+func TestQuerySchemaChange(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	c := newCache(t, q)
+	query := []byte(`{ users { id } }`)
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Query(context.Background(), query, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	notes := schema.NewDBTable("public", "notes", "", []schema.DBColumn{
+		{Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true},
+		{Name: "user_id", Type: "bigint", FKeySchema: "public", FKeyTable: "users", FKeyCol: "id"},
+	})
+	if err := c.qcc.Schema().AddTable(notes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Query(context.Background(), query, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if st := c.Stats(); st.Misses != 2 || st.Hits != 1 || st.Size != 1 {
+		t.Errorf("got %+v, want the statement prepared again", st)
+	}
+}
+
+func TestQueryExplain(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex := explain.NewFrom(&fakeQuerier{data: `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Total Cost": 12.5}}]`})
+	c := NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s), WithExplain(ex))
+	query := []byte(`{ users { id } }`)
+
+	if _, ok := c.Plan(query, "", nil); ok {
+		t.Error("got the metadata of a statement not run")
+	}
+	if _, err := c.Query(context.Background(), query, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	md, ok := c.Plan(query, "", nil)
+	if !ok || md.Plan == nil {
+		t.Fatalf("got metadata %+v, want a plan", md)
+	}
+	if md.Plan.TotalCost != 12.5 || len(md.Plan.SeqScans) != 1 {
+		t.Errorf("got plan %+v", md.Plan)
+	}
+}
+
+// the mutations of a router are run on the primary
+func TestQueryPrimary(t *testing.T) {
+	primary, replica := &fakeQuerier{data: `{}`}, &fakeQuerier{data: `{}`}
+	r := router.New(primary, router.WithReplicas(replica), router.WithHealthCheck(0, 0))
+	defer r.Close()
+	c := newCache(t, r)
+
+	for _, query := range []string{
+		`{ users { id } }`,
+		`mutation { users(insert: {email: "a"}) { id } }`,
+	} {
+		if _, err := c.Query(context.Background(), []byte(query), "", nil); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	if len(replica.stmts) != 1 || len(primary.stmts) != 1 {
+		t.Fatalf("got %d statements on the replica and %d on the primary", len(replica.stmts), len(primary.stmts))
+	}
+	if !strings.Contains(primary.stmts[0], "INSERT") {
+		t.Errorf("got statement on the primary:\n%s", primary.stmts[0])
+	}
+}
+
+// the statements of the tenants read their own schema and are cached apart
+func TestQueryTenant(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		BuildSchema(schema.WithTenantTemplate("public"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s))
+	query := []byte(`{ users { id } }`)
+
+	for _, tenant := range []string{"tenant_1", "tenant_2", "tenant_1"} {
+		if _, err := c.QueryTenant(context.Background(), tenant, query, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st := c.Stats(); st.Misses != 2 || st.Hits != 1 {
+		t.Errorf("got %+v, want a statement per tenant", st)
+	}
+	for i, want := range []string{`"tenant_1"."users"`, `"tenant_2"."users"`} {
+		if !strings.Contains(q.stmts[i], want) {
+			t.Errorf("statement without %s:\n%s", want, q.stmts[i])
+		}
+	}
+}
+
+// the filter variables are read from the session and the statements of
+// the roles are cached apart
+func TestQuerySession(t *testing.T) {
+	q := &fakeQuerier{data: `{}`}
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "org_id notnull").
+		BuildSchema(schema.WithRoles(schema.Role{Name: "member", Tables: []schema.RoleTable{{
+			Table:  "users",
+			Filter: `{org_id: {eq: $org_id}}`,
+		}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewFrom(q, qcode.NewCompiler(s), psql.NewCompiler(s))
+	query := []byte(`{ users { id } }`)
+	sess := psql.Session{Role: "member", Vars: map[string]interface{}{"org_id": 9}}
+
+	vars := map[string]json.RawMessage{"org_id": json.RawMessage(`1`)}
+	if _, err := c.QuerySession(context.Background(), sess, query, "", vars); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(q.stmts[0], `"org_id"`) || !strings.Contains(q.stmts[0], `= "9"`) {
+		t.Errorf("got statement\n%s", q.stmts[0])
+	}
+	if _, err := c.Query(context.Background(), query, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if st := c.Stats(); st.Misses != 2 {
+		t.Errorf("got %+v, want a statement per role", st)
+	}
+
+	if _, err := c.QuerySession(context.Background(), psql.Session{Role: "member"}, query, "", nil); err == nil {
+		t.Error("want an error for a session variable not set")
+	}
+	sess.Settings = map[string]string{"app.org_id": "9"}
+	if _, err := c.QuerySession(context.Background(), sess, query, "", nil); err == nil {
+		t.Error("want an error for settings without a database")
+	}
+}
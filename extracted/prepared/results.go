@@ -0,0 +1,168 @@
+package prepared
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ResultStore keeps the results of queries for WithResults. Set is given
+// the tables a result was read from and Invalidate drops the results
+// read from any of them, a Redis store can keep a set of keys for each
+// table. Errors of a store are returned by the requests
+type ResultStore interface {
+	Get(ctx context.Context, key string) (json.RawMessage, bool, error)
+	Set(ctx context.Context, key string, data json.RawMessage, tables []string) error
+	Invalidate(ctx context.Context, tables ...string) error
+}
+
+// WithResults caches the results of queries in a store, keyed by their
+// statement and its values. The results of the tables written by a
+// mutation run by the cache are dropped when it succeeds, writes made
+// elsewhere must be passed to Invalidate. A query racing a write may
+// cache a result from before it
+func WithResults(rs ResultStore) Option {
+	return func(c *Cache) {
+		c.results = rs
+	}
+}
+
+// Invalidate drops the cached results read from any of the tables, named
+// 'table' or 'schema.table'. Like live.Engine.Notify it is meant to be
+// called from a change feed
+func (c *Cache) Invalidate(ctx context.Context, tables ...string) error {
+	if c.results == nil || len(tables) == 0 {
+		return nil
+	}
+	return c.results.Invalidate(ctx, tables...)
+}
+
+// resultKey returns the key of the result of a statement run with the
+// values, the settings of a session are part of it as row level
+// security policies read them
+func resultKey(e *entry, setArgs, args []interface{}) (string, error) {
+	b, err := json.Marshal([]interface{}{setArgs, args})
+	if err != nil {
+		return "", err
+	}
+	return e.key + " " + string(b), nil
+}
+
+// MemoryResults is an in-memory ResultStore keeping the most recently
+// used results
+type MemoryResults struct {
+	size int
+	ttl  time.Duration
+
+	mu     sync.Mutex
+	lru    *list.List // of *result, most recently used first
+	items  map[string]*list.Element
+	tables map[string]map[string]struct{} // keys of the results of each table
+}
+
+// result is a cached result with the tables it was read from
+type result struct {
+	key     string
+	data    json.RawMessage
+	tables  []string
+	expires time.Time // zero without a ttl
+}
+
+// NewMemoryResults returns a store keeping up to size results for ttl,
+// a zero size or ttl is not limited
+func NewMemoryResults(size int, ttl time.Duration) *MemoryResults {
+	return &MemoryResults{
+		size:   size,
+		ttl:    ttl,
+		lru:    list.New(),
+		items:  make(map[string]*list.Element),
+		tables: make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns the result of a key unless it has expired
+func (m *MemoryResults) Get(ctx context.Context, key string) (json.RawMessage, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	r := el.Value.(*result)
+	if !r.expires.IsZero() && time.Now().After(r.expires) {
+		m.remove(el)
+		return nil, false, nil
+	}
+	m.lru.MoveToFront(el)
+	return r.data, true, nil
+}
+
+// Set keeps the result of a key, the least recently used results are
+// dropped over the size of the store
+func (m *MemoryResults) Set(ctx context.Context, key string, data json.RawMessage, tables []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.remove(el)
+	}
+
+	r := &result{key: key, data: data, tables: tables}
+	if m.ttl > 0 {
+		r.expires = time.Now().Add(m.ttl)
+	}
+	m.items[key] = m.lru.PushFront(r)
+	for _, t := range tables {
+		keys, ok := m.tables[t]
+		if !ok {
+			keys = make(map[string]struct{})
+			m.tables[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+
+	for m.size > 0 && m.lru.Len() > m.size {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+// Invalidate drops the results read from any of the tables
+func (m *MemoryResults) Invalidate(ctx context.Context, tables ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range tables {
+		for key := range m.tables[t] {
+			if el, ok := m.items[key]; ok {
+				m.remove(el)
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of results kept
+func (m *MemoryResults) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+// remove drops a result and its keys from the tables it was read from
+func (m *MemoryResults) remove(el *list.Element) {
+	r := el.Value.(*result)
+	m.lru.Remove(el)
+	delete(m.items, r.key)
+
+	for _, t := range r.tables {
+		keys := m.tables[t]
+		delete(keys, r.key)
+		if len(keys) == 0 {
+			delete(m.tables, t)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package prepared
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMemoryResults(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryResults(2, 0)
+
+	for _, key := range []string{"a", "b"} {
+		if err := m.Set(ctx, key, json.RawMessage(`"`+key+`"`), []string{"users"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Set(ctx, "c", json.RawMessage(`"c"`), []string{"posts"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the least recently used result is dropped over the size
+	if _, ok, _ := m.Get(ctx, "a"); ok || m.Len() != 2 {
+		t.Errorf("got a with %d results", m.Len())
+	}
+	if data, ok, _ := m.Get(ctx, "b"); !ok || string(data) != `"b"` {
+		t.Errorf("got %s, %v", data, ok)
+	}
+
+	if err := m.Invalidate(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := m.Get(ctx, "b"); ok {
+		t.Error("got a result of an invalidated table")
+	}
+	if _, ok, _ := m.Get(ctx, "c"); !ok {
+		t.Error("got the result of another table dropped")
+	}
+}
+
+func TestMemoryResultsTTL(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryResults(0, time.Millisecond)
+	if err := m.Set(ctx, "a", json.RawMessage(`1`), nil); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := m.Get(ctx, "a"); ok || m.Len() != 0 {
+		t.Error("got an expired result")
+	}
+}
+
+// the results of a query are cached until a mutation writes its tables
+func TestQueryResults(t *testing.T) {
+	ctx := context.Background()
+	q := &fakeQuerier{data: `{}`}
+	rs := NewMemoryResults(0, 0)
+	base := newCache(t, q)
+	c := NewFrom(q, base.qcc, base.pcc, WithResults(rs))
+
+	users := []byte(`{ users { id } }`)
+	posts := []byte(`{ posts { id } }`)
+	run := func(query string) {
+		t.Helper()
+		if _, err := c.Query(ctx, []byte(query), "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(string(users))
+	run(string(users))
+	run(string(posts))
+	if st := c.Stats(); st.ResultHits != 1 || st.ResultMisses != 2 || len(q.stmts) != 2 {
+		t.Errorf("got %+v with %d statements run", st, len(q.stmts))
+	}
+
+	// the insert drops the results of the users and keeps those of posts
+	run(`mutation { users(insert: {email: "a"}) { id } }`)
+	run(string(users))
+	run(string(posts))
+	if st := c.Stats(); st.ResultHits != 2 || st.ResultMisses != 3 {
+		t.Errorf("got %+v", st)
+	}
+
+	if err := c.Invalidate(ctx, "public.posts"); err != nil {
+		t.Fatal(err)
+	}
+	if rs.Len() != 1 {
+		t.Errorf("got %d results after invalidating posts", rs.Len())
+	}
+
+	// the values of the variables are part of the key
+	query := []byte(`query ($id: ID!) { users(id: $id) { id } }`)
+	for _, id := range []string{"1", "2", "1"} {
+		if _, err := c.Query(ctx, query, "", map[string]json.RawMessage{"id": json.RawMessage(id)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st := c.Stats(); st.ResultHits != 3 || st.ResultMisses != 5 {
+		t.Errorf("got %+v", st)
+	}
+}
@@ -0,0 +1 @@
+SELECT json_build_object('users', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "users_0"."id", 'posts', "__sj_1"."json") AS "json" FROM (SELECT "users_0"."id" FROM "public"."users" AS "users_0" WHERE ("users_0"."email" = 'it''s')) AS "users_0" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_1"."json"), '[]') AS "json" FROM (SELECT json_build_object('title', "posts_1"."title") AS "json" FROM (SELECT "posts_1"."title" FROM "public"."posts" AS "posts_1" WHERE ("users_0"."id" = "posts_1"."user_id")) AS "posts_1") AS "__sr_1") AS "__sj_1" ON true) AS "__sr_0") AS "__sj_0" ON true
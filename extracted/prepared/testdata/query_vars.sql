@@ -0,0 +1,3 @@
+SELECT json_build_object('posts', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "posts_0"."id") AS "json" FROM (SELECT "posts_0"."id" FROM "public"."posts" AS "posts_0" WHERE (("posts_0"."user_id" = $1::bigint) AND ("posts_0"."title" <> $2::text)) LIMIT 5) AS "posts_0") AS "__sr_0") AS "__sj_0" ON true
+-- $1 = "5"
+-- $2 = "say \"hi\""
@@ -0,0 +1,55 @@
+package psql
+
+import (
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// aggSQL returns a subquery aggregating the rows related to a row of a
+// selection, field i of the selection is the aggregate
+func (c *compilerContext) aggSQL(sel *qcode.Select, i int, ta string) (string, error) {
+	f := sel.Fields[i]
+	a := f.Agg
+	r := a.Rel.DBRel
+	aa := fmt.Sprintf("__ag_%d_%d", sel.ID, i)
+
+	var where string
+	if f.Where != nil {
+		v, err := c.expSQL(aa, f.Where)
+		if err != nil {
+			return "", err
+		}
+		where = " AND (" + v + ")"
+	}
+
+	fn := "count(*)"
+	if a.Func != schema.AggCount {
+		fn = string(a.Func) + "(" + colSQL(aa, a.Col) + ")"
+	}
+
+	v := "(SELECT " + fn + " FROM " + c.tableRef(r.Right.Ti) + " AS " + quoteIdent(aa)
+
+	// rows joined through a join table are counted once for each link
+	if r.Type == schema.RelManyToMany {
+		ja := fmt.Sprintf("__agt_%d_%d", sel.ID, i)
+		v += " INNER JOIN " + c.tableRef(r.Through.Ti) + " AS " + quoteIdent(ja) +
+			" ON " + keyCond(ja, r.Through.ColR, aa, r.Right.Col)
+		if f.Through != nil {
+			tv, err := c.expSQL(ja, f.Through)
+			if err != nil {
+				return "", err
+			}
+			v += " AND (" + tv + ")"
+		}
+
+		r = schema.DBRel{
+			Type:  schema.RelOneToOne,
+			Left:  r.Left,
+			Right: schema.DBRelRight{Ti: r.Through.Ti, Col: r.Through.ColL},
+		}
+		aa = ja
+	}
+	return v + " WHERE " + relCond(r, ta, aa) + where + ")", nil
+}
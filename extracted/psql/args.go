@@ -0,0 +1,127 @@
+package psql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// Args returns the values of the placeholders of a compiled statement
+// from the variables of a request, a variable that is not set takes
+// the default of the operation or else is null. Values are passed as
+// text and converted by the cast of their placeholder, lists become
+// array literals and objects their JSON text
+func Args(qc *qcode.QCode, md Metadata, vars map[string]json.RawMessage) ([]interface{}, error) {
+	args := make([]interface{}, len(md.Params))
+
+	for i, p := range md.Params {
+		if v, ok := vars[p.Name]; ok {
+			a, err := jsonArg(v)
+			if err != nil {
+				return nil, fmt.Errorf("variable $%s: %s", p.Name, err)
+			}
+			args[i] = a
+			continue
+		}
+
+		for _, v := range qc.Vars {
+			if v.Name == p.Name && v.Default != nil {
+				args[i] = valueArg(*v.Default)
+			}
+		}
+	}
+	return args, nil
+}
+
+// Redacted is logged in place of the values of redacted params
+const Redacted = "[redacted]"
+
+// LogArgs returns the values of the placeholders of a statement to log,
+// the values of the params of redacted columns are replaced by Redacted
+func LogArgs(md Metadata, args []interface{}) []interface{} {
+	out := make([]interface{}, len(args))
+	copy(out, args)
+	for i, p := range md.Params {
+		if p.Redact && i < len(out) {
+			out[i] = Redacted
+		}
+	}
+	return out
+}
+
+// jsonArg converts a JSON value to a placeholder value
+func jsonArg(v json.RawMessage) (interface{}, error) {
+	v = bytes.TrimSpace(v)
+
+	switch {
+	case len(v) == 0, v[0] == 'n':
+		return nil, nil
+	case v[0] == '"':
+		var s string
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case v[0] == '[':
+		return jsonArray(v)
+	}
+
+	// numbers, booleans and objects are passed as written
+	return string(v), nil
+}
+
+// jsonArray returns the text of a Postgres array of a JSON list, items
+// are quoted so they are read as written
+func jsonArray(v json.RawMessage) (string, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(v, &items); err != nil {
+		return "", err
+	}
+
+	vals := make([]string, len(items))
+	for i, item := range items {
+		item = bytes.TrimSpace(item)
+		if len(item) != 0 && item[0] == '[' {
+			a, err := jsonArray(item)
+			if err != nil {
+				return "", err
+			}
+			vals[i] = a
+			continue
+		}
+
+		a, err := jsonArg(item)
+		if err != nil {
+			return "", err
+		}
+		vals[i] = arrayItem(a)
+	}
+	return "{" + strings.Join(vals, ",") + "}", nil
+}
+
+// valueArg converts an operation default to a placeholder value
+func valueArg(v qcode.Value) interface{} {
+	switch v.Type {
+	case qcode.ValNull, qcode.ValNone:
+		return nil
+	case qcode.ValList:
+		vals := make([]string, len(v.List))
+		for i, item := range v.List {
+			vals[i] = arrayItem(valueArg(item))
+		}
+		return "{" + strings.Join(vals, ",") + "}"
+	}
+	return v.Val
+}
+
+// arrayItem returns an item of an array literal
+func arrayItem(v interface{}) string {
+	s, ok := v.(string)
+	if !ok {
+		return "NULL"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
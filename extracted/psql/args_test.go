@@ -0,0 +1,66 @@
+package psql
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// the params of the columns the policy redacts are not logged
+func TestLogArgs(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull", "name text").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkSensitiveColumns(schema.SensitiveColumns{Columns: map[string][]string{"users.email": {"pii"}}})
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`query ($e: String, $n: String) { users(where: {email: {eq: $e}, name: {eq: $n}}) { id } }`,
+			"e=true n=false"},
+		// the variable is compared with the email after its placeholder
+		{`query ($e: String) { users(where: {or: [{name: {eq: $e}}, {email: {eq: $e}}]}) { id } }`,
+			"e=true"},
+		{`mutation ($e: String, $n: String) { users(insert: {email: $e, name: $n}) { id } }`,
+			"e=true n=false"},
+	}
+	for _, tt := range tests {
+		qc, err := qcode.NewCompiler(s).Compile([]byte(tt.query), "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		_, md, err := NewCompiler(s).CompileString(qc)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		var got string
+		for i, p := range md.Params {
+			if i != 0 {
+				got += " "
+			}
+			got += fmt.Sprintf("%s=%v", p.Name, p.Redact)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got params %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	md := Metadata{Params: []Param{{Name: "e", Redact: true}, {Name: "n"}}}
+	args := []interface{}{"a@b.c", "Ada"}
+	if got := fmt.Sprint(LogArgs(md, args)); got != "["+Redacted+" Ada]" {
+		t.Errorf("got %s", got)
+	}
+	if args[0] != "a@b.c" {
+		t.Error("got the arguments changed")
+	}
+}
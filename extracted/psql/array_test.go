@@ -0,0 +1,68 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// tagSchema has posts with an array column of tags
+func tagSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("posts", "id pk", "title text", "tags text[]", "scores integer[]").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileArrayFilters(t *testing.T) {
+	s := tagSchema(t)
+
+	ti, err := s.Find("", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := ti.GetColumn("tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Array || c.ElemType != "text" {
+		t.Fatalf("got array %v of %q", c.Array, c.ElemType)
+	}
+
+	tests := []struct {
+		where string
+		want  string
+	}{
+		{`{tags: {contains: ["go", "sql"]}}`, `"posts_0"."tags" @> ARRAY[`},
+		{`{tags: {contained_in: ["go"]}}`, `"posts_0"."tags" <@ ARRAY[`},
+		{`{tags: {overlaps: ["go", "sql"]}}`, `"posts_0"."tags" && ARRAY[`},
+		// a single value is an array of one element
+		{`{tags: {contains: "go"}}`, `"posts_0"."tags" @> ARRAY[`},
+		{`{scores: {overlaps: $scores}}`, `"posts_0"."scores" && $1::integer[]`},
+	}
+	for _, tt := range tests {
+		sql := compileSQL(t, s, `query ($scores: [Int]) { posts(where: `+tt.where+`) { id } }`)
+		if !strings.Contains(sql, tt.want) {
+			t.Errorf("%s: statement without %s:\n%s", tt.where, tt.want, sql)
+		}
+	}
+}
+
+func TestArrayFilterErrors(t *testing.T) {
+	s := tagSchema(t)
+	for _, where := range []string{
+		`{title: {overlaps: ["a"]}}`,
+		`{tags: {overlaps: "a"}}`,
+	} {
+		_, err := qcode.NewCompiler(s).Compile([]byte(`{ posts(where: `+where+`) { id } }`), "")
+		if err == nil {
+			t.Errorf("%s: want an error", where)
+		}
+	}
+}
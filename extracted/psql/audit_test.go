@@ -0,0 +1,37 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestCompileAudit(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique", "created_at timestamptz", "updated_at timestamptz",
+			"created_by bigint").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkAuditColumns(schema.AuditColumns{})
+	s, err := schema.NewDBSchema(di, nil, schema.WithAuditUser("user_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmt := compileSQL(t, s, `mutation { users(insert: {email: "a"}) { id } }`)
+	for _, want := range []string{`"created_at"`, `"updated_at"`, `"created_by"`, "CURRENT_TIMESTAMP"} {
+		if !strings.Contains(stmt, want) {
+			t.Errorf("insert without %s:\n%s", want, stmt)
+		}
+	}
+
+	// an existing row keeps when and by whom it was created
+	stmt = compileSQL(t, s, `mutation { users(upsert: {email: "a"}) { id } }`)
+	want := `ON CONFLICT ("email") DO UPDATE SET "updated_at" = EXCLUDED."updated_at" RETURNING *`
+	if !strings.Contains(stmt, want) {
+		t.Errorf("no %s in:\n%s", want, stmt)
+	}
+}
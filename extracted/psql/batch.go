@@ -0,0 +1,73 @@
+package psql
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// BatchQuery is a query of a batch, its result is under Label in the
+// result of the batch
+type BatchQuery struct {
+	Label string
+	QC    *qcode.QCode
+}
+
+// CompileBatch writes a single statement running independent queries,
+// it returns one row with one JSON object holding the result of each
+// query under its label. The result of a query is the object Compile
+// would return for it
+//
+//	SELECT json_build_object('users', (SELECT ...), 'orders', (SELECT ...))
+//
+// The placeholders of each query follow those of the queries before it,
+// the metadata of each query lists its own. Mutations cannot be batched
+func (co *Compiler) CompileBatch(w *bytes.Buffer, batch []BatchQuery, tenant string) ([]Metadata, error) {
+	if len(batch) == 0 {
+		return nil, fmt.Errorf("no queries to batch")
+	}
+
+	seen := make(map[string]bool, len(batch))
+	mds := make([]Metadata, len(batch))
+	base := 0
+
+	w.WriteString(`SELECT `)
+	o := newJSONObject(w, len(batch))
+	for i, b := range batch {
+		switch {
+		case b.Label == "":
+			return nil, fmt.Errorf("batch query %d has no label", i)
+		case seen[b.Label]:
+			return nil, fmt.Errorf("duplicate batch label: %s", b.Label)
+		case b.QC.Type == qcode.QTMutation:
+			return nil, fmt.Errorf("mutations cannot be batched: %s", b.Label)
+		}
+		seen[b.Label] = true
+
+		o.key(b.Label)
+		w.WriteString(`(`)
+
+		md, err := co.compile(w, b.QC, tenant, base)
+		if err != nil {
+			return nil, fmt.Errorf("batch query %s: %w", b.Label, err)
+		}
+		w.WriteString(`)`)
+
+		mds[i] = md
+		base += len(md.Params)
+	}
+	o.close()
+	w.WriteString(` AS "__root"`)
+	return mds, nil
+}
+
+// CompileBatchString is CompileBatch returning the statement as a string
+func (co *Compiler) CompileBatchString(batch []BatchQuery, tenant string) (string, []Metadata, error) {
+	var w bytes.Buffer
+	mds, err := co.CompileBatch(&w, batch, tenant)
+	if err != nil {
+		return "", nil, err
+	}
+	return w.String(), mds, nil
+}
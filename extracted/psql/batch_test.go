@@ -0,0 +1,95 @@
+package psql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// the placeholders of a query follow those of the queries before it
+func TestCompileBatch(t *testing.T) {
+	s := blogSchema(t)
+	var batch []BatchQuery
+	for _, v := range []struct{ label, query string }{
+		{"users", `query ($id: ID!) { users(id: $id) { id } }`},
+		{"it's", `query ($id: ID!, $t: String) { posts(where: {user_id: {eq: $id}, title: {eq: $t}}) { id } }`},
+	} {
+		qc, err := qcode.NewCompiler(s).Compile([]byte(v.query), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch = append(batch, BatchQuery{Label: v.label, QC: qc})
+	}
+
+	sql, mds, err := NewCompiler(s).CompileBatchString(batch, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sql, `SELECT json_build_object('users', (`) || !strings.Contains(sql, `, 'it''s', (`) {
+		t.Errorf("labels are not the keys of the object:\n%s", sql)
+	}
+	for _, p := range []string{`$1::bigint`, `$2::bigint`, `$3::text`} {
+		if !strings.Contains(sql, p) {
+			t.Errorf("missing %s in:\n%s", p, sql)
+		}
+	}
+	if len(mds) != 2 || len(mds[0].Params) != 1 || len(mds[1].Params) != 2 {
+		t.Errorf("got metadata %+v", mds)
+	}
+}
+
+func TestCompileBatchErrors(t *testing.T) {
+	s := blogSchema(t)
+	compile := func(query string) *qcode.QCode {
+		qc, err := qcode.NewCompiler(s).Compile([]byte(query), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return qc
+	}
+	q := compile(`{ users { id } }`)
+	m := compile(`mutation { users(insert: {email: "a"}) { id } }`)
+
+	tests := []struct {
+		name  string
+		batch []BatchQuery
+		err   string
+	}{
+		{"empty", nil, "no queries to batch"},
+		{"no label", []BatchQuery{{QC: q}}, "batch query 0 has no label"},
+		{"duplicate", []BatchQuery{{Label: "a", QC: q}, {Label: "a", QC: q}}, "duplicate batch label: a"},
+		{"mutation", []BatchQuery{{Label: "a", QC: m}}, "mutations cannot be batched: a"},
+	}
+	for _, tt := range tests {
+		if _, _, err := NewCompiler(s).CompileBatchString(tt.batch, ""); err == nil || err.Error() != tt.err {
+			t.Errorf("%s: got %v, want %s", tt.name, err, tt.err)
+		}
+	}
+}
+
+// a batch of more queries than json_build_object takes keys is spliced
+// from several objects
+func TestCompileBatchWide(t *testing.T) {
+	s := blogSchema(t)
+
+	var batch []BatchQuery
+	for i := 0; i < 60; i++ {
+		qc, err := qcode.NewCompiler(s).Compile([]byte(`{ users { id } }`), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		batch = append(batch, BatchQuery{Label: fmt.Sprintf("q%d", i), QC: qc})
+	}
+	sql, _, err := NewCompiler(s).CompileBatchString(batch, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := maxArgs(sql); n > 100 {
+		t.Errorf("batch json_build_object called with %d arguments", n)
+	}
+	if !strings.HasSuffix(sql, `::text, 2))::json AS "__root"`) {
+		t.Errorf("batch object is not spliced:\n%s", sql)
+	}
+}
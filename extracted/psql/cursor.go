@@ -0,0 +1,113 @@
+package psql
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// EncodeCursor returns the cursor of a row from the values of the
+// columns it is ordered by, a cursor is the base64 encoded JSON array
+// of the values the same as the ones built by compiled statements
+func EncodeCursor(vals ...interface{}) (string, error) {
+	b, err := json.Marshal(vals)
+	if err != nil {
+		return "", fmt.Errorf("error encoding cursor: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor returns the values of the ordering columns held in a
+// cursor
+func DecodeCursor(cursor string) ([]json.RawMessage, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
+	}
+
+	var vals []json.RawMessage
+	if err := json.Unmarshal(b, &vals); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %s", err)
+	}
+	return vals, nil
+}
+
+// cursorSQL returns the expression building the cursor of a row, the
+// base64 encoding of Postgres wraps lines so the newlines are removed
+func cursorSQL(sel *qcode.Select, ta string) string {
+	var cols []string
+	for _, ob := range orderBy(sel) {
+		cols = append(cols, colRef(ta, obColumn(ob)))
+	}
+	return `translate(encode(convert_to(json_build_array(` + strings.Join(cols, ", ") +
+		`)::text, 'UTF8'), 'base64'), E'\n', '')`
+}
+
+// cursorWhere returns the condition selecting the rows after the
+// cursor of a page, or before it when paging backward. With the
+// ordering a, b it is a > $a OR (a = $a AND b > $b), a cursor passed
+// as a variable can be null for the first page so it is $n IS NULL OR
+// the condition
+func (c *compilerContext) cursorWhere(sel *qcode.Select, ta string) string {
+	var from, first string
+	if sel.Paging.From.Type == qcode.ValVar {
+		from = c.param(sel.Paging.From.Val, "text")
+		first = from + " IS NULL OR "
+	} else {
+		from = quoteLiteral(sel.Paging.From.Val)
+	}
+
+	ob := orderBy(sel)
+	vals := make([]string, len(ob))
+	for i, o := range ob {
+		vals[i] = `(convert_from(decode(` + from + `, 'base64'), 'UTF8')::json ->> ` +
+			strconv.Itoa(i) + `)::` + o.Col.Type
+	}
+
+	var or []string
+	for i, o := range ob {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, c.orderKeySQL(sel, ta, ob[j])+" = "+vals[j])
+		}
+
+		op := " > "
+		if descending(o.Order) != sel.Paging.Backward {
+			op = " < "
+		}
+		and = append(and, c.orderKeySQL(sel, ta, o)+op+vals[i])
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+	return first + strings.Join(or, " OR ")
+}
+
+// descending returns true for the descending sort orders
+func descending(o qcode.Order) bool {
+	switch o {
+	case qcode.OrderDesc, qcode.OrderDescNullsFirst, qcode.OrderDescNullsLast:
+		return true
+	}
+	return false
+}
+
+// reverseOrder returns the sort order listing rows in the opposite
+// order, nulls included
+func reverseOrder(o qcode.Order) qcode.Order {
+	switch o {
+	case qcode.OrderDesc:
+		return qcode.OrderAsc
+	case qcode.OrderAscNullsFirst:
+		return qcode.OrderDescNullsLast
+	case qcode.OrderAscNullsLast:
+		return qcode.OrderDescNullsFirst
+	case qcode.OrderDescNullsFirst:
+		return qcode.OrderAscNullsLast
+	case qcode.OrderDescNullsLast:
+		return qcode.OrderAscNullsFirst
+	}
+	return qcode.OrderDesc
+}
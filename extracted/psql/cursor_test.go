@@ -0,0 +1,92 @@
+package psql
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// where returns the condition of the statement of a root selection, the
+// cursor condition is the last one
+func where(sql string) string {
+	i := strings.Index(sql, ` FROM "public"."posts" AS "posts_0"`)
+	if i == -1 {
+		return ""
+	}
+	sql = sql[i:]
+	i, j := strings.Index(sql, " WHERE "), strings.Index(sql, " ORDER BY ")
+	if i == -1 || j < i {
+		return ""
+	}
+	return sql[i+len(" WHERE ") : j]
+}
+
+func TestCursorWhere(t *testing.T) {
+	const (
+		after  = `("posts_0"."id" > (convert_from(decode(%s, 'base64'), 'UTF8')::json ->> 0)::bigint)`
+		before = `("posts_0"."id" < (convert_from(decode(%s, 'base64'), 'UTF8')::json ->> 0)::bigint)`
+	)
+	sub := func(f, v string) string { return strings.ReplaceAll(f, "%s", v) }
+
+	tests := []struct {
+		name, query, where, order string
+	}{
+		{"first", `{ posts(first: 2) { id } }`, ``, `"id" ASC`},
+		{"first after null", `{ posts(first: 2, after: null) { id } }`, ``, `"id" ASC`},
+		{"first after", `{ posts(first: 2, after: "WzFd") { id } }`, "(" + sub(after, `'WzFd'`) + ")", `"id" ASC`},
+		{"first after var", `query ($c: String) { posts(first: 2, after: $c) { id } }`,
+			"($1::text IS NULL OR " + sub(after, `$1::text`) + ")", `"id" ASC`},
+		{"last", `{ posts(last: 2) { id } }`, ``, `"id" DESC`},
+		{"last before null", `{ posts(last: 2, before: null) { id } }`, ``, `"id" DESC`},
+		{"last before", `{ posts(last: 2, before: "WzFd") { id } }`, "(" + sub(before, `'WzFd'`) + ")", `"id" DESC`},
+		{"last before var", `query ($c: String) { posts(last: 2, before: $c) { id } }`,
+			"($1::text IS NULL OR " + sub(before, `$1::text`) + ")", `"id" DESC`},
+	}
+
+	s := blogSchema(t)
+	for _, tt := range tests {
+		sql := compileSQL(t, s, tt.query)
+		if got := where(sql); got != tt.where {
+			t.Errorf("%s: got condition\n%s\nwant\n%s", tt.name, got, tt.where)
+		}
+		if !strings.Contains(sql, `ORDER BY "posts_0".`+tt.order+` LIMIT 2`) {
+			t.Errorf("%s: not ordered by %s LIMIT 2:\n%s", tt.name, tt.order, sql)
+		}
+	}
+}
+
+// a null or missing cursor variable is bound to null which selects the
+// first page
+func TestCursorVarArgs(t *testing.T) {
+	s := blogSchema(t)
+	qc, err := qcode.NewCompiler(s).Compile([]byte(`query ($c: String) { posts(first: 2, after: $c) { id } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	md, err := NewCompiler(s).Compile(&b, qc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vars := range []map[string]json.RawMessage{nil, {"c": json.RawMessage(`null`)}} {
+		args, err := Args(qc, md, vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(args) != 1 || args[0] != nil {
+			t.Errorf("vars %s: got args %v, want [<nil>]", vars, args)
+		}
+	}
+
+	args, err := Args(qc, md, map[string]json.RawMessage{"c": json.RawMessage(`"WzFd"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(args) != 1 || args[0] != "WzFd" {
+		t.Errorf("got args %v, want [WzFd]", args)
+	}
+}
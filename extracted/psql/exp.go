@@ -0,0 +1,169 @@
+package psql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// expSQL returns the SQL of a filter expression on the table alias
+func (c *compilerContext) expSQL(ta string, ex *qcode.Exp) (string, error) {
+	switch ex.Op {
+	case qcode.OpAnd, qcode.OpOr:
+		sep := " AND "
+		if ex.Op == qcode.OpOr {
+			sep = " OR "
+		}
+
+		items := make([]string, 0, len(ex.Children))
+		for _, ce := range ex.Children {
+			v, err := c.expSQL(ta, ce)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, "("+v+")")
+		}
+		return strings.Join(items, sep), nil
+
+	case qcode.OpNot:
+		v, err := c.expSQL(ta, ex.Children[0])
+		if err != nil {
+			return "", err
+		}
+		return "NOT (" + v + ")", nil
+	}
+
+	col := colSQL(ta, ex.Col)
+	typ := ex.Col.Type
+	c.redactVars(ex.Col, ex.Val)
+
+	switch ex.Op {
+	case qcode.OpEquals, qcode.OpNotEquals:
+		if ex.Val.Type == qcode.ValNull {
+			if ex.Op == qcode.OpEquals {
+				return col + " IS NULL", nil
+			}
+			return col + " IS NOT NULL", nil
+		}
+	}
+
+	switch ex.Op {
+	case qcode.OpEquals:
+		return col + " = " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpNotEquals:
+		return col + " <> " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpGreaterThan:
+		return col + " > " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpGreaterOrEquals:
+		return col + " >= " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpLesserThan:
+		return col + " < " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpLesserOrEquals:
+		return col + " <= " + c.valueSQL(ex.Val, typ), nil
+	case qcode.OpLike:
+		return col + " LIKE " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpNotLike:
+		return col + " NOT LIKE " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpILike:
+		return col + " ILIKE " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpNotILike:
+		return col + " NOT ILIKE " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpRegex:
+		return col + " ~ " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpNotRegex:
+		return col + " !~ " + c.valueSQL(ex.Val, "text"), nil
+	case qcode.OpHasKey:
+		return col + " ? " + c.valueSQL(ex.Val, "text"), nil
+
+	case qcode.OpDWithin, qcode.OpSTContains, qcode.OpSTWithin, qcode.OpSTIntersects:
+		return c.spatialSQL(col, ex), nil
+
+	case qcode.OpIn, qcode.OpNotIn:
+		v := col + " = any(" + c.listSQL(ex.Val, typ) + ")"
+		if ex.Op == qcode.OpNotIn {
+			v = "NOT (" + v + ")"
+		}
+		return v, nil
+
+	case qcode.OpIsNull:
+		switch ex.Val.Type {
+		case qcode.ValVar:
+			return "(" + col + " IS NULL) = " + c.param(ex.Val.Val, "boolean"), nil
+		case qcode.ValBool:
+			if ex.Val.Val == "true" {
+				return col + " IS NULL", nil
+			}
+			return col + " IS NOT NULL", nil
+		}
+
+	case qcode.OpContains, qcode.OpContainedIn, qcode.OpOverlaps:
+		op := " @> "
+		switch ex.Op {
+		case qcode.OpContainedIn:
+			op = " <@ "
+		case qcode.OpOverlaps:
+			op = " && "
+		}
+		et := strings.TrimSuffix(typ, "[]")
+		if ex.Col.ElemType != "" {
+			et = ex.Col.ElemType
+		}
+
+		switch {
+		case ex.Val.Type == qcode.ValList:
+			return col + op + c.listSQL(ex.Val, et), nil
+		case ex.Col.Array && ex.Val.Type != qcode.ValVar:
+			// a single value is an array of one element
+			v := qcode.Value{Type: qcode.ValList, List: []qcode.Value{ex.Val}}
+			return col + op + c.listSQL(v, et), nil
+		case ex.Col.Array:
+			return col + op + c.param(ex.Val.Val, et+"[]"), nil
+		}
+		return col + op + c.valueSQL(ex.Val, typ), nil
+	}
+
+	return "", fmt.Errorf("operator cannot be compiled on %s", ex.Col.Name)
+}
+
+// valueSQL returns a constant or the placeholder of a variable cast to
+// the type
+func (c *compilerContext) valueSQL(v qcode.Value, typ string) string {
+	switch v.Type {
+	case qcode.ValVar:
+		return c.param(v.Val, typ)
+	case qcode.ValStr:
+		return quoteLiteral(v.Val)
+	case qcode.ValNum:
+		return v.Val
+	case qcode.ValBool:
+		return v.Val
+	case qcode.ValList:
+		return c.listSQL(v, strings.TrimSuffix(typ, "[]"))
+	case qcode.ValNow:
+		return "CURRENT_TIMESTAMP"
+	}
+	return "NULL"
+}
+
+// listSQL returns an array of the items of a list cast to an array of
+// the item type, a variable is expected to hold the whole array
+func (c *compilerContext) listSQL(v qcode.Value, typ string) string {
+	if v.Type == qcode.ValVar {
+		return c.param(v.Val, typ+"[]")
+	}
+
+	items := make([]string, len(v.List))
+	for i, item := range v.List {
+		items[i] = c.valueSQL(item, typ)
+	}
+	return "ARRAY[" + strings.Join(items, ", ") + "]::" + typ + "[]"
+}
+
+// intSQL returns an integer constant or the placeholder of a variable
+func (c *compilerContext) intSQL(v qcode.Value) string {
+	if v.Type == qcode.ValVar {
+		return c.param(v.Val, "integer")
+	}
+	return v.Val
+}
@@ -0,0 +1,70 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// tenantSchema has users and their posts kept apart by tenant
+func tenantSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "tenant_id notnull").
+		Table("posts", "id pk", "user_id notnull", "tenant_id notnull", "title").
+		Table("comments", "id pk", "post_id notnull", "body").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		BuildSchema(schema.WithTableFilters(
+			schema.TableFilter{Table: "users", Filter: `{ tenant_id: { eq: $tenant } }`},
+			schema.TableFilter{Table: "posts", Filter: `{ tenant_id: { eq: $tenant } }`},
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileTableFilters(t *testing.T) {
+	s := tenantSchema(t)
+	const users, posts = `"users_0"."tenant_id" = $1::bigint`, `"posts_1"."tenant_id" = $1::bigint`
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`{ users { id } }`, []string{users}},
+		{`{ users { id posts { id } } }`, []string{users, posts}},
+		// the posts are joined through to reach the users
+		{`{ comments { id users { id } } }`, []string{`AND ("__p_1_1"."tenant_id" = $1::bigint)`, `"users_1"."tenant_id" = $1::bigint`}},
+		{`{ comments { id post { id user { id } } } }`, []string{`"posts_1"."tenant_id" = $1::bigint`, `"users_2"."tenant_id" = $1::bigint`}},
+		{`mutation { posts(update: {title: "a"}, where: {id: {eq: 1}}) { id } }`, []string{`"tenant_id" = $1::bigint`}},
+		{`mutation { posts(delete: true, where: {id: {eq: 1}}) { id } }`, []string{`"tenant_id" = $1::bigint`}},
+	}
+	for _, tt := range tests {
+		sql := compileSQL(t, s, tt.query)
+		for _, want := range tt.want {
+			if !strings.Contains(sql, want) {
+				t.Errorf("%s: statement without %s:\n%s", tt.query, want, sql)
+			}
+		}
+	}
+
+	// the comments are not filtered
+	if sql := compileSQL(t, s, `{ comments { id } }`); strings.Contains(sql, "tenant_id") {
+		t.Errorf("comments filtered:\n%s", sql)
+	}
+}
+
+func TestTableFilterErrors(t *testing.T) {
+	for _, tf := range []schema.TableFilter{
+		{Table: "missing", Filter: `{ id: { eq: 1 } }`},
+		{Table: "users"},
+	} {
+		_, err := schema.NewTestSchema().Table("users", "id pk").BuildSchema(schema.WithTableFilters(tf))
+		if err == nil {
+			t.Errorf("%+v: want an error", tf)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// jsonPathSchema has orders with the country and the first item of a
+// jsonb column as pseudo-columns
+func jsonPathSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("orders", "id pk", "metadata jsonb").
+		BuildSchema(schema.WithJSONPaths(
+			schema.JSONPath{Table: "orders", Column: "metadata", Path: []string{"shipping", "country"}, Name: "country"},
+			schema.JSONPath{Table: "orders", Column: "metadata", Path: []string{"items", "0"}, Name: "first_item", Type: "jsonb"},
+			schema.JSONPath{Table: "orders", Column: "metadata", Path: []string{"total"}, Name: "total", Type: "numeric"},
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileJSONPaths(t *testing.T) {
+	s := jsonPathSchema(t)
+	sql := compileSQL(t, s, `{ orders(where: {total: {gt: 10}}, order_by: {country: asc}) { id country first_item } }`)
+
+	for _, want := range []string{
+		`("orders_0"."metadata" -> 'shipping' ->> 'country')::text AS "country"`,
+		`("orders_0"."metadata" -> 'items' -> 0)::jsonb AS "first_item"`,
+		`("orders_0"."metadata" ->> 'total')::numeric > 10`,
+		`ORDER BY ("orders_0"."metadata" -> 'shipping' ->> 'country')::text ASC`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("statement without %s:\n%s", want, sql)
+		}
+	}
+
+	// a pseudo-column is not written
+	q := `mutation { orders(insert: {country: "NL"}) { id } }`
+	if _, err := qcode.NewCompiler(s).Compile([]byte(q), ""); err == nil {
+		t.Error("want an error writing a json path column")
+	}
+}
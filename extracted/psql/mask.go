@@ -0,0 +1,23 @@
+package psql
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// maskSQL returns the masked value of a column, v is the unmasked value
+func maskSQL(col schema.DBColumn, v string) string {
+	switch col.Mask {
+	case schema.MaskNull:
+		return "NULL::" + col.Type
+
+	case schema.MaskHash:
+		return "encode(sha256(convert_to(" + v + "::text, 'UTF8')), 'hex')"
+
+	case schema.MaskPartial:
+		// short values are masked whole so little of them is shown
+		t := v + "::text"
+		return "(CASE WHEN length(" + t + ") > 8 THEN repeat('*', length(" + t + ") - 4) || right(" + t +
+			", 4) ELSE repeat('*', length(" + t + ")) END)"
+	}
+	return v
+}
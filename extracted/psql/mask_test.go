@@ -0,0 +1,66 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// compileRoleSQL returns the statement of a query compiled for a role
+func compileRoleSQL(t *testing.T, s *schema.DBSchema, query, role string) string {
+	t.Helper()
+	qc, err := qcode.NewCompiler(s).CompileRole([]byte(query), "", role)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	var b bytes.Buffer
+	if _, err := NewCompiler(s).Compile(&b, qc); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return b.String()
+}
+
+// maskSchema has users whose email, phone and ssn a support role reads
+// masked
+func maskSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text", "phone text", "ssn text").
+		BuildSchema(schema.WithRoles(schema.Role{Name: "support", Tables: []schema.RoleTable{{
+			Table: "users",
+			Masks: []schema.ColumnMask{
+				{Column: "email", Mask: schema.MaskHash},
+				{Column: "phone", Mask: schema.MaskPartial},
+				{Column: "ssn", Mask: schema.MaskNull},
+			},
+		}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileMasks(t *testing.T) {
+	s := maskSchema(t)
+	sql := compileRoleSQL(t, s, `{ users(where: {email: {eq: "a"}}, order_by: {phone: asc}) { id email phone ssn } }`, "support")
+
+	for _, want := range []string{
+		`encode(sha256(convert_to("users_0"."email"::text, 'UTF8')), 'hex') AS "email"`,
+		`repeat('*', length("users_0"."phone"::text) - 4) || right("users_0"."phone"::text, 4)`,
+		`NULL::text AS "ssn"`,
+		// filters see the masked value
+		`encode(sha256(convert_to("users_0"."email"::text, 'UTF8')), 'hex') = 'a'`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("statement without %s:\n%s", want, sql)
+		}
+	}
+
+	// other roles read the values
+	if sql := compileSQL(t, s, `{ users { id email } }`); strings.Contains(sql, "sha256") {
+		t.Errorf("email masked without the role:\n%s", sql)
+	}
+}
@@ -0,0 +1,53 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestCompileMerged(t *testing.T) {
+	orders, err := schema.NewTestSchema().
+		Table("orders", "id pk", "user_id notnull").
+		Table("items", "id pk", "order_id notnull").
+		FK("items.order_id", "orders.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := schema.NewTestSchema().Table("users", "id pk").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di, err := schema.MergeDBInfo(orders.Namespaced("orders"), auth.Namespaced("auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vr, err := schema.NewVirtualRel("orders:public.orders.user_id", "auth:public.users.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.NewDBSchema(di, nil, schema.WithVirtualRels(vr))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the namespace is not part of the statement
+	sql := compileSQL(t, s, `{ orders { id items { id } } }`)
+	if !strings.Contains(sql, `FROM "public"."orders"`) || strings.Contains(sql, "orders:") {
+		t.Errorf("got statement:\n%s", sql)
+	}
+
+	// a statement runs against one database
+	qc, err := qcode.NewCompiler(s).Compile([]byte(`{ orders { id user { id } } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := NewCompiler(s).Compile(&b, qc); err == nil {
+		t.Errorf("want an error for tables in two databases")
+	}
+}
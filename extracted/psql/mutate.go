@@ -0,0 +1,430 @@
+package psql
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// renderMutations writes the WITH clause of a mutation with a CTE for
+// each write, a row a write points to is written before it so its key
+// can be read from the earlier CTE
+func (c *compilerContext) renderMutations() error {
+	c.w.WriteString(`WITH `)
+	n := 0
+
+	err := c.walkMutations(func(m *qcode.Mutate) error {
+		if n != 0 {
+			c.w.WriteString(`, `)
+		}
+		n++
+
+		c.w.WriteString(quoteIdent(cteName(m)))
+		c.w.WriteString(` AS (`)
+		if err := c.renderWrite(m); err != nil {
+			return err
+		}
+		c.w.WriteString(`)`)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.w.WriteString(` `)
+	return nil
+}
+
+// walkMutations calls fn for each write in the order they are run and
+// records the CTEs the selections read the written rows from
+func (c *compilerContext) walkMutations(fn func(m *qcode.Mutate) error) error {
+	c.ctes = make(map[string][]string)
+	c.roots = make(map[string][]string)
+
+	for i := range c.qc.Mutates {
+		m := &c.qc.Mutates[i]
+		if m.ParentID != -1 {
+			continue
+		}
+		if err := c.walkMutate(m, fn); err != nil {
+			return err
+		}
+		k := m.Ti.String()
+		c.roots[k] = append(c.roots[k], cteName(m))
+	}
+	return nil
+}
+
+// walkMutate calls fn for a write and its nested writes, those written
+// before it first
+func (c *compilerContext) walkMutate(m *qcode.Mutate, fn func(m *qcode.Mutate) error) error {
+	for _, id := range m.Children {
+		if cm := &c.qc.Mutates[id]; writtenBefore(cm) {
+			if err := c.walkMutate(cm, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := fn(m); err != nil {
+		return err
+	}
+
+	if m.Type != qcode.MTDelete {
+		k := m.Ti.String()
+		c.ctes[k] = append(c.ctes[k], cteName(m))
+	}
+
+	for _, id := range m.Children {
+		if cm := &c.qc.Mutates[id]; !writtenBefore(cm) {
+			if err := c.walkMutate(cm, fn); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderWrite writes the statement of a write returning the rows it
+// wrote
+func (c *compilerContext) renderWrite(m *qcode.Mutate) error {
+	switch m.Type {
+	case qcode.MTInsert, qcode.MTUpsert:
+		return c.renderInsert(m)
+	case qcode.MTUpdate:
+		return c.renderUpdate(m)
+	case qcode.MTDelete:
+		return c.renderDelete(m)
+	case qcode.MTConnect, qcode.MTDisconnect:
+		return c.renderLink(m)
+	}
+	return nil
+}
+
+// writtenBefore returns true if a nested write must come before its
+// parent, new or connected rows the parent points to are needed before
+// the parent can be written
+func writtenBefore(m *qcode.Mutate) bool {
+	switch m.Type {
+	case qcode.MTInsert, qcode.MTUpsert, qcode.MTConnect:
+		return !m.ParentFirst()
+	}
+	return false
+}
+
+// colValue is a column set to an SQL expression
+type colValue struct {
+	col string
+	val string
+}
+
+// writeValues returns the columns set by a write including the foreign
+// keys linking it to its parent and nested writes, along with the CTEs
+// those keys are read from
+func (c *compilerContext) writeValues(m *qcode.Mutate) ([]colValue, []string) {
+	var links []colValue
+	var from []string
+
+	if m.ParentID != -1 && m.ParentFirst() && m.Type != qcode.MTUpdate {
+		pm := &c.qc.Mutates[m.ParentID]
+		links = append(links, colValue{m.Rel.Right.Col.Name, colRef(cteName(pm), m.Rel.Left.Col.Name)})
+		from = append(from, cteName(pm))
+	}
+
+	for _, id := range m.Children {
+		if cm := &c.qc.Mutates[id]; writtenBefore(cm) {
+			links = append(links, colValue{cm.Rel.Left.Col.Name, colRef(cteName(cm), cm.Rel.Right.Col.Name)})
+			from = append(from, cteName(cm))
+		}
+	}
+
+	var vals []colValue
+	for _, mc := range m.Cols {
+		linked := false
+		for _, l := range links {
+			linked = linked || l.col == mc.Col.Name
+		}
+		if !linked {
+			c.redactVars(mc.Col, mc.Val)
+			vals = append(vals, colValue{mc.Col.Name, c.writeValueSQL(mc.Val, mc.Col.Type)})
+		}
+	}
+	return append(vals, links...), from
+}
+
+// writeValueSQL returns a value written to a column, constants are cast
+// to the column type as the values of an INSERT ... SELECT are not
+// typed by the columns they are inserted into
+func (c *compilerContext) writeValueSQL(v qcode.Value, typ string) string {
+	switch v.Type {
+	case qcode.ValStr, qcode.ValNull:
+		return c.valueSQL(v, typ) + "::" + typ
+	}
+	return c.valueSQL(v, typ)
+}
+
+// renderFromList writes a FROM clause of CTEs
+func (c *compilerContext) renderFromList(from []string) {
+	for i, name := range from {
+		if i == 0 {
+			c.w.WriteString(` FROM `)
+		} else {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(quoteIdent(name))
+	}
+}
+
+// renderInsert writes an insert, an upsert updates the row that
+// conflicts on the primary key or a unique column it sets
+func (c *compilerContext) renderInsert(m *qcode.Mutate) error {
+	vals, from := c.writeValues(m)
+
+	c.w.WriteString(`INSERT INTO `)
+	c.w.WriteString(c.tableRef(m.Ti))
+	c.w.WriteString(` (`)
+	for i, v := range vals {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(quoteIdent(v.col))
+	}
+	c.w.WriteString(`) SELECT `)
+	for i, v := range vals {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(v.val)
+	}
+	c.renderFromList(from)
+
+	if m.Type == qcode.MTUpsert {
+		target, err := conflictTarget(m, vals)
+		if err != nil {
+			return err
+		}
+
+		c.w.WriteString(` ON CONFLICT `)
+		c.w.WriteString(target.String())
+		c.w.WriteString(` DO UPDATE SET `)
+
+		// the key is only set again when nothing else is
+		key := make(map[string]bool, len(target.Columns))
+		for _, col := range target.Columns {
+			key[col] = true
+		}
+
+		// the row keeps when and by whom it was created
+		created := make(map[string]bool)
+		for _, mc := range m.Cols {
+			if mc.Col.Audit == schema.AuditCreatedAt || mc.Col.Audit == schema.AuditCreatedBy {
+				created[mc.Col.Name] = true
+			}
+		}
+
+		onlyKey := true
+		for _, v := range vals {
+			onlyKey = onlyKey && (key[v.col] || created[v.col])
+		}
+
+		n := 0
+		for _, v := range vals {
+			if key[v.col] && !onlyKey || created[v.col] && !key[v.col] {
+				continue
+			}
+			if n != 0 {
+				c.w.WriteString(`, `)
+			}
+			n++
+			c.w.WriteString(quoteIdent(v.col))
+			c.w.WriteString(` = EXCLUDED.`)
+			c.w.WriteString(quoteIdent(v.col))
+		}
+	}
+
+	c.w.WriteString(` RETURNING *`)
+	return nil
+}
+
+// conflictTarget returns what an upsert conflicts on, the primary key
+// when it is set or else a unique index of the columns set
+func conflictTarget(m *qcode.Mutate, vals []colValue) (schema.ConflictTarget, error) {
+	cols := make([]string, len(vals))
+	for i, v := range vals {
+		cols[i] = v.col
+	}
+
+	target, err := m.Ti.ConflictTarget(cols...)
+	if err != nil {
+		return target, fmt.Errorf("upsert on %s requires a primary key or unique column value", m.Ti.Name)
+	}
+	return target, nil
+}
+
+// renderUpdate writes an update, a nested update only changes the rows
+// related to the rows of its parent
+func (c *compilerContext) renderUpdate(m *qcode.Mutate) error {
+	ta := mutateAlias(m)
+	vals, from := c.writeValues(m)
+
+	var where []string
+	if m.ParentID != -1 {
+		pm := &c.qc.Mutates[m.ParentID]
+		where = append(where, keyCond(cteName(pm), m.Rel.Left.Col, ta, m.Rel.Right.Col))
+		from = append(from, cteName(pm))
+	}
+	if m.Where != nil {
+		v, err := c.expSQL(ta, m.Where)
+		if err != nil {
+			return err
+		}
+		where = append(where, v)
+	}
+
+	// without values the rows are only selected so nested writes
+	// can be linked to them
+	if len(vals) == 0 {
+		c.w.WriteString(`SELECT `)
+		c.w.WriteString(quoteIdent(ta))
+		c.w.WriteString(`.* FROM `)
+		c.w.WriteString(c.tableRef(m.Ti))
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(ta))
+		for _, name := range from {
+			c.w.WriteString(`, `)
+			c.w.WriteString(quoteIdent(name))
+		}
+		c.renderWhere(where)
+		return nil
+	}
+
+	c.w.WriteString(`UPDATE `)
+	c.w.WriteString(c.tableRef(m.Ti))
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(` SET `)
+	c.renderSet(vals)
+	c.renderFromList(from)
+	c.renderWhere(where)
+	c.w.WriteString(` RETURNING `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(`.*`)
+	return nil
+}
+
+// renderDelete writes a delete
+func (c *compilerContext) renderDelete(m *qcode.Mutate) error {
+	ta := mutateAlias(m)
+
+	v, err := c.expSQL(ta, m.Where)
+	if err != nil {
+		return err
+	}
+
+	c.w.WriteString(`DELETE FROM `)
+	c.w.WriteString(c.tableRef(m.Ti))
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(ta))
+	c.renderWhere([]string{v})
+	c.w.WriteString(` RETURNING `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(`.*`)
+	return nil
+}
+
+// renderLink writes a connect or disconnect, when the parent points to
+// the connected row it is only selected for the parent to read its key,
+// otherwise the foreign key of the row is set or cleared
+func (c *compilerContext) renderLink(m *qcode.Mutate) error {
+	ta := mutateAlias(m)
+
+	v, err := c.expSQL(ta, m.Where)
+	if err != nil {
+		return err
+	}
+	where := []string{v}
+
+	if !m.ParentFirst() {
+		c.w.WriteString(`SELECT `)
+		c.w.WriteString(quoteIdent(ta))
+		c.w.WriteString(`.* FROM `)
+		c.w.WriteString(c.tableRef(m.Ti))
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(ta))
+		c.renderWhere(where)
+		c.w.WriteString(` LIMIT 1`)
+		return nil
+	}
+
+	pm := &c.qc.Mutates[m.ParentID]
+	pc := cteName(pm)
+	val := colRef(pc, m.Rel.Left.Col.Name)
+
+	if m.Type == qcode.MTDisconnect {
+		val = "NULL"
+		where = append(where, keyCond(pc, m.Rel.Left.Col, ta, m.Rel.Right.Col))
+	}
+
+	c.w.WriteString(`UPDATE `)
+	c.w.WriteString(c.tableRef(m.Ti))
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(` SET `)
+	c.renderSet([]colValue{{m.Rel.Right.Col.Name, val}})
+	c.renderFromList([]string{pc})
+	c.renderWhere(where)
+	c.w.WriteString(` RETURNING `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(`.*`)
+	return nil
+}
+
+// renderSet writes the assignments of an update
+func (c *compilerContext) renderSet(vals []colValue) {
+	for i, v := range vals {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(quoteIdent(v.col))
+		c.w.WriteString(` = `)
+		c.w.WriteString(v.val)
+	}
+}
+
+// renderWhere writes the conditions joined with an and
+func (c *compilerContext) renderWhere(where []string) {
+	for i, v := range where {
+		if i == 0 {
+			c.w.WriteString(` WHERE `)
+		} else {
+			c.w.WriteString(` AND `)
+		}
+		c.w.WriteString(`(`)
+		c.w.WriteString(v)
+		c.w.WriteString(`)`)
+	}
+}
+
+// cteSource returns the CTEs a selection of a mutation reads its rows
+// from, root selections return the rows of the root writes and nested
+// ones the rows written to their table if any
+func (c *compilerContext) cteSource(sel *qcode.Select) []string {
+	if c.qc.Type != qcode.QTMutation {
+		return nil
+	}
+	if sel.ParentID == -1 {
+		return c.roots[sel.Ti.String()]
+	}
+	return c.ctes[sel.Ti.String()]
+}
+
+// cteName returns the name of the CTE of a write
+func cteName(m *qcode.Mutate) string {
+	return m.Ti.Name + "_m" + strconv.Itoa(int(m.ID))
+}
+
+// mutateAlias returns the alias of the table written by a write
+func mutateAlias(m *qcode.Mutate) string {
+	return "__mt_" + strconv.Itoa(int(m.ID))
+}
@@ -0,0 +1,112 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// blogSchema has posts belonging to users and comments belonging to
+// posts, its primary keys are not unique keys as in an introspected
+// Postgres schema
+func blogSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("posts", "id pk", "user_id notnull", "title text notnull", "published boolean").
+		Table("comments", "id pk", "post_id notnull", "body").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ti := range di.Tables {
+		for j, c := range ti.Columns {
+			if c.PrimaryKey {
+				di.Tables[i].Columns[j].UniqueKey = false
+			}
+		}
+		di.Tables[i].PrimaryCol.UniqueKey = false
+	}
+
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// compileSQL returns the statement of a query on a schema
+func compileSQL(t *testing.T, s *schema.DBSchema, query string) string {
+	t.Helper()
+	qc, err := qcode.NewCompiler(s).Compile([]byte(query), "")
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	var b bytes.Buffer
+	if _, err := NewCompiler(s).Compile(&b, qc); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return b.String()
+}
+
+// the CTE of a row is written before the CTEs of the rows pointing to it
+func TestNestedInsertOrder(t *testing.T) {
+	tests := []struct {
+		query string
+		ctes  []string // in the order they must be written
+	}{
+		{
+			`mutation { users(insert: {email: "a", posts: [{title: "x"}, {title: "y"}]}) { id } }`,
+			[]string{
+				`"users_m0" AS (INSERT INTO "public"."users"`,
+				`"posts_m1" AS (INSERT INTO "public"."posts" ("title", "user_id") SELECT 'x'::text, "users_m0"."id" FROM "users_m0"`,
+				`"posts_m2" AS (INSERT INTO "public"."posts" ("title", "user_id") SELECT 'y'::text, "users_m0"."id" FROM "users_m0"`,
+			},
+		},
+		{
+			`mutation { posts(insert: {title: "x", user: {email: "a"}}) { id } }`,
+			[]string{
+				`"users_m1" AS (INSERT INTO "public"."users"`,
+				`"posts_m0" AS (INSERT INTO "public"."posts" ("title", "user_id") SELECT 'x'::text, "users_m1"."id" FROM "users_m1"`,
+			},
+		},
+		{
+			`mutation { posts(insert: {title: "x", user: {email: "a"}, comments: [{body: "b"}]}) { id } }`,
+			[]string{
+				`"users_m1" AS (INSERT INTO "public"."users"`,
+				`"posts_m0" AS (INSERT INTO "public"."posts"`,
+				`"comments_m2" AS (INSERT INTO "public"."comments" ("body", "post_id") SELECT 'b'::text, "posts_m0"."id" FROM "posts_m0"`,
+			},
+		},
+		{
+			`mutation { users(insert: {email: "a", posts: [{title: "x", comments: [{body: "b"}]}]}) { id } }`,
+			[]string{
+				`"users_m0" AS (INSERT INTO "public"."users"`,
+				`"posts_m1" AS (INSERT INTO "public"."posts"`,
+				`"comments_m2" AS (INSERT INTO "public"."comments" ("body", "post_id") SELECT 'b'::text, "posts_m1"."id" FROM "posts_m1"`,
+			},
+		},
+	}
+
+	s := blogSchema(t)
+	for _, tt := range tests {
+		sql := compileSQL(t, s, tt.query)
+		last := -1
+		for _, cte := range tt.ctes {
+			i := strings.Index(sql, cte)
+			if i == -1 {
+				t.Errorf("%s: missing %s in:\n%s", tt.query, cte, sql)
+				break
+			}
+			if i < last {
+				t.Errorf("%s: %s written too early in:\n%s", tt.query, cte, sql)
+			}
+			last = i
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package psql compiles QCode into a single Postgres statement that
+// returns the whole nested result as one JSON value
+package psql
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/explain"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Metadata describes the parameters of a compiled statement
+type Metadata struct {
+	// Params are the query variables in the order of the $n
+	// placeholders, each variable has a single placeholder
+	Params []Param
+
+	// Plan is the estimated cost of the statement, set in development
+	// by an explain.Explainer
+	Plan *explain.Plan
+}
+
+// Param is a query variable bound to a placeholder
+type Param struct {
+	Name string
+	Type string // database type the value is cast to
+
+	// Redact is true when the value is compared with or written to a
+	// column the policy of the schema redacts, it is left out of logs
+	Redact bool
+}
+
+// Compiler compiles QCode for a schema, it is safe for concurrent use
+type Compiler struct {
+	s *schema.DBSchema
+}
+
+// NewCompiler returns a compiler for a schema
+func NewCompiler(s *schema.DBSchema) *Compiler {
+	return &Compiler{s: s}
+}
+
+// compilerContext holds the state of a single compilation
+type compilerContext struct {
+	w      *bytes.Buffer
+	qc     *qcode.QCode
+	md     Metadata
+	params map[string]int
+	redact map[string]bool // variables of redacted columns
+	s      *schema.DBSchema
+	tenant string // schema of the tenant the tables are read from
+
+	// base is the number of placeholders of the queries before this
+	// one in a batch
+	base int
+
+	// ctes are the names of the mutation CTEs returning the rows
+	// written to a table, roots only those of the root writes
+	ctes  map[string][]string
+	roots map[string][]string
+}
+
+// Compile writes the SQL statement for an operation, the statement
+// returns a single row with a single JSON column holding an object with
+// a key for each root selection. Mutations are written as CTEs and
+// their selections read the written rows from them
+func (co *Compiler) Compile(w *bytes.Buffer, qc *qcode.QCode) (Metadata, error) {
+	return co.CompileTenant(w, qc, "")
+}
+
+// CompileTenant writes the SQL statement of an operation for a tenant,
+// the tables of the tenant template of the schema are read from the
+// schema of the tenant (see schema.WithTenantTemplate)
+func (co *Compiler) CompileTenant(w *bytes.Buffer, qc *qcode.QCode, tenant string) (Metadata, error) {
+	return co.compile(w, qc, tenant, 0)
+}
+
+// compile writes the statement of an operation with its placeholders
+// numbered from base+1
+func (co *Compiler) compile(w *bytes.Buffer, qc *qcode.QCode, tenant string, base int) (Metadata, error) {
+	c := &compilerContext{
+		w:      w,
+		qc:     qc,
+		params: make(map[string]int),
+		s:      co.s.Snapshot(),
+		tenant: tenant,
+		base:   base,
+	}
+
+	if err := checkNamespace(qc); err != nil {
+		return Metadata{}, err
+	}
+
+	if qc.Type == qcode.QTMutation {
+		if err := c.renderMutations(); err != nil {
+			return Metadata{}, err
+		}
+	}
+
+	if err := c.renderRoot(); err != nil {
+		return Metadata{}, err
+	}
+	return c.md, nil
+}
+
+// checkNamespace returns an error when the tables of an operation are
+// in more than one of the databases merged into the schema, a statement
+// runs against a single database
+func checkNamespace(qc *qcode.QCode) error {
+	var ns string
+	var nt string
+
+	check := func(t schema.DBTable) error {
+		if t.Name == "" {
+			return nil
+		}
+		v, _ := schema.SplitNamespace(t.Schema)
+		if nt == "" {
+			ns, nt = v, t.Name
+			return nil
+		}
+		if v != ns {
+			return fmt.Errorf("tables %s and %s are in different databases", nt, t.Name)
+		}
+		return nil
+	}
+
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+		if err := check(sel.Ti); err != nil {
+			return err
+		}
+		for _, r := range sel.Path {
+			for _, t := range []schema.DBTable{r.Left.Ti, r.Right.Ti, r.Through.Ti} {
+				if err := check(t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// CompileString is Compile returning the statement as a string
+func (co *Compiler) CompileString(qc *qcode.QCode) (string, Metadata, error) {
+	return co.CompileTenantString(qc, "")
+}
+
+// CompileTenantString is CompileTenant returning the statement as a string
+func (co *Compiler) CompileTenantString(qc *qcode.QCode, tenant string) (string, Metadata, error) {
+	var w bytes.Buffer
+	md, err := co.CompileTenant(&w, qc, tenant)
+	if err != nil {
+		return "", md, err
+	}
+	return w.String(), md, nil
+}
+
+// param returns the placeholder of a variable, the type is the one of
+// the first use of the variable
+func (c *compilerContext) param(name, typ string) string {
+	n, ok := c.params[name]
+	if !ok {
+		c.md.Params = append(c.md.Params, Param{Name: name, Type: typ, Redact: c.redact[name]})
+		n = len(c.md.Params)
+		c.params[name] = n
+	}
+	return fmt.Sprintf("$%d::%s", c.base+n, c.md.Params[n-1].Type)
+}
+
+// redactVars marks the variables of a value compared with or written to
+// a column the policy redacts, before or after their placeholders are
+// written
+func (c *compilerContext) redactVars(col schema.DBColumn, v qcode.Value) {
+	if c.s == nil || !c.s.Handling(col).Redact {
+		return
+	}
+	if v.Type == qcode.ValVar {
+		if c.redact == nil {
+			c.redact = make(map[string]bool)
+		}
+		c.redact[v.Val] = true
+		if n, ok := c.params[v.Val]; ok {
+			c.md.Params[n-1].Redact = true
+		}
+	}
+	for _, item := range v.List {
+		c.redactVars(col, item)
+	}
+}
+
+// quoteIdent quotes an identifier
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteLiteral quotes a string constant
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// colRef returns a column qualified by a table alias
+func colRef(alias, col string) string {
+	return quoteIdent(alias) + "." + quoteIdent(col)
+}
+
+// tableRef returns a schema qualified table name, the tables of the
+// tenant template are in the schema of the tenant
+func (c *compilerContext) tableRef(t schema.DBTable) string {
+	sn := t.Schema
+	if c.s != nil {
+		sn = c.s.TenantSchema(sn, c.tenant)
+	}
+	// the namespace of a merged database is not part of its tables
+	_, sn = schema.SplitNamespace(sn)
+	if sn == "" {
+		return quoteIdent(t.Name)
+	}
+	return quoteIdent(sn) + "." + quoteIdent(t.Name)
+}
+
+// tableAlias returns the alias of the table of a selection
+func tableAlias(sel *qcode.Select) string {
+	return sel.Ti.Name + "_" + strconv.Itoa(int(sel.ID))
+}
@@ -0,0 +1,800 @@
+package psql
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// renderRoot writes the outer statement that joins the root selections
+// into a single JSON object
+func (c *compilerContext) renderRoot() error {
+	if len(c.qc.Roots) == 0 {
+		return fmt.Errorf("no selections to compile")
+	}
+
+	pairs := 0
+	for _, id := range c.qc.Roots {
+		pairs += selKeys(&c.qc.Selects[id])
+	}
+
+	c.w.WriteString(`SELECT `)
+	o := newJSONObject(c.w, pairs)
+	for _, id := range c.qc.Roots {
+		c.renderSelKeys(o, &c.qc.Selects[id])
+	}
+	o.close()
+	c.w.WriteString(` AS "__root" FROM (SELECT true) AS "__root_x"`)
+
+	for _, id := range c.qc.Roots {
+		if err := c.renderLateral(&c.qc.Selects[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderSelKeys writes the keys and values of a selection in the
+// object of its parent, a list paged with a cursor adds its cursor
+func (c *compilerContext) renderSelKeys(o *jsonObject, sel *qcode.Select) {
+	o.key(sel.FieldName)
+	c.w.WriteString(colRef(subAlias(sel), "json"))
+
+	if sel.Paging.Cursor {
+		o.key(sel.FieldName + qcode.CursorSuffix)
+		c.w.WriteString(colRef(subAlias(sel), "cursor"))
+	}
+}
+
+// selKeys returns the number of keys renderSelKeys writes
+func selKeys(sel *qcode.Select) int {
+	if sel.Paging.Cursor {
+		return 2
+	}
+	return 1
+}
+
+// renderRemoteKey writes the key of the rows of a remote table in place
+// of its selection, the rows are fetched by it after the query runs
+func (c *compilerContext) renderRemoteKey(o *jsonObject, sel *qcode.Select, ta string) {
+	o.key(sel.FieldName)
+	c.renderCondValue(sel.Conds, `to_json(`+colRef(ta, sel.Path[0].Left.Col.Name)+`)`)
+}
+
+// renderLateral writes a selection as a lateral join
+func (c *compilerContext) renderLateral(sel *qcode.Select) error {
+	c.w.WriteString(` LEFT OUTER JOIN LATERAL (`)
+	if err := c.renderSelect(sel); err != nil {
+		return err
+	}
+	c.w.WriteString(`) AS `)
+	c.w.WriteString(quoteIdent(subAlias(sel)))
+	c.w.WriteString(` ON true`)
+	return nil
+}
+
+// renderSelect writes a query returning the JSON of a selection in a
+// json column, lists are aggregated in the order of the selection
+func (c *compilerContext) renderSelect(sel *qcode.Select) error {
+	if sel.Singular {
+		return c.renderRow(sel)
+	}
+
+	ra := "__sr_" + strconv.Itoa(int(sel.ID))
+
+	c.w.WriteString(`SELECT coalesce(json_agg(`)
+	c.w.WriteString(colRef(ra, "json"))
+
+	for i, ob := range orderBy(sel) {
+		if i == 0 {
+			c.w.WriteString(` ORDER BY `)
+		} else {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(colRef(ra, "__ob_"+strconv.Itoa(i)))
+		c.w.WriteString(orderSQL(ob.Order))
+	}
+
+	c.w.WriteString(`), '[]') AS "json"`)
+
+	// the cursor of a page is the one of its last row in the direction
+	// of paging, it is null for an empty page
+	if sel.Paging.Cursor {
+		c.w.WriteString(`, (array_agg(`)
+		c.w.WriteString(colRef(ra, "__cursor"))
+		for i, ob := range orderBy(sel) {
+			if i == 0 {
+				c.w.WriteString(` ORDER BY `)
+			} else {
+				c.w.WriteString(`, `)
+			}
+			if !sel.Paging.Backward {
+				ob.Order = reverseOrder(ob.Order)
+			}
+			c.w.WriteString(colRef(ra, "__ob_"+strconv.Itoa(i)))
+			c.w.WriteString(orderSQL(ob.Order))
+		}
+		c.w.WriteString(`))[1] AS "cursor"`)
+	}
+
+	c.w.WriteString(` FROM (`)
+	if err := c.renderRow(sel); err != nil {
+		return err
+	}
+	c.w.WriteString(`) AS `)
+	c.w.WriteString(quoteIdent(ra))
+	return nil
+}
+
+// renderRow writes a query returning a row with the JSON object of each
+// row of a selection and the columns it is ordered by
+func (c *compilerContext) renderRow(sel *qcode.Select) error {
+	ta := tableAlias(sel)
+
+	pairs := len(sel.Fields)
+	for _, id := range sel.Children {
+		if child := &c.qc.Selects[id]; child.Ti.Type == "remote" {
+			pairs++
+		} else {
+			pairs += selKeys(child)
+		}
+	}
+
+	c.w.WriteString(`SELECT `)
+	o := newJSONObject(c.w, pairs)
+	for i, f := range sel.Fields {
+		o.key(f.Name)
+
+		var v string
+		switch f.Type {
+		case qcode.FieldTypename:
+			v = quoteLiteral(sel.Ti.Name)
+		case qcode.FieldAgg:
+			var err error
+			if v, err = c.aggSQL(sel, i, ta); err != nil {
+				return err
+			}
+		case qcode.FieldSearchRank:
+			v = colRef(ta, rankColumn)
+		default:
+			v = colRef(ta, f.Col.Name)
+			// PostGIS values are returned as GeoJSON
+			if f.Col.GeoType != "" {
+				v = "ST_AsGeoJSON(" + v + ")::json"
+			}
+		}
+		c.renderCondValue(f.Conds, v)
+	}
+
+	for _, id := range sel.Children {
+		child := &c.qc.Selects[id]
+		if child.Ti.Type == "remote" {
+			c.renderRemoteKey(o, child, ta)
+			continue
+		}
+		c.renderSelKeys(o, child)
+	}
+	o.close()
+	c.w.WriteString(` AS "json"`)
+
+	if !sel.Singular {
+		for i, ob := range orderBy(sel) {
+			c.w.WriteString(`, `)
+			c.w.WriteString(colRef(ta, obColumn(ob)))
+			c.w.WriteString(` AS `)
+			c.w.WriteString(quoteIdent("__ob_" + strconv.Itoa(i)))
+		}
+		if sel.Paging.Cursor {
+			c.w.WriteString(`, `)
+			c.w.WriteString(cursorSQL(sel, ta))
+			c.w.WriteString(` AS "__cursor"`)
+		}
+	}
+
+	c.w.WriteString(` FROM (`)
+	if err := c.renderBase(sel); err != nil {
+		return err
+	}
+	c.w.WriteString(`) AS `)
+	c.w.WriteString(quoteIdent(ta))
+
+	for _, id := range sel.Children {
+		if c.qc.Selects[id].Ti.Type == "remote" {
+			continue
+		}
+		if err := c.renderLateral(&c.qc.Selects[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCondValue writes a value that is null when the @skip or
+// @include conditions leave it out
+func (c *compilerContext) renderCondValue(conds []qcode.Cond, v string) {
+	if len(conds) == 0 {
+		c.w.WriteString(v)
+		return
+	}
+	c.w.WriteString(`(CASE WHEN `)
+	c.renderConds(conds)
+	c.w.WriteString(` THEN `)
+	c.w.WriteString(v)
+	c.w.WriteString(` END)`)
+}
+
+// renderConds writes the conditions that must hold for a field or
+// selection to be included
+func (c *compilerContext) renderConds(conds []qcode.Cond) {
+	for i, cd := range conds {
+		if i != 0 {
+			c.w.WriteString(` AND `)
+		}
+		if cd.Skip {
+			c.w.WriteString(`NOT `)
+		}
+		c.w.WriteString(c.param(cd.Var, "boolean"))
+	}
+}
+
+// renderBase writes the query selecting the rows of the table of a
+// selection along with their relationship to the parent row
+func (c *compilerContext) renderBase(sel *qcode.Select) error {
+	ta := tableAlias(sel)
+
+	if sel.Recursive != nil {
+		if err := c.renderRecursive(sel); err != nil {
+			return err
+		}
+	}
+
+	c.w.WriteString(`SELECT `)
+	if len(sel.DistinctOn) != 0 {
+		c.w.WriteString(`DISTINCT ON (`)
+		for i, col := range sel.DistinctOn {
+			if i != 0 {
+				c.w.WriteString(`, `)
+			}
+			c.w.WriteString(colSQL(ta, col))
+		}
+		c.w.WriteString(`) `)
+	}
+
+	for i, col := range c.baseColumns(sel) {
+		if i != 0 {
+			c.w.WriteString(`, `)
+		}
+		c.w.WriteString(colSQL(ta, col))
+		if len(col.JSONPath) != 0 || col.Mask != "" {
+			c.w.WriteString(` AS `)
+			c.w.WriteString(quoteIdent(col.Name))
+		}
+	}
+	if sel.Search != nil {
+		c.w.WriteString(`, `)
+		c.w.WriteString(c.rankSQL(sel, ta))
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(rankColumn))
+	}
+
+	c.w.WriteString(` FROM `)
+	var where []string
+
+	switch {
+	case sel.Recursive != nil:
+		c.w.WriteString(quoteIdent(recursiveName(sel)))
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(ta))
+
+	case len(sel.Path) == 0:
+		if err := c.renderFrom(sel, ta); err != nil {
+			return err
+		}
+
+	default:
+		parent := &c.qc.Selects[sel.ParentID]
+		conds, err := c.renderPath(sel, tableAlias(parent), ta)
+		if err != nil {
+			return err
+		}
+		where = append(where, conds...)
+	}
+
+	if sel.Where != nil {
+		v, err := c.expSQL(ta, sel.Where)
+		if err != nil {
+			return err
+		}
+		where = append(where, v)
+	}
+	if sel.Search != nil {
+		where = append(where, c.searchWhere(sel, ta))
+	}
+	if sel.Paging.From.Type != qcode.ValNone {
+		where = append(where, c.cursorWhere(sel, ta))
+	}
+
+	if len(where) != 0 || len(sel.Conds) != 0 {
+		c.w.WriteString(` WHERE `)
+		for i, v := range where {
+			if i != 0 {
+				c.w.WriteString(` AND `)
+			}
+			c.w.WriteString(`(`)
+			c.w.WriteString(v)
+			c.w.WriteString(`)`)
+		}
+		if len(sel.Conds) != 0 {
+			if len(where) != 0 {
+				c.w.WriteString(` AND `)
+			}
+			c.renderConds(sel.Conds)
+		}
+	}
+
+	for i, ob := range orderBy(sel) {
+		if i == 0 {
+			c.w.WriteString(` ORDER BY `)
+		} else {
+			c.w.WriteString(`, `)
+		}
+		// a backward page takes the rows before the cursor nearest to it
+		// first, the list is put back in order when aggregated
+		if sel.Paging.Backward {
+			ob.Order = reverseOrder(ob.Order)
+		}
+		c.w.WriteString(c.orderKeySQL(sel, ta, ob))
+		c.w.WriteString(orderSQL(ob.Order))
+	}
+
+	switch {
+	case sel.Singular:
+		c.w.WriteString(` LIMIT 1`)
+	case sel.Paging.Limit.Type != qcode.ValNone:
+		c.w.WriteString(` LIMIT `)
+		c.w.WriteString(c.intSQL(sel.Paging.Limit))
+	}
+
+	if sel.Paging.Offset.Type != qcode.ValNone {
+		c.w.WriteString(` OFFSET `)
+		c.w.WriteString(c.intSQL(sel.Paging.Offset))
+	}
+	return nil
+}
+
+// renderFrom writes the table of a selection, function tables are
+// called with the selection arguments
+func (c *compilerContext) renderFrom(sel *qcode.Select, ta string) error {
+	if ctes := c.cteSource(sel); len(ctes) != 0 {
+		if len(ctes) == 1 {
+			c.w.WriteString(quoteIdent(ctes[0]))
+		} else {
+			c.w.WriteString(`(`)
+			for i, name := range ctes {
+				if i != 0 {
+					c.w.WriteString(` UNION ALL `)
+				}
+				c.w.WriteString(`SELECT * FROM `)
+				c.w.WriteString(quoteIdent(name))
+			}
+			c.w.WriteString(`)`)
+		}
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(ta))
+		return nil
+	}
+
+	switch sel.Ti.Type {
+	case "virtual":
+		return fmt.Errorf("virtual table cannot be selected directly: %s", sel.Ti.Name)
+
+	case "function":
+		c.w.WriteString(c.tableRef(sel.Ti))
+		c.w.WriteString(`(`)
+		for i, a := range sel.Args {
+			if i != 0 {
+				c.w.WriteString(`, `)
+			}
+			if a.Param.Name != "" {
+				c.w.WriteString(quoteIdent(a.Param.Name))
+				c.w.WriteString(` => `)
+			}
+			c.w.WriteString(c.valueSQL(a.Val, a.Param.Type))
+		}
+		c.w.WriteString(`)`)
+
+	default:
+		c.w.WriteString(c.tableRef(sel.Ti))
+	}
+
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(ta))
+	return nil
+}
+
+// renderPath writes the table of a nested selection joined with the
+// tables between it and the parent, it returns the conditions linking
+// the rows to the parent row. Hop i of the path goes from the table
+// aliased __p_<id>_<i> (the parent for the first hop) to the table of
+// the next hop (this table for the last hop)
+func (c *compilerContext) renderPath(sel *qcode.Select, pa, ta string) ([]string, error) {
+	last := len(sel.Path) - 1
+	alias := func(i int) string {
+		switch {
+		case i == 0:
+			return pa
+		case i > last:
+			return ta
+		}
+		return fmt.Sprintf("__p_%d_%d", sel.ID, i)
+	}
+
+	if r := sel.Path[0]; r.Type == schema.RelEmbedded {
+		if last != 0 {
+			return nil, fmt.Errorf("embedded table cannot be joined through: %s", r.Right.Ti.Name)
+		}
+		return nil, c.renderEmbedded(sel, r, pa, ta)
+	}
+
+	if err := c.renderFrom(sel, ta); err != nil {
+		return nil, err
+	}
+
+	var where []string
+	for i := last; i >= 0; i-- {
+		r := sel.Path[i]
+		ra := alias(i + 1)
+
+		switch r.Type {
+		case schema.RelOneToOne, schema.RelOneToMany, schema.RelRecursive,
+			schema.RelPolymorphic, schema.RelManyToMany:
+		default:
+			return nil, fmt.Errorf("relationship cannot be compiled: %s", r.String())
+		}
+		if r.Right.Ti.Type == "virtual" || r.Left.Ti.Type == "virtual" {
+			return nil, fmt.Errorf("virtual table cannot be joined: %s", r.Right.Ti.Name)
+		}
+
+		var jf qcode.JoinFilter
+		if len(sel.JoinFilters) != 0 {
+			jf = sel.JoinFilters[i]
+		}
+
+		if r.Type == schema.RelManyToMany {
+			ja := fmt.Sprintf("__t_%d_%d", sel.ID, i)
+			c.w.WriteString(` INNER JOIN `)
+			c.w.WriteString(c.tableRef(r.Through.Ti))
+			c.w.WriteString(` AS `)
+			c.w.WriteString(quoteIdent(ja))
+			c.w.WriteString(` ON `)
+			c.w.WriteString(keyCond(ja, r.Through.ColR, ra, r.Right.Col))
+			if err := c.renderJoinFilter(ja, jf.Through); err != nil {
+				return nil, err
+			}
+
+			// the rest of the hop joins the left table to the join table
+			// which points to it with ColL
+			r = schema.DBRel{
+				Type:  schema.RelOneToOne,
+				Left:  r.Left,
+				Right: schema.DBRelRight{Ti: r.Through.Ti, Col: r.Through.ColL},
+			}
+			ra = ja
+		}
+
+		cond := relCond(r, alias(i), ra)
+		if i == 0 {
+			where = append(where, cond)
+			continue
+		}
+
+		c.w.WriteString(` INNER JOIN `)
+		c.w.WriteString(c.tableRef(r.Left.Ti))
+		c.w.WriteString(` AS `)
+		c.w.WriteString(quoteIdent(alias(i)))
+		c.w.WriteString(` ON `)
+		c.w.WriteString(cond)
+		if err := c.renderJoinFilter(alias(i), jf.Left); err != nil {
+			return nil, err
+		}
+	}
+	return where, nil
+}
+
+// renderJoinFilter adds the filter of a table to the join or where
+// condition just written
+func (c *compilerContext) renderJoinFilter(ta string, ex *qcode.Exp) error {
+	if ex == nil {
+		return nil
+	}
+	v, err := c.expSQL(ta, ex)
+	if err != nil {
+		return err
+	}
+	c.w.WriteString(` AND (`)
+	c.w.WriteString(v)
+	c.w.WriteString(`)`)
+	return nil
+}
+
+// renderEmbedded writes the rows of a table embedded as JSON in a
+// column of the parent table
+func (c *compilerContext) renderEmbedded(sel *qcode.Select, r schema.DBRel, pa, ta string) error {
+	fn := "json_to_recordset"
+	if sel.Singular {
+		fn = "json_to_record"
+	}
+	if strings.HasPrefix(r.Left.Col.Type, "jsonb") {
+		fn = "jsonb" + strings.TrimPrefix(fn, "json")
+	}
+
+	c.w.WriteString(fn)
+	c.w.WriteString(`(`)
+	c.w.WriteString(colRef(pa, r.Left.Col.Name))
+	c.w.WriteString(`) AS `)
+	c.w.WriteString(quoteIdent(ta))
+	c.w.WriteString(`(`)
+	n := 0
+	for _, col := range sel.Ti.Columns {
+		if len(col.JSONPath) != 0 {
+			continue
+		}
+		if n != 0 {
+			c.w.WriteString(`, `)
+		}
+		n++
+		c.w.WriteString(quoteIdent(col.Name))
+		c.w.WriteString(` `)
+		c.w.WriteString(col.Type)
+	}
+	c.w.WriteString(`)`)
+	return nil
+}
+
+// colSQL returns a column of a table alias, a JSON path column is read
+// from its json column with -> up to the last key and ->> for it unless
+// the value is cast to a json type. Masked columns are masked
+func colSQL(ta string, col schema.DBColumn) string {
+	if col.Mask != "" {
+		return maskSQL(col, rawColSQL(ta, col))
+	}
+	return rawColSQL(ta, col)
+}
+
+// rawColSQL returns the unmasked value of a column
+func rawColSQL(ta string, col schema.DBColumn) string {
+	if len(col.JSONPath) == 0 {
+		return colRef(ta, col.Name)
+	}
+
+	jsonType := strings.HasPrefix(col.Type, "json")
+	var sb strings.Builder
+	sb.WriteString(`(`)
+	sb.WriteString(colRef(ta, col.JSONCol))
+
+	for i, k := range col.JSONPath {
+		if i == len(col.JSONPath)-1 && !jsonType {
+			sb.WriteString(` ->> `)
+		} else {
+			sb.WriteString(` -> `)
+		}
+		if _, err := strconv.Atoi(k); err == nil {
+			sb.WriteString(k)
+		} else {
+			sb.WriteString(quoteLiteral(k))
+		}
+	}
+
+	sb.WriteString(`)::`)
+	sb.WriteString(col.Type)
+	return sb.String()
+}
+
+// relCond returns the condition joining the left and right tables of a
+// relationship, array columns match any of their values
+func relCond(r schema.DBRel, la, ra string) string {
+	lcols, rcols := r.Left.Cols, r.Right.Cols
+	if len(lcols) == 0 || len(lcols) != len(rcols) {
+		lcols = []schema.DBColumn{r.Left.Col}
+		rcols = []schema.DBColumn{r.Right.Col}
+	}
+
+	var conds []string
+	for i := range lcols {
+		conds = append(conds, keyCond(la, lcols[i], ra, rcols[i]))
+	}
+
+	if p := r.Poly; p.TypeValue != "" {
+		pa := ra
+		if p.TypeCol.Table == r.Left.Ti.Name && p.TypeCol.Schema == r.Left.Ti.Schema {
+			pa = la
+		}
+		conds = append(conds, colRef(pa, p.TypeCol.Name)+" = "+quoteLiteral(p.TypeValue))
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// keyCond returns the condition matching two key columns
+func keyCond(la string, lc schema.DBColumn, ra string, rc schema.DBColumn) string {
+	l, r := colRef(la, lc.Name), colRef(ra, rc.Name)
+
+	switch {
+	case lc.Array && !rc.Array:
+		return r + " = any(" + l + ")"
+	case rc.Array && !lc.Array:
+		return l + " = any(" + r + ")"
+	}
+	return l + " = " + r
+}
+
+// baseColumns returns the columns of a table needed by a selection,
+// its fields, ordering and the relationships of its children
+func (c *compilerContext) baseColumns(sel *qcode.Select) []schema.DBColumn {
+	var cols []schema.DBColumn
+	seen := make(map[string]struct{})
+
+	add := func(col schema.DBColumn) {
+		if _, ok := seen[col.Name]; !ok {
+			seen[col.Name] = struct{}{}
+			cols = append(cols, col)
+		}
+	}
+
+	for _, f := range sel.Fields {
+		switch f.Type {
+		case qcode.FieldCol:
+			add(f.Col)
+		case qcode.FieldAgg:
+			add(f.Agg.Rel.Left.Col)
+		}
+	}
+	for _, ob := range orderBy(sel) {
+		if !ob.Rank {
+			add(ob.Col)
+		}
+	}
+
+	for _, id := range sel.Children {
+		r := c.qc.Selects[id].Path[0]
+		if len(r.Left.Cols) > 1 {
+			for _, col := range r.Left.Cols {
+				add(col)
+			}
+		} else {
+			add(r.Left.Col)
+		}
+
+		tc := r.Poly.TypeCol
+		if r.Poly.TypeValue != "" && tc.Table == sel.Ti.Name && tc.Schema == sel.Ti.Schema {
+			add(tc)
+		}
+
+		// the parents of a row are found from its foreign key
+		if rc := c.qc.Selects[id].Recursive; rc != nil && rc.Find == qcode.FindParents {
+			add(rc.Ref.Col)
+		}
+	}
+
+	if len(cols) == 0 {
+		if pk := sel.Ti.PrimaryCol; pk.Name != "" {
+			add(pk)
+		} else if len(sel.Ti.Columns) != 0 {
+			add(sel.Ti.Columns[0])
+		}
+	}
+	return cols
+}
+
+// orderBy returns the ordering of a selection, the distinct on columns
+// are put first as Postgres requires them to lead the ordering
+func orderBy(sel *qcode.Select) []qcode.OrderBy {
+	if len(sel.DistinctOn) == 0 {
+		return sel.OrderBy
+	}
+
+	var ob []qcode.OrderBy
+	for _, col := range sel.DistinctOn {
+		o := qcode.OrderBy{Col: col}
+		for _, v := range sel.OrderBy {
+			if v.Col.Name == col.Name {
+				o = v
+			}
+		}
+		ob = append(ob, o)
+	}
+
+	for _, v := range sel.OrderBy {
+		dup := false
+		for _, col := range sel.DistinctOn {
+			dup = dup || v.Col.Name == col.Name
+		}
+		if !dup {
+			ob = append(ob, v)
+		}
+	}
+	return ob
+}
+
+// orderSQL returns the SQL of a sort order
+func orderSQL(o qcode.Order) string {
+	switch o {
+	case qcode.OrderDesc:
+		return " DESC"
+	case qcode.OrderAscNullsFirst:
+		return " ASC NULLS FIRST"
+	case qcode.OrderAscNullsLast:
+		return " ASC NULLS LAST"
+	case qcode.OrderDescNullsFirst:
+		return " DESC NULLS FIRST"
+	case qcode.OrderDescNullsLast:
+		return " DESC NULLS LAST"
+	}
+	return " ASC"
+}
+
+// subAlias returns the alias of the lateral join of a selection
+func subAlias(sel *qcode.Select) string {
+	return "__sj_" + strconv.Itoa(int(sel.ID))
+}
+
+// maxObjectKeys is the number of keys a json_build_object call takes at
+// most, Postgres functions are limited to 100 arguments
+const maxObjectKeys = 50
+
+// jsonObject writes a JSON object built with json_build_object. An
+// object with more keys than a call takes is built by several calls
+// whose text is spliced into one object, jsonb concatenation would lose
+// the order of the keys
+//
+//	(left(json_build_object(...)::text, -1) || ', ' || substr(json_build_object(...)::text, 2))::json
+type jsonObject struct {
+	w    *bytes.Buffer
+	keys int // the number of keys of the object
+	n    int // the number of keys written
+}
+
+// newJSONObject starts an object with a number of keys
+func newJSONObject(w *bytes.Buffer, keys int) *jsonObject {
+	if keys > maxObjectKeys {
+		w.WriteString(`(left(`)
+	}
+	w.WriteString(`json_build_object(`)
+	return &jsonObject{w: w, keys: keys}
+}
+
+// key writes the next key, its value is written after it
+func (o *jsonObject) key(k string) {
+	switch {
+	case o.n == 0:
+	case o.n%maxObjectKeys == 0:
+		// the first call keeps its opening brace, the last its closing one
+		if o.n == maxObjectKeys {
+			o.w.WriteString(`)::text, -1) || ', ' || `)
+		} else {
+			o.w.WriteString(`)::text, -1), 2) || ', ' || `)
+		}
+		if o.n+maxObjectKeys >= o.keys {
+			o.w.WriteString(`substr(json_build_object(`)
+		} else {
+			o.w.WriteString(`substr(left(json_build_object(`)
+		}
+	default:
+		o.w.WriteString(`, `)
+	}
+	o.n++
+	o.w.WriteString(quoteLiteral(k))
+	o.w.WriteString(`, `)
+}
+
+// close ends the object
+func (o *jsonObject) close() {
+	if o.keys > maxObjectKeys {
+		o.w.WriteString(`)::text, 2))::json`)
+		return
+	}
+	o.w.WriteString(`)`)
+}
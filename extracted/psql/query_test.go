@@ -0,0 +1,222 @@
+package psql
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestCompileGolden(t *testing.T) {
+	tests := []struct {
+		name, query string
+	}{
+		{"number", `{ users(where: {id: {eq: 5}}) { id email } }`},
+		{"bool", `{ posts(where: {published: {eq: true}}, limit: 10) { id title } }`},
+		{"string", `{ posts(where: {title: {eq: "it's \"new\""}}) { id } }`},
+		{"in", `{ posts(where: {id: {in: [1, 2, 3]}}) { id } }`},
+		{"var", `query ($id: ID!) { users(id: $id) { id email } }`},
+		{"nested", `{ users { id posts(order_by: {id: desc}) { title comments { body } } } }`},
+		{"parent", `{ comments { body post { title user { email } } } }`},
+		{"roots", `{ users { id } posts { id } }`},
+	}
+
+	s := blogSchema(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			golden.Check(t, tt.name+".sql", compileSQL(t, s, tt.query)+"\n")
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		in, ident, literal string
+	}{
+		{`users`, `"users"`, `'users'`},
+		{`it's`, `"it's"`, `'it''s'`},
+		{`say "hi"`, `"say ""hi"""`, `'say "hi"'`},
+		{`''`, `"''"`, `''''''`},
+		{``, `""`, `''`},
+	}
+	for _, tt := range tests {
+		if got := quoteIdent(tt.in); got != tt.ident {
+			t.Errorf("quoteIdent(%s): got %s, want %s", tt.in, got, tt.ident)
+		}
+		if got := quoteLiteral(tt.in); got != tt.literal {
+			t.Errorf("quoteLiteral(%s): got %s, want %s", tt.in, got, tt.literal)
+		}
+	}
+}
+
+// quotedSchema has a database schema whose name needs quoting
+func quotedSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table(`my"app's.users`, "id pk", "bio").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.Schema = `my"app's`
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileQuoted(t *testing.T) {
+	s := quotedSchema(t)
+	golden.Check(t, "quoted.sql", compileSQL(t, s, `{ users(where: {bio: {eq: "a 'b'"}}) { id bio } }`)+"\n")
+}
+
+// constants are written as they are, only strings are quoted
+func TestValueSQL(t *testing.T) {
+	tests := []struct {
+		v    qcode.Value
+		typ  string
+		want string
+	}{
+		{qcode.Value{Type: qcode.ValNum, Val: "5"}, "bigint", "5"},
+		{qcode.Value{Type: qcode.ValNum, Val: "-1.5e3"}, "numeric", "-1.5e3"},
+		{qcode.Value{Type: qcode.ValBool, Val: "true"}, "boolean", "true"},
+		{qcode.Value{Type: qcode.ValBool, Val: "false"}, "boolean", "false"},
+		{qcode.Value{Type: qcode.ValStr, Val: "it's"}, "text", "'it''s'"},
+		{qcode.Value{Type: qcode.ValVar, Val: "id"}, "bigint", "$1::bigint"},
+		{qcode.Value{Type: qcode.ValNow}, "timestamptz", "CURRENT_TIMESTAMP"},
+		{qcode.Value{}, "text", "NULL"},
+		{qcode.Value{Type: qcode.ValList, List: []qcode.Value{
+			{Type: qcode.ValNum, Val: "1"},
+			{Type: qcode.ValBool, Val: "true"},
+		}}, "bigint[]", "ARRAY[1, true]::bigint[]"},
+	}
+	for _, tt := range tests {
+		c := &compilerContext{params: make(map[string]int)}
+		if got := c.valueSQL(tt.v, tt.typ); got != tt.want {
+			t.Errorf("valueSQL(%v): got %s, want %s", tt.v, got, tt.want)
+		}
+	}
+}
+
+// objectSQL returns an object of n keys named k<i> with the value i
+func objectSQL(n int) string {
+	var w bytes.Buffer
+	o := newJSONObject(&w, n)
+	for i := 0; i < n; i++ {
+		o.key(fmt.Sprintf("k%d", i))
+		fmt.Fprint(&w, i)
+	}
+	o.close()
+	return w.String()
+}
+
+// pairsSQL returns the arguments of json_build_object for the keys
+// from i to j
+func pairsSQL(i, j int) string {
+	var v []string
+	for ; i < j; i++ {
+		v = append(v, fmt.Sprintf("'k%d', %d", i, i))
+	}
+	return "json_build_object(" + strings.Join(v, ", ") + ")"
+}
+
+// a Postgres function takes at most 100 arguments
+func TestJSONObject(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, `json_build_object()`},
+		{1, pairsSQL(0, 1)},
+		{50, pairsSQL(0, 50)},
+		{51, `(left(` + pairsSQL(0, 50) + `::text, -1) || ', ' || substr(` + pairsSQL(50, 51) + `::text, 2))::json`},
+		{100, `(left(` + pairsSQL(0, 50) + `::text, -1) || ', ' || substr(` + pairsSQL(50, 100) + `::text, 2))::json`},
+		{120, `(left(` + pairsSQL(0, 50) + `::text, -1) || ', ' || substr(left(` + pairsSQL(50, 100) + `::text, -1), 2) || ', ' || substr(` + pairsSQL(100, 120) + `::text, 2))::json`},
+	}
+	for _, tt := range tests {
+		if got := objectSQL(tt.n); got != tt.want {
+			t.Errorf("%d keys: got %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+// wideSchema has a table with more columns than json_build_object takes
+// keys
+func wideSchema(t *testing.T, n int) *schema.DBSchema {
+	t.Helper()
+	cols := []string{"id pk"}
+	for i := 1; i < n; i++ {
+		cols = append(cols, fmt.Sprintf("c%d", i))
+	}
+	di, err := schema.NewTestSchema().
+		Table("wide", cols...).
+		Table("items", "id pk", "wide_id notnull").
+		FK("items.wide_id", "wide.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// maxArgs returns the largest number of arguments of a json_build_object
+// call of a statement
+func maxArgs(sql string) int {
+	most := 0
+	for {
+		i := strings.Index(sql, "json_build_object(")
+		if i == -1 {
+			return most
+		}
+		sql = sql[i+len("json_build_object("):]
+
+		args, depth := 0, 0
+		inStr := false
+	scan:
+		for j := 0; j < len(sql); j++ {
+			switch ch := sql[j]; {
+			case ch == '\'':
+				inStr = !inStr
+			case inStr:
+			case ch == '(':
+				depth++
+			case ch == ')' && depth == 0:
+				if j != 0 {
+					args++
+				}
+				break scan
+			case ch == ')':
+				depth--
+			case ch == ',' && depth == 0:
+				args++
+			}
+		}
+		most = max(most, args)
+	}
+}
+
+func TestCompileWide(t *testing.T) {
+	s := wideSchema(t, 120)
+
+	var fields []string
+	for i := 1; i < 120; i++ {
+		fields = append(fields, fmt.Sprintf("c%d", i))
+	}
+	query := fmt.Sprintf(`{ wides { id %s items { id } } }`, strings.Join(fields, " "))
+
+	sql := compileSQL(t, s, query)
+	if n := maxArgs(sql); n > 100 {
+		t.Errorf("json_build_object called with %d arguments", n)
+	}
+	golden.Check(t, "wide.sql", sql+"\n")
+
+}
@@ -0,0 +1,69 @@
+package psql
+
+import (
+	"strconv"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// renderRecursive writes the WITH RECURSIVE clause of a recursive
+// selection, the CTE holds the rows found by following the foreign key
+// from the parent row one level at a time up to the depth limit
+func (c *compilerContext) renderRecursive(sel *qcode.Select) error {
+	rc := sel.Recursive
+	id := strconv.Itoa(int(sel.ID))
+	name, aa, ra := recursiveName(sel), "__rca_"+id, "__rcr_"+id
+	pa := tableAlias(&c.qc.Selects[sel.ParentID])
+
+	// children point to the rows of the level before, parents are
+	// pointed to by them
+	col, key := rc.Ref.Col.Name, rc.Ref.Key.Name
+	if rc.Find == qcode.FindParents {
+		col, key = key, col
+	}
+
+	c.w.WriteString(`WITH RECURSIVE `)
+	c.w.WriteString(quoteIdent(name))
+	c.w.WriteString(` AS (SELECT `)
+	c.w.WriteString(quoteIdent(aa))
+	c.w.WriteString(`.*, 1 AS "__rc_depth" FROM `)
+	c.w.WriteString(c.tableRef(sel.Ti))
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(aa))
+	c.w.WriteString(` WHERE `)
+	c.w.WriteString(colRef(aa, col))
+	c.w.WriteString(` = `)
+	c.w.WriteString(colRef(pa, key))
+	if err := c.renderJoinFilter(aa, rc.Filter); err != nil {
+		return err
+	}
+
+	c.w.WriteString(` UNION ALL SELECT `)
+	c.w.WriteString(quoteIdent(ra))
+	c.w.WriteString(`.*, `)
+	c.w.WriteString(colRef(name, "__rc_depth"))
+	c.w.WriteString(` + 1 FROM `)
+	c.w.WriteString(c.tableRef(sel.Ti))
+	c.w.WriteString(` AS `)
+	c.w.WriteString(quoteIdent(ra))
+	c.w.WriteString(`, `)
+	c.w.WriteString(quoteIdent(name))
+	c.w.WriteString(` WHERE `)
+	c.w.WriteString(colRef(ra, col))
+	c.w.WriteString(` = `)
+	c.w.WriteString(colRef(name, key))
+	if err := c.renderJoinFilter(ra, rc.Filter); err != nil {
+		return err
+	}
+	c.w.WriteString(` AND `)
+	c.w.WriteString(colRef(name, "__rc_depth"))
+	c.w.WriteString(` < `)
+	c.w.WriteString(strconv.Itoa(int(rc.Depth)))
+	c.w.WriteString(`) `)
+	return nil
+}
+
+// recursiveName returns the name of the CTE of a recursive selection
+func recursiveName(sel *qcode.Select) string {
+	return "__rc_" + strconv.Itoa(int(sel.ID))
+}
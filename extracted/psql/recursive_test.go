@@ -0,0 +1,60 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// categorySchema has categories with a parent category
+func categorySchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("categories", "id pk", "parent_id", "name").
+		FK("categories.parent_id", "categories.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileRecursive(t *testing.T) {
+	s := categorySchema(t)
+	sql := compileSQL(t, s, `{ categories(id: 1) { id categories(find: children, depth: 3) { id name } } }`)
+	golden.Check(t, "recursive.sql", sql+"\n")
+
+	sql = compileSQL(t, s, `{ categories(id: 1) { id categories(find: parents) { id } } }`)
+	for _, want := range []string{
+		`SELECT "categories_0"."id", "categories_0"."parent_id" FROM`,
+		`WHERE "__rca_1"."id" = "categories_0"."parent_id"`,
+		`"__rc_depth" < 10)`,
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("statement without %s:\n%s", want, sql)
+		}
+	}
+}
+
+func TestRecursiveErrors(t *testing.T) {
+	s := categorySchema(t)
+	for _, query := range []string{
+		`{ categories { id categories(find: siblings) { id } } }`,
+		`{ categories { id categories(find: children, depth: 11) { id } } }`,
+		`{ categories { id categories(find: children, depth: 0) { id } } }`,
+		`{ categories(find: children) { id } }`,
+	} {
+		if _, err := qcode.NewCompiler(s).Compile([]byte(query), ""); err == nil {
+			t.Errorf("%s: want an error", query)
+		}
+	}
+
+	// the depth limit of the compiler
+	q := `{ categories { id categories(find: children, depth: 5) { id } } }`
+	if _, err := qcode.NewCompiler(s, qcode.WithMaxDepth(4)).Compile([]byte(q), ""); err == nil {
+		t.Errorf("%s: want an error above the max depth", q)
+	}
+}
@@ -0,0 +1,82 @@
+package psql
+
+import (
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// rankColumn is the column of the rows of a search holding their rank
+const rankColumn = "__search_rank"
+
+var tsqueryFuncs = map[qcode.SearchMode]string{
+	qcode.SearchWeb:    "websearch_to_tsquery",
+	qcode.SearchPlain:  "plainto_tsquery",
+	qcode.SearchPhrase: "phraseto_tsquery",
+	qcode.SearchRaw:    "to_tsquery",
+}
+
+// searchWhere returns the condition matching the rows of a search
+func (c *compilerContext) searchWhere(sel *qcode.Select, ta string) string {
+	s := sel.Search
+	items := make([]string, len(s.Cols))
+	for i, sc := range s.Cols {
+		items[i] = tsvectorSQL(sc, ta) + " @@ " + c.tsquerySQL(s, sc)
+	}
+	return strings.Join(items, " OR ")
+}
+
+// rankSQL returns the rank of a row of a search, the sum of its rank
+// in each of the searched columns
+func (c *compilerContext) rankSQL(sel *qcode.Select, ta string) string {
+	s := sel.Search
+	items := make([]string, len(s.Cols))
+	for i, sc := range s.Cols {
+		items[i] = "ts_rank(" + tsvectorSQL(sc, ta) + ", " + c.tsquerySQL(s, sc) + ")"
+	}
+	return strings.Join(items, " + ")
+}
+
+// tsvectorSQL returns the document of a searched column, a text column
+// is converted the same way as in its index so the index is used
+func tsvectorSQL(sc schema.DBSearchColumn, ta string) string {
+	if sc.Config == "" {
+		return colRef(ta, sc.Col.Name)
+	}
+	return "to_tsvector(" + quoteLiteral(sc.Config) + "::regconfig, " + colRef(ta, sc.Col.Name) + ")"
+}
+
+// tsquerySQL returns the query of a search for a column, parsed with
+// the configuration of the column when it has one
+func (c *compilerContext) tsquerySQL(s *qcode.Search, sc schema.DBSearchColumn) string {
+	var q string
+	if s.Query.Type == qcode.ValVar {
+		q = c.param(s.Query.Val, "text")
+	} else {
+		q = quoteLiteral(s.Query.Val) + "::text"
+	}
+
+	if sc.Config == "" {
+		return tsqueryFuncs[s.Mode] + "(" + q + ")"
+	}
+	return tsqueryFuncs[s.Mode] + "(" + quoteLiteral(sc.Config) + "::regconfig, " + q + ")"
+}
+
+// obColumn returns the column of the base rows of a selection an
+// ordering reads
+func obColumn(ob qcode.OrderBy) string {
+	if ob.Rank {
+		return rankColumn
+	}
+	return ob.Col.Name
+}
+
+// orderKeySQL returns the value an ordering sorts the rows of the
+// table of a selection by
+func (c *compilerContext) orderKeySQL(sel *qcode.Select, ta string, ob qcode.OrderBy) string {
+	if ob.Rank {
+		return c.rankSQL(sel, ta)
+	}
+	return colSQL(ta, ob.Col)
+}
@@ -0,0 +1,73 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// searchSchema has articles with a tsvector column and a title with a
+// to_tsvector expression index
+func searchSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("articles", "id pk", "title text notnull", "doc tsvector fulltext").
+		Table("tags", "id pk", "name").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ti := range di.Tables {
+		if ti.Name == "articles" {
+			di.Tables[i].Indexes = []schema.DBIndex{
+				{Schema: "public", Table: "articles", Name: "articles_doc", Method: "gin", Columns: []string{"doc"}},
+				{Schema: "public", Table: "articles", Name: "articles_title", Method: "gin",
+					Columns: []string{"to_tsvector('english'::regconfig, title)"}},
+			}
+		}
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileSearch(t *testing.T) {
+	s := searchSchema(t)
+	sql := compileSQL(t, s, `query ($q: String!) { articles(search: $q) { id title search_rank } }`)
+	golden.Check(t, "search.sql", sql+"\n")
+
+	tests := []struct {
+		query, want string
+	}{
+		{`{ articles(search: "go") { id } }`, `websearch_to_tsquery('go'::text)`},
+		{`{ articles(search: {query: "go", mode: phrase}) { id } }`, `phraseto_tsquery('english'::regconfig, 'go'::text)`},
+		{`{ articles(search: {query: "go", mode: raw}) { id } }`, `to_tsquery('go'::text)`},
+		{`{ articles(search: "go", order_by: {id: asc}) { id } }`, `ORDER BY "articles_0"."id" ASC`},
+		{`{ articles(search: "go", order_by: {search_rank: asc}) { id } }`, `"__sr_0"."__ob_0" ASC`},
+	}
+	for _, tt := range tests {
+		if sql := compileSQL(t, s, tt.query); !strings.Contains(sql, tt.want) {
+			t.Errorf("%s: statement without %s:\n%s", tt.query, tt.want, sql)
+		}
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	s := searchSchema(t)
+	for _, query := range []string{
+		`{ tags(search: "go") { id } }`,
+		`{ articles(search: {query: "go", mode: fuzzy}) { id } }`,
+		`{ articles(search: {mode: plain}) { id } }`,
+		`{ articles(search: 5) { id } }`,
+		`{ articles(order_by: {search_rank: desc}) { id } }`,
+	} {
+		if _, err := qcode.NewCompiler(s).Compile([]byte(query), ""); err == nil {
+			t.Errorf("%s: want an error", query)
+		}
+	}
+}
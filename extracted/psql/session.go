@@ -0,0 +1,117 @@
+package psql
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// Session is what a request runs as, taken from its user rather than
+// from the operation eg. the claims of a JWT
+//
+//	sess := psql.Session{
+//	    Role:     "user",
+//	    Vars:     map[string]interface{}{"user_id": claims.Sub},
+//	    Settings: map[string]string{"app.user_id": claims.Sub},
+//	}
+type Session struct {
+	// Role is the role the operation is compiled for, see
+	// qcode.CompileRole
+	Role string
+
+	// Tenant is the tenant the statement is compiled for, see
+	// CompileTenant
+	Tenant string
+
+	// Vars are the values of the variables of the filters and of the
+	// audit user, those in QCode.FilterVars. The variables of the
+	// request cannot set them
+	Vars map[string]interface{}
+
+	// Settings are set with SET LOCAL in the transaction of the
+	// statement, for row level security policies reading them with
+	// current_setting
+	Settings map[string]string
+}
+
+// SessionArgs is Args with the filter variables read from the session,
+// an error is returned for a filter variable the session does not set
+func SessionArgs(qc *qcode.QCode, md Metadata, vars map[string]json.RawMessage, sess Session) ([]interface{}, error) {
+	opVars := make(map[string]json.RawMessage, len(vars))
+	for k, v := range vars {
+		if !isFilterVar(qc, k) {
+			opVars[k] = v
+		}
+	}
+
+	args, err := Args(qc, md, opVars)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, p := range md.Params {
+		if !isFilterVar(qc, p.Name) {
+			continue
+		}
+		v, ok := sess.Vars[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("session variable not set: $%s", p.Name)
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("session variable $%s: %s", p.Name, err)
+		}
+		if args[i], err = jsonArg(b); err != nil {
+			return nil, fmt.Errorf("session variable $%s: %s", p.Name, err)
+		}
+	}
+	return args, nil
+}
+
+// isFilterVar returns true for a variable of the filters of a query
+func isFilterVar(qc *qcode.QCode, name string) bool {
+	for _, v := range qc.FilterVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionSQL returns the statement setting the settings of a session for
+// the rest of the transaction it runs in, with its arguments. It is
+// empty when there are none
+//
+//	SELECT set_config($1, $2, true), set_config($3, $4, true)
+func SessionSQL(sess Session) (string, []interface{}) {
+	if len(sess.Settings) == 0 {
+		return "", nil
+	}
+
+	names := make([]string, 0, len(sess.Settings))
+	for k := range sess.Settings {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	args := make([]interface{}, 0, 2*len(names))
+
+	sb.WriteString(`SELECT `)
+	for i, k := range names {
+		if i != 0 {
+			sb.WriteString(`, `)
+		}
+		sb.WriteString(`set_config($`)
+		sb.WriteString(strconv.Itoa(2*i + 1))
+		sb.WriteString(`, $`)
+		sb.WriteString(strconv.Itoa(2*i + 2))
+		sb.WriteString(`, true)`)
+		args = append(args, k, sess.Settings[k])
+	}
+	return sb.String(), args
+}
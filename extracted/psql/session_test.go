@@ -0,0 +1,62 @@
+package psql
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestSessionArgs(t *testing.T) {
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "org_id notnull").
+		BuildSchema(schema.WithRoles(schema.Role{Name: "member", Tables: []schema.RoleTable{{
+			Table:  "users",
+			Filter: `{org_id: {eq: $org_id}}`,
+		}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	qc, err := qcode.NewCompiler(s).CompileRole([]byte(`query ($id: ID) { users(id: $id) { id } }`), "", "member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, md, err := NewCompiler(s).CompileString(qc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the request cannot set the variable of the filter
+	vars := map[string]json.RawMessage{"id": json.RawMessage(`5`), "org_id": json.RawMessage(`1`)}
+	args, err := SessionArgs(qc, md, vars, Session{Vars: map[string]interface{}{"org_id": 9}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]interface{}{}
+	for i, p := range md.Params {
+		got[p.Name] = args[i]
+	}
+	if fmt.Sprint(got["id"]) != "5" || fmt.Sprint(got["org_id"]) != "9" {
+		t.Errorf("got arguments %v", got)
+	}
+
+	if _, err := SessionArgs(qc, md, vars, Session{}); err == nil {
+		t.Error("want an error for a session variable not set")
+	}
+}
+
+func TestSessionSQL(t *testing.T) {
+	stmt, args := SessionSQL(Session{Settings: map[string]string{"app.user_id": "7", "app.org_id": "9"}})
+	if stmt != `SELECT set_config($1, $2, true), set_config($3, $4, true)` {
+		t.Errorf("got statement %s", stmt)
+	}
+	if fmt.Sprint(args) != "[app.org_id 9 app.user_id 7]" {
+		t.Errorf("got arguments %v", args)
+	}
+
+	if stmt, args := SessionSQL(Session{Role: "member"}); stmt != "" || args != nil {
+		t.Errorf("got %q %v without settings", stmt, args)
+	}
+}
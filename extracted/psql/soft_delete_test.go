@@ -0,0 +1,78 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// deletedSchema has users deleted by a boolean, posts by a timestamp and
+// tags of the posts through a soft deleted join table
+func deletedSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text", "is_deleted boolean").
+		Table("posts", "id pk", "user_id notnull", "deleted_at timestamptz").
+		Table("tags", "id pk", "name text").
+		Table("post_tags", "post_id notnull", "tag_id notnull", "deleted_at timestamptz").
+		FK("posts.user_id", "users.id").
+		FK("post_tags.post_id", "posts.id").
+		FK("post_tags.tag_id", "tags.id").
+		BuildSchema(schema.WithSoftDeletes(
+			schema.SoftDelete{Table: "users"},
+			schema.SoftDelete{Table: "posts"},
+			schema.SoftDelete{Table: "post_tags"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileSoftDelete(t *testing.T) {
+	s := deletedSchema(t)
+	tests := []struct {
+		query   string
+		want    []string
+		notWant []string
+	}{
+		{`{ posts { id } }`, []string{`WHERE ("posts_0"."deleted_at" IS NULL)`}, nil},
+		{`{ posts(with_deleted: true) { id } }`, nil, []string{`"deleted_at"`}},
+		{`{ users { id posts { id } } }`, []string{
+			`WHERE (("users_0"."is_deleted" IS NULL) OR ("users_0"."is_deleted" = false))`,
+			`WHERE ("users_0"."id" = "posts_1"."user_id") AND ("posts_1"."deleted_at" IS NULL)`,
+		}, nil},
+		{`{ users { id posts(with_deleted: true) { id } } }`, []string{`"is_deleted"`}, []string{`"deleted_at"`}},
+		// the join table of a path is filtered in its join
+		{`{ posts(with_deleted: true) { tags { id } } }`, []string{
+			`ON "__p_1_1"."tag_id" = "tags_1"."id" AND ("__p_1_1"."deleted_at" IS NULL)`,
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		got := compileSQL(t, s, tt.query)
+		for _, w := range tt.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("%s: no %s in:\n%s", tt.query, w, got)
+			}
+		}
+		for _, w := range tt.notWant {
+			if strings.Contains(got, w) {
+				t.Errorf("%s: got %s in:\n%s", tt.query, w, got)
+			}
+		}
+	}
+}
+
+func TestSoftDeleteErrors(t *testing.T) {
+	s := deletedSchema(t)
+	for _, query := range []string{
+		`{ tags(with_deleted: true) { id } }`,
+		`{ posts(with_deleted: "yes") { id } }`,
+	} {
+		if _, err := qcode.NewCompiler(s).Compile([]byte(query), ""); err == nil {
+			t.Errorf("%s: want an error", query)
+		}
+	}
+}
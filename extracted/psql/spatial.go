@@ -0,0 +1,79 @@
+package psql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// spatialSQL returns the SQL of a spatial operator on a PostGIS column,
+// geography columns have no ST_Contains and ST_Within so ST_Covers and
+// ST_CoveredBy are used for them
+func (c *compilerContext) spatialSQL(col string, ex *qcode.Exp) string {
+	shape := c.shapeSQL(ex)
+	geog := ex.Col.IsGeography()
+
+	switch ex.Op {
+	case qcode.OpDWithin:
+		return "ST_DWithin(" + col + ", " + shape + ", " + c.valueSQL(ex.Shape.Distance, "float8") + ")"
+	case qcode.OpSTContains:
+		if geog {
+			return "ST_Covers(" + col + ", " + shape + ")"
+		}
+		return "ST_Contains(" + col + ", " + shape + ")"
+	case qcode.OpSTWithin:
+		if geog {
+			return "ST_CoveredBy(" + col + ", " + shape + ")"
+		}
+		return "ST_Within(" + col + ", " + shape + ")"
+	}
+	return "ST_Intersects(" + col + ", " + shape + ")"
+}
+
+// shapeSQL returns the geometry of a spatial operator in the SRID and
+// type of the column
+func (c *compilerContext) shapeSQL(ex *qcode.Exp) string {
+	sh := ex.Shape
+
+	var v string
+	switch {
+	case sh.Point != nil:
+		v = c.pointSQL(sh.Point)
+
+	case sh.Polygon != nil:
+		points := sh.Polygon
+		// the ring of a polygon ends at its first point
+		first, last := points[0], points[len(points)-1]
+		if !sameValue(first[0], last[0]) || !sameValue(first[1], last[1]) {
+			points = append(points[:len(points):len(points)], first)
+		}
+
+		items := make([]string, len(points))
+		for i, p := range points {
+			items[i] = c.pointSQL(p)
+		}
+		v = "ST_MakePolygon(ST_MakeLine(ARRAY[" + strings.Join(items, ", ") + "]))"
+
+	default:
+		v = "ST_GeomFromGeoJSON(" + c.valueSQL(sh.GeoJSON, "text") + ")"
+	}
+
+	if ex.Col.SRID > 0 {
+		v = "ST_SetSRID(" + v + ", " + strconv.Itoa(ex.Col.SRID) + ")"
+	}
+	if ex.Col.IsGeography() {
+		v += "::geography"
+	}
+	return v
+}
+
+// pointSQL returns a point from its x and y
+func (c *compilerContext) pointSQL(p []qcode.Value) string {
+	return "ST_MakePoint(" + c.valueSQL(p[0], "float8") + ", " + c.valueSQL(p[1], "float8") + ")"
+}
+
+// sameValue returns true when two constants or variables are the same
+func sameValue(a, b qcode.Value) bool {
+	return a.Type == b.Type && a.Val == b.Val
+}
@@ -0,0 +1,77 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// placeSchema has stores with a geography location and zones with a
+// geometry area
+func placeSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("stores", "id pk", "name text", "location geography(Point,4326)").
+		Table("zones", "id pk", "area geometry(Polygon,3857)").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileSpatial(t *testing.T) {
+	s := placeSchema(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`{ stores(where: {location: {st_dwithin: {point: [-122.4, 37.7], distance: 1000}}}) { id } }`,
+			[]string{`ST_DWithin("stores_0"."location", ST_SetSRID(ST_MakePoint(`, `, 4326)::geography, `}},
+		{`{ stores(where: {location: {st_contains: {point: [1, 2]}}}) { id } }`,
+			[]string{`ST_Covers("stores_0"."location", `}},
+		{`{ zones(where: {area: {st_contains: {point: [1, 2]}}}) { id } }`,
+			[]string{`ST_Contains("zones_0"."area", ST_SetSRID(ST_MakePoint(`, `, 3857))`}},
+		{`{ zones(where: {area: {st_within: {geojson: "{}"}}}) { id } }`,
+			[]string{`ST_Within("zones_0"."area", ST_SetSRID(ST_GeomFromGeoJSON(`}},
+		// the ring of the polygon is closed
+		{`{ zones(where: {area: {st_intersects: {polygon: [[0, 0], [0, 1], [1, 1]]}}}) { id } }`,
+			[]string{`ST_Intersects("zones_0"."area", ST_SetSRID(ST_MakePolygon(ST_MakeLine(ARRAY[`}},
+		{`{ stores { name location } }`, []string{`ST_AsGeoJSON("stores_0"."location")::json`}},
+	}
+	for _, tt := range tests {
+		sql := compileSQL(t, s, tt.query)
+		for _, want := range tt.want {
+			if !strings.Contains(sql, want) {
+				t.Errorf("%s: statement without %s:\n%s", tt.query, want, sql)
+			}
+		}
+	}
+
+	sql := compileSQL(t, s, `{ zones(where: {area: {st_intersects: {polygon: [[0, 0], [0, 1], [1, 1]]}}}) { id } }`)
+	if n := strings.Count(sql, "ST_MakePoint("); n != 4 {
+		t.Errorf("got a polygon of %d points, want 4:\n%s", n, sql)
+	}
+}
+
+func TestSpatialErrors(t *testing.T) {
+	s := placeSchema(t)
+	for _, where := range []string{
+		`{name: {st_contains: {point: [1, 2]}}}`,
+		`{location: {st_dwithin: {point: [1, 2]}}}`,
+		`{location: {st_contains: {point: [1, 2], distance: 5}}}`,
+		`{location: {st_contains: {point: [1]}}}`,
+		`{location: {st_contains: {point: [1, 2], geojson: "{}"}}}`,
+		`{location: {st_contains: {polygon: [[0, 0], [1, 1]]}}}`,
+		`{location: {st_contains: {geojson: 1}}}`,
+		`{location: {st_contains: "a"}}`,
+	} {
+		_, err := qcode.NewCompiler(s).Compile([]byte(`{ stores(where: `+where+`) { id } }`), "")
+		if err == nil {
+			t.Errorf("%s: want an error", where)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package psql
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// Step is a write of a mutation compiled as a statement of its own. The
+// rows it writes are kept in a temporary table named like the CTE of
+// the write, the later steps and the result read them from it
+type Step struct {
+	ID    int32  // of the write in QCode.Mutates
+	Table string // temporary table of the rows written
+
+	// Setup creates the temporary table of the step, dropped when the
+	// transaction ends
+	Setup string
+
+	// SQL runs the write and copies the rows written to the table
+	SQL      string
+	Metadata Metadata
+}
+
+// Steps is a mutation compiled as a statement for each write, in the
+// order they run, and a statement returning the result
+type Steps struct {
+	Steps    []Step
+	SQL      string
+	Metadata Metadata
+}
+
+// CompileSteps compiles a mutation as a statement for each write so the
+// writes can run one by one in a transaction, eg. each in a savepoint.
+// The result is the JSON Compile returns read from the written rows
+//
+//	CREATE TEMPORARY TABLE "users_m0" (LIKE "public"."users") ON COMMIT DROP
+//	WITH "__w" AS (INSERT INTO "public"."users" ... RETURNING *)
+//	    INSERT INTO "users_m0" SELECT * FROM "__w"
+func (co *Compiler) CompileSteps(qc *qcode.QCode, tenant string) (Steps, error) {
+	var st Steps
+
+	if qc.Type != qcode.QTMutation {
+		return st, fmt.Errorf("only mutations are run in steps")
+	}
+	if err := checkNamespace(qc); err != nil {
+		return st, err
+	}
+
+	c := &compilerContext{qc: qc, s: co.s.Snapshot(), tenant: tenant}
+
+	err := c.walkMutations(func(m *qcode.Mutate) error {
+		var w bytes.Buffer
+		c.w, c.md, c.params = &w, Metadata{}, make(map[string]int)
+
+		name := quoteIdent(cteName(m))
+		w.WriteString(`WITH "__w" AS (`)
+		if err := c.renderWrite(m); err != nil {
+			return err
+		}
+		w.WriteString(`) INSERT INTO `)
+		w.WriteString(name)
+		w.WriteString(` SELECT * FROM "__w"`)
+
+		st.Steps = append(st.Steps, Step{
+			ID:       m.ID,
+			Table:    cteName(m),
+			Setup:    `CREATE TEMPORARY TABLE ` + name + ` (LIKE ` + c.tableRef(m.Ti) + `) ON COMMIT DROP`,
+			SQL:      w.String(),
+			Metadata: c.md,
+		})
+		return nil
+	})
+	if err != nil {
+		return Steps{}, err
+	}
+
+	var w bytes.Buffer
+	c.w, c.md, c.params = &w, Metadata{}, make(map[string]int)
+	if err := c.renderRoot(); err != nil {
+		return Steps{}, err
+	}
+	st.SQL, st.Metadata = w.String(), c.md
+	return st, nil
+}
@@ -0,0 +1,53 @@
+package psql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+func TestCompileSteps(t *testing.T) {
+	s := blogSchema(t)
+	qc, err := qcode.NewCompiler(s).Compile([]byte(
+		`mutation { posts(insert: {title: "a", user: {email: "b"}}) { id user { email } } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st, err := NewCompiler(s).CompileSteps(qc, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the user is written before the post pointing to it
+	if len(st.Steps) != 2 {
+		t.Fatalf("got steps %+v", st.Steps)
+	}
+	users, posts := st.Steps[0], st.Steps[1]
+	if qc.Mutates[users.ID].Ti.Name != "users" || qc.Mutates[posts.ID].Ti.Name != "posts" {
+		t.Fatalf("got steps %+v", st.Steps)
+	}
+	want := `CREATE TEMPORARY TABLE "` + users.Table + `" (LIKE "public"."users") ON COMMIT DROP`
+	if users.Setup != want {
+		t.Errorf("got setup %s, want %s", users.Setup, want)
+	}
+	if !strings.HasPrefix(users.SQL, `WITH "__w" AS (INSERT INTO "public"."users"`) ||
+		!strings.HasSuffix(users.SQL, `) INSERT INTO "`+users.Table+`" SELECT * FROM "__w"`) {
+		t.Errorf("got statement\n%s", users.SQL)
+	}
+	// the post reads the key of the user from the rows written
+	if !strings.Contains(posts.SQL, `"`+users.Table+`"`) {
+		t.Errorf("got statement\n%s", posts.SQL)
+	}
+	if !strings.Contains(st.SQL, `"`+users.Table+`"`) || !strings.Contains(st.SQL, `"`+posts.Table+`"`) {
+		t.Errorf("got result statement\n%s", st.SQL)
+	}
+
+	qc, err = qcode.NewCompiler(s).Compile([]byte(`{ users { id } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCompiler(s).CompileSteps(qc, ""); err == nil {
+		t.Error("want an error for a query")
+	}
+}
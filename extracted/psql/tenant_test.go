@@ -0,0 +1,52 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// the tables of the template, here the default schema, are read from
+// the schema of the tenant
+func TestCompileTenant(t *testing.T) {
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id notnull", "title text notnull").
+		FK("posts.user_id", "users.id").
+		BuildSchema(schema.WithTenantTemplate("public"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qc, err := qcode.NewCompiler(s).Compile([]byte(`{ users { id posts { title } } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := NewCompiler(s)
+
+	stmt, _, err := co.CompileTenantString(qc, "tenant_123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stmt, `"tenant_123"."users"`) || !strings.Contains(stmt, `"tenant_123"."posts"`) ||
+		strings.Contains(stmt, `"public"`) {
+		t.Errorf("got statement\n%s", stmt)
+	}
+
+	// the same compiled query is used for another tenant
+	var b bytes.Buffer
+	if _, err := co.CompileTenant(&b, qc, "tenant_456"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"tenant_456"."users"`) || strings.Contains(b.String(), "tenant_123") {
+		t.Errorf("got statement\n%s", b.String())
+	}
+
+	// without a tenant the template is read
+	if stmt := compileSQL(t, s, `{ users { id } }`); !strings.Contains(stmt, `"public"."users"`) {
+		t.Errorf("got statement\n%s", stmt)
+	}
+}
@@ -0,0 +1 @@
+SELECT json_build_object('users', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "users_0"."id", 'posts', "__sj_1"."json") AS "json" FROM (SELECT "users_0"."id" FROM "public"."users" AS "users_0") AS "users_0" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_1"."json" ORDER BY "__sr_1"."__ob_0" DESC), '[]') AS "json" FROM (SELECT json_build_object('title', "posts_1"."title", 'comments', "__sj_2"."json") AS "json", "posts_1"."id" AS "__ob_0" FROM (SELECT "posts_1"."title", "posts_1"."id" FROM "public"."posts" AS "posts_1" WHERE ("users_0"."id" = "posts_1"."user_id") ORDER BY "posts_1"."id" DESC) AS "posts_1" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_2"."json"), '[]') AS "json" FROM (SELECT json_build_object('body', "comments_2"."body") AS "json" FROM (SELECT "comments_2"."body" FROM "public"."comments" AS "comments_2" WHERE ("posts_1"."id" = "comments_2"."post_id")) AS "comments_2") AS "__sr_2") AS "__sj_2" ON true) AS "__sr_1") AS "__sj_1" ON true) AS "__sr_0") AS "__sj_0" ON true
@@ -0,0 +1 @@
+SELECT json_build_object('comments', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('body', "comments_0"."body", 'post', "__sj_1"."json") AS "json" FROM (SELECT "comments_0"."body", "comments_0"."post_id" FROM "public"."comments" AS "comments_0") AS "comments_0" LEFT OUTER JOIN LATERAL (SELECT json_build_object('title', "posts_1"."title", 'user', "__sj_2"."json") AS "json" FROM (SELECT "posts_1"."title", "posts_1"."user_id" FROM "public"."posts" AS "posts_1" WHERE ("comments_0"."post_id" = "posts_1"."id") LIMIT 1) AS "posts_1" LEFT OUTER JOIN LATERAL (SELECT json_build_object('email', "users_2"."email") AS "json" FROM (SELECT "users_2"."email" FROM "public"."users" AS "users_2" WHERE ("posts_1"."user_id" = "users_2"."id") LIMIT 1) AS "users_2") AS "__sj_2" ON true) AS "__sj_1" ON true) AS "__sr_0") AS "__sj_0" ON true
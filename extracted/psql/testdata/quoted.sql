@@ -0,0 +1 @@
+SELECT json_build_object('users', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "users_0"."id", 'bio', "users_0"."bio") AS "json" FROM (SELECT "users_0"."id", "users_0"."bio" FROM "my""app's"."users" AS "users_0" WHERE ("users_0"."bio" = 'a ''b''')) AS "users_0") AS "__sr_0") AS "__sj_0" ON true
@@ -0,0 +1 @@
+SELECT json_build_object('categories', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT json_build_object('id', "categories_0"."id", 'categories', "__sj_1"."json") AS "json" FROM (SELECT "categories_0"."id" FROM "public"."categories" AS "categories_0" WHERE ("categories_0"."id" = 1) LIMIT 1) AS "categories_0" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_1"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "categories_1"."id", 'name', "categories_1"."name") AS "json" FROM (WITH RECURSIVE "__rc_1" AS (SELECT "__rca_1".*, 1 AS "__rc_depth" FROM "public"."categories" AS "__rca_1" WHERE "__rca_1"."parent_id" = "categories_0"."id" UNION ALL SELECT "__rcr_1".*, "__rc_1"."__rc_depth" + 1 FROM "public"."categories" AS "__rcr_1", "__rc_1" WHERE "__rcr_1"."parent_id" = "__rc_1"."id" AND "__rc_1"."__rc_depth" < 3) SELECT "categories_1"."id", "categories_1"."name" FROM "__rc_1" AS "categories_1") AS "categories_1") AS "__sr_1") AS "__sj_1" ON true) AS "__sj_0" ON true
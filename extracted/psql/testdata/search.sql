@@ -0,0 +1 @@
+SELECT json_build_object('articles', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json" ORDER BY "__sr_0"."__ob_0" DESC), '[]') AS "json" FROM (SELECT json_build_object('id', "articles_0"."id", 'title', "articles_0"."title", 'search_rank', "articles_0"."__search_rank") AS "json", "articles_0"."__search_rank" AS "__ob_0" FROM (SELECT "articles_0"."id", "articles_0"."title", ts_rank("articles_0"."doc", websearch_to_tsquery($1::text)) + ts_rank(to_tsvector('english'::regconfig, "articles_0"."title"), websearch_to_tsquery('english'::regconfig, $1::text)) AS "__search_rank" FROM "public"."articles" AS "articles_0" WHERE ("articles_0"."doc" @@ websearch_to_tsquery($1::text) OR to_tsvector('english'::regconfig, "articles_0"."title") @@ websearch_to_tsquery('english'::regconfig, $1::text)) ORDER BY ts_rank("articles_0"."doc", websearch_to_tsquery($1::text)) + ts_rank(to_tsvector('english'::regconfig, "articles_0"."title"), websearch_to_tsquery('english'::regconfig, $1::text)) DESC) AS "articles_0") AS "__sr_0") AS "__sj_0" ON true
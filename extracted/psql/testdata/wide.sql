@@ -0,0 +1 @@
+SELECT json_build_object('wides', "__sj_0"."json") AS "__root" FROM (SELECT true) AS "__root_x" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_0"."json"), '[]') AS "json" FROM (SELECT (left(json_build_object('id', "wide_0"."id", 'c1', "wide_0"."c1", 'c2', "wide_0"."c2", 'c3', "wide_0"."c3", 'c4', "wide_0"."c4", 'c5', "wide_0"."c5", 'c6', "wide_0"."c6", 'c7', "wide_0"."c7", 'c8', "wide_0"."c8", 'c9', "wide_0"."c9", 'c10', "wide_0"."c10", 'c11', "wide_0"."c11", 'c12', "wide_0"."c12", 'c13', "wide_0"."c13", 'c14', "wide_0"."c14", 'c15', "wide_0"."c15", 'c16', "wide_0"."c16", 'c17', "wide_0"."c17", 'c18', "wide_0"."c18", 'c19', "wide_0"."c19", 'c20', "wide_0"."c20", 'c21', "wide_0"."c21", 'c22', "wide_0"."c22", 'c23', "wide_0"."c23", 'c24', "wide_0"."c24", 'c25', "wide_0"."c25", 'c26', "wide_0"."c26", 'c27', "wide_0"."c27", 'c28', "wide_0"."c28", 'c29', "wide_0"."c29", 'c30', "wide_0"."c30", 'c31', "wide_0"."c31", 'c32', "wide_0"."c32", 'c33', "wide_0"."c33", 'c34', "wide_0"."c34", 'c35', "wide_0"."c35", 'c36', "wide_0"."c36", 'c37', "wide_0"."c37", 'c38', "wide_0"."c38", 'c39', "wide_0"."c39", 'c40', "wide_0"."c40", 'c41', "wide_0"."c41", 'c42', "wide_0"."c42", 'c43', "wide_0"."c43", 'c44', "wide_0"."c44", 'c45', "wide_0"."c45", 'c46', "wide_0"."c46", 'c47', "wide_0"."c47", 'c48', "wide_0"."c48", 'c49', "wide_0"."c49")::text, -1) || ', ' || substr(left(json_build_object('c50', "wide_0"."c50", 'c51', "wide_0"."c51", 'c52', "wide_0"."c52", 'c53', "wide_0"."c53", 'c54', "wide_0"."c54", 'c55', "wide_0"."c55", 'c56', "wide_0"."c56", 'c57', "wide_0"."c57", 'c58', "wide_0"."c58", 'c59', "wide_0"."c59", 'c60', "wide_0"."c60", 'c61', "wide_0"."c61", 'c62', "wide_0"."c62", 'c63', "wide_0"."c63", 'c64', "wide_0"."c64", 'c65', "wide_0"."c65", 'c66', "wide_0"."c66", 'c67', "wide_0"."c67", 'c68', "wide_0"."c68", 'c69', "wide_0"."c69", 'c70', "wide_0"."c70", 'c71', "wide_0"."c71", 'c72', "wide_0"."c72", 'c73', "wide_0"."c73", 'c74', "wide_0"."c74", 'c75', "wide_0"."c75", 'c76', "wide_0"."c76", 'c77', "wide_0"."c77", 'c78', "wide_0"."c78", 'c79', "wide_0"."c79", 'c80', "wide_0"."c80", 'c81', "wide_0"."c81", 'c82', "wide_0"."c82", 'c83', "wide_0"."c83", 'c84', "wide_0"."c84", 'c85', "wide_0"."c85", 'c86', "wide_0"."c86", 'c87', "wide_0"."c87", 'c88', "wide_0"."c88", 'c89', "wide_0"."c89", 'c90', "wide_0"."c90", 'c91', "wide_0"."c91", 'c92', "wide_0"."c92", 'c93', "wide_0"."c93", 'c94', "wide_0"."c94", 'c95', "wide_0"."c95", 'c96', "wide_0"."c96", 'c97', "wide_0"."c97", 'c98', "wide_0"."c98", 'c99', "wide_0"."c99")::text, -1), 2) || ', ' || substr(json_build_object('c100', "wide_0"."c100", 'c101', "wide_0"."c101", 'c102', "wide_0"."c102", 'c103', "wide_0"."c103", 'c104', "wide_0"."c104", 'c105', "wide_0"."c105", 'c106', "wide_0"."c106", 'c107', "wide_0"."c107", 'c108', "wide_0"."c108", 'c109', "wide_0"."c109", 'c110', "wide_0"."c110", 'c111', "wide_0"."c111", 'c112', "wide_0"."c112", 'c113', "wide_0"."c113", 'c114', "wide_0"."c114", 'c115', "wide_0"."c115", 'c116', "wide_0"."c116", 'c117', "wide_0"."c117", 'c118', "wide_0"."c118", 'c119', "wide_0"."c119", 'items', "__sj_1"."json")::text, 2))::json AS "json" FROM (SELECT "wide_0"."id", "wide_0"."c1", "wide_0"."c2", "wide_0"."c3", "wide_0"."c4", "wide_0"."c5", "wide_0"."c6", "wide_0"."c7", "wide_0"."c8", "wide_0"."c9", "wide_0"."c10", "wide_0"."c11", "wide_0"."c12", "wide_0"."c13", "wide_0"."c14", "wide_0"."c15", "wide_0"."c16", "wide_0"."c17", "wide_0"."c18", "wide_0"."c19", "wide_0"."c20", "wide_0"."c21", "wide_0"."c22", "wide_0"."c23", "wide_0"."c24", "wide_0"."c25", "wide_0"."c26", "wide_0"."c27", "wide_0"."c28", "wide_0"."c29", "wide_0"."c30", "wide_0"."c31", "wide_0"."c32", "wide_0"."c33", "wide_0"."c34", "wide_0"."c35", "wide_0"."c36", "wide_0"."c37", "wide_0"."c38", "wide_0"."c39", "wide_0"."c40", "wide_0"."c41", "wide_0"."c42", "wide_0"."c43", "wide_0"."c44", "wide_0"."c45", "wide_0"."c46", "wide_0"."c47", "wide_0"."c48", "wide_0"."c49", "wide_0"."c50", "wide_0"."c51", "wide_0"."c52", "wide_0"."c53", "wide_0"."c54", "wide_0"."c55", "wide_0"."c56", "wide_0"."c57", "wide_0"."c58", "wide_0"."c59", "wide_0"."c60", "wide_0"."c61", "wide_0"."c62", "wide_0"."c63", "wide_0"."c64", "wide_0"."c65", "wide_0"."c66", "wide_0"."c67", "wide_0"."c68", "wide_0"."c69", "wide_0"."c70", "wide_0"."c71", "wide_0"."c72", "wide_0"."c73", "wide_0"."c74", "wide_0"."c75", "wide_0"."c76", "wide_0"."c77", "wide_0"."c78", "wide_0"."c79", "wide_0"."c80", "wide_0"."c81", "wide_0"."c82", "wide_0"."c83", "wide_0"."c84", "wide_0"."c85", "wide_0"."c86", "wide_0"."c87", "wide_0"."c88", "wide_0"."c89", "wide_0"."c90", "wide_0"."c91", "wide_0"."c92", "wide_0"."c93", "wide_0"."c94", "wide_0"."c95", "wide_0"."c96", "wide_0"."c97", "wide_0"."c98", "wide_0"."c99", "wide_0"."c100", "wide_0"."c101", "wide_0"."c102", "wide_0"."c103", "wide_0"."c104", "wide_0"."c105", "wide_0"."c106", "wide_0"."c107", "wide_0"."c108", "wide_0"."c109", "wide_0"."c110", "wide_0"."c111", "wide_0"."c112", "wide_0"."c113", "wide_0"."c114", "wide_0"."c115", "wide_0"."c116", "wide_0"."c117", "wide_0"."c118", "wide_0"."c119" FROM "public"."wide" AS "wide_0") AS "wide_0" LEFT OUTER JOIN LATERAL (SELECT coalesce(json_agg("__sr_1"."json"), '[]') AS "json" FROM (SELECT json_build_object('id', "items_1"."id") AS "json" FROM (SELECT "items_1"."id" FROM "public"."items" AS "items_1" WHERE ("wide_0"."id" = "items_1"."wide_id")) AS "items_1") AS "__sr_1") AS "__sj_1" ON true) AS "__sr_0") AS "__sj_0" ON true
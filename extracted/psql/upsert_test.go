@@ -0,0 +1,65 @@
+package psql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// memberSchema has members unique by organization and email
+func memberSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("members", "id pk", "org_id notnull", "email text notnull", "name text").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.Tables[0].Indexes = []schema.DBIndex{
+		{Name: "members_pkey", Columns: []string{"id"}, Primary: true, Unique: true, Constraint: true},
+		{Name: "members_org_email_key", Columns: []string{"org_id", "email"}, Unique: true, Constraint: true},
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileUpsert(t *testing.T) {
+	tests := []struct {
+		s           *schema.DBSchema
+		query, want string
+	}{
+		{blogSchema(t), `mutation { users(upsert: {email: "a"}) { id } }`,
+			`ON CONFLICT ("email") DO UPDATE SET "email" = EXCLUDED."email" RETURNING *`},
+		{blogSchema(t), `mutation { users(upsert: {id: 1, email: "a"}) { id } }`,
+			`ON CONFLICT ("id") DO UPDATE SET "email" = EXCLUDED."email" RETURNING *`},
+		// the columns of the key are not set again
+		{memberSchema(t), `mutation { members(upsert: {org_id: 1, email: "a", name: "b"}) { id } }`,
+			`ON CONFLICT ON CONSTRAINT "members_org_email_key" DO UPDATE SET "name" = EXCLUDED."name" RETURNING *`},
+		{memberSchema(t), `mutation { members(upsert: {id: 1, org_id: 1, email: "a"}) { id } }`,
+			`ON CONFLICT ON CONSTRAINT "members_pkey" DO UPDATE SET "org_id" = EXCLUDED."org_id", "email" = EXCLUDED."email" RETURNING *`},
+	}
+	for _, tt := range tests {
+		if got := compileSQL(t, tt.s, tt.query); !strings.Contains(got, tt.want) {
+			t.Errorf("%s: no %s in:\n%s", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestUpsertErrors(t *testing.T) {
+	s := memberSchema(t)
+	qc, err := qcode.NewCompiler(s).Compile([]byte(`mutation { members(upsert: {email: "a", name: "b"}) { id } }`), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	_, err = NewCompiler(s).Compile(&b, qc)
+	if err == nil || !strings.Contains(err.Error(), "requires a primary key or unique column value") {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package qcode
+
+import (
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// CompileRole compiles an operation for a role of the schema, tables and
+// columns the role cannot use are unknown to the query, the filters of
+// the role are added to the rows read, updated and deleted and only the
+// mutations the role is allowed compile. The variables of the filters
+// are listed in QCode.FilterVars and must be set by the caller
+func (co *Compiler) CompileRole(query []byte, opName, role string) (*QCode, error) {
+	if role != "" && !co.s.HasRole(role) {
+		return nil, fmt.Errorf("role not found: %s", role)
+	}
+	return co.compile(query, opName, role)
+}
+
+// tableAccess is the access of the role to a table along with the
+// table as it is in the schema, filters can use every column of it
+type tableAccess struct {
+	schema.TableAccess
+	ti schema.DBTable
+}
+
+// table returns a table as the role of the compilation sees it, the
+// access of the role is kept for the filters and mutation checks
+func (c *compiler) table(t schema.DBTable) (schema.DBTable, error) {
+	k := t.String()
+	if _, ok := c.acl[k]; ok {
+		t = c.acl[k].ti
+	} else if t.Partial {
+		// tables of paths in a lazy schema only hold their keys
+		ft, err := c.s.Find(t.Schema, t.Name)
+		if err != nil {
+			return t, err
+		}
+		t = ft
+	}
+
+	rt, acc, err := c.s.RoleTable(c.role, t)
+	if err != nil {
+		return t, err
+	}
+	c.acl[k] = tableAccess{TableAccess: acc, ti: t}
+	return rt, nil
+}
+
+// access returns the access of the role to a table
+func (c *compiler) access(t schema.DBTable) (tableAccess, error) {
+	if acc, ok := c.acl[t.String()]; ok {
+		return acc, nil
+	}
+	_, err := c.table(t)
+	return c.acl[t.String()], err
+}
+
+// pathAllowed returns true when the role can join through every table
+// of a path
+func (c *compiler) pathAllowed(path []schema.DBRel) (bool, error) {
+	for _, r := range path {
+		for _, t := range []schema.DBTable{r.Left.Ti, r.Right.Ti, r.Through.Ti} {
+			if t.Name == "" {
+				continue
+			}
+			rt, err := c.table(t)
+			if err != nil {
+				return false, err
+			}
+			if rt.Blocked {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// canWrite checks the role is allowed a mutation of a table
+func (c *compiler) canWrite(typ MType, t schema.DBTable, pos Pos) error {
+	acc, err := c.access(t)
+	if err != nil {
+		return err
+	}
+
+	ok := false
+	switch typ {
+	case MTInsert:
+		ok = acc.Insert
+	case MTUpdate, MTConnect, MTDisconnect:
+		ok = acc.Update
+	case MTUpsert:
+		// a filter cannot be checked against the row an upsert updates
+		ok = acc.Upsert && acc.Filter == ""
+	case MTDelete:
+		ok = acc.Delete
+	}
+
+	if !ok {
+		return errorf(pos, "%s on %s is not allowed", mutationName(typ), t.Name)
+	}
+	return nil
+}
+
+// mutationName returns the name of a mutation type
+func mutationName(typ MType) string {
+	switch typ {
+	case MTInsert:
+		return "insert"
+	case MTUpdate:
+		return "update"
+	case MTUpsert:
+		return "upsert"
+	case MTDelete:
+		return "delete"
+	case MTConnect:
+		return "connect"
+	}
+	return "disconnect"
+}
@@ -0,0 +1,110 @@
+package qcode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// roleSchema returns the blog schema with a member role that reads its
+// own posts and no emails and an anon role limited to the posts
+func roleSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("posts", "id pk", "user_id notnull", "title text notnull").
+		Table("comments", "id pk", "post_id", "body").
+		Table("likes", "id pk", "comment_id").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		FK("likes.comment_id", "comments.id").
+		BuildSchema(schema.WithRoles(
+			schema.Role{Name: "member", Tables: []schema.RoleTable{
+				{Table: "users", BlockColumns: []string{"email"}},
+				{Table: "posts", Filter: `{ user_id: { eq: $user_id } }`, Insert: true, Upsert: true},
+			}},
+			schema.Role{Name: "anon", Restricted: true, Tables: []schema.RoleTable{
+				{Table: "posts", Columns: []string{"id", "title"}},
+				{Table: "comments", Block: true},
+				{Table: "likes"},
+			}},
+		))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestCompileRole(t *testing.T) {
+	s := roleSchema(t)
+	tests := []struct {
+		role, query string
+		ok          bool
+	}{
+		{"", `{ users { id email } }`, true},
+		{"member", `{ users { id } }`, true},
+		{"member", `{ users { id email } }`, false},
+		{"member", `{ comments { id } }`, true},
+		{"member", `{ posts { id likes { id } } }`, true},
+		{"anon", `{ posts { id title } }`, true},
+		{"anon", `{ posts { id user_id } }`, false},
+		{"anon", `{ users { id } }`, false},
+		{"anon", `{ posts { id comments { id } } }`, false},
+		// the role cannot join through a table it is denied
+		{"anon", `{ likes { id } }`, true},
+		{"anon", `{ posts { id likes { id } } }`, false},
+		{"member", `mutation { posts(insert: {title: "a", user_id: 1}) { id } }`, true},
+		{"member", `mutation { posts(update: {title: "a"}, where: {id: {eq: 1}}) { id } }`, false},
+		{"member", `mutation { posts(delete: true, where: {id: {eq: 1}}) { id } }`, false},
+		// the filter cannot be checked against the row an upsert updates
+		{"member", `mutation { posts(upsert: {id: 1, title: "a", user_id: 1}) { id } }`, false},
+		{"member", `mutation { comments(insert: {body: "a"}) { id } }`, true},
+		{"member", `mutation { users(insert: {email: "a"}) { id } }`, false},
+		{"anon", `mutation { posts(insert: {title: "a"}) { id } }`, false},
+	}
+	for _, tt := range tests {
+		_, err := NewCompiler(s).CompileRole([]byte(tt.query), "", tt.role)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s %s: got error %v", tt.role, tt.query, err)
+		}
+	}
+
+	if _, err := NewCompiler(s).CompileRole([]byte(`{ posts { id } }`), "", "admin"); err == nil {
+		t.Error("want an error for an unknown role")
+	}
+}
+
+func TestRoleFilter(t *testing.T) {
+	s := roleSchema(t)
+	qc, err := NewCompiler(s).CompileRole([]byte(`{ posts { id } }`), "", "member")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(qc.FilterVars, []string{"user_id"}) {
+		t.Errorf("got filter variables %v", qc.FilterVars)
+	}
+	if qc.Selects[0].Where == nil {
+		t.Error("the filter of the role is not added")
+	}
+
+	// the variables of a filter are set by the caller
+	q := `query ($user_id: ID!) { posts { id } }`
+	if _, err := NewCompiler(s).CompileRole([]byte(q), "", "member"); err == nil {
+		t.Error("want an error for an operation setting a filter variable")
+	}
+}
+
+func TestRoleErrors(t *testing.T) {
+	for _, r := range []schema.Role{
+		{},
+		{Name: "a", Tables: []schema.RoleTable{{Table: "missing"}}},
+		{Name: "a", Tables: []schema.RoleTable{{Table: "users", Columns: []string{"missing"}}}},
+		{Name: "a", Tables: []schema.RoleTable{{Table: "users"}, {Table: "users"}}},
+	} {
+		_, err := schema.NewTestSchema().Table("users", "id pk").BuildSchema(schema.WithRoles(r))
+		if err == nil {
+			t.Errorf("%+v: want an error", r)
+		}
+	}
+}
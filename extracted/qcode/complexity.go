@@ -0,0 +1,175 @@
+package qcode
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+const (
+	unknownRows   = 1000 // rows of a table without a row count
+	unknownFanOut = 10   // rows a row joins to when a row count is missing
+)
+
+// Limits are the most a query can ask of the database, a zero limit is
+// not checked. A query over a limit is an *ErrTooComplex unless Truncate
+// is set, selections deeper than MaxDepth are then left out
+type Limits struct {
+	MaxDepth  int   // deepest nesting of selections, roots are at 1
+	MaxTables int   // tables joined by the selections and aggregates
+	MaxCost   int64 // estimated rows read, see Complexity
+	Truncate  bool
+}
+
+// WithLimits checks the complexity of every query compiled against the
+// limits
+func WithLimits(l Limits) Option {
+	return func(co *Compiler) {
+		co.limits = l
+	}
+}
+
+// Complexity is what a query asks of the database, Cost is the number
+// of rows it reads estimated from the row counts of the tables, the
+// limits of the selections and one row for an object
+type Complexity struct {
+	Depth  int
+	Tables int
+	Cost   int64
+}
+
+// ErrTooComplex is returned when a query is over one of the Limits
+type ErrTooComplex struct {
+	Limit      string // depth, tables or cost
+	Value, Max int64
+}
+
+// Error returns the error message with the limit exceeded
+func (e *ErrTooComplex) Error() string {
+	return fmt.Sprintf("query too complex: %s %d is over the limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// Is makes errors.Is match any ErrTooComplex
+func (e *ErrTooComplex) Is(target error) bool {
+	_, ok := target.(*ErrTooComplex)
+	return ok
+}
+
+// Complexity returns the complexity of a compiled query
+func (qc *QCode) Complexity() Complexity {
+	var cx Complexity
+	rows := make([]int64, len(qc.Selects))
+
+	for i := range qc.Selects {
+		sel := &qc.Selects[i]
+
+		// parents are added before their children
+		n := int64(1)
+		if sel.ParentID != -1 {
+			n = rows[sel.ParentID]
+		}
+		rows[i] = mulRows(n, qc.selectRows(sel))
+		cx.Cost = addRows(cx.Cost, rows[i])
+
+		if d := qc.depth(sel); d > cx.Depth {
+			cx.Depth = d
+		}
+
+		cx.Tables++
+		for j, r := range sel.Path {
+			if j != 0 {
+				cx.Tables++
+			}
+			if r.Through.Ti.Name != "" {
+				cx.Tables++
+			}
+		}
+
+		for _, f := range sel.Fields {
+			if f.Type == FieldAgg {
+				cx.Tables++
+				cx.Cost = addRows(cx.Cost, mulRows(rows[i], fanOut(f.Agg.Rel.Left.Ti.RowCount, f.Agg.Rel.Right.Ti.RowCount)))
+			}
+		}
+	}
+	return cx
+}
+
+// selectRows returns the rows of a selection for each row of its parent
+func (qc *QCode) selectRows(sel *Select) int64 {
+	if sel.Singular {
+		return 1
+	}
+
+	var n int64 = 1
+	switch {
+	case sel.ParentID != -1:
+	case qc.Type == QTMutation:
+		// the roots of a mutation return the rows written
+		n = int64(len(qc.Mutates))
+	case sel.Ti.RowCount > 0:
+		n = sel.Ti.RowCount
+	default:
+		n = unknownRows
+	}
+	for _, r := range sel.Path {
+		n = mulRows(n, fanOut(r.Left.Ti.RowCount, r.Right.Ti.RowCount))
+	}
+
+	if sel.Paging.Limit.Type == ValNum {
+		if l, err := strconv.ParseInt(sel.Paging.Limit.Val, 10, 64); err == nil && l < n {
+			n = l
+		}
+	}
+	return n
+}
+
+// depth returns the level of a selection, roots are at 1
+func (qc *QCode) depth(sel *Select) int {
+	d := 1
+	for id := sel.ParentID; id != -1; id = qc.Selects[id].ParentID {
+		d++
+	}
+	return d
+}
+
+// fanOut returns the rows of a table each row of another table joins to
+func fanOut(from, to int64) int64 {
+	if from < 1 || to < 1 {
+		return unknownFanOut
+	}
+	if n := to / from; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// mulRows and addRows are * and + of row counts that stop at math.MaxInt64
+func mulRows(a, b int64) int64 {
+	if a != 0 && b > math.MaxInt64/a {
+		return math.MaxInt64
+	}
+	return a * b
+}
+
+func addRows(a, b int64) int64 {
+	if a > math.MaxInt64-b {
+		return math.MaxInt64
+	}
+	return a + b
+}
+
+// check returns an *ErrTooComplex when a query is over a limit
+func (l Limits) check(qc *QCode) error {
+	cx := qc.Complexity()
+
+	switch {
+	case l.MaxDepth > 0 && cx.Depth > l.MaxDepth:
+		return &ErrTooComplex{Limit: "depth", Value: int64(cx.Depth), Max: int64(l.MaxDepth)}
+	case l.MaxTables > 0 && cx.Tables > l.MaxTables:
+		return &ErrTooComplex{Limit: "tables", Value: int64(cx.Tables), Max: int64(l.MaxTables)}
+	case l.MaxCost > 0 && cx.Cost > l.MaxCost:
+		return &ErrTooComplex{Limit: "cost", Value: cx.Cost, Max: l.MaxCost}
+	}
+	return nil
+}
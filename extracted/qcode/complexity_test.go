@@ -0,0 +1,101 @@
+package qcode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// countedSchema has 100 users with 1000 posts with 10000 comments and
+// tags without a row count
+func countedSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id notnull").
+		Table("comments", "id pk", "post_id notnull").
+		Table("tags", "id pk", "post_id notnull").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		FK("tags.post_id", "posts.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, n := range map[string]int64{"users": 100, "posts": 1000, "comments": 10000} {
+		ti, err := di.GetTable("public", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ti.RowCount = n
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestComplexity(t *testing.T) {
+	s := countedSchema(t)
+	tests := []struct {
+		query string
+		want  Complexity
+	}{
+		{`{ users { id } }`, Complexity{Depth: 1, Tables: 1, Cost: 100}},
+		// 10 users with 10 posts each with 10 comments each
+		{`{ users(limit: 10) { id posts { id comments { id } } } }`, Complexity{Depth: 3, Tables: 3, Cost: 1110}},
+		// an object is a row
+		{`{ posts(limit: 5) { id user { id } } }`, Complexity{Depth: 2, Tables: 2, Cost: 10}},
+		// tags without a row count
+		{`{ tags { id } }`, Complexity{Depth: 1, Tables: 1, Cost: 1000}},
+	}
+	for _, tt := range tests {
+		qc, err := NewCompiler(s).Compile([]byte(tt.query), "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.query, err)
+		}
+		if got := qc.Complexity(); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := countedSchema(t)
+	const query = `{ users(limit: 10) { id posts { id comments { id } } } }`
+	tests := []struct {
+		limits Limits
+		limit  string
+	}{
+		{Limits{MaxDepth: 2}, "depth"},
+		{Limits{MaxTables: 2}, "tables"},
+		{Limits{MaxCost: 1000}, "cost"},
+		{Limits{MaxDepth: 3, MaxTables: 3, MaxCost: 1110}, ""},
+	}
+	for _, tt := range tests {
+		_, err := NewCompiler(s, WithLimits(tt.limits)).Compile([]byte(query), "")
+		var ec *ErrTooComplex
+		switch {
+		case tt.limit == "" && err != nil:
+			t.Errorf("%+v: %v", tt.limits, err)
+		case tt.limit != "" && (!errors.As(err, &ec) || ec.Limit != tt.limit):
+			t.Errorf("%+v: got %v, want the %s limit", tt.limits, err, tt.limit)
+		}
+	}
+
+	// the selections over the depth are left out
+	qc, err := NewCompiler(s, WithLimits(Limits{MaxDepth: 2, Truncate: true})).Compile([]byte(query), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cx := qc.Complexity(); cx.Depth != 2 || len(qc.Selects) != 2 {
+		t.Errorf("got %+v with %d selections", cx, len(qc.Selects))
+	}
+
+	err = &ErrTooComplex{Limit: "depth", Value: 3, Max: 2}
+	if !errors.Is(err, &ErrTooComplex{}) || err.Error() != "query too complex: depth 3 is over the limit of 2" {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package qcode
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// CursorSuffix is added to the key of a list paged with a cursor to
+// get the key of its cursor, the cursor of products is returned as
+// products_cursor next to it
+const CursorSuffix = "_cursor"
+
+// cursorArgs are the arguments of cursor pagination, first and after
+// page forward and last and before page backward as with Relay
+// connections
+var cursorArgs = map[string]bool{
+	"first":  true,
+	"after":  true,
+	"last":   true,
+	"before": true,
+}
+
+// cursorKey returns the result key of the cursor of a field if it is
+// paged with a cursor
+func (f *field) cursorKey() (string, bool) {
+	for _, a := range f.args {
+		if cursorArgs[a.name] {
+			return f.key() + CursorSuffix, true
+		}
+	}
+	return "", false
+}
+
+// cursorArg compiles first, after, last or before, the page size is
+// the limit of the selection and the cursor the row it starts after
+func (c *compiler) cursorArg(sel *Select, a argument) error {
+	p := &sel.Paging
+	backward := a.name == "last" || a.name == "before"
+
+	if p.Cursor && p.Backward != backward {
+		return errorf(a.pos, "first and after cannot be used with last or before")
+	}
+	p.Cursor, p.Backward = true, backward
+
+	var err error
+	switch a.name {
+	case "first", "last":
+		p.Limit, err = c.intValue(a.val)
+	default:
+		// a null cursor asks for the first page, so does a variable
+		// set to null
+		switch a.val.typ {
+		case valNull:
+		case valStr, valVar:
+			p.From, err = c.value(a.val)
+		default:
+			err = errorf(a.val.pos, "expected a cursor found %s", a.val)
+		}
+	}
+	return err
+}
+
+// cursorPaging checks the arguments used with a cursor and completes
+// the ordering so every row has a distinct position, a row is found
+// again from the values of its ordering columns
+func (c *compiler) cursorPaging(sel *Select, f *field) error {
+	for _, a := range f.args {
+		switch a.name {
+		case "limit", "offset", "distinct":
+			return errorf(a.pos, "%s cannot be used with a cursor", a.name)
+		}
+	}
+	if sel.Singular {
+		return errorf(f.pos, "cursor cannot be used on a single row selection: %s", f.key())
+	}
+
+	t := sel.Ti
+	if orderedUniquely(t, sel.OrderBy) {
+		return nil
+	}
+
+	key := cursorKeyCols(t)
+	if len(key) == 0 {
+		return errorf(f.pos, "cursor pagination on %s requires a primary key or unique index", t.Name)
+	}
+
+	for _, col := range key {
+		dup := false
+		for _, ob := range sel.OrderBy {
+			dup = dup || ob.Col.Name == col.Name
+		}
+		if !dup {
+			sel.OrderBy = append(sel.OrderBy, OrderBy{Col: col, Order: OrderAsc})
+		}
+	}
+	return nil
+}
+
+// orderedUniquely returns true if the ordering includes the primary
+// key, a unique column or all the columns of a unique index
+func orderedUniquely(t schema.DBTable, ob []OrderBy) bool {
+	ordered := make(map[string]struct{}, len(ob))
+	for _, o := range ob {
+		if o.Col.PrimaryKey || (o.Col.UniqueKey && o.Col.NotNull) {
+			return true
+		}
+		ordered[o.Col.Name] = struct{}{}
+	}
+
+	for _, idx := range uniqueIndexes(t) {
+		all := true
+		for _, name := range idx.Columns {
+			_, ok := ordered[name]
+			all = all && ok
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// cursorKeyCols returns the columns added to an ordering to make it
+// unique, the primary key or else the columns of the first unique index
+func cursorKeyCols(t schema.DBTable) []schema.DBColumn {
+	if t.PrimaryCol.Name != "" && !t.PrimaryCol.Blocked {
+		return []schema.DBColumn{t.PrimaryCol}
+	}
+
+	for _, idx := range uniqueIndexes(t) {
+		cols := make([]schema.DBColumn, len(idx.Columns))
+		for i, name := range idx.Columns {
+			cols[i], _ = t.ColumnExists(name)
+		}
+		return cols
+	}
+	return nil
+}
+
+// uniqueIndexes returns the unique indexes of a table that can order
+// rows by, partial indexes and those with nullable columns are left out
+// as they allow duplicate rows
+func uniqueIndexes(t schema.DBTable) []schema.DBIndex {
+	var indexes []schema.DBIndex
+
+outer:
+	for _, idx := range t.Indexes {
+		if !idx.Unique || idx.Predicate != "" || len(idx.Columns) == 0 {
+			continue
+		}
+		for _, name := range idx.Columns {
+			col, ok := t.ColumnExists(name)
+			if !ok || col.Blocked || (!col.NotNull && !col.PrimaryKey) {
+				continue outer
+			}
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes
+}
+
+// reserveCursorKey adds the cursor key of a field to the keys of its
+// parent so no other field can use it
+func reserveCursorKey(keys map[string]struct{}, f *field) error {
+	k, ok := f.cursorKey()
+	if !ok {
+		return nil
+	}
+	if _, ok := keys[k]; ok {
+		return errorf(f.pos, "duplicate field: %s", k)
+	}
+	keys[k] = struct{}{}
+	return nil
+}
@@ -0,0 +1,510 @@
+package qcode
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// ExpOp is the operator of a filter expression
+type ExpOp int
+
+const (
+	OpNop ExpOp = iota
+	OpAnd
+	OpOr
+	OpNot
+	OpEquals
+	OpNotEquals
+	OpGreaterThan
+	OpGreaterOrEquals
+	OpLesserThan
+	OpLesserOrEquals
+	OpIn
+	OpNotIn
+	OpLike
+	OpNotLike
+	OpILike
+	OpNotILike
+	OpRegex
+	OpNotRegex
+	OpIsNull
+	OpContains
+	OpContainedIn
+	OpOverlaps
+	OpHasKey
+	OpDWithin
+	OpSTContains
+	OpSTWithin
+	OpSTIntersects
+)
+
+// expOps maps the operator names used in where arguments
+var expOps = map[string]ExpOp{
+	"eq":                OpEquals,
+	"equals":            OpEquals,
+	"neq":               OpNotEquals,
+	"not_equals":        OpNotEquals,
+	"gt":                OpGreaterThan,
+	"greater_than":      OpGreaterThan,
+	"gte":               OpGreaterOrEquals,
+	"greater_or_equals": OpGreaterOrEquals,
+	"lt":                OpLesserThan,
+	"lesser_than":       OpLesserThan,
+	"lte":               OpLesserOrEquals,
+	"lesser_or_equals":  OpLesserOrEquals,
+	"in":                OpIn,
+	"nin":               OpNotIn,
+	"not_in":            OpNotIn,
+	"like":              OpLike,
+	"nlike":             OpNotLike,
+	"not_like":          OpNotLike,
+	"ilike":             OpILike,
+	"nilike":            OpNotILike,
+	"not_ilike":         OpNotILike,
+	"regex":             OpRegex,
+	"nregex":            OpNotRegex,
+	"not_regex":         OpNotRegex,
+	"is_null":           OpIsNull,
+	"contains":          OpContains,
+	"contained_in":      OpContainedIn,
+	"overlaps":          OpOverlaps,
+	"has_key":           OpHasKey,
+	"st_dwithin":        OpDWithin,
+	"st_contains":       OpSTContains,
+	"st_within":         OpSTWithin,
+	"st_intersects":     OpSTIntersects,
+}
+
+// Exp is a filter expression, OpAnd, OpOr and OpNot combine their
+// children and other operators compare Col with Val. Spatial operators
+// compare Col with Shape
+type Exp struct {
+	Op       ExpOp
+	Col      schema.DBColumn
+	Val      Value
+	Shape    *Shape
+	Children []*Exp
+}
+
+// ValType is the type of a value
+type ValType int
+
+const (
+	ValNone ValType = iota
+	ValStr
+	ValNum
+	ValBool
+	ValNull
+	ValList
+	ValVar
+	ValNow // the time of the statement, set by the compiler in audit columns
+)
+
+// Value is a constant or a variable, Val is the variable name for
+// variables and List holds the items of lists
+type Value struct {
+	Type ValType
+	Val  string
+	List []Value
+}
+
+// Order is the sort order of an order by column
+type Order int
+
+const (
+	OrderAsc Order = iota
+	OrderDesc
+	OrderAscNullsFirst
+	OrderAscNullsLast
+	OrderDescNullsFirst
+	OrderDescNullsLast
+)
+
+var orders = map[string]Order{
+	"asc":              OrderAsc,
+	"desc":             OrderDesc,
+	"asc_nulls_first":  OrderAscNullsFirst,
+	"asc_nulls_last":   OrderAscNullsLast,
+	"desc_nulls_first": OrderDescNullsFirst,
+	"desc_nulls_last":  OrderDescNullsLast,
+}
+
+// OrderBy is a column to sort a selection by, when Rank is true the
+// selection is sorted by the rank of its search and Col only has the
+// name and type of the rank
+type OrderBy struct {
+	Col   schema.DBColumn
+	Order Order
+	Rank  bool
+}
+
+// compileArgs sets the filters, ordering, paging and function
+// arguments of a selection from the field arguments
+func (c *compiler) compileArgs(sel *Select, f *field) error {
+	t := sel.Ti
+
+	for _, a := range f.args {
+		var err error
+
+		switch a.name {
+		case "id":
+			err = c.argID(sel, a)
+		case "where":
+			var ex *Exp
+			if ex, err = c.exp(t, a.val); err == nil {
+				sel.Where = andExp(sel.Where, ex)
+			}
+		case "order_by":
+			sel.OrderBy, err = c.orderBy(t, a.val)
+		case "distinct":
+			sel.DistinctOn, err = c.distinct(t, a.val)
+		case "limit":
+			sel.Paging.Limit, err = c.intValue(a.val)
+		case "offset":
+			sel.Paging.Offset, err = c.intValue(a.val)
+		case "first", "after", "last", "before":
+			err = c.cursorArg(sel, a)
+		case "find":
+			err = c.find(sel, a)
+		case "depth":
+			err = c.depthArg(sel, a)
+		case "search":
+			sel.Search, err = c.search(t, a.val)
+		case "with_deleted":
+			sel.WithDeleted, err = c.withDeleted(t, a.val)
+		case "args":
+			if t.Type != "function" {
+				return errorf(a.pos, "%s is not a function table", t.Name)
+			}
+			sel.Args, err = c.funcArgs(t, a.val)
+		default:
+			err = errorf(a.pos, "unknown argument: %s", a.name)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if sel.Recursive != nil && sel.Recursive.Find == 0 {
+		return errorf(f.pos, "depth requires find")
+	}
+
+	if err := c.rankOrder(sel, f); err != nil {
+		return err
+	}
+
+	if sel.Paging.Cursor {
+		return c.cursorPaging(sel, f)
+	}
+	return nil
+}
+
+// argID filters a selection by primary key and makes it singular
+func (c *compiler) argID(sel *Select, a argument) error {
+	pk := sel.Ti.PrimaryCol
+	if pk.Name == "" || pk.Blocked {
+		return errorf(a.pos, "%s has no primary key", sel.Ti.Name)
+	}
+
+	v, err := c.scalarValue(a.val)
+	if err != nil {
+		return err
+	}
+
+	sel.Where = andExp(sel.Where, &Exp{Op: OpEquals, Col: pk, Val: v})
+	sel.Singular = true
+	return nil
+}
+
+// andExp joins two expressions with an and
+func andExp(a, b *Exp) *Exp {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case a.Op == OpAnd:
+		a.Children = append(a.Children, b)
+		return a
+	}
+	return &Exp{Op: OpAnd, Children: []*Exp{a, b}}
+}
+
+// exp compiles a where object eg. { price: { gt: 10 }, or: [...] },
+// the keys of an object are joined with an and
+func (c *compiler) exp(t schema.DBTable, v *value) (*Exp, error) {
+	if v.typ != valObj || len(v.obj) == 0 {
+		return nil, errorf(v.pos, "expected an expression object found %s", v)
+	}
+
+	var ex *Exp
+	for _, a := range v.obj {
+		e, err := c.expKey(t, a)
+		if err != nil {
+			return nil, err
+		}
+		ex = andExp(ex, e)
+	}
+	return ex, nil
+}
+
+// expKey compiles a single key of a where object
+func (c *compiler) expKey(t schema.DBTable, a argument) (*Exp, error) {
+	switch a.name {
+	case "and", "or":
+		ex := &Exp{Op: OpAnd}
+		if a.name == "or" {
+			ex.Op = OpOr
+		}
+
+		items := []*value{a.val}
+		if a.val.typ == valList {
+			items = a.val.list
+		}
+		if len(items) == 0 {
+			return nil, errorf(a.val.pos, "%s requires at least one expression", a.name)
+		}
+
+		for _, item := range items {
+			e, err := c.exp(t, item)
+			if err != nil {
+				return nil, err
+			}
+			ex.Children = append(ex.Children, e)
+		}
+		return ex, nil
+
+	case "not":
+		e, err := c.exp(t, a.val)
+		if err != nil {
+			return nil, err
+		}
+		return &Exp{Op: OpNot, Children: []*Exp{e}}, nil
+	}
+
+	col, ok := t.ColumnExists(a.name)
+	if !ok || col.Blocked {
+		return nil, errorf(a.pos, "unknown column: %s.%s", t.Name, a.name)
+	}
+
+	if a.val.typ != valObj || len(a.val.obj) == 0 {
+		return nil, errorf(a.val.pos, "expected an operator object for %s found %s", a.name, a.val)
+	}
+
+	var ex *Exp
+	for _, o := range a.val.obj {
+		e, err := c.colExp(col, o)
+		if err != nil {
+			return nil, err
+		}
+		ex = andExp(ex, e)
+	}
+	return ex, nil
+}
+
+// colExp compiles an operator on a column eg. gt: 10
+func (c *compiler) colExp(col schema.DBColumn, o argument) (*Exp, error) {
+	op, ok := expOps[o.name]
+	if !ok {
+		return nil, errorf(o.pos, "unknown operator: %s", o.name)
+	}
+	ex := &Exp{Op: op, Col: col}
+
+	var err error
+	switch op {
+	case OpIn, OpNotIn:
+		ex.Val, err = c.value(o.val)
+		if err == nil && ex.Val.Type != ValList && ex.Val.Type != ValVar {
+			err = errorf(o.val.pos, "%s requires a list", o.name)
+		}
+
+	case OpIsNull:
+		ex.Val, err = c.value(o.val)
+		if err == nil && ex.Val.Type != ValBool && ex.Val.Type != ValVar {
+			err = errorf(o.val.pos, "%s requires a boolean", o.name)
+		}
+
+	case OpContains, OpContainedIn:
+		ex.Val, err = c.value(o.val)
+
+	case OpDWithin, OpSTContains, OpSTWithin, OpSTIntersects:
+		ex.Shape, err = c.shape(col, o)
+
+	case OpOverlaps:
+		if !col.Array {
+			return nil, errorf(o.pos, "%s requires an array column: %s", o.name, col.Name)
+		}
+		ex.Val, err = c.value(o.val)
+		if err == nil && ex.Val.Type != ValList && ex.Val.Type != ValVar {
+			err = errorf(o.val.pos, "%s requires a list", o.name)
+		}
+
+	default:
+		ex.Val, err = c.scalarValue(o.val)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return ex, nil
+}
+
+// orderBy compiles an order by object or a list of them, the columns
+// of an object are sorted in the order they are written
+func (c *compiler) orderBy(t schema.DBTable, v *value) ([]OrderBy, error) {
+	items := []*value{v}
+	if v.typ == valList {
+		items = v.list
+	}
+
+	var ob []OrderBy
+	for _, item := range items {
+		if item.typ != valObj {
+			return nil, errorf(item.pos, "expected an order by object found %s", item)
+		}
+
+		for _, a := range item.obj {
+			o, ok := orders[a.val.val]
+			if !ok || (a.val.typ != valEnum && a.val.typ != valStr) {
+				return nil, errorf(a.val.pos, "invalid sort order: %s", a.val)
+			}
+
+			col, ok := t.ColumnExists(a.name)
+			switch {
+			case !ok && a.name == SearchRank:
+				ob = append(ob, rankOrderBy(o))
+				continue
+			case !ok || col.Blocked:
+				return nil, errorf(a.pos, "unknown column: %s.%s", t.Name, a.name)
+			}
+			ob = append(ob, OrderBy{Col: col, Order: o})
+		}
+	}
+	return ob, nil
+}
+
+// distinct compiles a column name or a list of column names
+func (c *compiler) distinct(t schema.DBTable, v *value) ([]schema.DBColumn, error) {
+	items := []*value{v}
+	if v.typ == valList {
+		items = v.list
+	}
+
+	var cols []schema.DBColumn
+	for _, item := range items {
+		if item.typ != valStr && item.typ != valEnum {
+			return nil, errorf(item.pos, "expected a column name found %s", item)
+		}
+		col, ok := t.ColumnExists(item.val)
+		if !ok || col.Blocked {
+			return nil, errorf(item.pos, "unknown column: %s.%s", t.Name, item.val)
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// funcArgs compiles the arguments of a function table given as an
+// object of named inputs or a list of positional ones
+func (c *compiler) funcArgs(t schema.DBTable, v *value) ([]Arg, error) {
+	fn := t.Func
+	var args []Arg
+
+	switch v.typ {
+	case valList:
+		if len(v.list) > len(fn.Inputs) {
+			return nil, errorf(v.pos, "%s takes %d arguments", fn.Name, len(fn.Inputs))
+		}
+		for i, item := range v.list {
+			val, err := c.value(item)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, Arg{Param: fn.Inputs[i], Val: val})
+		}
+
+	case valObj:
+		vals := make(map[string]Value, len(v.obj))
+		for _, a := range v.obj {
+			if _, err := fn.GetInput(a.name); err != nil {
+				return nil, errorf(a.pos, "unknown argument: %s", a.name)
+			}
+			val, err := c.value(a.val)
+			if err != nil {
+				return nil, err
+			}
+			vals[a.name] = val
+		}
+		for _, in := range fn.Inputs {
+			if val, ok := vals[in.Name]; ok {
+				args = append(args, Arg{Param: in, Val: val})
+			}
+		}
+
+	default:
+		return nil, errorf(v.pos, "expected an object or a list of arguments found %s", v)
+	}
+	return args, nil
+}
+
+// intValue returns an integer constant or a variable
+func (c *compiler) intValue(v *value) (Value, error) {
+	if v.typ != valInt && v.typ != valVar {
+		return Value{}, errorf(v.pos, "expected an integer found %s", v)
+	}
+	return c.value(v)
+}
+
+// withDeleted returns the constant of a with_deleted argument, the table
+// must be soft deleted
+func (c *compiler) withDeleted(t schema.DBTable, v *value) (bool, error) {
+	if _, ok := c.s.SoftDelete(t); !ok {
+		return false, errorf(v.pos, "%s has no soft deleted rows", t.Name)
+	}
+	if v.typ != valBool {
+		return false, errorf(v.pos, "with_deleted must be true or false found %s", v)
+	}
+	return v.val == "true", nil
+}
+
+// scalarValue returns a value that is not a list
+func (c *compiler) scalarValue(v *value) (Value, error) {
+	if v.typ == valList {
+		return Value{}, errorf(v.pos, "expected a single value found %s", v)
+	}
+	return c.value(v)
+}
+
+// value converts a parsed value, variables must be defined by the
+// operation and objects are only allowed as function arguments
+func (c *compiler) value(v *value) (Value, error) {
+	switch v.typ {
+	case valStr, valEnum:
+		return Value{Type: ValStr, Val: v.val}, nil
+	case valInt, valFloat:
+		return Value{Type: ValNum, Val: v.val}, nil
+	case valBool:
+		return Value{Type: ValBool, Val: v.val}, nil
+	case valNull:
+		return Value{Type: ValNull}, nil
+
+	case valVar:
+		if _, ok := c.vars[v.val]; !ok {
+			return Value{}, errorf(v.pos, "variable not defined: $%s", v.val)
+		}
+		return Value{Type: ValVar, Val: v.val}, nil
+
+	case valList:
+		val := Value{Type: ValList}
+		for _, item := range v.list {
+			iv, err := c.scalarValue(item)
+			if err != nil {
+				return Value{}, err
+			}
+			val.List = append(val.List, iv)
+		}
+		return val, nil
+	}
+
+	return Value{}, errorf(v.pos, "unexpected object value %s", v)
+}
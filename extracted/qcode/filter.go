@@ -0,0 +1,147 @@
+package qcode
+
+import (
+	"fmt"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// JoinFilter holds the filters of the tables a hop of a path joins
+// through, Left is the table the hop starts at and Through the join
+// table of a many to many hop
+type JoinFilter struct {
+	Left    *Exp
+	Through *Exp
+}
+
+// filter returns the filters every row of a table must match, the
+// filters of the table in the schema and of the role and the exclusion
+// of its soft deleted rows. It is nil when the rows are not filtered
+func (c *compiler) filter(t schema.DBTable) (*Exp, error) {
+	return c.rowFilter(t, false)
+}
+
+// rowFilter is filter keeping the soft deleted rows when deleted is true
+func (c *compiler) rowFilter(t schema.DBTable, deleted bool) (*Exp, error) {
+	acc, err := c.access(t)
+	if err != nil {
+		return nil, err
+	}
+
+	srcs := c.s.TableFilters(acc.ti)
+	if sd := c.s.SoftDeleteFilter(acc.ti); sd != "" && !deleted {
+		srcs = append(srcs[:len(srcs):len(srcs)], sd)
+	}
+
+	var ex *Exp
+	for _, src := range srcs {
+		e, err := c.compileFilter(acc.ti, src)
+		if err != nil {
+			return nil, fmt.Errorf("filter on %s: %s", t.Name, err)
+		}
+		ex = andExp(ex, e)
+	}
+
+	if acc.Filter != "" {
+		e, err := c.compileFilter(acc.ti, acc.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("role %s: filter on %s: %s", c.role, t.Name, err)
+		}
+		ex = andExp(ex, e)
+	}
+	return ex, nil
+}
+
+// joinFilters returns the filters of the tables a path joins through,
+// nil when none of them is filtered. The first hop starts at the parent
+// which is filtered by its own selection, deleted keeps the soft deleted
+// rows of the tables
+func (c *compiler) joinFilters(path []schema.DBRel, deleted bool) ([]JoinFilter, error) {
+	jf := make([]JoinFilter, len(path))
+	found := false
+
+	for i, r := range path {
+		var err error
+		if i != 0 {
+			if jf[i].Left, err = c.rowFilter(r.Left.Ti, deleted); err != nil {
+				return nil, err
+			}
+		}
+		if r.Type == schema.RelManyToMany {
+			if jf[i].Through, err = c.rowFilter(r.Through.Ti, deleted); err != nil {
+				return nil, err
+			}
+		}
+		found = found || jf[i].Left != nil || jf[i].Through != nil
+	}
+
+	if !found {
+		return nil, nil
+	}
+	return jf, nil
+}
+
+// compileFilter compiles a where expression given outside of the query
+// against every column of a table. Its variables are not defined by the
+// operation, they are listed in QCode.FilterVars and cannot be used by
+// the operation
+func (c *compiler) compileFilter(t schema.DBTable, src string) (*Exp, error) {
+	v, err := parseValueString(src)
+	if err != nil {
+		return nil, err
+	}
+	if v.typ != valObj {
+		return nil, fmt.Errorf("expected an expression object found %s", v)
+	}
+
+	var added []string
+	defer func() {
+		for _, name := range added {
+			delete(c.vars, name)
+		}
+	}()
+
+	for _, name := range valueVars(v, nil) {
+		if _, ok := c.vars[name]; ok {
+			return nil, fmt.Errorf("variable $%s is reserved by the filter", name)
+		}
+		if !c.isFilterVar(name) {
+			c.qc.FilterVars = append(c.qc.FilterVars, name)
+		}
+		c.vars[name] = struct{}{}
+		added = append(added, name)
+	}
+	return c.exp(t, v)
+}
+
+// isFilterVar returns true for a variable already used by a filter
+func (c *compiler) isFilterVar(name string) bool {
+	for _, v := range c.qc.FilterVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// valueVars appends the variables used in a value
+func valueVars(v *value, names []string) []string {
+	switch v.typ {
+	case valVar:
+		for _, n := range names {
+			if n == v.val {
+				return names
+			}
+		}
+		names = append(names, v.val)
+	case valList:
+		for _, item := range v.list {
+			names = valueVars(item, names)
+		}
+	case valObj:
+		for _, a := range v.obj {
+			names = valueVars(a.val, names)
+		}
+	}
+	return names
+}
@@ -0,0 +1,132 @@
+package qcode
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+)
+
+var valTypes = []string{"none", "str", "num", "bool", "null", "list", "var", "now"}
+
+// valueText returns a value with its type, strings are quoted as Go strings
+// so the quotes they hold are seen
+func valueText(v Value) string {
+	switch v.Type {
+	case ValStr:
+		return "str " + fmt.Sprintf("%q", v.Val)
+	case ValList:
+		items := make([]string, len(v.List))
+		for i, item := range v.List {
+			items[i] = valueText(item)
+		}
+		return "list [" + strings.Join(items, ", ") + "]"
+	case ValNone, ValNow:
+		return valTypes[v.Type]
+	}
+	return valTypes[v.Type] + " " + v.Val
+}
+
+// opName returns the shortest name of an operator in where arguments
+func opName(op ExpOp) string {
+	switch op {
+	case OpAnd:
+		return "and"
+	case OpOr:
+		return "or"
+	case OpNot:
+		return "not"
+	}
+	return shortest(expOps, op)
+}
+
+// shortest returns the shortest key of a value of a map
+func shortest[V comparable](m map[string]V, v V) string {
+	name := ""
+	for k, mv := range m {
+		if mv == v && (name == "" || len(k) < len(name) || len(k) == len(name) && k < name) {
+			name = k
+		}
+	}
+	return name
+}
+
+// orderName returns the shortest name of a sort order
+func orderName(o Order) string {
+	return shortest(orders, o)
+}
+
+// exp returns a filter expression as op(column, value)
+func exp(ex *Exp) string {
+	if len(ex.Children) != 0 {
+		items := make([]string, len(ex.Children))
+		for i, c := range ex.Children {
+			items[i] = exp(c)
+		}
+		return opName(ex.Op) + "(" + strings.Join(items, ", ") + ")"
+	}
+	return opName(ex.Op) + "(" + ex.Col.Name + ", " + valueText(ex.Val) + ")"
+}
+
+// dump returns the selections of a query a line per property
+func dump(qc *QCode) string {
+	var sb strings.Builder
+	for _, sel := range qc.Selects {
+		kind := "list"
+		if sel.Singular {
+			kind = "singular"
+		}
+		fmt.Fprintf(&sb, "select %d %s %s.%s %s parent %d\n",
+			sel.ID, sel.FieldName, sel.Ti.Schema, sel.Ti.Name, kind, sel.ParentID)
+
+		for _, f := range sel.Fields {
+			fmt.Fprintf(&sb, "\tfield %s %s\n", f.Name, f.Col.Name)
+		}
+		for _, r := range sel.Path {
+			fmt.Fprintf(&sb, "\tpath %s %s.%s %s.%s\n",
+				r.Type, r.Left.Ti.Name, r.Left.Col.Name, r.Right.Ti.Name, r.Right.Col.Name)
+		}
+		if sel.Where != nil {
+			fmt.Fprintf(&sb, "\twhere %s\n", exp(sel.Where))
+		}
+		for _, ob := range sel.OrderBy {
+			fmt.Fprintf(&sb, "\torder %s %s\n", ob.Col.Name, orderName(ob.Order))
+		}
+		if p := sel.Paging; p.Limit.Type != ValNone {
+			fmt.Fprintf(&sb, "\tlimit %s\n", valueText(p.Limit))
+		}
+		if p := sel.Paging; p.Offset.Type != ValNone {
+			fmt.Fprintf(&sb, "\toffset %s\n", valueText(p.Offset))
+		}
+		for _, a := range sel.Args {
+			fmt.Fprintf(&sb, "\targ %s %s\n", a.Param.Name, valueText(a.Val))
+		}
+	}
+	return sb.String()
+}
+
+func TestCompileGolden(t *testing.T) {
+	tests := []struct {
+		name, query string
+	}{
+		{"number", `{ users(where: {id: {eq: 5}}, limit: 10, offset: 20) { id email } }`},
+		{"bool", `{ comments(where: {body: {is_null: true}}) { id } }`},
+		{"string", `{ posts(where: {title: {eq: "it's \"new\" \\ done"}}) { id } }`},
+		{"list", `{ posts(where: {id: {in: [1, 2.5, -3]}}) { id } }`},
+		{"var", `query ($id: ID!, $t: String) { posts(where: {and: [{user_id: {eq: $id}}, {title: {neq: $t}}]}) { id } }`},
+		{"nested", `{ users { id posts(order_by: {id: desc}) { title comments { body } } } }`},
+		{"parent", `{ comments { body post { title user { email } } } }`},
+	}
+
+	s := blogSchema(t, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qc, err := NewCompiler(s).Compile([]byte(tt.query), "")
+			if err != nil {
+				t.Fatalf("%s: %v", tt.query, err)
+			}
+			golden.Check(t, tt.name+".txt", dump(qc))
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package qcode
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Pos is the position of a token in a query document, lines and
+// columns start at 1 and columns count runes
+type Pos struct {
+	Line int
+	Col  int
+}
+
+// Error is a query error with the position it was found at
+type Error struct {
+	Pos Pos
+	Msg string
+}
+
+// Error returns the error message prefixed with its position
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d column %d: %s", e.Pos.Line, e.Pos.Col, e.Msg)
+}
+
+// errorf returns a query error at a position
+func errorf(pos Pos, format string, args ...interface{}) error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+type tokType int
+
+const (
+	tokEOF tokType = iota
+	tokName
+	tokInt
+	tokFloat
+	tokString
+	tokPunct
+)
+
+// token is a lexical token of a query document
+type token struct {
+	typ tokType
+	val string
+	pos Pos
+}
+
+// String returns the token as it is shown in errors
+func (t token) String() string {
+	switch t.typ {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return strconv.Quote(t.val)
+	}
+	return "'" + t.val + "'"
+}
+
+// lexer splits a query document into tokens, commas and comments
+// are skipped like white space
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, col: 1}
+}
+
+// peek returns the rune at the current offset
+func (l *lexer) peek() rune {
+	if l.off >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	return r
+}
+
+// advance moves past the current rune
+func (l *lexer) advance() {
+	r, n := utf8.DecodeRuneInString(l.src[l.off:])
+	l.off += n
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+}
+
+func (l *lexer) pos() Pos {
+	return Pos{Line: l.line, Col: l.col}
+}
+
+// skipIgnored skips white space, commas and comments
+func (l *lexer) skipIgnored() {
+	for {
+		switch r := l.peek(); r {
+		case ' ', '\t', '\n', '\r', ',', '\uFEFF':
+			l.advance()
+		case '#':
+			for r != -1 && r != '\n' {
+				l.advance()
+				r = l.peek()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+
+	pos := l.pos()
+	r := l.peek()
+
+	switch {
+	case r == -1:
+		return token{typ: tokEOF, pos: pos}, nil
+
+	case r == '.':
+		if !strings.HasPrefix(l.src[l.off:], "...") {
+			return token{}, errorf(pos, "unexpected character '.'")
+		}
+		l.advance()
+		l.advance()
+		l.advance()
+		return token{typ: tokPunct, val: "...", pos: pos}, nil
+
+	case strings.ContainsRune("!$()&:=@[]{}|", r):
+		l.advance()
+		return token{typ: tokPunct, val: string(r), pos: pos}, nil
+
+	case r == '_' || isLetter(r):
+		start := l.off
+		for r = l.peek(); r == '_' || isLetter(r) || isDigit(r); r = l.peek() {
+			l.advance()
+		}
+		return token{typ: tokName, val: l.src[start:l.off], pos: pos}, nil
+
+	case r == '-' || isDigit(r):
+		return l.number(pos)
+
+	case r == '"':
+		if strings.HasPrefix(l.src[l.off:], `"""`) {
+			return l.blockString(pos)
+		}
+		return l.string(pos)
+	}
+
+	return token{}, errorf(pos, "unexpected character %q", r)
+}
+
+// number lexes an int or a float
+func (l *lexer) number(pos Pos) (token, error) {
+	start := l.off
+	typ := tokInt
+
+	if l.peek() == '-' {
+		l.advance()
+	}
+	if !l.digits() {
+		return token{}, errorf(pos, "invalid number")
+	}
+
+	if l.peek() == '.' {
+		typ = tokFloat
+		l.advance()
+		if !l.digits() {
+			return token{}, errorf(pos, "invalid number")
+		}
+	}
+
+	if r := l.peek(); r == 'e' || r == 'E' {
+		typ = tokFloat
+		l.advance()
+		if r = l.peek(); r == '+' || r == '-' {
+			l.advance()
+		}
+		if !l.digits() {
+			return token{}, errorf(pos, "invalid number")
+		}
+	}
+
+	if r := l.peek(); r == '_' || isLetter(r) {
+		return token{}, errorf(pos, "invalid number")
+	}
+	return token{typ: typ, val: l.src[start:l.off], pos: pos}, nil
+}
+
+// digits skips a run of digits and returns false if there was none
+func (l *lexer) digits() bool {
+	start := l.off
+	for isDigit(l.peek()) {
+		l.advance()
+	}
+	return l.off != start
+}
+
+// string lexes a quoted string and unescapes it
+func (l *lexer) string(pos Pos) (token, error) {
+	var sb strings.Builder
+	l.advance()
+
+	for {
+		r := l.peek()
+		switch r {
+		case -1, '\n', '\r':
+			return token{}, errorf(pos, "unterminated string")
+
+		case '"':
+			l.advance()
+			return token{typ: tokString, val: sb.String(), pos: pos}, nil
+
+		case '\\':
+			epos := l.pos()
+			l.advance()
+			r = l.peek()
+			l.advance()
+
+			switch r {
+			case '"', '\\', '/':
+				sb.WriteRune(r)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				if l.off+4 > len(l.src) {
+					return token{}, errorf(epos, "invalid unicode escape")
+				}
+				v, err := strconv.ParseUint(l.src[l.off:l.off+4], 16, 32)
+				if err != nil {
+					return token{}, errorf(epos, "invalid unicode escape")
+				}
+				for i := 0; i < 4; i++ {
+					l.advance()
+				}
+				sb.WriteRune(rune(v))
+			default:
+				return token{}, errorf(epos, "invalid escape sequence")
+			}
+
+		default:
+			sb.WriteRune(r)
+			l.advance()
+		}
+	}
+}
+
+// blockString lexes a triple quoted string, the common indentation
+// and the leading and trailing blank lines are removed
+func (l *lexer) blockString(pos Pos) (token, error) {
+	for i := 0; i < 3; i++ {
+		l.advance()
+	}
+
+	var sb strings.Builder
+	for {
+		switch {
+		case l.off >= len(l.src):
+			return token{}, errorf(pos, "unterminated string")
+
+		case strings.HasPrefix(l.src[l.off:], `\"""`):
+			sb.WriteString(`"""`)
+			for i := 0; i < 4; i++ {
+				l.advance()
+			}
+
+		case strings.HasPrefix(l.src[l.off:], `"""`):
+			for i := 0; i < 3; i++ {
+				l.advance()
+			}
+			return token{typ: tokString, val: blockValue(sb.String()), pos: pos}, nil
+
+		default:
+			sb.WriteRune(l.peek())
+			l.advance()
+		}
+	}
+}
+
+// blockValue removes the indentation of a block string
+func blockValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+
+	indent := -1
+	for _, ln := range lines[1:] {
+		n := len(ln) - len(strings.TrimLeft(ln, " \t"))
+		if n < len(ln) && (indent == -1 || n < indent) {
+			indent = n
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		} else {
+			lines[i] = ""
+		}
+	}
+
+	for len(lines) != 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,429 @@
+package qcode
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// MType is the type of a mutation
+type MType int
+
+const (
+	MTInsert MType = iota + 1
+	MTUpdate
+	MTUpsert
+	MTDelete
+	MTConnect
+	MTDisconnect
+)
+
+// Mutate is a write to a table, nested writes follow the relationships
+// of the table and are children of the write they are nested in
+type Mutate struct {
+	ID       int32
+	ParentID int32 // -1 for root mutations
+	Type     MType
+	Ti       schema.DBTable
+
+	// Rel is the relationship from the table of the parent mutation
+	// to this table, Left is the parent table
+	Rel schema.DBRel
+
+	Cols     []MColumn
+	Where    *Exp
+	Children []int32
+}
+
+// MColumn is a column value set by a mutation
+type MColumn struct {
+	Col schema.DBColumn
+	Val Value
+}
+
+// mutationArgs are the root field arguments that define a mutation
+// instead of filtering the returned rows
+var mutationArgs = map[string]bool{
+	"insert": true,
+	"update": true,
+	"upsert": true,
+	"delete": true,
+	"where":  true,
+	"id":     true,
+}
+
+// ParentFirst returns true if the parent of a nested mutation must be
+// written before it, that is when this table holds the foreign key to
+// the parent table. Otherwise the parent holds the foreign key and
+// this row is written first so the parent can point to it
+func (m *Mutate) ParentFirst() bool {
+	r := m.Rel
+	return !(r.Left.Col.FKeyTable == r.Right.Ti.Name &&
+		r.Left.Col.FKeySchema == r.Right.Ti.Schema &&
+		r.Left.Col.FKeyCol == r.Right.Col.Name)
+}
+
+// addMutation compiles the mutation arguments of a root field and
+// returns the field with only the arguments of the returned selection
+func (c *compiler) addMutation(sel *Select, f *field) (*field, error) {
+	for _, d := range f.dirs {
+		if d.name == "skip" || d.name == "include" {
+			return nil, errorf(d.pos, "@%s cannot be used on a mutation", d.name)
+		}
+	}
+
+	var margs []argument
+	nf := *f
+	nf.args = nil
+
+	for _, a := range f.args {
+		if mutationArgs[a.name] {
+			margs = append(margs, a)
+		} else {
+			nf.args = append(nf.args, a)
+		}
+	}
+
+	var where *Exp
+	var mt *argument
+
+	for i, a := range margs {
+		switch a.name {
+		case "where":
+			ex, err := c.exp(sel.Ti, a.val)
+			if err != nil {
+				return nil, err
+			}
+			where = andExp(where, ex)
+
+		case "id":
+			if err := c.argID(sel, a); err != nil {
+				return nil, err
+			}
+			where = andExp(where, sel.Where)
+			sel.Where = nil
+
+		default:
+			if mt != nil {
+				return nil, errorf(a.pos, "only one of insert, update, upsert or delete can be used")
+			}
+			mt = &margs[i]
+		}
+	}
+
+	if mt == nil {
+		return nil, errorf(f.pos, "mutation on %s requires insert, update, upsert or delete", sel.Ti.Name)
+	}
+
+	typ := map[string]MType{"insert": MTInsert, "update": MTUpdate, "upsert": MTUpsert, "delete": MTDelete}[mt.name]
+	if err := c.canWrite(typ, sel.Ti, mt.pos); err != nil {
+		return nil, err
+	}
+	if typ == MTUpdate || typ == MTDelete {
+		filter, err := c.filter(sel.Ti)
+		if err != nil {
+			return nil, err
+		}
+		if where != nil {
+			where = andExp(filter, where)
+		}
+	}
+
+	switch mt.name {
+	case "insert", "upsert":
+		if where != nil {
+			return nil, errorf(mt.pos, "%s cannot be filtered with where or id", mt.name)
+		}
+		typ := MTInsert
+		if mt.name == "upsert" {
+			typ = MTUpsert
+		}
+
+		items := []*value{mt.val}
+		if mt.val.typ == valList {
+			items = mt.val.list
+		} else {
+			sel.Singular = true
+		}
+
+		for _, item := range items {
+			if _, err := c.addWrite(typ, -1, sel.Ti, schema.DBRel{}, item, nil); err != nil {
+				return nil, err
+			}
+		}
+
+	case "update":
+		if where == nil {
+			return nil, errorf(mt.pos, "update requires where or id")
+		}
+		if _, err := c.addWrite(MTUpdate, -1, sel.Ti, schema.DBRel{}, mt.val, where); err != nil {
+			return nil, err
+		}
+
+	case "delete":
+		if mt.val.typ != valBool || mt.val.val != "true" {
+			return nil, errorf(mt.val.pos, "delete must be true")
+		}
+		if where == nil {
+			return nil, errorf(mt.pos, "delete requires where or id")
+		}
+		c.qc.Mutates = append(c.qc.Mutates, Mutate{
+			ID:       int32(len(c.qc.Mutates)),
+			ParentID: -1,
+			Type:     MTDelete,
+			Ti:       sel.Ti,
+			Where:    where,
+		})
+	}
+	return &nf, nil
+}
+
+// addWrite compiles an object of column values and nested writes into
+// an insert, upsert or update of a table
+func (c *compiler) addWrite(typ MType, pid int32, t schema.DBTable, rel schema.DBRel, v *value, where *Exp) (int32, error) {
+	if v.typ != valObj || len(v.obj) == 0 {
+		return -1, errorf(v.pos, "expected an object of values for %s found %s", t.Name, v)
+	}
+
+	id := int32(len(c.qc.Mutates))
+	c.qc.Mutates = append(c.qc.Mutates, Mutate{
+		ID:       id,
+		ParentID: pid,
+		Type:     typ,
+		Ti:       t,
+		Rel:      rel,
+		Where:    where,
+	})
+	if pid != -1 {
+		c.qc.Mutates[pid].Children = append(c.qc.Mutates[pid].Children, id)
+	}
+
+	for _, a := range v.obj {
+		if col, ok := t.ColumnExists(a.name); ok && !col.Blocked {
+			if col.Generated != "" {
+				return -1, errorf(a.pos, "generated column cannot be written: %s.%s", t.Name, a.name)
+			}
+			if col.Identity == "always" {
+				return -1, errorf(a.pos, "identity column cannot be written: %s.%s", t.Name, a.name)
+			}
+			if len(col.JSONPath) != 0 {
+				return -1, errorf(a.pos, "json path column cannot be written: %s.%s", t.Name, a.name)
+			}
+			val, err := c.value(a.val)
+			if err != nil {
+				return -1, err
+			}
+			c.qc.Mutates[id].Cols = append(c.qc.Mutates[id].Cols, MColumn{Col: col, Val: val})
+			continue
+		}
+
+		r, err := c.writeRel(t, a)
+		if err != nil {
+			return -1, err
+		}
+		if err := c.addNestedWrite(typ, id, r, a); err != nil {
+			return -1, err
+		}
+	}
+
+	if err := c.addAudit(&c.qc.Mutates[id], v.pos); err != nil {
+		return -1, err
+	}
+	return id, nil
+}
+
+// addAudit sets the audit columns a write leaves out, the time of the
+// statement in the _at columns and the audit user of the schema in the
+// _by ones. An update only sets them when it changes the row
+func (c *compiler) addAudit(m *Mutate, pos Pos) error {
+	kinds := []schema.Audit{schema.AuditUpdatedAt, schema.AuditUpdatedBy}
+	switch {
+	case m.Type != MTUpdate:
+		kinds = append([]schema.Audit{schema.AuditCreatedAt, schema.AuditCreatedBy}, kinds...)
+	case len(m.Cols) == 0:
+		return nil
+	}
+
+	// the columns are set whatever the role can see of the table
+	acc, err := c.access(m.Ti)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range kinds {
+		col, ok := acc.ti.AuditColumn(a)
+		if !ok || setsColumn(m, col.Name) {
+			continue
+		}
+
+		val := Value{Type: ValNow}
+		if a == schema.AuditCreatedBy || a == schema.AuditUpdatedBy {
+			name := c.s.AuditUser()
+			if name == "" {
+				continue
+			}
+			if _, ok := c.vars[name]; ok {
+				return errorf(pos, "variable $%s is reserved for the audit user", name)
+			}
+			if !c.isFilterVar(name) {
+				c.qc.FilterVars = append(c.qc.FilterVars, name)
+			}
+			val = Value{Type: ValVar, Val: name}
+		}
+		m.Cols = append(m.Cols, MColumn{Col: col, Val: val})
+	}
+	return nil
+}
+
+// setsColumn returns true if a write sets a column
+func setsColumn(m *Mutate, name string) bool {
+	for _, mc := range m.Cols {
+		if mc.Col.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addNestedWrite compiles the value of a relationship key of a write,
+// a connect or disconnect object links existing rows, other objects
+// and lists of objects write related rows
+func (c *compiler) addNestedWrite(typ MType, pid int32, r schema.TableRel, a argument) error {
+	v := a.val
+	nested := &Mutate{Rel: r.DBRel}
+
+	ct, err := c.table(r.Right.Ti)
+	if err != nil {
+		return err
+	}
+	if ct.Blocked {
+		return errorf(a.pos, "unknown column or relationship: %s.%s", r.Left.Ti.Name, a.name)
+	}
+	filter, err := c.filter(ct)
+	if err != nil {
+		return err
+	}
+
+	if v.typ == valObj && len(v.obj) != 0 && (v.obj[0].name == "connect" || v.obj[0].name == "disconnect") {
+		for _, o := range v.obj {
+			mt := MTConnect
+			switch o.name {
+			case "connect":
+			case "disconnect":
+				if typ != MTUpdate {
+					return errorf(o.pos, "disconnect can only be used in an update")
+				}
+				mt = MTDisconnect
+			default:
+				return errorf(o.pos, "unexpected %s next to connect or disconnect", o.name)
+			}
+
+			if err := c.canWrite(mt, ct, o.pos); err != nil {
+				return err
+			}
+			ex, err := c.connectExp(ct, o.val)
+			if err != nil {
+				return err
+			}
+			nested.Type, nested.Where = mt, andExp(ex, filter)
+
+			// unlinking a row the parent points to clears the parent column
+			if mt == MTDisconnect && !nested.ParentFirst() {
+				c.qc.Mutates[pid].Cols = append(c.qc.Mutates[pid].Cols,
+					MColumn{Col: r.Left.Col, Val: Value{Type: ValNull}})
+				continue
+			}
+			c.addLink(pid, *nested)
+		}
+		return nil
+	}
+
+	items := []*value{v}
+	if v.typ == valList {
+		if !r.Many {
+			return errorf(v.pos, "%s takes a single object", a.name)
+		}
+		items = v.list
+	}
+
+	nt := MTInsert
+	if typ == MTUpdate {
+		nt = MTUpdate
+	} else {
+		filter = nil
+	}
+	if err := c.canWrite(nt, ct, a.pos); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if _, err := c.addWrite(nt, pid, ct, r.DBRel, item, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addLink adds a connect or disconnect as a child of a write
+func (c *compiler) addLink(pid int32, m Mutate) {
+	m.ID = int32(len(c.qc.Mutates))
+	m.ParentID = pid
+	m.Ti = m.Rel.Right.Ti
+	c.qc.Mutates = append(c.qc.Mutates, m)
+	c.qc.Mutates[pid].Children = append(c.qc.Mutates[pid].Children, m.ID)
+}
+
+// writeRel returns the relationship a nested write follows, only direct
+// foreign key relationships can be written through
+func (c *compiler) writeRel(t schema.DBTable, a argument) (schema.TableRel, error) {
+	rels, err := c.tableRels(t)
+	if err != nil {
+		return schema.TableRel{}, err
+	}
+
+	for _, r := range rels {
+		if r.Name != a.name {
+			continue
+		}
+		switch r.Type {
+		case schema.RelOneToOne, schema.RelOneToMany, schema.RelRecursive:
+			if r.Left.Col.Array || r.Right.Col.Array || len(r.Left.Cols) > 1 {
+				break
+			}
+			return r, nil
+		}
+		return r, errorf(a.pos, "relationship cannot be written through: %s.%s", t.Name, a.name)
+	}
+	return schema.TableRel{}, errorf(a.pos, "unknown column or relationship: %s.%s", t.Name, a.name)
+}
+
+// connectExp compiles the filter selecting the rows to connect or
+// disconnect, keys can be columns with a value as a shorthand for eq
+// eg. { id: 5 } or where expressions
+func (c *compiler) connectExp(t schema.DBTable, v *value) (*Exp, error) {
+	if v.typ != valObj || len(v.obj) == 0 {
+		return nil, errorf(v.pos, "expected a filter object found %s", v)
+	}
+
+	var ex *Exp
+	for _, a := range v.obj {
+		col, ok := t.ColumnExists(a.name)
+		if !ok || col.Blocked || a.val.typ == valObj {
+			e, err := c.expKey(t, a)
+			if err != nil {
+				return nil, err
+			}
+			ex = andExp(ex, e)
+			continue
+		}
+
+		op := OpEquals
+		if a.val.typ == valList {
+			op = OpIn
+		}
+		val, err := c.value(a.val)
+		if err != nil {
+			return nil, err
+		}
+		ex = andExp(ex, &Exp{Op: op, Col: col, Val: val})
+	}
+	return ex, nil
+}
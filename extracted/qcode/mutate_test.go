@@ -0,0 +1,149 @@
+package qcode
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestNestedInsert(t *testing.T) {
+	for _, qc := range compile(t, `mutation { users(insert: {email: "a", posts: [{title: "x"}, {title: "y"}]}) { id } }`) {
+		if len(qc.Mutates) != 3 {
+			t.Fatalf("got %d mutations, want 3", len(qc.Mutates))
+		}
+		root := qc.Mutates[0]
+		if root.Ti.Name != "users" || root.Type != MTInsert || len(root.Children) != 2 {
+			t.Fatalf("unexpected root mutation: %s %v %v", root.Ti.Name, root.Type, root.Children)
+		}
+		for _, id := range root.Children {
+			m := qc.Mutates[id]
+			if m.Ti.Name != "posts" || m.ParentID != 0 || !m.ParentFirst() {
+				t.Errorf("unexpected nested mutation: %s parent %d parent first %v", m.Ti.Name, m.ParentID, m.ParentFirst())
+			}
+		}
+	}
+}
+
+func TestNestedInsertParent(t *testing.T) {
+	for _, qc := range compile(t, `mutation { posts(insert: {title: "x", user: {email: "a"}}) { id } }`) {
+		if len(qc.Mutates) != 2 {
+			t.Fatalf("got %d mutations, want 2", len(qc.Mutates))
+		}
+		if m := qc.Mutates[1]; m.Ti.Name != "users" || m.ParentID != 0 || m.ParentFirst() {
+			t.Errorf("unexpected nested mutation: %s parent %d parent first %v", m.Ti.Name, m.ParentID, m.ParentFirst())
+		}
+	}
+}
+
+func TestNestedInsertErrors(t *testing.T) {
+	tests := []struct {
+		query, err string
+	}{
+		{`mutation { posts(insert: {title: "x", user: [{email: "a"}]}) { id } }`, "user takes a single object"},
+		{`mutation { users(insert: {email: "a", profiles: [{bio: "b"}]}) { id } }`, "profiles takes a single object"},
+	}
+	for _, tt := range tests {
+		for _, pkUnique := range []bool{true, false} {
+			_, err := NewCompiler(blogSchema(t, pkUnique)).Compile([]byte(tt.query), "")
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: got error %v, want %q", tt.query, err, tt.err)
+			}
+		}
+	}
+}
+
+func TestInsertIdentity(t *testing.T) {
+	di, err := schema.ParseDDL([]byte(`
+		CREATE TABLE orders (
+			id bigint GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+			number bigint GENERATED BY DEFAULT AS IDENTITY,
+			note text
+		);`), "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	co := NewCompiler(s)
+
+	if _, err := co.Compile([]byte(`mutation { orders(insert: {number: 5, note: "a"}) { id } }`), ""); err != nil {
+		t.Error(err)
+	}
+	_, err = co.Compile([]byte(`mutation { orders(insert: {id: 5, note: "a"}) { id } }`), "")
+	if err == nil || !strings.Contains(err.Error(), "identity column cannot be written: orders.id") {
+		t.Errorf("got error %v", err)
+	}
+}
+
+// auditSchema has users with the four audit columns
+func auditSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull", "created_at timestamptz", "updated_at timestamptz",
+			"created_by bigint", "updated_by bigint").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkAuditColumns(schema.AuditColumns{})
+	s, err := schema.NewDBSchema(di, nil, schema.WithAuditUser("user_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// auditValues returns the values of the audit columns of the first write
+func auditValues(t *testing.T, s *schema.DBSchema, query string) (map[string]Value, *QCode) {
+	t.Helper()
+	qc, err := NewCompiler(s).Compile([]byte(query), "")
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	vals := make(map[string]Value)
+	for _, mc := range qc.Mutates[0].Cols {
+		if mc.Col.Audit != "" {
+			vals[mc.Col.Name] = mc.Val
+		}
+	}
+	return vals, qc
+}
+
+func TestInsertAudit(t *testing.T) {
+	s := auditSchema(t)
+
+	vals, qc := auditValues(t, s, `mutation { users(insert: {email: "a"}) { id } }`)
+	for col, want := range map[string]Value{
+		"created_at": {Type: ValNow},
+		"updated_at": {Type: ValNow},
+		"created_by": {Type: ValVar, Val: "user_id"},
+		"updated_by": {Type: ValVar, Val: "user_id"},
+	} {
+		if v := vals[col]; v.Type != want.Type || v.Val != want.Val {
+			t.Errorf("%s: got %+v, want %+v", col, v, want)
+		}
+	}
+	if len(qc.FilterVars) != 1 || qc.FilterVars[0] != "user_id" {
+		t.Errorf("got filter vars %v", qc.FilterVars)
+	}
+
+	// a value set by the mutation is kept
+	vals, _ = auditValues(t, s, `mutation { users(insert: {email: "a", created_at: "2020-01-01"}) { id } }`)
+	if vals["created_at"].Type == ValNow {
+		t.Error("got the created_at value replaced")
+	}
+
+	// updates only set the updated_ columns
+	vals, _ = auditValues(t, s, `mutation { users(id: 1, update: {email: "b"}) { id } }`)
+	if _, ok := vals["created_at"]; ok || vals["updated_at"].Type != ValNow || vals["updated_by"].Type != ValVar {
+		t.Errorf("got audit values %+v", vals)
+	}
+
+	_, err := NewCompiler(s).Compile([]byte(`mutation ($user_id: ID!) { users(insert: {email: $user_id}) { id } }`), "")
+	if err == nil || !strings.Contains(err.Error(), "reserved for the audit user") {
+		t.Errorf("got error %v", err)
+	}
+}
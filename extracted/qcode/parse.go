@@ -0,0 +1,630 @@
+package qcode
+
+import (
+	"strings"
+)
+
+// document is a parsed query document
+type document struct {
+	ops   []*operation
+	frags map[string]*fragment
+}
+
+// operation is a query, mutation or subscription
+type operation struct {
+	typ    string
+	name   string
+	vars   []varDef
+	dirs   []directive
+	fields []selection
+	pos    Pos
+}
+
+// varDef is a variable definition of an operation
+type varDef struct {
+	name string
+	typ  string
+	def  *value
+	pos  Pos
+}
+
+// fragment is a named fragment definition
+type fragment struct {
+	name   string
+	on     string
+	dirs   []directive
+	fields []selection
+	pos    Pos
+}
+
+// selection is a field, a fragment spread or an inline fragment,
+// spreads have a name and no fields, inline fragments neither
+type selection struct {
+	field  *field
+	spread string
+	on     string
+	dirs   []directive
+	fields []selection
+	pos    Pos
+}
+
+// field is a selected field with its arguments and sub fields
+type field struct {
+	alias  string
+	name   string
+	args   []argument
+	dirs   []directive
+	fields []selection
+	pos    Pos
+}
+
+// argument is a named value of a field or directive
+type argument struct {
+	name string
+	val  *value
+	pos  Pos
+}
+
+// directive is a directive applied to a field or fragment
+type directive struct {
+	name string
+	args []argument
+	pos  Pos
+}
+
+type valType int
+
+const (
+	valStr valType = iota
+	valInt
+	valFloat
+	valBool
+	valNull
+	valEnum
+	valList
+	valObj
+	valVar
+)
+
+// value is an argument value, object values keep their keys
+// in the order they were written
+type value struct {
+	typ  valType
+	val  string
+	list []*value
+	obj  []argument
+	pos  Pos
+}
+
+// parser is a recursive descent parser of query documents
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// parse parses a query document
+func parse(query string) (*document, error) {
+	p := &parser{lex: newLexer(query)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{frags: make(map[string]*fragment)}
+
+	for p.tok.typ != tokEOF {
+		switch {
+		case p.isPunct("{"):
+			op := &operation{typ: "query", pos: p.tok.pos}
+			sel, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			op.fields = sel
+			doc.ops = append(doc.ops, op)
+
+		case p.isName("query"), p.isName("mutation"), p.isName("subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.ops = append(doc.ops, op)
+
+		case p.isName("fragment"):
+			fr, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.frags[fr.name]; ok {
+				return nil, errorf(fr.pos, "duplicate fragment: %s", fr.name)
+			}
+			doc.frags[fr.name] = fr
+
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.ops) == 0 {
+		return nil, errorf(p.tok.pos, "no operation found")
+	}
+	return doc, nil
+}
+
+// parseValueString parses a single value such as a where expression
+// given outside of a query document
+func parseValueString(src string) (*value, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	v, err := p.parseValue(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.typ != tokEOF {
+		return nil, p.unexpected()
+	}
+	return v, nil
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) isPunct(v string) bool {
+	return p.tok.typ == tokPunct && p.tok.val == v
+}
+
+func (p *parser) isName(v string) bool {
+	return p.tok.typ == tokName && p.tok.val == v
+}
+
+func (p *parser) unexpected() error {
+	return errorf(p.tok.pos, "unexpected %s", p.tok)
+}
+
+// expect moves past a punctuator or fails
+func (p *parser) expect(v string) error {
+	if !p.isPunct(v) {
+		return errorf(p.tok.pos, "expected '%s' found %s", v, p.tok)
+	}
+	return p.advance()
+}
+
+// name returns the current name token and moves past it
+func (p *parser) name() (string, error) {
+	if p.tok.typ != tokName {
+		return "", errorf(p.tok.pos, "expected a name found %s", p.tok)
+	}
+	v := p.tok.val
+	return v, p.advance()
+}
+
+// parseOperation parses: type name? vars? directives? selection-set
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{typ: p.tok.val, pos: p.tok.pos}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if p.tok.typ == tokName {
+		if op.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isPunct("(") {
+		if op.vars, err = p.parseVarDefs(); err != nil {
+			return nil, err
+		}
+	}
+
+	if op.dirs, err = p.parseDirectives(true); err != nil {
+		return nil, err
+	}
+
+	if op.fields, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// parseVarDefs parses: ( $name: Type = default ... )
+func (p *parser) parseVarDefs() ([]varDef, error) {
+	var vars []varDef
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.isPunct(")") {
+		vd := varDef{pos: p.tok.pos}
+
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if vd.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if vd.typ, err = p.parseType(); err != nil {
+			return nil, err
+		}
+
+		if p.isPunct("=") {
+			if err = p.advance(); err != nil {
+				return nil, err
+			}
+			if vd.def, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+
+		if _, err = p.parseDirectives(true); err != nil {
+			return nil, err
+		}
+
+		for _, v := range vars {
+			if v.name == vd.name {
+				return nil, errorf(vd.pos, "duplicate variable: $%s", vd.name)
+			}
+		}
+		vars = append(vars, vd)
+	}
+	return vars, p.advance()
+}
+
+// parseType parses a variable type eg. [String!]!
+func (p *parser) parseType() (string, error) {
+	var v string
+
+	if p.isPunct("[") {
+		if err := p.advance(); err != nil {
+			return "", err
+		}
+		t, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err = p.expect("]"); err != nil {
+			return "", err
+		}
+		v = "[" + t + "]"
+	} else {
+		n, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		v = n
+	}
+
+	if p.isPunct("!") {
+		v += "!"
+		return v, p.advance()
+	}
+	return v, nil
+}
+
+// parseFragment parses: fragment name on Type directives? selection-set
+func (p *parser) parseFragment() (*fragment, error) {
+	fr := &fragment{pos: p.tok.pos}
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if fr.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if !p.isName("on") {
+		return nil, errorf(p.tok.pos, "expected 'on' found %s", p.tok)
+	}
+	if err = p.advance(); err != nil {
+		return nil, err
+	}
+	if fr.on, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if fr.dirs, err = p.parseDirectives(true); err != nil {
+		return nil, err
+	}
+	if fr.fields, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return fr, nil
+}
+
+// parseSelectionSet parses: { selection ... }
+func (p *parser) parseSelectionSet() ([]selection, error) {
+	pos := p.tok.pos
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var sel []selection
+	for !p.isPunct("}") {
+		if p.tok.typ == tokEOF {
+			return nil, errorf(pos, "unclosed selection set")
+		}
+
+		s, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, s)
+	}
+
+	if len(sel) == 0 {
+		return nil, errorf(pos, "empty selection set")
+	}
+	return sel, p.advance()
+}
+
+// parseSelection parses a field, a fragment spread or an inline fragment
+func (p *parser) parseSelection() (selection, error) {
+	s := selection{pos: p.tok.pos}
+
+	if !p.isPunct("...") {
+		f, err := p.parseField()
+		if err != nil {
+			return s, err
+		}
+		s.field = f
+		return s, nil
+	}
+
+	if err := p.advance(); err != nil {
+		return s, err
+	}
+
+	var err error
+	switch {
+	case p.isName("on"):
+		if err = p.advance(); err != nil {
+			return s, err
+		}
+		if s.on, err = p.name(); err != nil {
+			return s, err
+		}
+
+	case p.tok.typ == tokName:
+		if s.spread, err = p.name(); err != nil {
+			return s, err
+		}
+	}
+
+	if s.dirs, err = p.parseDirectives(false); err != nil {
+		return s, err
+	}
+
+	if s.spread == "" {
+		if s.fields, err = p.parseSelectionSet(); err != nil {
+			return s, err
+		}
+	}
+	return s, nil
+}
+
+// parseField parses: alias: name args? directives? selection-set?
+func (p *parser) parseField() (*field, error) {
+	f := &field{pos: p.tok.pos}
+
+	var err error
+	if f.name, err = p.name(); err != nil {
+		return nil, err
+	}
+
+	if p.isPunct(":") {
+		if err = p.advance(); err != nil {
+			return nil, err
+		}
+		f.alias = f.name
+		f.pos = p.tok.pos
+
+		if f.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isPunct("(") {
+		if f.args, err = p.parseArgs(false); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.dirs, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+
+	if p.isPunct("{") {
+		if f.fields, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseArgs parses: ( name: value ... )
+func (p *parser) parseArgs(constant bool) ([]argument, error) {
+	var args []argument
+
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	for !p.isPunct(")") {
+		a := argument{pos: p.tok.pos}
+
+		var err error
+		if a.name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if a.val, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+
+		for _, v := range args {
+			if v.name == a.name {
+				return nil, errorf(a.pos, "duplicate argument: %s", a.name)
+			}
+		}
+		args = append(args, a)
+	}
+	return args, p.advance()
+}
+
+// parseDirectives parses: @name args? ...
+func (p *parser) parseDirectives(constant bool) ([]directive, error) {
+	var dirs []directive
+
+	for p.isPunct("@") {
+		d := directive{pos: p.tok.pos}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if d.name, err = p.name(); err != nil {
+			return nil, err
+		}
+
+		if p.isPunct("(") {
+			if d.args, err = p.parseArgs(constant); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+// parseValue parses a value, variables are not allowed in
+// constant values like variable defaults
+func (p *parser) parseValue(constant bool) (*value, error) {
+	v := &value{pos: p.tok.pos, val: p.tok.val}
+
+	switch p.tok.typ {
+	case tokString:
+		v.typ = valStr
+		return v, p.advance()
+
+	case tokInt:
+		v.typ = valInt
+		return v, p.advance()
+
+	case tokFloat:
+		v.typ = valFloat
+		return v, p.advance()
+
+	case tokName:
+		switch v.val {
+		case "true", "false":
+			v.typ = valBool
+		case "null":
+			v.typ = valNull
+		default:
+			v.typ = valEnum
+		}
+		return v, p.advance()
+	}
+
+	switch {
+	case p.isPunct("$"):
+		if constant {
+			return nil, errorf(v.pos, "variables are not allowed here")
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		n, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		v.typ, v.val = valVar, n
+		return v, nil
+
+	case p.isPunct("["):
+		v.typ = valList
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		for !p.isPunct("]") {
+			if p.tok.typ == tokEOF {
+				return nil, errorf(v.pos, "unclosed list")
+			}
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			v.list = append(v.list, item)
+		}
+		return v, p.advance()
+
+	case p.isPunct("{"):
+		v.typ = valObj
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		for !p.isPunct("}") {
+			a := argument{pos: p.tok.pos}
+
+			var err error
+			if a.name, err = p.name(); err != nil {
+				return nil, err
+			}
+			if err = p.expect(":"); err != nil {
+				return nil, err
+			}
+			if a.val, err = p.parseValue(constant); err != nil {
+				return nil, err
+			}
+			v.obj = append(v.obj, a)
+		}
+		return v, p.advance()
+	}
+
+	return nil, errorf(v.pos, "expected a value found %s", p.tok)
+}
+
+// String returns the value as it would be written in a query
+func (v *value) String() string {
+	switch v.typ {
+	case valStr:
+		return `"` + strings.ReplaceAll(v.val, `"`, `\"`) + `"`
+	case valVar:
+		return "$" + v.val
+	case valList:
+		items := make([]string, len(v.list))
+		for i, item := range v.list {
+			items[i] = item.String()
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case valObj:
+		items := make([]string, len(v.obj))
+		for i, a := range v.obj {
+			items[i] = a.name + ": " + a.val.String()
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return v.val
+}
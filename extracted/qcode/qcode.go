@@ -0,0 +1,758 @@
+// Package qcode compiles GraphQL query documents into QCode, a tree of
+// table selections resolved against the tables, columns and
+// relationships of a DBSchema
+package qcode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// QType is the type of a GraphQL operation
+type QType int
+
+const (
+	QTQuery QType = iota + 1
+	QTMutation
+	QTSubscription
+)
+
+// QCode is a compiled GraphQL operation
+type QCode struct {
+	Type QType
+	Name string
+	Vars []Var
+
+	// Selects holds all the selections, Roots the ids of the top
+	// level ones and Select.Children the ids of the nested ones
+	Selects []Select
+	Roots   []int32
+
+	// Mutates holds the writes of a mutation, the root selections
+	// return the rows written by the root writes
+	Mutates []Mutate
+
+	// Role is the role the operation was compiled for and FilterVars
+	// the variables of its filters and of the audit user, they are not
+	// defined by the operation and the caller must always set them
+	Role       string
+	FilterVars []string
+}
+
+// Var is a variable defined by the operation
+type Var struct {
+	Name    string
+	Type    string // GraphQL type eg. [Int!]!
+	Default *Value
+}
+
+// Select is a selection of rows from a table
+type Select struct {
+	ID       int32
+	ParentID int32 // -1 for root selections
+
+	// FieldName is the key of the selection in the result
+	FieldName string
+
+	// Table is the table name used in the query which can be
+	// an alias of Ti
+	Table string
+	Ti    schema.DBTable
+
+	// Singular is true when the selection returns a single row
+	// instead of a list of rows
+	Singular bool
+
+	Fields   []Field
+	Children []int32
+
+	// Path is the relationship chain from the parent table to this
+	// table, the first relationship starts at the parent table and the
+	// last one ends at this table, roots have no path
+	Path []schema.DBRel
+
+	// JoinFilters holds a filter for each hop of the path when any of
+	// the tables joined through is filtered
+	JoinFilters []JoinFilter
+
+	Where      *Exp
+	Search     *Search
+	Recursive  *Recursive
+	OrderBy    []OrderBy
+	DistinctOn []schema.DBColumn
+	Paging     Paging
+
+	// Args are the arguments of a function table in the order
+	// of the function inputs
+	Args []Arg
+
+	Conds []Cond
+
+	// WithDeleted is true when the selection keeps the soft deleted rows
+	// of its table and of the tables its path joins through
+	WithDeleted bool
+}
+
+// FieldType is the type of a selected field
+type FieldType int
+
+const (
+	FieldCol FieldType = iota
+	FieldTypename
+	FieldAgg
+	FieldSearchRank
+)
+
+// Field is a column, meta field or aggregate of related rows of a
+// selection
+type Field struct {
+	Type  FieldType
+	Name  string // key of the field in the result
+	Col   schema.DBColumn
+	Agg   schema.RelAggregate
+	Conds []Cond
+
+	// Where filters the rows of an aggregate and Through the join
+	// table of an aggregate over a many to many relationship
+	Where   *Exp
+	Through *Exp
+}
+
+// Cond is an @skip or @include directive that depends on a variable,
+// the field or selection is left out when the variable is true for a
+// skip and false for an include
+type Cond struct {
+	Var  string
+	Skip bool
+}
+
+// Paging holds the limit and offset of a selection, unset values
+// have no type
+type Paging struct {
+	Limit  Value
+	Offset Value
+
+	// Cursor is true for pages fetched with first, after, last or
+	// before, the ordering of the selection is then unique. From is
+	// the cursor the page starts after, or before when Backward
+	Cursor   bool
+	Backward bool
+	From     Value
+}
+
+// Arg is an argument of a function table
+type Arg struct {
+	Param schema.DBFuncParam
+	Val   Value
+}
+
+// Compiler compiles query documents against a schema, it is safe
+// for concurrent use
+type Compiler struct {
+	s        *schema.DBSchema
+	maxDepth int32
+	limits   Limits
+}
+
+// NewCompiler returns a compiler for a schema
+func NewCompiler(s *schema.DBSchema, opts ...Option) *Compiler {
+	co := &Compiler{s: s, maxDepth: DefaultMaxDepth}
+	for _, fn := range opts {
+		fn(co)
+	}
+	return co
+}
+
+// Schema returns the schema queries are compiled against
+func (co *Compiler) Schema() *schema.DBSchema {
+	return co.s
+}
+
+// compiler holds the state of a single compilation
+type compiler struct {
+	s     *schema.DBSchema
+	doc   *document
+	op    *operation
+	qc    *QCode
+	vars  map[string]struct{}
+	rels  map[string][]schema.TableRel
+	aggs  map[string][]schema.RelAggregate
+	stack []string // fragments being expanded
+	depth int32    // deepest level find can reach
+	lim   Limits
+	role  string
+	acl   map[string]tableAccess // access of the role by table
+}
+
+// Compile compiles the named operation of a query document, the name
+// can be empty when the document has a single operation. Errors in
+// the document are returned as *Error with the position of the problem
+func (co *Compiler) Compile(query []byte, opName string) (*QCode, error) {
+	return co.compile(query, opName, "")
+}
+
+func (co *Compiler) compile(query []byte, opName, role string) (*QCode, error) {
+	doc, err := parse(string(query))
+	if err != nil {
+		return nil, err
+	}
+
+	// the lookups of a query are made on one snapshot of the schema
+	c := &compiler{
+		s:     co.s.Snapshot(),
+		doc:   doc,
+		vars:  make(map[string]struct{}),
+		rels:  make(map[string][]schema.TableRel),
+		aggs:  make(map[string][]schema.RelAggregate),
+		depth: co.maxDepth,
+		lim:   co.limits,
+		role:  role,
+		acl:   make(map[string]tableAccess),
+	}
+
+	if c.op, err = pickOperation(doc, opName); err != nil {
+		return nil, err
+	}
+	return c.compile()
+}
+
+// pickOperation returns the operation to compile
+func pickOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.ops) != 1 {
+			return nil, errorf(doc.ops[1].pos, "operation name required for documents with several operations")
+		}
+		return doc.ops[0], nil
+	}
+
+	for _, op := range doc.ops {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("operation not found: %s", name)
+}
+
+func (c *compiler) compile() (*QCode, error) {
+	op := c.op
+	c.qc = &QCode{Name: op.name, Role: c.role}
+
+	switch op.typ {
+	case "query":
+		c.qc.Type = QTQuery
+	case "mutation":
+		c.qc.Type = QTMutation
+	case "subscription":
+		c.qc.Type = QTSubscription
+	}
+
+	if len(op.dirs) != 0 {
+		return nil, errorf(op.dirs[0].pos, "unknown directive: @%s", op.dirs[0].name)
+	}
+
+	for _, vd := range op.vars {
+		v := Var{Name: vd.name, Type: vd.typ}
+		if vd.def != nil {
+			dv, err := c.value(vd.def)
+			if err != nil {
+				return nil, err
+			}
+			v.Default = &dv
+		}
+		c.qc.Vars = append(c.qc.Vars, v)
+		c.vars[vd.name] = struct{}{}
+	}
+
+	fields, err := c.expand(op.fields, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for _, fs := range fields {
+		f := fs.f
+		if _, ok := keys[f.key()]; ok {
+			return nil, errorf(f.pos, "duplicate field: %s", f.key())
+		}
+		keys[f.key()] = struct{}{}
+
+		if err := reserveCursorKey(keys, f); err != nil {
+			return nil, err
+		}
+
+		id, err := c.addRoot(fs)
+		if err != nil {
+			return nil, err
+		}
+		if id != -1 {
+			c.qc.Roots = append(c.qc.Roots, id)
+		}
+	}
+
+	if len(c.qc.Roots) == 0 {
+		return nil, errorf(op.pos, "no tables selected")
+	}
+	if err := c.lim.check(c.qc); err != nil {
+		return nil, err
+	}
+	return c.qc, nil
+}
+
+// key returns the result key of a field
+func (f *field) key() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// fieldSel is a field with the conditions of the fragments it came from
+type fieldSel struct {
+	f     *field
+	conds []Cond
+}
+
+// expand flattens fragment spreads and inline fragments into a list of
+// fields, on is the table fragments must apply to (empty at the root)
+func (c *compiler) expand(sels []selection, on string, conds []Cond) ([]fieldSel, error) {
+	var fields []fieldSel
+
+	for _, s := range sels {
+		sc, skip, err := c.conds(s.dirs, conds, false)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			continue
+		}
+
+		if s.field != nil {
+			fields = append(fields, fieldSel{f: s.field, conds: sc})
+			continue
+		}
+
+		fs := s.fields
+		typ := s.on
+
+		if s.spread != "" {
+			fr, ok := c.doc.frags[s.spread]
+			if !ok {
+				return nil, errorf(s.pos, "unknown fragment: %s", s.spread)
+			}
+			for _, n := range c.stack {
+				if n == fr.name {
+					return nil, errorf(s.pos, "fragment cycle: %s", strings.Join(append(c.stack, fr.name), " -> "))
+				}
+			}
+			if sc, skip, err = c.conds(fr.dirs, sc, false); err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+			fs, typ = fr.fields, fr.on
+			c.stack = append(c.stack, fr.name)
+		}
+
+		if typ != "" && !typeMatches(typ, on) {
+			return nil, errorf(s.pos, "fragment on %s cannot be used on %s", typ, typeOrRoot(on))
+		}
+
+		v, err := c.expand(fs, on, sc)
+		if s.spread != "" {
+			c.stack = c.stack[:len(c.stack)-1]
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, v...)
+	}
+	return fields, nil
+}
+
+// typeMatches returns true if a fragment type condition names the
+// table, names are compared ignoring case and underscores so both
+// line_items and LineItems match
+func typeMatches(typ, table string) bool {
+	if table == "" {
+		switch typ {
+		case "Query", "Mutation", "Subscription":
+			return true
+		}
+		return false
+	}
+	norm := func(s string) string { return strings.ToLower(strings.ReplaceAll(s, "_", "")) }
+	return norm(typ) == norm(table)
+}
+
+func typeOrRoot(table string) string {
+	if table == "" {
+		return "the root"
+	}
+	return table
+}
+
+// conds applies the @skip and @include directives to the conditions
+// inherited from the parent, skip is true when a constant directive
+// leaves the field out, object allows the @object directive
+func (c *compiler) conds(dirs []directive, parent []Cond, object bool) ([]Cond, bool, error) {
+	conds := parent
+
+	for _, d := range dirs {
+		switch d.name {
+		case "skip", "include":
+		case "object":
+			if object {
+				continue
+			}
+			fallthrough
+		default:
+			return nil, false, errorf(d.pos, "unknown directive: @%s", d.name)
+		}
+
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return nil, false, errorf(d.pos, "@%s requires a single 'if' argument", d.name)
+		}
+
+		v := d.args[0].val
+		switch v.typ {
+		case valBool:
+			if (v.val == "true") == (d.name == "skip") {
+				return nil, true, nil
+			}
+		case valVar:
+			if _, ok := c.vars[v.val]; !ok {
+				return nil, false, errorf(v.pos, "variable not defined: $%s", v.val)
+			}
+			conds = append(conds[:len(conds):len(conds)], Cond{Var: v.val, Skip: d.name == "skip"})
+		default:
+			return nil, false, errorf(v.pos, "@%s 'if' must be a boolean or a variable", d.name)
+		}
+	}
+	return conds, false, nil
+}
+
+// addRoot adds a top level selection of a table
+func (c *compiler) addRoot(fs fieldSel) (int32, error) {
+	f := fs.f
+
+	if f.name == "__typename" {
+		return -1, errorf(f.pos, "__typename is not supported at the root")
+	}
+
+	t, err := c.s.Find("", f.name)
+	if err == nil {
+		t, err = c.table(t)
+	}
+	if err != nil || t.Blocked {
+		return -1, errorf(f.pos, "unknown table: %s", f.name)
+	}
+
+	sel := Select{
+		ID:        int32(len(c.qc.Selects)),
+		ParentID:  -1,
+		FieldName: f.key(),
+		Table:     f.name,
+		Ti:        t,
+	}
+
+	if c.qc.Type == QTMutation {
+		if len(fs.conds) != 0 {
+			return -1, errorf(f.pos, "mutation on %s cannot depend on @skip or @include", f.name)
+		}
+		if fs.f, err = c.addMutation(&sel, f); err != nil {
+			return -1, err
+		}
+	}
+	return c.addSelect(sel, fs)
+}
+
+// addSelect compiles the arguments and fields of a selection and adds
+// it along with its nested selections
+func (c *compiler) addSelect(sel Select, fs fieldSel) (int32, error) {
+	f := fs.f
+
+	conds, skip, err := c.conds(f.dirs, fs.conds, true)
+	if err != nil {
+		return -1, err
+	}
+	if skip {
+		return -1, nil
+	}
+	sel.Conds = conds
+
+	for _, d := range f.dirs {
+		if d.name == "object" {
+			sel.Singular = true
+		}
+	}
+
+	if len(f.fields) == 0 {
+		return -1, errorf(f.pos, "no fields selected for %s", f.name)
+	}
+
+	// the rows of remote tables are fetched after the query runs by the
+	// key in the parent row
+	if sel.Ti.Type == "remote" {
+		switch {
+		case sel.ParentID == -1:
+			return -1, errorf(f.pos, "remote table %s must be selected in a related table", f.name)
+		case len(f.args) != 0:
+			return -1, errorf(f.args[0].pos, "remote table %s takes no arguments", f.name)
+		}
+	}
+
+	if err := c.compileArgs(&sel, f); err != nil {
+		return -1, err
+	}
+
+	filter, err := c.rowFilter(sel.Ti, sel.WithDeleted)
+	if err != nil {
+		return -1, err
+	}
+	sel.Where = andExp(filter, sel.Where)
+	if sel.Recursive != nil {
+		if sel.Recursive.Filter, err = c.rowFilter(sel.Ti, sel.WithDeleted); err != nil {
+			return -1, err
+		}
+	}
+	if sel.JoinFilters, err = c.joinFilters(sel.Path, sel.WithDeleted); err != nil {
+		return -1, err
+	}
+
+	c.qc.Selects = append(c.qc.Selects, sel)
+	id := sel.ID
+
+	fields, err := c.expand(f.fields, sel.Table, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	keys := make(map[string]struct{})
+	for _, cf := range fields {
+		k := cf.f.key()
+		if _, ok := keys[k]; ok {
+			return -1, errorf(cf.f.pos, "duplicate field: %s", k)
+		}
+		keys[k] = struct{}{}
+
+		if err := reserveCursorKey(keys, cf.f); err != nil {
+			return -1, err
+		}
+
+		if err := c.addField(id, cf); err != nil {
+			return -1, err
+		}
+	}
+	return id, nil
+}
+
+// addField adds a column, meta field or nested selection to a selection
+func (c *compiler) addField(id int32, fs fieldSel) error {
+	f := fs.f
+	sel := &c.qc.Selects[id]
+	t := sel.Ti
+
+	if f.name == "__typename" {
+		conds, skip, err := c.conds(f.dirs, fs.conds, false)
+		if err != nil || skip {
+			return err
+		}
+		sel.Fields = append(sel.Fields, Field{Type: FieldTypename, Name: f.key(), Conds: conds})
+		return nil
+	}
+
+	if _, ok := t.ColumnExists(f.name); !ok && f.name == SearchRank && sel.Search != nil {
+		conds, skip, err := c.conds(f.dirs, fs.conds, false)
+		if err != nil || skip {
+			return err
+		}
+		sel.Fields = append(sel.Fields, Field{Type: FieldSearchRank, Name: f.key(), Conds: conds})
+		return nil
+	}
+
+	if len(f.fields) == 0 {
+		fd := Field{Type: FieldCol, Name: f.key()}
+
+		col, ok := t.ColumnExists(f.name)
+		switch {
+		case ok && !col.Blocked:
+			fd.Col = col
+		default:
+			agg, err := c.relAggregate(t, f)
+			if err != nil {
+				return err
+			}
+			if fd.Where, err = c.filter(agg.Rel.Right.Ti); err != nil {
+				return err
+			}
+			if agg.Rel.Type == schema.RelManyToMany {
+				if fd.Through, err = c.filter(agg.Rel.Through.Ti); err != nil {
+					return err
+				}
+			}
+			fd.Type, fd.Agg = FieldAgg, agg
+		}
+		if len(f.args) != 0 {
+			return errorf(f.args[0].pos, "unknown argument: %s", f.args[0].name)
+		}
+
+		conds, skip, err := c.conds(f.dirs, fs.conds, false)
+		if err != nil || skip {
+			return err
+		}
+		fd.Conds = conds
+		sel.Fields = append(sel.Fields, fd)
+		return nil
+	}
+
+	// selections over the depth limit are left out when truncating
+	if c.lim.Truncate && c.lim.MaxDepth > 0 && c.qc.depth(sel) >= c.lim.MaxDepth {
+		return nil
+	}
+
+	child, err := c.childSelect(sel, f)
+	if err != nil {
+		return err
+	}
+
+	cid, err := c.addSelect(child, fs)
+	if err != nil || cid == -1 {
+		return err
+	}
+
+	// addSelect can grow the slice so the parent is looked up again
+	sel = &c.qc.Selects[id]
+	sel.Children = append(sel.Children, cid)
+	return nil
+}
+
+// childSelect resolves a nested field to a related table, the field can
+// be a relationship name of the table or the name of a table reachable
+// from it
+func (c *compiler) childSelect(parent *Select, f *field) (Select, error) {
+	sel := Select{
+		ID:        int32(len(c.qc.Selects)),
+		ParentID:  parent.ID,
+		FieldName: f.key(),
+		Table:     f.name,
+	}
+
+	if parent.Ti.Type == "remote" {
+		return sel, errorf(f.pos, "unknown field: %s.%s", parent.Ti.Name, f.name)
+	}
+
+	rels, err := c.tableRels(parent.Ti)
+	if err != nil {
+		return sel, err
+	}
+
+	for _, r := range rels {
+		if r.Name != f.name {
+			continue
+		}
+		ok, err := c.pathAllowed([]schema.DBRel{r.DBRel})
+		if err != nil {
+			return sel, err
+		}
+		if !ok {
+			break
+		}
+		if sel.Ti, err = c.table(r.Right.Ti); err != nil {
+			return sel, err
+		}
+		sel.Path = []schema.DBRel{r.DBRel}
+		sel.Singular = !r.Many
+		return sel, nil
+	}
+
+	t, err := c.s.Find("", f.name)
+	if err == nil {
+		t, err = c.table(t)
+	}
+	if err != nil || t.Blocked {
+		return sel, errorf(f.pos, "unknown field: %s.%s", parent.Ti.Name, f.name)
+	}
+
+	path, err := c.s.FindPath(qualifiedName(c.s, parent.Ti), f.name, "")
+	if err != nil {
+		return sel, errorf(f.pos, "no relationship between %s and %s: %s", parent.Ti.Name, f.name, err)
+	}
+
+	sel.Ti = t
+	sel.Singular = true
+	for _, p := range path {
+		r := schema.PathToRel(p)
+		sel.Path = append(sel.Path, r)
+
+		if ok, err := c.pathAllowed([]schema.DBRel{r}); err != nil || !ok {
+			return sel, errorf(f.pos, "no relationship between %s and %s", parent.Ti.Name, f.name)
+		}
+
+		// a hop to child rows makes the whole path a list
+		if r.ToMany() {
+			sel.Singular = false
+		}
+	}
+	return sel, nil
+}
+
+// tableRels returns the named relationships of a table
+func (c *compiler) tableRels(t schema.DBTable) ([]schema.TableRel, error) {
+	k := t.String()
+	if v, ok := c.rels[k]; ok {
+		return v, nil
+	}
+
+	v, err := c.s.GetTableRels(t)
+	if err != nil {
+		return nil, err
+	}
+	c.rels[k] = v
+	return v, nil
+}
+
+// relAggregate returns the aggregate of related rows a field names
+func (c *compiler) relAggregate(t schema.DBTable, f *field) (schema.RelAggregate, error) {
+	k := t.String()
+	aggs, ok := c.aggs[k]
+	if !ok {
+		var err error
+		if aggs, err = c.s.GetRelAggregates(t); err != nil {
+			return schema.RelAggregate{}, err
+		}
+		c.aggs[k] = aggs
+	}
+
+	for _, a := range aggs {
+		if a.Name != f.name {
+			continue
+		}
+		ok, err := c.pathAllowed([]schema.DBRel{a.Rel.DBRel})
+		if err != nil {
+			return a, err
+		}
+		rt, err := c.table(a.Rel.Right.Ti)
+		if err != nil {
+			return a, err
+		}
+		// masked values are not aggregated
+		if col, found := rt.ColumnExists(a.Col.Name); ok && (a.Col.Name == "" || (found && !col.Blocked && col.Mask == "")) {
+			a.Rel.Right.Ti = rt
+			return a, nil
+		}
+		break
+	}
+	return schema.RelAggregate{}, errorf(f.pos, "unknown column: %s.%s", t.Name, f.name)
+}
+
+// qualifiedName returns the table name, schema qualified when the
+// table is not in the default schema
+func qualifiedName(s *schema.DBSchema, t schema.DBTable) string {
+	if t.Schema == "" || t.Schema == s.DBSchema() {
+		return t.Name
+	}
+	return t.Schema + "." + t.Name
+}
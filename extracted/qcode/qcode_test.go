@@ -0,0 +1,139 @@
+package qcode
+
+import (
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// blogSchema has posts and comments belonging to users, a profile per
+// user and posts with an array of tags
+func blogSchema(t *testing.T, pkUnique bool) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("profiles", "id pk", "user_id notnull unique", "bio").
+		Table("posts", "id pk", "user_id notnull", "title text notnull", "tag_ids bigint[] array").
+		Table("comments", "id pk", "post_id", "user_id", "body").
+		Table("tags", "id pk", "name").
+		FK("profiles.user_id", "users.id").
+		FK("posts.user_id", "users.id").
+		FK("posts.tag_ids", "tags.id").
+		FK("comments.post_id", "posts.id").
+		FK("comments.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the primary keys of an introspected Postgres schema are not
+	// marked as unique keys
+	if !pkUnique {
+		for i, ti := range di.Tables {
+			for j, c := range ti.Columns {
+				if c.PrimaryKey {
+					di.Tables[i].Columns[j].UniqueKey = false
+				}
+			}
+			di.Tables[i].PrimaryCol.UniqueKey = false
+		}
+	}
+
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// compile compiles a query against the blog schema, with and without
+// unique primary keys
+func compile(t *testing.T, query string) []*QCode {
+	t.Helper()
+	var out []*QCode
+	for _, pkUnique := range []bool{true, false} {
+		qc, err := NewCompiler(blogSchema(t, pkUnique)).Compile([]byte(query), "")
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		out = append(out, qc)
+	}
+	return out
+}
+
+// child returns the selection of a field of a selection
+func child(t *testing.T, qc *QCode, sel *Select, name string) *Select {
+	t.Helper()
+	for _, id := range sel.Children {
+		if c := &qc.Selects[id]; c.FieldName == name {
+			return c
+		}
+	}
+	t.Fatalf("%s has no child %s", sel.FieldName, name)
+	return nil
+}
+
+func TestParseNested(t *testing.T) {
+	sels, err := ParseSelections([]byte(`query { users { id posts(limit: 2) { title } } }`), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sels) != 1 || sels[0].Field != "users" || len(sels[0].Fields) != 2 {
+		t.Fatalf("unexpected selections: %+v", sels)
+	}
+	posts := sels[0].Fields[1]
+	if posts.Field != "posts" || posts.Args["limit"] != float64(2) || len(posts.Fields) != 1 {
+		t.Fatalf("unexpected posts selection: %+v", posts)
+	}
+}
+
+func TestChildSingular(t *testing.T) {
+	tests := []struct {
+		query, parent, child string
+		singular             bool
+	}{
+		{`{ users { posts { id } } }`, "users", "posts", false},
+		{`{ users { comments { id } } }`, "users", "comments", false},
+		{`{ users { profiles { id } } }`, "users", "profiles", true},
+		{`{ posts { user { id } } }`, "posts", "user", true},
+		{`{ comments { user { id } } }`, "comments", "user", true},
+		{`{ comments { post { id } } }`, "comments", "post", true},
+		{`{ profiles { user { id } } }`, "profiles", "user", true},
+		{`{ posts { tag { id } } }`, "posts", "tag", false},
+		{`{ tags { posts { id } } }`, "tags", "posts", false},
+	}
+
+	for _, tt := range tests {
+		for _, qc := range compile(t, tt.query) {
+			root := &qc.Selects[qc.Roots[0]]
+			if root.FieldName != tt.parent || root.Singular {
+				t.Errorf("%s: unexpected root %s singular %v", tt.query, root.FieldName, root.Singular)
+				continue
+			}
+			if c := child(t, qc, root, tt.child); c.Singular != tt.singular {
+				t.Errorf("%s: %s singular %v, want %v", tt.query, tt.child, c.Singular, tt.singular)
+			}
+		}
+	}
+}
+
+// a table reached through a path is a list as soon as a hop goes to
+// child rows
+func TestPathSingular(t *testing.T) {
+	tests := []struct {
+		query, parent, child string
+		singular             bool
+	}{
+		{`{ comments { users { id } } }`, "comments", "users", true},
+		{`{ profiles { posts { id } } }`, "profiles", "posts", false},
+	}
+
+	for _, tt := range tests {
+		for _, qc := range compile(t, tt.query) {
+			c := child(t, qc, &qc.Selects[qc.Roots[0]], tt.child)
+			if c.Singular != tt.singular {
+				t.Errorf("%s: %s singular %v, want %v", tt.query, tt.child, c.Singular, tt.singular)
+			}
+		}
+	}
+}
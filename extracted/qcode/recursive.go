@@ -0,0 +1,104 @@
+package qcode
+
+import (
+	"strconv"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultMaxDepth is the deepest level find reaches unless the compiler
+// is configured with WithMaxDepth
+const DefaultMaxDepth = 10
+
+// Option configures a Compiler
+type Option func(*Compiler)
+
+// WithMaxDepth sets the deepest level find can reach, a depth argument
+// larger than it is an error
+func WithMaxDepth(n int32) Option {
+	return func(co *Compiler) {
+		co.maxDepth = n
+	}
+}
+
+// FindType is the direction a recursive selection follows a foreign
+// key of a table to itself
+type FindType int
+
+const (
+	FindChildren FindType = iota + 1 // rows referencing the row and so on
+	FindParents                      // the row referenced by the row and so on
+)
+
+// Recursive is a selection of all the rows reachable from the parent
+// row by following a foreign key of the table to itself, a level is
+// one step along the key and Depth the deepest level selected
+type Recursive struct {
+	Find  FindType
+	Depth int32
+	Ref   schema.SelfRef
+
+	// Filter holds the filters of the table, rows that do not match
+	// are not followed
+	Filter *Exp
+}
+
+// find compiles the find argument of a selection nested in a selection
+// of the same table, the relationship it was reached through names the
+// foreign key to follow
+func (c *compiler) find(sel *Select, a argument) error {
+	if a.val.typ != valEnum && a.val.typ != valStr {
+		return errorf(a.val.pos, "expected children or parents found %s", a.val)
+	}
+
+	rc := &Recursive{Depth: c.depth}
+	switch a.val.val {
+	case "children":
+		rc.Find = FindChildren
+	case "parents":
+		rc.Find = FindParents
+	default:
+		return errorf(a.val.pos, "expected children or parents found %s", a.val)
+	}
+
+	if len(sel.Path) != 1 || sel.Path[0].Type != schema.RelRecursive {
+		return errorf(a.pos, "find requires a relationship of %s to itself", sel.Ti.Name)
+	}
+	r := sel.Path[0]
+
+	found := false
+	for _, ref := range sel.Ti.SelfRefs() {
+		if (ref.Col.Name == r.Left.Col.Name && ref.Key.Name == r.Right.Col.Name) ||
+			(ref.Col.Name == r.Right.Col.Name && ref.Key.Name == r.Left.Col.Name) {
+			rc.Ref, found = ref, true
+		}
+	}
+	if !found {
+		return errorf(a.pos, "find requires a relationship of %s to itself", sel.Ti.Name)
+	}
+
+	// the depth can come before or after find
+	if sel.Recursive != nil {
+		rc.Depth = sel.Recursive.Depth
+	}
+	sel.Recursive = rc
+	sel.Singular = false
+	return nil
+}
+
+// depthArg compiles the depth limit of a recursive selection
+func (c *compiler) depthArg(sel *Select, a argument) error {
+	if a.val.typ != valInt {
+		return errorf(a.val.pos, "depth must be an integer found %s", a.val)
+	}
+	n, err := strconv.ParseInt(a.val.val, 10, 32)
+	if err != nil || n < 1 || int32(n) > c.depth {
+		return errorf(a.val.pos, "depth must be between 1 and %d", c.depth)
+	}
+
+	if sel.Recursive == nil {
+		sel.Recursive = &Recursive{}
+	}
+	sel.Recursive.Depth = int32(n)
+	return nil
+}
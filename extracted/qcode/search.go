@@ -0,0 +1,98 @@
+package qcode
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// SearchRank is the field and order by key of the rank of a row found
+// by a full text search
+const SearchRank = "search_rank"
+
+// SearchMode is how the text of a search is turned into a query
+type SearchMode int
+
+const (
+	SearchWeb    SearchMode = iota // websearch_to_tsquery
+	SearchPlain                    // plainto_tsquery
+	SearchPhrase                   // phraseto_tsquery
+	SearchRaw                      // to_tsquery
+)
+
+var searchModes = map[string]SearchMode{
+	"websearch": SearchWeb,
+	"plain":     SearchPlain,
+	"phrase":    SearchPhrase,
+	"raw":       SearchRaw,
+}
+
+// Search is a full text search of a selection, a row matches when any
+// of the columns matches the query
+type Search struct {
+	Query Value
+	Mode  SearchMode
+	Cols  []schema.DBSearchColumn
+}
+
+// search compiles the search argument, a string or a variable is
+// searched as typed into a search box, an object eg.
+// { query: $q, mode: phrase } picks how it is parsed
+func (c *compiler) search(t schema.DBTable, v *value) (*Search, error) {
+	cols := t.SearchColumns()
+	if len(cols) == 0 {
+		return nil, errorf(v.pos, "%s has no full text search columns", t.Name)
+	}
+	s := &Search{Cols: cols}
+
+	q := v
+	if v.typ == valObj {
+		q = nil
+		for _, a := range v.obj {
+			switch a.name {
+			case "query":
+				q = a.val
+			case "mode":
+				m, ok := searchModes[a.val.val]
+				if !ok || (a.val.typ != valEnum && a.val.typ != valStr) {
+					return nil, errorf(a.val.pos, "invalid search mode: %s", a.val)
+				}
+				s.Mode = m
+			default:
+				return nil, errorf(a.pos, "unknown search option: %s", a.name)
+			}
+		}
+		if q == nil {
+			return nil, errorf(v.pos, "search requires a query")
+		}
+	}
+
+	if q.typ != valStr && q.typ != valVar {
+		return nil, errorf(q.pos, "expected a search query found %s", q)
+	}
+
+	var err error
+	s.Query, err = c.value(q)
+	return s, err
+}
+
+// rankOrder sorts a search by rank when it has no other ordering, the
+// rank can only be used to sort a selection with a search
+func (c *compiler) rankOrder(sel *Select, f *field) error {
+	if sel.Search == nil {
+		for _, ob := range sel.OrderBy {
+			if ob.Rank {
+				return errorf(f.pos, "%s requires a search", SearchRank)
+			}
+		}
+		return nil
+	}
+
+	if len(sel.OrderBy) == 0 {
+		sel.OrderBy = []OrderBy{rankOrderBy(OrderDesc)}
+	}
+	return nil
+}
+
+// rankOrderBy returns the ordering by search rank
+func rankOrderBy(o Order) OrderBy {
+	return OrderBy{Col: schema.DBColumn{Name: SearchRank, Type: "real"}, Order: o, Rank: true}
+}
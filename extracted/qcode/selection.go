@@ -0,0 +1,208 @@
+package qcode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Selection is a field of an operation with its fragments expanded, it
+// lets fields that are not tables such as __schema and __type be
+// answered outside of the compiler
+type Selection struct {
+	Name   string // key of the field in the result
+	Field  string
+	Args   map[string]interface{}
+	On     string // type condition of the fragment the field came from
+	Fields []Selection
+}
+
+// ParseSelections returns the root fields of the named operation, the
+// arguments and the @skip and @include directives are resolved with the
+// variables
+func ParseSelections(query []byte, opName string, vars json.RawMessage) ([]Selection, error) {
+	doc, err := parse(string(query))
+	if err != nil {
+		return nil, err
+	}
+	op, err := pickOperation(doc, opName)
+	if err != nil {
+		return nil, err
+	}
+
+	sp := &selParser{doc: doc, op: op}
+	if len(vars) != 0 {
+		if err := json.Unmarshal(vars, &sp.vars); err != nil {
+			return nil, fmt.Errorf("invalid variables: %w", err)
+		}
+	}
+	return sp.expand(op.fields, "")
+}
+
+// selParser holds the state of ParseSelections
+type selParser struct {
+	doc   *document
+	op    *operation
+	vars  map[string]interface{}
+	stack []string // fragments being expanded
+}
+
+// expand returns the fields of a selection set, on is the type condition
+// of the enclosing fragment
+func (sp *selParser) expand(sels []selection, on string) ([]Selection, error) {
+	var fields []Selection
+
+	for _, s := range sels {
+		skip, err := sp.skip(s.dirs)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			continue
+		}
+
+		if f := s.field; f != nil {
+			if skip, err = sp.skip(f.dirs); err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+			sel := Selection{Name: f.key(), Field: f.name, On: on}
+			if sel.Args, err = sp.args(f.args); err != nil {
+				return nil, err
+			}
+			if sel.Fields, err = sp.expand(f.fields, ""); err != nil {
+				return nil, err
+			}
+			fields = append(fields, sel)
+			continue
+		}
+
+		fs, typ := s.fields, s.on
+		if s.spread != "" {
+			fr, ok := sp.doc.frags[s.spread]
+			if !ok {
+				return nil, errorf(s.pos, "unknown fragment: %s", s.spread)
+			}
+			for _, n := range sp.stack {
+				if n == fr.name {
+					return nil, errorf(s.pos, "fragment cycle: %s", strings.Join(append(sp.stack, fr.name), " -> "))
+				}
+			}
+			if skip, err = sp.skip(fr.dirs); err != nil {
+				return nil, err
+			}
+			if skip {
+				continue
+			}
+			fs, typ = fr.fields, fr.on
+			sp.stack = append(sp.stack, fr.name)
+		}
+		if typ == "" {
+			typ = on
+		}
+
+		v, err := sp.expand(fs, typ)
+		if s.spread != "" {
+			sp.stack = sp.stack[:len(sp.stack)-1]
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, v...)
+	}
+	return fields, nil
+}
+
+// skip returns true when an @skip or @include directive leaves the field
+// or fragment out
+func (sp *selParser) skip(dirs []directive) (bool, error) {
+	for _, d := range dirs {
+		if d.name != "skip" && d.name != "include" {
+			return false, errorf(d.pos, "unknown directive: @%s", d.name)
+		}
+		if len(d.args) != 1 || d.args[0].name != "if" {
+			return false, errorf(d.pos, "@%s requires a single 'if' argument", d.name)
+		}
+
+		v, err := sp.value(d.args[0].val)
+		if err != nil {
+			return false, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return false, errorf(d.args[0].pos, "@%s 'if' must be a boolean", d.name)
+		}
+		if b == (d.name == "skip") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// args returns the values of arguments by name
+func (sp *selParser) args(args []argument) (map[string]interface{}, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]interface{}, len(args))
+	for _, a := range args {
+		v, err := sp.value(a.val)
+		if err != nil {
+			return nil, err
+		}
+		m[a.name] = v
+	}
+	return m, nil
+}
+
+// value returns an argument value as it would be decoded from JSON,
+// enum values are strings and variables take their default when unset
+func (sp *selParser) value(v *value) (interface{}, error) {
+	switch v.typ {
+	case valStr, valEnum:
+		return v.val, nil
+	case valInt, valFloat:
+		f, err := strconv.ParseFloat(v.val, 64)
+		if err != nil {
+			return nil, errorf(v.pos, "invalid number: %s", v.val)
+		}
+		return f, nil
+	case valBool:
+		return v.val == "true", nil
+	case valNull:
+		return nil, nil
+
+	case valList:
+		list := make([]interface{}, len(v.list))
+		for i, item := range v.list {
+			iv, err := sp.value(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = iv
+		}
+		return list, nil
+
+	case valObj:
+		return sp.args(v.obj)
+
+	case valVar:
+		if val, ok := sp.vars[v.val]; ok {
+			return val, nil
+		}
+		for _, vd := range sp.op.vars {
+			if vd.name != v.val {
+				continue
+			}
+			if vd.def == nil {
+				return nil, nil
+			}
+			return sp.value(vd.def)
+		}
+		return nil, errorf(v.pos, "variable not defined: $%s", v.val)
+	}
+	return nil, errorf(v.pos, "unexpected value: %s", v)
+}
@@ -0,0 +1,106 @@
+package qcode
+
+import (
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Shape is the geometry a spatial operator compares a PostGIS column
+// with, one of Point, Polygon or GeoJSON is set
+//
+//	location: { st_dwithin: { point: [-122.4, 37.7], distance: 1000 } }
+//	area: { st_contains: { point: [$lng, $lat] } }
+//	area: { st_intersects: { geojson: $shape } }
+type Shape struct {
+	Point   []Value   // x (longitude) and y (latitude)
+	Polygon [][]Value // vertices of the polygon
+	GeoJSON Value     // GeoJSON geometry as a string or variable
+
+	// Distance is the distance of st_dwithin in the units of the column
+	// SRID or in meters for geography columns
+	Distance Value
+}
+
+// shape compiles the geometry of a spatial operator
+func (c *compiler) shape(col schema.DBColumn, o argument) (*Shape, error) {
+	if col.GeoType == "" {
+		return nil, errorf(o.pos, "%s requires a geometry column: %s", o.name, col.Name)
+	}
+	if o.val.typ != valObj {
+		return nil, errorf(o.val.pos, "%s requires an object found %s", o.name, o.val)
+	}
+
+	sh := &Shape{}
+	var n int
+
+	for _, a := range o.val.obj {
+		var err error
+
+		switch a.name {
+		case "point":
+			sh.Point, err = c.coords(a.val)
+			n++
+		case "polygon":
+			if a.val.typ != valList || len(a.val.list) < 3 {
+				return nil, errorf(a.val.pos, "polygon requires a list of at least three points")
+			}
+			for _, v := range a.val.list {
+				p, err := c.coords(v)
+				if err != nil {
+					return nil, err
+				}
+				sh.Polygon = append(sh.Polygon, p)
+			}
+			n++
+		case "geojson":
+			sh.GeoJSON, err = c.scalarValue(a.val)
+			if err == nil && sh.GeoJSON.Type != ValStr && sh.GeoJSON.Type != ValVar {
+				err = errorf(a.val.pos, "geojson requires a string")
+			}
+			n++
+		case "distance":
+			if o.name != "st_dwithin" {
+				return nil, errorf(a.pos, "%s has no distance", o.name)
+			}
+			sh.Distance, err = c.number(a.val)
+		default:
+			return nil, errorf(a.pos, "unknown %s argument: %s", o.name, a.name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case n != 1:
+		return nil, errorf(o.val.pos, "%s requires one of point, polygon or geojson", o.name)
+	case o.name == "st_dwithin" && sh.Distance.Type == ValNone:
+		return nil, errorf(o.val.pos, "%s requires a distance", o.name)
+	}
+	return sh, nil
+}
+
+// coords compiles the x and y of a point
+func (c *compiler) coords(v *value) ([]Value, error) {
+	if v.typ != valList || len(v.list) != 2 {
+		return nil, errorf(v.pos, "a point requires a list of two numbers found %s", v)
+	}
+	var p []Value
+	for _, item := range v.list {
+		n, err := c.number(item)
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, n)
+	}
+	return p, nil
+}
+
+// number compiles a number or a variable holding one
+func (c *compiler) number(v *value) (Value, error) {
+	n, err := c.scalarValue(v)
+	if err == nil && n.Type != ValNum && n.Type != ValVar {
+		err = errorf(v.pos, "expected a number found %s", v)
+	}
+	return n, err
+}
@@ -0,0 +1,54 @@
+package qcode
+
+import "sort"
+
+// Tables returns the tables a query reads in order, each table is named
+// both 'table' and 'schema.table'. A cached result of the query is stale
+// once one of them is written
+func (qc *QCode) Tables() []string {
+	tables := make(map[string]struct{})
+	for _, sel := range qc.Selects {
+		addTable(tables, sel.Ti.Name, sel.Ti.Schema)
+		for _, r := range sel.Path {
+			addTable(tables, r.Left.Ti.Name, r.Left.Ti.Schema)
+			addTable(tables, r.Through.Ti.Name, r.Through.Ti.Schema)
+		}
+		for _, f := range sel.Fields {
+			if f.Type == FieldAgg {
+				r := f.Agg.Rel
+				addTable(tables, r.Right.Ti.Name, r.Right.Ti.Schema)
+				addTable(tables, r.Through.Ti.Name, r.Through.Ti.Schema)
+			}
+		}
+	}
+	return sortedTables(tables)
+}
+
+// WriteTables returns the tables a mutation writes in order, named like
+// those of Tables. It is empty for a query
+func (qc *QCode) WriteTables() []string {
+	tables := make(map[string]struct{})
+	for _, m := range qc.Mutates {
+		addTable(tables, m.Ti.Name, m.Ti.Schema)
+	}
+	return sortedTables(tables)
+}
+
+func addTable(tables map[string]struct{}, name, schema string) {
+	if name == "" {
+		return
+	}
+	tables[name] = struct{}{}
+	if schema != "" {
+		tables[schema+"."+name] = struct{}{}
+	}
+}
+
+func sortedTables(tables map[string]struct{}) []string {
+	names := make([]string, 0, len(tables))
+	for t := range tables {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	return names
+}
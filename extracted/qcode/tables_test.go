@@ -0,0 +1,34 @@
+package qcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTables(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ users { id } }`, "public.users users"},
+		{`{ users { id posts { id comments { id } } } }`,
+			"comments posts public.comments public.posts public.users users"},
+		// the tags of the posts through their array of keys
+		{`{ posts { id tags { name } } }`, "posts public.posts public.tags tags"},
+		{`mutation { users(insert: {email: "a"}) { id posts { id } } }`, "posts public.posts public.users users"},
+	}
+	for _, tt := range tests {
+		qc := compile(t, tt.query)[0]
+		if got := strings.Join(qc.Tables(), " "); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.query, got, tt.want)
+		}
+	}
+
+	qc := compile(t, `mutation { users(insert: {email: "a"}) { id posts { id } } }`)[0]
+	if got := strings.Join(qc.WriteTables(), " "); got != "public.users users" {
+		t.Errorf("got write tables %s", got)
+	}
+	if qc := compile(t, `{ users { id } }`)[0]; len(qc.WriteTables()) != 0 {
+		t.Errorf("got write tables %v for a query", qc.WriteTables())
+	}
+}
@@ -0,0 +1,49 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Plugin is a relationship plugin that also fetches the rows of its
+// tables, eg. an edge from products to their reviews in a search index
+//
+//	dbSchema, err := schema.NewDBSchema(di, nil, schema.WithRelPlugins(p))
+//	data, err = remote.Stitch(ctx, qc, data, remote.PluginResolvers(dbSchema))
+type Plugin interface {
+	schema.RelPlugin
+
+	// Resolver returns the resolver of a table of the plugin
+	Resolver(table string) Resolver
+}
+
+// BatchResolver is a Resolver that fetches the rows of many keys at once,
+// eg. with a multi-get of a key-value store. Stitch calls it once per
+// table with the keys of the result
+type BatchResolver interface {
+	Resolver
+
+	// ResolveBatch returns the rows of each key in the order of the keys,
+	// a missing key has a null or empty value
+	ResolveBatch(ctx context.Context, keys []json.RawMessage) ([]json.RawMessage, error)
+}
+
+// PluginResolvers returns the resolvers of the remote tables added by
+// plugins to a schema, the tables of plugins that are not a Plugin are
+// left out
+func PluginResolvers(s *schema.DBSchema) Resolvers {
+	res := make(Resolvers)
+	for _, t := range s.GetTables() {
+		if t.Type != "remote" {
+			continue
+		}
+		if p, ok := s.Plugin(t.Name).(Plugin); ok {
+			if r := p.Resolver(t.Name); r != nil {
+				res[t.Name] = r
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,142 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// reviewPlugin serves the reviews of products by their sku from a map,
+// all the keys of a result at once
+type reviewPlugin struct {
+	reviews map[string]string
+	batches atomic.Int32
+	err     error
+}
+
+func (p *reviewPlugin) Name() string { return "reviews" }
+
+func (p *reviewPlugin) Tables(di *schema.DBInfo) ([]schema.RemoteTable, error) {
+	return []schema.RemoteTable{{
+		Name:    "reviews",
+		Table:   "products",
+		Column:  "sku",
+		Columns: []schema.DBColumn{{Name: "stars", Type: "integer"}},
+	}}, nil
+}
+
+func (p *reviewPlugin) Resolver(table string) Resolver {
+	if table != "reviews" {
+		return nil
+	}
+	return p
+}
+
+func (p *reviewPlugin) Resolve(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+	return nil, errors.New("want a batch")
+}
+
+func (p *reviewPlugin) ResolveBatch(ctx context.Context, keys []json.RawMessage) ([]json.RawMessage, error) {
+	p.batches.Add(1)
+	if p.err != nil {
+		return nil, p.err
+	}
+	vals := make([]json.RawMessage, len(keys))
+	for i, k := range keys {
+		var sku string
+		if err := json.Unmarshal(k, &sku); err != nil {
+			return nil, err
+		}
+		if v, ok := p.reviews[sku]; ok {
+			vals[i] = json.RawMessage(v)
+		}
+	}
+	return vals, nil
+}
+
+func pluginSchema(t *testing.T, p schema.RelPlugin) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("products", "id pk", "sku text").
+		BuildSchema(schema.WithRelPlugins(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPluginStitch(t *testing.T) {
+	p := &reviewPlugin{reviews: map[string]string{
+		"a": `[{"stars": 5}, {"stars": 3}]`,
+		"b": `[{"stars": 1}]`,
+	}}
+	s := pluginSchema(t, p)
+	res := PluginResolvers(s)
+	if len(res) != 1 || res["reviews"] == nil {
+		t.Fatalf("got resolvers %v", res)
+	}
+
+	qc, _ := compile(t, s, `{ products { id reviews { stars } } }`)
+	data := json.RawMessage(`{"products": [
+		{"id": 1, "reviews": "a"},
+		{"id": 2, "reviews": "b"},
+		{"id": 3, "reviews": "c"}]}`)
+	got, err := Stitch(context.Background(), qc, data, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"products":[{"id":1,"reviews":[{"stars":5},{"stars":3}]},{"id":2,"reviews":[{"stars":1}]},{"id":3,"reviews":null}]}`
+	if string(got) != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if n := p.batches.Load(); n != 1 {
+		t.Errorf("got %d batches, want 1", n)
+	}
+
+	p.err = errors.New("index down")
+	if _, err := Stitch(context.Background(), qc, data, res); !errors.Is(err, p.err) {
+		t.Errorf("got %v, want the error of the batch", err)
+	}
+}
+
+// a batch returns a value for each key
+func TestPluginStitchMissing(t *testing.T) {
+	s := pluginSchema(t, &reviewPlugin{})
+	qc, _ := compile(t, s, `{ products { id reviews { stars } } }`)
+	data := json.RawMessage(`{"products": [{"id": 1, "reviews": "a"}]}`)
+
+	short := Resolvers{"reviews": shortBatch{}}
+	if _, err := Stitch(context.Background(), qc, data, short); err == nil {
+		t.Error("want an error for a result missing keys")
+	}
+}
+
+type shortBatch struct{}
+
+func (shortBatch) Resolve(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+	return nil, nil
+}
+
+func (shortBatch) ResolveBatch(ctx context.Context, keys []json.RawMessage) ([]json.RawMessage, error) {
+	return nil, nil
+}
+
+// the tables of plugins without resolvers have none
+func TestPluginResolversRelPlugin(t *testing.T) {
+	p := relOnly{}
+	if res := PluginResolvers(pluginSchema(t, p)); len(res) != 0 {
+		t.Errorf("got resolvers %v", res)
+	}
+}
+
+type relOnly struct{}
+
+func (relOnly) Name() string { return "rel" }
+
+func (relOnly) Tables(di *schema.DBInfo) ([]schema.RemoteTable, error) {
+	return (&reviewPlugin{}).Tables(di)
+}
@@ -0,0 +1,117 @@
+// Package remote adds the rows of remote tables to the result of a
+// query, the SQL statement returns the key of the remote rows in their
+// place and they are fetched from their resolvers once it has run
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Resolver fetches the rows of a remote table for a key, the result is
+// a JSON object or an array of objects
+type Resolver interface {
+	Resolve(ctx context.Context, key json.RawMessage) (json.RawMessage, error)
+}
+
+// ResolverFunc is a function used as a Resolver
+type ResolverFunc func(ctx context.Context, key json.RawMessage) (json.RawMessage, error)
+
+// Resolve calls the function
+func (fn ResolverFunc) Resolve(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+	return fn(ctx, key)
+}
+
+// DefaultTimeout is how long an HTTP resolver waits for a response
+// unless it has its own client
+const DefaultTimeout = 10 * time.Second
+
+// MaxResponseSize is the largest response an HTTP resolver reads
+const MaxResponseSize = 10 << 20
+
+// HTTPResolver fetches the rows of a remote table with a GET request,
+// "$id" in the URL is replaced by the key eg.
+// https://api.stripe.com/v1/customers/$id/payments
+type HTTPResolver struct {
+	URL    string
+	Header http.Header
+	Client *http.Client
+
+	// Path are the keys of the rows in the response eg. ["data"],
+	// the whole response is used when it is empty
+	Path []string
+}
+
+// NewHTTPResolver returns a resolver fetching rows from the URL
+func NewHTTPResolver(url string, path ...string) *HTTPResolver {
+	return &HTTPResolver{URL: url, Path: path}
+}
+
+// Resolve fetches the rows for a key
+func (r *HTTPResolver) Resolve(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+	u := strings.ReplaceAll(r.URL, "$id", url.PathEscape(keyString(key)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("remote: %s returned %s", req.URL.Redacted(), res.Status)
+	}
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, MaxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > MaxResponseSize {
+		return nil, fmt.Errorf("remote: response of %s is too large", req.URL.Redacted())
+	}
+	return pick(b, r.Path)
+}
+
+// pick returns the value at the path of a JSON document
+func pick(b []byte, path []string) (json.RawMessage, error) {
+	v := json.RawMessage(b)
+	for _, k := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(v, &obj); err != nil {
+			return nil, fmt.Errorf("remote: expected an object at %s: %w", k, err)
+		}
+		var ok bool
+		if v, ok = obj[k]; !ok {
+			return json.RawMessage("null"), nil
+		}
+	}
+	return v, nil
+}
+
+// keyString returns a key as it is put in a URL, strings are unquoted
+func keyString(key json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(key, &s); err == nil {
+		return s
+	}
+	return string(key)
+}
@@ -0,0 +1,183 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// paymentSchema has the payments of users fetched by their stripe id
+func paymentSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text", "stripe_id text").
+		BuildSchema(schema.WithRemoteTables(schema.RemoteTable{
+			Name:    "payments",
+			Table:   "users",
+			Column:  "stripe_id",
+			Columns: []schema.DBColumn{{Name: "amount", Type: "numeric"}, {Name: "currency", Type: "text"}},
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func compile(t *testing.T, s *schema.DBSchema, query string) (*qcode.QCode, string) {
+	t.Helper()
+	qc, err := qcode.NewCompiler(s).Compile([]byte(query), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if _, err := psql.NewCompiler(s).Compile(&b, qc); err != nil {
+		t.Fatal(err)
+	}
+	return qc, b.String()
+}
+
+func TestStitch(t *testing.T) {
+	qc, sql := compile(t, paymentSchema(t), `{ users { id payments { amount } } }`)
+	if !strings.Contains(sql, `'payments', to_json("users_0"."stripe_id")`) {
+		t.Errorf("statement without the remote key:\n%s", sql)
+	}
+
+	var calls int32
+	res := Resolvers{"payments": ResolverFunc(func(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+		atomic.AddInt32(&calls, 1)
+		return json.RawMessage(`[{"amount": 10, "currency": "usd", "key": ` + string(key) + `}]`), nil
+	})}
+
+	data := json.RawMessage(`{"users": [
+		{"id": 1, "payments": "cus_a"},
+		{"id": 2, "payments": "cus_a"},
+		{"id": 3, "payments": null}]}`)
+	got, err := Stitch(context.Background(), qc, data, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"users":[{"id":1,"payments":[{"amount":10}]},{"id":2,"payments":[{"amount":10}]},{"id":3,"payments":null}]}`
+	if string(got) != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("got %d resolver calls, want 1", calls)
+	}
+
+	// results without remote tables are returned as they are
+	qc, _ = compile(t, paymentSchema(t), `{ users { id } }`)
+	if got, _ := Stitch(context.Background(), qc, data, nil); !bytes.Equal(got, data) {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestStitchErrors(t *testing.T) {
+	qc, _ := compile(t, paymentSchema(t), `{ users { id payments { amount } } }`)
+	data := json.RawMessage(`{"users": [{"id": 1, "payments": "cus_a"}]}`)
+
+	if _, err := Stitch(context.Background(), qc, data, Resolvers{}); err == nil {
+		t.Error("want an error without a resolver")
+	}
+
+	failed := errors.New("failed")
+	res := Resolvers{"payments": ResolverFunc(func(ctx context.Context, key json.RawMessage) (json.RawMessage, error) {
+		return nil, failed
+	})}
+	if _, err := Stitch(context.Background(), qc, data, res); !errors.Is(err, failed) {
+		t.Errorf("got error %v", err)
+	}
+	if _, err := Stitch(context.Background(), qc, json.RawMessage(`{`), res); err == nil {
+		t.Error("want an error decoding the result")
+	}
+
+	// remote tables are selected in their database table
+	s := paymentSchema(t)
+	for _, query := range []string{
+		`{ payments { amount } }`,
+		`{ users { payments(limit: 1) { amount } } }`,
+		`{ users { payments { amount users { id } } } }`,
+	} {
+		if _, err := qcode.NewCompiler(s).Compile([]byte(query), ""); err == nil {
+			t.Errorf("%s: want an error", query)
+		}
+	}
+}
+
+func TestRemoteTableErrors(t *testing.T) {
+	for _, rt := range []schema.RemoteTable{
+		{Name: "payments", Table: "users"},
+		{Name: "payments", Table: "missing", Column: "stripe_id"},
+		{Name: "payments", Table: "users", Column: "missing"},
+		{Name: "users", Table: "users", Column: "stripe_id"},
+	} {
+		_, err := schema.NewTestSchema().
+			Table("users", "id pk", "stripe_id text").
+			BuildSchema(schema.WithRemoteTables(rt))
+		if err == nil {
+			t.Errorf("%+v: want an error", rt)
+		}
+	}
+}
+
+func TestHTTPResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("Authorization") != "Bearer k":
+			http.Error(w, "no key", http.StatusUnauthorized)
+		case r.URL.Path == "/customers/cus a/payments":
+			w.Write([]byte(`{"data": [{"amount": 10}], "has_more": false}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := NewHTTPResolver(srv.URL+"/customers/$id/payments", "data")
+	r.Header = http.Header{"Authorization": {"Bearer k"}}
+
+	got, err := r.Resolve(context.Background(), json.RawMessage(`"cus a"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `[{"amount": 10}]` {
+		t.Errorf("got %s", got)
+	}
+
+	if _, err := r.Resolve(context.Background(), json.RawMessage(`12`)); err == nil {
+		t.Error("want an error for a missing customer")
+	}
+	r.Header = nil
+	if _, err := r.Resolve(context.Background(), json.RawMessage(`"cus a"`)); err == nil {
+		t.Error("want an error without the key")
+	}
+}
+
+func TestPick(t *testing.T) {
+	for _, tt := range []struct {
+		doc  string
+		path []string
+		want string
+	}{
+		{`[1]`, nil, `[1]`},
+		{`{"data": {"rows": [1]}}`, []string{"data", "rows"}, `[1]`},
+		{`{"data": {}}`, []string{"data", "rows"}, `null`},
+	} {
+		got, err := pick([]byte(tt.doc), tt.path)
+		if err != nil || string(got) != tt.want {
+			t.Errorf("pick(%s, %v) = %s, %v, want %s", tt.doc, tt.path, got, err, tt.want)
+		}
+	}
+	if _, err := pick([]byte(`[1]`), []string{"data"}); err == nil {
+		t.Error("want an error picking a key of a list")
+	}
+}
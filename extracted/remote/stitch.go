@@ -0,0 +1,221 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// DefaultConcurrency is the number of resolver calls Stitch makes at the
+// same time
+const DefaultConcurrency = 8
+
+// Resolvers maps the names of remote tables to their resolvers
+type Resolvers map[string]Resolver
+
+// call is a resolver call for a key of a remote table
+type call struct {
+	sel *qcode.Select
+	key json.RawMessage
+	res interface{}
+}
+
+// slot is a place in the result holding the key of remote rows
+type slot struct {
+	obj  map[string]interface{}
+	sel  *qcode.Select
+	call int
+}
+
+// Stitch replaces the keys of remote rows in the result of a query with
+// the rows fetched from the resolvers of their tables, the rows keep the
+// fields selected by the query. A key is fetched once per table
+func Stitch(ctx context.Context, qc *qcode.QCode, data json.RawMessage, res Resolvers) (json.RawMessage, error) {
+	if !hasRemote(qc) {
+		return data, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var root map[string]interface{}
+	if err := d.Decode(&root); err != nil {
+		return nil, fmt.Errorf("remote: decoding result: %w", err)
+	}
+
+	st := &stitcher{qc: qc, calls: make(map[string]int)}
+	for _, id := range qc.Roots {
+		sel := &qc.Selects[id]
+		if err := st.walk(sel, root[sel.FieldName]); err != nil {
+			return nil, err
+		}
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(DefaultConcurrency)
+
+	// the calls of the tables of batch resolvers by table
+	batches := make(map[string][]int)
+
+	for i := range st.list {
+		c := &st.list[i]
+		r, ok := res[c.sel.Ti.Name]
+		if !ok {
+			return nil, fmt.Errorf("remote: no resolver for table %s", c.sel.Ti.Name)
+		}
+		if _, ok := r.(BatchResolver); ok {
+			batches[c.sel.Ti.Name] = append(batches[c.sel.Ti.Name], i)
+			continue
+		}
+		g.Go(func() error {
+			v, err := r.Resolve(gctx, c.key)
+			if err != nil {
+				return fmt.Errorf("remote: %s: %w", c.sel.Ti.Name, err)
+			}
+			return c.decode(v)
+		})
+	}
+
+	for name, ids := range batches {
+		br := res[name].(BatchResolver)
+		g.Go(func() error {
+			keys := make([]json.RawMessage, len(ids))
+			for i, id := range ids {
+				keys[i] = st.list[id].key
+			}
+			vals, err := br.ResolveBatch(gctx, keys)
+			if err != nil {
+				return fmt.Errorf("remote: %s: %w", name, err)
+			}
+			if len(vals) != len(keys) {
+				return fmt.Errorf("remote: %s: %d results for %d keys", name, len(vals), len(keys))
+			}
+			for i, id := range ids {
+				if err := st.list[id].decode(vals[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range st.slots {
+		s.obj[s.sel.FieldName] = rows(s.sel, st.list[s.call].res)
+	}
+	return json.Marshal(root)
+}
+
+// decode sets the rows fetched for a call, an empty value is no rows
+func (c *call) decode(v json.RawMessage) error {
+	if len(v) == 0 {
+		return nil
+	}
+	d := json.NewDecoder(bytes.NewReader(v))
+	d.UseNumber()
+	if err := d.Decode(&c.res); err != nil {
+		return fmt.Errorf("remote: %s: decoding rows: %w", c.sel.Ti.Name, err)
+	}
+	return nil
+}
+
+// hasRemote returns true when an operation selects a remote table
+func hasRemote(qc *qcode.QCode) bool {
+	for i := range qc.Selects {
+		if qc.Selects[i].Ti.Type == "remote" {
+			return true
+		}
+	}
+	return false
+}
+
+// stitcher collects the keys of the remote rows of a result
+type stitcher struct {
+	qc    *qcode.QCode
+	list  []call
+	calls map[string]int // index of the call by table and key
+	slots []slot
+}
+
+// walk collects the keys of the remote children of the rows of a
+// selection, a value is a row, a list of rows or null
+func (st *stitcher) walk(sel *qcode.Select, v interface{}) error {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if err := st.walk(sel, item); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case map[string]interface{}:
+		for _, id := range sel.Children {
+			child := &st.qc.Selects[id]
+			cv, ok := v[child.FieldName]
+			if !ok || cv == nil {
+				continue
+			}
+			if child.Ti.Type != "remote" {
+				if err := st.walk(child, cv); err != nil {
+					return err
+				}
+				continue
+			}
+
+			key, err := json.Marshal(cv)
+			if err != nil {
+				return err
+			}
+			k := child.Ti.Name + ":" + string(key)
+			n, ok := st.calls[k]
+			if !ok {
+				n = len(st.list)
+				st.calls[k] = n
+				st.list = append(st.list, call{sel: child, key: key})
+			}
+			st.slots = append(st.slots, slot{obj: v, sel: child, call: n})
+		}
+	}
+	return nil
+}
+
+// rows returns the fetched rows in the shape the resolver returned them,
+// a list of rows or a single row
+func rows(sel *qcode.Select, v interface{}) interface{} {
+	list, ok := v.([]interface{})
+	if !ok {
+		return row(sel, v)
+	}
+	out := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		out = append(out, row(sel, item))
+	}
+	return out
+}
+
+// row returns the selected fields of a fetched row
+func row(sel *qcode.Select, v interface{}) interface{} {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	r := make(map[string]interface{}, len(sel.Fields))
+	for _, f := range sel.Fields {
+		switch f.Type {
+		case qcode.FieldTypename:
+			r[f.Name] = sel.Ti.Name
+		case qcode.FieldCol:
+			r[f.Name] = obj[f.Col.Name]
+		}
+	}
+	return r
+}
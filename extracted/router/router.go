@@ -0,0 +1,196 @@
+// Package router sends the read queries of the execution layer to
+// replica pools and mutations to the primary, replicas failing their
+// health check are skipped until they pass it again
+package router
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultCheckInterval is how often replicas are checked unless the
+// router is configured with WithHealthCheck
+const DefaultCheckInterval = 10 * time.Second
+
+// Option configures a Router
+type Option func(*Router)
+
+// WithReplicas adds replica pools the read queries are spread over
+func WithReplicas(qs ...schema.Querier) Option {
+	return func(r *Router) {
+		for _, q := range qs {
+			rp := &replica{q: q}
+			rp.healthy.Store(true)
+			r.replicas = append(r.replicas, rp)
+		}
+	}
+}
+
+// WithHealthCheck sets how often replicas are checked and how long a
+// check waits, a zero interval turns checking off
+func WithHealthCheck(interval, timeout time.Duration) Option {
+	return func(r *Router) {
+		r.interval = interval
+		r.timeout = timeout
+	}
+}
+
+// WithCheckQuery sets the query of the health check, a replica is
+// healthy when it returns a row. It is SELECT 1 unless set
+func WithCheckQuery(query string) Option {
+	return func(r *Router) {
+		r.check = query
+	}
+}
+
+// Router is a Querier running each query on the primary or on one of
+// the healthy replicas in turn. Queries with a context from WithPrimary
+// go to the primary, the execution layer marks its mutations so
+type Router struct {
+	primary  schema.Querier
+	replicas []*replica
+	next     atomic.Uint32
+
+	interval time.Duration
+	timeout  time.Duration
+	check    string
+
+	stop chan struct{}
+	once sync.Once
+}
+
+type replica struct {
+	q       schema.Querier
+	healthy atomic.Bool
+}
+
+// New returns a router for a primary, the replicas are checked in the
+// background until Close
+func New(primary schema.Querier, opts ...Option) *Router {
+	r := &Router{
+		primary:  primary,
+		interval: DefaultCheckInterval,
+		timeout:  time.Second,
+		check:    "SELECT 1",
+		stop:     make(chan struct{}),
+	}
+	for _, fn := range opts {
+		fn(r)
+	}
+
+	if len(r.replicas) != 0 && r.interval > 0 {
+		go r.run()
+	}
+	return r
+}
+
+type primaryKey struct{}
+
+// WithPrimary returns a context whose queries go to the primary, for
+// mutations and for reads that must see a write just made
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// IsPrimary returns true if the queries of the context go to the primary
+func IsPrimary(ctx context.Context) bool {
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// Query runs a query on the pool picked for the context
+func (r *Router) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	return r.Pick(ctx).Query(ctx, query, args...)
+}
+
+// QueryRow runs a query returning a single row on the pool picked for
+// the context
+func (r *Router) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	return r.Pick(ctx).QueryRow(ctx, query, args...)
+}
+
+// Pick returns the primary for a context from WithPrimary and the next
+// healthy replica otherwise, the primary when none is healthy
+func (r *Router) Pick(ctx context.Context) schema.Querier {
+	if len(r.replicas) == 0 || IsPrimary(ctx) {
+		return r.primary
+	}
+
+	n := len(r.replicas)
+	start := int(r.next.Add(1))
+	for i := 0; i < n; i++ {
+		rp := r.replicas[(start+i)%n]
+		if rp.healthy.Load() {
+			return rp.q
+		}
+	}
+	return r.primary
+}
+
+// Healthy returns the number of replicas passing their health check
+func (r *Router) Healthy() int {
+	n := 0
+	for _, rp := range r.replicas {
+		if rp.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// Check runs the health check of every replica now
+func (r *Router) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, rp := range r.replicas {
+		wg.Add(1)
+		go func(rp *replica) {
+			defer wg.Done()
+			rp.healthy.Store(r.ping(ctx, rp.q) == nil)
+		}(rp)
+	}
+	wg.Wait()
+}
+
+func (r *Router) ping(ctx context.Context, q schema.Querier) error {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	var v interface{}
+	return q.QueryRow(ctx, r.check).Scan(&v)
+}
+
+// run checks the replicas on the interval until Close
+func (r *Router) run() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-r.stop
+		cancel()
+	}()
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			r.Check(ctx)
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Close stops checking the replicas, the pools are left open
+func (r *Router) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	return nil
+}
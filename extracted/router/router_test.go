@@ -0,0 +1,139 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// pool is a Querier counting the queries it ran, its health check
+// fails while down is set
+type pool struct {
+	name string
+	down atomic.Bool
+	n    atomic.Int32
+}
+
+func (p *pool) Query(ctx context.Context, query string, args ...interface{}) (schema.Rows, error) {
+	p.n.Add(1)
+	return nil, nil
+}
+
+func (p *pool) QueryRow(ctx context.Context, query string, args ...interface{}) schema.Row {
+	p.n.Add(1)
+	return row{p}
+}
+
+type row struct{ p *pool }
+
+func (r row) Scan(dest ...interface{}) error {
+	if r.p.down.Load() {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func name(q schema.Querier) string {
+	return q.(*pool).name
+}
+
+func TestPick(t *testing.T) {
+	primary, r1, r2 := &pool{name: "primary"}, &pool{name: "r1"}, &pool{name: "r2"}
+	r := New(primary, WithReplicas(r1, r2), WithHealthCheck(0, 0))
+	defer r.Close()
+	ctx := context.Background()
+
+	// reads are spread over the replicas in turn
+	got := map[string]int{}
+	for i := 0; i < 4; i++ {
+		got[name(r.Pick(ctx))]++
+	}
+	if got["r1"] != 2 || got["r2"] != 2 {
+		t.Errorf("got picks %v", got)
+	}
+	if p := name(r.Pick(WithPrimary(ctx))); p != "primary" {
+		t.Errorf("got %s with WithPrimary", p)
+	}
+
+	// an unhealthy replica is skipped until it passes its check again
+	r2.down.Store(true)
+	r.Check(ctx)
+	if r.Healthy() != 1 {
+		t.Errorf("got %d healthy replicas, want 1", r.Healthy())
+	}
+	for i := 0; i < 3; i++ {
+		if p := name(r.Pick(ctx)); p != "r1" {
+			t.Errorf("got %s, want r1", p)
+		}
+	}
+
+	r1.down.Store(true)
+	r.Check(ctx)
+	if p := name(r.Pick(ctx)); p != "primary" {
+		t.Errorf("got %s without healthy replicas, want primary", p)
+	}
+
+	r2.down.Store(false)
+	r.Check(ctx)
+	if p := name(r.Pick(ctx)); p != "r2" {
+		t.Errorf("got %s, want r2", p)
+	}
+
+	// without replicas everything goes to the primary
+	r0 := New(primary)
+	defer r0.Close()
+	if p := name(r0.Pick(ctx)); p != "primary" {
+		t.Errorf("got %s, want primary", p)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	primary, replica := &pool{name: "primary"}, &pool{name: "replica"}
+	r := New(primary, WithReplicas(replica), WithHealthCheck(0, 0))
+	defer r.Close()
+	ctx := context.Background()
+
+	if _, err := r.Query(ctx, "SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.QueryRow(WithPrimary(ctx), "SELECT 1").Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if replica.n.Load() != 1 || primary.n.Load() != 1 {
+		t.Errorf("got %d queries on the replica and %d on the primary", replica.n.Load(), primary.n.Load())
+	}
+	if IsPrimary(ctx) || !IsPrimary(WithPrimary(ctx)) {
+		t.Error("IsPrimary does not match WithPrimary")
+	}
+}
+
+// the replicas are checked in the background until Close
+func TestHealthCheck(t *testing.T) {
+	replica := &pool{name: "replica"}
+	replica.down.Store(true)
+	r := New(&pool{name: "primary"}, WithReplicas(replica), WithHealthCheck(time.Millisecond, time.Second))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for r.Healthy() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("replica not checked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	n := replica.n.Load()
+	time.Sleep(10 * time.Millisecond)
+	if replica.n.Load() > n+1 {
+		t.Error("replica checked after Close")
+	}
+}
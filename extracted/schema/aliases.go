@@ -0,0 +1,144 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Alias is another name for a table, a relationship or a column so the
+// API names can differ from the database names
+//
+//	{Name: "people", Table: "users"}                          // table
+//	{Name: "author", Table: "users", Via: "comments.user_id"} // relationship
+//	{Name: "mail", Table: "users", Column: "email"}           // column
+//
+// A relationship alias finds the table only through the foreign key
+// column named by Via, eg. FindPath("comments", "author", "") joins on
+// comments.user_id even when comments has other keys to users
+type Alias struct {
+	Name   string
+	Table  string // table name, can be schema qualified
+	Via    string // foreign key column eg. "comments.user_id"
+	Column string // column of the table
+}
+
+// WithAliases adds table, relationship and column aliases
+func WithAliases(aliases ...Alias) Option {
+	return func(o *schemaOptions) {
+		o.aliases = append(o.aliases, aliases...)
+	}
+}
+
+// addColumnAliases adds the column aliases of the WithAliases option,
+// they are added before the relationships so every copy of the tables
+// has them
+func (s *DBSchema) addColumnAliases(aliases []Alias) error {
+	for _, a := range aliases {
+		if a.Column == "" {
+			continue
+		}
+		nodeID, err := s.aliasTable(a)
+		if err != nil {
+			return err
+		}
+		if err := s.addColumnAlias(nodeID, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addAliasOpts adds the table and relationship aliases of the
+// WithAliases option, it runs once the relationships are in the graph
+func (s *DBSchema) addAliasOpts(aliases []Alias) error {
+	for _, a := range aliases {
+		if a.Column != "" {
+			continue
+		}
+		nodeID, err := s.aliasTable(a)
+		if err != nil {
+			return err
+		}
+		t := s.tables[nodeID]
+
+		if a.Via != "" {
+			if err := s.addRelAlias(t, nodeID, a); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.addAliases(t, nodeID, []string{a.Name})
+		for _, ei := range s.edgesIndex[t.Name] {
+			if ei.nodeID == nodeID {
+				s.addEdgeInfo(a.Name, ei)
+			}
+		}
+	}
+	return nil
+}
+
+// aliasTable returns the node of the table of an alias
+func (s *DBSchema) aliasTable(a Alias) (int32, error) {
+	if a.Name == "" {
+		return 0, fmt.Errorf("alias: name not set for table %s", a.Table)
+	}
+	schema, name := s.splitTableName(a.Table)
+	v, ok := s.tindex[(schema + ":" + name)]
+	if !ok {
+		return 0, fmt.Errorf("alias %s: table not found: %s", a.Name, a.Table)
+	}
+	return v.nodeID, nil
+}
+
+// addRelAlias adds the alias of the table reached through a foreign key
+// column, only the edge of that column is indexed under the alias
+func (s *DBSchema) addRelAlias(t DBTable, nodeID int32, a Alias) error {
+	i := strings.LastIndexByte(a.Via, '.')
+	if i == -1 {
+		return fmt.Errorf("alias %s: via must be table.column: %s", a.Name, a.Via)
+	}
+	vs, vn := s.splitTableName(a.Via[:i])
+	col := a.Via[i+1:]
+
+	var ids []int32
+	for id, e := range s.allEdges {
+		if rt := s.tables[e.To]; e.From == nodeID && rt.Schema == vs && rt.Name == vn &&
+			e.R.Name == col && e.CName == col {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("alias %s: no relationship from %s to %s", a.Name, a.Via, t.Name)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	s.addAliases(t, nodeID, []string{a.Name})
+	for _, id := range ids {
+		s.addEdgeInfo(a.Name, edgeInfo{nodeID: nodeID, edgeIDs: []int32{id}})
+	}
+	return nil
+}
+
+// addColumnAlias adds another name for a column of a table, the index of
+// the table is copied as it is shared with the DBInfo
+func (s *DBSchema) addColumnAlias(nodeID int32, a Alias) error {
+	t := &s.tables[nodeID]
+
+	ci, ok := t.colMap[a.Column]
+	if !ok {
+		return fmt.Errorf("alias %s: column not found: %s.%s", a.Name, t.Name, a.Column)
+	}
+	if _, ok := t.colMap[a.Name]; ok {
+		return fmt.Errorf("alias %s: column already exists in %s", a.Name, t.Name)
+	}
+
+	cm := make(map[string]int, len(t.colMap)+1)
+	for k, v := range t.colMap {
+		cm[k] = v
+	}
+	cm[a.Name] = ci
+	t.colMap = cm
+	return nil
+}
@@ -0,0 +1,62 @@
+package schema
+
+import "testing"
+
+func TestAliases(t *testing.T) {
+	s := marketTestSchema(t, WithAmbiguousPathErrors(), WithAliases(
+		Alias{Name: "people", Table: "users"},
+		Alias{Name: "buyer", Table: "users", Via: "orders.buyer_id"},
+		Alias{Name: "seller", Table: "users", Via: "orders.seller_id"},
+		Alias{Name: "full_name", Table: "users", Column: "name"},
+	))
+
+	ti, err := s.Find("", "people")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Name != "users" {
+		t.Errorf("got table %s", ti.Name)
+	}
+	c, err := ti.GetColumn("full_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name != "name" {
+		t.Errorf("got column %s", c.Name)
+	}
+
+	// the relationship aliases pick their foreign key
+	for to, want := range map[string]string{"buyer": "buyer_id", "seller": "seller_id"} {
+		path, err := s.FindPath("orders", to, "")
+		if err != nil {
+			t.Fatalf("%s: %v", to, err)
+		}
+		if len(path) != 1 || path[0].LC.Name != want || path[0].RT.Name != "users" {
+			t.Errorf("%s: got path %s", to, pathString(path))
+		}
+	}
+
+	// the table alias has both keys
+	if _, err := s.FindPath("orders", "people", ""); err == nil {
+		t.Error("want an ambiguous path to people")
+	}
+}
+
+func TestAliasErrors(t *testing.T) {
+	for _, a := range []Alias{
+		{Table: "users"},
+		{Name: "people", Table: "missing"},
+		{Name: "mail", Table: "users", Column: "email"},
+		{Name: "id", Table: "users", Column: "name"},
+		{Name: "buyer", Table: "users", Via: "buyer_id"},
+		{Name: "buyer", Table: "users", Via: "orders.id"},
+	} {
+		if _, err := NewTestSchema().
+			Table("users", "id pk", "name").
+			Table("orders", "id pk", "buyer_id notnull").
+			FK("orders.buyer_id", "users.id").
+			BuildSchema(WithAliases(a)); err == nil {
+			t.Errorf("%+v: want an error", a)
+		}
+	}
+}
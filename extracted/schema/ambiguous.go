@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrAmbiguousPath is returned by FindPath when more than one path with
+// the same number of joins and weight exists between two tables. Columns
+// holds for each path the foreign key column where it differs from the
+// others eg. orders.buyer_id and orders.seller_id
+type ErrAmbiguousPath struct {
+	From, To string
+	Paths    [][]TPath
+	Columns  []string
+}
+
+// Error returns the error message listing the disambiguating columns
+func (e *ErrAmbiguousPath) Error() string {
+	return fmt.Sprintf("ambiguous path from %s to %s: %d paths found via %s",
+		e.From, e.To, len(e.Paths), strings.Join(e.Columns, ", "))
+}
+
+// Is makes errors.Is match any ErrAmbiguousPath
+func (e *ErrAmbiguousPath) Is(target error) bool {
+	_, ok := target.(*ErrAmbiguousPath)
+	return ok
+}
+
+// checkAmbiguous returns an ErrAmbiguousPath when the picked path is not
+// the only one of its length and weight between the two tables
+func (s *DBSchema) checkAmbiguous(from, to, through string, res graphResult) error {
+	all := s.allPaths([]edgeInfo{res.from}, []edgeInfo{res.to}, len(res.edges))
+
+	if through != "" {
+		all = s.pathsThrough(all, through)
+	}
+	all = s.pathsEndingAt(all, res.to)
+
+	if len(all) < 2 {
+		return nil
+	}
+
+	best := all[0]
+	var cands []foundPath
+	for _, fp := range all {
+		if len(fp.edges) == len(best.edges) && fp.weight == best.weight {
+			cands = append(cands, fp)
+		}
+	}
+
+	if len(cands) < 2 {
+		return nil
+	}
+
+	e := &ErrAmbiguousPath{From: from, To: to}
+	for _, fp := range cands {
+		e.Paths = append(e.Paths, fp.path)
+		e.Columns = append(e.Columns, s.diffColumn(fp, cands))
+	}
+	return e
+}
+
+// pathsThrough returns the paths that pass through the table
+func (s *DBSchema) pathsThrough(all []foundPath, through string) []foundPath {
+	v, ok := s.findNode(through)
+	if !ok {
+		return nil
+	}
+
+	var paths []foundPath
+	for _, fp := range all {
+		for _, eid := range fp.edges {
+			e := s.allEdges[eid]
+			if e.From == v.nodeID || e.To == v.nodeID {
+				paths = append(paths, fp)
+				break
+			}
+		}
+	}
+	return paths
+}
+
+// pathsEndingAt returns the paths whose last join is the reverse of an
+// edge of the end of the path, all the paths when there are none
+func (s *DBSchema) pathsEndingAt(all []foundPath, to edgeInfo) []foundPath {
+	var paths []foundPath
+	for _, fp := range all {
+		eid := fp.edges[len(fp.edges)-1]
+		e := s.allEdges[eid]
+		for _, v := range s.relationshipGraph.GetEdges(e.From, e.To) {
+			if v.ID == eid && s.endsAt(v, to) {
+				paths = append(paths, fp)
+				break
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return all
+	}
+	return paths
+}
+
+// diffColumn returns the foreign key column of the first join where
+// the path differs from any of the other paths
+func (s *DBSchema) diffColumn(fp foundPath, all []foundPath) string {
+	for i, eid := range fp.edges {
+		e := s.allEdges[eid]
+
+		for _, o := range all {
+			oe := s.allEdges[o.edges[i]]
+			if oe.From != e.From || oe.To != e.To || oe.CName != e.CName {
+				return fkeyColumnName(s.getEdge(eid))
+			}
+		}
+	}
+	return ""
+}
+
+// fkeyColumnName returns the table qualified name of the foreign
+// key column of an edge
+func fkeyColumnName(e TEdge) string {
+	switch {
+	case e.Through.Ti.Name != "":
+		return e.Through.Ti.Name + "." + e.CName
+	case e.L.Name == e.CName:
+		return e.LT.Name + "." + e.CName
+	default:
+		return e.RT.Name + "." + e.CName
+	}
+}
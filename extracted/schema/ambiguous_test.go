@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func marketTestSchema(t *testing.T, opts ...Option) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("users", "id pk", "name").
+		Table("orders", "id pk", "buyer_id notnull", "seller_id notnull").
+		FK("orders.buyer_id", "users.id").
+		FK("orders.seller_id", "users.id").
+		BuildSchema(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestAmbiguousPath(t *testing.T) {
+	s := marketTestSchema(t, WithAmbiguousPathErrors())
+
+	_, err := s.FindPath("orders", "users", "")
+	var ae *ErrAmbiguousPath
+	if !errors.As(err, &ae) {
+		t.Fatalf("got error %v", err)
+	}
+	if !errors.Is(err, &ErrAmbiguousPath{}) {
+		t.Error("errors.Is does not match ErrAmbiguousPath")
+	}
+	if ae.From != "orders" || ae.To != "users" || len(ae.Paths) != 2 {
+		t.Errorf("got error %+v", ae)
+	}
+	if !reflect.DeepEqual(ae.Columns, []string{"orders.buyer_id", "orders.seller_id"}) {
+		t.Errorf("got columns %v", ae.Columns)
+	}
+
+	// blocking one of the columns leaves one path
+	s = marketTestSchema(t, WithAmbiguousPathErrors(), WithBlockedRels("orders.seller_id"))
+	path, err := s.FindPath("orders", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LC.Name != "buyer_id" {
+		t.Errorf("got path %s", pathString(path))
+	}
+
+	// without the option one of the paths is picked
+	s = marketTestSchema(t)
+	if _, err := s.FindPath("orders", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,271 @@
+package schema
+
+import (
+	"sort"
+)
+
+// Analysis reports the shape of the relationship graph of a schema
+type Analysis struct {
+	// Cycles are the groups of tables that reference each other through
+	// foreign keys, directly or through other tables of the group. A
+	// table with a foreign key to itself is a group of one
+	Cycles [][]DBTable
+
+	// Components are the groups of tables connected by relationships,
+	// largest first. A schema with more than one is disconnected
+	Components [][]DBTable
+
+	// Orphans are the tables without a relationship to another table
+	Orphans []DBTable
+}
+
+// Analyze returns the foreign key cycles, connected components and
+// orphan tables of the schema, blocked and removed tables are left out
+func (s *DBSchema) Analyze() Analysis {
+	s = s.current()
+
+	var a Analysis
+	nodes := s.nodes()
+
+	a.Cycles = s.fkeyCycles(nodes)
+
+	seen := make(map[int32]bool, len(nodes))
+	for _, n := range nodes {
+		if seen[n] {
+			continue
+		}
+		comp := s.component(n, seen)
+		if len(comp) == 1 {
+			a.Orphans = append(a.Orphans, comp[0])
+			continue
+		}
+		a.Components = append(a.Components, comp)
+	}
+
+	sort.SliceStable(a.Components, func(i, j int) bool {
+		return len(a.Components[i]) > len(a.Components[j])
+	})
+	sortTables(a.Orphans)
+	return a
+}
+
+// component returns the tables connected to a node, the nodes found are
+// marked as seen
+func (s *DBSchema) component(n int32, seen map[int32]bool) []DBTable {
+	var tables []DBTable
+	queue := []int32{n}
+	seen[n] = true
+
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		tables = append(tables, s.tables[id])
+
+		for _, m := range s.relationshipGraph.Connections(id) {
+			if _, ok := s.removed[m]; ok || seen[m] || s.tables[m].Blocked {
+				continue
+			}
+			seen[m] = true
+			queue = append(queue, m)
+		}
+	}
+	sortTables(tables)
+	return tables
+}
+
+// TopoSort returns the tables in foreign key order, a table comes after
+// the tables it references. The tables of a cycle cannot be ordered and
+// are returned together as a group, the other groups hold one table. A
+// table with a foreign key to itself is a group of one. Rows can be
+// inserted group by group and tables truncated in reverse, blocked and
+// removed tables are left out. Groups that do not depend on each other
+// are ordered by name so the order is the same for the same schema
+//
+//	for _, group := range dbSchema.TopoSort() {
+//		for _, t := range group { ... }
+//	}
+func (s *DBSchema) TopoSort() [][]DBTable {
+	s = s.current()
+
+	nodes := s.nodes()
+	refs := s.fkeyRefs(nodes)
+	groups := fkeyGroups(nodes, refs)
+
+	of := make(map[int32]int, len(nodes))
+	tables := make([][]DBTable, len(groups))
+	for i, g := range groups {
+		for _, n := range g {
+			of[n] = i
+			tables[i] = append(tables[i], s.tables[n])
+		}
+		sortTables(tables[i])
+	}
+
+	// deps are the number of groups a group references that are not
+	// placed, users are the groups referencing a group
+	deps := make([]int, len(groups))
+	users := make([][]int, len(groups))
+	for i, g := range groups {
+		seen := make(map[int]bool)
+		for _, n := range g {
+			for _, m := range refs[n] {
+				if j := of[m]; j != i && !seen[j] {
+					seen[j] = true
+					deps[i]++
+					users[j] = append(users[j], i)
+				}
+			}
+		}
+	}
+
+	var ready []int
+	for i := range groups {
+		if deps[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	order := make([][]DBTable, 0, len(groups))
+	for len(ready) != 0 {
+		k := 0
+		for j := range ready {
+			if tableLess(tables[ready[j]][0], tables[ready[k]][0]) {
+				k = j
+			}
+		}
+		i := ready[k]
+		ready = append(ready[:k], ready[k+1:]...)
+		order = append(order, tables[i])
+
+		for _, j := range users[i] {
+			if deps[j]--; deps[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	return order
+}
+
+// nodes returns the nodes of the tables that are not blocked or removed
+func (s *DBSchema) nodes() []int32 {
+	nodes := make([]int32, 0, len(s.tables))
+	for i, t := range s.tables {
+		if _, ok := s.removed[int32(i)]; ok || t.Blocked {
+			continue
+		}
+		nodes = append(nodes, int32(i))
+	}
+	return nodes
+}
+
+// fkeyCycles returns the strongly connected components of the graph of
+// foreign keys that are cycles
+func (s *DBSchema) fkeyCycles(nodes []int32) [][]DBTable {
+	refs := s.fkeyRefs(nodes)
+
+	var cycles [][]DBTable
+	for _, g := range fkeyGroups(nodes, refs) {
+		if len(g) == 1 && !selfRef(refs, g[0]) {
+			continue
+		}
+		group := make([]DBTable, len(g))
+		for i, n := range g {
+			group[i] = s.tables[n]
+		}
+		sortTables(group)
+		cycles = append(cycles, group)
+	}
+
+	sort.SliceStable(cycles, func(i, j int) bool {
+		return tableLess(cycles[i][0], cycles[j][0])
+	})
+	return cycles
+}
+
+// selfRef returns true if a node references itself
+func selfRef(refs map[int32][]int32, n int32) bool {
+	for _, m := range refs[n] {
+		if m == n {
+			return true
+		}
+	}
+	return false
+}
+
+// fkeyRefs returns the nodes each node references with foreign keys
+func (s *DBSchema) fkeyRefs(nodes []int32) map[int32][]int32 {
+	in := make(map[int32]bool, len(nodes))
+	for _, n := range nodes {
+		in[n] = true
+	}
+
+	refs := make(map[int32][]int32, len(nodes))
+	for _, n := range nodes {
+		t := s.tables[n]
+		for _, c := range t.Columns {
+			if c.FKeyTable == "" || c.FKeyCol == "" {
+				continue
+			}
+			sn := c.FKeySchema
+			if sn == "" {
+				sn = t.Schema
+			}
+			if v, ok := s.tindex[(sn + ":" + c.FKeyTable)]; ok && in[v.nodeID] {
+				refs[n] = append(refs[n], v.nodeID)
+			}
+		}
+	}
+	return refs
+}
+
+// fkeyGroups returns the strongly connected components of the graph of
+// foreign keys, a component comes after the components it references
+func fkeyGroups(nodes []int32, refs map[int32][]int32) [][]int32 {
+	// Tarjan's algorithm
+	var (
+		groups  [][]int32
+		stack   []int32
+		next    int
+		index   = make(map[int32]int, len(nodes))
+		low     = make(map[int32]int, len(nodes))
+		onStack = make(map[int32]bool, len(nodes))
+		visit   func(n int32)
+	)
+	visit = func(n int32) {
+		index[n], low[n] = next, next
+		next++
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, m := range refs[n] {
+			if _, ok := index[m]; !ok {
+				visit(m)
+				low[n] = min(low[n], low[m])
+			} else if onStack[m] {
+				low[n] = min(low[n], index[m])
+			}
+		}
+		if low[n] != index[n] {
+			return
+		}
+
+		var group []int32
+		for {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[m] = false
+			group = append(group, m)
+			if m == n {
+				break
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	for _, n := range nodes {
+		if _, ok := index[n]; !ok {
+			visit(n)
+		}
+	}
+	return groups
+}
@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+// groupNames returns the names of groups of tables, eg. "a b | c"
+func groupNames(groups [][]DBTable) string {
+	var gs []string
+	for _, g := range groups {
+		gs = append(gs, names(g))
+	}
+	return strings.Join(gs, " | ")
+}
+
+func TestAnalyze(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk", "manager_id", "team_id").
+		Table("teams", "id pk", "lead_id").
+		Table("posts", "id pk", "user_id notnull").
+		Table("products", "id pk").
+		Table("prices", "id pk", "product_id notnull").
+		Table("settings", "id pk").
+		FK("users.manager_id", "users.id").
+		FK("users.team_id", "teams.id").
+		FK("teams.lead_id", "users.id").
+		FK("posts.user_id", "users.id").
+		FK("prices.product_id", "products.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := s.Analyze()
+
+	// users and teams reference each other, users also references itself
+	if got := groupNames(a.Cycles); got != "teams users" {
+		t.Errorf("got cycles %q", got)
+	}
+	if got := groupNames(a.Components); got != "posts teams users | prices products" {
+		t.Errorf("got components %q", got)
+	}
+	if got := names(a.Orphans); got != "settings" {
+		t.Errorf("got orphans %q", got)
+	}
+}
+
+func TestAnalyzeSelfReference(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("categories", "id pk", "parent_id").
+		Table("items", "id pk", "category_id").
+		FK("categories.parent_id", "categories.id").
+		FK("items.category_id", "categories.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := s.Analyze()
+	if got := groupNames(a.Cycles); got != "categories" {
+		t.Errorf("got cycles %q", got)
+	}
+	if len(a.Components) != 1 || len(a.Orphans) != 0 {
+		t.Errorf("got components %q and orphans %q", groupNames(a.Components), names(a.Orphans))
+	}
+}
+
+func TestTopoSort(t *testing.T) {
+	ts := NewTestSchema().
+		Table("users", "id pk", "manager_id", "team_id").
+		Table("teams", "id pk", "lead_id").
+		Table("posts", "id pk", "user_id notnull").
+		Table("products", "id pk").
+		Table("prices", "id pk", "product_id notnull").
+		Table("settings", "id pk").
+		Table("secrets", "id pk").
+		FK("users.manager_id", "users.id").
+		FK("users.team_id", "teams.id").
+		FK("teams.lead_id", "users.id").
+		FK("posts.user_id", "users.id").
+		FK("prices.product_id", "products.id")
+	di, err := ts.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range di.Tables {
+		di.Tables[i].Blocked = di.Tables[i].Name == "secrets"
+	}
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the cycle of users and teams is a group placed before the posts
+	// referencing it
+	want := "products | prices | settings | teams users | posts"
+	if got := groupNames(s.TopoSort()); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if err := s.RemoveTable("prices"); err != nil {
+		t.Fatal(err)
+	}
+	if got := groupNames(s.TopoSort()); got != "products | settings | teams users | posts" {
+		t.Errorf("got %q without the prices", got)
+	}
+}
+
+// the order does not depend on the order the tables are added in, the
+// tables of the audit schema come first
+func TestTopoSortStable(t *testing.T) {
+	for _, reverse := range []bool{false, true} {
+		if got := groupNames(tieSchema(t, reverse).TopoSort()); got != "users | entries | users | invoices | orders | posts | threads | likes" {
+			t.Errorf("reverse %v: got %q", reverse, got)
+		}
+	}
+}
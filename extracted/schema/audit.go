@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+)
+
+// Audit is what an audit column records about the writes of a row
+type Audit string
+
+const (
+	AuditCreatedAt Audit = "created_at" // when the row was inserted
+	AuditUpdatedAt Audit = "updated_at" // when the row was last written
+	AuditCreatedBy Audit = "created_by" // the user who inserted the row
+	AuditUpdatedBy Audit = "updated_by" // the user who last wrote the row
+)
+
+// AuditColumns are the names of the audit columns of each kind, patterns
+// like "created_at" or "orders.placed_at" matched with path.Match. The
+// _at columns must be timestamps or dates
+type AuditColumns struct {
+	CreatedAt []string
+	UpdatedAt []string
+	CreatedBy []string
+	UpdatedBy []string
+}
+
+// DefaultAuditColumns are the audit columns of WithAuditColumns when it
+// is given none
+var DefaultAuditColumns = AuditColumns{
+	CreatedAt: []string{"created_at", "inserted_at", "created_on"},
+	UpdatedAt: []string{"updated_at", "modified_at", "updated_on"},
+	CreatedBy: []string{"created_by", "created_by_id", "creator_id"},
+	UpdatedBy: []string{"updated_by", "updated_by_id", "modified_by"},
+}
+
+// WithAuditColumns sets the Audit of the columns matching the patterns,
+// the zero AuditColumns uses DefaultAuditColumns. The compiler fills in
+// the audit columns a mutation does not set, see WithAuditUser
+func WithAuditColumns(ac AuditColumns) InfoOption {
+	return func(o *infoOptions) {
+		o.audit = &ac
+	}
+}
+
+// WithAuditUser names the variable holding the user of the session, the
+// compiler writes it to the created_by and updated_by columns. Like the
+// variables of filters it is listed in QCode.FilterVars
+func WithAuditUser(variable string) Option {
+	return func(o *schemaOptions) {
+		o.auditUser = variable
+	}
+}
+
+// AuditUser returns the variable of the user of the session, empty
+// unless WithAuditUser is used
+func (s *DBSchema) AuditUser() string {
+	return s.auditUser
+}
+
+// kinds returns the patterns of each kind of audit column
+func (ac AuditColumns) kinds() []struct {
+	audit    Audit
+	patterns []string
+} {
+	if ac.CreatedAt == nil && ac.UpdatedAt == nil && ac.CreatedBy == nil && ac.UpdatedBy == nil {
+		ac = DefaultAuditColumns
+	}
+	return []struct {
+		audit    Audit
+		patterns []string
+	}{
+		{AuditCreatedAt, ac.CreatedAt},
+		{AuditUpdatedAt, ac.UpdatedAt},
+		{AuditCreatedBy, ac.CreatedBy},
+		{AuditUpdatedBy, ac.UpdatedBy},
+	}
+}
+
+// check returns an error for an invalid pattern
+func (ac AuditColumns) check() error {
+	for _, k := range ac.kinds() {
+		for _, p := range k.patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return fmt.Errorf("invalid audit column pattern: %s", p)
+			}
+		}
+	}
+	return nil
+}
+
+// MarkAuditColumns sets the Audit of the columns of every table that
+// match the patterns and clears it on the others, a column matches the
+// first kind it fits. The zero AuditColumns uses DefaultAuditColumns
+func (di *DBInfo) MarkAuditColumns(ac AuditColumns) {
+	kinds := ac.kinds()
+
+	for i := range di.Tables {
+		t := &di.Tables[i]
+		for j := range t.Columns {
+			c := &t.Columns[j]
+			c.Audit = ""
+			if c.Generated != "" || c.Identity != "" || len(c.JSONPath) != 0 {
+				continue
+			}
+			for _, k := range kinds {
+				// the table of a column is matched like the schema of a table
+				if matchTable(k.patterns, c.Table, c.Name) && auditType(k.audit, *c) {
+					c.Audit = k.audit
+					break
+				}
+			}
+		}
+	}
+}
+
+// auditType returns true if a column can hold an audit value, a time
+// for the _at columns and anything for the user of the _by ones
+func auditType(a Audit, c DBColumn) bool {
+	switch a {
+	case AuditCreatedAt, AuditUpdatedAt:
+		switch logicalType(c) {
+		case TypeTime, TypeTimestamp, TypeDate:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// AuditColumn returns the column of a kind of audit of a table
+func (ti *DBTable) AuditColumn(a Audit) (DBColumn, bool) {
+	for _, c := range ti.Columns {
+		if c.Audit == a && !c.Blocked {
+			return c, true
+		}
+	}
+	return DBColumn{}, false
+}
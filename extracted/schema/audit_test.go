@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMarkAuditColumns(t *testing.T) {
+	di, err := NewTestSchema().
+		Table("users", "id pk", "created_at timestamptz", "updated_at timestamp", "created_by text", "modified_by bigint").
+		Table("orders", "id pk", "placed_at date", "created_at text", "updated_by_id bigint").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	di.MarkAuditColumns(AuditColumns{})
+	want := map[string]Audit{
+		"users.created_at":     AuditCreatedAt,
+		"users.updated_at":     AuditUpdatedAt,
+		"users.created_by":     AuditCreatedBy,
+		"users.modified_by":    AuditUpdatedBy,
+		"orders.updated_by_id": AuditUpdatedBy,
+		// an _at column that is not a time
+		"orders.created_at": "",
+		"orders.placed_at":  "",
+	}
+	checkAudit(t, di, want)
+
+	// the patterns can be of a table and the marks of others are cleared
+	di.MarkAuditColumns(AuditColumns{CreatedAt: []string{"orders.placed_at"}})
+	want = map[string]Audit{
+		"orders.placed_at": AuditCreatedAt,
+		"users.created_at": "",
+		"users.created_by": "",
+	}
+	checkAudit(t, di, want)
+
+	ti, err := di.GetTable("public", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, ok := ti.AuditColumn(AuditCreatedAt); !ok || c.Name != "placed_at" {
+		t.Errorf("got audit column %s, %v", c.Name, ok)
+	}
+	if _, ok := ti.AuditColumn(AuditUpdatedAt); ok {
+		t.Error("got an updated_at column")
+	}
+}
+
+func checkAudit(t *testing.T, di *DBInfo, want map[string]Audit) {
+	t.Helper()
+	for _, ti := range di.Tables {
+		for _, c := range ti.Columns {
+			if a, ok := want[ti.Name+"."+c.Name]; ok && c.Audit != a {
+				t.Errorf("%s.%s: got audit %q, want %q", ti.Name, c.Name, c.Audit, a)
+			}
+		}
+	}
+}
+
+func TestWithAuditColumns(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "users", "inserted_at", "datetime", true, false),
+		},
+	}}
+	di, err := GetDBInfoFrom(context.Background(), q, "mysql", nil, WithAuditColumns(AuditColumns{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := di.GetColumn("shop", "users", "inserted_at"); c.Audit != AuditCreatedAt {
+		t.Errorf("got audit %q", c.Audit)
+	}
+
+	_, err = GetDBInfoFrom(context.Background(), q, "mysql", nil, WithAuditColumns(AuditColumns{UpdatedAt: []string{"["}}))
+	if err == nil {
+		t.Error("want an error for an invalid pattern")
+	}
+
+	s, err := NewDBSchema(di, nil, WithAuditUser("user_id"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AuditUser() != "user_id" {
+		t.Errorf("got audit user %q", s.AuditUser())
+	}
+}
@@ -0,0 +1,55 @@
+package schema
+
+import "strings"
+
+// bigqueryTypes maps bigquery type names to the type names
+// used across the rest of the schema package
+var bigqueryTypes = map[string]string{
+	"int64":      "bigint",
+	"int":        "bigint",
+	"integer":    "bigint",
+	"smallint":   "bigint",
+	"bigint":     "bigint",
+	"tinyint":    "bigint",
+	"byteint":    "bigint",
+	"float64":    "double precision",
+	"float":      "double precision",
+	"numeric":    "numeric",
+	"decimal":    "numeric",
+	"bignumeric": "numeric",
+	"bigdecimal": "numeric",
+	"bool":       "boolean",
+	"boolean":    "boolean",
+	"string":     "text",
+	"bytes":      "bytea",
+	"date":       "date",
+	"datetime":   "timestamp without time zone",
+	"time":       "time without time zone",
+	"timestamp":  "timestamp with time zone",
+	"geography":  "geography",
+	"json":       "json",
+	"interval":   "interval",
+	"struct":     "json",
+	"range":      "text",
+}
+
+// bigqueryType returns the normalized name for a bigquery type, arrays
+// become arrays of the element type and structs become json
+func bigqueryType(t string) string {
+	t = strings.TrimSpace(t)
+	if e, ok := strings.CutPrefix(t, "ARRAY<"); ok && strings.HasSuffix(e, ">") {
+		return bigqueryType(e[:len(e)-1]) + "[]"
+	}
+
+	name := strings.ToLower(t)
+	if i := strings.IndexAny(name, "<("); i != -1 {
+		if name[:i] == "string" && name[i] == '(' {
+			return "character varying"
+		}
+		name = name[:i]
+	}
+	if v, ok := bigqueryTypes[name]; ok {
+		return v
+	}
+	return t
+}
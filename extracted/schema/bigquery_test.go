@@ -0,0 +1,91 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetDBInfoBigQuery(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		bigqueryInfo: {{0, "analytics", "shop-project"}},
+		bigqueryColumnsStmt: {
+			columnRow("analytics", "users", "id", "INT64", true, false),
+			columnRow("analytics", "users", "tags", "ARRAY<STRING>", false, false),
+			columnRow("analytics", "events", "id", "INT64", true, false),
+			columnRow("analytics", "events", "user_id", "INT64", false, false),
+			columnRow("analytics", "events", "props", "STRUCT<a INT64>", false, false),
+			// the primary key declared as not enforced
+			columnRow("analytics", "users", "id", "INT64", true, true),
+		},
+		bigqueryCommentsStmt: {{"analytics", "events", "", "page views"}},
+		bigqueryDefaultsStmt: {{"analytics", "events", "id", "GENERATE_UUID()", "", ""}},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "bigquery", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(bigqueryColumnsStmt) || q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("bigquery columns not read with the bigquery statements")
+	}
+	if di.Type != "bigquery" || di.Schema != "analytics" || di.Name != "shop-project" {
+		t.Errorf("got database %s %s %s", di.Type, di.Schema, di.Name)
+	}
+
+	for col, want := range map[string]string{"id": "bigint", "user_id": "bigint", "props": "json"} {
+		c, err := di.GetColumn("analytics", "events", col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Type, want)
+		}
+	}
+	events, err := di.GetTable("analytics", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := di.GetColumn("analytics", "users", "id"); !c.PrimaryKey {
+		t.Error("got users.id without its declared primary key")
+	}
+	if events.Comment != "page views" {
+		t.Errorf("got comment %q", events.Comment)
+	}
+	if c, _ := di.GetColumn("analytics", "events", "id"); c.Default != "GENERATE_UUID()" {
+		t.Errorf("got default %q", c.Default)
+	}
+
+	// the columns named by a key suffix are taken as keys without any
+	// declared primary key
+	s, err := NewDBSchema(di, nil, WithInferredRels(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("events", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinString(path); got != "events.user_id -> users.id" {
+		t.Errorf("got path %s", got)
+	}
+}
+
+func TestBigQueryType(t *testing.T) {
+	for in, want := range map[string]string{
+		"INT64":                     "bigint",
+		"FLOAT64":                   "double precision",
+		"NUMERIC(10, 2)":            "numeric",
+		"STRING":                    "text",
+		"STRING(20)":                "character varying",
+		"ARRAY<STRING>":             "text[]",
+		"ARRAY<STRUCT<a INT64>>":    "json[]",
+		"STRUCT<a INT64, b STRING>": "json",
+		"TIMESTAMP":                 "timestamp with time zone",
+		"RANGE<DATE>":               "text",
+		"UNKNOWN":                   "UNKNOWN",
+	} {
+		if got := bigqueryType(in); got != want {
+			t.Errorf("bigqueryType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package schema
+
+// setBlocklist sets the tables and foreign key columns that are left out
+// of the relationship graph
+func (s *DBSchema) setBlocklist(tables, rels []string) error {
+	s.blockedTables = tables
+	s.blockedRels = make(map[string]struct{}, len(rels))
+
+	for _, r := range rels {
+		schema, table, col, err := splitColumnName(r)
+		if err != nil {
+			return err
+		}
+		if schema == "" {
+			schema = s.schema
+		}
+		s.blockedRels[(schema + ":" + table + ":" + col)] = struct{}{}
+	}
+	return nil
+}
+
+// isBlockedTable returns true if no edges should be added to the table, the
+// blocklist entries are matched against the name and the schema qualified name
+func (s *DBSchema) isBlockedTable(t DBTable) bool {
+	if len(s.blockedTables) == 0 {
+		return false
+	}
+	return isInList(t.Name, s.blockedTables) ||
+		isInList((t.Schema+"."+t.Name), s.blockedTables)
+}
+
+// isBlockedRel returns true if the relationship from the column of the
+// left table to the right table should not be added to the graph
+func (s *DBSchema) isBlockedRel(lti DBTable, lcol DBColumn, rti DBTable) bool {
+	if s.isBlockedTable(lti) || s.isBlockedTable(rti) {
+		return true
+	}
+	_, ok := s.blockedRels[(lti.Schema + ":" + lti.Name + ":" + lcol.Name)]
+	return ok
+}
@@ -0,0 +1,47 @@
+package schema
+
+import "testing"
+
+func TestBlockedTables(t *testing.T) {
+	s := blogTestSchema(t, WithBlockedTables("posts"))
+
+	path, err := s.FindPath("comments", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 1 || path[0].LC.Name != "user_id" {
+		t.Errorf("got path %s", pathString(path))
+	}
+	if _, err := s.FindPath("comments", "posts", ""); err == nil {
+		t.Error("want no path to a blocked table")
+	}
+	// the blocked table can still be looked up
+	if _, err := s.Find("", "posts"); err != nil {
+		t.Error(err)
+	}
+
+	s = blogTestSchema(t, WithBlockedTables("public.post.*"))
+	if _, err := s.FindPath("posts", "users", ""); err == nil {
+		t.Error("want no path from a blocked schema qualified table")
+	}
+}
+
+func TestBlockedRels(t *testing.T) {
+	s := blogTestSchema(t, WithBlockedRels("comments.user_id"))
+
+	// the path goes around the blocked foreign key
+	path, err := s.FindPath("comments", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 || path[0].RT.Name != "posts" {
+		t.Errorf("got path %s", pathString(path))
+	}
+	if _, err := s.FindPath("posts", "users", ""); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewTestSchema().Table("users", "id pk").BuildSchema(WithBlockedRels("users")); err == nil {
+		t.Error("want an error for a relationship without a column")
+	}
+}
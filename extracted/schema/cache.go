@@ -0,0 +1,94 @@
+package schema
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// infoCache is the file a DBInfo is saved to by WithCache
+type infoCache struct {
+	dir string
+	dsn string
+	ttl time.Duration
+}
+
+// path returns the file of the DBInfo of a database read with the
+// options, the name is a hash so the credentials of the dsn are not
+// written to disk
+func (c *infoCache) path(dbType string, blockList []string, io *infoOptions) string {
+	h := sha256.New()
+	for _, v := range [][]string{
+		{c.dsn, dbType}, blockList, io.schemas, io.tables, io.include, io.exclude,
+	} {
+		h.Write([]byte(strings.Join(v, "\x00")))
+		h.Write([]byte{0xff})
+	}
+	return filepath.Join(c.dir, "dbinfo-"+hex.EncodeToString(h.Sum(nil))[:32]+".json")
+}
+
+// load returns the saved DBInfo if it is younger than the ttl, a file
+// that cannot be read is not used
+func (c *infoCache) load(path string) (*DBInfo, bool) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(fi.ModTime()) > c.ttl {
+		return nil, false
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	di, err := LoadDBInfo(bytes.NewReader(b))
+	if err != nil {
+		return nil, false
+	}
+	return di, true
+}
+
+// saveCache saves the DBInfo read from the database, the DBInfo is still
+// returned when it cannot be saved and is read again the next time
+func (o *infoOptions) saveCache(path string, di *DBInfo) {
+	if err := o.cache.save(path, di); err != nil {
+		o.log.warn("dbinfo not cached", slog.String("path", path), slog.Any("error", err))
+	}
+}
+
+// save writes the DBInfo, the file is replaced in one step so processes
+// reading it at the same time see the old or the new one
+func (c *infoCache) save(path string, di *DBInfo) error {
+	b, err := json.Marshal(di)
+	if err != nil {
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".dbinfo-*")
+	if err != nil {
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving dbinfo cache: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveCacheError(t *testing.T) {
+	// the cache directory cannot be created under a file
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	o := infoOptions{
+		cache: &infoCache{dir: filepath.Join(file, "cache")},
+		log:   logger{slog.New(slog.NewTextHandler(&buf, nil))},
+	}
+	o.saveCache(filepath.Join(file, "cache", "dbinfo.json"), GetTestDBInfo())
+
+	if !strings.Contains(buf.String(), "level=WARN") || !strings.Contains(buf.String(), "dbinfo not cached") {
+		t.Errorf("got log %q", buf.String())
+	}
+}
+
+func TestSaveCacheLoad(t *testing.T) {
+	dir := t.TempDir()
+	c := &infoCache{dir: dir}
+	path := filepath.Join(dir, "dbinfo.json")
+
+	cols := []DBColumn{
+		{Schema: "public", Table: "users", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true},
+		{Schema: "public", Table: "posts", Name: "user_id", Type: "bigint", FKeySchema: "public", FKeyTable: "users", FKeyCol: "id"},
+	}
+	di := NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil)
+	if err := c.save(path, di); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.load(path)
+	if !ok {
+		t.Fatal("saved dbinfo not loaded")
+	}
+	if got.Hash() != di.Hash() {
+		t.Errorf("got hash %d, want %d", got.Hash(), di.Hash())
+	}
+}
+
+func TestHash(t *testing.T) {
+	cols := []DBColumn{{Schema: "public", Table: "users", Name: "id", Type: "bigint", PrimaryKey: true}}
+	a := NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil)
+	b := NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil)
+	if a.Hash() != b.Hash() {
+		t.Errorf("same columns got hashes %d and %d", a.Hash(), b.Hash())
+	}
+
+	cols = append(cols, DBColumn{Schema: "public", Table: "users", Name: "email", Type: "text"})
+	c := NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil)
+	if c.Hash() == a.Hash() {
+		t.Errorf("added column did not change the hash %d", a.Hash())
+	}
+}
@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// DBCheck holds a check constraint of a table
+type DBCheck struct {
+	Schema  string
+	Table   string
+	Name    string
+	Expr    string
+	Columns []string
+}
+
+// DiscoverChecks returns the check constraints of a database
+func DiscoverChecks(ctx context.Context, db Querier, dbtype string) ([]DBCheck, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlChecksStmt
+	case "mssql":
+		sqlStmt = mssqlChecksStmt
+	case "duckdb":
+		sqlStmt = duckdbChecksStmt
+	case "sqlite":
+		// sqlite only keeps check constraints in the table sql
+		return nil, nil
+	case "bigquery", "snowflake":
+		// bigquery and snowflake have no check constraints
+		return nil, nil
+	case "clickhouse":
+		// clickhouse only keeps constraints in the create table query
+		return nil, nil
+	case "trino", "redshift":
+		// trino and redshift have no check constraints
+		return nil, nil
+	default:
+		sqlStmt = postgresChecksStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching check constraints: %s", err)
+	}
+	defer rows.Close()
+
+	var checks []DBCheck
+	cm := make(map[string]int)
+
+	for rows.Next() {
+		var cs, ct, cn, ce, cc string
+
+		if err = rows.Scan(&cs, &ct, &cn, &ce, &cc); err != nil {
+			return nil, err
+		}
+
+		k := (cs + ":" + ct + ":" + cn)
+		i, ok := cm[k]
+		if !ok {
+			checks = append(checks, DBCheck{Schema: cs, Table: ct, Name: cn, Expr: ce})
+			i = len(checks) - 1
+			cm[k] = i
+		}
+
+		if cc != "" {
+			checks[i].Columns = append(checks[i].Columns, cc)
+		}
+	}
+
+	return checks, rows.Err()
+}
+
+// addChecks adds check constraints to their tables, when the database
+// does not return the columns of a check they are found by looking for
+// the table column names in the expression
+func (di *DBInfo) addChecks(checks []DBCheck) {
+	for _, c := range checks {
+		t, err := di.GetTable(c.Schema, c.Table)
+		if err != nil {
+			continue
+		}
+
+		if len(c.Columns) == 0 {
+			c.Columns = exprColumns(t, c.Expr)
+		}
+		t.Checks = append(t.Checks, c)
+	}
+}
+
+// exprColumns returns the columns of a table used in an expression
+func exprColumns(t *DBTable, expr string) []string {
+	var cols []string
+	seen := make(map[string]struct{})
+
+	words := strings.FieldsFunc(expr, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$'
+	})
+
+	for _, w := range words {
+		if _, ok := seen[w]; ok {
+			continue
+		}
+		if _, ok := t.colMap[w]; ok {
+			cols = append(cols, w)
+			seen[w] = struct{}{}
+		}
+	}
+	return cols
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverChecks(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "shop"}},
+		postgresColumnsStmt: {
+			columnRow("public", "products", "id", "bigint", true, true),
+			columnRow("public", "products", "price", "numeric", true, false),
+			columnRow("public", "products", "sale_price", "numeric", false, false),
+		},
+		postgresChecksStmt: {
+			{"public", "products", "price_positive", "CHECK ((price >= 0))", "price"},
+			{"public", "products", "sale_below_price", "CHECK ((sale_price < price))", "sale_price"},
+			{"public", "products", "sale_below_price", "CHECK ((sale_price < price))", "price"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := di.GetTable("public", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tbl.Checks) != 2 {
+		t.Fatalf("got checks %v", tbl.Checks)
+	}
+	c := tbl.Checks[1]
+	if c.Name != "sale_below_price" || c.Expr != "CHECK ((sale_price < price))" ||
+		!reflect.DeepEqual(c.Columns, []string{"sale_price", "price"}) {
+		t.Errorf("got check %+v", c)
+	}
+}
+
+func TestCheckExprColumns(t *testing.T) {
+	// mysql does not return the columns of a check
+	di := GetTestDBInfo()
+	di.addChecks([]DBCheck{{
+		Schema: "public",
+		Table:  "products",
+		Name:   "price_check",
+		Expr:   "(`price` > 0 and `price` < 1000 or name = 'price')",
+	}})
+
+	tbl, _ := di.GetTable("public", "products")
+	c := tbl.Checks[len(tbl.Checks)-1]
+	if !reflect.DeepEqual(c.Columns, []string{"price", "name"}) {
+		t.Errorf("got columns %v", c.Columns)
+	}
+}
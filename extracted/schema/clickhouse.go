@@ -0,0 +1,66 @@
+package schema
+
+import "strings"
+
+// clickhouseTypes maps clickhouse type names to the type names
+// used across the rest of the schema package
+var clickhouseTypes = map[string]string{
+	"bool":        "boolean",
+	"int8":        "smallint",
+	"uint8":       "smallint",
+	"int16":       "smallint",
+	"uint16":      "integer",
+	"int32":       "integer",
+	"uint32":      "bigint",
+	"int64":       "bigint",
+	"uint64":      "numeric",
+	"int128":      "numeric",
+	"uint128":     "numeric",
+	"int256":      "numeric",
+	"uint256":     "numeric",
+	"float32":     "real",
+	"float64":     "double precision",
+	"decimal":     "numeric",
+	"decimal32":   "numeric",
+	"decimal64":   "numeric",
+	"decimal128":  "numeric",
+	"decimal256":  "numeric",
+	"string":      "text",
+	"fixedstring": "character",
+	"enum8":       "text",
+	"enum16":      "text",
+	"uuid":        "uuid",
+	"date":        "date",
+	"date32":      "date",
+	"datetime":    "timestamp with time zone",
+	"datetime64":  "timestamp with time zone",
+	"ipv4":        "inet",
+	"ipv6":        "inet",
+	"json":        "json",
+	"object":      "json",
+	"map":         "json",
+	"tuple":       "json",
+}
+
+// clickhouseType returns the normalized name for a clickhouse type, the
+// LowCardinality and Nullable wrappers are dropped and arrays become
+// arrays of the element type
+func clickhouseType(t string) string {
+	for _, w := range []string{"LowCardinality(", "Nullable("} {
+		if e, ok := strings.CutPrefix(t, w); ok && strings.HasSuffix(e, ")") {
+			t = e[:len(e)-1]
+		}
+	}
+	if e, ok := strings.CutPrefix(t, "Array("); ok && strings.HasSuffix(e, ")") {
+		return clickhouseType(e[:len(e)-1]) + "[]"
+	}
+
+	name := strings.ToLower(t)
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	if v, ok := clickhouseTypes[name]; ok {
+		return v
+	}
+	return t
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetDBInfoClickHouse(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		clickhouseInfo: {{240300, "analytics", "analytics"}},
+		clickhouseColumnsStmt: {
+			columnRow("analytics", "users", "id", "UInt32", true, true),
+			columnRow("analytics", "users", "country", "LowCardinality(String)", true, false),
+			columnRow("analytics", "events", "user_id", "UInt32", true, false),
+			columnRow("analytics", "events", "at", "DateTime64(3)", true, false),
+			columnRow("analytics", "events", "tags", "Array(Nullable(String))", true, false),
+			columnRow("analytics", "events", "day", "Date", true, false),
+		},
+		clickhouseGeneratedStmt: {{"analytics", "events", "day", "stored", "toDate(at)"}},
+		clickhouseIndexesStmt: {
+			{"analytics", "events", "sorting_key", "mergetree", false, false, false, "", "user_id"},
+			{"analytics", "events", "sorting_key", "mergetree", false, false, false, "", "at"},
+			{"analytics", "events", "tags_idx", "bloom_filter", false, false, false, "", "tags"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "clickhouse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(clickhouseColumnsStmt) || q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("clickhouse columns not read with the clickhouse statements")
+	}
+	if di.Type != "clickhouse" || di.Version != 240300 || di.Schema != "analytics" {
+		t.Errorf("got database %s %d %s", di.Type, di.Version, di.Schema)
+	}
+
+	users, err := di.GetTable("analytics", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users.PrimaryCol.Name != "id" || users.PrimaryCol.Type != "bigint" {
+		t.Errorf("got primary key %+v", users.PrimaryCol)
+	}
+
+	events, err := di.GetTable("analytics", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Indexes) != 2 || events.Indexes[0].Method != "mergetree" ||
+		strings.Join(events.Indexes[0].Columns, ", ") != "user_id, at" || events.Indexes[1].Method != "bloom_filter" {
+		t.Errorf("got indexes %+v", events.Indexes)
+	}
+	for col, want := range map[string]string{"at": "timestamp with time zone", "tags": "text[]", "day": "date"} {
+		c, err := di.GetColumn("analytics", "events", col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Type, want)
+		}
+	}
+	if c, _ := di.GetColumn("analytics", "events", "day"); c.Generated != "stored" || c.GenExpr != "toDate(at)" {
+		t.Errorf("got generated %q %q", c.Generated, c.GenExpr)
+	}
+
+	// there are no foreign keys, the relationships are declared
+	vr, err := NewVirtualRel("analytics.events.user_id", "analytics.users.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDBSchema(di, nil, WithVirtualRels(vr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("events", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClickHouseType(t *testing.T) {
+	for in, want := range map[string]string{
+		"UInt8":                            "smallint",
+		"UInt64":                           "numeric",
+		"Nullable(Int32)":                  "integer",
+		"LowCardinality(Nullable(String))": "text",
+		"Array(LowCardinality(String))":    "text[]",
+		"Decimal(18, 4)":                   "numeric",
+		"FixedString(16)":                  "character",
+		"Enum8('a' = 1, 'b' = 2)":          "text",
+		"Map(String, UInt64)":              "json",
+		"AggregateFunction(uniq, UInt64)":  "AggregateFunction(uniq, UInt64)",
+	} {
+		if got := clickhouseType(in); got != want {
+			t.Errorf("clickhouseType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// FindColumnPath returns a path from a column eg. "orders.billing_address_id"
+// to a table, the first join is on the column. A table with more than one
+// foreign key to the same table picks the one to join on this way
+//
+//	path, err := s.FindColumnPath("orders.shipping_address_id", "addresses")
+func (s *DBSchema) FindColumnPath(from, to string) ([]TPath, error) {
+	s = s.current()
+
+	ft, fc, err := s.findColumn(from)
+	if err != nil {
+		return nil, err
+	}
+
+	tt, err := s.find("", to)
+	if err != nil {
+		return nil, err
+	}
+	return s.columnPath(from, to, ft, fc, tt, DBColumn{})
+}
+
+// FindColumnsPath returns a path between two columns, the first join is
+// on the from column and the last one on the to column
+//
+//	path, err := s.FindColumnsPath("orders.billing_address_id", "addresses.id")
+func (s *DBSchema) FindColumnsPath(from, to string) ([]TPath, error) {
+	s = s.current()
+
+	ft, fc, err := s.findColumn(from)
+	if err != nil {
+		return nil, err
+	}
+
+	tt, tc, err := s.findColumn(to)
+	if err != nil {
+		return nil, err
+	}
+	return s.columnPath(from, to, ft, fc, tt, tc)
+}
+
+// columnPath returns the shortest path starting with an edge on the from
+// column and ending at the to table, with an edge on the to column unless
+// it is empty. Paths with the same number of joins are ordered by the
+// edges they start and end with
+func (s *DBSchema) columnPath(from, to string, ft DBTable, fc DBColumn, tt DBTable, tc DBColumn) ([]TPath, error) {
+	fn, ok := s.tindex[(ft.Schema + ":" + ft.Name)]
+	if !ok {
+		return nil, s.tableNotFound(from, ErrFromEdgeNotFound)
+	}
+	tn, ok := s.tindex[(tt.Schema + ":" + tt.Name)]
+	if !ok {
+		return nil, s.tableNotFound(to, ErrToEdgeNotFound)
+	}
+
+	first := s.columnEdges(func(e edge) bool {
+		return e.From == fn.nodeID && joinsOn(e.L, e.LCs, fc.Name)
+	})
+	if len(first) == 0 {
+		return nil, fmt.Errorf("%w: no relationship on column %s", ErrFromEdgeNotFound, from)
+	}
+
+	var last []int32
+	if tc.Name != "" {
+		last = s.columnEdges(func(e edge) bool {
+			return e.To == tn.nodeID && joinsOn(e.R, e.RCs, tc.Name)
+		})
+		if len(last) == 0 {
+			return nil, fmt.Errorf("%w: no relationship on column %s", ErrToEdgeNotFound, to)
+		}
+	}
+
+	var best []TPath
+	for _, f := range first {
+		fe := s.allEdges[f]
+
+		if tc.Name == "" {
+			if p, ok := s.joinEdges(f, -1, fe.To, tn.nodeID); ok && (best == nil || len(p) < len(best)) {
+				best = p
+			}
+			continue
+		}
+
+		for _, l := range last {
+			le := s.allEdges[l]
+			if p, ok := s.joinEdges(f, l, fe.To, le.From); ok && (best == nil || len(p) < len(best)) {
+				best = p
+			}
+		}
+	}
+
+	if best == nil {
+		fl := []edgeInfo{{nodeID: fn.nodeID}}
+		tl := []edgeInfo{{nodeID: tn.nodeID}}
+		return nil, s.noPath(from, to, "", fl, tl)
+	}
+	return best, nil
+}
+
+// joinEdges returns the path of the first edge, the shortest path
+// between two nodes and the last edge, -1 when there is none. A first
+// edge that is also the last one is the whole path
+func (s *DBSchema) joinEdges(first, last, from, to int32) ([]TPath, bool) {
+	path := s.edgesToPath([]int32{first})
+	if first == last {
+		return path, true
+	}
+
+	if from != to {
+		ft, tt := s.tables[from], s.tables[to]
+		p, err := s.findNamedPath(context.Background(), ft.Schema+"."+ft.Name, tt.Schema+"."+tt.Name, "")
+		if err != nil {
+			return nil, false
+		}
+		path = append(path, p...)
+	}
+
+	if last != -1 {
+		path = append(path, s.edgesToPath([]int32{last})...)
+	}
+	return path, true
+}
+
+// columnEdges returns the sorted ids of the edges that match
+func (s *DBSchema) columnEdges(match func(edge) bool) []int32 {
+	var ids []int32
+	for id, e := range s.allEdges {
+		if match(e) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// joinsOn returns true if a column of an edge is the named column
+func joinsOn(c DBColumn, cols []DBColumn, name string) bool {
+	if c.Name == name {
+		return true
+	}
+	for _, col := range cols {
+		if col.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// addressSchema has orders with a billing and a shipping address and
+// countries of the addresses
+func addressSchema(t *testing.T) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("countries", "id pk").
+		Table("addresses", "id pk", "country_id notnull").
+		Table("orders", "id pk", "billing_address_id notnull", "shipping_address_id notnull").
+		FK("addresses.country_id", "countries.id").
+		FK("orders.billing_address_id", "addresses.id").
+		FK("orders.shipping_address_id", "addresses.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFindColumnPath(t *testing.T) {
+	s := addressSchema(t)
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"orders.shipping_address_id", "addresses", "orders.shipping_address_id -> addresses.id"},
+		{"orders.billing_address_id", "addresses", "orders.billing_address_id -> addresses.id"},
+		{"orders.shipping_address_id", "countries",
+			"orders.shipping_address_id -> addresses.id, addresses.country_id -> countries.id"},
+		// the reverse of a foreign key
+		{"addresses.id", "orders", "addresses.id -> orders.billing_address_id"},
+	}
+	for _, tt := range tests {
+		path, err := s.FindColumnPath(tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%s -> %s: %v", tt.from, tt.to, err)
+		}
+		if got := joinString(path); got != tt.want {
+			t.Errorf("%s -> %s: got %s, want %s", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestFindColumnsPath(t *testing.T) {
+	s := addressSchema(t)
+	path, err := s.FindColumnsPath("addresses.id", "orders.shipping_address_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinString(path); got != "addresses.id -> orders.shipping_address_id" {
+		t.Errorf("got path %s", got)
+	}
+
+	path, err = s.FindColumnsPath("countries.id", "orders.billing_address_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinString(path); got != "countries.id -> addresses.country_id, addresses.id -> orders.billing_address_id" {
+		t.Errorf("got path %s", got)
+	}
+}
+
+func TestFindColumnPathErrors(t *testing.T) {
+	s := addressSchema(t)
+	if _, err := s.FindColumnPath("orders.id", "addresses"); !errors.Is(err, ErrFromEdgeNotFound) {
+		t.Errorf("got %v, want ErrFromEdgeNotFound for a column without a relationship", err)
+	}
+	if _, err := s.FindColumnsPath("orders.billing_address_id", "countries.id"); err != nil {
+		t.Errorf("got %v", err)
+	}
+	if _, err := s.FindColumnsPath("countries.id", "orders.id"); !errors.Is(err, ErrToEdgeNotFound) {
+		t.Errorf("got %v, want ErrToEdgeNotFound", err)
+	}
+	if _, err := s.FindColumnPath("orders.missing", "addresses"); err == nil {
+		t.Error("want an error for an unknown column")
+	}
+	if _, err := s.FindColumnPath("orders.billing_address_id", "missing"); err == nil {
+		t.Error("want an error for an unknown table")
+	}
+}
@@ -0,0 +1,76 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBComment holds the comment of a table or when Column is set
+// the comment of a column
+type DBComment struct {
+	Schema  string
+	Table   string
+	Column  string
+	Comment string
+}
+
+// DiscoverComments returns the table and column comments of a database
+func DiscoverComments(ctx context.Context, db Querier, dbtype string) ([]DBComment, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlCommentsStmt
+	case "mssql":
+		sqlStmt = mssqlCommentsStmt
+	case "bigquery":
+		sqlStmt = bigqueryCommentsStmt
+	case "snowflake":
+		sqlStmt = snowflakeCommentsStmt
+	case "clickhouse":
+		sqlStmt = clickhouseCommentsStmt
+	case "duckdb":
+		sqlStmt = duckdbCommentsStmt
+	case "trino":
+		sqlStmt = trinoCommentsStmt
+	case "sqlite":
+		// sqlite does not support comments
+		return nil, nil
+	default:
+		sqlStmt = postgresCommentsStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching comments: %s", err)
+	}
+	defer rows.Close()
+
+	var comments []DBComment
+
+	for rows.Next() {
+		var c DBComment
+
+		if err = rows.Scan(&c.Schema, &c.Table, &c.Column, &c.Comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	return comments, rows.Err()
+}
+
+// addComments sets the comments of tables and columns
+func (di *DBInfo) addComments(comments []DBComment) {
+	for _, c := range comments {
+		if c.Column == "" {
+			if t, err := di.GetTable(c.Schema, c.Table); err == nil {
+				t.Comment = c.Comment
+			}
+			continue
+		}
+		if col, err := di.GetColumn(c.Schema, c.Table, c.Column); err == nil {
+			col.Comment = c.Comment
+		}
+	}
+}
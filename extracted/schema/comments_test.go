@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiscoverComments(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "users", "email", "varchar", true, false),
+		},
+		mysqlCommentsStmt: {
+			{"shop", "users", "", "People who signed up"},
+			{"shop", "users", "email", "Login address"},
+			{"shop", "users", "missing", "Not a column"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "mysql", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := di.GetTable("shop", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.Comment != "People who signed up" {
+		t.Errorf("got table comment %q", tbl.Comment)
+	}
+	for col, want := range map[string]string{"email": "Login address", "id": ""} {
+		c, _ := di.GetColumn("shop", "users", col)
+		if c.Comment != want {
+			t.Errorf("%s: got comment %q, want %q", col, c.Comment, want)
+		}
+	}
+}
+
+func TestDiscoverCommentsStmt(t *testing.T) {
+	for dbType, stmt := range map[string]string{
+		"postgres": postgresCommentsStmt,
+		"mssql":    mssqlCommentsStmt,
+		"mariadb":  mysqlCommentsStmt,
+	} {
+		q := &routeQuerier{}
+		if _, err := DiscoverComments(context.Background(), q, dbType); err != nil {
+			t.Fatal(err)
+		}
+		if !q.ran(stmt) {
+			t.Errorf("%s: comments not read with the %s statement", dbType, dbType)
+		}
+	}
+
+	q := &routeQuerier{}
+	if _, err := DiscoverComments(context.Background(), q, "sqlite"); err != nil || len(q.queries) != 0 {
+		t.Errorf("sqlite: got queries %v, %v", q.queries, err)
+	}
+}
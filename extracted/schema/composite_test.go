@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompositeFKeys(t *testing.T) {
+	fk := func(name, col string) DBColumn {
+		return DBColumn{Schema: "public", Table: "items", Name: name, Type: "bigint", NotNull: true,
+			FKeySchema: "public", FKeyTable: "orders", FKeyCol: col, FKeyName: "items_order_fkey"}
+	}
+	cols := []DBColumn{
+		{Schema: "public", Table: "orders", Name: "tenant_id", Type: "bigint", NotNull: true, PrimaryKey: true},
+		{Schema: "public", Table: "orders", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true},
+		{Schema: "public", Table: "items", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, UniqueKey: true},
+		fk("tenant_id", "tenant_id"),
+		fk("order_id", "id"),
+	}
+	s, err := NewDBSchema(NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := s.FindPath("items", "orders", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := path[0]
+	// the relationship is named after the column not matching its key
+	if p.LC.Name != "order_id" || len(p.LCs) != 2 || len(p.RCs) != 2 {
+		t.Fatalf("got column %s with %d and %d key columns", p.LC.Name, len(p.LCs), len(p.RCs))
+	}
+	for i := range p.LCs {
+		if p.LCs[i].FKeyCol != p.RCs[i].Name {
+			t.Errorf("column %s paired with %s", p.LCs[i].Name, p.RCs[i].Name)
+		}
+	}
+
+	sql, _, err := PathToSQL(path, JoinOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"items"."order_id" = "orders"."id"`, `"items"."tenant_id" = "orders"."tenant_id"`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("join without %s:\n%s", want, sql)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictTarget is what the ON CONFLICT clause of an upsert conflicts
+// on, a constraint by name or the columns of a unique index
+type ConflictTarget struct {
+	Constraint string   // primary key or unique constraint, empty for an index
+	Columns    []string // columns of the key in its order
+	Predicate  string   // predicate of a partial unique index
+}
+
+// String returns the target as written after ON CONFLICT in Postgres,
+// eg. ON CONSTRAINT "users_email_key" or ("org_id", "email") WHERE ...
+func (ct ConflictTarget) String() string {
+	if ct.Constraint != "" {
+		return "ON CONSTRAINT " + quoteName(ct.Constraint)
+	}
+	cols := make([]string, len(ct.Columns))
+	for i, c := range ct.Columns {
+		cols[i] = quoteName(c)
+	}
+	s := "(" + strings.Join(cols, ", ") + ")"
+	if ct.Predicate != "" {
+		s += " WHERE " + ct.Predicate
+	}
+	return s
+}
+
+// ConflictTarget returns the target of an upsert of a table setting the
+// columns, the primary key or unique index made of the columns set. The
+// primary key wins over unique indexes, then indexes without a predicate
+// and with the fewest columns. Without indexes the primary key or a
+// column with a unique constraint is used
+func (ti *DBTable) ConflictTarget(cols ...string) (ConflictTarget, error) {
+	set := make(map[string]struct{}, len(cols))
+	for _, c := range cols {
+		set[c] = struct{}{}
+	}
+	covered := func(names []string) bool {
+		for _, n := range names {
+			if _, ok := set[n]; !ok {
+				return false
+			}
+		}
+		return len(names) != 0
+	}
+
+	var idxs []DBIndex
+	for _, idx := range ti.Indexes {
+		if (idx.Unique || idx.Primary) && covered(idx.Columns) {
+			idxs = append(idxs, idx)
+		}
+	}
+	sort.SliceStable(idxs, func(i, j int) bool {
+		a, b := idxs[i], idxs[j]
+		switch {
+		case a.Primary != b.Primary:
+			return a.Primary
+		case (a.Predicate == "") != (b.Predicate == ""):
+			return a.Predicate == ""
+		case len(a.Columns) != len(b.Columns):
+			return len(a.Columns) < len(b.Columns)
+		case a.Constraint != b.Constraint:
+			return a.Constraint
+		}
+		return a.Name < b.Name
+	})
+	if len(idxs) != 0 {
+		idx := idxs[0]
+		ct := ConflictTarget{Columns: idx.Columns, Predicate: idx.Predicate}
+		if idx.Constraint {
+			ct.Constraint = idx.Name
+		}
+		return ct, nil
+	}
+
+	if len(ti.Indexes) == 0 {
+		var pk []string
+		for _, c := range ti.Columns {
+			if c.PrimaryKey {
+				pk = append(pk, c.Name)
+			}
+		}
+		if covered(pk) {
+			return ConflictTarget{Columns: pk}, nil
+		}
+		for _, c := range ti.Columns {
+			if _, ok := set[c.Name]; ok && c.UniqueKey {
+				return ConflictTarget{Columns: []string{c.Name}}, nil
+			}
+		}
+	}
+
+	return ConflictTarget{}, fmt.Errorf("no primary key or unique index of %s on the columns: %s",
+		ti.Name, strings.Join(cols, ", "))
+}
+
+// quoteName returns a name quoted as an SQL identifier
+func quoteName(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
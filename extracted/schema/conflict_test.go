@@ -0,0 +1,79 @@
+package schema
+
+import "testing"
+
+func TestConflictTarget(t *testing.T) {
+	ti := NewDBTable("public", "members", "", []DBColumn{
+		{Name: "id", PrimaryKey: true},
+		{Name: "org_id"},
+		{Name: "email"},
+		{Name: "handle"},
+	})
+	ti.Indexes = []DBIndex{
+		{Name: "members_pkey", Columns: []string{"id"}, Primary: true, Unique: true, Constraint: true},
+		{Name: "members_org_email_key", Columns: []string{"org_id", "email"}, Unique: true, Constraint: true},
+		{Name: "members_email_idx", Columns: []string{"email"}, Unique: true},
+		{Name: "members_handle_idx", Columns: []string{"handle"}, Unique: true, Predicate: "(deleted_at IS NULL)"},
+		{Name: "members_org_idx", Columns: []string{"org_id"}},
+	}
+
+	tests := []struct {
+		cols []string
+		want string
+	}{
+		{[]string{"id", "email"}, `ON CONSTRAINT "members_pkey"`},
+		{[]string{"org_id", "email"}, `("email")`},
+		{[]string{"org_id", "handle"}, `("handle") WHERE (deleted_at IS NULL)`},
+	}
+	for _, tt := range tests {
+		ct, err := ti.ConflictTarget(tt.cols...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.cols, err)
+			continue
+		}
+		if got := ct.String(); got != tt.want {
+			t.Errorf("%v: got %s, want %s", tt.cols, got, tt.want)
+		}
+	}
+
+	// a unique constraint of some of the columns set
+	ti.Indexes = ti.Indexes[1:2]
+	ct, err := ti.ConflictTarget("org_id", "email", "handle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct.Constraint != "members_org_email_key" || len(ct.Columns) != 2 {
+		t.Errorf("got %+v", ct)
+	}
+
+	if _, err := ti.ConflictTarget("org_id"); err == nil {
+		t.Error("want an error without a unique index of the columns")
+	}
+}
+
+func TestConflictTargetNoIndexes(t *testing.T) {
+	ti := NewDBTable("public", "users", "", []DBColumn{
+		{Name: "id", PrimaryKey: true},
+		{Name: "email", UniqueKey: true},
+		{Name: "name"},
+	})
+
+	for cols, want := range map[string][]string{
+		"id":    {"id", "name"},
+		"email": {"email", "name"},
+	} {
+		ct, err := ti.ConflictTarget(want...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct.Constraint != "" || len(ct.Columns) != 1 || ct.Columns[0] != cols {
+			t.Errorf("%v: got %+v", want, ct)
+		}
+	}
+	if _, err := ti.ConflictTarget("name"); err == nil {
+		t.Error("want an error without a key")
+	}
+	if got := (ConflictTarget{Columns: []string{`a"b`}}).String(); got != `("a""b")` {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,1129 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DDLSource is a source reading the tables of a database from a SQL
+// schema script like the one written by pg_dump --schema-only, the
+// CREATE TABLE, ALTER TABLE ... ADD and ALTER COLUMN, ALTER SEQUENCE ...
+// OWNED BY, CREATE UNIQUE INDEX and COMMENT ON statements are read and
+// every other statement is skipped
+type DDLSource struct {
+	db     DBDatabase
+	tables []DBTable
+	cols   []DBColumn
+	fks    []DBForeignKey
+	tmap   map[string]int
+	cmap   map[string]int
+}
+
+// ParseDDL returns the database schema information described by a SQL
+// schema script, no database connection is needed
+func ParseDDL(ddl []byte, dbType string) (*DBInfo, error) {
+	src, err := NewDDLSource(ddl, dbType)
+	if err != nil {
+		return nil, err
+	}
+	return NewDBInfoFromSource(context.Background(), src, nil)
+}
+
+// NewDDLSource parses a SQL schema script, tables without a schema are
+// in the schema set by SET search_path or USE or else in the default
+// schema of the database type
+func NewDDLSource(ddl []byte, dbType string) (*DDLSource, error) {
+	s := &DDLSource{
+		db:   DBDatabase{Type: dbType, Schema: ddlDefaultSchema(dbType)},
+		tmap: make(map[string]int),
+		cmap: make(map[string]int),
+	}
+
+	toks, err := lexDDL(string(ddl), dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range splitDDL(toks) {
+		p := &ddlParser{src: s, toks: stmt, text: string(ddl)}
+		if err := p.statement(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.resolveFKeys(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// DiscoverDatabase returns the database type and the default schema
+func (s *DDLSource) DiscoverDatabase(ctx context.Context) (DBDatabase, error) {
+	return s.db, nil
+}
+
+// DiscoverTables returns the tables of the script
+func (s *DDLSource) DiscoverTables(ctx context.Context) ([]DBTable, error) {
+	return s.tables, nil
+}
+
+// DiscoverColumns returns the columns of the script
+func (s *DDLSource) DiscoverColumns(ctx context.Context) ([]DBColumn, error) {
+	return s.cols, nil
+}
+
+// DiscoverRelationships returns the foreign keys of the script
+func (s *DDLSource) DiscoverRelationships(ctx context.Context) ([]DBForeignKey, error) {
+	return s.fks, nil
+}
+
+// ddlDefaultSchema returns the schema of unqualified tables
+func ddlDefaultSchema(dbType string) string {
+	switch dbType {
+	case "sqlite":
+		return "main"
+	case "mssql":
+		return "dbo"
+	}
+	return "public"
+}
+
+// addTable adds a table unless it is already known
+func (s *DDLSource) addTable(schema, name string) {
+	k := schema + ":" + name
+	if _, ok := s.tmap[k]; ok {
+		return
+	}
+	s.tmap[k] = len(s.tables)
+	s.tables = append(s.tables, DBTable{Schema: schema, Name: name})
+}
+
+// addColumn adds a column to a table
+func (s *DDLSource) addColumn(c DBColumn) {
+	k := c.Schema + ":" + c.Table + ":" + c.Name
+	if i, ok := s.cmap[k]; ok {
+		s.cols[i] = c
+		return
+	}
+	s.cmap[k] = len(s.cols)
+	s.cols = append(s.cols, c)
+}
+
+// column returns a column of a table
+func (s *DDLSource) column(schema, table, name string) (*DBColumn, bool) {
+	i, ok := s.cmap[(schema + ":" + table + ":" + name)]
+	if !ok {
+		return nil, false
+	}
+	return &s.cols[i], true
+}
+
+// resolveFKeys sets the columns of the foreign keys that only name the
+// table they point to, they point to its primary key
+func (s *DDLSource) resolveFKeys() error {
+	for i := range s.fks {
+		fk := &s.fks[i]
+		if fk.FKeyCol != "" {
+			continue
+		}
+		var pk []string
+		for _, c := range s.cols {
+			if c.Schema == fk.FKeySchema && c.Table == fk.FKeyTable && c.PrimaryKey {
+				pk = append(pk, c.Name)
+			}
+		}
+		if len(pk) != 1 {
+			return fmt.Errorf("ddl: foreign key %s.%s references %s.%s without a single column primary key",
+				fk.Table, fk.Column, fk.FKeySchema, fk.FKeyTable)
+		}
+		fk.FKeyCol = pk[0]
+	}
+	return nil
+}
+
+// ddlTokType is the type of a token of a SQL script
+type ddlTokType int
+
+const (
+	dtWord   ddlTokType = iota // keyword or unquoted identifier
+	dtIdent                    // quoted identifier
+	dtString                   // string literal
+	dtNumber                   // number literal
+	dtPunct                    // any other character
+)
+
+// ddlToken is a token of a SQL script with its offsets in the script
+type ddlToken struct {
+	typ        ddlTokType
+	val        string
+	line       int
+	start, end int
+}
+
+// lexDDL splits a SQL script into tokens, comments are dropped
+func lexDDL(src, dbType string) ([]ddlToken, error) {
+	var toks []ddlToken
+	fold := dbType == "" || dbType == "postgres" || dbType == "cockroach" || dbType == "cockroachdb" ||
+		dbType == "redshift"
+	line := 1
+
+	for i := 0; i < len(src); {
+		ch := src[i]
+		start := i
+
+		switch {
+		case ch == '\n':
+			line++
+			i++
+			continue
+
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f':
+			i++
+			continue
+
+		case ch == '-' && strings.HasPrefix(src[i:], "--"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+
+		case ch == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("ddl: line %d: unterminated comment", line)
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+			continue
+
+		case ch == '\'':
+			v, n, err := lexQuoted(src[i:], '\'', '\'')
+			if err != nil {
+				return nil, fmt.Errorf("ddl: line %d: %w", line, err)
+			}
+			toks = append(toks, ddlToken{typ: dtString, val: v, line: line, start: start, end: i + n})
+			line += strings.Count(src[i:i+n], "\n")
+			i += n
+			continue
+
+		case ch == '"' || ch == '`' || (ch == '[' && dbType == "mssql"):
+			closing := ch
+			if ch == '[' {
+				closing = ']'
+			}
+			v, n, err := lexQuoted(src[i:], ch, closing)
+			if err != nil {
+				return nil, fmt.Errorf("ddl: line %d: %w", line, err)
+			}
+			toks = append(toks, ddlToken{typ: dtIdent, val: v, line: line, start: start, end: i + n})
+			i += n
+			continue
+
+		case ch == '$':
+			// dollar quoted strings hold function bodies
+			if tag, ok := dollarTag(src[i:]); ok {
+				end := strings.Index(src[i+len(tag):], tag)
+				if end == -1 {
+					return nil, fmt.Errorf("ddl: line %d: unterminated dollar quoted string", line)
+				}
+				n := len(tag) + end + len(tag)
+				toks = append(toks, ddlToken{
+					typ:   dtString,
+					val:   src[i+len(tag) : i+len(tag)+end],
+					line:  line,
+					start: start,
+					end:   i + n,
+				})
+				line += strings.Count(src[i:i+n], "\n")
+				i += n
+				continue
+			}
+
+		case isDDLWordChar(ch) && !(ch >= '0' && ch <= '9'):
+			for i < len(src) && isDDLWordChar(src[i]) {
+				i++
+			}
+			v := src[start:i]
+			if fold {
+				v = strings.ToLower(v)
+			}
+			toks = append(toks, ddlToken{typ: dtWord, val: v, line: line, start: start, end: i})
+			continue
+
+		case ch >= '0' && ch <= '9':
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			toks = append(toks, ddlToken{typ: dtNumber, val: src[start:i], line: line, start: start, end: i})
+			continue
+		}
+
+		toks = append(toks, ddlToken{typ: dtPunct, val: string(ch), line: line, start: start, end: i + 1})
+		i++
+	}
+	return toks, nil
+}
+
+// lexQuoted returns the value of a quoted token and its length, the
+// closing quote is escaped by doubling it
+func lexQuoted(src string, open, closing byte) (string, int, error) {
+	var sb strings.Builder
+	for i := 1; i < len(src); i++ {
+		if src[i] != closing {
+			sb.WriteByte(src[i])
+			continue
+		}
+		if i+1 < len(src) && src[i+1] == closing && open == closing {
+			sb.WriteByte(closing)
+			i++
+			continue
+		}
+		return sb.String(), i + 1, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quoted string")
+}
+
+// dollarTag returns the opening tag of a dollar quoted string eg. $body$
+func dollarTag(src string) (string, bool) {
+	for i := 1; i < len(src); i++ {
+		switch {
+		case src[i] == '$':
+			return src[:i+1], true
+		case !isDDLWordChar(src[i]) || (i == 1 && src[i] >= '0' && src[i] <= '9'):
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// isDDLWordChar returns true for the characters of keywords and
+// unquoted identifiers
+func isDDLWordChar(ch byte) bool {
+	return ch == '_' || ch == '$' ||
+		(ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+		(ch >= '0' && ch <= '9') || ch >= 0x80
+}
+
+// splitDDL splits the tokens of a script into statements
+func splitDDL(toks []ddlToken) [][]ddlToken {
+	var stmts [][]ddlToken
+	start := 0
+	for i, t := range toks {
+		if t.typ == dtPunct && t.val == ";" {
+			if i > start {
+				stmts = append(stmts, toks[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(toks) {
+		stmts = append(stmts, toks[start:])
+	}
+	return stmts
+}
+
+// ddlParser parses a single statement of a script
+type ddlParser struct {
+	src  *DDLSource
+	toks []ddlToken
+	text string
+	pos  int
+}
+
+// statement reads a statement, the ones that don't describe tables
+// are skipped
+func (p *ddlParser) statement() error {
+	switch {
+	case p.keyword("create"):
+		p.keyword("or")
+		p.keyword("replace")
+		p.keyword("global")
+		p.keyword("local")
+		for p.keyword("temp") || p.keyword("temporary") || p.keyword("unlogged") {
+		}
+		switch {
+		case p.keyword("table"):
+			return p.createTable()
+		case p.keyword("unique"):
+			p.keyword("clustered")
+			p.keyword("nonclustered")
+			if p.keyword("index") {
+				return p.createUniqueIndex()
+			}
+		}
+
+	case p.keyword("alter"):
+		switch {
+		case p.keyword("table"):
+			return p.alterTable()
+		case p.keyword("sequence"):
+			return p.alterSequence()
+		}
+
+	case p.keyword("comment"):
+		if p.keyword("on") {
+			return p.comment()
+		}
+
+	case p.keyword("set"):
+		if p.keyword("search_path") {
+			if p.punct("=") || p.keyword("to") {
+				if name, ok := p.name(); ok {
+					p.src.db.Schema = name
+				}
+			}
+		}
+
+	case p.keyword("use"):
+		if name, ok := p.name(); ok {
+			p.src.db.Schema = name
+		}
+	}
+	return nil
+}
+
+// createTable reads a CREATE TABLE statement
+func (p *ddlParser) createTable() error {
+	p.ifNotExists()
+
+	schema, table, err := p.tableName()
+	if err != nil {
+		return err
+	}
+
+	// partitions, typed tables and tables created from a query have no
+	// column list of their own
+	if p.peekKeyword("partition") || p.peekKeyword("of") || p.peekKeyword("as") {
+		return nil
+	}
+	if !p.punct("(") {
+		return p.errorf("expected ( after table %s", table)
+	}
+	p.src.addTable(schema, table)
+
+	for {
+		if p.punct(")") {
+			return nil
+		}
+		if err := p.tableElement(schema, table); err != nil {
+			return err
+		}
+		if p.punct(",") {
+			continue
+		}
+		if !p.punct(")") {
+			return p.errorf("expected , or ) in table %s", table)
+		}
+		return nil
+	}
+}
+
+// alterTable reads the ADD and ALTER COLUMN actions of an ALTER TABLE
+// statement
+func (p *ddlParser) alterTable() error {
+	p.keyword("if")
+	p.keyword("exists")
+	p.keyword("only")
+
+	schema, table, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	p.punct("*")
+
+	for !p.eof() {
+		switch {
+		case p.keyword("add"):
+			p.keyword("column")
+			p.ifNotExists()
+			if err := p.tableElement(schema, table); err != nil {
+				return err
+			}
+		case p.keyword("alter"):
+			p.keyword("column")
+			p.alterColumn(schema, table)
+		}
+		// skip the rest of the action
+		p.skipExpr()
+		p.punct(",")
+	}
+	return nil
+}
+
+// alterColumn reads the SET DEFAULT, DROP DEFAULT, SET NOT NULL and DROP
+// NOT NULL actions of ALTER COLUMN, pg_dump sets the defaults of serial
+// columns with SET DEFAULT nextval(...)
+func (p *ddlParser) alterColumn(schema, table string) {
+	name, ok := p.name()
+	if !ok {
+		return
+	}
+	c, ok := p.src.column(schema, table, name)
+	if !ok {
+		return
+	}
+
+	switch {
+	case p.keyword("set"):
+		switch {
+		case p.keyword("default"):
+			c.Default = p.defaultExpr()
+			if seq := nextvalSequence(c.Default); seq != "" {
+				c.Sequence = seq
+			}
+		case p.keyword("not"):
+			if p.keyword("null") {
+				c.NotNull = true
+			}
+		}
+	case p.keyword("drop"):
+		switch {
+		case p.keyword("default"):
+			c.Default = ""
+		case p.keyword("not"):
+			if p.keyword("null") {
+				c.NotNull = false
+			}
+		}
+	}
+}
+
+// alterSequence reads the OWNED BY clause of an ALTER SEQUENCE statement,
+// the column owning the sequence is set from it like a serial column
+func (p *ddlParser) alterSequence() error {
+	p.keyword("if")
+	p.keyword("exists")
+
+	seq := p.qualifiedName()
+	if len(seq) == 0 {
+		return p.errorf("expected sequence name")
+	}
+
+	for !p.eof() {
+		if !p.keyword("owned") {
+			p.skipValue()
+			continue
+		}
+		if !p.keyword("by") {
+			return p.errorf("expected by after owned of sequence %s", strings.Join(seq, "."))
+		}
+		if p.keyword("none") {
+			return nil
+		}
+
+		names := p.qualifiedName()
+		if len(names) < 2 {
+			return p.errorf("expected table.column after owned by of sequence %s", strings.Join(seq, "."))
+		}
+		schema, table := p.splitName(names[:len(names)-1])
+		if c, ok := p.src.column(schema, table, names[len(names)-1]); ok {
+			c.Sequence = strings.Join(seq, ".")
+		}
+		return nil
+	}
+	return nil
+}
+
+// nextvalSequence returns the sequence of a nextval('...'::regclass)
+// default or an empty string for other defaults
+func nextvalSequence(def string) string {
+	m := nextvalRe.FindStringSubmatch(def)
+	if m == nil {
+		return ""
+	}
+	seq := strings.ReplaceAll(m[1], "''", "'")
+	return strings.ReplaceAll(seq, `"`, "")
+}
+
+var nextvalRe = regexp.MustCompile(`(?i)^nextval\('((?:[^']|'')+)'(?:::regclass)?\)$`)
+
+// createUniqueIndex reads a CREATE UNIQUE INDEX statement, the column
+// of a single column index is a unique key
+func (p *ddlParser) createUniqueIndex() error {
+	p.keyword("concurrently")
+	p.ifNotExists()
+	if !p.peekKeyword("on") {
+		p.qualifiedName()
+	}
+	if !p.keyword("on") {
+		return nil
+	}
+	p.keyword("only")
+
+	schema, table, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if p.keyword("using") {
+		p.next()
+	}
+
+	cols, ok := p.columnList()
+	if !ok || len(cols) != 1 || p.keyword("where") {
+		return nil
+	}
+	if c, ok := p.src.column(schema, table, cols[0]); ok {
+		c.UniqueKey = true
+	}
+	return nil
+}
+
+// comment reads a COMMENT ON TABLE or COMMENT ON COLUMN statement
+func (p *ddlParser) comment() error {
+	isTable := p.keyword("table")
+	if !isTable && !p.keyword("column") {
+		return nil
+	}
+
+	names := p.qualifiedName()
+	if !p.keyword("is") {
+		return nil
+	}
+	t := p.next()
+	if t.typ != dtString {
+		return nil
+	}
+
+	if isTable {
+		schema, table := p.splitName(names)
+		if i, ok := p.src.tmap[(schema + ":" + table)]; ok {
+			p.src.tables[i].Comment = t.val
+		}
+		return nil
+	}
+
+	if len(names) < 2 {
+		return nil
+	}
+	schema, table := p.splitName(names[:len(names)-1])
+	if c, ok := p.src.column(schema, table, names[len(names)-1]); ok {
+		c.Comment = t.val
+	}
+	return nil
+}
+
+// tableElement reads a column or a table constraint
+func (p *ddlParser) tableElement(schema, table string) error {
+	switch {
+	case p.peekKeyword("constraint"), p.peekKeyword("primary"),
+		p.peekKeyword("unique"), p.peekKeyword("foreign"):
+		return p.tableConstraint(schema, table)
+
+	case p.peekKeyword("check"), p.peekKeyword("exclude"), p.peekKeyword("like"),
+		p.peekKeyword("key"), p.peekKeyword("index"), p.peekKeyword("fulltext"),
+		p.peekKeyword("spatial"):
+		p.skipExpr()
+		return nil
+	}
+	return p.columnDef(schema, table)
+}
+
+// columnDef reads a column definition and its constraints
+func (p *ddlParser) columnDef(schema, table string) error {
+	name, ok := p.name()
+	if !ok {
+		return p.errorf("expected column name in table %s", table)
+	}
+	c := DBColumn{Schema: schema, Table: table, Name: name}
+	c.Type, c.Array = p.columnType()
+	c.FullText = c.Type == "tsvector"
+	if serialType(c.Type) {
+		c.Sequence = table + "_" + name + "_seq"
+		if schema != "" {
+			c.Sequence = schema + "." + c.Sequence
+		}
+	}
+
+	var fkName string
+	for !p.eof() && !p.peekPunct(",") && !p.peekPunct(")") {
+		switch {
+		case p.keyword("constraint"):
+			fkName, _ = p.name()
+		case p.keyword("not"):
+			if p.keyword("null") {
+				c.NotNull = true
+			}
+		case p.keyword("null"):
+		case p.keyword("primary"):
+			p.keyword("key")
+			c.PrimaryKey, c.UniqueKey, c.NotNull = true, true, true
+		case p.keyword("unique"):
+			p.keyword("key")
+			c.UniqueKey = true
+		case p.keyword("references"):
+			fk, err := p.references(schema, table, []string{name}, fkName)
+			if err != nil {
+				return err
+			}
+			p.src.fks = append(p.src.fks, fk...)
+		case p.keyword("generated"):
+			p.generated(&c)
+		case p.keyword("default"):
+			c.Default = p.defaultExpr()
+			if seq := nextvalSequence(c.Default); seq != "" {
+				c.Sequence = seq
+			}
+		case p.keyword("auto_increment"), p.keyword("autoincrement"):
+			c.Identity = "by default"
+		case p.keyword("identity"):
+			// SQL Server identity columns can only be set with IDENTITY_INSERT
+			c.Identity = "always"
+			if p.peekPunct("(") {
+				p.skipValue()
+			}
+		case p.keyword("comment"):
+			if t := p.next(); t.typ == dtString {
+				c.Comment = t.val
+			}
+		case p.keyword("collate"), p.keyword("check"), p.keyword("as"):
+			p.skipValue()
+		default:
+			p.skipValue()
+		}
+	}
+
+	p.src.addColumn(c)
+	return nil
+}
+
+// tableConstraint reads a primary key, unique or foreign key constraint
+// of a table
+func (p *ddlParser) tableConstraint(schema, table string) error {
+	var name string
+	if p.keyword("constraint") {
+		name, _ = p.name()
+	}
+
+	switch {
+	case p.keyword("primary"):
+		p.keyword("key")
+		p.keyword("clustered")
+		p.keyword("nonclustered")
+		// the columns of a composite key are not unique on their own
+		cols, _ := p.columnList()
+		for _, cn := range cols {
+			if c, ok := p.src.column(schema, table, cn); ok {
+				c.PrimaryKey, c.NotNull = true, true
+				c.UniqueKey = c.UniqueKey || len(cols) == 1
+			}
+		}
+
+	case p.keyword("unique"):
+		if p.keyword("key") || p.keyword("index") {
+			if !p.peekPunct("(") {
+				p.name()
+			}
+		}
+		p.keyword("clustered")
+		p.keyword("nonclustered")
+		cols, _ := p.columnList()
+		if len(cols) == 1 {
+			if c, ok := p.src.column(schema, table, cols[0]); ok {
+				c.UniqueKey = true
+			}
+		}
+
+	case p.keyword("foreign"):
+		p.keyword("key")
+		cols, ok := p.columnList()
+		if !ok {
+			return p.errorf("expected foreign key columns in table %s", table)
+		}
+		if !p.keyword("references") {
+			return p.errorf("expected references in foreign key of table %s", table)
+		}
+		fk, err := p.references(schema, table, cols, name)
+		if err != nil {
+			return err
+		}
+		p.src.fks = append(p.src.fks, fk...)
+	}
+
+	p.skipExpr()
+	return nil
+}
+
+// references reads the table and columns a foreign key points to along
+// with its ON DELETE action
+func (p *ddlParser) references(schema, table string, cols []string, name string) ([]DBForeignKey, error) {
+	fs, ft, err := p.tableName()
+	if err != nil {
+		return nil, err
+	}
+
+	fcols, _ := p.columnList()
+	if len(fcols) != 0 && len(fcols) != len(cols) {
+		return nil, p.errorf("foreign key of %s has %d columns but references %d", table, len(cols), len(fcols))
+	}
+	if name == "" {
+		name = table + "_" + strings.Join(cols, "_") + "_fkey"
+	}
+
+	var onDelete string
+	for {
+		switch {
+		case p.keyword("match"):
+			p.next()
+			continue
+		case p.keyword("on"):
+			isDelete := p.keyword("delete")
+			if !isDelete {
+				p.keyword("update")
+			}
+			var action string
+			switch {
+			case p.keyword("cascade"):
+				action = "CASCADE"
+			case p.keyword("restrict"):
+				action = "RESTRICT"
+			case p.keyword("set"):
+				if p.keyword("null") {
+					action = "SET NULL"
+				} else {
+					p.keyword("default")
+					action = "SET DEFAULT"
+				}
+				p.columnList()
+			case p.keyword("no"):
+				p.keyword("action")
+				action = "NO ACTION"
+			}
+			if isDelete {
+				onDelete = action
+			}
+			continue
+		}
+		break
+	}
+
+	fks := make([]DBForeignKey, len(cols))
+	for i, cn := range cols {
+		fks[i] = DBForeignKey{
+			Name:       name,
+			Schema:     schema,
+			Table:      table,
+			Column:     cn,
+			FKeySchema: fs,
+			FKeyTable:  ft,
+			OnDelete:   onDelete,
+		}
+		if len(fcols) != 0 {
+			fks[i].FKeyCol = fcols[i]
+		}
+	}
+	return fks, nil
+}
+
+// generated reads the GENERATED clause of a column, identity columns
+// are not generated columns
+func (p *ddlParser) generated(c *DBColumn) {
+	identity := "always"
+	if p.keyword("by") {
+		p.keyword("default")
+		identity = "by default"
+	} else {
+		p.keyword("always")
+	}
+	if !p.keyword("as") {
+		p.skipValue()
+		return
+	}
+	if p.peekKeyword("identity") {
+		c.Identity = identity
+		p.skipValue()
+		return
+	}
+	if !p.peekPunct("(") {
+		return
+	}
+
+	start := p.toks[p.pos].end
+	p.skipValue()
+	end := p.toks[p.pos-1].start
+	c.GenExpr = strings.TrimSpace(p.text[start:end])
+
+	c.Generated = "stored"
+	if p.keyword("virtual") {
+		c.Generated = "virtual"
+	} else {
+		p.keyword("stored")
+	}
+}
+
+// defaultExpr reads the expression of a DEFAULT clause, it ends at the
+// next column constraint
+func (p *ddlParser) defaultExpr() string {
+	if p.eof() {
+		return ""
+	}
+	start, end := p.toks[p.pos].start, -1
+	for !p.eof() && !p.peekPunct(",") && !p.peekPunct(")") {
+		t := p.toks[p.pos]
+		if t.typ == dtWord && (columnTypeEnd(t.val) || strings.EqualFold(t.val, "on")) {
+			break
+		}
+		p.skipValue()
+		end = p.toks[p.pos-1].end
+	}
+	if end == -1 {
+		return ""
+	}
+	return strings.TrimSpace(p.text[start:end])
+}
+
+// serialType returns true for the Postgres types of integer columns set
+// from a sequence they own
+func serialType(typ string) bool {
+	switch strings.ToLower(typ) {
+	case "serial", "serial4", "bigserial", "serial8", "smallserial", "serial2":
+		return true
+	}
+	return false
+}
+
+// columnType reads the type of a column, it ends at the first column
+// constraint. Array types end with [] like the ones of the catalog
+func (p *ddlParser) columnType() (string, bool) {
+	var sb strings.Builder
+	var array bool
+
+loop:
+	for !p.eof() && !p.peekPunct(",") && !p.peekPunct(")") {
+		t := p.toks[p.pos]
+		switch {
+		case t.typ == dtWord && columnTypeEnd(t.val):
+			break loop
+
+		case t.typ == dtWord && strings.EqualFold(t.val, "array"):
+			array = true
+			p.pos++
+			if p.peekPunct("[") {
+				p.skipValue()
+			}
+
+		case t.typ == dtPunct && t.val == "[":
+			array = true
+			p.skipValue()
+
+		case t.typ == dtPunct && t.val == "(":
+			start := t.start
+			p.skipValue()
+			sb.WriteString(p.text[start:p.toks[p.pos-1].end])
+
+		case t.typ == dtPunct && t.val == ".":
+			// a type in a schema eg. public.citext
+			sb.WriteString(".")
+			p.pos++
+			if p.pos < len(p.toks) {
+				sb.WriteString(p.toks[p.pos].val)
+				p.pos++
+			}
+
+		default:
+			if sb.Len() != 0 {
+				sb.WriteByte(' ')
+			}
+			sb.WriteString(t.val)
+			p.pos++
+		}
+	}
+
+	typ := strings.TrimSpace(sb.String())
+	if array {
+		typ += "[]"
+	}
+	return typ, array
+}
+
+// columnTypeEnd returns true for the keywords that end the type of a
+// column
+func columnTypeEnd(word string) bool {
+	switch strings.ToLower(word) {
+	case "constraint", "not", "null", "primary", "unique", "references",
+		"default", "check", "generated", "collate", "comment",
+		"auto_increment", "identity", "autoincrement":
+		return true
+	}
+	return false
+}
+
+// tableName reads a table name that can be schema qualified
+func (p *ddlParser) tableName() (string, string, error) {
+	names := p.qualifiedName()
+	if len(names) == 0 {
+		return "", "", p.errorf("expected table name")
+	}
+	schema, table := p.splitName(names)
+	return schema, table, nil
+}
+
+// splitName returns the schema and name of a qualified name, a three
+// part name has the database first
+func (p *ddlParser) splitName(names []string) (string, string) {
+	switch len(names) {
+	case 0:
+		return "", ""
+	case 1:
+		return p.src.db.Schema, names[0]
+	}
+	return names[len(names)-2], names[len(names)-1]
+}
+
+// qualifiedName reads a name made of parts separated by dots
+func (p *ddlParser) qualifiedName() []string {
+	var names []string
+	for {
+		name, ok := p.name()
+		if !ok {
+			return names
+		}
+		names = append(names, name)
+		if !p.punct(".") {
+			return names
+		}
+	}
+}
+
+// columnList reads a list of names in parentheses, expressions in the
+// list are skipped
+func (p *ddlParser) columnList() ([]string, bool) {
+	if !p.punct("(") {
+		return nil, false
+	}
+	var cols []string
+	for !p.eof() {
+		if p.punct(")") {
+			return cols, true
+		}
+		if name, ok := p.name(); ok {
+			cols = append(cols, name)
+		}
+		// sort order, operator classes and expressions
+		for !p.eof() && !p.peekPunct(",") && !p.peekPunct(")") {
+			p.skipValue()
+		}
+		p.punct(",")
+	}
+	return cols, true
+}
+
+// ifNotExists reads an optional IF NOT EXISTS
+func (p *ddlParser) ifNotExists() {
+	if p.keyword("if") {
+		p.keyword("not")
+		p.keyword("exists")
+	}
+}
+
+// name reads an identifier
+func (p *ddlParser) name() (string, bool) {
+	if p.eof() {
+		return "", false
+	}
+	t := p.toks[p.pos]
+	if t.typ != dtWord && t.typ != dtIdent {
+		return "", false
+	}
+	p.pos++
+	return t.val, true
+}
+
+// skipValue skips a token, a parenthesized or bracketed token is skipped
+// along with everything up to its closing token
+func (p *ddlParser) skipValue() {
+	if p.eof() {
+		return
+	}
+	t := p.toks[p.pos]
+	p.pos++
+	if t.typ != dtPunct || (t.val != "(" && t.val != "[") {
+		return
+	}
+
+	depth := 1
+	for !p.eof() && depth > 0 {
+		t := p.toks[p.pos]
+		p.pos++
+		if t.typ != dtPunct {
+			continue
+		}
+		switch t.val {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			depth--
+		}
+	}
+}
+
+// skipExpr skips everything up to the next comma or closing parenthesis
+// that is not nested
+func (p *ddlParser) skipExpr() {
+	for !p.eof() && !p.peekPunct(",") && !p.peekPunct(")") {
+		p.skipValue()
+	}
+}
+
+// keyword reads a keyword when it is next
+func (p *ddlParser) keyword(word string) bool {
+	if p.peekKeyword(word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// peekKeyword returns true when the next token is a keyword
+func (p *ddlParser) peekKeyword(word string) bool {
+	if p.eof() {
+		return false
+	}
+	t := p.toks[p.pos]
+	return t.typ == dtWord && strings.EqualFold(t.val, word)
+}
+
+// punct reads a punctuation character when it is next
+func (p *ddlParser) punct(v string) bool {
+	if p.peekPunct(v) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// peekPunct returns true when the next token is a punctuation character
+func (p *ddlParser) peekPunct(v string) bool {
+	if p.eof() {
+		return false
+	}
+	t := p.toks[p.pos]
+	return t.typ == dtPunct && t.val == v
+}
+
+// next returns the next token
+func (p *ddlParser) next() ddlToken {
+	if p.eof() {
+		return ddlToken{typ: dtPunct}
+	}
+	p.pos++
+	return p.toks[p.pos-1]
+}
+
+// eof returns true at the end of the statement
+func (p *ddlParser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+// errorf returns an error at the line of the current token
+func (p *ddlParser) errorf(format string, args ...interface{}) error {
+	line := 0
+	switch {
+	case p.pos < len(p.toks):
+		line = p.toks[p.pos].line
+	case len(p.toks) != 0:
+		line = p.toks[len(p.toks)-1].line
+	}
+	return fmt.Errorf("ddl: line %d: %s", line, fmt.Sprintf(format, args...))
+}
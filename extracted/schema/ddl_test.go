@@ -0,0 +1,89 @@
+package schema
+
+import "testing"
+
+// pgDump is a schema script in the form written by pg_dump --schema-only
+const pgDump = `
+SET search_path = public;
+
+CREATE TABLE public.users (
+    id integer NOT NULL,
+    email text NOT NULL
+);
+
+CREATE SEQUENCE public.users_id_seq AS integer START WITH 1 INCREMENT BY 1 NO MINVALUE NO MAXVALUE CACHE 1;
+ALTER SEQUENCE public.users_id_seq OWNED BY public.users.id;
+ALTER TABLE ONLY public.users ALTER COLUMN id SET DEFAULT nextval('public.users_id_seq'::regclass);
+ALTER TABLE ONLY public.users ADD CONSTRAINT users_pkey PRIMARY KEY (id);
+
+CREATE TABLE public.memberships (
+    user_id integer NOT NULL,
+    group_id integer NOT NULL,
+    role text
+);
+ALTER TABLE ONLY public.memberships ADD CONSTRAINT memberships_pkey PRIMARY KEY (user_id, group_id);
+ALTER TABLE ONLY public.memberships ALTER COLUMN role SET DEFAULT 'member'::text, ALTER COLUMN role SET NOT NULL;
+
+CREATE TABLE public.events (
+    id bigint DEFAULT nextval('events_id_seq'::regclass) NOT NULL,
+    code text
+);
+CREATE SEQUENCE public.codes_seq;
+ALTER SEQUENCE public.codes_seq OWNED BY NONE;
+`
+
+// ddlColumn returns a column of a table parsed from a script
+func ddlColumn(t *testing.T, di *DBInfo, table, name string) *DBColumn {
+	t.Helper()
+	c, err := di.GetColumn("public", table, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestParseDDLSequences(t *testing.T) {
+	di, err := ParseDDL([]byte(pgDump), "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := ddlColumn(t, di, "users", "id")
+	if id.Sequence != "public.users_id_seq" {
+		t.Errorf("got sequence %q", id.Sequence)
+	}
+	if id.Default != "nextval('public.users_id_seq'::regclass)" {
+		t.Errorf("got default %q", id.Default)
+	}
+	if !id.PrimaryKey || !id.UniqueKey {
+		t.Errorf("single column primary key %s not unique", id.Name)
+	}
+
+	if c := ddlColumn(t, di, "events", "id"); c.Sequence != "events_id_seq" {
+		t.Errorf("got inline sequence %q", c.Sequence)
+	}
+
+	role := ddlColumn(t, di, "memberships", "role")
+	if role.Default != "'member'::text" || !role.NotNull {
+		t.Errorf("got default %q and not null %t", role.Default, role.NotNull)
+	}
+}
+
+func TestParseDDLCompositeKey(t *testing.T) {
+	di, err := ParseDDL([]byte(pgDump), "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"user_id", "group_id"} {
+		c := ddlColumn(t, di, "memberships", name)
+		if !c.PrimaryKey || c.UniqueKey {
+			t.Errorf("%s: got primary key %t and unique key %t", name, c.PrimaryKey, c.UniqueKey)
+		}
+	}
+}
+
+func TestParseDDLOwnedByError(t *testing.T) {
+	if _, err := ParseDDL([]byte(`ALTER SEQUENCE s OWNED BY x;`), "postgres"); err == nil {
+		t.Error("want an error for an owner without a table")
+	}
+}
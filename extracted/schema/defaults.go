@@ -0,0 +1,100 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBDefault holds the default value of a column and how the database
+// generates it, Identity is either "always" or "by default"
+type DBDefault struct {
+	Schema   string
+	Table    string
+	Column   string
+	Default  string
+	Identity string
+	Sequence string
+}
+
+// DiscoverDefaults returns the columns of a database with a default
+// value, the identity and auto increment columns and the serial columns
+// along with the sequence they own
+func DiscoverDefaults(ctx context.Context, db Querier, dbtype string) ([]DBDefault, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlDefaultsStmt
+	case "mssql":
+		sqlStmt = mssqlDefaultsStmt
+	case "bigquery":
+		sqlStmt = bigqueryDefaultsStmt
+	case "snowflake":
+		sqlStmt = snowflakeDefaultsStmt
+	case "clickhouse":
+		sqlStmt = clickhouseDefaultsStmt
+	case "duckdb":
+		sqlStmt = duckdbDefaultsStmt
+	case "redshift":
+		sqlStmt = redshiftDefaultsStmt
+	case "sqlite":
+		sqlStmt = sqliteDefaultsStmt
+	case "trino":
+		// the connectors leave the column defaults of system.jdbc empty
+		return nil, nil
+	default:
+		sqlStmt = postgresDefaultsStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching column defaults: %s", err)
+	}
+	defer rows.Close()
+
+	var defs []DBDefault
+
+	for rows.Next() {
+		var d DBDefault
+
+		err = rows.Scan(&d.Schema, &d.Table, &d.Column, &d.Default, &d.Identity, &d.Sequence)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, d)
+	}
+
+	return defs, rows.Err()
+}
+
+// addDefaults sets the defaults, identity and sequence of columns
+func (di *DBInfo) addDefaults(defs []DBDefault) {
+	for _, d := range defs {
+		c, err := di.GetColumn(d.Schema, d.Table, d.Column)
+		if err != nil {
+			continue
+		}
+		c.Default = d.Default
+		c.Identity = d.Identity
+		c.Sequence = d.Sequence
+	}
+}
+
+// AutoGenerated returns true if the database generates the value of
+// the column, an identity, auto increment, serial or generated column
+func (col DBColumn) AutoGenerated() bool {
+	return col.Identity != "" || col.Sequence != "" || col.Generated != ""
+}
+
+// ReturningColumns returns the columns of a table the database sets when
+// an insert leaves them out, the generated columns and the ones with a
+// default. Reading them back after an insert needs a RETURNING clause
+func (ti *DBTable) ReturningColumns() []DBColumn {
+	var cols []DBColumn
+	for _, c := range ti.Columns {
+		if c.AutoGenerated() || c.Default != "" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
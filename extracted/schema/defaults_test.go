@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverDefaults(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "shop"}},
+		postgresColumnsStmt: {
+			columnRow("public", "orders", "id", "bigint", true, true),
+			columnRow("public", "orders", "number", "integer", true, false),
+			columnRow("public", "orders", "created_at", "timestamptz", true, false),
+			columnRow("public", "orders", "note", "text", false, false),
+		},
+		postgresDefaultsStmt: {
+			{"public", "orders", "id", "", "always", ""},
+			{"public", "orders", "number", "nextval('orders_number_seq'::regclass)", "", "public.orders_number_seq"},
+			{"public", "orders", "created_at", "now()", "", ""},
+			{"public", "missing", "id", "1", "", ""},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := di.GetTable("public", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]struct {
+		def, identity, seq string
+		auto               bool
+	}{
+		"id":         {"", "always", "", true},
+		"number":     {"nextval('orders_number_seq'::regclass)", "", "public.orders_number_seq", true},
+		"created_at": {"now()", "", "", false},
+		"note":       {"", "", "", false},
+	}
+	for _, c := range tbl.Columns {
+		w := want[c.Name]
+		if c.Default != w.def || c.Identity != w.identity || c.Sequence != w.seq || c.AutoGenerated() != w.auto {
+			t.Errorf("%s: got default %q identity %q sequence %q auto %v", c.Name, c.Default, c.Identity, c.Sequence, c.AutoGenerated())
+		}
+	}
+
+	var ret, ins []string
+	for _, c := range tbl.ReturningColumns() {
+		ret = append(ret, c.Name)
+	}
+	for _, c := range tbl.InsertColumns() {
+		ins = append(ins, c.Name)
+	}
+	if got := strings.Join(ret, " "); got != "id number created_at" {
+		t.Errorf("got returning columns %s", got)
+	}
+	if got := strings.Join(ins, " "); got != "number created_at note" {
+		t.Errorf("got insert columns %s", got)
+	}
+}
+
+func TestDiscoverDefaultsDialects(t *testing.T) {
+	for dbType, stmt := range map[string]string{
+		"mysql":   mysqlDefaultsStmt,
+		"mariadb": mysqlDefaultsStmt,
+		"mssql":   mssqlDefaultsStmt,
+		"sqlite":  sqliteDefaultsStmt,
+		"":        postgresDefaultsStmt,
+	} {
+		q := &routeQuerier{}
+		if _, err := DiscoverDefaults(context.Background(), q, dbType); err != nil {
+			t.Fatal(err)
+		}
+		if !q.ran(stmt) {
+			t.Errorf("%q: defaults not read with the statement of the dialect", dbType)
+		}
+	}
+}
+
+func TestParseDDLDefaults(t *testing.T) {
+	tests := []struct {
+		dbType, ddl             string
+		col, def, identity, seq string
+	}{
+		{"postgres", `CREATE TABLE t (id serial PRIMARY KEY);`, "id", "", "", "public.t_id_seq"},
+		{"postgres", `CREATE TABLE app.t (id bigserial);`, "id", "", "", "app.t_id_seq"},
+		{"postgres", `CREATE TABLE t (id int GENERATED ALWAYS AS IDENTITY);`, "id", "", "always", ""},
+		{"postgres", `CREATE TABLE t (id int GENERATED BY DEFAULT AS IDENTITY);`, "id", "", "by default", ""},
+		{"postgres", `CREATE TABLE t (at timestamptz DEFAULT now() NOT NULL);`, "at", "now()", "", ""},
+		{"postgres", `CREATE TABLE t (n numeric DEFAULT (1 + 2), m int);`, "n", "(1 + 2)", "", ""},
+		{"mysql", "CREATE TABLE t (id int NOT NULL AUTO_INCREMENT, PRIMARY KEY (id));", "id", "", "by default", ""},
+		{"sqlite", `CREATE TABLE t (id integer PRIMARY KEY AUTOINCREMENT);`, "id", "", "by default", ""},
+		{"mssql", `CREATE TABLE t (id int IDENTITY(1,1) NOT NULL);`, "id", "", "always", ""},
+	}
+	for _, tt := range tests {
+		di, err := ParseDDL([]byte(tt.ddl), tt.dbType)
+		if err != nil {
+			t.Errorf("%s: %v", tt.ddl, err)
+			continue
+		}
+		c := di.Tables[0].Columns[0]
+		for _, cc := range di.Tables[0].Columns {
+			if cc.Name == tt.col {
+				c = cc
+			}
+		}
+		if c.Default != tt.def || c.Identity != tt.identity || c.Sequence != tt.seq {
+			t.Errorf("%s: got default %q identity %q sequence %q", tt.ddl, c.Default, c.Identity, c.Sequence)
+		}
+	}
+}
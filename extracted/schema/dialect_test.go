@@ -0,0 +1,258 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// routeQuerier returns the rows set for a statement and no rows for the
+// statements it has none for, it keeps the statements it was given
+type routeQuerier struct {
+	mu      sync.Mutex
+	rows    map[string][][]interface{}
+	queries []string
+}
+
+func (q *routeQuerier) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = append(q.queries, query)
+	return &routeRows{rows: q.rows[query], i: -1}, nil
+}
+
+func (q *routeQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queries = append(q.queries, query)
+	return &routeRows{rows: q.rows[query]}
+}
+
+// ran returns true if the statement was run
+func (q *routeQuerier) ran(stmt string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, s := range q.queries {
+		if s == stmt {
+			return true
+		}
+	}
+	return false
+}
+
+// routeRows scans the values of a row into destinations of their type or
+// of a type they convert to, nil leaves the destination unset
+type routeRows struct {
+	rows [][]interface{}
+	i    int
+}
+
+func (r *routeRows) Next() bool {
+	r.i++
+	return r.i < len(r.rows)
+}
+
+func (r *routeRows) Scan(dest ...interface{}) error {
+	if r.i < 0 {
+		r.i = 0
+	}
+	if r.i >= len(r.rows) {
+		return sql.ErrNoRows
+	}
+	row := r.rows[r.i]
+	if len(row) != len(dest) {
+		return fmt.Errorf("scan %d values into %d", len(row), len(dest))
+	}
+	for i, v := range row {
+		if v == nil {
+			continue
+		}
+		if s, ok := dest[i].(sql.Scanner); ok {
+			if err := s.Scan(v); err != nil {
+				return err
+			}
+			continue
+		}
+		d := reflect.ValueOf(dest[i]).Elem()
+		rv := reflect.ValueOf(v)
+		if !rv.Type().ConvertibleTo(d.Type()) {
+			return fmt.Errorf("cannot scan %T into %s", v, d.Type())
+		}
+		d.Set(rv.Convert(d.Type()))
+	}
+	return nil
+}
+
+func (r *routeRows) Err() error   { return nil }
+func (r *routeRows) Close() error { return nil }
+
+// columnRow returns a row of a columns statement
+func columnRow(schema, table, name, typ string, notNull, pk bool, fk ...string) []interface{} {
+	row := []interface{}{schema, table, name, typ, notNull, pk, false, false, false, "", "", "", "", ""}
+	if len(fk) == 3 {
+		row[9], row[10], row[11] = fk[0], fk[1], fk[2]
+	}
+	return row
+}
+
+func TestGetDBInfoMySQL(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "users", "email", "varchar", true, false),
+			columnRow("shop", "orders", "id", "bigint", true, true),
+			columnRow("shop", "orders", "user_id", "bigint", false, false, "shop", "users", "id"),
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "mysql", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(mysqlColumnsStmt) || q.ran(postgresColumnsStmt) {
+		t.Errorf("mysql columns not read with the mysql statement")
+	}
+	if di.Type != "mysql" || di.Version != 80022 || di.Schema != "shop" {
+		t.Errorf("got database %s %d %s", di.Type, di.Version, di.Schema)
+	}
+
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("orders", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetDBInfoMariaDB(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo:        {{100500, "shop", "shop"}},
+		mysqlColumnsStmt: {columnRow("shop", "users", "id", "bigint", true, true)},
+	}}
+	if _, err := GetDBInfoFrom(context.Background(), q, "mariadb", nil); err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(mysqlColumnsStmt) {
+		t.Error("mariadb columns not read with the mysql statement")
+	}
+}
+
+func TestGetDBInfoError(t *testing.T) {
+	// the info row is missing
+	q := &routeQuerier{}
+	if _, err := GetDBInfoFrom(context.Background(), q, "mysql", nil); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestGetDBInfoSQLite(t *testing.T) {
+	fk := columnRow("main", "posts", "user_id", "integer", true, false, "main", "users", "id")
+	fk[12] = "CASCADE"
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		sqliteInfo: {{3039, "main", "main"}},
+		sqliteColumnsStmt: {
+			columnRow("main", "users", "id", "integer", true, true),
+			columnRow("main", "posts", "id", "integer", true, true),
+			fk,
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "sqlite", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(sqliteColumnsStmt) {
+		t.Error("sqlite columns not read with the sqlite statement")
+	}
+	if len(di.Functions) != 0 {
+		t.Errorf("got functions %v", di.Functions)
+	}
+
+	c, err := di.GetColumn("main", "posts", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FKeyTable != "users" || c.FKeyOnDelete != "CASCADE" {
+		t.Errorf("got foreign key %s on delete %s", c.FKeyTable, c.FKeyOnDelete)
+	}
+}
+
+func TestGetDBInfoMSSQL(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mssqlInfo: {{15, "dbo", "shop"}},
+		mssqlColumnsStmt: {
+			columnRow("dbo", "users", "id", "int", true, true),
+			columnRow("dbo", "users", "name", "NVARCHAR", false, false),
+			columnRow("dbo", "users", "active", "bit", true, false),
+		},
+		mssqlFunctionsStmt: {
+			{"1", "dbo", "full_name", "nvarchar", 1, "id", "int", "IN"},
+			{"1", "dbo", "full_name", "nvarchar", 2, "", "nvarchar", "OUT"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "mssql", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, want := range map[string]string{"id": "integer", "name": "character varying", "active": "boolean"} {
+		c, err := di.GetColumn("dbo", "users", col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Type, want)
+		}
+	}
+
+	if len(di.Functions) != 1 {
+		t.Fatalf("got functions %v", di.Functions)
+	}
+	fn := di.Functions[0]
+	if fn.Type != "character varying" || len(fn.Inputs) != 1 || fn.Inputs[0].Type != "integer" || len(fn.Outputs) != 1 {
+		t.Errorf("got function %s", fn.String())
+	}
+}
+
+func TestMSSQLType(t *testing.T) {
+	for in, want := range map[string]string{
+		"DATETIME2":        "timestamp without time zone",
+		"uniqueidentifier": "uuid",
+		"varbinary":        "bytea",
+		"geography":        "geography",
+	} {
+		if got := mssqlType(in); got != want {
+			t.Errorf("mssqlType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestGetDBInfoCockroach(t *testing.T) {
+	for _, dbType := range []string{"cockroach", "cockroachdb"} {
+		q := &routeQuerier{rows: map[string][][]interface{}{
+			postgresInfo:         {{130000, "public", "defaultdb"}},
+			cockroachColumnsStmt: {columnRow("public", "users", "id", "bigint", true, true)},
+		}}
+
+		di, err := GetDBInfoFrom(context.Background(), q, dbType, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(di.Tables) != 1 {
+			t.Errorf("%s: got tables %v", dbType, di.Tables)
+		}
+		for _, stmt := range []string{cockroachColumnsStmt, cockroachFunctionsStmt, cockroachIndexesStmt} {
+			if !q.ran(stmt) {
+				t.Errorf("%s: cockroach statement not run:\n%.60s", dbType, stmt)
+			}
+		}
+		if q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+			t.Errorf("%s: postgres catalog statement run", dbType)
+		}
+	}
+}
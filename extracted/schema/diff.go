@@ -0,0 +1,315 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// DBInfoDiff holds the changes between two DBInfo snapshots
+type DBInfoDiff struct {
+	TablesAdded    []DBTable
+	TablesRemoved  []DBTable
+	ColumnsAdded   []DBColumn
+	ColumnsRemoved []DBColumn
+	ColumnsChanged []DBColumnChange
+	FKeysAdded     []DBColumn
+	FKeysDropped   []DBColumn
+
+	// TablesChanged are the tables that became or stopped being views
+	// and the views whose definition changed
+	TablesChanged []DBTableChange
+
+	IndexesAdded     []DBIndex
+	IndexesDropped   []DBIndex
+	FunctionsAdded   []DBFunction
+	FunctionsRemoved []DBFunction
+}
+
+// DBTableChange holds the old and new versions of a changed table
+type DBTableChange struct {
+	Old DBTable
+	New DBTable
+}
+
+// DBColumnChange holds the old and new versions of a changed column
+type DBColumnChange struct {
+	Old DBColumn
+	New DBColumn
+}
+
+// Diff returns the changes needed to go from the old to the new DBInfo
+func Diff(old, new *DBInfo) *DBInfoDiff {
+	d := &DBInfoDiff{}
+
+	ot := tablesByKey(old)
+	nt := tablesByKey(new)
+
+	for _, k := range sortedKeys(nt) {
+		t := nt[k]
+		o, ok := ot[k]
+		if !ok {
+			d.TablesAdded = append(d.TablesAdded, t)
+			for _, c := range t.Columns {
+				if c.FKeyTable != "" {
+					d.FKeysAdded = append(d.FKeysAdded, c)
+				}
+			}
+			continue
+		}
+		if tableChanged(o, t) {
+			d.TablesChanged = append(d.TablesChanged, DBTableChange{Old: o, New: t})
+		}
+		d.diffColumns(o, t)
+		d.diffIndexes(o, t)
+	}
+
+	for _, k := range sortedKeys(ot) {
+		t := ot[k]
+		if _, ok := nt[k]; ok {
+			continue
+		}
+		d.TablesRemoved = append(d.TablesRemoved, t)
+		for _, c := range t.Columns {
+			if c.FKeyTable != "" {
+				d.FKeysDropped = append(d.FKeysDropped, c)
+			}
+		}
+	}
+
+	d.diffFunctions(old, new)
+	return d
+}
+
+// diffIndexes adds the indexes created and dropped between two versions
+// of a table, an index whose definition changed is dropped and created
+func (d *DBInfoDiff) diffIndexes(old, new DBTable) {
+	oi := indexesByKey(old)
+	ni := indexesByKey(new)
+
+	for _, idx := range new.Indexes {
+		if _, ok := oi[indexKey(idx)]; !ok {
+			d.IndexesAdded = append(d.IndexesAdded, idx)
+		}
+	}
+	for _, idx := range old.Indexes {
+		if _, ok := ni[indexKey(idx)]; !ok {
+			d.IndexesDropped = append(d.IndexesDropped, idx)
+		}
+	}
+}
+
+// diffFunctions adds the functions created and dropped, a function whose
+// signature changed is dropped and created
+func (d *DBInfoDiff) diffFunctions(old, new *DBInfo) {
+	of := functionsByKey(old)
+	nf := functionsByKey(new)
+
+	if new != nil {
+		for _, fn := range new.Functions {
+			if _, ok := of[functionKey(fn)]; !ok {
+				d.FunctionsAdded = append(d.FunctionsAdded, fn)
+			}
+		}
+	}
+	if old != nil {
+		for _, fn := range old.Functions {
+			if _, ok := nf[functionKey(fn)]; !ok {
+				d.FunctionsRemoved = append(d.FunctionsRemoved, fn)
+			}
+		}
+	}
+}
+
+// diffColumns adds the column changes between two versions of a table
+func (d *DBInfoDiff) diffColumns(old, new DBTable) {
+	for _, c := range sortedColumns(new) {
+		o, ok := old.getColumn(c.Name)
+		if !ok {
+			d.ColumnsAdded = append(d.ColumnsAdded, c)
+			if c.FKeyTable != "" {
+				d.FKeysAdded = append(d.FKeysAdded, c)
+			}
+			continue
+		}
+
+		if columnChanged(o, c) {
+			d.ColumnsChanged = append(d.ColumnsChanged, DBColumnChange{Old: o, New: c})
+		}
+
+		if !sameFKey(o, c) {
+			if o.FKeyTable != "" {
+				d.FKeysDropped = append(d.FKeysDropped, o)
+			}
+			if c.FKeyTable != "" {
+				d.FKeysAdded = append(d.FKeysAdded, c)
+			}
+		}
+	}
+
+	for _, c := range sortedColumns(old) {
+		if _, ok := new.getColumn(c.Name); ok {
+			continue
+		}
+		d.ColumnsRemoved = append(d.ColumnsRemoved, c)
+		if c.FKeyTable != "" {
+			d.FKeysDropped = append(d.FKeysDropped, c)
+		}
+	}
+}
+
+// Empty returns true if there are no changes
+func (d *DBInfoDiff) Empty() bool {
+	return len(d.TablesAdded) == 0 &&
+		len(d.TablesRemoved) == 0 &&
+		len(d.ColumnsAdded) == 0 &&
+		len(d.ColumnsRemoved) == 0 &&
+		len(d.ColumnsChanged) == 0 &&
+		len(d.FKeysAdded) == 0 &&
+		len(d.FKeysDropped) == 0 &&
+		len(d.TablesChanged) == 0 &&
+		len(d.IndexesAdded) == 0 &&
+		len(d.IndexesDropped) == 0 &&
+		len(d.FunctionsAdded) == 0 &&
+		len(d.FunctionsRemoved) == 0
+}
+
+// GraphChanged returns true if the changes affect the relationship graph
+// built by NewDBSchema (tables, functions, foreign keys or relationship
+// cardinality, which unique indexes decide)
+func (d *DBInfoDiff) GraphChanged() bool {
+	if len(d.TablesAdded) != 0 || len(d.TablesRemoved) != 0 ||
+		len(d.FKeysAdded) != 0 || len(d.FKeysDropped) != 0 ||
+		len(d.FunctionsAdded) != 0 || len(d.FunctionsRemoved) != 0 {
+		return true
+	}
+
+	for _, idxs := range [][]DBIndex{d.IndexesAdded, d.IndexesDropped} {
+		for _, idx := range idxs {
+			if idx.Unique {
+				return true
+			}
+		}
+	}
+
+	for _, c := range d.ColumnsChanged {
+		if c.Old.UniqueKey != c.New.UniqueKey ||
+			c.Old.PrimaryKey != c.New.PrimaryKey {
+			return true
+		}
+	}
+	return false
+}
+
+// Breaking returns true if the changes can break existing queries
+// (tables, columns, functions or foreign keys removed or column types
+// changed)
+func (d *DBInfoDiff) Breaking() bool {
+	if len(d.TablesRemoved) != 0 || len(d.ColumnsRemoved) != 0 ||
+		len(d.FKeysDropped) != 0 || len(d.FunctionsRemoved) != 0 {
+		return true
+	}
+
+	for _, c := range d.ColumnsChanged {
+		if c.Old.Type != c.New.Type || c.Old.Array != c.New.Array ||
+			(!c.Old.NotNull && c.New.NotNull) {
+			return true
+		}
+	}
+	return false
+}
+
+// columnChanged returns true if any column attribute other than
+// the foreign key and the discovery order changed
+func columnChanged(a, b DBColumn) bool {
+	return a.Comment != b.Comment ||
+		a.Type != b.Type ||
+		a.Array != b.Array ||
+		a.NotNull != b.NotNull ||
+		a.PrimaryKey != b.PrimaryKey ||
+		a.UniqueKey != b.UniqueKey ||
+		a.FullText != b.FullText ||
+		a.FKeyOnDelete != b.FKeyOnDelete ||
+		a.Blocked != b.Blocked ||
+		!slices.Equal(a.Enum, b.Enum)
+}
+
+// tableChanged returns true if a table became or stopped being a view or
+// the definition of the view changed
+func tableChanged(a, b DBTable) bool {
+	return a.Type != b.Type ||
+		a.Definition != b.Definition ||
+		a.Materialized != b.Materialized
+}
+
+// indexKey returns the definition of an index
+func indexKey(idx DBIndex) string {
+	return fmt.Sprintf("%s %v unique:%t primary:%t %s %s",
+		idx.Name, idx.Columns, idx.Unique, idx.Primary, idx.Method, idx.Predicate)
+}
+
+// indexesByKey returns the indexes of a table keyed by indexKey
+func indexesByKey(t DBTable) map[string]struct{} {
+	im := make(map[string]struct{}, len(t.Indexes))
+	for _, idx := range t.Indexes {
+		im[indexKey(idx)] = struct{}{}
+	}
+	return im
+}
+
+// functionKey returns the signature of a function, overloads of a name
+// have their own
+func functionKey(fn DBFunction) string {
+	return fmt.Sprintf("%s set:%t %s.%s", fn.String(), fn.ReturnsSet, fn.ReturnSchema, fn.ReturnTable)
+}
+
+// functionsByKey returns the functions of a DBInfo object keyed by
+// functionKey
+func functionsByKey(di *DBInfo) map[string]struct{} {
+	fm := make(map[string]struct{})
+	if di == nil {
+		return fm
+	}
+	for _, fn := range di.Functions {
+		fm[functionKey(fn)] = struct{}{}
+	}
+	return fm
+}
+
+// sameFKey returns true if both columns reference the same foreign key
+func sameFKey(a, b DBColumn) bool {
+	return a.FKeySchema == b.FKeySchema &&
+		a.FKeyTable == b.FKeyTable &&
+		a.FKeyCol == b.FKeyCol
+}
+
+// tablesByKey returns the tables of a DBInfo object keyed by schema:name
+func tablesByKey(di *DBInfo) map[string]DBTable {
+	tm := make(map[string]DBTable)
+	if di == nil {
+		return tm
+	}
+	for _, t := range di.Tables {
+		tm[(t.Schema + ":" + t.Name)] = t
+	}
+	return tm
+}
+
+// sortedKeys returns the keys of a table map in sorted order
+func sortedKeys(tm map[string]DBTable) []string {
+	keys := make([]string, 0, len(tm))
+	for k := range tm {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedColumns returns the columns of a table sorted by name
+func sortedColumns(t DBTable) []DBColumn {
+	cols := append([]DBColumn{}, t.Columns...)
+	sort.Slice(cols, func(i, j int) bool {
+		return cols[i].Name < cols[j].Name
+	})
+	return cols
+}
@@ -0,0 +1,66 @@
+package schema
+
+import "testing"
+
+// diffInfo returns a DBInfo of users with an index on email
+func diffInfo(t *testing.T) *DBInfo {
+	t.Helper()
+	di, err := NewTestSchema().
+		Table("users", "id pk", "email text notnull", "status text").
+		Table("posts", "id pk", "user_id notnull").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.addIndexes([]DBIndex{{Schema: "public", Table: "users", Name: "users_email", Columns: []string{"email"}}})
+	return di
+}
+
+func TestDiffUnchanged(t *testing.T) {
+	if d := Diff(diffInfo(t), diffInfo(t)); !d.Empty() {
+		t.Errorf("got changes %+v", d)
+	}
+}
+
+func TestDiffIndexes(t *testing.T) {
+	old, new := diffInfo(t), diffInfo(t)
+	users, _ := new.GetTable("public", "users")
+	users.Indexes[0].Unique = true
+
+	d := Diff(old, new)
+	if len(d.IndexesAdded) != 1 || len(d.IndexesDropped) != 1 {
+		t.Fatalf("got indexes added %v and dropped %v", d.IndexesAdded, d.IndexesDropped)
+	}
+	if d.Empty() || !d.GraphChanged() || d.Breaking() {
+		t.Errorf("empty %t, graph changed %t, breaking %t", d.Empty(), d.GraphChanged(), d.Breaking())
+	}
+}
+
+func TestDiffFunctions(t *testing.T) {
+	old, new := diffInfo(t), diffInfo(t)
+	new.Functions = append(new.Functions, DBFunction{Schema: "public", Name: "now_utc", Type: "timestamptz"})
+
+	d := Diff(old, new)
+	if len(d.FunctionsAdded) != 1 || d.Empty() || !d.GraphChanged() {
+		t.Errorf("got functions added %v", d.FunctionsAdded)
+	}
+
+	d = Diff(new, old)
+	if len(d.FunctionsRemoved) != 1 || !d.Breaking() {
+		t.Errorf("got functions removed %v", d.FunctionsRemoved)
+	}
+}
+
+func TestDiffViewsAndEnums(t *testing.T) {
+	old, new := diffInfo(t), diffInfo(t)
+	new.addViews([]DBView{{Schema: "public", Name: "posts", Definition: "SELECT 1"}})
+	new.addEnums([]DBEnum{{Schema: "public", Table: "users", Column: "status", Values: []string{"on", "off"}}})
+
+	d := Diff(old, new)
+	if len(d.TablesChanged) != 1 || d.TablesChanged[0].New.Type != "view" {
+		t.Errorf("got tables changed %v", d.TablesChanged)
+	}
+	if len(d.ColumnsChanged) != 1 || d.ColumnsChanged[0].New.Name != "status" {
+		t.Errorf("got columns changed %v", d.ColumnsChanged)
+	}
+}
@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBDistribution holds how the rows of a table are spread across the
+// nodes of a cluster and sorted on them, eg. the DISTSTYLE, DISTKEY and
+// SORTKEY of a redshift table
+type DBDistribution struct {
+	Schema    string
+	Table     string
+	Style     string   // EVEN, KEY, ALL or AUTO(...)
+	Key       string   // the distribution key column of the KEY style
+	SortKey   []string // the sort key columns in order
+	SortStyle string   // compound or interleaved
+}
+
+// DiscoverDistribution returns the distribution and sort keys of the
+// tables of a database
+func DiscoverDistribution(ctx context.Context, db Querier, dbtype string) ([]DBDistribution, error) {
+	switch dbtype {
+	case "redshift":
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Query(ctx, redshiftDistributionStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching distribution keys: %s", err)
+	}
+	defer rows.Close()
+
+	var dists []DBDistribution
+	dm := make(map[string]int)
+
+	for rows.Next() {
+		var ds, dt, style, col string
+		var distKey bool
+		var sortOrd int
+
+		if err = rows.Scan(&ds, &dt, &style, &col, &distKey, &sortOrd); err != nil {
+			return nil, err
+		}
+
+		k := (ds + ":" + dt)
+		i, ok := dm[k]
+		if !ok {
+			dists = append(dists, DBDistribution{Schema: ds, Table: dt, Style: style})
+			i = len(dists) - 1
+			dm[k] = i
+		}
+
+		d := &dists[i]
+		if distKey {
+			d.Key = col
+		}
+		// interleaved sort keys have a negative order
+		if sortOrd != 0 {
+			d.SortKey = append(d.SortKey, col)
+			d.SortStyle = "compound"
+			if sortOrd < 0 {
+				d.SortStyle = "interleaved"
+			}
+		}
+	}
+
+	return dists, rows.Err()
+}
+
+// addDistribution sets the distribution and sort keys of tables
+func (di *DBInfo) addDistribution(dists []DBDistribution) {
+	for _, d := range dists {
+		if t, err := di.GetTable(d.Schema, d.Table); err == nil {
+			t.Distribution = d
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGetDBInfoRedshift(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		redshiftInfo: {{74296, "public", "dev"}},
+		redshiftColumnsStmt: {
+			columnRow("public", "users", "id", "bigint", true, true),
+			columnRow("public", "events", "id", "bigint", true, true),
+			columnRow("public", "events", "user_id", "bigint", true, false, "public", "users", "id"),
+			columnRow("public", "events", "created_at", "timestamp without time zone", true, false),
+			columnRow("public", "events", "payload", "json", false, false),
+			columnRow("public", "recent_events", "id", "bigint", false, false),
+		},
+		redshiftViewsStmt: {
+			{"public", "recent_events", "SELECT id FROM public.events WITH NO SCHEMA BINDING", "", "", ""},
+		},
+		redshiftDistributionStmt: {
+			{"public", "users", "ALL", "", false, 0},
+			{"public", "events", "KEY", "user_id", true, 0},
+			{"public", "events", "KEY", "created_at", false, 1},
+			{"public", "events", "KEY", "id", false, 2},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "redshift", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(redshiftColumnsStmt) || q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("redshift columns not read with the redshift statements")
+	}
+	if di.Type != "redshift" || di.Version != 74296 || di.Name != "dev" {
+		t.Errorf("got database %s %d %s", di.Type, di.Version, di.Name)
+	}
+
+	events, err := di.GetTable("public", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DBDistribution{
+		Schema: "public", Table: "events",
+		Style: "KEY", Key: "user_id",
+		SortKey: []string{"created_at", "id"}, SortStyle: "compound",
+	}
+	if !reflect.DeepEqual(events.Distribution, want) {
+		t.Errorf("got distribution %+v", events.Distribution)
+	}
+	if users, _ := di.GetTable("public", "users"); users.Distribution.Style != "ALL" || users.Distribution.SortKey != nil {
+		t.Errorf("got distribution %+v", users.Distribution)
+	}
+	if v, _ := di.GetTable("public", "recent_events"); v.Type != "view" {
+		t.Errorf("got late binding view of type %s", v.Type)
+	}
+
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("events", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDiscoverDistribution(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		redshiftDistributionStmt: {
+			{"public", "events", "AUTO(EVEN)", "day", false, -1},
+			{"public", "events", "AUTO(EVEN)", "kind", false, -2},
+		},
+	}}
+	dists, err := DiscoverDistribution(context.Background(), q, "redshift")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dists) != 1 || dists[0].SortStyle != "interleaved" || len(dists[0].SortKey) != 2 || dists[0].Key != "" {
+		t.Errorf("got distributions %+v", dists)
+	}
+
+	// other databases have no distribution keys
+	if dists, err := DiscoverDistribution(context.Background(), q, "postgres"); err != nil || dists != nil {
+		t.Errorf("got distributions %v, %v", dists, err)
+	}
+}
+
+// the distribution is kept through JSON and a namespace
+func TestDistributionJSON(t *testing.T) {
+	di, err := NewTestSchema().Table("events", "id pk").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.Tables[0].Distribution = DBDistribution{Schema: "public", Table: "events", Style: "EVEN"}
+
+	b, err := json.Marshal(di)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got DBInfo
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tables[0].Distribution.Style != "EVEN" {
+		t.Errorf("got distribution %+v", got.Tables[0].Distribution)
+	}
+
+	nt, err := di.Namespaced("dw").GetTable("dw:public", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nt.Distribution.Schema != "dw:public" {
+		t.Errorf("got distribution %+v", nt.Distribution)
+	}
+}
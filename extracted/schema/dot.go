@@ -0,0 +1,217 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/util"
+)
+
+// DOTOptions configures the GraphViz output of ToDOT
+type DOTOptions struct {
+	// Root limits the output to the tables reachable from this table,
+	// the name can be schema qualified
+	Root string
+
+	// Depth is the number of relationships to follow from Root,
+	// zero follows all of them
+	Depth int
+
+	// Columns adds the table columns to the table nodes
+	Columns bool
+}
+
+// schemaRel is a relationship between two tables drawn once no
+// matter how many edges the graph has for it
+type schemaRel struct {
+	edge TEdge
+	key  string
+}
+
+// ToDOT returns the relationship graph in the GraphViz DOT language,
+// edges are labeled with their foreign key columns and styled by type
+func (s *DBSchema) ToDOT(opts DOTOptions) (string, error) {
+	s = s.current()
+
+	nodes, err := s.reachableNodes(opts.Root, opts.Depth)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph schema {\n")
+	sb.WriteString("\trankdir=LR;\n")
+	sb.WriteString("\tnode [shape=record, fontname=\"Helvetica\"];\n")
+	sb.WriteString("\tedge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, n := range nodes {
+		t := s.tables[n]
+		label := s.displayName(t)
+
+		if opts.Columns {
+			var cols []string
+			for _, c := range t.Columns {
+				cols = append(cols, dotEscapeRecord(c.Name+": "+c.Type)+"\\l")
+			}
+			label = "{" + dotEscapeRecord(label) + "|" + strings.Join(cols, "") + "}"
+		} else {
+			label = dotEscapeRecord(label)
+		}
+		fmt.Fprintf(&sb, "\t%s [label=%s];\n", dotQuote(t.String()), dotLabel(label))
+	}
+
+	for _, r := range s.schemaRels(nodes) {
+		e := r.edge
+		fmt.Fprintf(&sb, "\t%s -> %s [label=%s%s];\n",
+			dotQuote(e.LT.String()),
+			dotQuote(e.RT.String()),
+			dotQuote(relLabel(e)),
+			dotStyle(e.Type))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// reachableNodes returns the sorted nodes reachable from the root table
+// within depth relationships, all nodes are returned when root is empty
+func (s *DBSchema) reachableNodes(root string, depth int) ([]int32, error) {
+	var nodes []int32
+
+	if root == "" {
+		for i := range s.tables {
+			if _, ok := s.removed[int32(i)]; !ok {
+				nodes = append(nodes, int32(i))
+			}
+		}
+	} else {
+		schema, name := s.splitTableName(root)
+		v, ok := s.tindex[(schema + ":" + name)]
+		if !ok {
+			return nil, fmt.Errorf("table not found: %s", root)
+		}
+
+		seen := map[int32]struct{}{v.nodeID: {}}
+		next := []int32{v.nodeID}
+		nodes = append(nodes, v.nodeID)
+
+		for d := 0; len(next) != 0 && (depth == 0 || d < depth); d++ {
+			var curr []int32
+			for _, n := range next {
+				for _, m := range s.relationshipGraph.Connections(n) {
+					if _, ok := seen[m]; ok {
+						continue
+					}
+					seen[m] = struct{}{}
+					nodes = append(nodes, m)
+					curr = append(curr, m)
+				}
+			}
+			next = curr
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return s.tables[nodes[i]].String() < s.tables[nodes[j]].String()
+	})
+	return nodes, nil
+}
+
+// schemaRels returns the relationships between the nodes sorted by their
+// tables and columns, of each pair of edges created for a relationship
+// only the first one added is kept
+func (s *DBSchema) schemaRels(nodes []int32) []schemaRel {
+	in := make(map[int32]struct{}, len(nodes))
+	for _, n := range nodes {
+		in[n] = struct{}{}
+	}
+
+	var rels []schemaRel
+	for _, n := range nodes {
+		for _, m := range s.relationshipGraph.Connections(n) {
+			if _, ok := in[m]; !ok {
+				continue
+			}
+			for _, ge := range s.relationshipGraph.GetEdges(n, m) {
+				if !firstOfPair(ge) {
+					continue
+				}
+				e := s.getEdge(ge.ID)
+				rels = append(rels, schemaRel{
+					edge: e,
+					key:  e.LT.String() + ">" + e.RT.String() + ":" + e.CName,
+				})
+			}
+		}
+	}
+
+	sort.Slice(rels, func(i, j int) bool { return rels[i].key < rels[j].key })
+	return rels
+}
+
+// firstOfPair returns true for the first of the two edges added for
+// a relationship, copies of an edge indexed under other names are
+// never paired so they are skipped too
+func firstOfPair(e util.Edge) bool {
+	return e.OppID != -1 && e.ID < e.OppID
+}
+
+// displayName returns the table name qualified with its schema when it
+// is not in the default schema
+func (s *DBSchema) displayName(t DBTable) string {
+	if t.Schema == "" || t.Schema == s.schema {
+		return t.Name
+	}
+	return t.String()
+}
+
+// relLabel returns the columns of a relationship
+func relLabel(e TEdge) string {
+	if e.Type == RelManyToMany {
+		return e.Through.Ti.Name
+	}
+
+	var cols []string
+	for _, c := range e.LCs {
+		cols = append(cols, c.Name)
+	}
+	if len(cols) == 0 {
+		cols = append(cols, e.L.Name)
+	}
+	return strings.Join(cols, ", ")
+}
+
+// dotStyle returns the DOT edge attributes for a relationship type
+func dotStyle(rt RelType) string {
+	switch rt {
+	case RelOneToMany:
+		return ", arrowhead=crow"
+	case RelManyToMany:
+		return ", dir=both, arrowhead=crow, arrowtail=crow, style=bold"
+	case RelPolymorphic:
+		return ", style=dashed"
+	case RelEmbedded:
+		return ", style=dotted"
+	case RelRemote:
+		return ", style=dashed, color=gray40"
+	case RelRecursive:
+		return ", color=blue"
+	}
+	return ""
+}
+
+// dotQuote returns a quoted DOT identifier
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s) + "\""
+}
+
+// dotLabel returns a quoted record label keeping its escape sequences
+func dotLabel(s string) string {
+	return "\"" + strings.ReplaceAll(s, "\"", "\\\"") + "\""
+}
+
+// dotEscapeRecord escapes the characters that have a meaning in record labels
+func dotEscapeRecord(s string) string {
+	return strings.NewReplacer("{", "\\{", "}", "\\}", "|", "\\|", "<", "\\<", ">", "\\>").Replace(s)
+}
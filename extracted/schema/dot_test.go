@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+)
+
+func TestToDOT(t *testing.T) {
+	s := blogTestSchema(t)
+
+	dot, err := s.ToDOT(DOTOptions{Columns: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "schema.dot", dot)
+
+	// the likes are two joins away from the users
+	chain, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id").
+		Table("likes", "id pk", "post_id").
+		FK("posts.user_id", "users.id").
+		FK("likes.post_id", "posts.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for depth, want := range map[int]bool{1: false, 2: true, 0: true} {
+		dot, err = chain.ToDOT(DOTOptions{Root: "users", Depth: depth})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(dot, `"public.likes"`); got != want {
+			t.Errorf("depth %d: got likes %t, want %t:\n%s", depth, got, want, dot)
+		}
+	}
+
+	if _, err := s.ToDOT(DOTOptions{Root: "missing"}); err == nil {
+		t.Error("want an error for a missing root table")
+	}
+}
+
+func TestDOTQuote(t *testing.T) {
+	if got := dotQuote(`a "b"` + "\n"); got != `"a \"b\"\n"` {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,59 @@
+package schema
+
+import "strings"
+
+// duckdbTypes maps duckdb type names to the type names
+// used across the rest of the schema package
+var duckdbTypes = map[string]string{
+	"tinyint":                  "smallint",
+	"utinyint":                 "smallint",
+	"smallint":                 "smallint",
+	"usmallint":                "integer",
+	"integer":                  "integer",
+	"uinteger":                 "bigint",
+	"bigint":                   "bigint",
+	"ubigint":                  "numeric",
+	"hugeint":                  "numeric",
+	"uhugeint":                 "numeric",
+	"decimal":                  "numeric",
+	"float":                    "real",
+	"double":                   "double precision",
+	"boolean":                  "boolean",
+	"varchar":                  "text",
+	"blob":                     "bytea",
+	"bit":                      "bit",
+	"uuid":                     "uuid",
+	"json":                     "json",
+	"date":                     "date",
+	"time":                     "time without time zone",
+	"time with time zone":      "time with time zone",
+	"timestamp":                "timestamp without time zone",
+	"timestamp_s":              "timestamp without time zone",
+	"timestamp_ms":             "timestamp without time zone",
+	"timestamp_ns":             "timestamp without time zone",
+	"timestamp with time zone": "timestamp with time zone",
+	"interval":                 "interval",
+	"struct":                   "json",
+	"map":                      "json",
+	"union":                    "json",
+	"enum":                     "text",
+}
+
+// duckdbType returns the normalized name for a duckdb type, lists and
+// fixed size arrays become arrays of the element type
+func duckdbType(t string) string {
+	if strings.HasSuffix(t, "]") {
+		if i := strings.LastIndexByte(t, '['); i > 0 {
+			return duckdbType(t[:i]) + "[]"
+		}
+	}
+
+	name := strings.ToLower(t)
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	if v, ok := duckdbTypes[name]; ok {
+		return v
+	}
+	return t
+}
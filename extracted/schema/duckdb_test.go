@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetDBInfoDuckDB(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		duckdbInfo: {{110, "main", "local"}},
+		duckdbColumnsStmt: {
+			columnRow("main", "events", "user_id", "BIGINT", false, false),
+			columnRow("main", "events", "tags", "VARCHAR[]", false, false),
+			columnRow("main", "events", "props", "STRUCT(a INTEGER)", false, false),
+			// the main schema of the attached warehouse database
+			columnRow("warehouse", "users", "id", "BIGINT", true, true),
+			columnRow("warehouse", "users", "name", "VARCHAR", false, false),
+		},
+		duckdbViewsStmt: {
+			{"main", "events", "CREATE VIEW events AS SELECT * FROM read_parquet('events/*.parquet');", "", "", ""},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "duckdb", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(duckdbColumnsStmt) || q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("duckdb columns not read with the duckdb statements")
+	}
+	if di.Type != "duckdb" || di.Schema != "main" || di.Name != "local" {
+		t.Errorf("got database %s %s %s", di.Type, di.Schema, di.Name)
+	}
+
+	events, err := di.GetTable("main", "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if events.Type != "view" || events.Definition == "" {
+		t.Errorf("got table %s of type %s", events.String(), events.Type)
+	}
+	for col, want := range map[string]string{"user_id": "bigint", "tags": "text[]", "props": "json"} {
+		c, err := di.GetColumn("main", "events", col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Type, want)
+		}
+	}
+
+	// views have no keys, they are joined to the tables of the attached
+	// database with virtual relationships
+	vr, err := NewVirtualRel("main.events.user_id", "warehouse.users.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDBSchema(di, nil, WithVirtualRels(vr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("events", "warehouse.users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinString(path); got != "events.user_id -> users.id" || path[0].RT.Schema != "warehouse" {
+		t.Errorf("got path %s", got)
+	}
+}
+
+func TestDuckDBType(t *testing.T) {
+	for in, want := range map[string]string{
+		"UBIGINT":                  "numeric",
+		"DECIMAL(18,3)":            "numeric",
+		"VARCHAR":                  "text",
+		"INTEGER[]":                "integer[]",
+		"DOUBLE[3]":                "double precision[]",
+		"STRUCT(a INTEGER)[]":      "json[]",
+		"TIMESTAMP WITH TIME ZONE": "timestamp with time zone",
+		"MAP(VARCHAR, INTEGER)":    "json",
+		"VARINT":                   "VARINT",
+	} {
+		if got := duckdbType(in); got != want {
+			t.Errorf("duckdbType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,1340 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/yourusername/graphjin-extracted/util"
+)
+
+var (
+	ErrFromEdgeNotFound   = errors.New("from edge not found")
+	ErrToEdgeNotFound     = errors.New("to edge not found")
+	ErrPathNotFound       = errors.New("path not found")
+	ErrThoughNodeNotFound = errors.New("though node not found")
+	ErrPathTooDeep        = errors.New("path too deep")
+)
+
+// TEdge represents a table edge for the graph
+type TEdge struct {
+	From, To, Weight int32
+
+	Type    RelType
+	LT, RT  DBTable
+	L, R    DBColumn
+	LCs     []DBColumn
+	RCs     []DBColumn
+	CName   string
+	Poly    DBRelPoly
+	Through DBRelThrough
+	name    string
+}
+
+// edge is a TEdge as it is kept in the graph, the tables are the nodes
+// it joins so every edge does not hold copies of them. The discriminator
+// and join table are only set on the edges that have them
+type edge struct {
+	From, To, Weight int32
+
+	Type    RelType
+	L, R    DBColumn
+	LCs     []DBColumn
+	RCs     []DBColumn
+	CName   string
+	Poly    *DBRelPoly
+	Through *edgeThrough
+	name    string
+}
+
+// edgeThrough is the join table of a many-to-many edge by node, or a
+// copy of it when it is not one
+type edgeThrough struct {
+	node       int32
+	ti         *DBTable
+	ColL, ColR DBColumn
+}
+
+// packEdge returns the edge kept for a TEdge
+func (s *DBSchema) packEdge(te TEdge) edge {
+	e := edge{
+		From: te.From, To: te.To, Weight: te.Weight,
+		Type: te.Type,
+		L:    te.L, R: te.R,
+		LCs: te.LCs, RCs: te.RCs,
+		CName: te.CName,
+		name:  te.name,
+	}
+	if te.Poly.TypeCol.Name != "" || te.Poly.TypeValue != "" || te.Poly.Types != nil {
+		poly := te.Poly
+		e.Poly = &poly
+	}
+	if ti := te.Through.Ti; ti.Name != "" {
+		e.Through = &edgeThrough{node: -1, ColL: te.Through.ColL, ColR: te.Through.ColR}
+		if v, ok := s.tindex[(ti.Schema + ":" + ti.Name)]; ok {
+			e.Through.node = v.nodeID
+		} else {
+			e.Through.ti = &ti
+		}
+	}
+	return e
+}
+
+// getEdge returns the TEdge of an edge id with the tables of its nodes
+func (s *DBSchema) getEdge(id int32) TEdge {
+	e := s.allEdges[id]
+	te := TEdge{
+		From: e.From, To: e.To, Weight: e.Weight,
+		Type: e.Type,
+		LT:   s.tables[e.From], RT: s.tables[e.To],
+		L: e.L, R: e.R,
+		LCs: e.LCs, RCs: e.RCs,
+		CName: e.CName,
+		name:  e.name,
+	}
+	if e.Poly != nil {
+		te.Poly = *e.Poly
+	}
+	if th := e.Through; th != nil {
+		te.Through = DBRelThrough{ColL: th.ColL, ColR: th.ColR}
+		if th.ti != nil {
+			te.Through.Ti = *th.ti
+		} else {
+			te.Through.Ti = s.tables[th.node]
+		}
+	}
+	return te
+}
+
+// addNode adds a table node to the graph
+func (s *DBSchema) addNode(t DBTable) int32 {
+	s.tables = append(s.tables, t)
+	n := s.relationshipGraph.AddNode()
+
+	s.tindex[(t.Schema + ":" + t.Name)] = nodeInfo{n}
+	return n
+}
+
+// addAliases adds table aliases to the graph
+func (s *DBSchema) addAliases(t DBTable, nodeID int32, aliases []string) {
+	for _, al := range aliases {
+		s.tindex[(t.Schema + ":" + al)] = nodeInfo{nodeID}
+		s.tableAliasIndex[al] = nodeInfo{nodeID}
+	}
+}
+
+// GetAliases returns a map of table aliases
+func (s *DBSchema) GetAliases() map[string]DBTable {
+	s = s.current()
+
+	ts := make(map[string]DBTable)
+
+	for name, n := range s.tableAliasIndex {
+		ts[name] = s.tables[int(n.nodeID)]
+	}
+	return ts
+}
+
+// IsAlias checks if a table is an alias
+func (s *DBSchema) IsAlias(name string) bool {
+	s = s.current()
+
+	_, ok := s.tableAliasIndex[name]
+	return ok
+}
+
+// Building the graph
+// 1. AddNode is used to add tables nodes to the graph
+// 2. addEdge creates relationships between schema:table -> fk_schema:fk_table
+// 3. addEdge creates relationships between fk_schema:fk_table:column_name -> schema:table
+// 4. addEdge creates relationships between :fk_table:column_name -> schema:table
+// 5. addEdge creates relationships between :column_name -> schema:table
+//
+// Note 1: `_id` or `id_` is stripped from the column name to use as a graph key
+// in the case where that then matches a real table name will result in conflict.
+
+func (s *DBSchema) addToGraph(
+	lti DBTable, lcol DBColumn,
+	rti DBTable, rcol DBColumn,
+	rt RelType,
+) error {
+	return s.addRelToGraph(lti, lcol, rti, rcol, rt, relExtra{})
+}
+
+// relExtra holds the optional parts of a relationship
+type relExtra struct {
+	poly   DBRelPoly  // discriminator of a polymorphic relationship
+	lcols  []DBColumn // all columns of a composite foreign key
+	rcols  []DBColumn // all columns referenced by a composite foreign key
+	unique bool       // the foreign key columns are unique on their table
+}
+
+// addRelToGraph is addToGraph with the optional parts of the
+// relationship set on both edges
+func (s *DBSchema) addRelToGraph(
+	lti DBTable, lcol DBColumn,
+	rti DBTable, rcol DBColumn,
+	rt RelType,
+	ex relExtra,
+) error {
+	var err error
+
+	if s.isBlockedRel(lti, lcol, rti) {
+		s.log.rel("relationship skipped", rt, lti, lcol, rti, rcol, slog.String("reason", "blocked"))
+		return nil
+	}
+
+	if len(ex.lcols) == 0 {
+		ex.lcols = []DBColumn{lcol}
+		ex.rcols = []DBColumn{rcol}
+	}
+
+	rel, ok := s.relHook(DBRel{
+		Type:  rt,
+		Left:  DBRelLeft{Ti: lti, Col: lcol, Cols: ex.lcols},
+		Right: DBRelRight{Ti: rti, Col: rcol, Cols: ex.rcols},
+		Poly:  ex.poly,
+	})
+	if !ok {
+		s.log.rel("relationship skipped", rt, lti, lcol, rti, rcol, slog.String("reason", "hook"))
+		return nil
+	}
+	rt, ex.poly = rel.Type, rel.Poly
+	lti, lcol, ex.lcols = rel.Left.Ti, rel.Left.Col, rel.Left.Cols
+	rti, rcol, ex.rcols = rel.Right.Ti, rel.Right.Col, rel.Right.Cols
+
+	var rt2 RelType
+	k1 := (lti.Schema + ":" + lti.Name)
+	k2 := (rti.Schema + ":" + rti.Name)
+
+	fn, ok := s.tindex[k1]
+	if !ok {
+		return fmt.Errorf("addEdge: unknown node: %s", k1)
+	}
+
+	tn, ok := s.tindex[k2]
+	if !ok {
+		return fmt.Errorf("addEdge: unknown node: %s", k2)
+	}
+
+	ln := fn.nodeID
+	rn := tn.nodeID
+
+	var weight int32 = 1
+	relT := GetRelName(lcol.Name)
+
+	switch rt {
+	case RelOneToOne:
+		rt2 = RelOneToMany
+		if ex.unique {
+			rt2 = RelOneToOne
+		}
+	case RelOneToMany:
+		rt2 = RelOneToOne
+	case RelPolymorphic:
+		rt2 = rt
+		relT = rti.Name
+		weight = 15
+	case RelEmbedded:
+		rt2 = rt
+		relT = rti.Name
+		weight = 5
+	case RelRecursive:
+		rt2 = rt
+		weight = 10
+	case RelRemote:
+		rt2 = rt
+		weight = 8
+		relT = lti.Name
+	default:
+		s.log.rel("relationship skipped", rt, lti, lcol, rti, rcol, slog.String("reason", "unsupported type"))
+		return nil
+	}
+
+	var edgeID1, edgeID2 int32
+
+	// Add edge from table -> foreign key table
+	e1 := TEdge{
+		From:   ln,
+		To:     rn,
+		Weight: weight,
+		Type:   rt,
+		LT:     lti, RT: rti,
+		L: lcol, R: rcol,
+		LCs: ex.lcols, RCs: ex.rcols,
+		CName: lcol.Name,
+		Poly:  ex.poly,
+	}
+
+	if edgeID1, err = s.addEdge(lti.Name, e1, true); err != nil {
+		return err
+	}
+
+	// Add reverse edge from parent table -> column_name
+	e2 := TEdge{
+		From:   rn,
+		To:     ln,
+		Weight: weight,
+		Type:   rt2,
+		LT:     rti, RT: lti,
+		L: rcol, R: lcol,
+		LCs: ex.rcols, RCs: ex.lcols,
+		CName: lcol.Name,
+		Poly:  ex.poly,
+	}
+
+	if edgeID2, err = s.addEdge(relT, e2, true); err != nil {
+		return err
+	}
+
+	if err := s.relationshipGraph.UpdateEdge(ln, rn, edgeID1, edgeID2); err != nil {
+		return err
+	}
+
+	if err := s.relationshipGraph.UpdateEdge(rn, ln, edgeID2, edgeID1); err != nil {
+		return err
+	}
+
+	if rti.Name != relT {
+		if _, err := s.addEdge(rti.Name, e2, false); err != nil {
+			return err
+		}
+	}
+
+	s.log.rel("relationship added", rt, lti, lcol, rti, rcol)
+
+	// fmt.Printf("1. (%s, %d) %s.%s (%d) -> %s.%s (%d) == %s\n", lti.Name, e1.ID(), lti.Name, lcol.Name, ln.ID(), rti.Name, rcol.Name, rn.ID(), rt.String())
+	// fmt.Printf("2. (%s, %d) %s.%s (%d) -> %s.%s (%d) == %s\n", rti.Name, e2.ID(), rti.Name, rcol.Name, rn.ID(), lti.Name, lcol.Name, ln.ID(), rt2.String())
+	// fmt.Printf("3. (%s, %d) %s.%s (%d) -> %s.%s (%d) == %s\n", relT, e2.ID(), rti.Name, rcol.Name, rn.ID(), lti.Name, lcol.Name, ln.ID(), rt2.String())
+	// fmt.Println("-----")
+	return nil
+}
+
+// addManyToManyToGraph adds a pair of edges directly connecting the two
+// tables joined by a join table
+func (s *DBSchema) addManyToManyToGraph(
+	lti DBTable, lcol DBColumn,
+	rti DBTable, rcol DBColumn,
+	through DBRelThrough,
+) error {
+	var err error
+
+	if s.isBlockedTable(through.Ti) ||
+		s.isBlockedRel(through.Ti, through.ColL, lti) ||
+		s.isBlockedRel(through.Ti, through.ColR, rti) {
+		s.log.rel("relationship skipped", RelManyToMany, lti, lcol, rti, rcol,
+			slog.String("through", through.Ti.Name), slog.String("reason", "blocked"))
+		return nil
+	}
+
+	rel, ok := s.relHook(DBRel{
+		Type:    RelManyToMany,
+		Left:    DBRelLeft{Ti: lti, Col: lcol, Cols: []DBColumn{lcol}},
+		Right:   DBRelRight{Ti: rti, Col: rcol, Cols: []DBColumn{rcol}},
+		Through: through,
+	})
+	if !ok {
+		s.log.rel("relationship skipped", RelManyToMany, lti, lcol, rti, rcol,
+			slog.String("through", through.Ti.Name), slog.String("reason", "hook"))
+		return nil
+	}
+	lti, lcol, rti, rcol, through = rel.Left.Ti, rel.Left.Col, rel.Right.Ti, rel.Right.Col, rel.Through
+
+	k1 := (lti.Schema + ":" + lti.Name)
+	k2 := (rti.Schema + ":" + rti.Name)
+
+	fn, ok := s.tindex[k1]
+	if !ok {
+		return fmt.Errorf("addEdge: unknown node: %s", k1)
+	}
+
+	tn, ok := s.tindex[k2]
+	if !ok {
+		return fmt.Errorf("addEdge: unknown node: %s", k2)
+	}
+
+	ln := fn.nodeID
+	rn := tn.nodeID
+
+	// weighted higher than a direct foreign key between the two tables
+	var weight int32 = 2
+	var edgeID1, edgeID2 int32
+
+	e1 := TEdge{
+		From:   ln,
+		To:     rn,
+		Weight: weight,
+		Type:   RelManyToMany,
+		LT:     lti, RT: rti,
+		L: lcol, R: rcol,
+		LCs: []DBColumn{lcol}, RCs: []DBColumn{rcol},
+		CName:   through.ColL.Name,
+		Through: through,
+	}
+
+	if edgeID1, err = s.addEdge(lti.Name, e1, true); err != nil {
+		return err
+	}
+
+	e2 := TEdge{
+		From:   rn,
+		To:     ln,
+		Weight: weight,
+		Type:   RelManyToMany,
+		LT:     rti, RT: lti,
+		L: rcol, R: lcol,
+		LCs: []DBColumn{rcol}, RCs: []DBColumn{lcol},
+		CName: through.ColR.Name,
+		Through: DBRelThrough{
+			Ti:   through.Ti,
+			ColL: through.ColR,
+			ColR: through.ColL,
+		},
+	}
+
+	if edgeID2, err = s.addEdge(rti.Name, e2, true); err != nil {
+		return err
+	}
+
+	if err := s.relationshipGraph.UpdateEdge(ln, rn, edgeID1, edgeID2); err != nil {
+		return err
+	}
+
+	if err := s.relationshipGraph.UpdateEdge(rn, ln, edgeID2, edgeID1); err != nil {
+		return err
+	}
+
+	s.log.rel("relationship added", RelManyToMany, lti, lcol, rti, rcol,
+		slog.String("through", through.Ti.Name))
+	return nil
+}
+
+// addEdge creates a relationship between two tables
+func (s *DBSchema) addEdge(name string, edge TEdge, inSchema bool,
+) (int32, error) {
+	// add edge to graph
+	edgeID, err := s.relationshipGraph.AddEdge(edge.From, edge.To,
+		edge.Weight, edge.CName)
+	if err != nil {
+		return -1, err
+	}
+
+	ei := edgeInfo{nodeID: edge.From, edgeIDs: []int32{edgeID}}
+	s.addEdgeInfo(name, ei)
+
+	// also index edges named after their table by the schema qualified
+	// name so tables with the same name in different schemas can be told apart
+	if name == edge.LT.Name && edge.LT.Schema != "" {
+		s.addEdgeInfo((edge.LT.Schema + "." + name), ei)
+	}
+
+	if inSchema {
+		edge.name = name
+	}
+	s.allEdges[edgeID] = s.packEdge(edge)
+
+	return edgeID, nil
+}
+
+// addEdgeInfo adds edge info to the index
+func (s *DBSchema) addEdgeInfo(k string, ei edgeInfo) {
+	if eiList, ok := s.edgesIndex[k]; ok {
+		for i, v := range eiList {
+			if v.nodeID != ei.nodeID {
+				continue
+			}
+			for _, eid := range v.edgeIDs {
+				if eid == ei.edgeIDs[0] {
+					return
+				}
+			}
+			edgeIDs := append(v.edgeIDs, ei.edgeIDs[0])
+			s.edgesIndex[k][i].edgeIDs = edgeIDs
+			return
+		}
+	}
+	s.edgesIndex[k] = append(s.edgesIndex[k], ei)
+}
+
+// Find returns a table by schema and name, when schema is empty
+// the name can be schema qualified eg. "billing.invoices". The columns
+// of a partial table of a lazy schema are loaded on the first call
+func (s *DBSchema) Find(schema, name string) (DBTable, error) {
+	return s.current().find(schema, name)
+}
+
+// find is Find on the snapshot it is called on
+func (s *DBSchema) find(schema, name string) (DBTable, error) {
+	var t DBTable
+
+	if schema == "" {
+		schema, name = s.splitTableName(name)
+	}
+
+	v, ok := s.findNode(schema + "." + name)
+	if !ok {
+		return t, s.tableNotFound(schema+"."+name, nil)
+	}
+
+	return s.lazyTable(v.nodeID)
+}
+
+// FindColumn returns a column and its table by name, the name is a
+// column of a table eg. "users.email" that can be schema qualified or a
+// bare column name which must belong to a single table of the default
+// schema else an ErrAmbiguousColumn is returned
+func (s *DBSchema) FindColumn(name string) (DBTable, DBColumn, error) {
+	return s.current().findColumn(name)
+}
+
+// findColumn is FindColumn on the snapshot it is called on
+func (s *DBSchema) findColumn(name string) (DBTable, DBColumn, error) {
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		t, err := s.find("", name[:i])
+		if err != nil {
+			return t, DBColumn{}, err
+		}
+		c, err := t.GetColumn(name[i+1:])
+		return t, c, err
+	}
+
+	var found []int32
+	for i, t := range s.tables {
+		if t.Schema != s.DBSchema() || t.Blocked || t.Type == "virtual" {
+			continue
+		}
+		if _, ok := s.removed[int32(i)]; ok {
+			continue
+		}
+		if _, ok := t.getColumn(name); ok {
+			found = append(found, int32(i))
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return DBTable{}, DBColumn{}, fmt.Errorf("column not found: %s", name)
+	case 1:
+		t, err := s.lazyTable(found[0])
+		if err != nil {
+			return t, DBColumn{}, err
+		}
+		c, err := t.GetColumn(name)
+		return t, c, err
+	}
+
+	e := &ErrAmbiguousColumn{Column: name}
+	for _, n := range found {
+		e.Tables = append(e.Tables, s.tables[n].Name)
+	}
+	sort.Strings(e.Tables)
+	return DBTable{}, DBColumn{}, e
+}
+
+// findNode returns the node of a table name that can be schema
+// qualified, the singular and plural forms of the name are tried when
+// the name is not found unless WithExactNames is used
+func (s *DBSchema) findNode(name string) (nodeInfo, bool) {
+	schema, tn := s.splitTableName(name)
+	if v, ok := s.tindex[(schema + ":" + tn)]; ok {
+		return v, true
+	}
+	for _, n := range s.inflections(tn) {
+		if v, ok := s.tindex[(schema + ":" + n)]; ok {
+			return v, true
+		}
+	}
+	return nodeInfo{}, false
+}
+
+// splitTableName splits a schema qualified table name, names
+// without a schema are in the default schema
+func (s *DBSchema) splitTableName(name string) (string, string) {
+	if schema, table, ok := strings.Cut(name, "."); ok {
+		return schema, table
+	}
+	return s.DBSchema(), name
+}
+
+// findEdges returns the edges indexed under a name, when the name
+// is not schema qualified edges from tables in the default schema
+// are returned first. The singular and plural forms of a name that
+// is not indexed are tried
+func (s *DBSchema) findEdges(name string) ([]edgeInfo, bool) {
+	el, ok := s.edgesIndex[name]
+	if !ok {
+		for _, n := range s.inflections(name) {
+			if el, ok = s.edgesIndex[n]; ok {
+				name = n
+				break
+			}
+		}
+	}
+	if !ok || len(el) < 2 {
+		return el, ok
+	}
+
+	// tables of the default schema first unless the name is qualified,
+	// then by schema and name so the order does not depend on the order
+	// the tables were added in
+	qualified := strings.Contains(name, ".")
+	el = append([]edgeInfo(nil), el...)
+	sort.SliceStable(el, func(i, j int) bool {
+		a, b := s.tables[el[i].nodeID], s.tables[el[j].nodeID]
+		if !qualified {
+			if da, db := a.Schema == s.DBSchema(), b.Schema == s.DBSchema(); da != db {
+				return da
+			}
+		}
+		return tableLess(a, b)
+	})
+	return el, true
+}
+
+// tableLess orders tables by schema and name
+func tableLess(a, b DBTable) bool {
+	if a.Schema != b.Schema {
+		return a.Schema < b.Schema
+	}
+	return a.Name < b.Name
+}
+
+// TPath represents a table path
+type TPath struct {
+	Rel     RelType
+	LT      DBTable
+	LC      DBColumn
+	RT      DBTable
+	RC      DBColumn
+	LCs     []DBColumn
+	RCs     []DBColumn
+	Poly    DBRelPoly
+	Through DBRelThrough
+}
+
+// FindPath returns a path between two tables
+func (s *DBSchema) FindPath(from, to, through string) ([]TPath, error) {
+	return s.FindPathContext(context.Background(), from, to, through)
+}
+
+// FindPathContext is FindPath recording its span as a child of the span
+// of the context, see WithTracing and WithMetrics
+func (s *DBSchema) FindPathContext(ctx context.Context, from, to, through string) ([]TPath, error) {
+	s = s.current()
+
+	if s.tracer == nil && s.metrics == nil && !s.log.enabled() {
+		path, _, err := s.cachedPath(ctx, from, to, through)
+		return path, err
+	}
+
+	start := time.Now()
+	_, span := startSpan(ctx, s.tracer, "schema.FindPath",
+		attribute.String("schema.path.from", from),
+		attribute.String("schema.path.to", to),
+		attribute.String("schema.path.through", through))
+
+	path, hit, err := s.cachedPath(ctx, from, to, through)
+	span.SetAttributes(
+		attribute.Int("schema.path.length", len(path)),
+		attribute.Bool("schema.cache.hit", hit))
+	endSpan(span, err)
+
+	if s.metrics != nil {
+		s.metrics.PathFound(time.Since(start), hit, err)
+	}
+	if s.log.enabled() {
+		s.log.debug("path resolved",
+			slog.String("from", from), slog.String("to", to), slog.String("through", through),
+			slog.String("path", pathString(path)), slog.Bool("cached", hit), slog.Any("error", err))
+	}
+	return path, err
+}
+
+// pathString returns the tables of a path eg. comments -> posts -> users
+func pathString(path []TPath) string {
+	if len(path) == 0 {
+		return ""
+	}
+	names := []string{path[0].LT.Name}
+	for _, p := range path {
+		names = append(names, p.RT.Name)
+	}
+	return strings.Join(names, " -> ")
+}
+
+// cachedPath is FindPath on the snapshot it is called on, true when the
+// path is cached. A search stopped by the context is not cached
+func (s *DBSchema) cachedPath(ctx context.Context, from, to, through string) ([]TPath, bool, error) {
+	if s.pathTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.pathTimeout)
+		defer cancel()
+	}
+
+	if s.pathCache == nil {
+		path, err := s.findPath(ctx, from, to, through)
+		return path, false, err
+	}
+
+	k := (from + ":" + to + ":" + through)
+	if v, ok := s.pathCache.get(k); ok {
+		return v.path, true, v.err
+	}
+
+	path, err := s.findPath(ctx, from, to, through)
+	if ctx.Err() != nil && err != nil {
+		return nil, false, err
+	}
+	s.pathCache.set(k, cachedPath{path: path, err: err})
+
+	if path != nil {
+		path = append([]TPath(nil), path...)
+	}
+	return path, false, err
+}
+
+// findPath finds a path between two tables, when there is none the
+// singular and plural forms of the names are tried since a name can
+// also be the name of an unrelated relationship eg. "tag" of tag_id
+func (s *DBSchema) findPath(ctx context.Context, from, to, through string) ([]TPath, error) {
+	path, err := s.findNamedPath(ctx, from, to, through)
+	if err == nil || s.exactNames || !errors.Is(err, ErrPathNotFound) {
+		return path, err
+	}
+
+	for _, f := range append([]string{from}, s.inflections(from)...) {
+		for _, t := range append([]string{to}, s.inflections(to)...) {
+			if f == from && t == to {
+				continue
+			}
+			if p, err := s.findNamedPath(ctx, f, t, through); err == nil {
+				s.log.debug("path found by inflected names",
+					slog.String("from", from), slog.String("to", to),
+					slog.String("inflected_from", f), slog.String("inflected_to", t))
+				return p, nil
+			}
+		}
+	}
+	return path, err
+}
+
+// findNamedPath finds a path between the tables indexed under two names
+func (s *DBSchema) findNamedPath(ctx context.Context, from, to, through string) ([]TPath, error) {
+	fl, err := s.pathEdges(from, ErrFromEdgeNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	tl, err := s.pathEdges(to, ErrToEdgeNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if len(fl) == 0 || len(tl) == 0 {
+		return nil, s.noPath(from, to, through, fl, tl)
+	}
+
+	res, err := s.between(ctx, fl, tl, through)
+	switch err {
+	case nil:
+	case ErrPathNotFound:
+		return nil, s.noPath(from, to, through, fl, tl)
+	case ErrPathTooDeep:
+		return nil, fmt.Errorf("%w: %s -> %s needs more than %d joins", ErrPathTooDeep, from, to, s.maxPathDepth)
+	case ErrThoughNodeNotFound:
+		return nil, s.tableNotFound(through, ErrThoughNodeNotFound)
+	default:
+		return nil, err
+	}
+
+	if s.ambiguousPaths {
+		if err := s.checkAmbiguous(from, to, through, res); err != nil {
+			return nil, err
+		}
+	}
+
+	// fmt.Printf("> %s (%d) -> %s (%d)\n",
+	// 	from, res.from.nodeID,
+	// 	to, res.to.nodeID)
+
+	path := s.edgesToPath(res.edges)
+	if len(path) == 0 {
+		return nil, s.noPath(from, to, through, fl, tl)
+	}
+	return path, nil
+}
+
+// pathEdges returns the edges of a table a path starts or ends at, a
+// table without relationships has none
+func (s *DBSchema) pathEdges(name string, notFound error) ([]edgeInfo, error) {
+	if el, ok := s.findEdges(name); ok {
+		return el, nil
+	}
+	if _, ok := s.findNode(name); ok {
+		return nil, nil
+	}
+	return nil, s.tableNotFound(name, notFound)
+}
+
+// FindPathVia returns a path between two tables that passes through
+// the via tables in the given order eg. comments -> likes -> users
+func (s *DBSchema) FindPathVia(from, to string, via ...string) ([]TPath, error) {
+	s = s.current()
+
+	for _, v := range via {
+		if _, ok := s.findEdges(v); !ok {
+			return nil, s.tableNotFound(v, ErrThoughNodeNotFound)
+		}
+	}
+
+	stops := append(append([]string{from}, via...), to)
+
+	var path []TPath
+	for i := 1; i < len(stops); i++ {
+		p, _, err := s.cachedPath(context.Background(), stops[i-1], stops[i], "")
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, p...)
+	}
+	return path, nil
+}
+
+// edgesToPath converts graph edges to a table path
+func (s *DBSchema) edgesToPath(edges []int32) []TPath {
+	path := []TPath{}
+	for _, eid := range edges {
+		edge := s.getEdge(eid)
+		path = append(path, TPath{
+			Rel:     edge.Type,
+			LT:      edge.LT,
+			LC:      edge.L,
+			RT:      edge.RT,
+			RC:      edge.R,
+			LCs:     edge.LCs,
+			RCs:     edge.RCs,
+			Poly:    edge.Poly,
+			Through: edge.Through,
+		})
+	}
+	return path
+}
+
+// FindAllPaths returns all distinct paths between two tables with at
+// most maxDepth joins, ordered by the number of joins, then the total
+// edge weight and then the table and column names along the path
+func (s *DBSchema) FindAllPaths(from, to string, maxDepth int) ([][]TPath, error) {
+	s = s.current()
+
+	if maxDepth < 1 {
+		return nil, fmt.Errorf("max depth must be at least 1: %d", maxDepth)
+	}
+
+	fl, err := s.pathEdges(from, ErrFromEdgeNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	tl, err := s.pathEdges(to, ErrToEdgeNotFound)
+	if err != nil {
+		return nil, err
+	}
+	if len(fl) == 0 || len(tl) == 0 {
+		return nil, s.noPath(from, to, "", fl, tl)
+	}
+
+	all := s.allPaths(fl, tl, maxDepth)
+	if len(all) == 0 {
+		return nil, s.noPath(from, to, "", fl, tl)
+	}
+
+	paths := make([][]TPath, len(all))
+	for i, fp := range all {
+		paths[i] = fp.path
+	}
+	return paths, nil
+}
+
+// allPaths returns the sorted distinct paths between the tables of
+// the from and to edges with at most maxDepth joins
+func (s *DBSchema) allPaths(fl, tl []edgeInfo, maxDepth int) []foundPath {
+	var all []foundPath
+	seen := make(map[string]struct{})
+
+	for _, f := range fl {
+		first := make(map[int32]struct{}, len(f.edgeIDs))
+		for _, eid := range f.edgeIDs {
+			first[eid] = struct{}{}
+		}
+
+		for _, t := range tl {
+			visited := map[int32]struct{}{f.nodeID: {}}
+
+			s.walkPaths(f.nodeID, t.nodeID, maxDepth, first, visited, nil,
+				func(edges []int32) {
+					fp := s.newFoundPath(edges)
+					if _, ok := seen[fp.key]; ok {
+						return
+					}
+					seen[fp.key] = struct{}{}
+					all = append(all, fp)
+				})
+		}
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		a, b := all[i], all[j]
+		if len(a.path) != len(b.path) {
+			return len(a.path) < len(b.path)
+		}
+		if a.weight != b.weight {
+			return a.weight < b.weight
+		}
+		return a.key < b.key
+	})
+	return all
+}
+
+// foundPath is a path found by FindAllPaths with its sort keys
+type foundPath struct {
+	edges  []int32
+	path   []TPath
+	weight int32
+	key    string
+}
+
+// newFoundPath returns a found path for the edges
+func (s *DBSchema) newFoundPath(edges []int32) foundPath {
+	fp := foundPath{edges: edges, path: s.edgesToPath(edges)}
+
+	var sb strings.Builder
+	for i, eid := range edges {
+		e := s.allEdges[eid]
+		fp.weight += e.Weight
+
+		p := fp.path[i]
+		if i != 0 {
+			sb.WriteString(" > ")
+		}
+		sb.WriteString(p.LT.String() + "." + p.LC.Name + "=" + p.RT.String() + "." + p.RC.Name)
+		sb.WriteString(":" + p.Rel.String())
+	}
+	fp.key = sb.String()
+	return fp
+}
+
+// walkPaths calls fn with the edges of every path from node n to node
+// to that does not visit a node twice, the first edge must be one of first
+func (s *DBSchema) walkPaths(
+	n, to int32,
+	depth int,
+	first map[int32]struct{},
+	visited map[int32]struct{},
+	edges []int32,
+	fn func([]int32),
+) {
+	if depth == 0 {
+		return
+	}
+
+	for _, m := range s.relationshipGraph.Connections(n) {
+		if _, ok := visited[m]; ok && m != to {
+			continue
+		}
+
+		for _, e := range s.relationshipGraph.GetEdges(n, m) {
+			if len(edges) == 0 {
+				if _, ok := first[e.ID]; !ok {
+					continue
+				}
+			}
+
+			ne := append(edges[:len(edges):len(edges)], e.ID)
+			if m == to {
+				fn(ne)
+				continue
+			}
+
+			visited[m] = struct{}{}
+			s.walkPaths(m, to, depth-1, first, visited, ne, fn)
+			delete(visited, m)
+		}
+	}
+}
+
+// graphResult represents a graph result
+type graphResult struct {
+	from, to edgeInfo
+	edges    []int32
+}
+
+// between finds a path between two tables, ErrPathTooDeep when the
+// only paths are longer than the depth limit
+func (s *DBSchema) between(ctx context.Context, from, to []edgeInfo, through string) (res graphResult, err error) {
+	// TODO: picking a path
+	// 1. first look for a direct edge to other table
+	// 2. then find shortest path using relevant edges
+
+	if s.costPaths {
+		return s.cheapestPath(ctx, from, to, through)
+	}
+
+	var tooDeep bool
+	for _, f := range from {
+		for _, t := range to {
+			res, err = s.pickPath(ctx, f, t, through)
+			switch err {
+			case ErrPathNotFound:
+				continue
+			case ErrPathTooDeep:
+				tooDeep = true
+				continue
+			default:
+				return
+			}
+		}
+	}
+	if tooDeep {
+		return res, ErrPathTooDeep
+	}
+	return res, ErrPathNotFound
+}
+
+// limitedPaths returns the sorted paths between two nodes with at most the
+// depth limit of joins, ErrPathTooDeep when there are none within it but
+// the nodes are connected
+func (s *DBSchema) limitedPaths(ctx context.Context, from, to int32) ([][]int32, error) {
+	paths, cut, err := s.relationshipGraph.AllPathsLimit(ctx, from, to, s.maxPathDepth)
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 && cut && s.relationshipGraph.Connected(from, to) {
+		return nil, ErrPathTooDeep
+	}
+	return s.sortPaths(paths), nil
+}
+
+// pickPath picks a path between two tables
+func (s *DBSchema) pickPath(ctx context.Context, from, to edgeInfo, through string) (res graphResult, err error) {
+	res.from = from
+	res.to = to
+
+	fn := from.nodeID
+	tn := to.nodeID
+	paths, err := s.limitedPaths(ctx, fn, tn)
+	if err != nil {
+		return
+	}
+
+	if through != "" {
+		paths, err = s.pickThroughPath(paths, through)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, path := range paths {
+		edges, ok := s.pickEdges(path, from, to)
+		if ok {
+			res.edges = edges
+			return
+		}
+	}
+	return res, ErrPathNotFound
+}
+
+// cheapestPath picks the path with the lowest join cost out of all
+// the paths between the two tables
+func (s *DBSchema) cheapestPath(ctx context.Context, from, to []edgeInfo, through string) (res graphResult, err error) {
+	var min int64 = -1
+	var tooDeep bool
+
+	for _, f := range from {
+		for _, t := range to {
+			var paths [][]int32
+			paths, err = s.limitedPaths(ctx, f.nodeID, t.nodeID)
+			if err == ErrPathTooDeep {
+				tooDeep = true
+				continue
+			}
+			if err != nil {
+				return
+			}
+
+			if through != "" {
+				if paths, err = s.pickThroughPath(paths, through); err != nil {
+					return
+				}
+			}
+
+			for _, path := range paths {
+				edges, ok := s.pickEdges(path, f, t)
+				if !ok {
+					continue
+				}
+				if c := s.pathCost(edges); min == -1 || c < min {
+					min = c
+					res = graphResult{from: f, to: t, edges: edges}
+				}
+			}
+		}
+	}
+
+	switch {
+	case min != -1:
+		return res, nil
+	case tooDeep:
+		return res, ErrPathTooDeep
+	}
+	return res, ErrPathNotFound
+}
+
+// sortPaths orders the paths between two tables by the number of joins
+// and then by the schema and name of the tables along them, the first
+// of the shortest paths picked is the same whatever the order the
+// tables were added in
+func (s *DBSchema) sortPaths(paths [][]int32) [][]int32 {
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := paths[i], paths[j]
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		for k := range a {
+			if a[k] != b[k] {
+				return tableLess(s.tables[a[k]], s.tables[b[k]])
+			}
+		}
+		return false
+	})
+	return paths
+}
+
+// lineLess orders the lines between two tables by weight, then the ones
+// on a column named after the table it references first eg. user_id over
+// editor_id for users and then by the names of the columns they join on
+func (s *DBSchema) lineLess(a, b util.Edge) bool {
+	if a.Weight != b.Weight {
+		return a.Weight < b.Weight
+	}
+	ea, eb := s.getEdge(a.ID), s.getEdge(b.ID)
+	if na, nb := namedAfterTable(ea), namedAfterTable(eb); na != nb {
+		return na
+	}
+	switch {
+	case ea.L.Name != eb.L.Name:
+		return ea.L.Name < eb.L.Name
+	case ea.R.Name != eb.R.Name:
+		return ea.R.Name < eb.R.Name
+	case ea.CName != eb.CName:
+		return ea.CName < eb.CName
+	}
+	return ea.Type < eb.Type
+}
+
+// namedAfterTable returns true if the column of an edge is named after
+// one of the tables it joins
+func namedAfterTable(e TEdge) bool {
+	n := GetRelName(e.CName)
+	return n == Singular(e.LT.Name) || n == Singular(e.RT.Name)
+}
+
+// pathCost estimates the cost of joining along the edges as the sum of
+// the rows of each joined table scaled by the edge weight
+func (s *DBSchema) pathCost(edges []int32) int64 {
+	var cost int64
+	for _, eid := range edges {
+		e := s.allEdges[eid]
+		rows := s.tables[e.To].RowCount
+		if rows < 1 {
+			rows = 1
+		}
+		cost += int64(e.Weight) * rows
+	}
+	return cost
+}
+
+// pickEdges picks edges between two tables
+func (s *DBSchema) pickEdges(path []int32, from, to edgeInfo) (edges []int32, allFound bool) {
+	pathLen := len(path)
+	peID := int32(-2) // must be -2 so does not match default -1
+
+	for i := 1; i < pathLen; i++ {
+		fn := path[i-1]
+		tn := path[i]
+		lines := s.relationshipGraph.GetEdges(fn, tn)
+		all := lines
+
+		// s.PrintLines(lines)
+
+		if i == pathLen-1 {
+			lines = s.endLines(lines, to)
+		}
+
+		switch {
+		case i == 1:
+			v := s.pickLine(lines, from, peID)
+			if v == nil {
+				v = s.pickLine(all, from, peID)
+			}
+			if v != nil {
+				edges = append(edges, v.ID)
+				peID = v.ID
+			} else {
+				return
+			}
+
+		case i == (pathLen - 1):
+			if v := s.pickLine(lines, to, peID); v != nil {
+				edges = append(edges, v.ID)
+				peID = v.ID
+
+			} else {
+				v := s.minWeightedLine(lines, peID)
+				edges = append(edges, v.ID)
+				peID = v.ID
+			}
+
+		default:
+			v := s.minWeightedLine(lines, peID)
+			edges = append(edges, v.ID)
+			peID = v.ID
+		}
+	}
+	allFound = true
+	return
+}
+
+// pickThroughPath picks a path through a node
+func (s *DBSchema) pickThroughPath(paths [][]int32, through string) ([][]int32, error) {
+	var npaths [][]int32
+
+	if len(paths) == 1 && len(paths[0]) == 2 {
+		return paths, nil
+	}
+
+	v, ok := s.findNode(through)
+	if !ok {
+		return nil, ErrThoughNodeNotFound
+	}
+
+	for i := range paths {
+		for j := range paths[i] {
+			if paths[i][j] == v.nodeID {
+				npaths = append(npaths, paths[i])
+			}
+		}
+	}
+	return npaths, nil
+}
+
+// pickLine picks the line between two tables that is one of the edges of
+// the edge info and not the way back along the previous line, the first
+// by lineLess when there are more
+func (s *DBSchema) pickLine(lines []util.Edge, ei edgeInfo, peID int32) *util.Edge {
+	var line *util.Edge
+	for i, v := range lines {
+		if v.OppID == peID {
+			continue
+		}
+		for _, eid := range ei.edgeIDs {
+			if v.ID == eid && (line == nil || s.lineLess(v, *line)) {
+				line = &lines[i]
+			}
+		}
+	}
+	return line
+}
+
+// endLines returns the lines that are the reverse of an edge the path
+// ends at, eg. the foreign key of a relationship name like "buyer" or of
+// a relationship alias, all the lines when there are none
+func (s *DBSchema) endLines(lines []util.Edge, to edgeInfo) []util.Edge {
+	var el []util.Edge
+	for _, v := range lines {
+		if s.endsAt(v, to) {
+			el = append(el, v)
+		}
+	}
+	if len(el) == 0 {
+		return lines
+	}
+	return el
+}
+
+// endsAt returns true when the line is the reverse of an edge of the
+// end of a path
+func (s *DBSchema) endsAt(v util.Edge, to edgeInfo) bool {
+	for _, eid := range to.edgeIDs {
+		if v.OppID == eid {
+			return true
+		}
+	}
+	return false
+}
+
+// PathToRel converts a table path to a relationship
+func PathToRel(p TPath) DBRel {
+	return DBRel{
+		Type:    p.Rel,
+		Left:    DBRelLeft{Ti: p.LT, Col: p.LC, Cols: p.LCs},
+		Right:   DBRelRight{Ti: p.RT, Col: p.RC, Cols: p.RCs},
+		Poly:    p.Poly,
+		Through: p.Through,
+	}
+}
+
+// minWeightedLine returns the line with the minimum weight, lines of the
+// same weight are ordered by lineLess
+func (s *DBSchema) minWeightedLine(lines []util.Edge, peID int32) *util.Edge {
+	var line *util.Edge
+
+	for i, v := range lines {
+		if v.Weight < 100 && v.OppID != peID && (line == nil || s.lineLess(v, *line)) {
+			line = &lines[i]
+		}
+	}
+
+	if line == nil && len(lines) != 0 {
+		return &lines[0]
+	}
+
+	return line
+}
+
+// PrintLines prints the graph lines
+func (s *DBSchema) PrintLines(lines []util.Edge) {
+	s = s.current()
+	for _, v := range lines {
+		e := s.allEdges[v.ID]
+		f := s.tables[e.From]
+		t := s.tables[e.To]
+
+		fmt.Printf("- (EdgeID: %d, OppEdge: %d, W:%d, N:%s) %s TableID:%d -> %s TableID:%d\n",
+			v.ID, v.OppID, v.Weight, e.name, f.Name, e.From, t.Name, e.To)
+	}
+	fmt.Println("---")
+}
+
+// PrintEdgeInfo prints edge info
+func (s *DBSchema) PrintEdgeInfo(e edgeInfo) {
+	s = s.current()
+	t := s.tables[e.nodeID]
+	fmt.Printf("-- EdgeInfo %s %+v\n", t.Name, e.edgeIDs)
+
+	// for _, id := range e.edgeIDs {
+	// 	e := s.ae[id]
+	// }
+}
+
+// String returns a string representation of a table path
+func (tp *TPath) String() string {
+	return fmt.Sprintf("(%s) %s ==> %s ==> (%s) %s",
+		tp.LT.String(), tp.LC.String(),
+		tp.Rel.String(),
+		tp.RT.String(), tp.RC.String())
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// blogTestSchema returns users with posts and comments on posts by users
+func blogTestSchema(t *testing.T, opts ...Option) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("users", "id pk", "name").
+		Table("posts", "id pk", "user_id notnull", "title").
+		Table("comments", "id pk", "post_id notnull", "user_id notnull", "body").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		FK("comments.user_id", "users.id").
+		BuildSchema(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFindAllPaths(t *testing.T) {
+	s := blogTestSchema(t)
+
+	paths, err := s.FindAllPaths("comments", "users", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("got paths %v", paths)
+	}
+	// the shortest path comes first
+	if len(paths[0]) != 1 || paths[0][0].LC.Name != "user_id" {
+		t.Errorf("got first path %v", pathString(paths[0]))
+	}
+	if len(paths[1]) != 2 || paths[1][0].RT.Name != "posts" {
+		t.Errorf("got second path %v", pathString(paths[1]))
+	}
+
+	// the order is the same every time
+	again, _ := s.FindAllPaths("comments", "users", 2)
+	for i := range paths {
+		if pathString(paths[i]) != pathString(again[i]) {
+			t.Errorf("path %d: got %s, then %s", i, pathString(paths[i]), pathString(again[i]))
+		}
+	}
+
+	if paths, _ := s.FindAllPaths("comments", "users", 1); len(paths) != 1 {
+		t.Errorf("got paths %v with one join", paths)
+	}
+	if _, err := s.FindAllPaths("comments", "users", 0); err == nil {
+		t.Error("want an error with no joins")
+	}
+	if _, err := s.FindAllPaths("comments", "missing", 2); err == nil {
+		t.Error("want an error for a missing table")
+	}
+}
+
+func TestFindPathVia(t *testing.T) {
+	s := blogTestSchema(t)
+
+	path, err := s.FindPathVia("comments", "users", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 || path[0].RT.Name != "posts" || path[1].RT.Name != "users" {
+		t.Errorf("got path %s", pathString(path))
+	}
+
+	// without via tables it is the shortest path
+	if path, err := s.FindPathVia("comments", "users"); err != nil || len(path) != 1 {
+		t.Errorf("got path %s, %v", pathString(path), err)
+	}
+
+	if _, err := s.FindPathVia("comments", "users", "missing"); !errors.Is(err, ErrThoughNodeNotFound) {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// DBEnum holds the allowed values of an enum column
+type DBEnum struct {
+	Schema string
+	Table  string
+	Column string
+	Values []string
+}
+
+// DiscoverEnums returns the enum columns of a database and their values
+func DiscoverEnums(ctx context.Context, db Querier, dbtype string) ([]DBEnum, error) {
+	var sqlStmt string
+	var mysql bool
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlEnumsStmt
+		mysql = true
+	case "", "postgres":
+		sqlStmt = postgresEnumsStmt
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching enums: %s", err)
+	}
+	defer rows.Close()
+
+	var enums []DBEnum
+	em := make(map[string]int)
+
+	for rows.Next() {
+		var es, et, ec, v string
+
+		if err = rows.Scan(&es, &et, &ec, &v); err != nil {
+			return nil, err
+		}
+
+		k := (es + ":" + et + ":" + ec)
+		i, ok := em[k]
+		if !ok {
+			enums = append(enums, DBEnum{Schema: es, Table: et, Column: ec})
+			i = len(enums) - 1
+			em[k] = i
+		}
+
+		// mysql returns the column type eg. enum('a','b')
+		if mysql {
+			enums[i].Values = append(enums[i].Values, parseMySQLEnum(v)...)
+		} else {
+			enums[i].Values = append(enums[i].Values, v)
+		}
+	}
+
+	return enums, rows.Err()
+}
+
+// parseMySQLEnum returns the values of a mysql enum column type
+func parseMySQLEnum(ct string) []string {
+	i := strings.IndexByte(ct, '(')
+	if i == -1 || !strings.HasSuffix(ct, ")") {
+		return nil
+	}
+	s := ct[(i + 1):(len(ct) - 1)]
+
+	var values []string
+	var sb strings.Builder
+	var quoted bool
+
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '\'' && !quoted:
+			quoted = true
+		case ch == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			sb.WriteByte(ch)
+			i++
+		case ch == '\'':
+			quoted = false
+			values = append(values, sb.String())
+			sb.Reset()
+		case quoted:
+			sb.WriteByte(ch)
+		}
+	}
+	return values
+}
+
+// addEnums sets the allowed values of enum columns
+func (di *DBInfo) addEnums(enums []DBEnum) {
+	for _, e := range enums {
+		c, err := di.GetColumn(e.Schema, e.Table, e.Column)
+		if err != nil {
+			continue
+		}
+		c.Enum = e.Values
+	}
+}
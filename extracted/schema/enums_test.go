@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestDiscoverEnums(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo:        {{140000, "public", "shop"}},
+		postgresColumnsStmt: {columnRow("public", "orders", "status", "order_status", true, false)},
+		postgresEnumsStmt: {
+			{"public", "orders", "status", "new"},
+			{"public", "orders", "status", "paid"},
+			{"public", "missing", "status", "new"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := di.GetColumn("public", "orders", "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Enum, []string{"new", "paid"}) {
+		t.Errorf("got enum %v", c.Enum)
+	}
+}
+
+func TestDiscoverEnumsMySQL(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlEnumsStmt: {{"shop", "orders", "size", "enum('s','m','it''s, big')"}},
+	}}
+	enums, err := DiscoverEnums(context.Background(), q, "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(enums) != 1 || !reflect.DeepEqual(enums[0].Values, []string{"s", "m", "it's, big"}) {
+		t.Errorf("got enums %v", enums)
+	}
+
+	// other databases have no enum types
+	if enums, err := DiscoverEnums(context.Background(), q, "sqlite"); err != nil || enums != nil {
+		t.Errorf("got enums %v, %v", enums, err)
+	}
+}
+
+func TestParseMySQLEnum(t *testing.T) {
+	for in, want := range map[string][]string{
+		"enum('a')":    {"a"},
+		"enum('','b')": {"", "b"},
+		"varchar(10)":  nil,
+		"enum(":        nil,
+	} {
+		if got := parseMySQLEnum(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseMySQLEnum(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
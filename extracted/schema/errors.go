@@ -0,0 +1,203 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxCandidates is the number of similar names an ErrTableNotFound lists
+const maxCandidates = 3
+
+// ErrTableNotFound is returned when a name is neither a table nor an
+// alias of one, Candidates are the most similar table names
+type ErrTableNotFound struct {
+	Name       string
+	Candidates []string
+	err        error
+}
+
+// Error returns the error message with the candidates
+func (e *ErrTableNotFound) Error() string {
+	msg := "table not found: " + e.Name
+	if len(e.Candidates) != 0 {
+		msg += " (did you mean " + strings.Join(e.Candidates, ", ") + "?)"
+	}
+	return msg
+}
+
+// Is makes errors.Is match any ErrTableNotFound
+func (e *ErrTableNotFound) Is(target error) bool {
+	_, ok := target.(*ErrTableNotFound)
+	return ok
+}
+
+// Unwrap returns ErrFromEdgeNotFound, ErrToEdgeNotFound or
+// ErrThoughNodeNotFound for the tables of a path
+func (e *ErrTableNotFound) Unwrap() error {
+	return e.err
+}
+
+// ErrNoPath is returned when no path joins two tables, FromTables and
+// ToTables are the tables each of them is connected to so a missing
+// foreign key between the two groups can be spotted
+type ErrNoPath struct {
+	From, To, Through    string
+	FromTables, ToTables []string
+}
+
+// Error returns the error message with the size of both groups
+func (e *ErrNoPath) Error() string {
+	msg := "path not found: " + e.From + " -> " + e.To
+	if e.Through != "" {
+		msg += " through " + e.Through
+	}
+	return fmt.Sprintf("%s (%s is connected to %d tables, %s to %d)",
+		msg, e.From, len(e.FromTables), e.To, len(e.ToTables))
+}
+
+// Is makes errors.Is match any ErrNoPath
+func (e *ErrNoPath) Is(target error) bool {
+	_, ok := target.(*ErrNoPath)
+	return ok
+}
+
+// Unwrap returns ErrPathNotFound
+func (e *ErrNoPath) Unwrap() error {
+	return ErrPathNotFound
+}
+
+// ErrAmbiguousColumn is returned by FindColumn when a column name
+// without a table is a column of more than one table
+type ErrAmbiguousColumn struct {
+	Column string
+	Tables []string
+}
+
+// Error returns the error message listing the tables
+func (e *ErrAmbiguousColumn) Error() string {
+	return fmt.Sprintf("ambiguous column %s: found in %s", e.Column, strings.Join(e.Tables, ", "))
+}
+
+// Is makes errors.Is match any ErrAmbiguousColumn
+func (e *ErrAmbiguousColumn) Is(target error) bool {
+	_, ok := target.(*ErrAmbiguousColumn)
+	return ok
+}
+
+// tableNotFound returns an ErrTableNotFound with the names most similar
+// to the name
+func (s *DBSchema) tableNotFound(name string, err error) error {
+	type candidate struct {
+		name string
+		dist int
+	}
+
+	schema, tn := s.splitTableName(name)
+	limit := len(tn) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	var list []candidate
+	seen := make(map[string]struct{})
+	for k := range s.tindex {
+		ks, kn, _ := strings.Cut(k, ":")
+		if ks != schema {
+			continue
+		}
+		if _, ok := seen[kn]; ok {
+			continue
+		}
+		seen[kn] = struct{}{}
+
+		d := editDistance(tn, kn)
+		if d > limit && !strings.Contains(kn, tn) {
+			continue
+		}
+		if schema != s.DBSchema() {
+			kn = schema + "." + kn
+		}
+		list = append(list, candidate{kn, d})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].dist != list[j].dist {
+			return list[i].dist < list[j].dist
+		}
+		return list[i].name < list[j].name
+	})
+	if len(list) > maxCandidates {
+		list = list[:maxCandidates]
+	}
+
+	e := &ErrTableNotFound{Name: name, err: err}
+	for _, c := range list {
+		e.Candidates = append(e.Candidates, c.name)
+	}
+	return e
+}
+
+// noPath returns an ErrNoPath with the tables connected to the tables of
+// the from and to edges
+func (s *DBSchema) noPath(from, to, through string, fl, tl []edgeInfo) error {
+	return &ErrNoPath{
+		From:       from,
+		To:         to,
+		Through:    through,
+		FromTables: s.connected(fl),
+		ToTables:   s.connected(tl),
+	}
+}
+
+// connected returns the sorted names of the tables reachable from the
+// nodes of the edges, the nodes themselves are left out
+func (s *DBSchema) connected(el []edgeInfo) []string {
+	seen := make(map[int32]struct{})
+	var queue []int32
+	for _, ei := range el {
+		if _, ok := seen[ei.nodeID]; !ok {
+			seen[ei.nodeID] = struct{}{}
+			queue = append(queue, ei.nodeID)
+		}
+	}
+	start := len(queue)
+
+	var names []string
+	for i := 0; i < len(queue); i++ {
+		n := queue[i]
+		if i >= start {
+			names = append(names, s.tables[n].String())
+		}
+		for _, c := range s.relationshipGraph.Connections(n) {
+			if _, ok := seen[c]; !ok {
+				seen[c] = struct{}{}
+				queue = append(queue, c)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// editDistance returns the Levenshtein distance between two names
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
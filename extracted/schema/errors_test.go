@@ -0,0 +1,131 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestErrTableNotFound(t *testing.T) {
+	s := blogTestSchema(t)
+
+	_, err := s.Find("", "usrs")
+	var te *ErrTableNotFound
+	if !errors.As(err, &te) {
+		t.Fatalf("got error %v", err)
+	}
+	if te.Name != "public.usrs" || !reflect.DeepEqual(te.Candidates, []string{"users"}) {
+		t.Errorf("got error %+v", te)
+	}
+	if err.Error() != "table not found: public.usrs (did you mean users?)" {
+		t.Errorf("got message %s", err)
+	}
+
+	// the tables of a path wrap the edge errors
+	_, err = s.FindPath("comments", "psts", "")
+	if !errors.Is(err, &ErrTableNotFound{}) || !errors.Is(err, ErrToEdgeNotFound) {
+		t.Errorf("got error %v", err)
+	}
+	_, err = s.FindPath("coments", "posts", "")
+	if !errors.Is(err, ErrFromEdgeNotFound) {
+		t.Errorf("got error %v", err)
+	}
+	_, err = s.FindPath("comments", "users", "likes")
+	if !errors.As(err, &te) || !errors.Is(err, ErrThoughNodeNotFound) || te.Name != "likes" {
+		t.Errorf("got error %v", err)
+	}
+
+	// unlike names have no candidates
+	_, err = s.Find("", "invoices")
+	if errors.As(err, &te) && len(te.Candidates) != 0 {
+		t.Errorf("got candidates %v", te.Candidates)
+	}
+}
+
+func TestErrNoPath(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id notnull").
+		Table("products", "id pk").
+		Table("prices", "id pk", "product_id notnull").
+		Table("notes", "id pk").
+		FK("posts.user_id", "users.id").
+		FK("prices.product_id", "products.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.FindPath("posts", "prices", "")
+	var ne *ErrNoPath
+	if !errors.As(err, &ne) || !errors.Is(err, ErrPathNotFound) {
+		t.Fatalf("got error %v", err)
+	}
+	if !reflect.DeepEqual(ne.FromTables, []string{"public.users"}) ||
+		!reflect.DeepEqual(ne.ToTables, []string{"public.products"}) {
+		t.Errorf("got error %+v", ne)
+	}
+	if err.Error() != "path not found: posts -> prices (posts is connected to 1 tables, prices to 1)" {
+		t.Errorf("got message %s", err)
+	}
+
+	// a table without relationships has no path
+	if _, err := s.FindPath("notes", "users", ""); !errors.Is(err, &ErrNoPath{}) {
+		t.Errorf("got error %v", err)
+	}
+	if _, err := s.FindAllPaths("users", "products", 3); !errors.Is(err, &ErrNoPath{}) {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestFindColumn(t *testing.T) {
+	s := blogTestSchema(t)
+
+	ti, c, err := s.FindColumn("posts.user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Name != "posts" || c.Name != "user_id" {
+		t.Errorf("got %s.%s", ti.Name, c.Name)
+	}
+	if _, _, err := s.FindColumn("public.comments.post_id"); err != nil {
+		t.Error(err)
+	}
+
+	_, c, err = s.FindColumn("post_id")
+	if err != nil || c.Table != "comments" {
+		t.Errorf("got column %s.%s, %v", c.Table, c.Name, err)
+	}
+
+	_, _, err = s.FindColumn("user_id")
+	var ae *ErrAmbiguousColumn
+	if !errors.As(err, &ae) || !errors.Is(err, &ErrAmbiguousColumn{}) {
+		t.Fatalf("got error %v", err)
+	}
+	if !reflect.DeepEqual(ae.Tables, []string{"comments", "posts"}) {
+		t.Errorf("got tables %v", ae.Tables)
+	}
+
+	for _, name := range []string{"missing", "posts.missing", "missing.id"} {
+		if _, _, err := s.FindColumn(name); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"users", "users", 0},
+		{"usrs", "users", 1},
+		{"post", "posts", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	} {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
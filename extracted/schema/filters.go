@@ -0,0 +1,47 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// TableFilter is a where expression every row of a table must match
+// in every query, join, update and delete of it whatever the role, eg.
+// TableFilter{Table: "orders", Filter: "{ tenant_id: { eq: $tenant }, deleted_at: { is_null: true } }"}
+// keeps each tenant to its own live orders. The variables are set by
+// the caller and not by the operation
+type TableFilter struct {
+	Schema string
+	Table  string
+	Filter string
+}
+
+// addTableFilters checks the tables of the filters and indexes them,
+// several filters on a table must all match
+func (s *DBSchema) addTableFilters(filters []TableFilter) error {
+	if len(filters) == 0 {
+		return nil
+	}
+	s.tableFilters = make(map[string][]string, len(filters))
+
+	for _, tf := range filters {
+		if tf.Schema == "" {
+			tf.Schema = s.schema
+		}
+		k := tf.Schema + ":" + tf.Table
+
+		if _, ok := s.tindex[k]; !ok {
+			return fmt.Errorf("table filter: table not found: %s.%s", tf.Schema, tf.Table)
+		}
+		if tf.Filter == "" {
+			return fmt.Errorf("table filter: empty filter for %s.%s", tf.Schema, tf.Table)
+		}
+		s.tableFilters[k] = append(s.tableFilters[k], tf.Filter)
+	}
+	return nil
+}
+
+// TableFilters returns the filters every row of a table must match
+func (s *DBSchema) TableFilters(t DBTable) []string {
+	s = s.current()
+	return s.tableFilters[t.Schema+":"+t.Name]
+}
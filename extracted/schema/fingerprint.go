@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a hash of the tables, columns, relationships and
+// functions of the schema. It only changes when the schema does, so
+// caches of compiled queries can be keyed by it and a migration leaves
+// nothing stale behind
+func (s *DBSchema) Fingerprint() string {
+	s = s.current()
+	return s.fingerprint
+}
+
+// setFingerprint computes the fingerprint, it is called once the schema
+// is built and after every change to it
+func (s *DBSchema) setFingerprint() {
+	var lines []string
+
+	for i, t := range s.tables {
+		if _, ok := s.removed[int32(i)]; ok {
+			continue
+		}
+		tl := fmt.Sprintf("table %s %s", t.String(), t.Type)
+		for _, c := range t.Columns {
+			lines = append(lines, fmt.Sprintf("%s column %s %s array=%t notnull=%t pk=%t uk=%t fk=%s.%s.%s",
+				tl, c.Name, c.Type, c.Array, c.NotNull, c.PrimaryKey, c.UniqueKey,
+				c.FKeySchema, c.FKeyTable, c.FKeyCol))
+		}
+		lines = append(lines, tl)
+	}
+
+	for id := range s.allEdges {
+		e := s.getEdge(id)
+		l := fmt.Sprintf("rel %s %s.%s -> %s.%s %s", e.name,
+			e.LT.String(), e.L.Name, e.RT.String(), e.R.Name, e.Type)
+		if e.Through.Ti.Name != "" {
+			l += " through " + e.Through.Ti.String()
+		}
+		lines = append(lines, l)
+	}
+
+	for _, fn := range s.dbFunctions {
+		lines = append(lines, "func "+fn.String())
+	}
+	sort.Strings(lines)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %d %s %s\n", s.dbType, s.version, s.schema, s.name)
+	h.Write([]byte(strings.Join(lines, "\n")))
+	s.fingerprint = hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,40 @@
+package schema
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	build := func(postCols ...string) string {
+		t.Helper()
+		s, err := NewTestSchema().
+			Table("users", "id pk", "email text notnull").
+			Table("posts", postCols...).
+			FK("posts.user_id", "users.id").
+			BuildSchema()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.Fingerprint()
+	}
+
+	fp := build("id pk", "user_id notnull", "title text")
+	if len(fp) != 64 {
+		t.Fatalf("got fingerprint %q", fp)
+	}
+	// the fingerprint does not depend on the order the maps are read in
+	for i := 0; i < 5; i++ {
+		if got := build("id pk", "user_id notnull", "title text"); got != fp {
+			t.Fatalf("fingerprint changed on rebuild: %s, %s", got, fp)
+		}
+	}
+
+	for _, cols := range [][]string{
+		{"id pk", "user_id notnull", "title text", "body text"},
+		{"id pk", "user_id notnull", "title varchar"},
+		{"id pk", "user_id notnull", "title text notnull"},
+		{"id pk", "user_id", "title text"},
+	} {
+		if build(cols...) == fp {
+			t.Errorf("%v: fingerprint not changed", cols)
+		}
+	}
+}
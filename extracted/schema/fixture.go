@@ -0,0 +1,162 @@
+package schema
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fixture is a schema written as YAML
+//
+//	schema: public
+//	tables:
+//	  - name: users
+//	    columns: [id pk, email text notnull unique]
+//	  - name: comments
+//	    columns:
+//	      - id pk
+//	      - name: user_id
+//	        fk: users.id
+//	      - body
+//	relationships:
+//	  - from: comments.reply_to_id
+//	    to: comments.id
+type fixture struct {
+	Type          string          `yaml:"type"`
+	Version       int             `yaml:"version"`
+	Name          string          `yaml:"name"`
+	Schema        string          `yaml:"schema"`
+	Tables        []fixtureTable  `yaml:"tables"`
+	Relationships []fixtureRel    `yaml:"relationships"`
+	VirtualTables []fixtureVTable `yaml:"virtual_tables"`
+}
+
+type fixtureTable struct {
+	Name    string          `yaml:"name"`
+	Columns []fixtureColumn `yaml:"columns"`
+}
+
+// fixtureColumn is a column given as a 'name [type] [flags]' string, as
+// for TestSchema.Table, or as a mapping
+type fixtureColumn struct {
+	col DBColumn
+	fk  string
+}
+
+type fixtureRel struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+type fixtureVTable struct {
+	Name       string `yaml:"name"`
+	IDColumn   string `yaml:"id_column"`
+	TypeColumn string `yaml:"type_column"`
+	FKeyColumn string `yaml:"fkey_column"`
+}
+
+// UnmarshalYAML reads a column from a string or a mapping
+func (fc *fixtureColumn) UnmarshalYAML(n *yaml.Node) error {
+	var err error
+	if n.Kind == yaml.ScalarNode {
+		fc.col, err = testColumn(n.Value)
+		return err
+	}
+
+	var v struct {
+		Name      string   `yaml:"name"`
+		Type      string   `yaml:"type"`
+		PK        bool     `yaml:"pk"`
+		Unique    bool     `yaml:"unique"`
+		NotNull   bool     `yaml:"notnull"`
+		Array     bool     `yaml:"array"`
+		FullText  bool     `yaml:"fulltext"`
+		FK        string   `yaml:"fk"`
+		Comment   string   `yaml:"comment"`
+		Sensitive []string `yaml:"sensitive"`
+	}
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+	if fc.col, err = testColumn(v.Name); err != nil {
+		return fmt.Errorf("line %d: %w", n.Line, err)
+	}
+
+	c := &fc.col
+	if v.Type != "" {
+		c.Type = v.Type
+	}
+	c.PrimaryKey = v.PK
+	c.UniqueKey = v.PK || v.Unique
+	c.NotNull = v.PK || v.NotNull
+	c.Array = v.Array
+	c.FullText = v.FullText
+	c.Comment = v.Comment
+	c.Sensitive = v.Sensitive
+	fc.fk = v.FK
+	return nil
+}
+
+// LoadFixture reads a DBInfo from a YAML fixture so schemas can be
+// shared as text by teams without access to the database. Unknown keys
+// are an error
+func LoadFixture(r io.Reader) (*DBInfo, error) {
+	var f fixture
+
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("error reading fixture: %w", err)
+	}
+
+	ts := NewTestSchema()
+	ts.dbType = f.Type
+	if f.Version != 0 {
+		ts.version = f.Version
+	}
+	if f.Name != "" {
+		ts.name = f.Name
+	}
+	if f.Schema != "" {
+		ts.schema = f.Schema
+	}
+
+	for _, ft := range f.Tables {
+		cols := make([]DBColumn, len(ft.Columns))
+		for i, fc := range ft.Columns {
+			cols[i] = fc.col
+			if fc.fk != "" {
+				ts.FK(ft.Name+"."+fc.col.Name, fc.fk)
+			}
+		}
+		if err := ts.addTable(ft.Name, cols); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range f.Relationships {
+		ts.FK(r.From, r.To)
+	}
+
+	for _, vt := range f.VirtualTables {
+		ts.VirtualTable(VirtualTable{
+			Name:       vt.Name,
+			IDColumn:   vt.IDColumn,
+			TypeColumn: vt.TypeColumn,
+			FKeyColumn: vt.FKeyColumn,
+		})
+	}
+	return ts.Build()
+}
+
+// LoadFixtureFile reads a DBInfo from a YAML fixture file
+func LoadFixtureFile(path string) (*DBInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadFixture(f)
+}
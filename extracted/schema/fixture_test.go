@@ -0,0 +1,61 @@
+package schema
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFixture(t *testing.T) {
+	di, err := LoadFixtureFile(filepath.Join("testdata", "blog.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.Type != "postgres" || di.Version != 150000 || di.Name != "blog" || len(di.Tables) != 3 {
+		t.Fatalf("got %s %d %s tables %v", di.Type, di.Version, di.Name, di.Tables)
+	}
+
+	c, err := di.GetColumn("public", "comments", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.NotNull || c.FKeyTable != "users" || c.FKeyCol != "id" {
+		t.Errorf("got column %+v", c)
+	}
+	c, _ = di.GetColumn("public", "comments", "body")
+	if c.Type != "varchar(200)" || c.Comment != "Text of the comment" {
+		t.Errorf("got column %+v", c)
+	}
+	c, _ = di.GetColumn("public", "users", "email")
+	if c.Type != "text" || !c.UniqueKey || !c.NotNull {
+		t.Errorf("got column %+v", c)
+	}
+
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range [][2]string{{"comments", "users"}, {"billing.invoices", "users"}} {
+		if _, err := s.FindPath(p[0], p[1], ""); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestLoadFixtureErrors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"unknown key":     "tables:\n  - name: users\n    colums: [id pk]\n",
+		"unknown flag":    "tables:\n  - name: users\n    columns: [id pk primary]\n",
+		"column name":     "tables:\n  - name: users\n    columns:\n      - type: text\n",
+		"duplicate table": "tables:\n  - name: users\n    columns: [id pk]\n  - name: users\n    columns: [id pk]\n",
+		"missing fk":      "tables:\n  - name: users\n    columns:\n      - name: org_id\n        fk: orgs.id\n",
+		"bad yaml":        "tables: [",
+	} {
+		if _, err := LoadFixture(strings.NewReader(doc)); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+	if _, err := LoadFixtureFile(filepath.Join("testdata", "missing.yaml")); err == nil {
+		t.Error("want an error for a missing file")
+	}
+}
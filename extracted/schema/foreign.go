@@ -0,0 +1,69 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DBForeignTable is a foreign table and the server it is read from, eg.
+// a table of another database with postgres_fdw or a file with file_fdw
+type DBForeignTable struct {
+	Schema        string            `json:"schema,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Server        string            `json:"server"`
+	Wrapper       string            `json:"wrapper"`                 // foreign data wrapper eg. postgres_fdw
+	Options       map[string]string `json:"options,omitempty"`       // eg. schema_name and table_name
+	ServerOptions map[string]string `json:"serverOptions,omitempty"` // eg. host and dbname
+}
+
+// DiscoverForeignTables returns the foreign tables of a database
+func DiscoverForeignTables(ctx context.Context, db Querier, dbtype string) ([]DBForeignTable, error) {
+	switch dbtype {
+	case "", "postgres":
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Query(ctx, postgresForeignTablesStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching foreign tables: %s", err)
+	}
+	defer rows.Close()
+
+	var fts []DBForeignTable
+
+	for rows.Next() {
+		var ft DBForeignTable
+		var opts, srvOpts string
+
+		err = rows.Scan(&ft.Schema, &ft.Name, &ft.Server, &ft.Wrapper, &opts, &srvOpts)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(opts), &ft.Options); err != nil {
+			return nil, fmt.Errorf("error fetching foreign tables: %s", err)
+		}
+		if err := json.Unmarshal([]byte(srvOpts), &ft.ServerOptions); err != nil {
+			return nil, fmt.Errorf("error fetching foreign tables: %s", err)
+		}
+		fts = append(fts, ft)
+	}
+
+	return fts, rows.Err()
+}
+
+// addForeignTables marks the tables that are foreign tables. Foreign
+// tables cannot have keys so they are related to local tables by
+// virtual or inferred relationships
+func (di *DBInfo) addForeignTables(fts []DBForeignTable) {
+	for _, ft := range fts {
+		tid, ok := di.tableMap[(ft.Schema + ":" + ft.Name)]
+		if !ok {
+			continue
+		}
+		t := &di.Tables[tid]
+		t.Type = "foreign"
+		t.Foreign = ft
+	}
+}
@@ -0,0 +1,105 @@
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// foreignQuerier returns local customers, orders in a foreign table of
+// another database and accounts in one without a key
+func foreignQuerier() *routeQuerier {
+	return &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "shop"}},
+		postgresColumnsStmt: {
+			columnRow("public", "customers", "id", "bigint", true, true),
+			columnRow("public", "customers", "account_id", "bigint", false, false),
+			columnRow("public", "remote_orders", "id", "bigint", false, false),
+			columnRow("public", "remote_orders", "customer_id", "bigint", false, false),
+			columnRow("public", "accounts", "id", "bigint", false, false),
+		},
+		postgresForeignTablesStmt: {
+			{"public", "remote_orders", "billing", "postgres_fdw",
+				`{"schema_name": "sales", "table_name": "orders"}`, `{"host": "billing.internal", "dbname": "billing"}`},
+			{"public", "accounts", "billing", "postgres_fdw", `{}`, `{}`},
+		},
+	}}
+}
+
+func TestForeignTables(t *testing.T) {
+	di, err := GetDBInfoFrom(context.Background(), foreignQuerier(), "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ti, err := di.GetTable("public", "remote_orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DBForeignTable{
+		Schema:        "public",
+		Name:          "remote_orders",
+		Server:        "billing",
+		Wrapper:       "postgres_fdw",
+		Options:       map[string]string{"schema_name": "sales", "table_name": "orders"},
+		ServerOptions: map[string]string{"host": "billing.internal", "dbname": "billing"},
+	}
+	if ti.Type != "foreign" || !reflect.DeepEqual(ti.Foreign, want) {
+		t.Errorf("got table of type %q with %+v", ti.Type, ti.Foreign)
+	}
+	if ti, _ := di.GetTable("public", "customers"); ti.Type != "" {
+		t.Errorf("got local table of type %q", ti.Type)
+	}
+
+	// the server of a foreign table is saved with it
+	b, err := json.Marshal(di)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var di2 DBInfo
+	if err := json.Unmarshal(b, &di2); err != nil {
+		t.Fatal(err)
+	}
+	if ti, err := di2.GetTable("public", "remote_orders"); err != nil || !reflect.DeepEqual(ti.Foreign, want) {
+		t.Errorf("got %+v, %v after a JSON round trip", ti.Foreign, err)
+	}
+}
+
+// foreign tables have no constraints, their relationships are inferred
+func TestForeignTableRels(t *testing.T) {
+	di, err := GetDBInfoFrom(context.Background(), foreignQuerier(), "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDBSchema(di, nil, WithInferredRels(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range [][2]string{{"remote_orders", "customers"}, {"customers", "accounts"}} {
+		path, err := s.FindPath(tc[0], tc[1], "")
+		if err != nil {
+			t.Errorf("%s -> %s: %v", tc[0], tc[1], err)
+			continue
+		}
+		if len(path) != 1 {
+			t.Errorf("%s -> %s: got path %s", tc[0], tc[1], pathString(path))
+		}
+	}
+}
+
+func TestDiscoverForeignTablesDialects(t *testing.T) {
+	q := &routeQuerier{}
+	fts, err := DiscoverForeignTables(context.Background(), q, "mysql")
+	if err != nil || fts != nil || len(q.queries) != 0 {
+		t.Errorf("got %v, %v and statements %v", fts, err, q.queries)
+	}
+
+	q = &routeQuerier{rows: map[string][][]interface{}{
+		postgresForeignTablesStmt: {{"public", "t", "s", "file_fdw", `not json`, `{}`}},
+	}}
+	if _, err := DiscoverForeignTables(context.Background(), q, "postgres"); err == nil {
+		t.Error("want an error for bad options")
+	}
+}
@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"regexp"
+)
+
+// DBSearchColumn is a column full text search can match, a tsvector
+// column or a text column with a to_tsvector expression index
+type DBSearchColumn struct {
+	Col DBColumn
+
+	// Config is the text search configuration of an expression index,
+	// the query must be parsed with the same one for the index to be
+	// used. It is empty for tsvector columns
+	Config string
+
+	// Indexed is true when a GIN or GiST index covers the column
+	Indexed bool
+}
+
+// tsvectorExpr matches the to_tsvector expression of an index column
+// as returned by pg_get_indexdef eg. to_tsvector('english'::regconfig, name)
+// or to_tsvector('english'::regconfig, (title)::text) for varchar columns
+var tsvectorExpr = regexp.MustCompile(`^to_tsvector\('([^']+)'(?:::regconfig)?,\s*\(?"?([^"()]+?)"?\)?(?:::text)?\)$`)
+
+// SearchColumns returns the columns of a table that can be searched,
+// tsvector columns come first followed by the text columns of
+// to_tsvector expression indexes, masked columns are left out
+func (ti *DBTable) SearchColumns() []DBSearchColumn {
+	var cols []DBSearchColumn
+
+	for _, c := range ti.FullText {
+		if c.Blocked || c.Mask != "" {
+			continue
+		}
+		sc := DBSearchColumn{Col: c}
+		for _, idx := range ti.Indexes {
+			if searchIndex(idx) && len(idx.Columns) != 0 && idx.Columns[0] == c.Name {
+				sc.Indexed = true
+			}
+		}
+		cols = append(cols, sc)
+	}
+
+	for _, idx := range ti.Indexes {
+		if !searchIndex(idx) || len(idx.Columns) != 1 {
+			continue
+		}
+		m := tsvectorExpr.FindStringSubmatch(idx.Columns[0])
+		if m == nil {
+			continue
+		}
+		c, ok := ti.ColumnExists(m[2])
+		if !ok || c.Blocked || c.Mask != "" {
+			continue
+		}
+		cols = append(cols, DBSearchColumn{Col: c, Config: m[1], Indexed: true})
+	}
+	return cols
+}
+
+// searchIndex returns true for the index methods used by full text
+// search, partial indexes are left out
+func searchIndex(idx DBIndex) bool {
+	return (idx.Method == "gin" || idx.Method == "gist") && idx.Predicate == ""
+}
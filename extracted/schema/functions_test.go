@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFunctionTables(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "blog"}},
+		postgresColumnsStmt: {
+			columnRow("public", "users", "id", "bigint", true, true),
+			columnRow("public", "posts", "id", "bigint", true, true),
+			columnRow("public", "posts", "user_id", "bigint", true, false, "public", "users", "id"),
+		},
+		postgresFunctionsStmt: {
+			{"recent_posts_1", "public", "recent_posts", "record", 1, "n", "integer", "IN"},
+			{"post_count_2", "public", "post_count", "bigint", 1, "uid", "bigint", "IN"},
+		},
+		postgresFunctionReturnsStmt: {
+			{"recent_posts_1", true, "public", "posts"},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ft, err := di.GetTable("public", "recent_posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ft.Type != "function" || !ft.Func.ReturnsSet || ft.Func.ReturnTable != "posts" {
+		t.Errorf("got function table %s %+v", ft.Type, ft.Func)
+	}
+	if len(ft.Columns) != 2 || len(ft.Func.Outputs) != 2 || len(ft.Func.Inputs) != 1 {
+		t.Fatalf("got columns %v outputs %v", ft.Columns, ft.Func.Outputs)
+	}
+	// a scalar function is no table
+	if _, err := di.GetTable("public", "post_count"); err == nil {
+		t.Error("want no table of a scalar function")
+	}
+
+	// the rows of the function join to what the rows of the table join to
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{"users", "posts"} {
+		if _, err := s.FindPath("recent_posts", to, ""); err != nil {
+			t.Errorf("recent_posts -> %s: %s", to, err)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBGenerated holds a generated column and the expression it is
+// computed from, Kind is either "stored" or "virtual"
+type DBGenerated struct {
+	Schema string
+	Table  string
+	Column string
+	Kind   string
+	Expr   string
+}
+
+// DiscoverGenerated returns the generated columns of a database
+func DiscoverGenerated(ctx context.Context, db Querier, dbtype string) ([]DBGenerated, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlGeneratedStmt
+	case "mssql":
+		sqlStmt = mssqlGeneratedStmt
+	case "sqlite":
+		sqlStmt = sqliteGeneratedStmt
+	case "cockroach", "cockroachdb":
+		// pg_attribute on cockroach has no attgenerated, computed
+		// columns are read as stored since information_schema does not
+		// tell the virtual ones apart
+		sqlStmt = cockroachGeneratedStmt
+	case "clickhouse":
+		sqlStmt = clickhouseGeneratedStmt
+	case "bigquery", "snowflake", "duckdb":
+		// the generated columns of duckdb are not in its catalog and
+		// bigquery and snowflake have none
+		return nil, nil
+	case "trino", "redshift":
+		// trino and redshift have no generated columns
+		return nil, nil
+	default:
+		sqlStmt = postgresGeneratedStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching generated columns: %s", err)
+	}
+	defer rows.Close()
+
+	var gens []DBGenerated
+
+	for rows.Next() {
+		var g DBGenerated
+
+		if err = rows.Scan(&g.Schema, &g.Table, &g.Column, &g.Kind, &g.Expr); err != nil {
+			return nil, err
+		}
+		gens = append(gens, g)
+	}
+
+	return gens, rows.Err()
+}
+
+// addGenerated marks generated columns
+func (di *DBInfo) addGenerated(gens []DBGenerated) {
+	for _, g := range gens {
+		c, err := di.GetColumn(g.Schema, g.Table, g.Column)
+		if err != nil {
+			continue
+		}
+		c.Generated = g.Kind
+		c.GenExpr = g.Expr
+	}
+}
+
+// InsertColumns returns the columns of a table that can be set in an
+// insert, generated columns and identity columns generated always are
+// left out
+func (ti *DBTable) InsertColumns() []DBColumn {
+	cols := make([]DBColumn, 0, len(ti.Columns))
+	for _, c := range ti.Columns {
+		if c.Generated == "" && c.Identity != "always" {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverGeneratedCockroach(t *testing.T) {
+	q := &fakeQuerier{rows: [][]interface{}{
+		{"public", "users", "full_name", "stored", "concat(first_name, ' ', last_name)"},
+	}}
+
+	for _, dbtype := range []string{"cockroach", "cockroachdb"} {
+		q.queries = nil
+		gens, err := DiscoverGenerated(context.Background(), q, dbtype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(q.queries) != 1 || q.queries[0] != cockroachGeneratedStmt {
+			t.Fatalf("%s: not queried with the cockroach statement", dbtype)
+		}
+		if strings.Contains(q.queries[0], "attgenerated") {
+			t.Errorf("%s: statement uses attgenerated", dbtype)
+		}
+
+		want := []DBGenerated{{Schema: "public", Table: "users", Column: "full_name",
+			Kind: "stored", Expr: "concat(first_name, ' ', last_name)"}}
+		if !reflect.DeepEqual(gens, want) {
+			t.Errorf("%s: got %+v, want %+v", dbtype, gens, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Tables returns the tables of the relationship graph sorted by schema
+// and name, blocked and removed tables are left out
+func (s *DBSchema) Tables() []DBTable {
+	s = s.current()
+
+	tables := make([]DBTable, 0, len(s.tables))
+	for i, t := range s.tables {
+		if _, ok := s.removed[int32(i)]; ok || t.Blocked {
+			continue
+		}
+		tables = append(tables, t)
+	}
+	sortTables(tables)
+	return tables
+}
+
+// GetRelationships returns the relationships from a table to the tables
+// it is directly related to, sorted by related table and column. Unlike
+// GetTableRels the relationships are not named and a relationship to a
+// table is returned for each of its foreign keys
+func (s *DBSchema) GetRelationships(t DBTable) ([]DBRel, error) {
+	s = s.current()
+
+	n, ok := s.tindex[(t.Schema + ":" + t.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table not found: %s", t.String())
+	}
+
+	var rels []DBRel
+	for _, m := range s.relationshipGraph.Connections(n.nodeID) {
+		for _, ge := range s.relationshipGraph.GetEdges(n.nodeID, m) {
+			// edges without an opposite only index a relationship by
+			// another name
+			if ge.OppID == -1 {
+				continue
+			}
+			rels = append(rels, edgeRel(s.getEdge(ge.ID)))
+		}
+	}
+
+	sort.SliceStable(rels, func(i, j int) bool {
+		a, b := rels[i], rels[j]
+		if a.Right.Ti.Schema != b.Right.Ti.Schema {
+			return a.Right.Ti.Schema < b.Right.Ti.Schema
+		}
+		if a.Right.Ti.Name != b.Right.Ti.Name {
+			return a.Right.Ti.Name < b.Right.Ti.Name
+		}
+		if a.Left.Col.Name != b.Left.Col.Name {
+			return a.Left.Col.Name < b.Left.Col.Name
+		}
+		return a.Right.Col.Name < b.Right.Col.Name
+	})
+	return rels, nil
+}
+
+// Neighbors returns the tables directly related to a table sorted by
+// schema and name, the table itself is one when it references itself
+func (s *DBSchema) Neighbors(t DBTable) ([]DBTable, error) {
+	s = s.current()
+
+	n, ok := s.tindex[(t.Schema + ":" + t.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table not found: %s", t.String())
+	}
+
+	var tables []DBTable
+	for _, m := range s.relationshipGraph.Connections(n.nodeID) {
+		if _, ok := s.removed[m]; ok {
+			continue
+		}
+		tables = append(tables, s.tables[m])
+	}
+	sortTables(tables)
+	return tables, nil
+}
+
+// edgeRel returns the relationship of an edge of the graph
+func edgeRel(e TEdge) DBRel {
+	return DBRel{
+		Type:    e.Type,
+		Left:    DBRelLeft{Ti: e.LT, Col: e.L, Cols: e.LCs},
+		Right:   DBRelRight{Ti: e.RT, Col: e.R, Cols: e.RCs},
+		Poly:    e.Poly,
+		Through: e.Through,
+	}
+}
+
+// sortTables sorts tables by schema and name
+func sortTables(tables []DBTable) {
+	sort.Slice(tables, func(i, j int) bool { return tableLess(tables[i], tables[j]) })
+}
@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// graphTestSchema has orders with a buyer and a seller, users managed by
+// users and audit logs left out of the graph
+func graphTestSchema(t *testing.T) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("users", "id pk", "manager_id").
+		Table("orders", "id pk", "buyer_id notnull", "seller_id notnull").
+		Table("audit_logs", "id pk", "user_id").
+		Table("settings", "id pk").
+		FK("users.manager_id", "users.id").
+		FK("orders.buyer_id", "users.id").
+		FK("orders.seller_id", "users.id").
+		FK("audit_logs.user_id", "users.id").
+		BuildSchema(WithBlockedTables("audit_logs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func names(tables []DBTable) string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = t.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestTables(t *testing.T) {
+	s := graphTestSchema(t)
+	// the blocked tables can still be looked up
+	if got := names(s.Tables()); got != "audit_logs orders settings users" {
+		t.Errorf("got tables %s", got)
+	}
+
+	if err := s.RemoveTable("settings"); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(s.Tables()); got != "audit_logs orders users" {
+		t.Errorf("got tables %s after removing settings", got)
+	}
+}
+
+func TestGetRelationships(t *testing.T) {
+	s := graphTestSchema(t)
+
+	tests := []struct {
+		table string
+		want  []string
+	}{
+		{"orders", []string{
+			"orders.buyer_id RelOneToOne users.id",
+			"orders.seller_id RelOneToOne users.id",
+		}},
+		{"users", []string{
+			"users.id RelOneToMany orders.buyer_id",
+			"users.id RelOneToMany orders.seller_id",
+			"users.id RelRecursive users.manager_id",
+			"users.manager_id RelRecursive users.id",
+		}},
+		{"audit_logs", nil},
+		{"settings", nil},
+	}
+	for _, tt := range tests {
+		ti, err := s.Find("", tt.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rels, err := s.GetRelationships(ti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range rels {
+			got = append(got, fmt.Sprintf("%s.%s %s %s.%s",
+				r.Left.Ti.Name, r.Left.Col.Name, r.Type, r.Right.Ti.Name, r.Right.Col.Name))
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got relationships\n%s\nwant\n%s", tt.table,
+				strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	s := graphTestSchema(t)
+
+	for table, want := range map[string]string{
+		"users":      "orders users",
+		"orders":     "users",
+		"audit_logs": "",
+		"settings":   "",
+	} {
+		ti, err := s.Find("", table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.Neighbors(ti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if names(got) != want {
+			t.Errorf("%s: got neighbors %q, want %q", table, names(got), want)
+		}
+	}
+
+	missing := DBTable{Schema: "public", Name: "people"}
+	if _, err := s.Neighbors(missing); err == nil {
+		t.Error("want an error for a missing table")
+	}
+	if _, err := s.GetRelationships(missing); err == nil {
+		t.Error("want an error for a missing table")
+	}
+}
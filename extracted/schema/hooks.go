@@ -0,0 +1,90 @@
+package schema
+
+import "log/slog"
+
+// Hooks are called as the entities of a schema are discovered so they
+// can be renamed, annotated or augmented in place, returning false
+// drops the entity
+type Hooks struct {
+	// OnColumnDiscovered is called by GetDBInfo with each column read
+	// from the catalog before the tables are built from them
+	OnColumnDiscovered func(c *DBColumn) bool
+
+	// OnTableDiscovered is called by GetDBInfo with each table once its
+	// views, comments, indexes and the rest of the catalog are set.
+	// Columns renamed or added by the hook are looked up by their names
+	OnTableDiscovered func(t *DBTable) bool
+
+	// OnRelationshipDiscovered is called by NewDBSchema with each
+	// relationship before it is added to the graph, including the ones
+	// of virtual, polymorphic and many-to-many relationships
+	OnRelationshipDiscovered func(rel *DBRel) bool
+}
+
+// WithInfoHooks runs the column and table hooks on the catalog read by
+// GetDBInfo, a DBInfo saved by WithCache is saved with them applied
+func WithInfoHooks(h Hooks) InfoOption {
+	return func(o *infoOptions) {
+		o.hooks = h
+	}
+}
+
+// WithHooks runs the relationship hook on the relationships added to
+// the graph by NewDBSchema and later by AddTable and AddRelationship
+func WithHooks(h Hooks) Option {
+	return func(o *schemaOptions) {
+		o.hooks = h
+	}
+}
+
+// columnHook returns the columns kept by the column hook
+func (h Hooks) columnHook(cols []DBColumn, log logger) []DBColumn {
+	if h.OnColumnDiscovered == nil {
+		return cols
+	}
+	kept := cols[:0]
+	for _, c := range cols {
+		if h.OnColumnDiscovered(&c) {
+			kept = append(kept, c)
+		} else {
+			log.debug("column skipped", slog.String("column", c.Schema+"."+c.Table+"."+c.Name), slog.String("reason", "hook"))
+		}
+	}
+	return kept
+}
+
+// tableHook keeps the tables kept by the table hook and indexes them
+// again by name
+func (h Hooks) tableHook(di *DBInfo, log logger) {
+	if h.OnTableDiscovered == nil {
+		return
+	}
+	tables := di.Tables[:0]
+	for _, t := range di.Tables {
+		if !h.OnTableDiscovered(&t) {
+			log.debug("table skipped", slog.String("table", t.String()), slog.String("reason", "hook"))
+			continue
+		}
+		t.colMap = make(map[string]int, len(t.Columns))
+		for i, c := range t.Columns {
+			t.colMap[c.Name] = i
+		}
+		tables = append(tables, t)
+	}
+
+	di.Tables = tables
+	di.tableMap = make(map[string]int, len(tables))
+	for i, t := range tables {
+		di.tableMap[(t.Schema + ":" + t.Name)] = i
+	}
+}
+
+// relHook returns the relationship as changed by the relationship hook
+// and false if it is dropped
+func (s *DBSchema) relHook(rel DBRel) (DBRel, bool) {
+	if s.onRel == nil {
+		return rel, true
+	}
+	ok := s.onRel(&rel)
+	return rel, ok
+}
@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestInfoHooks(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "users", "password", "varchar", true, false),
+			columnRow("shop", "tbl_orders", "id", "bigint", true, true),
+			columnRow("shop", "tbl_orders", "user_id", "bigint", false, false, "shop", "users", "id"),
+			columnRow("shop", "schema_migrations", "version", "varchar", true, true),
+		},
+	}}
+
+	h := Hooks{
+		OnColumnDiscovered: func(c *DBColumn) bool {
+			return c.Name != "password"
+		},
+		OnTableDiscovered: func(t *DBTable) bool {
+			if t.Name == "schema_migrations" {
+				return false
+			}
+			t.Name = strings.TrimPrefix(t.Name, "tbl_")
+			t.Comment = "discovered"
+			return true
+		},
+	}
+	di, err := GetDBInfoFrom(context.Background(), q, "mysql", nil, WithInfoHooks(h))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(di.Tables) != 2 {
+		t.Fatalf("got tables %v", di.Tables)
+	}
+	if _, err := di.GetColumn("shop", "users", "password"); err == nil {
+		t.Error("got the column dropped by the hook")
+	}
+	c, err := di.GetColumn("shop", "orders", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FKeyTable != "users" {
+		t.Errorf("got foreign key to %s", c.FKeyTable)
+	}
+	ti, err := di.GetTable("shop", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Comment != "discovered" {
+		t.Errorf("got comment %q", ti.Comment)
+	}
+}
+
+func TestRelHook(t *testing.T) {
+	var seen []string
+	h := Hooks{OnRelationshipDiscovered: func(rel *DBRel) bool {
+		seen = append(seen, rel.Left.Ti.Name+"."+rel.Left.Col.Name)
+		// comments are attached to posts only
+		return !(rel.Left.Ti.Name == "comments" && rel.Left.Col.Name == "user_id")
+	}}
+	s := blogTestSchema(t, WithHooks(h))
+
+	path, err := s.FindPath("comments", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 {
+		t.Errorf("got path %s, want it through posts", pathString(path))
+	}
+	if !strings.Contains(strings.Join(seen, " "), "comments.user_id") {
+		t.Errorf("hook not called with the dropped relationship, got %v", seen)
+	}
+
+	// relationships added later are passed to the hook
+	notes := noteTable()
+	seen = nil
+	if err := s.AddTable(notes); err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) == 0 || seen[0] != "notes.user_id" {
+		t.Errorf("got relationships %v passed to the hook", seen)
+	}
+}
@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// checkPatterns returns an error for the first table pattern of the
+// options that is not a valid glob pattern
+func (o *infoOptions) checkPatterns() error {
+	for _, p := range append(o.include[:len(o.include):len(o.include)], o.exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid table pattern: %s", p)
+		}
+	}
+	if o.audit != nil {
+		if err := o.audit.check(); err != nil {
+			return err
+		}
+	}
+	if o.sensitive != nil {
+		return o.sensitive.check()
+	}
+	return nil
+}
+
+// limited returns true when the options leave out some tables
+func (o *infoOptions) limited() bool {
+	return len(o.schemas) != 0 || len(o.tables) != 0 ||
+		len(o.include) != 0 || len(o.exclude) != 0
+}
+
+// included returns true when the options keep a table
+func (o *infoOptions) included(schema, table string) bool {
+	qn := schema + "." + table
+
+	if len(o.schemas) != 0 && !inStrings(o.schemas, schema) {
+		return false
+	}
+	if matchTable(o.exclude, schema, table) {
+		return false
+	}
+	if len(o.tables) == 0 && len(o.include) == 0 {
+		return true
+	}
+	return inStrings(o.tables, table) || inStrings(o.tables, qn) ||
+		matchTable(o.include, schema, table)
+}
+
+// filterColumns returns the columns of the tables the options keep,
+// foreign keys to tables left out are removed from the columns
+func (o *infoOptions) filterColumns(cols []DBColumn) []DBColumn {
+	if !o.limited() {
+		return cols
+	}
+
+	var fc []DBColumn
+	var skipped string
+	for _, c := range cols {
+		if !o.included(c.Schema, c.Table) {
+			if k := c.Schema + "." + c.Table; k != skipped {
+				o.log.debug("table skipped", slog.String("table", k), slog.String("reason", "not included"))
+				skipped = k
+			}
+			continue
+		}
+		if c.FKeyTable != "" {
+			fs := c.FKeySchema
+			if fs == "" {
+				fs = c.Schema
+			}
+			if !o.included(fs, c.FKeyTable) {
+				c.FKeySchema, c.FKeyTable, c.FKeyCol = "", "", ""
+				c.FKeyOnDelete, c.FKeyName = "", ""
+				c.FKRecursive = false
+			}
+		}
+		c.ID = int32(len(fc))
+		fc = append(fc, c)
+	}
+	return fc
+}
+
+// filterFunctions returns the functions of the schemas the options keep
+func (o *infoOptions) filterFunctions(funcs []DBFunction) []DBFunction {
+	if len(o.schemas) == 0 {
+		return funcs
+	}
+
+	var ff []DBFunction
+	for _, f := range funcs {
+		if inStrings(o.schemas, f.Schema) {
+			ff = append(ff, f)
+		} else {
+			o.log.debug("function skipped", slog.String("function", f.Schema+"."+f.Name), slog.String("reason", "not included"))
+		}
+	}
+	return ff
+}
+
+// matchTable returns true when a table matches any of the patterns
+func matchTable(patterns []string, schema, table string) bool {
+	for _, p := range patterns {
+		name := table
+		if strings.Contains(p, ".") {
+			name = schema + "." + table
+		}
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inStrings returns true when a value is in a list
+func inStrings(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
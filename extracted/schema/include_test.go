@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// includeRows returns the columns of tables in two schemas, the
+// invoices point to the users
+func includeRows() map[string][][]interface{} {
+	return map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "app"}},
+		postgresColumnsStmt: {
+			columnRow("public", "users", "id", "bigint", true, true),
+			columnRow("public", "audit_log", "id", "bigint", true, true),
+			columnRow("public", "audit_old", "id", "bigint", true, true),
+			columnRow("billing", "invoices", "id", "bigint", true, true),
+			columnRow("billing", "invoices", "user_id", "bigint", true, false, "public", "users", "id"),
+		},
+		postgresFunctionsStmt: {
+			{"f_1", "public", "f", "integer", 1, "a", "integer", "IN"},
+			{"g_2", "billing", "g", "integer", 1, "a", "integer", "IN"},
+		},
+	}
+}
+
+func tableNames(di *DBInfo) string {
+	var names []string
+	for _, t := range di.Tables {
+		names = append(names, t.Schema+"."+t.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+func TestIncludeTables(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []InfoOption
+		want string
+	}{
+		{"all", nil, "billing.invoices public.audit_log public.audit_old public.users"},
+		{"schemas", []InfoOption{WithSchemas("billing")}, "billing.invoices"},
+		{"tables", []InfoOption{WithTables("users", "billing.invoices")}, "billing.invoices public.users"},
+		{"include", []InfoOption{WithIncludeTables("audit_*")}, "public.audit_log public.audit_old"},
+		{"include qualified", []InfoOption{WithIncludeTables("billing.*")}, "billing.invoices"},
+		{"exclude", []InfoOption{WithExcludeTables("audit_*")}, "billing.invoices public.users"},
+		{"exclude wins", []InfoOption{WithIncludeTables("audit_*"), WithExcludeTables("*_old")}, "public.audit_log"},
+	}
+	for _, tt := range tests {
+		q := &routeQuerier{rows: includeRows()}
+		di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil, tt.opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tableNames(di); got != tt.want {
+			t.Errorf("%s: got tables %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIncludeForeignKeys(t *testing.T) {
+	q := &routeQuerier{rows: includeRows()}
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil, WithSchemas("billing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the foreign key to a table left out is removed
+	c, err := di.GetColumn("billing", "invoices", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FKeyTable != "" {
+		t.Errorf("got foreign key to %s.%s", c.FKeySchema, c.FKeyTable)
+	}
+	if len(di.Functions) != 1 || di.Functions[0].Name != "g" {
+		t.Errorf("got functions %v", di.Functions)
+	}
+}
+
+func TestIncludePatternError(t *testing.T) {
+	q := &routeQuerier{rows: includeRows()}
+	if _, err := GetDBInfoFrom(context.Background(), q, "postgres", nil, WithIncludeTables("[a-")); err == nil {
+		t.Error("want an error for an invalid pattern")
+	}
+}
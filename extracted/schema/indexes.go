@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBIndex holds an index of a table, expression index columns are
+// returned as the expression text
+type DBIndex struct {
+	Schema     string
+	Table      string
+	Name       string
+	Columns    []string
+	Unique     bool
+	Primary    bool
+	Constraint bool // the index of a primary key or unique constraint
+	Method     string
+	Predicate  string
+}
+
+// DiscoverIndexes returns the indexes of a database
+func DiscoverIndexes(ctx context.Context, db Querier, dbtype string) ([]DBIndex, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlIndexesStmt
+	case "mssql":
+		sqlStmt = mssqlIndexesStmt
+	case "sqlite":
+		sqlStmt = sqliteIndexesStmt
+	case "cockroach", "cockroachdb":
+		// pg_index on cockroach has no indnkeyatts and pg_get_indexdef
+		// does not take a column, the storing columns of an index are
+		// its included columns and the implicit ones the primary key
+		// added to secondary indexes
+		sqlStmt = cockroachIndexesStmt
+	case "bigquery":
+		// bigquery only has search and vector indexes
+		return nil, nil
+	case "snowflake":
+		// snowflake has no indexes outside of hybrid tables
+		return nil, nil
+	case "duckdb":
+		// duckdb only keeps the create index sql of an index
+		return nil, nil
+	case "clickhouse":
+		// the primary and sorting keys of the table engine and the
+		// data skipping indexes
+		sqlStmt = clickhouseIndexesStmt
+	case "trino":
+		// the indexes of the underlying systems are not exposed
+		return nil, nil
+	case "redshift":
+		// redshift has sort keys instead of indexes, see DiscoverDistribution
+		return nil, nil
+	default:
+		sqlStmt = postgresIndexesStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching indexes: %s", err)
+	}
+	defer rows.Close()
+
+	var indexes []DBIndex
+	im := make(map[string]int)
+
+	for rows.Next() {
+		var is, it, in, method, pred, col string
+		var unique, primary, constraint bool
+
+		err = rows.Scan(&is, &it, &in, &method, &unique, &primary, &constraint, &pred, &col)
+		if err != nil {
+			return nil, err
+		}
+
+		k := (is + ":" + it + ":" + in)
+		i, ok := im[k]
+		if !ok {
+			indexes = append(indexes, DBIndex{
+				Schema:     is,
+				Table:      it,
+				Name:       in,
+				Unique:     unique,
+				Primary:    primary,
+				Constraint: constraint,
+				Method:     method,
+				Predicate:  pred,
+			})
+			i = len(indexes) - 1
+			im[k] = i
+		}
+
+		if col != "" {
+			indexes[i].Columns = append(indexes[i].Columns, col)
+		}
+	}
+
+	return indexes, rows.Err()
+}
+
+// addIndexes adds indexes to their tables
+func (di *DBInfo) addIndexes(indexes []DBIndex) {
+	for _, idx := range indexes {
+		t, err := di.GetTable(idx.Schema, idx.Table)
+		if err != nil {
+			continue
+		}
+		t.Indexes = append(t.Indexes, idx)
+
+		// materialized views have no constraints so a single column
+		// unique index is used as their key for relationships
+		if t.Materialized && idx.Unique && idx.Predicate == "" && len(idx.Columns) == 1 {
+			if cid, ok := t.colMap[idx.Columns[0]]; ok {
+				t.Columns[cid].UniqueKey = true
+			}
+		}
+	}
+}
+
+// IsIndexed returns true if the column is the first column of an index
+// on the table or its primary key
+func (ti *DBTable) IsIndexed(col string) bool {
+	if ti.PrimaryCol.Name == col {
+		return true
+	}
+	for _, idx := range ti.Indexes {
+		if idx.Predicate == "" && len(idx.Columns) != 0 && idx.Columns[0] == col {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnique returns true if the columns can hold a value on one row of
+// the table only, that is they cover its primary key or a unique index.
+// Without indexes a single column with a unique constraint is unique
+func (ti *DBTable) IsUnique(cols ...string) bool {
+	if len(cols) == 0 {
+		return false
+	}
+	set := make(map[string]struct{}, len(cols))
+	for _, c := range cols {
+		set[c] = struct{}{}
+	}
+	covers := func(names []string) bool {
+		for _, n := range names {
+			if _, ok := set[n]; !ok {
+				return false
+			}
+		}
+		return len(names) != 0
+	}
+
+	// the columns of a composite primary key are each marked as the key
+	var pk []string
+	for _, c := range ti.Columns {
+		if c.PrimaryKey {
+			pk = append(pk, c.Name)
+		}
+	}
+	if covers(pk) {
+		return true
+	}
+
+	for _, idx := range ti.Indexes {
+		if (idx.Unique || idx.Primary) && idx.Predicate == "" && covers(idx.Columns) {
+			return true
+		}
+	}
+
+	if len(ti.Indexes) == 0 && len(cols) == 1 {
+		c, ok := ti.getColumn(cols[0])
+		return ok && c.UniqueKey && !c.PrimaryKey
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverIndexesCockroach(t *testing.T) {
+	q := &fakeQuerier{rows: [][]interface{}{
+		{"public", "users", "users_email_key", "btree", true, false, true, "", "email"},
+		{"public", "users", "users_org_name_idx", "btree", true, false, false, "deleted_at IS NULL", "org_id"},
+		{"public", "users", "users_org_name_idx", "btree", true, false, false, "deleted_at IS NULL", "name"},
+		{"public", "users", "users_pkey", "btree", true, true, true, "", "id"},
+	}}
+
+	for _, dbtype := range []string{"cockroach", "cockroachdb"} {
+		q.queries = nil
+		indexes, err := DiscoverIndexes(context.Background(), q, dbtype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(q.queries) != 1 || q.queries[0] != cockroachIndexesStmt {
+			t.Fatalf("%s: not queried with the cockroach statement", dbtype)
+		}
+		for _, v := range []string{"indnkeyatts", "pg_get_indexdef", "relispartition"} {
+			if strings.Contains(q.queries[0], v) {
+				t.Errorf("%s: statement uses %s", dbtype, v)
+			}
+		}
+
+		want := []DBIndex{
+			{Schema: "public", Table: "users", Name: "users_email_key", Columns: []string{"email"},
+				Unique: true, Constraint: true, Method: "btree"},
+			{Schema: "public", Table: "users", Name: "users_org_name_idx", Columns: []string{"org_id", "name"},
+				Unique: true, Method: "btree", Predicate: "deleted_at IS NULL"},
+			{Schema: "public", Table: "users", Name: "users_pkey", Columns: []string{"id"},
+				Unique: true, Primary: true, Constraint: true, Method: "btree"},
+		}
+		if !reflect.DeepEqual(indexes, want) {
+			t.Errorf("%s: got %+v, want %+v", dbtype, indexes, want)
+		}
+	}
+}
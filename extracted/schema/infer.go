@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"sort"
+	"strings"
+)
+
+// InferredRel is a relationship inferred from column naming conventions
+type InferredRel struct {
+	VirtualRel
+
+	// Confidence is between 0 and 1, higher is more likely to be correct
+	Confidence float64
+}
+
+// keySuffixes are the column name suffixes that point at a key column
+// in another table eg. user_id -> users.id and owner_uuid -> users.uuid
+var keySuffixes = []struct {
+	suffix, key string
+}{
+	{"_id", "id"},
+	{"_uuid", "uuid"},
+	{"Id", "id"},
+	{"_ids", "id"},
+}
+
+// InferRels returns the relationships that can be inferred from the
+// naming of columns that are not already foreign keys
+func InferRels(info *DBInfo) []InferredRel {
+	var rels []InferredRel
+
+	tables := make(map[string]*DBTable)
+	for i := range info.Tables {
+		t := &info.Tables[i]
+		if t.Type != "" && t.Type != "foreign" {
+			continue
+		}
+		tables[(t.Schema + ":" + t.Name)] = t
+	}
+
+	keys := make([]string, 0, len(tables))
+	for k := range tables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	kl := keyless(info.Type)
+
+	for _, k := range keys {
+		t := tables[k]
+		for _, c := range t.Columns {
+			if c.FKeyTable != "" || c.PrimaryKey {
+				continue
+			}
+			if r, ok := inferRel(tables, keys, t, c, kl); ok {
+				rels = append(rels, r)
+			}
+		}
+	}
+	return rels
+}
+
+// inferRel infers the relationship for a single column, keyless is true
+// when the database does not declare keys
+func inferRel(
+	tables map[string]*DBTable,
+	keys []string,
+	t *DBTable,
+	c DBColumn,
+	keyless bool,
+) (InferredRel, bool) {
+	for _, ks := range keySuffixes {
+		if !strings.HasSuffix(c.Name, ks.suffix) || len(c.Name) == len(ks.suffix) {
+			continue
+		}
+		if (ks.suffix == "_ids") != c.Array {
+			continue
+		}
+		base := strings.ToLower(c.Name[:len(c.Name)-len(ks.suffix)])
+
+		candidates := []struct {
+			name       string
+			confidence float64
+		}{
+			{pluralize(base), 0.9},
+			{base, 0.8},
+			{singularize(base), 0.7},
+		}
+
+		for _, cand := range candidates {
+			ft, ok := tables[(t.Schema + ":" + cand.name)]
+			if !ok {
+				continue
+			}
+			fc, ok := inferKeyColumn(ft, ks.key, keyless)
+			if !ok {
+				continue
+			}
+			conf := cand.confidence
+			if !typesMatch(c, fc) {
+				conf -= 0.4
+			}
+			return newInferredRel(t, c, ft, fc, conf), true
+		}
+
+		// fallback to the only table with a matching unique key column
+		// eg. owner_uuid -> users.uuid when no owners table exists
+		var match *DBTable
+		var mc DBColumn
+		for _, k := range keys {
+			ft := tables[k]
+			if ft == t {
+				continue
+			}
+			fc, ok := ft.getColumn(ks.key)
+			if !ok || !fc.UniqueKey {
+				continue
+			}
+			if ks.key == "id" || match != nil {
+				match = nil
+				break
+			}
+			match, mc = ft, fc
+		}
+		if match != nil && typesMatch(c, mc) {
+			return newInferredRel(t, c, match, mc, 0.3), true
+		}
+	}
+	return InferredRel{}, false
+}
+
+// inferKeyColumn returns the column a key suffix refers to, falling
+// back to the primary key. Foreign tables and the tables of a keyless
+// database have no constraints so the column is taken as their key
+func inferKeyColumn(t *DBTable, key string, keyless bool) (DBColumn, bool) {
+	if c, ok := t.getColumn(key); ok && (c.PrimaryKey || c.UniqueKey || keyless || t.Type == "foreign") {
+		return c, true
+	}
+	if t.PrimaryCol.Name != "" {
+		return t.PrimaryCol, true
+	}
+	return DBColumn{}, false
+}
+
+// keyless returns true for the databases where tables rarely declare
+// primary or foreign keys
+func keyless(dbType string) bool {
+	return dbType == "bigquery" || dbType == "trino"
+}
+
+// typesMatch returns true if a column can reference the other column
+func typesMatch(c, fc DBColumn) bool {
+	return strings.TrimSuffix(c.Type, "[]") == strings.TrimSuffix(fc.Type, "[]")
+}
+
+// newInferredRel returns an inferred relationship between two columns
+func newInferredRel(t *DBTable, c DBColumn, ft *DBTable, fc DBColumn, conf float64) InferredRel {
+	return InferredRel{
+		VirtualRel: VirtualRel{
+			Schema:     t.Schema,
+			Table:      t.Name,
+			Column:     c.Name,
+			FKeySchema: ft.Schema,
+			FKeyTable:  ft.Name,
+			FKeyCol:    fc.Name,
+		},
+		Confidence: conf,
+	}
+}
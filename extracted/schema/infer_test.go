@@ -0,0 +1,86 @@
+package schema
+
+import "testing"
+
+// inferInfo has no foreign keys, the columns are named after the
+// tables they point to
+func inferInfo(t *testing.T) *DBInfo {
+	t.Helper()
+	info, err := NewTestSchema().
+		Table("users", "id pk", "uuid uuid unique").
+		Table("categories", "id pk").
+		Table("people", "id pk").
+		Table("posts", "id pk", "user_id", "category_id text", "owner_uuid uuid", "person_id", "tag_ids bigint array").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestInferRels(t *testing.T) {
+	got := make(map[string]InferredRel)
+	for _, r := range InferRels(inferInfo(t)) {
+		got[r.Column] = r
+	}
+
+	tests := []struct {
+		col, table, key string
+		conf            float64
+	}{
+		{"user_id", "users", "id", 0.9},
+		{"category_id", "categories", "id", 0.5}, // the types differ
+		{"owner_uuid", "users", "uuid", 0.3},     // the only uuid key
+		{"person_id", "people", "id", 0.9},
+	}
+	for _, tt := range tests {
+		r, ok := got[tt.col]
+		if !ok {
+			t.Errorf("%s: not inferred", tt.col)
+			continue
+		}
+		if r.FKeyTable != tt.table || r.FKeyCol != tt.key {
+			t.Errorf("%s: got %s.%s, want %s.%s", tt.col, r.FKeyTable, r.FKeyCol, tt.table, tt.key)
+		}
+		if diff := r.Confidence - tt.conf; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("%s: got confidence %v, want %v", tt.col, r.Confidence, tt.conf)
+		}
+	}
+	if _, ok := got["tag_ids"]; ok {
+		t.Error("tag_ids inferred without a tags table")
+	}
+}
+
+func TestWithInferredRels(t *testing.T) {
+	s, err := NewDBSchema(inferInfo(t), nil, WithInferredRels(0.6, func(r InferredRel) bool {
+		return r.Column != "person_id"
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("posts", "users", ""); err != nil {
+		t.Error(err)
+	}
+	// below the confidence or rejected
+	for _, to := range []string{"categories", "people"} {
+		if _, err := s.FindPath("posts", to, ""); err == nil {
+			t.Errorf("posts -> %s: want no path", to)
+		}
+	}
+}
+
+func TestInflections(t *testing.T) {
+	for s, want := range map[string]string{
+		"user": "users", "category": "categories", "box": "boxes", "day": "days", "person": "people",
+	} {
+		if got := pluralize(s); got != want {
+			t.Errorf("pluralize(%s) = %s, want %s", s, got, want)
+		}
+		if got := singularize(want); got != s {
+			t.Errorf("singularize(%s) = %s, want %s", want, got, s)
+		}
+	}
+	if got := Singular("address"); got != "address" {
+		t.Errorf("Singular(address) = %s", got)
+	}
+}
@@ -0,0 +1,96 @@
+package schema
+
+import "strings"
+
+// irregulars maps the singular of irregular nouns to their plural
+var irregulars = map[string]string{
+	"person": "people",
+	"child":  "children",
+	"man":    "men",
+	"woman":  "women",
+	"mouse":  "mice",
+	"goose":  "geese",
+	"foot":   "feet",
+	"tooth":  "teeth",
+}
+
+// pluralize returns the plural form of an english noun
+func pluralize(s string) string {
+	if v, ok := irregulars[s]; ok {
+		return v
+	}
+	switch {
+	case s == "":
+		return s
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !isVowel(s[len(s)-2]):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"),
+		strings.HasSuffix(s, "x"),
+		strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"),
+		strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+	return s + "s"
+}
+
+// singularize returns the singular form of an english noun
+func singularize(s string) string {
+	for k, v := range irregulars {
+		if s == v {
+			return k
+		}
+	}
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 3:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"),
+		strings.HasSuffix(s, "xes"),
+		strings.HasSuffix(s, "zes"),
+		strings.HasSuffix(s, "ches"),
+		strings.HasSuffix(s, "shes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ss"):
+		return s
+	case strings.HasSuffix(s, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// Singular returns the singular form of a table name eg. categories ->
+// category
+func Singular(name string) string {
+	return singularize(name)
+}
+
+// isVowel returns true if the byte is a lowercase vowel
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u':
+		return true
+	}
+	return false
+}
+
+// inflections returns the plural and singular forms of a table name to
+// look up when the name itself is not found, the schema of a qualified
+// name is kept
+func (s *DBSchema) inflections(name string) []string {
+	if s.exactNames {
+		return nil
+	}
+
+	var prefix string
+	if i := strings.LastIndexByte(name, '.'); i != -1 {
+		prefix, name = name[:i+1], name[i+1:]
+	}
+
+	var names []string
+	for _, v := range []string{pluralize(name), singularize(name)} {
+		if v != name && v != "" {
+			names = append(names, prefix+v)
+		}
+	}
+	return names
+}
@@ -0,0 +1,36 @@
+package schema
+
+import "testing"
+
+func TestInflectedLookups(t *testing.T) {
+	s := blogTestSchema(t)
+
+	ti, err := s.Find("", "post")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Name != "posts" {
+		t.Errorf("got table %s", ti.Name)
+	}
+	path, err := s.FindPath("comment", "user", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LT.Name != "comments" || path[len(path)-1].RT.Name != "users" {
+		t.Errorf("got path %s", pathString(path))
+	}
+	if _, err := s.FindPath("posts", "comment", ""); err != nil {
+		t.Error(err)
+	}
+
+	s = blogTestSchema(t, WithExactNames())
+	if _, err := s.Find("", "post"); err == nil {
+		t.Error("want an error finding post with exact names")
+	}
+	if _, err := s.FindPath("comment", "user", ""); err == nil {
+		t.Error("want an error finding a path from comment with exact names")
+	}
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
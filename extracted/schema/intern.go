@@ -0,0 +1,30 @@
+package schema
+
+// interner keeps a single copy of equal strings. The columns of a large
+// catalog are read row by row so every column holds its own copy of the
+// names of its schema, table and type even though only a few differ
+type interner map[string]string
+
+func (in interner) intern(s string) string {
+	if s == "" {
+		return s
+	}
+	if v, ok := in[s]; ok {
+		return v
+	}
+	in[s] = s
+	return s
+}
+
+// column interns the names of a column and the key it references
+func (in interner) column(c *DBColumn) {
+	c.Name = in.intern(c.Name)
+	c.Type = in.intern(c.Type)
+	c.ElemType = in.intern(c.ElemType)
+	c.Schema = in.intern(c.Schema)
+	c.Table = in.intern(c.Table)
+	c.FKeySchema = in.intern(c.FKeySchema)
+	c.FKeyTable = in.intern(c.FKeyTable)
+	c.FKeyCol = in.intern(c.FKeyCol)
+	c.FKeyOnDelete = in.intern(c.FKeyOnDelete)
+}
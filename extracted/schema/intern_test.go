@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// catalogColumns returns the columns of a catalog as a driver reads
+// them, every name is its own copy
+func catalogColumns(tables, cols int) []DBColumn {
+	out := make([]DBColumn, 0, tables*(cols+2))
+	for t := 0; t < tables; t++ {
+		name := fmt.Sprintf("table_%d", t)
+		out = append(out, DBColumn{
+			Schema: strings.Clone("public"), Table: strings.Clone(name), Name: strings.Clone("id"),
+			Type: strings.Clone("bigint"), PrimaryKey: true, NotNull: true,
+		})
+		for c := 0; c < cols; c++ {
+			out = append(out, DBColumn{
+				Schema: strings.Clone("public"), Table: strings.Clone(name), Name: fmt.Sprintf("col_%d", c),
+				Type: strings.Clone("character varying"),
+			})
+		}
+		if t != 0 {
+			out = append(out, DBColumn{
+				Schema: strings.Clone("public"), Table: strings.Clone(name), Name: strings.Clone("parent_id"),
+				Type: strings.Clone("bigint"), FKeySchema: strings.Clone("public"),
+				FKeyTable: fmt.Sprintf("table_%d", t-1), FKeyCol: strings.Clone("id"),
+				FKeyOnDelete: strings.Clone("CASCADE"),
+			})
+		}
+	}
+	return out
+}
+
+// heapAlloc returns the bytes of the live heap objects
+func heapAlloc() int64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return int64(m.HeapAlloc)
+}
+
+// copyNames gives every column of a DBInfo its own copy of its names,
+// as they were before NewDBInfo interned them
+func copyNames(di *DBInfo) {
+	for i := range di.Tables {
+		for j := range di.Tables[i].Columns {
+			c := &di.Tables[i].Columns[j]
+			c.Name = strings.Clone(c.Name)
+			c.Type = strings.Clone(c.Type)
+			c.ElemType = strings.Clone(c.ElemType)
+			c.Schema = strings.Clone(c.Schema)
+			c.Table = strings.Clone(c.Table)
+			c.FKeySchema = strings.Clone(c.FKeySchema)
+			c.FKeyTable = strings.Clone(c.FKeyTable)
+			c.FKeyCol = strings.Clone(c.FKeyCol)
+			c.FKeyOnDelete = strings.Clone(c.FKeyOnDelete)
+		}
+	}
+}
+
+// BenchmarkDBInfoHeap reports the heap held by a DBInfo with interned
+// names and with a copy of the names per column, heap-B/op is the live
+// heap once the columns read are released
+func BenchmarkDBInfoHeap(b *testing.B) {
+	for _, size := range [][2]int{{100, 10}, {1000, 20}} {
+		for _, interned := range []bool{true, false} {
+			name := fmt.Sprintf("%dx%d/interned", size[0], size[1])
+			if !interned {
+				name = fmt.Sprintf("%dx%d/copies", size[0], size[1])
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+
+				var heap int64
+				for i := 0; i < b.N; i++ {
+					b.StopTimer()
+					base := heapAlloc()
+					cols := catalogColumns(size[0], size[1])
+					b.StartTimer()
+
+					di := NewDBInfo("postgres", 140000, "public", "db", cols, nil, nil)
+
+					b.StopTimer()
+					if !interned {
+						copyNames(di)
+					}
+					cols = nil
+					heap += heapAlloc() - base
+					runtime.KeepAlive(di)
+					b.StartTimer()
+				}
+				b.ReportMetric(float64(heap)/float64(b.N), "heap-B/op")
+			})
+		}
+	}
+}
+
+// BenchmarkIntern is the interning of the names of the columns alone
+func BenchmarkIntern(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cols := catalogColumns(1000, 20)
+		b.StartTimer()
+
+		in := make(interner)
+		for j := range cols {
+			in.column(&cols[j])
+		}
+	}
+}
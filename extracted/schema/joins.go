@@ -0,0 +1,382 @@
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JoinMode is how PathToJoins renders a path
+type JoinMode int
+
+const (
+	JoinPlain   JoinMode = iota // a join for each table of the path
+	JoinLateral                 // nested lateral subqueries returning JSON
+)
+
+// JoinOptions configures the joins of PathToJoins
+type JoinOptions struct {
+	Mode      JoinMode
+	Kind      string              // join kind, JOIN unless set eg. LEFT JOIN
+	FromAlias string              // alias of the first table, its name unless set
+	Quote     func(string) string // quotes identifiers, double quotes unless set
+	Param     func(n int) string  // placeholder of the nth argument, $n unless set
+	Catalogs  bool                // the namespace of a table is its catalog, eg. for trino
+	Schema    func(string) string // the schema a table is read from, eg. DBSchema.TenantSchema
+}
+
+// Join is a join of a path, the values of the placeholders in its
+// condition are in Args
+type Join struct {
+	Table DBTable
+	Alias string
+	On    string
+	Args  []interface{}
+	SQL   string // eg. JOIN "public"."users" AS "users" ON ...
+}
+
+// PathToJoins returns the joins from the first table of a path to the
+// last one in order. Repeated tables get numbered aliases, composite keys
+// join on all their columns, array keys match any of their values and
+// many-to-many hops join the join table first. The type value of a
+// polymorphic relationship is passed as an argument.
+//
+// In the lateral mode the path is a single LEFT JOIN LATERAL of nested
+// subqueries, each table of the path is a JSON object of its columns and
+// of the table after it in a json column, the tables joining many rows
+// are aggregated into arrays
+func PathToJoins(path []TPath, opts JoinOptions) ([]Join, error) {
+	jb, err := newJoinBuilder(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return jb.joins, nil
+}
+
+// PathToSQL returns the FROM clause joining the tables of a path with
+// the values of its placeholders. In the lateral mode it returns a query
+// with a row for each row of the first table holding its nested JSON in
+// a json column
+func PathToSQL(path []TPath, opts JoinOptions) (string, []interface{}, error) {
+	jb, err := newJoinBuilder(path, opts)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var sb strings.Builder
+	var args []interface{}
+
+	if jb.opts.Mode == JoinLateral {
+		sb.WriteString("SELECT " + jb.object(path[0].LT, jb.from, 0) + ` AS "json" `)
+	}
+	sb.WriteString("FROM " + jb.tableRef(path[0].LT) + " AS " + jb.quote(jb.from))
+	for _, j := range jb.joins {
+		sb.WriteString(" " + j.SQL)
+		args = append(args, j.Args...)
+	}
+	return sb.String(), args, nil
+}
+
+// joinBuilder holds the state of PathToJoins
+type joinBuilder struct {
+	opts   JoinOptions
+	path   []TPath
+	from   string // alias of the first table
+	used   map[string]struct{}
+	nargs  int
+	hops   []Join // joins of the hops of the path
+	starts []int  // index of the first join of each hop
+	joins  []Join
+}
+
+func newJoinBuilder(path []TPath, opts JoinOptions) (*joinBuilder, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	if opts.Kind == "" {
+		opts.Kind = "JOIN"
+		if opts.Mode == JoinLateral {
+			opts.Kind = "LEFT JOIN"
+		}
+	}
+
+	jb := &joinBuilder{opts: opts, path: path, used: make(map[string]struct{})}
+	jb.from = opts.FromAlias
+	if jb.from == "" {
+		jb.from = path[0].LT.Name
+	}
+	jb.used[jb.from] = struct{}{}
+
+	la := jb.from
+	for _, p := range path {
+		var err error
+		jb.starts = append(jb.starts, len(jb.hops))
+		if la, err = jb.addHop(p, la); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Mode != JoinLateral {
+		jb.joins = jb.hops
+	} else {
+		var args []interface{}
+		for _, j := range jb.hops {
+			args = append(args, j.Args...)
+		}
+		jb.joins = []Join{{
+			Table: path[0].RT,
+			Alias: jb.jsonAlias(0),
+			On:    "true",
+			Args:  args,
+			SQL:   jb.lateralJoin(0),
+		}}
+	}
+	return jb, nil
+}
+
+// addHop adds the joins of a hop from the table with the alias and
+// returns the alias of the table joined
+func (jb *joinBuilder) addHop(p TPath, la string) (string, error) {
+	switch p.Rel {
+	case RelOneToOne, RelOneToMany, RelRecursive, RelPolymorphic:
+	case RelManyToMany:
+		ta := jb.alias(p.Through.Ti.Name)
+		jb.add(p.Through.Ti, ta, []string{jb.keyCond(la, p.LC, ta, p.Through.ColL)}, nil)
+
+		ra := jb.alias(p.RT.Name)
+		jb.add(p.RT, ra, []string{jb.keyCond(ta, p.Through.ColR, ra, p.RC)}, nil)
+		return ra, nil
+	default:
+		return "", fmt.Errorf("cannot join a %s relationship: %s -> %s",
+			p.Rel, p.LT.String(), p.RT.String())
+	}
+
+	ra := jb.alias(p.RT.Name)
+
+	lcols, rcols := p.LCs, p.RCs
+	if len(lcols) == 0 || len(lcols) != len(rcols) {
+		lcols, rcols = []DBColumn{p.LC}, []DBColumn{p.RC}
+	}
+
+	var conds []string
+	for i := range lcols {
+		conds = append(conds, jb.keyCond(la, lcols[i], ra, rcols[i]))
+	}
+
+	var args []interface{}
+	if pv := p.Poly; pv.TypeValue != "" {
+		pa := ra
+		if pv.TypeCol.Table == p.LT.Name && pv.TypeCol.Schema == p.LT.Schema {
+			pa = la
+		}
+		jb.nargs++
+		conds = append(conds, jb.colRef(pa, pv.TypeCol.Name)+" = "+jb.param(jb.nargs))
+		args = append(args, pv.TypeValue)
+	}
+
+	jb.add(p.RT, ra, conds, args)
+	return ra, nil
+}
+
+// add adds a join on the conditions
+func (jb *joinBuilder) add(t DBTable, alias string, conds []string, args []interface{}) {
+	on := strings.Join(conds, " AND ")
+	jb.hops = append(jb.hops, Join{
+		Table: t,
+		Alias: alias,
+		On:    on,
+		Args:  args,
+		SQL:   jb.opts.Kind + " " + jb.tableRef(t) + " AS " + jb.quote(alias) + " ON " + on,
+	})
+}
+
+// hop returns the joins of the ith hop of the path, the last one joins
+// its table
+func (jb *joinBuilder) hop(i int) []Join {
+	if i+1 < len(jb.starts) {
+		return jb.hops[jb.starts[i]:jb.starts[i+1]]
+	}
+	return jb.hops[jb.starts[i]:]
+}
+
+// lateralJoin returns the lateral join of the JSON of the ith hop
+func (jb *joinBuilder) lateralJoin(i int) string {
+	return jb.opts.Kind + " LATERAL (" + jb.lateral(i) + ") AS " +
+		jb.quote(jb.jsonAlias(i)) + " ON true"
+}
+
+// lateral returns the subquery of the JSON of the table joined by the ith
+// hop, the hop conditions on the table before it go in the where clause
+func (jb *joinBuilder) lateral(i int) string {
+	var sb strings.Builder
+
+	hop := jb.hop(i)
+	rj := hop[len(hop)-1]
+	obj := jb.object(rj.Table, rj.Alias, i+1)
+
+	many := isMany(jb.path[i])
+	if many {
+		sb.WriteString("SELECT coalesce(json_agg(" + obj + `), '[]') AS "json"`)
+	} else {
+		sb.WriteString("SELECT " + obj + ` AS "json"`)
+	}
+	sb.WriteString(" FROM " + jb.tableRef(rj.Table) + " AS " + jb.quote(rj.Alias))
+
+	// a many-to-many hop joins the join table to the related table
+	if len(hop) == 2 {
+		sb.WriteString(" JOIN " + jb.tableRef(hop[0].Table) + " AS " + jb.quote(hop[0].Alias) +
+			" ON " + rj.On)
+	}
+	if i+1 < len(jb.path) {
+		sb.WriteString(" " + jb.lateralJoin(i+1))
+	}
+
+	sb.WriteString(" WHERE " + hop[0].On)
+	if !many {
+		sb.WriteString(" LIMIT 1")
+	}
+	return sb.String()
+}
+
+// object returns the JSON object of the columns of a table and of the
+// table joined by the ith hop, named after it
+func (jb *joinBuilder) object(t DBTable, alias string, i int) string {
+	var vals []string
+	used := make(map[string]struct{}, len(t.Columns))
+
+	for _, c := range t.Columns {
+		if c.Blocked {
+			continue
+		}
+		used[c.Name] = struct{}{}
+		vals = append(vals, quoteString(c.Name), jb.colRef(alias, c.Name))
+	}
+
+	if i < len(jb.path) {
+		p := jb.path[i]
+		name := uniqueName(used, p.RT.Name, p.RT.Name+"_by_"+p.RC.Name)
+		vals = append(vals, quoteString(name), jb.colRef(jb.jsonAlias(i), "json"))
+	}
+	return jsonObject(vals)
+}
+
+// maxObjectKeys is the number of keys a json_build_object call takes at
+// most, Postgres functions are limited to 100 arguments
+const maxObjectKeys = 50
+
+// jsonObject returns the json_build_object of key and value pairs. An
+// object with more keys than a call takes is spliced from the text of
+// several calls, as psql builds it, so its keys keep their order
+func jsonObject(pairs []string) string {
+	if len(pairs) <= 2*maxObjectKeys {
+		return "json_build_object(" + strings.Join(pairs, ", ") + ")"
+	}
+
+	var calls []string
+	for i := 0; i < len(pairs); i += 2 * maxObjectKeys {
+		end := min(i+2*maxObjectKeys, len(pairs))
+		call := "json_build_object(" + strings.Join(pairs[i:end], ", ") + ")::text"
+
+		// the first call keeps its opening brace, the last its closing one
+		switch {
+		case i == 0:
+			call = "left(" + call + ", -1)"
+		case end == len(pairs):
+			call = "substr(" + call + ", 2)"
+		default:
+			call = "substr(left(" + call + ", -1), 2)"
+		}
+		calls = append(calls, call)
+	}
+	return "(" + strings.Join(calls, " || ', ' || ") + ")::json"
+}
+
+// jsonAlias returns the alias of the subquery of the ith hop
+func (jb *joinBuilder) jsonAlias(i int) string {
+	hop := jb.hop(i)
+	return "__sj_" + hop[len(hop)-1].Alias
+}
+
+// isMany returns true if a hop can join more than one row to a row of
+// the table before it
+func isMany(p TPath) bool {
+	if p.Rel == RelManyToMany || p.LC.Array || p.RC.Array {
+		return true
+	}
+
+	rcols := p.RCs
+	if len(rcols) == 0 || len(rcols) != len(p.LCs) {
+		rcols = []DBColumn{p.RC}
+	}
+	names := make([]string, len(rcols))
+	for i, c := range rcols {
+		names[i] = c.Name
+	}
+	return !p.RT.IsUnique(names...)
+}
+
+// alias returns the name of a table or the name numbered when it is
+// already the alias of another table of the path
+func (jb *joinBuilder) alias(name string) string {
+	a := name
+	for i := 2; ; i++ {
+		if _, ok := jb.used[a]; !ok {
+			break
+		}
+		a = name + "_" + strconv.Itoa(i)
+	}
+	jb.used[a] = struct{}{}
+	return a
+}
+
+// keyCond returns the condition matching two key columns
+func (jb *joinBuilder) keyCond(la string, lc DBColumn, ra string, rc DBColumn) string {
+	l, r := jb.colRef(la, lc.Name), jb.colRef(ra, rc.Name)
+
+	switch {
+	case lc.Array && !rc.Array:
+		return r + " = any(" + l + ")"
+	case rc.Array && !lc.Array:
+		return l + " = any(" + r + ")"
+	}
+	return l + " = " + r
+}
+
+func (jb *joinBuilder) colRef(alias, col string) string {
+	return jb.quote(alias) + "." + jb.quote(col)
+}
+
+// tableRef returns the schema qualified name of a table
+func (jb *joinBuilder) tableRef(t DBTable) string {
+	ts := t.Schema
+	if jb.opts.Schema != nil {
+		ts = jb.opts.Schema(ts)
+	}
+	ns, sn := SplitNamespace(ts)
+	if sn == "" {
+		return jb.quote(t.Name)
+	}
+	if jb.opts.Catalogs && ns != "" {
+		return jb.quote(ns) + "." + jb.quote(sn) + "." + jb.quote(t.Name)
+	}
+	return jb.quote(sn) + "." + jb.quote(t.Name)
+}
+
+func (jb *joinBuilder) quote(s string) string {
+	if jb.opts.Quote != nil {
+		return jb.opts.Quote(s)
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteString returns a string as an SQL literal
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func (jb *joinBuilder) param(n int) string {
+	if jb.opts.Param != nil {
+		return jb.opts.Param(n)
+	}
+	return "$" + strconv.Itoa(n)
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+)
+
+// joinsSchema has comments on posts of users and a table whose names
+// need quoting as identifiers and as literals
+func joinsSchema(t *testing.T) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("posts", "id pk", "user_id notnull", "title").
+		Table("comments", "id pk", "post_id notnull", "body").
+		Table(`my"notes`, "id pk", "user_id notnull", `it's`, `say"hi"`).
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		FK(`my"notes.user_id`, "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPathToSQL(t *testing.T) {
+	tests := []struct {
+		name, from, to string
+		opts           JoinOptions
+	}{
+		{"plain", "users", "comments", JoinOptions{}},
+		{"parent", "comments", "users", JoinOptions{Kind: "LEFT JOIN", FromAlias: "c"}},
+		{"quoted", "users", `my"notes`, JoinOptions{}},
+		{"lateral", "users", "comments", JoinOptions{Mode: JoinLateral}},
+		{"lateral_quoted", "users", `my"notes`, JoinOptions{Mode: JoinLateral}},
+	}
+
+	s := joinsSchema(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := s.FindPath(tt.from, tt.to, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sql, args, err := PathToSQL(path, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) != 0 {
+				t.Errorf("got args %v", args)
+			}
+			golden.Check(t, "join_"+tt.name+".sql", sql+"\n")
+		})
+	}
+}
+
+func TestPathToSQLWide(t *testing.T) {
+	cols := []string{"id pk", "user_id notnull"}
+	for i := 0; i < 60; i++ {
+		cols = append(cols, fmt.Sprintf("c%d text", i))
+	}
+	s, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("events", cols...).
+		FK("events.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := s.FindPath("users", "events", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, _, err := PathToSQL(path, JoinOptions{Mode: JoinLateral})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sql, `left(json_build_object('id', `) ||
+		!strings.Contains(sql, `substr(json_build_object('c48', `) {
+		t.Errorf("object of 62 keys not spliced from two calls:\n%s", sql)
+	}
+	golden.Check(t, "join_lateral_wide.sql", sql+"\n")
+}
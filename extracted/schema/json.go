@@ -0,0 +1,249 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// dbInfoJSON has the same fields as DBInfo but none of its methods
+// so it can be used to encode and decode without recursion
+type dbInfoJSON DBInfo
+
+// MarshalJSON returns the JSON encoding of the DBInfo object
+func (di DBInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dbInfoJSON(di))
+}
+
+// UnmarshalJSON decodes a DBInfo object and rebuilds its lookup indexes
+func (di *DBInfo) UnmarshalJSON(data []byte) error {
+	var v dbInfoJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*di = DBInfo(v)
+
+	di.tableMap = make(map[string]int)
+
+	for i := range di.Tables {
+		t := &di.Tables[i]
+		t.colMap = make(map[string]int, len(t.Columns))
+
+		for j, c := range t.Columns {
+			// dbinfo saved before logical types were added
+			if c.Logical == "" {
+				t.Columns[j].Logical, _ = DefaultTypes.Lookup(c.Type)
+				setTemporal(&t.Columns[j], di.Type)
+			}
+			t.colMap[c.Name] = j
+		}
+		di.tableMap[(t.Schema + ":" + t.Name)] = i
+	}
+
+	di.setHash(infoColumns(di))
+	return nil
+}
+
+// LoadDBInfo reads a DBInfo object previously saved as JSON
+func LoadDBInfo(r io.Reader) (*DBInfo, error) {
+	var di DBInfo
+	if err := json.NewDecoder(r).Decode(&di); err != nil {
+		return nil, fmt.Errorf("error loading dbinfo: %w", err)
+	}
+	return &di, nil
+}
+
+// dbTableJSON is DBTable with the keys of its JSON encoding, the keys
+// match the field names so tables saved before still decode
+type dbTableJSON struct {
+	Comment      string         `json:"comment,omitempty"`
+	Schema       string         `json:"schema"`
+	Name         string         `json:"name"`
+	Type         string         `json:"type"`
+	Columns      []DBColumn     `json:"columns"`
+	PrimaryCol   DBColumn       `json:"primaryCol,omitzero"`
+	SecondaryCol DBColumn       `json:"secondaryCol,omitzero"`
+	FullText     []DBColumn     `json:"fullText,omitempty"`
+	Blocked      bool           `json:"blocked,omitempty"`
+	Partial      bool           `json:"partial,omitempty"`
+	Func         DBFunction     `json:"func,omitzero"`
+	Definition   string         `json:"definition,omitempty"`
+	Materialized bool           `json:"materialized,omitempty"`
+	Populated    bool           `json:"populated,omitempty"`
+	RefreshedAt  time.Time      `json:"refreshedAt,omitzero"`
+	Indexes      []DBIndex      `json:"indexes,omitempty"`
+	Checks       []DBCheck      `json:"checks,omitempty"`
+	PartitionKey string         `json:"partitionKey,omitempty"`
+	Partitions   []DBPartition  `json:"partitions,omitempty"`
+	Foreign      DBForeignTable `json:"foreign,omitzero"`
+	RowCount     int64          `json:"rowCount,omitempty"`
+	Distribution DBDistribution `json:"distribution,omitzero"`
+	colMap       map[string]int
+}
+
+// MarshalJSON returns the JSON encoding of a table, unset fields are
+// left out
+func (ti DBTable) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dbTableJSON(ti))
+}
+
+// dbColumnJSON is DBColumn with the keys of its JSON encoding
+type dbColumnJSON struct {
+	Comment      string      `json:"comment,omitempty"`
+	ID           int32       `json:"id"`
+	Name         string      `json:"name"`
+	Type         string      `json:"type"`
+	Logical      LogicalType `json:"logical,omitempty"`
+	Array        bool        `json:"array,omitempty"`
+	ElemType     string      `json:"elemType,omitempty"`
+	TimeZone     bool        `json:"timeZone,omitempty"`
+	Precision    int         `json:"precision,omitempty"`
+	NotNull      bool        `json:"notNull,omitempty"`
+	PrimaryKey   bool        `json:"primaryKey,omitempty"`
+	UniqueKey    bool        `json:"uniqueKey,omitempty"`
+	FullText     bool        `json:"fullText,omitempty"`
+	Enum         []string    `json:"enum,omitempty"`
+	Generated    string      `json:"generated,omitempty"`
+	GenExpr      string      `json:"genExpr,omitempty"`
+	Default      string      `json:"default,omitempty"`
+	Identity     string      `json:"identity,omitempty"`
+	Sequence     string      `json:"sequence,omitempty"`
+	GeoType      string      `json:"geoType,omitempty"`
+	SRID         int         `json:"srid,omitempty"`
+	FKRecursive  bool        `json:"fkRecursive,omitempty"`
+	FKeySchema   string      `json:"fkeySchema,omitempty"`
+	FKeyTable    string      `json:"fkeyTable,omitempty"`
+	FKeyCol      string      `json:"fkeyCol,omitempty"`
+	FKeyOnDelete string      `json:"fkeyOnDelete,omitempty"`
+	FKeyName     string      `json:"fkeyName,omitempty"`
+	BaseSchema   string      `json:"baseSchema,omitempty"`
+	BaseTable    string      `json:"baseTable,omitempty"`
+	BaseCol      string      `json:"baseCol,omitempty"`
+	JSONCol      string      `json:"jsonCol,omitempty"`
+	JSONPath     []string    `json:"jsonPath,omitempty"`
+	Mask         Mask        `json:"mask,omitempty"`
+	Audit        Audit       `json:"audit,omitempty"`
+	Sensitive    []string    `json:"sensitive,omitempty"`
+	Blocked      bool        `json:"blocked,omitempty"`
+	Table        string      `json:"table"`
+	Schema       string      `json:"schema"`
+}
+
+// MarshalJSON returns the JSON encoding of a column, unset fields are
+// left out
+func (col DBColumn) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dbColumnJSON(col))
+}
+
+// relTypeNames are the names of the relationship types in JSON
+var relTypeNames = map[RelType]string{
+	RelNone:        "none",
+	RelOneToOne:    "one_to_one",
+	RelOneToMany:   "one_to_many",
+	RelPolymorphic: "polymorphic",
+	RelRecursive:   "recursive",
+	RelEmbedded:    "embedded",
+	RelRemote:      "remote",
+	RelSkip:        "skip",
+	RelManyToMany:  "many_to_many",
+}
+
+// MarshalText returns the name of a relationship type eg. one_to_many
+func (rt RelType) MarshalText() ([]byte, error) {
+	if v, ok := relTypeNames[rt]; ok {
+		return []byte(v), nil
+	}
+	return nil, fmt.Errorf("unknown relationship type: %d", rt)
+}
+
+// UnmarshalText sets a relationship type from its name
+func (rt *RelType) UnmarshalText(b []byte) error {
+	for k, v := range relTypeNames {
+		if v == string(b) {
+			*rt = k
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown relationship type: %s", b)
+}
+
+// relJSON is the JSON encoding of a relationship or a hop of a path,
+// tables are schema qualified names
+type relJSON struct {
+	Type    RelType      `json:"type"`
+	Left    relEndJSON   `json:"left"`
+	Right   relEndJSON   `json:"right"`
+	Through *relThruJSON `json:"through,omitempty"`
+	Poly    *relPolyJSON `json:"poly,omitempty"`
+}
+
+// relEndJSON is a side of a relationship
+type relEndJSON struct {
+	Table   string   `json:"table"`
+	Column  string   `json:"column"`
+	Columns []string `json:"columns,omitempty"` // composite keys
+	VTable  string   `json:"vtable,omitempty"`
+}
+
+// relThruJSON is the join table of a many to many relationship
+type relThruJSON struct {
+	Table string `json:"table"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// relPolyJSON is the discriminator of a polymorphic relationship
+type relPolyJSON struct {
+	TypeColumn string            `json:"typeColumn"`
+	TypeValue  string            `json:"typeValue"`
+	Types      map[string]string `json:"types,omitempty"`
+}
+
+// newRelJSON returns the JSON encoding of a relationship of any type
+func newRelJSON(rt RelType, left, right relEndJSON, thru DBRelThrough, poly DBRelPoly) relJSON {
+	v := relJSON{Type: rt, Left: left, Right: right}
+	if thru.Ti.Name != "" {
+		v.Through = &relThruJSON{Table: thru.Ti.String(), Left: thru.ColL.Name, Right: thru.ColR.Name}
+	}
+	if poly.TypeCol.Name != "" {
+		v.Poly = &relPolyJSON{TypeColumn: poly.TypeCol.Name, TypeValue: poly.TypeValue, Types: poly.Types}
+	}
+	return v
+}
+
+// newRelEnd returns a side of a relationship
+func newRelEnd(t DBTable, col DBColumn, cols []DBColumn) relEndJSON {
+	v := relEndJSON{Table: t.String(), Column: col.Name}
+	if len(cols) > 1 {
+		for _, c := range cols {
+			v.Columns = append(v.Columns, c.Name)
+		}
+	}
+	return v
+}
+
+// MarshalJSON returns the relationship with the table and column names
+// of both sides
+func (rel DBRel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rel.toJSON())
+}
+
+// toJSON returns the JSON encoding of the relationship
+func (rel DBRel) toJSON() relJSON {
+	right := newRelEnd(rel.Right.Ti, rel.Right.Col, rel.Right.Cols)
+	right.VTable = rel.Right.VTable
+
+	return newRelJSON(rel.Type,
+		newRelEnd(rel.Left.Ti, rel.Left.Col, rel.Left.Cols), right,
+		rel.Through, rel.Poly)
+}
+
+// MarshalJSON returns the relationship with its field name
+func (tr TableRel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name string `json:"name"`
+		Many bool   `json:"many"`
+		relJSON
+	}{tr.Name, tr.Many, tr.DBRel.toJSON()})
+}
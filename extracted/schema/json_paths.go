@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSONPath declares a pseudo-column holding a value nested in a json or
+// jsonb column so it can be selected, filtered and sorted like any
+// other column, eg. the country of metadata -> 'shipping' ->> 'country'
+// is JSONPath{Table: "orders", Column: "metadata",
+// Path: []string{"shipping", "country"}, Name: "shipping_country"}
+type JSONPath struct {
+	Schema string
+	Table  string
+	Column string
+
+	// Path are the keys from the column to the value, integer keys
+	// index into arrays
+	Path []string
+
+	// Name is the name of the pseudo-column
+	Name string
+
+	// Type is the database type the value is cast to, text when empty
+	Type string
+}
+
+// String returns a string representation of the JSONPath
+func (jp JSONPath) String() string {
+	return fmt.Sprintf("%s.%s.%s -> %s as %s",
+		jp.Schema, jp.Table, jp.Column, strings.Join(jp.Path, "."), jp.Name)
+}
+
+// addJSONPaths adds the pseudo-columns of the JSON paths to their tables
+func (s *DBSchema) addJSONPaths(paths []JSONPath) error {
+	for _, jp := range paths {
+		if jp.Schema == "" {
+			jp.Schema = s.schema
+		}
+		if jp.Type == "" {
+			jp.Type = "text"
+		}
+
+		v, ok := s.tindex[(jp.Schema + ":" + jp.Table)]
+		if !ok {
+			return fmt.Errorf("json path: table not found: %s.%s", jp.Schema, jp.Table)
+		}
+		t := &s.tables[v.nodeID]
+
+		c, ok := t.getColumn(jp.Column)
+		if !ok {
+			return fmt.Errorf("json path: column not found: %s.%s", jp.Table, jp.Column)
+		}
+		if !strings.HasPrefix(c.Type, "json") || c.Array {
+			return fmt.Errorf("json path: column is not json or jsonb: %s.%s", jp.Table, jp.Column)
+		}
+		if len(jp.Path) == 0 {
+			return fmt.Errorf("json path: empty path for %s.%s", jp.Table, jp.Name)
+		}
+		if _, ok := t.getColumn(jp.Name); ok {
+			return fmt.Errorf("json path: column already exists: %s.%s", jp.Table, jp.Name)
+		}
+
+		// copy the columns so the DBInfo passed in is not modified
+		t.Columns = append([]DBColumn{}, t.Columns...)
+		colMap := make(map[string]int, len(t.colMap)+1)
+		for k, i := range t.colMap {
+			colMap[k] = i
+		}
+		t.colMap = colMap
+
+		t.Columns = append(t.Columns, DBColumn{
+			ID:       int32(len(t.Columns)),
+			Name:     jp.Name,
+			Type:     jp.Type,
+			Schema:   t.Schema,
+			Table:    t.Name,
+			JSONCol:  c.Name,
+			JSONPath: append([]string{}, jp.Path...),
+		})
+		t.colMap[jp.Name] = len(t.Columns) - 1
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package schema
+
+import "testing"
+
+func TestJSONPathErrors(t *testing.T) {
+	tests := []JSONPath{
+		{Table: "missing", Column: "metadata", Path: []string{"a"}, Name: "a"},
+		{Table: "orders", Column: "missing", Path: []string{"a"}, Name: "a"},
+		{Table: "orders", Column: "note", Path: []string{"a"}, Name: "a"},
+		{Table: "orders", Column: "metadata", Name: "a"},
+		{Table: "orders", Column: "metadata", Path: []string{"a"}, Name: "note"},
+	}
+	for _, jp := range tests {
+		_, err := NewTestSchema().
+			Table("orders", "id pk", "metadata jsonb", "note").
+			BuildSchema(WithJSONPaths(jp))
+		if err == nil {
+			t.Errorf("%s: want an error", jp)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDBInfoJSON(t *testing.T) {
+	di := GetTestDBInfo()
+	b, err := json.Marshal(di)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadDBInfo(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tables) != len(di.Tables) || len(got.Functions) != len(di.Functions) {
+		t.Errorf("got %d tables and %d functions, want %d and %d",
+			len(got.Tables), len(got.Functions), len(di.Tables), len(di.Functions))
+	}
+
+	// the JSON is the same once read back
+	b2, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, b2) {
+		t.Error("JSON changed after a round trip")
+	}
+
+	// the lookup indexes are rebuilt
+	c, err := got.GetColumn("public", "products", "user_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.FKeyTable != "users" {
+		t.Errorf("got foreign key %s", c.FKeyTable)
+	}
+
+	s, err := NewDBSchema(got, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("products", "users", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDBColumnJSONOmitsUnset(t *testing.T) {
+	b, err := json.Marshal(DBColumn{Schema: "public", Table: "users", Name: "id", Type: "bigint"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"comment"`, `"notNull"`, `"fkeyTable"`, `"enum"`} {
+		if strings.Contains(string(b), key) {
+			t.Errorf("unset %s in %s", key, b)
+		}
+	}
+}
+
+func TestLoadDBInfoOld(t *testing.T) {
+	// saved before the logical types were added
+	const old = `{"Type": "postgres", "Schema": "public", "Tables": [{"Schema": "public", "Name": "users",
+		"Columns": [{"id": 0, "name": "id", "type": "bigint", "table": "users", "schema": "public"}]}]}`
+
+	di, err := LoadDBInfo(strings.NewReader(old))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := di.GetColumn("public", "users", "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Logical == "" {
+		t.Error("logical type not set")
+	}
+
+	if _, err := LoadDBInfo(strings.NewReader(`{"Tables": 1}`)); err == nil {
+		t.Error("want an error")
+	}
+}
@@ -0,0 +1,274 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// TableLoader returns a table with all of its columns, a lazy schema
+// calls it the first time a partial table is used
+type TableLoader func(schema, table string) (DBTable, error)
+
+// lazyTables holds the partial tables a snapshot of a lazy schema has
+// loaded so far, each snapshot has its own copy
+type lazyTables struct {
+	load   TableLoader
+	group  singleflight.Group
+	mu     sync.RWMutex
+	tables map[int32]DBTable
+}
+
+// clone returns a copy of the loaded tables for a new snapshot, a
+// table removed from the new snapshot stays loaded in the old ones
+func (lt *lazyTables) clone() *lazyTables {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+	return &lazyTables{load: lt.load, tables: maps.Clone(lt.tables)}
+}
+
+// WithLazyTables builds the schema with only the key columns of every
+// table, the primary, unique and foreign key columns the relationship
+// graph is made of. The other columns of a table are loaded with the
+// loader when Find returns it, queries get the full table while
+// GetTables and the tables of paths and relationships stay partial.
+// Tables named by the other options are loaded while the schema is
+// built
+func WithLazyTables(load TableLoader) Option {
+	return func(o *schemaOptions) {
+		o.lazy = load
+	}
+}
+
+// NewTableLoader returns a loader reading the columns of a table from
+// the database with the columns query used by GetDBInfo, the enum
+// values, comments and generated expressions of the columns are not
+// read
+func NewTableLoader(ctx context.Context, db *sql.DB, dbType string, blockList []string) TableLoader {
+	return func(schema, table string) (DBTable, error) {
+		cols, err := DiscoverTableColumns(ctx, SQLQuerier(db), dbType, schema, table, blockList)
+		if err != nil {
+			return DBTable{}, err
+		}
+		if len(cols) == 0 {
+			return DBTable{}, fmt.Errorf("table not found: %s.%s", schema, table)
+		}
+		return NewDBTable(schema, table, "", cols), nil
+	}
+}
+
+// DiscoverTableColumns returns the columns of a single table
+func DiscoverTableColumns(
+	ctx context.Context,
+	db Querier,
+	dbtype string,
+	schema string,
+	table string,
+	blockList []string,
+) ([]DBColumn, error) {
+	rows, err := db.Query(ctx, tableColumnsStmt(dbtype), schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching columns of %s.%s: %s", schema, table, err)
+	}
+	defer rows.Close()
+
+	return scanColumns(rows, dbtype, blockList)
+}
+
+// tableColumnsStmt returns the columns query of a database limited to
+// the table set by the two parameters, schema then table
+func tableColumnsStmt(dbtype string) string {
+	q1, q2 := `"`, `"`
+	p1, p2 := "$1", "$2"
+
+	switch dbtype {
+	case "mysql", "mariadb", "bigquery":
+		q1, q2 = "`", "`"
+		p1, p2 = "?", "?"
+	case "sqlite", "snowflake", "clickhouse", "duckdb", "trino":
+		p1, p2 = "?", "?"
+	case "mssql":
+		p1, p2 = "@p1", "@p2"
+	}
+
+	stmt := strings.TrimRight(columnsStmt(dbtype), "; \t\r\n")
+	return "SELECT * FROM (" + stmt + ") AS c WHERE c." +
+		q1 + "schema" + q2 + " = " + p1 + " AND c." +
+		q1 + "table" + q2 + " = " + p2
+}
+
+// keyTables returns the tables with only their key columns, the
+// primary, unique and foreign key columns and the columns foreign keys
+// point to. A table is partial when other columns were left out
+func keyTables(tables []DBTable) []DBTable {
+	refs := make(map[string]struct{})
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if c.FKeyTable != "" {
+				refs[c.FKeySchema+":"+c.FKeyTable+":"+c.FKeyCol] = struct{}{}
+			}
+		}
+	}
+
+	kts := make([]DBTable, len(tables))
+	for i, t := range tables {
+		kts[i] = keyTable(t, refs)
+	}
+	return kts
+}
+
+// keyTable returns a table with only its key columns
+func keyTable(t DBTable, refs map[string]struct{}) DBTable {
+	if t.Type != "" && t.Type != "view" && t.Type != "foreign" {
+		return t
+	}
+
+	var cols []DBColumn
+	for _, c := range t.Columns {
+		_, ref := refs[t.Schema+":"+t.Name+":"+c.Name]
+		if ref || c.PrimaryKey || c.UniqueKey || c.FKeyTable != "" {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == len(t.Columns) {
+		return t
+	}
+
+	kt := t
+	kt.Columns = cols
+	kt.FullText = nil
+	kt.colMap = make(map[string]int, len(cols))
+	for i, c := range cols {
+		kt.colMap[c.Name] = i
+	}
+	kt.Partial = true
+	return kt
+}
+
+// fullTable returns a partial table with the columns of the loaded
+// table, the key columns of the partial table are kept as they are
+// since the schema may have changed them
+func fullTable(pt, lt DBTable) DBTable {
+	t := pt
+	t.Columns = make([]DBColumn, len(lt.Columns))
+	t.colMap = make(map[string]int, len(lt.Columns))
+	t.PrimaryCol = pt.PrimaryCol
+	t.FullText = nil
+	t.Partial = false
+
+	for i, c := range lt.Columns {
+		if kc, ok := pt.getColumn(c.Name); ok {
+			c = kc
+		}
+		c.Schema, c.Table = pt.Schema, pt.Name
+		if c.FullText {
+			t.FullText = append(t.FullText, c)
+		}
+		t.Columns[i] = c
+		t.colMap[c.Name] = i
+	}
+
+	// column aliases of the partial table
+	for k, i := range pt.colMap {
+		if name := pt.Columns[i].Name; k != name {
+			if j, ok := t.colMap[name]; ok {
+				t.colMap[k] = j
+			}
+		}
+	}
+	return t
+}
+
+// lazyTable returns a table of the schema, the columns of a partial
+// table are loaded the first time it is asked for
+func (s *DBSchema) lazyTable(nid int32) (DBTable, error) {
+	t := s.tables[nid]
+	if s.lazy == nil || !t.Partial {
+		return t, nil
+	}
+	lt := s.lazy
+
+	lt.mu.RLock()
+	v, ok := lt.tables[nid]
+	lt.mu.RUnlock()
+	if ok {
+		return v, nil
+	}
+
+	res, err, _ := lt.group.Do(strconv.Itoa(int(nid)), func() (interface{}, error) {
+		ft, err := lt.load(t.Schema, t.Name)
+		if err != nil {
+			return nil, fmt.Errorf("loading table %s: %w", t.String(), err)
+		}
+		v := fullTable(t, ft)
+
+		lt.mu.Lock()
+		lt.tables[nid] = v
+		lt.mu.Unlock()
+		return v, nil
+	})
+	if err != nil {
+		return t, err
+	}
+	return res.(DBTable), nil
+}
+
+// loadOptionTables loads the partial tables the options of the schema
+// name, they are checked against and changed while it is built
+func (s *DBSchema) loadOptionTables(so *schemaOptions) error {
+	var keys []string
+	add := func(schema, table string) {
+		if schema == "" {
+			schema = s.schema
+		}
+		keys = append(keys, schema+":"+table)
+	}
+
+	for _, vr := range so.virtualRels {
+		add(vr.Schema, vr.Table)
+		if vr.FKeySchema != "" {
+			add(vr.FKeySchema, vr.FKeyTable)
+		} else {
+			add(vr.Schema, vr.FKeyTable)
+		}
+	}
+	for _, pr := range so.polyRels {
+		add(pr.Schema, pr.Table)
+		for _, t := range pr.Types {
+			add(pr.Schema, t)
+		}
+	}
+	for _, jp := range so.jsonPaths {
+		add(jp.Schema, jp.Table)
+	}
+	for _, r := range so.roles {
+		for _, rt := range r.Tables {
+			add(rt.Schema, rt.Table)
+		}
+	}
+	for _, a := range so.aliases {
+		if a.Column != "" {
+			add(s.splitTableName(a.Table))
+		}
+	}
+
+	for _, k := range keys {
+		v, ok := s.tindex[k]
+		if !ok {
+			// the option reports the missing table
+			continue
+		}
+		t, err := s.lazyTable(v.nodeID)
+		if err != nil {
+			return err
+		}
+		s.tables[v.nodeID] = t
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// lazySchema returns a lazy schema of users and posts and the number of
+// tables its loader loaded
+func lazySchema(t *testing.T, fail bool, opts ...Option) (*DBSchema, *atomic.Int32) {
+	t.Helper()
+	di, err := NewTestSchema().
+		Table("users", "id pk", "email", "bio").
+		Table("posts", "id pk", "user_id notnull", "title").
+		FK("posts.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := make(map[string]DBTable)
+	for _, ti := range di.Tables {
+		full[ti.Name] = ti
+	}
+
+	loads := new(atomic.Int32)
+	load := func(schema, table string) (DBTable, error) {
+		loads.Add(1)
+		if fail {
+			return DBTable{}, errors.New("connection refused")
+		}
+		return full[table], nil
+	}
+
+	s, err := NewDBSchema(di, nil, append(opts, WithLazyTables(load))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, loads
+}
+
+func TestLazyTables(t *testing.T) {
+	s, loads := lazySchema(t, false)
+
+	for _, ti := range s.GetTables() {
+		if ti.Name == "users" && (!ti.Partial || len(ti.Columns) != 1) {
+			t.Errorf("got users columns %v", ti.Columns)
+		}
+	}
+	if _, err := s.FindPath("posts", "users", ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := loads.Load(); n != 0 {
+		t.Errorf("got %d loads before the tables are used", n)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ti, err := s.Find("", "users")
+			if err != nil || ti.Partial || len(ti.Columns) != 3 {
+				t.Errorf("got users %v, %v", ti.Columns, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := loads.Load(); n != 1 {
+		t.Errorf("got %d loads, want 1", n)
+	}
+
+	// the key columns are all the posts have besides the title
+	if ti, _ := s.Find("", "posts"); len(ti.Columns) != 3 {
+		t.Errorf("got posts columns %v", ti.Columns)
+	}
+}
+
+func TestLazyTablesError(t *testing.T) {
+	s, _ := lazySchema(t, true)
+	if _, err := s.Find("", "users"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestLazyOptionTables(t *testing.T) {
+	// the role names a column that is not a key so the table is loaded
+	s, loads := lazySchema(t, false, WithRoles(Role{Name: "anon", Tables: []RoleTable{
+		{Table: "users", BlockColumns: []string{"email"}},
+	}}))
+	if n := loads.Load(); n != 1 {
+		t.Errorf("got %d loads while building, want 1", n)
+	}
+	if _, err := s.Find("", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if n := loads.Load(); n != 1 {
+		t.Errorf("got %d loads, want 1", n)
+	}
+}
+
+func TestTableColumnsStmt(t *testing.T) {
+	for dbType, want := range map[string]string{
+		"postgres": `WHERE c."schema" = $1 AND c."table" = $2`,
+		"mysql":    "WHERE c.`schema` = ? AND c.`table` = ?",
+		"mssql":    `WHERE c."schema" = @p1 AND c."table" = @p2`,
+	} {
+		if stmt := tableColumnsStmt(dbType); !strings.HasSuffix(stmt, want) || strings.Contains(stmt, ";") {
+			t.Errorf("%s: got statement ending %s", dbType, stmt[len(stmt)-60:])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithInfoLogger logs the tables GetDBInfo discovers and the tables,
+// columns and functions it leaves out at debug level, and the caches of
+// WithCache it cannot save as warnings
+func WithInfoLogger(l *slog.Logger) InfoOption {
+	return func(o *infoOptions) {
+		o.log = logger{l}
+	}
+}
+
+// WithLogger logs the relationships NewDBSchema adds, infers and skips
+// and how FindPath resolves paths at debug level
+func WithLogger(l *slog.Logger) Option {
+	return func(o *schemaOptions) {
+		o.log = logger{l}
+	}
+}
+
+// logger logs debug events, it does nothing without a slog.Logger
+type logger struct {
+	l *slog.Logger
+}
+
+// enabled returns true when debug events are logged, callers check it
+// before building costly attributes
+func (lg logger) enabled() bool {
+	return lg.l != nil && lg.l.Enabled(context.Background(), slog.LevelDebug)
+}
+
+// debug logs an event at debug level
+func (lg logger) debug(msg string, args ...any) {
+	if lg.l != nil {
+		lg.l.Debug(msg, args...)
+	}
+}
+
+// warn logs an error that does not fail the call it happened in
+func (lg logger) warn(msg string, args ...any) {
+	if lg.l != nil {
+		lg.l.Warn(msg, args...)
+	}
+}
+
+// rel logs an event of a relationship
+func (lg logger) rel(msg string, rt RelType, lti DBTable, lcol DBColumn, rti DBTable, rcol DBColumn, args ...any) {
+	if !lg.enabled() {
+		return
+	}
+	lg.l.Debug(msg, append([]any{
+		slog.String("type", rt.String()),
+		slog.String("from", lti.String()+"."+lcol.Name),
+		slog.String("to", rti.String()+"."+rcol.Name),
+	}, args...)...)
+}
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+// logEvents returns a logger writing JSON and a function returning the
+// events written so far
+func logEvents(t *testing.T, level slog.Level) (*slog.Logger, func() []map[string]any) {
+	t.Helper()
+	var b bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&b, &slog.HandlerOptions{Level: level}))
+	return l, func() []map[string]any {
+		var events []map[string]any
+		dec := json.NewDecoder(bytes.NewReader(b.Bytes()))
+		for dec.More() {
+			var ev map[string]any
+			if err := dec.Decode(&ev); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, ev)
+		}
+		return events
+	}
+}
+
+// findEvent returns the first event with a message and the attributes given
+func findEvent(events []map[string]any, msg string, attrs map[string]any) map[string]any {
+	for _, ev := range events {
+		if ev["msg"] != msg {
+			continue
+		}
+		ok := true
+		for k, v := range attrs {
+			if ev[k] != v {
+				ok = false
+			}
+		}
+		if ok {
+			return ev
+		}
+	}
+	return nil
+}
+
+func TestLogger(t *testing.T) {
+	l, events := logEvents(t, slog.LevelDebug)
+	s := blogTestSchema(t, WithLogger(l), WithBlockedRels("comments.user_id"))
+
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	evs := events()
+	for _, tt := range []struct {
+		msg   string
+		attrs map[string]any
+	}{
+		{"relationship added", map[string]any{"from": "public.posts.user_id", "to": "public.users.id"}},
+		{"relationship skipped", map[string]any{"from": "public.comments.user_id", "reason": "blocked"}},
+		{"path resolved", map[string]any{"from": "comments", "to": "users", "path": "comments -> posts -> users", "cached": false}},
+	} {
+		if findEvent(evs, tt.msg, tt.attrs) == nil {
+			t.Errorf("no %s event with %v in:\n%v", tt.msg, tt.attrs, evs)
+		}
+	}
+	for _, ev := range evs {
+		if ev["level"] != "DEBUG" {
+			t.Errorf("got event %v, want debug events", ev)
+		}
+	}
+}
+
+// nothing is logged above debug level
+func TestLoggerLevel(t *testing.T) {
+	l, events := logEvents(t, slog.LevelInfo)
+	s := blogTestSchema(t, WithLogger(l))
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Fatal(err)
+	}
+	if evs := events(); len(evs) != 0 {
+		t.Errorf("got events %v", evs)
+	}
+}
+
+func TestInfoLogger(t *testing.T) {
+	l, events := logEvents(t, slog.LevelDebug)
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "audit", "id", "bigint", true, true),
+		},
+	}}
+	_, err := GetDBInfoFrom(context.Background(), q, "mysql", nil,
+		WithInfoLogger(l), WithExcludeTables("audit"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evs := events()
+	if findEvent(evs, "table discovered", map[string]any{"table": "shop.users", "columns": float64(1)}) == nil {
+		t.Errorf("no discovered table in:\n%v", evs)
+	}
+	if findEvent(evs, "table skipped", map[string]any{"table": "shop.audit", "reason": "not included"}) == nil {
+		t.Errorf("no skipped table in:\n%v", evs)
+	}
+}
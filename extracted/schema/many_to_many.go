@@ -0,0 +1,63 @@
+package schema
+
+// joinTableCols returns the two foreign key columns of a join table, a
+// join table has exactly two foreign keys to different tables and no
+// other columns except for its primary key
+func joinTableCols(t DBTable) (DBColumn, DBColumn, bool) {
+	var fks []DBColumn
+
+	if t.Type != "" || t.Partial {
+		return DBColumn{}, DBColumn{}, false
+	}
+
+	for _, c := range t.Columns {
+		switch {
+		case c.FKeyTable != "" && c.FKeyCol != "" && !c.Array:
+			fks = append(fks, c)
+		case c.PrimaryKey:
+		default:
+			return DBColumn{}, DBColumn{}, false
+		}
+	}
+
+	if len(fks) != 2 {
+		return DBColumn{}, DBColumn{}, false
+	}
+
+	if fks[0].FKeySchema == fks[1].FKeySchema &&
+		fks[0].FKeyTable == fks[1].FKeyTable {
+		return DBColumn{}, DBColumn{}, false
+	}
+	return fks[0], fks[1], true
+}
+
+// addManyToManyRels connects the two tables of a join table directly
+func (s *DBSchema) addManyToManyRels(t DBTable) error {
+	c1, c2, ok := joinTableCols(t)
+	if !ok {
+		return nil
+	}
+
+	lt, err := s.find(c1.FKeySchema, c1.FKeyTable)
+	if err != nil {
+		return err
+	}
+
+	lc, err := lt.GetColumn(c1.FKeyCol)
+	if err != nil {
+		return err
+	}
+
+	rt, err := s.find(c2.FKeySchema, c2.FKeyTable)
+	if err != nil {
+		return err
+	}
+
+	rc, err := rt.GetColumn(c2.FKeyCol)
+	if err != nil {
+		return err
+	}
+
+	through := DBRelThrough{Ti: t, ColL: c1, ColR: c2}
+	return s.addManyToManyToGraph(lt, lc, rt, rc, through)
+}
@@ -0,0 +1,59 @@
+package schema
+
+import "testing"
+
+func TestManyToManyRels(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("posts", "id pk").
+		Table("tags", "id pk").
+		Table("post_tags", "id pk", "post_id notnull", "tag_id notnull").
+		FK("post_tags.post_id", "posts.id").
+		FK("post_tags.tag_id", "tags.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range [][2]string{{"posts", "tags"}, {"tags", "posts"}} {
+		path, err := s.FindPath(tc[0], tc[1], "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path) != 1 || path[0].Rel != RelManyToMany {
+			t.Fatalf("%s -> %s: got path %v", tc[0], tc[1], path)
+		}
+		th := path[0].Through
+		if th.Ti.Name != "post_tags" || th.ColL.Name != tc[0][:len(tc[0])-1]+"_id" {
+			t.Errorf("%s -> %s: got through %s.%s", tc[0], tc[1], th.Ti.Name, th.ColL.Name)
+		}
+	}
+}
+
+func TestJoinTableCols(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("posts", "id pk").
+		Table("tags", "id pk").
+		Table("post_tags", "post_id notnull", "tag_id notnull").
+		Table("ratings", "post_id notnull", "tag_id notnull", "score integer").
+		Table("links", "from_id notnull", "to_id notnull").
+		FK("post_tags.post_id", "posts.id").
+		FK("post_tags.tag_id", "tags.id").
+		FK("ratings.post_id", "posts.id").
+		FK("ratings.tag_id", "tags.id").
+		FK("links.from_id", "posts.id").
+		FK("links.to_id", "posts.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]bool{"post_tags": true, "ratings": false, "links": false, "posts": false} {
+		tbl, err := info.GetTable("public", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := joinTableCols(*tbl); ok != want {
+			t.Errorf("%s: got join table %t, want %t", name, ok, want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// Mask is how a masked column is shown to a role
+type Mask string
+
+const (
+	MaskNull    Mask = "null"    // the value is always null
+	MaskHash    Mask = "hash"    // the hex SHA-256 of the value as text
+	MaskPartial Mask = "partial" // all but the last 4 characters are replaced by *
+)
+
+// ColumnMask masks a column of a role table, the value is masked in the
+// SQL so the role never reads it and filters and orderings see the
+// masked value
+type ColumnMask struct {
+	Column string
+	Mask   Mask
+}
+
+// checkMask returns an error when a column of a table cannot be masked,
+// the columns tables are joined on are needed as they are
+func (s *DBSchema) checkMask(t DBTable, cm ColumnMask) error {
+	switch cm.Mask {
+	case MaskNull, MaskHash, MaskPartial:
+	default:
+		return fmt.Errorf("unknown mask: %s", cm.Mask)
+	}
+
+	c, ok := t.getColumn(cm.Column)
+	if !ok {
+		return fmt.Errorf("column not found: %s.%s", t.Name, cm.Column)
+	}
+	if c.PrimaryKey || c.FKeyTable != "" {
+		return fmt.Errorf("key column cannot be masked: %s.%s", t.Name, c.Name)
+	}
+
+	for _, ot := range s.tables {
+		for _, oc := range ot.Columns {
+			if oc.FKeySchema == t.Schema && oc.FKeyTable == t.Name && oc.FKeyCol == c.Name {
+				return fmt.Errorf("key column cannot be masked: %s.%s", t.Name, c.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// maskColumns sets the masks of the columns of a table, JSON path
+// columns read from a masked column are blocked
+func maskColumns(t *DBTable, masks []ColumnMask) {
+	if len(masks) == 0 {
+		return
+	}
+	mask := make(map[string]Mask, len(masks))
+	for _, cm := range masks {
+		mask[cm.Column] = cm.Mask
+	}
+
+	for i, c := range t.Columns {
+		switch {
+		case c.JSONCol != "" && mask[c.JSONCol] != "":
+			t.Columns[i].Blocked = true
+		case mask[c.Name] != "":
+			t.Columns[i].Mask = mask[c.Name]
+		}
+	}
+	for i, c := range t.FullText {
+		t.FullText[i].Mask = mask[c.Name]
+	}
+}
@@ -0,0 +1,23 @@
+package schema
+
+import "testing"
+
+func TestMaskErrors(t *testing.T) {
+	for _, cm := range []ColumnMask{
+		{Column: "email", Mask: "rot13"},
+		{Column: "missing", Mask: MaskNull},
+		{Column: "id", Mask: MaskNull},
+		{Column: "org_id", Mask: MaskNull},
+	} {
+		_, err := NewTestSchema().
+			Table("orgs", "id pk").
+			Table("users", "id pk", "org_id", "email").
+			FK("users.org_id", "orgs.id").
+			BuildSchema(WithRoles(Role{Name: "r", Tables: []RoleTable{
+				{Table: "users", Masks: []ColumnMask{cm}},
+			}}))
+		if err == nil {
+			t.Errorf("%+v: want an error", cm)
+		}
+	}
+}
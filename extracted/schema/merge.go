@@ -0,0 +1,172 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NamespaceSep separates the namespace of a database from the schema
+// names of its tables eg. "orders:public"
+const NamespaceSep = ":"
+
+// Namespaced returns a copy of the DBInfo with the schema of every table,
+// column and function prefixed by the namespace, the tables of several
+// databases can then be merged with MergeDBInfo without their names
+// colliding. A namespaced table is found as "orders:public.orders"
+func (di *DBInfo) Namespaced(ns string) *DBInfo {
+	nd := &DBInfo{
+		Type:     di.Type,
+		Version:  di.Version,
+		Schema:   nsSchema(ns, di.Schema),
+		Name:     di.Name,
+		VTables:  append([]VirtualTable{}, di.VTables...),
+		tableMap: make(map[string]int),
+	}
+
+	for _, t := range di.Tables {
+		nd.AddTable(namespaceTable(ns, t))
+	}
+
+	for _, f := range di.Functions {
+		nd.Functions = append(nd.Functions, namespaceFunc(ns, f))
+	}
+
+	nd.setHash(infoColumns(nd))
+	return nd
+}
+
+// MergeDBInfo merges the tables and functions of several databases into
+// one DBInfo so their relationship graphs live in a single DBSchema, the
+// database information of the result is the one of the first DBInfo.
+// The databases should be namespaced (see Namespaced) and relationships
+// across them declared as virtual relationships, eg.
+// "orders:public.orders.user_id" to "auth:public.users.id"
+func MergeDBInfo(infos ...*DBInfo) (*DBInfo, error) {
+	if len(infos) == 0 {
+		return nil, fmt.Errorf("merge: no dbinfo to merge")
+	}
+
+	first := infos[0]
+	di := &DBInfo{
+		Type:     first.Type,
+		Version:  first.Version,
+		Schema:   first.Schema,
+		Name:     first.Name,
+		tableMap: make(map[string]int),
+	}
+
+	for _, info := range infos {
+		for _, t := range info.Tables {
+			if _, ok := di.tableMap[(t.Schema + ":" + t.Name)]; ok {
+				return nil, fmt.Errorf("merge: duplicate table: %s.%s", t.Schema, t.Name)
+			}
+			di.AddTable(t)
+		}
+		di.Functions = append(di.Functions, info.Functions...)
+		di.VTables = append(di.VTables, info.VTables...)
+	}
+
+	di.setHash(infoColumns(di))
+	return di, nil
+}
+
+// SplitNamespace splits a schema name into the namespace of its database
+// and the schema name in the database, the namespace is empty for
+// schemas that are not namespaced
+func SplitNamespace(schema string) (string, string) {
+	if ns, name, ok := strings.Cut(schema, NamespaceSep); ok {
+		return ns, name
+	}
+	return "", schema
+}
+
+// nsSchema prefixes a schema name by a namespace
+func nsSchema(ns, schema string) string {
+	if ns == "" || schema == "" {
+		return schema
+	}
+	return ns + NamespaceSep + schema
+}
+
+// namespaceTable returns a copy of a table in a namespace
+func namespaceTable(ns string, t DBTable) DBTable {
+	nt := t
+	nt.Schema = nsSchema(ns, t.Schema)
+	nt.Columns = namespaceCols(ns, t.Columns)
+	nt.FullText = namespaceCols(ns, t.FullText)
+	nt.PrimaryCol = namespaceCol(ns, t.PrimaryCol)
+	nt.SecondaryCol = namespaceCol(ns, t.SecondaryCol)
+	nt.Func = namespaceFunc(ns, t.Func)
+
+	nt.colMap = make(map[string]int, len(nt.Columns))
+	for i, c := range nt.Columns {
+		nt.colMap[c.Name] = i
+	}
+
+	if t.Indexes != nil {
+		nt.Indexes = make([]DBIndex, len(t.Indexes))
+		for i, v := range t.Indexes {
+			v.Schema = nsSchema(ns, v.Schema)
+			nt.Indexes[i] = v
+		}
+	}
+	if t.Checks != nil {
+		nt.Checks = make([]DBCheck, len(t.Checks))
+		for i, v := range t.Checks {
+			v.Schema = nsSchema(ns, v.Schema)
+			nt.Checks[i] = v
+		}
+	}
+	if t.Distribution.Table != "" {
+		nt.Distribution.Schema = nsSchema(ns, t.Distribution.Schema)
+	}
+	if t.Partitions != nil {
+		nt.Partitions = make([]DBPartition, len(t.Partitions))
+		for i, v := range t.Partitions {
+			v.Schema = nsSchema(ns, v.Schema)
+			v.ParentSchema = nsSchema(ns, v.ParentSchema)
+			nt.Partitions[i] = v
+		}
+	}
+	return nt
+}
+
+// namespaceCols returns a copy of columns in a namespace
+func namespaceCols(ns string, cols []DBColumn) []DBColumn {
+	if cols == nil {
+		return nil
+	}
+	nc := make([]DBColumn, len(cols))
+	for i, c := range cols {
+		nc[i] = namespaceCol(ns, c)
+	}
+	return nc
+}
+
+// namespaceCol returns a column in a namespace, the tables it points to
+// are in the same database
+func namespaceCol(ns string, c DBColumn) DBColumn {
+	c.Schema = nsSchema(ns, c.Schema)
+	c.FKeySchema = nsSchema(ns, c.FKeySchema)
+	c.BaseSchema = nsSchema(ns, c.BaseSchema)
+	return c
+}
+
+// namespaceFunc returns a function in a namespace
+func namespaceFunc(ns string, f DBFunction) DBFunction {
+	f.Schema = nsSchema(ns, f.Schema)
+	f.ReturnSchema = nsSchema(ns, f.ReturnSchema)
+	return f
+}
+
+// infoColumns returns the columns of the tables of a DBInfo, the columns
+// of function tables are not part of the hash
+func infoColumns(di *DBInfo) []DBColumn {
+	var cols []DBColumn
+	for _, t := range di.Tables {
+		if t.Type != "function" {
+			cols = append(cols, t.Columns...)
+		}
+	}
+	return cols
+}
@@ -0,0 +1,84 @@
+package schema
+
+import "testing"
+
+// mergedInfo returns the orders and auth databases merged, both have
+// their tables in the public schema
+func mergedInfo(t *testing.T) *DBInfo {
+	t.Helper()
+	orders, err := NewTestSchema().
+		Table("orders", "id pk", "user_id notnull").
+		Table("items", "id pk", "order_id notnull").
+		FK("items.order_id", "orders.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewTestSchema().
+		Table("users", "id pk", "email").
+		Table("items", "id pk").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := MergeDBInfo(orders, auth); err == nil {
+		t.Error("want an error for the items table in both")
+	}
+
+	di, err := MergeDBInfo(orders.Namespaced("orders"), auth.Namespaced("auth"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return di
+}
+
+func TestMergeDBInfo(t *testing.T) {
+	di := mergedInfo(t)
+	if di.Schema != "orders:public" || len(di.Tables) != 4 {
+		t.Fatalf("got schema %s tables %d", di.Schema, len(di.Tables))
+	}
+	c, err := di.GetColumn("orders:public", "items", "order_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the foreign keys stay in their database
+	if c.FKeySchema != "orders:public" {
+		t.Errorf("got foreign key schema %s", c.FKeySchema)
+	}
+
+	vr, err := NewVirtualRel("orders:public.orders.user_id", "auth:public.users.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDBSchema(di, nil, WithVirtualRels(vr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("items", "auth:public.users", ""); err != nil {
+		t.Error(err)
+	}
+	if _, err := s.Find("auth:public", "items"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNamespaced(t *testing.T) {
+	di := GetTestDBInfo()
+	nd := di.Namespaced("shop")
+	if nd.Hash() == di.Hash() {
+		t.Error("got the same hash in a namespace")
+	}
+	if di.Tables[0].Schema == nd.Tables[0].Schema {
+		t.Error("the dbinfo was changed")
+	}
+
+	for _, tt := range [][3]string{
+		{"shop:public", "shop", "public"},
+		{"public", "", "public"},
+	} {
+		if ns, name := SplitNamespace(tt[0]); ns != tt[1] || name != tt[2] {
+			t.Errorf("SplitNamespace(%s) = %s, %s", tt[0], ns, name)
+		}
+	}
+}
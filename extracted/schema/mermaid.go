@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MermaidOptions configures the output of ToMermaid
+type MermaidOptions struct {
+	// Root limits the output to the tables reachable from this table,
+	// the name can be schema qualified
+	Root string
+
+	// Depth is the number of relationships to follow from Root,
+	// zero follows all of them
+	Depth int
+}
+
+// ToMermaid returns the tables and relationships as a Mermaid erDiagram
+// with column types and PK, FK and UK markers
+func (s *DBSchema) ToMermaid(opts MermaidOptions) (string, error) {
+	s = s.current()
+
+	nodes, err := s.reachableNodes(opts.Root, opts.Depth)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("erDiagram\n")
+
+	for _, n := range nodes {
+		t := s.tables[n]
+		fmt.Fprintf(&sb, "    %s {\n", mermaidName(s.displayName(t)))
+
+		for _, c := range t.Columns {
+			fmt.Fprintf(&sb, "        %s %s", mermaidName(c.Type), mermaidName(c.Name))
+
+			if keys := mermaidKeys(c); keys != "" {
+				sb.WriteString(" " + keys)
+			}
+			if c.Comment != "" {
+				fmt.Fprintf(&sb, " \"%s\"", strings.ReplaceAll(c.Comment, "\"", "'"))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("    }\n")
+	}
+
+	for _, r := range s.schemaRels(nodes) {
+		e := r.edge
+		fmt.Fprintf(&sb, "    %s %s %s : \"%s\"\n",
+			mermaidName(s.displayName(e.LT)),
+			mermaidCardinality(e),
+			mermaidName(s.displayName(e.RT)),
+			relLabel(e))
+	}
+
+	return sb.String(), nil
+}
+
+// mermaidKeys returns the key markers of a column
+func mermaidKeys(c DBColumn) string {
+	var keys []string
+	if c.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if c.FKeyTable != "" {
+		keys = append(keys, "FK")
+	}
+	if c.UniqueKey && !c.PrimaryKey {
+		keys = append(keys, "UK")
+	}
+	return strings.Join(keys, ", ")
+}
+
+// mermaidCardinality returns the relationship markers for an edge going
+// from the table holding the foreign key to the table it references,
+// relationships not backed by a foreign key are drawn dotted
+func mermaidCardinality(e TEdge) string {
+	switch e.Type {
+	case RelManyToMany, RelOneToMany:
+		return "}o--o{"
+	}
+
+	left := "}o"
+	if e.L.UniqueKey || e.L.PrimaryKey {
+		left = "|o"
+	}
+
+	right := "o|"
+	if e.L.NotNull {
+		right = "||"
+	}
+
+	switch e.Type {
+	case RelOneToOne, RelRecursive:
+		return left + "--" + right
+	}
+	return left + ".." + right
+}
+
+// mermaidName replaces the characters not allowed in Mermaid entity,
+// attribute and type names
+func mermaidName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_', r == '-', r == '(', r == ')', r == '[', r == ']':
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,42 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+)
+
+func TestToMermaid(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk", "email unique notnull", "created_at timestamptz").
+		Table("profiles", "id pk", "user_id unique notnull", "bio").
+		Table("posts", "id pk", "user_id", "title notnull").
+		FK("profiles.user_id", "users.id").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	er, err := s.ToMermaid(MermaidOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "schema.mmd", er)
+
+	if _, err := s.ToMermaid(MermaidOptions{Root: "missing"}); err == nil {
+		t.Error("want an error for a missing root table")
+	}
+}
+
+func TestMermaidName(t *testing.T) {
+	for in, want := range map[string]string{
+		"character varying": "character_varying",
+		"numeric(10,2)":     "numeric(10_2)",
+		"public.users":      "public_users",
+	} {
+		if got := mermaidName(in); got != want {
+			t.Errorf("mermaidName(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
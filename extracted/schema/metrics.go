@@ -0,0 +1,46 @@
+package schema
+
+import "time"
+
+// Metrics receives the measurements of the schema layer, the metrics
+// package exports them to Prometheus. The methods are called from any
+// goroutine and should not block
+type Metrics interface {
+	// Introspected is called after GetDBInfo with how long it took and
+	// true when the DBInfo came from the cache
+	Introspected(dbType string, d time.Duration, cacheHit bool, err error)
+
+	// GraphChanged is called with the size of the graph once NewDBSchema
+	// has built it and after AddTable, AddRelationship and RemoveTable
+	GraphChanged(tables, relationships int)
+
+	// PathFound is called after FindPath with how long it took and true
+	// when the path came from the path cache
+	PathFound(d time.Duration, cacheHit bool, err error)
+
+	// Reloaded is called after a Watcher reload, changed is false when
+	// the database was the same
+	Reloaded(changed bool, err error)
+}
+
+// WithInfoMetrics reports the duration of GetDBInfo to the metrics
+func WithInfoMetrics(m Metrics) InfoOption {
+	return func(o *infoOptions) {
+		o.metrics = m
+	}
+}
+
+// WithMetrics reports the size of the graph and the FindPath latency
+// and cache hits to the metrics
+func WithMetrics(m Metrics) Option {
+	return func(o *schemaOptions) {
+		o.metrics = m
+	}
+}
+
+// graphChanged reports the size of the graph
+func (s *DBSchema) graphChanged() {
+	if s.metrics != nil {
+		s.metrics.GraphChanged(len(s.tables)-len(s.removed), len(s.allEdges))
+	}
+}
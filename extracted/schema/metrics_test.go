@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMetrics keeps the measurements it is given
+type fakeMetrics struct {
+	mu           sync.Mutex
+	introspected []string
+	graph        [][2]int
+	hits, misses int
+	errs         int
+}
+
+func (m *fakeMetrics) Introspected(dbType string, d time.Duration, cacheHit bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.introspected = append(m.introspected, dbType)
+	if err != nil {
+		m.errs++
+	}
+}
+
+func (m *fakeMetrics) GraphChanged(tables, relationships int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.graph = append(m.graph, [2]int{tables, relationships})
+}
+
+func (m *fakeMetrics) PathFound(d time.Duration, cacheHit bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cacheHit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+	if err != nil {
+		m.errs++
+	}
+}
+
+func (m *fakeMetrics) Reloaded(changed bool, err error) {}
+
+func TestMetrics(t *testing.T) {
+	m := &fakeMetrics{}
+	s := blogTestSchema(t, WithMetrics(m), WithPathCache())
+
+	if len(m.graph) != 1 || m.graph[0][0] != 3 || m.graph[0][1] == 0 {
+		t.Fatalf("got graph sizes %v", m.graph)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.FindPath("comments", "users", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.FindPath("comments", "missing", ""); err == nil {
+		t.Fatal("want an error")
+	}
+	if m.misses != 2 || m.hits != 1 || m.errs != 1 {
+		t.Errorf("got %d misses, %d hits and %d errors", m.misses, m.hits, m.errs)
+	}
+
+	if err := s.AddTable(noteTable()); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.graph) != 2 || m.graph[1][0] != 4 || m.graph[1][1] <= m.graph[0][1] {
+		t.Errorf("got graph sizes %v after adding a table", m.graph)
+	}
+	if err := s.RemoveTable("notes"); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.graph) != 3 || m.graph[2][0] != 3 {
+		t.Errorf("got graph sizes %v after removing a table", m.graph)
+	}
+}
+
+func TestInfoMetrics(t *testing.T) {
+	m := &fakeMetrics{}
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo:        {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {columnRow("shop", "users", "id", "bigint", true, true)},
+	}}
+	if _, err := GetDBInfoFrom(context.Background(), q, "mysql", nil, WithInfoMetrics(m)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDBInfoFrom(context.Background(), &routeQuerier{}, "mysql", nil, WithInfoMetrics(m)); err == nil {
+		t.Fatal("want an error without the info row")
+	}
+	if len(m.introspected) != 2 || m.introspected[0] != "mysql" || m.errs != 1 {
+		t.Errorf("got introspections %v with %d errors", m.introspected, m.errs)
+	}
+}
@@ -0,0 +1,44 @@
+package schema
+
+import "strings"
+
+// mssqlTypes maps sql server type names to the type names
+// used across the rest of the schema package
+var mssqlTypes = map[string]string{
+	"bigint":           "bigint",
+	"int":              "integer",
+	"smallint":         "smallint",
+	"tinyint":          "smallint",
+	"bit":              "boolean",
+	"decimal":          "numeric",
+	"numeric":          "numeric",
+	"money":            "numeric",
+	"smallmoney":       "numeric",
+	"float":            "double precision",
+	"real":             "real",
+	"char":             "character",
+	"nchar":            "character",
+	"varchar":          "character varying",
+	"nvarchar":         "character varying",
+	"text":             "text",
+	"ntext":            "text",
+	"date":             "date",
+	"time":             "time without time zone",
+	"datetime":         "timestamp without time zone",
+	"datetime2":        "timestamp without time zone",
+	"smalldatetime":    "timestamp without time zone",
+	"datetimeoffset":   "timestamp with time zone",
+	"uniqueidentifier": "uuid",
+	"binary":           "bytea",
+	"varbinary":        "bytea",
+	"image":            "bytea",
+	"xml":              "xml",
+}
+
+// mssqlType returns the normalized name for a sql server type
+func mssqlType(t string) string {
+	if v, ok := mssqlTypes[strings.ToLower(t)]; ok {
+		return v
+	}
+	return t
+}
@@ -0,0 +1,77 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+// namespaceSchema has invoices in the default schema and in billing
+func namespaceSchema(t *testing.T) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("invoices", "id pk", "user_id notnull").
+		Table("billing.invoices", "id pk", "owner_id notnull").
+		Table("billing.payments", "id pk", "invoice_id notnull").
+		FK("invoices.user_id", "users.id").
+		FK("billing.invoices.owner_id", "public.users.id").
+		FK("billing.payments.invoice_id", "billing.invoices.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestFindQualified(t *testing.T) {
+	s := namespaceSchema(t)
+
+	tbl, err := s.Find("", "billing.invoices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tbl.Schema != "billing" {
+		t.Errorf("got schema %s", tbl.Schema)
+	}
+
+	// names without a schema are in the default schema
+	if tbl, err = s.Find("", "invoices"); err != nil || tbl.Schema != "public" {
+		t.Errorf("got %s, %v", tbl.Schema, err)
+	}
+	if _, err := s.Find("", "billing.users"); err == nil {
+		t.Error("want an error")
+	}
+}
+
+func TestFindPathQualified(t *testing.T) {
+	s := namespaceSchema(t)
+
+	path, err := s.FindPath("billing.invoices", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LT.Schema != "billing" || path[0].LC.Name != "owner_id" {
+		t.Errorf("got path from %s.%s", path[0].LT.Schema, path[0].LC.Name)
+	}
+
+	// the table of the default schema is preferred
+	path, err = s.FindPath("invoices", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LT.Schema != "public" {
+		t.Errorf("got path from %s", path[0].LT.Schema)
+	}
+
+	path, err = s.FindPath("billing.payments", "users", "billing.invoices")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 || path[1].LT.Schema != "billing" {
+		t.Errorf("got path %v", path)
+	}
+
+	if _, err := s.FindPath("billing.refunds", "users", ""); !errors.Is(err, ErrFromEdgeNotFound) {
+		t.Errorf("got error %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package schema
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Option configures how NewDBSchema builds the schema
+type Option func(*schemaOptions)
+
+// schemaOptions holds the options passed to NewDBSchema
+type schemaOptions struct {
+	virtualRels []VirtualRel
+	polyRels    []PolymorphicRel
+	jsonPaths   []JSONPath
+	roles       []Role
+	filters     []TableFilter
+	softDeletes []SoftDelete
+	inferRels   bool
+	minConf     float64
+	acceptRel   func(InferredRel) bool
+	costPaths   bool
+	blockTables []string
+	blockRels   []string
+	ambiguous   bool
+	pathCache   bool
+	lazy        TableLoader
+	aliases     []Alias
+	exactNames  bool
+	maxDepth    int
+	pathTimeout time.Duration
+	remotes     []RemoteTable
+	plugins     []RelPlugin
+	hooks       Hooks
+	tracer      trace.Tracer
+	traceCtx    context.Context
+	metrics     Metrics
+	log         logger
+	template    string
+	auditUser   string
+	policy      Policy
+}
+
+// WithVirtualRels adds relationships that are not backed by foreign
+// key constraints in the database, they are added to the graph
+// exactly as if they were real foreign keys
+func WithVirtualRels(rels ...VirtualRel) Option {
+	return func(o *schemaOptions) {
+		o.virtualRels = append(o.virtualRels, rels...)
+	}
+}
+
+// WithInferredRels adds relationships inferred from column naming
+// conventions (see InferRels) with at least the given confidence, the
+// accept function when not nil can reject any inferred relationship
+func WithInferredRels(minConfidence float64, accept func(InferredRel) bool) Option {
+	return func(o *schemaOptions) {
+		o.inferRels = true
+		o.minConf = minConfidence
+		o.acceptRel = accept
+	}
+}
+
+// WithPolymorphicRels adds polymorphic relationships where a type column
+// selects the table an id column points to
+func WithPolymorphicRels(rels ...PolymorphicRel) Option {
+	return func(o *schemaOptions) {
+		o.polyRels = append(o.polyRels, rels...)
+	}
+}
+
+// WithJSONPaths adds pseudo-columns for values nested in json and jsonb
+// columns, they can be read and filtered but not written
+func WithJSONPaths(paths ...JSONPath) Option {
+	return func(o *schemaOptions) {
+		o.jsonPaths = append(o.jsonPaths, paths...)
+	}
+}
+
+// WithRoles adds roles limiting the tables, columns, rows and mutations
+// a query compiled for the role can use
+func WithRoles(roles ...Role) Option {
+	return func(o *schemaOptions) {
+		o.roles = append(o.roles, roles...)
+	}
+}
+
+// WithTableFilters adds filters the compiler adds to every query, join,
+// update and delete of their tables
+func WithTableFilters(filters ...TableFilter) Option {
+	return func(o *schemaOptions) {
+		o.filters = append(o.filters, filters...)
+	}
+}
+
+// WithSoftDeletes marks the tables whose rows are deleted by setting a
+// column, the deleted rows are left out of queries and joins
+func WithSoftDeletes(tables ...SoftDelete) Option {
+	return func(o *schemaOptions) {
+		o.softDeletes = append(o.softDeletes, tables...)
+	}
+}
+
+// WithCostWeightedPaths makes FindPath pick the path with the lowest
+// estimated join cost based on table row counts, instead of the first
+// shortest path found
+func WithCostWeightedPaths() Option {
+	return func(o *schemaOptions) {
+		o.costPaths = true
+	}
+}
+
+// WithBlockedTables leaves the tables out of the relationship graph so no
+// path is ever found through them, the tables can still be looked up.
+// Names can be 'table' or 'schema.table' and are matched as regular
+// expressions like the DBInfo blocklist
+func WithBlockedTables(names ...string) Option {
+	return func(o *schemaOptions) {
+		o.blockTables = append(o.blockTables, names...)
+	}
+}
+
+// WithBlockedRels leaves the relationships of the foreign key columns
+// given as 'table.column' or 'schema.table.column' out of the graph
+func WithBlockedRels(cols ...string) Option {
+	return func(o *schemaOptions) {
+		o.blockRels = append(o.blockRels, cols...)
+	}
+}
+
+// WithAmbiguousPathErrors makes FindPath return an ErrAmbiguousPath
+// instead of picking one when several equally short paths exist
+func WithAmbiguousPathErrors() Option {
+	return func(o *schemaOptions) {
+		o.ambiguous = true
+	}
+}
+
+// WithPathCache memoizes the results of FindPath so repeated lookups
+// between the same tables skip the graph search, the cache grows with
+// every distinct lookup and is never evicted
+func WithPathCache() Option {
+	return func(o *schemaOptions) {
+		o.pathCache = true
+	}
+}
+
+// WithExactNames turns off the singular and plural lookup of table
+// names, for schemas having both a singular and a plural table eg.
+// "user" and "users"
+func WithExactNames() Option {
+	return func(o *schemaOptions) {
+		o.exactNames = true
+	}
+}
+
+// WithMaxPathDepth limits FindPath to paths of at most hops joins, the
+// search stops at the limit and returns an ErrPathTooDeep when the
+// tables are only joined by longer paths
+func WithMaxPathDepth(hops int) Option {
+	return func(o *schemaOptions) {
+		o.maxDepth = hops
+	}
+}
+
+// WithPathTimeout gives every FindPath search a deadline, a search that
+// takes longer returns context.DeadlineExceeded. The deadline of the
+// context passed to FindPathContext also stops the search
+func WithPathTimeout(d time.Duration) Option {
+	return func(o *schemaOptions) {
+		o.pathTimeout = d
+	}
+}
+
+// DefaultWorkers is the number of catalog queries GetDBInfo runs at
+// the same time unless WithWorkers is used
+const DefaultWorkers = 4
+
+// InfoOption configures how GetDBInfo reads the database catalog
+type InfoOption func(*infoOptions)
+
+// infoOptions holds the options passed to GetDBInfo
+type infoOptions struct {
+	workers   int
+	schemas   []string
+	tables    []string
+	include   []string
+	exclude   []string
+	types     *TypeMap
+	cache     *infoCache
+	hooks     Hooks
+	tracer    trace.Tracer
+	metrics   Metrics
+	log       logger
+	audit     *AuditColumns
+	sensitive *SensitiveColumns
+}
+
+// WithWorkers sets the number of catalog queries run at the same time,
+// each holds a connection of the pool while it runs. Zero or less runs
+// all of them at once
+func WithWorkers(n int) InfoOption {
+	return func(o *infoOptions) {
+		o.workers = n
+	}
+}
+
+// WithSchemas limits discovery to the tables and functions of the
+// given database schemas
+func WithSchemas(names ...string) InfoOption {
+	return func(o *infoOptions) {
+		o.schemas = append(o.schemas, names...)
+	}
+}
+
+// WithTables limits discovery to the named tables, a name is either a
+// table name or a schema qualified one eg. "billing.invoices"
+func WithTables(names ...string) InfoOption {
+	return func(o *infoOptions) {
+		o.tables = append(o.tables, names...)
+	}
+}
+
+// WithIncludeTables limits discovery to the tables matching any of the
+// glob patterns (see path.Match), a pattern with a dot is matched
+// against the schema qualified name. Tables named by WithTables are
+// included as well
+func WithIncludeTables(patterns ...string) InfoOption {
+	return func(o *infoOptions) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExcludeTables leaves out the tables matching any of the glob
+// patterns, exclusion wins over the other options
+func WithExcludeTables(patterns ...string) InfoOption {
+	return func(o *infoOptions) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
+// WithTypeMap sets the logical types of the columns with a type map
+// instead of DefaultTypes
+func WithTypeMap(tm *TypeMap) InfoOption {
+	return func(o *infoOptions) {
+		o.types = tm
+	}
+}
+
+// WithCache saves the DBInfo to a file in dir and reuses it instead of
+// reading the catalog while it is younger than ttl, a ttl of zero or
+// less reuses it until the file is removed. The file is kept for each
+// dsn, block list and set of the options limiting the tables discovered
+func WithCache(dir, dsn string, ttl time.Duration) InfoOption {
+	return func(o *infoOptions) {
+		o.cache = &infoCache{dir: dir, dsn: dsn, ttl: ttl}
+	}
+}
@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBPartition holds a partition of a partitioned table, the partition
+// tables themselves are not included in DBInfo
+type DBPartition struct {
+	ParentSchema string
+	ParentTable  string
+	Key          string
+	Schema       string
+	Name         string
+	Bound        string
+}
+
+// DiscoverPartitions returns the partitions of the partitioned tables
+// in a database
+func DiscoverPartitions(ctx context.Context, db Querier, dbtype string) ([]DBPartition, error) {
+	switch dbtype {
+	case "", "postgres":
+	default:
+		return nil, nil
+	}
+
+	rows, err := db.Query(ctx, postgresPartitionsStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching partitions: %s", err)
+	}
+	defer rows.Close()
+
+	var parts []DBPartition
+
+	for rows.Next() {
+		var p DBPartition
+
+		err = rows.Scan(&p.ParentSchema, &p.ParentTable, &p.Key, &p.Schema, &p.Name, &p.Bound)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, p)
+	}
+
+	return parts, rows.Err()
+}
+
+// addPartitions adds the partitions to their partitioned table, partitions
+// of partitions are added to the top most table found in DBInfo
+func (di *DBInfo) addPartitions(parts []DBPartition) {
+	parent := make(map[string]string, len(parts))
+	for _, p := range parts {
+		parent[(p.Schema + ":" + p.Name)] = (p.ParentSchema + ":" + p.ParentTable)
+	}
+
+	for _, p := range parts {
+		k := (p.ParentSchema + ":" + p.ParentTable)
+		tid, ok := di.tableMap[k]
+
+		for i := 0; !ok && i < len(parts); i++ {
+			if k, ok = parent[k]; !ok {
+				break
+			}
+			tid, ok = di.tableMap[k]
+		}
+
+		if !ok {
+			continue
+		}
+		t := &di.Tables[tid]
+
+		if t.PartitionKey == "" && t.Schema == p.ParentSchema && t.Name == p.ParentTable {
+			t.PartitionKey = p.Key
+		}
+		t.Partitions = append(t.Partitions, p)
+	}
+}
@@ -0,0 +1,38 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiscoverPartitions(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "shop"}},
+		postgresColumnsStmt: {
+			columnRow("public", "events", "id", "bigint", true, true),
+			columnRow("public", "events", "created_at", "timestamptz", true, false),
+		},
+		postgresPartitionsStmt: {
+			{"public", "events", "RANGE (created_at)", "public", "events_2024", "FOR VALUES FROM ('2024-01-01') TO ('2025-01-01')"},
+			{"public", "events_2024", "LIST (region)", "public", "events_2024_eu", "FOR VALUES IN ('eu')"},
+			{"public", "missing", "RANGE (id)", "public", "missing_1", ""},
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(di.Tables) != 1 {
+		t.Fatalf("got tables %v", di.Tables)
+	}
+
+	tbl, _ := di.GetTable("public", "events")
+	if tbl.PartitionKey != "RANGE (created_at)" {
+		t.Errorf("got partition key %q", tbl.PartitionKey)
+	}
+	// the partition of a partition is added to the top most table
+	if len(tbl.Partitions) != 2 || tbl.Partitions[1].Name != "events_2024_eu" {
+		t.Errorf("got partitions %v", tbl.Partitions)
+	}
+}
@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// PathCacheStats holds the statistics of the FindPath cache
+type PathCacheStats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// pathCache memoizes FindPath results and is safe for concurrent use,
+// each snapshot of a schema has its own entries and they share the hit
+// and miss counts
+type pathCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedPath
+	hits    *atomic.Uint64
+	misses  *atomic.Uint64
+}
+
+// cachedPath is a FindPath result
+type cachedPath struct {
+	path []TPath
+	err  error
+}
+
+func newPathCache() *pathCache {
+	return &pathCache{
+		entries: make(map[string]cachedPath),
+		hits:    new(atomic.Uint64),
+		misses:  new(atomic.Uint64),
+	}
+}
+
+// get returns a copy of the cached path so callers can modify it
+func (c *pathCache) get(k string) (cachedPath, bool) {
+	c.mu.RLock()
+	v, ok := c.entries[k]
+	c.mu.RUnlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return v, false
+	}
+	c.hits.Add(1)
+
+	if v.path != nil {
+		v.path = append([]TPath(nil), v.path...)
+	}
+	return v, true
+}
+
+func (c *pathCache) set(k string, v cachedPath) {
+	c.mu.Lock()
+	c.entries[k] = v
+	c.mu.Unlock()
+}
+
+// next returns an empty cache for a new snapshot, the hit and miss
+// counts are kept
+func (c *pathCache) next() *pathCache {
+	if c == nil {
+		return nil
+	}
+	return &pathCache{entries: make(map[string]cachedPath), hits: c.hits, misses: c.misses}
+}
+
+// PathCacheStats returns the statistics of the FindPath cache, they are
+// all zero when the cache is not enabled
+func (s *DBSchema) PathCacheStats() PathCacheStats {
+	s = s.current()
+
+	var st PathCacheStats
+	if s.pathCache == nil {
+		return st
+	}
+
+	st.Hits = s.pathCache.hits.Load()
+	st.Misses = s.pathCache.misses.Load()
+
+	s.pathCache.mu.RLock()
+	st.Entries = len(s.pathCache.entries)
+	s.pathCache.mu.RUnlock()
+	return st
+}
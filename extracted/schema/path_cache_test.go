@@ -0,0 +1,54 @@
+package schema
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPathCache(t *testing.T) {
+	s := blogTestSchema(t, WithPathCache())
+
+	want, err := s.FindPath("comments", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if p, err := s.FindPath("comments", "users", ""); err != nil || pathString(p) != pathString(want) {
+				t.Errorf("got path %s, %v", pathString(p), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// errors are cached too
+	for i := 0; i < 2; i++ {
+		if _, err := s.FindPath("comments", "missing", ""); err == nil {
+			t.Error("want an error for a missing table")
+		}
+	}
+
+	st := s.PathCacheStats()
+	if st.Hits != 9 || st.Misses != 2 || st.Entries != 2 {
+		t.Errorf("got stats %+v", st)
+	}
+
+	// a cached path can be changed by the caller
+	want[0].LC.Name = "changed"
+	if p, _ := s.FindPath("comments", "users", ""); p[0].LC.Name == "changed" {
+		t.Error("the cached path was changed")
+	}
+}
+
+func TestPathCacheDisabled(t *testing.T) {
+	s := blogTestSchema(t)
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Fatal(err)
+	}
+	if st := s.PathCacheStats(); st != (PathCacheStats{}) {
+		t.Errorf("got stats %+v", st)
+	}
+}
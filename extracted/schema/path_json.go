@@ -0,0 +1,13 @@
+package schema
+
+import (
+	"encoding/json"
+)
+
+// MarshalJSON returns a hop of a path in the JSON encoding of a DBRel
+func (tp TPath) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newRelJSON(tp.Rel,
+		newRelEnd(tp.LT, tp.LC, tp.LCs),
+		newRelEnd(tp.RT, tp.RC, tp.RCs),
+		tp.Through, tp.Poly))
+}
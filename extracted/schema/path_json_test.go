@@ -0,0 +1,109 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPathJSON(t *testing.T) {
+	s := blogTestSchema(t)
+
+	path, err := s.FindPath("comments", "posts", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"type":"one_to_one","left":{"table":"public.comments","column":"post_id"},"right":{"table":"public.posts","column":"id"}}]`
+	if string(b) != want {
+		t.Errorf("got %s\nwant %s", b, want)
+	}
+
+	ti, err := s.Find("", "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rels, err := s.GetTableRels(ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, r := range rels {
+		if r.Name != "comments" {
+			continue
+		}
+		found = true
+		b, err := json.Marshal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"name":"comments","many":true,"type":"one_to_many","left":{"table":"public.posts","column":"id"},"right":{"table":"public.comments","column":"post_id"}}`
+		if string(b) != want {
+			t.Errorf("got %s\nwant %s", b, want)
+		}
+	}
+	if !found {
+		t.Errorf("no comments relationship of posts in %v", rels)
+	}
+}
+
+func TestRelTypeText(t *testing.T) {
+	for rt, name := range relTypeNames {
+		b, err := rt.MarshalText()
+		if err != nil || string(b) != name {
+			t.Errorf("%d: got %s, %v", rt, b, err)
+		}
+		var got RelType
+		if err := got.UnmarshalText(b); err != nil || got != rt {
+			t.Errorf("%s: got %d, %v", name, got, err)
+		}
+	}
+	if _, err := RelType(100).MarshalText(); err == nil {
+		t.Error("want an error for an unknown type")
+	}
+	var rt RelType
+	if err := rt.UnmarshalText([]byte("many")); err == nil {
+		t.Error("want an error for an unknown name")
+	}
+}
+
+func TestRelJSON(t *testing.T) {
+	users := DBTable{Schema: "public", Name: "users"}
+	tags := DBTable{Schema: "public", Name: "tags"}
+	rel := DBRel{
+		Type:  RelManyToMany,
+		Left:  DBRelLeft{Ti: users, Col: DBColumn{Name: "id"}},
+		Right: DBRelRight{Ti: tags, Col: DBColumn{Name: "id"}},
+		Through: DBRelThrough{
+			Ti:   DBTable{Schema: "public", Name: "user_tags"},
+			ColL: DBColumn{Name: "user_id"},
+			ColR: DBColumn{Name: "tag_id"},
+		},
+	}
+	b, err := json.Marshal(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"many_to_many","left":{"table":"public.users","column":"id"},"right":{"table":"public.tags","column":"id"},"through":{"table":"public.user_tags","left":"user_id","right":"tag_id"}}`
+	if string(b) != want {
+		t.Errorf("got %s\nwant %s", b, want)
+	}
+
+	// the columns of a composite key are listed
+	rel = DBRel{
+		Type:  RelOneToOne,
+		Left:  DBRelLeft{Ti: users, Col: DBColumn{Name: "a"}, Cols: []DBColumn{{Name: "a"}, {Name: "b"}}},
+		Right: DBRelRight{Ti: tags, Col: DBColumn{Name: "x"}, Cols: []DBColumn{{Name: "x"}, {Name: "y"}}},
+		Poly:  DBRelPoly{TypeCol: DBColumn{Name: "kind"}, TypeValue: "user"},
+	}
+	b, err = json.Marshal(rel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"type":"one_to_one","left":{"table":"public.users","column":"a","columns":["a","b"]},"right":{"table":"public.tags","column":"x","columns":["x","y"]},"poly":{"typeColumn":"kind","typeValue":"user"}}`
+	if string(b) != want {
+		t.Errorf("got %s\nwant %s", b, want)
+	}
+}
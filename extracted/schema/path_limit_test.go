@@ -0,0 +1,83 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// chainSchema has a chain of tables each belonging to the one before it
+// and a table joined to none of them
+func chainSchema(t *testing.T, opts ...Option) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("a", "id pk").
+		Table("b", "id pk", "a_id notnull").
+		Table("c", "id pk", "b_id notnull").
+		Table("d", "id pk", "c_id notnull").
+		Table("e", "id pk", "d_id notnull").
+		Table("lone", "id pk").
+		FK("b.a_id", "a.id").
+		FK("c.b_id", "b.id").
+		FK("d.c_id", "c.id").
+		FK("e.d_id", "d.id").
+		BuildSchema(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestMaxPathDepth(t *testing.T) {
+	s := chainSchema(t, WithMaxPathDepth(2), WithPathCache())
+
+	path, err := s.FindPath("c", "a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 2 {
+		t.Errorf("got path %s", joinString(path))
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.FindPath("e", "a", ""); !errors.Is(err, ErrPathTooDeep) {
+			t.Errorf("got %v, want ErrPathTooDeep", err)
+		}
+	}
+	// tables that are not joined at all are not too deep
+	if _, err := s.FindPath("lone", "a", ""); err == nil || errors.Is(err, ErrPathTooDeep) {
+		t.Errorf("got %v, want a path not found", err)
+	}
+
+	// without a limit any depth is found
+	path, err = chainSchema(t).FindPath("e", "a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 4 {
+		t.Errorf("got path %s", joinString(path))
+	}
+}
+
+func TestPathTimeout(t *testing.T) {
+	s := chainSchema(t, WithPathCache())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := s.FindPathContext(ctx, "e", "a", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want the error of the context", err)
+	}
+	// the stopped search is not cached
+	if _, err := s.FindPath("e", "a", ""); err != nil {
+		t.Error(err)
+	}
+
+	s = chainSchema(t, WithPathTimeout(time.Nanosecond))
+	if _, err := s.FindPath("e", "a", ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := chainSchema(t, WithPathTimeout(time.Minute)).FindPath("e", "a", ""); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// RelPlugin contributes relationships to data that lives outside the
+// database, eg. documents of a search index or values of a key-value
+// store. When the schema is built it returns remote tables related to
+// the database tables and their rows are fetched by the plugin once the
+// query has run (see remote.Plugin)
+type RelPlugin interface {
+	// Name names the plugin in errors
+	Name() string
+
+	// Tables returns the remote tables of the plugin, the tables of the
+	// database are in the DBInfo
+	Tables(di *DBInfo) ([]RemoteTable, error)
+}
+
+// WithRelPlugins adds the remote tables of plugins to the graph, a table
+// of a plugin is added like one of WithRemoteTables
+func WithRelPlugins(plugins ...RelPlugin) Option {
+	return func(o *schemaOptions) {
+		o.plugins = append(o.plugins, plugins...)
+	}
+}
+
+// pluginTables returns the remote tables of the plugins and records the
+// plugin of each of them
+func (s *DBSchema) pluginTables(info *DBInfo, plugins []RelPlugin) ([]RemoteTable, error) {
+	var tables []RemoteTable
+	for _, p := range plugins {
+		rts, err := p.Tables(info)
+		if err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+		for _, rt := range rts {
+			if op, ok := s.plugins[rt.Name]; ok {
+				return nil, fmt.Errorf("plugin %s: remote table %s is already added by plugin %s",
+					p.Name(), rt.Name, op.Name())
+			}
+			if s.plugins == nil {
+				s.plugins = make(map[string]RelPlugin)
+			}
+			s.plugins[rt.Name] = p
+		}
+		tables = append(tables, rts...)
+	}
+	return tables, nil
+}
+
+// Plugin returns the plugin that added a remote table, nil when the table
+// is not one of a plugin
+func (s *DBSchema) Plugin(table string) RelPlugin {
+	return s.plugins[table]
+}
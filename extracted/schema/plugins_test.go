@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testPlugin contributes the reviews of products
+type testPlugin struct {
+	name   string
+	tables []RemoteTable
+	err    error
+}
+
+func (p testPlugin) Name() string { return p.name }
+
+func (p testPlugin) Tables(di *DBInfo) ([]RemoteTable, error) {
+	return p.tables, p.err
+}
+
+func pluginSchema(plugins ...RelPlugin) (*DBSchema, error) {
+	return NewTestSchema().
+		Table("products", "id pk", "sku text").
+		BuildSchema(WithRelPlugins(plugins...))
+}
+
+var reviews = RemoteTable{
+	Name:    "reviews",
+	Table:   "products",
+	Column:  "sku",
+	Columns: []DBColumn{{Name: "stars", Type: "integer"}},
+}
+
+func TestRelPlugins(t *testing.T) {
+	p := testPlugin{name: "search", tables: []RemoteTable{reviews}}
+	s, err := pluginSchema(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ti, err := s.Find("", "reviews")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Type != "remote" {
+		t.Errorf("got table type %q", ti.Type)
+	}
+	path, err := s.FindPath("products", "reviews", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].Rel != RelRemote || path[0].LC.Name != "sku" {
+		t.Errorf("got path %s", joinString(path))
+	}
+	if got := s.Plugin("reviews"); got == nil || got.Name() != "search" {
+		t.Errorf("got plugin %v", got)
+	}
+	if s.Plugin("products") != nil {
+		t.Error("got a plugin of a database table")
+	}
+}
+
+func TestRelPluginErrors(t *testing.T) {
+	failed := errors.New("index down")
+	if _, err := pluginSchema(testPlugin{name: "search", err: failed}); !errors.Is(err, failed) || !strings.Contains(err.Error(), "plugin search:") {
+		t.Errorf("got %v, want the error of the plugin", err)
+	}
+
+	a := testPlugin{name: "a", tables: []RemoteTable{reviews}}
+	b := testPlugin{name: "b", tables: []RemoteTable{reviews}}
+	if _, err := pluginSchema(a, b); err == nil || !strings.Contains(err.Error(), "already added by plugin a") {
+		t.Errorf("got %v, want an error for a table added twice", err)
+	}
+
+	bad := reviews
+	bad.Column = "missing"
+	if _, err := pluginSchema(testPlugin{name: "search", tables: []RemoteTable{bad}}); err == nil {
+		t.Error("want an error for a key column not found")
+	}
+}
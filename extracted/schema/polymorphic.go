@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PolymorphicRel declares a polymorphic relationship, eg. notifications
+// with (subject_type, subject_id) pointing at either users or products
+type PolymorphicRel struct {
+	Schema     string
+	Table      string
+	IDColumn   string
+	TypeColumn string
+
+	// Types maps each value of the type column to its table
+	Types map[string]string
+
+	// FKeyCol is the column of the target tables the id column points
+	// to, it defaults to the primary key of each target table
+	FKeyCol string
+}
+
+// addPolymorphicRels adds an edge from the table to each of the target
+// tables of a polymorphic relationship
+func (s *DBSchema) addPolymorphicRels(pr PolymorphicRel) error {
+	if pr.Schema == "" {
+		pr.Schema = s.schema
+	}
+
+	t, err := s.find(pr.Schema, pr.Table)
+	if err != nil {
+		return fmt.Errorf("polymorphic relationship: %w", err)
+	}
+
+	idCol, err := t.GetColumn(pr.IDColumn)
+	if err != nil {
+		return fmt.Errorf("polymorphic relationship: %w", err)
+	}
+
+	typeCol, err := t.GetColumn(pr.TypeColumn)
+	if err != nil {
+		return fmt.Errorf("polymorphic relationship: %w", err)
+	}
+
+	typeValues := make([]string, 0, len(pr.Types))
+	for v := range pr.Types {
+		typeValues = append(typeValues, v)
+	}
+	sort.Strings(typeValues)
+
+	for _, v := range typeValues {
+		ft, err := s.find(pr.Schema, pr.Types[v])
+		if err != nil {
+			return fmt.Errorf("polymorphic relationship: %w", err)
+		}
+
+		fc := ft.PrimaryCol
+		if pr.FKeyCol != "" {
+			if fc, err = ft.GetColumn(pr.FKeyCol); err != nil {
+				return fmt.Errorf("polymorphic relationship: %w", err)
+			}
+		}
+		if fc.Name == "" {
+			return fmt.Errorf("polymorphic relationship: no key column: %s", ft.String())
+		}
+
+		poly := DBRelPoly{
+			TypeCol:   typeCol,
+			TypeValue: v,
+			Types:     pr.Types,
+		}
+		if err := s.addRelToGraph(t, idCol, ft, fc, RelPolymorphic, relExtra{poly: poly}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+// polySchema has notes pointing to users or products by type and id
+func polySchema(t *testing.T, pr PolymorphicRel) (*DBSchema, error) {
+	t.Helper()
+	return NewTestSchema().
+		Table("users", "id pk").
+		Table("products", "id pk", "sku text unique").
+		Table("notes", "id pk", "subject_id notnull", "subject_type text notnull").
+		BuildSchema(WithPolymorphicRels(pr))
+}
+
+var notesRel = PolymorphicRel{
+	Table:      "notes",
+	IDColumn:   "subject_id",
+	TypeColumn: "subject_type",
+	Types:      map[string]string{"User": "users", "Product": "products"},
+}
+
+func TestPolymorphicRels(t *testing.T) {
+	s, err := polySchema(t, notesRel)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for to, typ := range map[string]string{"users": "User", "products": "Product"} {
+		path, err := s.FindPath("notes", to, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rel := path[0]
+		if rel.Rel != RelPolymorphic || rel.Poly.TypeValue != typ || rel.Poly.TypeCol.Name != "subject_type" {
+			t.Errorf("notes -> %s: got %s with type %s = %s", to, rel.Rel, rel.Poly.TypeCol.Name, rel.Poly.TypeValue)
+		}
+		if rel.LC.Name != "subject_id" || rel.RC.Name != "id" {
+			t.Errorf("notes -> %s: got columns %s and %s", to, rel.LC.Name, rel.RC.Name)
+		}
+
+		// the type value is bound as an argument of the join
+		sql, args, err := PathToSQL(path, JoinOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(sql, `"subject_type"`) || len(args) != 1 || args[0] != typ {
+			t.Errorf("notes -> %s: got %s with args %v", to, sql, args)
+		}
+	}
+
+	// the rows of a table pointing to it
+	path, err := s.FindPath("users", "notes", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].Poly.TypeValue != "User" {
+		t.Errorf("users -> notes: got type %s", path[0].Poly.TypeValue)
+	}
+}
+
+func TestPolymorphicKeyColumn(t *testing.T) {
+	pr := notesRel
+	pr.Types = map[string]string{"Product": "products"}
+	pr.FKeyCol = "sku"
+	s, err := polySchema(t, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("notes", "products", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].RC.Name != "sku" {
+		t.Errorf("got key column %s", path[0].RC.Name)
+	}
+}
+
+func TestPolymorphicErrors(t *testing.T) {
+	for _, fn := range []func(*PolymorphicRel){
+		func(pr *PolymorphicRel) { pr.Table = "comments" },
+		func(pr *PolymorphicRel) { pr.IDColumn = "owner_id" },
+		func(pr *PolymorphicRel) { pr.TypeColumn = "owner_type" },
+		func(pr *PolymorphicRel) { pr.Types = map[string]string{"Order": "orders"} },
+		func(pr *PolymorphicRel) { pr.FKeyCol = "code" },
+	} {
+		pr := notesRel
+		fn(&pr)
+		if _, err := polySchema(t, pr); err == nil {
+			t.Errorf("%+v: want an error", pr)
+		}
+	}
+}
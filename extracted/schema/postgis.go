@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+)
+
+// DefaultSRID is the spatial reference of geography columns without one,
+// it is WGS 84 (longitude and latitude)
+const DefaultSRID = 4326
+
+// setGeometry sets the geometry type and SRID of a PostGIS column from its
+// type eg. geometry(Point,4326), the column keeps them empty otherwise
+func setGeometry(c *DBColumn) {
+	t := strings.ToLower(strings.TrimSpace(c.Type))
+	t = strings.TrimSuffix(t, "[]")
+	if i := strings.LastIndexByte(t, '.'); i != -1 && i < strings.IndexByte(t+"(", '(') {
+		t = t[i+1:]
+	}
+
+	var geog bool
+	switch {
+	case strings.HasPrefix(t, "geometry"):
+		t = strings.TrimPrefix(t, "geometry")
+	case strings.HasPrefix(t, "geography"):
+		t = strings.TrimPrefix(t, "geography")
+		geog = true
+	default:
+		return
+	}
+
+	t = strings.TrimSpace(t)
+	c.GeoType = "Geometry"
+	if geog {
+		c.SRID = DefaultSRID
+	}
+	if !strings.HasPrefix(t, "(") || !strings.HasSuffix(t, ")") {
+		return
+	}
+
+	// the original case of the geometry type is kept eg. MultiPolygon
+	v := strings.TrimSpace(c.Type)
+	v = v[strings.IndexByte(v, '(')+1 : strings.LastIndexByte(v, ')')]
+
+	typ, srid, _ := strings.Cut(v, ",")
+	if typ = strings.TrimSpace(typ); typ != "" {
+		c.GeoType = typ
+	}
+	if n, err := strconv.Atoi(strings.TrimSpace(srid)); err == nil && n > 0 {
+		c.SRID = n
+	}
+}
+
+// IsGeography returns true for PostGIS geography columns, their
+// distances are in meters
+func (col DBColumn) IsGeography() bool {
+	t := strings.ToLower(col.Type)
+	if i := strings.LastIndexByte(t, '.'); i != -1 && i < strings.IndexByte(t+"(", '(') {
+		t = t[i+1:]
+	}
+	return col.GeoType != "" && strings.HasPrefix(t, "geography")
+}
@@ -0,0 +1,28 @@
+package schema
+
+import "testing"
+
+func TestSetGeometry(t *testing.T) {
+	tests := []struct {
+		typ     string
+		geoType string
+		srid    int
+		geog    bool
+	}{
+		{"geometry", "Geometry", 0, false},
+		{"geometry(Point,4326)", "Point", 4326, false},
+		{"public.geometry(MultiPolygon, 3857)", "MultiPolygon", 3857, false},
+		{"geography", "Geometry", DefaultSRID, true},
+		{"geography(Point)", "Point", DefaultSRID, true},
+		{"GEOGRAPHY(LineString,4269)", "LineString", 4269, true},
+		{"text", "", 0, false},
+		{"point", "", 0, false},
+	}
+	for _, tt := range tests {
+		c := DBColumn{Type: tt.typ}
+		setGeometry(&c)
+		if c.GeoType != tt.geoType || c.SRID != tt.srid || c.IsGeography() != tt.geog {
+			t.Errorf("%s: got %q srid %d geography %v", tt.typ, c.GeoType, c.SRID, c.IsGeography())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Querier runs the catalog queries of GetDBInfoFrom and the Discover
+// functions, SQLQuerier adapts a *sql.DB and the pgxdb package a pgx
+// pool, connection or transaction
+type Querier interface {
+	Query(ctx context.Context, query string, args ...interface{}) (Rows, error)
+	QueryRow(ctx context.Context, query string, args ...interface{}) Row
+}
+
+// Rows are the rows returned by a Querier, *sql.Rows is one
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close() error
+}
+
+// Row is the single row returned by a Querier, *sql.Row is one
+type Row interface {
+	Scan(dest ...interface{}) error
+}
+
+// SQLQuerier returns a Querier running on a database/sql pool
+func SQLQuerier(db *sql.DB) Querier {
+	return sqlQuerier{db}
+}
+
+type sqlQuerier struct {
+	db *sql.DB
+}
+
+func (q sqlQuerier) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (q sqlQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
+	return q.db.QueryRowContext(ctx, query, args...)
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// fakeQuerier returns the same rows for every query and keeps the
+// queries it was given
+type fakeQuerier struct {
+	rows    [][]interface{}
+	queries []string
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	q.queries = append(q.queries, query)
+	return &fakeRows{rows: q.rows, i: -1}, nil
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
+	q.queries = append(q.queries, query)
+	return &fakeRows{rows: q.rows}
+}
+
+// fakeRows scans the values of a row into strings, bools and int64s
+type fakeRows struct {
+	rows [][]interface{}
+	i    int
+}
+
+func (r *fakeRows) Next() bool {
+	r.i++
+	return r.i < len(r.rows)
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.i < 0 {
+		r.i = 0
+	}
+	if r.i >= len(r.rows) {
+		return fmt.Errorf("no rows")
+	}
+	row := r.rows[r.i]
+	if len(row) != len(dest) {
+		return fmt.Errorf("scan %d values into %d", len(row), len(dest))
+	}
+	for i, v := range row {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = v.(string)
+		case *bool:
+			*d = v.(bool)
+		case *int64:
+			*d = v.(int64)
+		default:
+			return fmt.Errorf("unsupported scan type %T", d)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRows) Err() error   { return nil }
+func (r *fakeRows) Close() error { return nil }
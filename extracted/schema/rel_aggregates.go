@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"strings"
+)
+
+// AggFunc is an aggregate function over the rows of a related table
+type AggFunc string
+
+const (
+	AggCount AggFunc = "count"
+	AggSum   AggFunc = "sum"
+	AggAvg   AggFunc = "avg"
+	AggMin   AggFunc = "min"
+	AggMax   AggFunc = "max"
+)
+
+// RelAggregate is an aggregate of the rows related to a row named so it
+// can be used as a field of the table, eg. comments_count for the count
+// of the comments of a user or products_max_price
+type RelAggregate struct {
+	Name string
+	Func AggFunc
+	Rel  TableRel
+
+	// Col is the aggregated column of the related table, it is unset
+	// for a count
+	Col DBColumn
+}
+
+// GetRelAggregates returns the aggregates of the relationships of a
+// table to lists of related rows. Every list has a count, numeric
+// columns a sum and an average and numeric and time columns a minimum
+// and a maximum, key columns are left out. Names used by a column or
+// a relationship are skipped
+func (s *DBSchema) GetRelAggregates(t DBTable) ([]RelAggregate, error) {
+	s = s.current()
+
+	rels, err := s.tableRels(t)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]struct{}, len(t.Columns)+len(rels))
+	for _, c := range t.Columns {
+		used[c.Name] = struct{}{}
+	}
+	for _, r := range rels {
+		used[r.Name] = struct{}{}
+	}
+
+	var aggs []RelAggregate
+	add := func(a RelAggregate) {
+		if _, ok := used[a.Name]; !ok {
+			used[a.Name] = struct{}{}
+			aggs = append(aggs, a)
+		}
+	}
+
+	for _, r := range rels {
+		if !aggregatable(r) {
+			continue
+		}
+		add(RelAggregate{Name: r.Name + "_count", Func: AggCount, Rel: r})
+
+		for _, c := range r.Right.Ti.Columns {
+			if c.Blocked || c.Array || c.PrimaryKey || c.FKeyCol != "" {
+				continue
+			}
+			num, tm := isNumericType(c.Type), isTimeType(c.Type)
+
+			fns := []AggFunc{AggSum, AggAvg, AggMin, AggMax}
+			switch {
+			case tm:
+				fns = fns[2:]
+			case !num:
+				continue
+			}
+			for _, fn := range fns {
+				add(RelAggregate{Name: r.Name + "_" + string(fn) + "_" + c.Name, Func: fn, Rel: r, Col: c})
+			}
+		}
+	}
+	return aggs, nil
+}
+
+// aggregatable returns true for the relationships to a list of rows
+// stored in a related table
+func aggregatable(r TableRel) bool {
+	if !r.Many || r.Right.Ti.Blocked || r.Right.Ti.Type == "virtual" {
+		return false
+	}
+	switch r.Type {
+	case RelOneToOne, RelOneToMany, RelRecursive, RelManyToMany:
+		return len(r.Left.Cols) <= 1
+	}
+	return false
+}
+
+// isNumericType returns true for the integer and decimal types
+func isNumericType(typ string) bool {
+	t := strings.ToLower(typ)
+	if strings.HasPrefix(t, "interval") {
+		return false
+	}
+	for _, v := range []string{"int", "smallint", "bigint", "tinyint", "mediumint", "serial",
+		"smallserial", "bigserial", "numeric", "decimal", "float", "double", "real", "money"} {
+		if strings.HasPrefix(t, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTimeType returns true for the date and time types
+func isTimeType(typ string) bool {
+	t := strings.ToLower(typ)
+	return strings.HasPrefix(t, "timestamp") || strings.HasPrefix(t, "date") || strings.HasPrefix(t, "time")
+}
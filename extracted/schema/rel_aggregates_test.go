@@ -0,0 +1,101 @@
+package schema
+
+import "testing"
+
+// testSchemas returns the test schema as built by GetTestSchema and
+// with primary keys that are not unique keys as Postgres reports them
+func testSchemas(t *testing.T) []*DBSchema {
+	t.Helper()
+	s, err := GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	di := GetTestDBInfo()
+	for i, ti := range di.Tables {
+		for j, c := range ti.Columns {
+			if c.PrimaryKey {
+				di.Tables[i].Columns[j].UniqueKey = false
+			}
+		}
+		di.Tables[i].PrimaryCol.UniqueKey = false
+	}
+	is, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*DBSchema{s, is}
+}
+
+// aggNames returns the names of the aggregates of a table
+func aggNames(t *testing.T, s *DBSchema, table string) map[string]RelAggregate {
+	t.Helper()
+	ti, err := s.Find("", table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggs, err := s.GetRelAggregates(ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]RelAggregate, len(aggs))
+	for _, a := range aggs {
+		names[a.Name] = a
+	}
+	return names
+}
+
+func TestRelAggregates(t *testing.T) {
+	tests := []struct {
+		table    string
+		has, not []string
+	}{
+		{
+			table: "users",
+			has: []string{"comments_count", "products_count", "customers_count",
+				"products_sum_price", "products_avg_price", "products_max_created_at"},
+			not: []string{"products_sum_created_at", "products_sum_id", "products_sum_user_id"},
+		},
+		{
+			table: "comments",
+			not:   []string{"product_count", "commenter_count", "user_count", "users_count", "products_count"},
+		},
+		{
+			table: "purchases",
+			not:   []string{"customer_count", "product_count", "customers_count", "products_count"},
+		},
+		{
+			table: "customers",
+			has:   []string{"purchases_count", "purchases_sum_quantity"},
+			not:   []string{"user_count", "product_count"},
+		},
+	}
+
+	for _, s := range testSchemas(t) {
+		for _, tt := range tests {
+			names := aggNames(t, s, tt.table)
+			for _, n := range tt.has {
+				if _, ok := names[n]; !ok {
+					t.Errorf("%s: missing %s", tt.table, n)
+				}
+			}
+			for _, n := range tt.not {
+				if _, ok := names[n]; ok {
+					t.Errorf("%s: unexpected %s", tt.table, n)
+				}
+			}
+		}
+	}
+}
+
+func TestRelAggregateCount(t *testing.T) {
+	for _, s := range testSchemas(t) {
+		a, ok := aggNames(t, s, "users")["comments_count"]
+		if !ok {
+			t.Fatal("missing comments_count")
+		}
+		if a.Func != AggCount || a.Col.Name != "" || !a.Rel.Many || a.Rel.Right.Ti.Name != "comments" {
+			t.Errorf("unexpected comments_count: %s %s many %v to %s", a.Func, a.Col.Name, a.Rel.Many, a.Rel.Right.Ti.Name)
+		}
+	}
+}
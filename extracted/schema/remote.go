@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// RemoteTable is a table whose rows come from an HTTP API instead of the
+// database, a row is fetched by the value of a column of a database
+// table eg. the payments of a user fetched by users.stripe_id. The rows
+// are added to the result after the query runs (see package remote)
+type RemoteTable struct {
+	Name    string
+	Columns []DBColumn // fields of the remote rows
+
+	// Table and Column are the database table and its column holding
+	// the key the remote rows are fetched by
+	Table  string
+	Column string
+}
+
+// WithRemoteTables adds tables served by HTTP APIs, they are related to
+// the database table of their key column
+func WithRemoteTables(tables ...RemoteTable) Option {
+	return func(o *schemaOptions) {
+		o.remotes = append(o.remotes, tables...)
+	}
+}
+
+// addRemoteTables adds the nodes of remote tables, their relationships
+// are added with the ones of the database tables
+func (s *DBSchema) addRemoteTables(tables []RemoteTable) error {
+	for _, rt := range tables {
+		if rt.Name == "" || rt.Table == "" || rt.Column == "" {
+			return fmt.Errorf("remote table: name, table and column are required: %s", rt.Name)
+		}
+
+		schema, name := s.splitTableName(rt.Table)
+		v, ok := s.tindex[(schema + ":" + name)]
+		if !ok {
+			return fmt.Errorf("remote table %s: table not found: %s", rt.Name, rt.Table)
+		}
+		pt := s.tables[v.nodeID]
+
+		pc, err := pt.GetColumn(rt.Column)
+		if err != nil {
+			return fmt.Errorf("remote table %s: %w", rt.Name, err)
+		}
+
+		if _, ok := s.tindex[(s.schema + ":" + rt.Name)]; ok {
+			return fmt.Errorf("remote table %s: table already exists", rt.Name)
+		}
+
+		cols := make([]DBColumn, len(rt.Columns))
+		for i, c := range rt.Columns {
+			c.ID = int32(i)
+			cols[i] = c
+		}
+		t := NewDBTable(s.schema, rt.Name, "remote", cols)
+
+		// the key points to the column holding it, it is not a field of
+		// the remote rows
+		t.PrimaryCol = DBColumn{
+			Name:       pc.Name,
+			Type:       pc.Type,
+			Schema:     t.Schema,
+			Table:      t.Name,
+			FKeySchema: pt.Schema,
+			FKeyTable:  pt.Name,
+			FKeyCol:    pc.Name,
+			Blocked:    true,
+		}
+		s.addNode(t)
+	}
+	return nil
+}
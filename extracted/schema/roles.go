@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// Role declares what a role can read and write, eg. an anon role that
+// can only read the names of products is
+// Role{Name: "anon", Restricted: true, Tables: []RoleTable{{Table: "products", Columns: []string{"id", "name"}}}}
+type Role struct {
+	Name string
+
+	// Restricted denies the tables that are not listed, otherwise they
+	// can be read and written without restrictions
+	Restricted bool
+
+	Tables []RoleTable
+}
+
+// RoleTable is the access of a role to a table
+type RoleTable struct {
+	Schema string
+	Table  string
+
+	// Block denies the table, it cannot be selected, joined through
+	// or written
+	Block bool
+
+	// Columns are the only columns the role can use when set and
+	// BlockColumns the ones it cannot
+	Columns      []string
+	BlockColumns []string
+
+	// Masks are the columns the role reads masked
+	Masks []ColumnMask
+
+	// Filter is a where expression the rows read, updated or deleted
+	// must match eg. { user_id: { eq: $user_id } }, its variables are
+	// set by the caller and not by the operation
+	Filter string
+
+	// Insert, Update, Upsert and Delete are the mutations allowed
+	Insert bool
+	Update bool
+	Upsert bool
+	Delete bool
+}
+
+// TableAccess is the access of a role to a table returned with the
+// table as the role sees it
+type TableAccess struct {
+	Filter string
+	Insert bool
+	Update bool
+	Upsert bool
+	Delete bool
+}
+
+// FullAccess is the access to tables not restricted by a role
+var FullAccess = TableAccess{Insert: true, Update: true, Upsert: true, Delete: true}
+
+// role is a role with its tables indexed by 'schema:table'
+type role struct {
+	restricted bool
+	tables     map[string]RoleTable
+}
+
+// addRoles checks the tables and columns of the roles and indexes them
+func (s *DBSchema) addRoles(roles []Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+	s.roles = make(map[string]*role, len(roles))
+
+	for _, r := range roles {
+		if r.Name == "" {
+			return fmt.Errorf("role: name required")
+		}
+		if _, ok := s.roles[r.Name]; ok {
+			return fmt.Errorf("role: duplicate role: %s", r.Name)
+		}
+		ro := &role{restricted: r.Restricted, tables: make(map[string]RoleTable, len(r.Tables))}
+
+		for _, rt := range r.Tables {
+			if rt.Schema == "" {
+				rt.Schema = s.schema
+			}
+			k := rt.Schema + ":" + rt.Table
+
+			v, ok := s.tindex[k]
+			if !ok {
+				return fmt.Errorf("role %s: table not found: %s.%s", r.Name, rt.Schema, rt.Table)
+			}
+			t := s.tables[v.nodeID]
+
+			for _, cn := range append(rt.Columns[:len(rt.Columns):len(rt.Columns)], rt.BlockColumns...) {
+				if _, ok := t.getColumn(cn); !ok {
+					return fmt.Errorf("role %s: column not found: %s.%s", r.Name, rt.Table, cn)
+				}
+			}
+			for _, cm := range rt.Masks {
+				if err := s.checkMask(t, cm); err != nil {
+					return fmt.Errorf("role %s: %s", r.Name, err)
+				}
+			}
+			if _, ok := ro.tables[k]; ok {
+				return fmt.Errorf("role %s: duplicate table: %s.%s", r.Name, rt.Schema, rt.Table)
+			}
+			ro.tables[k] = rt
+		}
+		s.roles[r.Name] = ro
+	}
+	return nil
+}
+
+// HasRole returns true when the role is defined
+func (s *DBSchema) HasRole(name string) bool {
+	_, ok := s.roles[name]
+	return ok
+}
+
+// RoleTable returns a table as a role sees it, the table is blocked
+// when the role is denied it and so are the columns the role cannot
+// use, masked columns have their mask set. The sensitive columns the
+// role does not mask are masked as the policy says. The empty role has
+// full access to every table and reads every column as it is
+func (s *DBSchema) RoleTable(name string, t DBTable) (DBTable, TableAccess, error) {
+	if name == "" {
+		return t, FullAccess, nil
+	}
+
+	ro, ok := s.roles[name]
+	if !ok {
+		return t, TableAccess{}, fmt.Errorf("role not found: %s", name)
+	}
+
+	rt, ok := ro.tables[t.Schema+":"+t.Name]
+	if !ok && ro.restricted || ok && rt.Block {
+		t.Blocked = true
+		return t, TableAccess{}, nil
+	}
+
+	acc := FullAccess
+	if ok {
+		acc = TableAccess{
+			Filter: rt.Filter,
+			Insert: rt.Insert,
+			Update: rt.Update,
+			Upsert: rt.Upsert,
+			Delete: rt.Delete,
+		}
+	}
+	masks := s.policyMasks(t, rt.Masks)
+	if len(rt.Columns) == 0 && len(rt.BlockColumns) == 0 && len(masks) == 0 {
+		return t, acc, nil
+	}
+
+	allowed := func(cn string) bool {
+		for _, v := range rt.BlockColumns {
+			if v == cn {
+				return false
+			}
+		}
+		if len(rt.Columns) == 0 {
+			return true
+		}
+		for _, v := range rt.Columns {
+			if v == cn {
+				return true
+			}
+		}
+		return false
+	}
+
+	// the columns are copied so the table of the schema is not modified,
+	// the column map still holds as the order is unchanged
+	t.Columns = append([]DBColumn{}, t.Columns...)
+	for i, c := range t.Columns {
+		if !allowed(c.Name) {
+			t.Columns[i].Blocked = true
+		}
+	}
+
+	t.FullText = append([]DBColumn{}, t.FullText...)
+	for i, c := range t.FullText {
+		if !allowed(c.Name) {
+			t.FullText[i].Blocked = true
+		}
+	}
+
+	if t.PrimaryCol.Name != "" && !allowed(t.PrimaryCol.Name) {
+		t.PrimaryCol.Blocked = true
+	}
+	maskColumns(&t, masks)
+	return t, acc, nil
+}
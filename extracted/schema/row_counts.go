@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+)
+
+// DBRowCount holds the estimated number of rows in a table
+type DBRowCount struct {
+	Schema string
+	Table  string
+	Rows   int64
+}
+
+// DiscoverRowCounts returns the estimated row counts of tables taken
+// from the database statistics so they are only as fresh as the last
+// analyze
+func DiscoverRowCounts(ctx context.Context, db Querier, dbtype string) ([]DBRowCount, error) {
+	var sqlStmt string
+
+	switch dbtype {
+	case "mysql", "mariadb":
+		sqlStmt = mysqlRowCountsStmt
+	case "mssql":
+		sqlStmt = mssqlRowCountsStmt
+	case "cockroach", "cockroachdb":
+		// pg_class on cockroach has no reltuples or relispartition, the
+		// estimates are the row counts of the table statistics
+		sqlStmt = cockroachRowCountsStmt
+	case "sqlite":
+		// sqlite keeps no row count statistics by default
+		return nil, nil
+	case "bigquery":
+		// the table storage view is only readable with a region
+		return nil, nil
+	case "snowflake":
+		sqlStmt = snowflakeRowCountsStmt
+	case "clickhouse":
+		sqlStmt = clickhouseRowCountsStmt
+	case "duckdb":
+		sqlStmt = duckdbRowCountsStmt
+	case "redshift":
+		sqlStmt = redshiftRowCountsStmt
+	case "trino":
+		// table statistics are only read with show stats per table
+		return nil, nil
+	default:
+		sqlStmt = postgresRowCountsStmt
+	}
+
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching row counts: %s", err)
+	}
+	defer rows.Close()
+
+	var counts []DBRowCount
+
+	for rows.Next() {
+		var rc DBRowCount
+
+		if err = rows.Scan(&rc.Schema, &rc.Table, &rc.Rows); err != nil {
+			return nil, err
+		}
+		counts = append(counts, rc)
+	}
+
+	return counts, rows.Err()
+}
+
+// addRowCounts sets the estimated row counts of tables
+func (di *DBInfo) addRowCounts(counts []DBRowCount) {
+	for _, rc := range counts {
+		if t, err := di.GetTable(rc.Schema, rc.Table); err == nil {
+			t.RowCount = rc.Rows
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiscoverRowCountsCockroach(t *testing.T) {
+	q := &fakeQuerier{rows: [][]interface{}{
+		{"public", "users", int64(1200)},
+		{"public", "posts", int64(0)},
+	}}
+
+	for _, dbtype := range []string{"cockroach", "cockroachdb"} {
+		q.queries = nil
+		counts, err := DiscoverRowCounts(context.Background(), q, dbtype)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(q.queries) != 1 || q.queries[0] != cockroachRowCountsStmt {
+			t.Fatalf("%s: not queried with the cockroach statement", dbtype)
+		}
+		for _, v := range []string{"reltuples", "relispartition"} {
+			if strings.Contains(q.queries[0], v) {
+				t.Errorf("%s: statement uses %s", dbtype, v)
+			}
+		}
+
+		want := []DBRowCount{
+			{Schema: "public", Table: "users", Rows: 1200},
+			{Schema: "public", Table: "posts", Rows: 0},
+		}
+		if !reflect.DeepEqual(counts, want) {
+			t.Errorf("%s: got %+v, want %+v", dbtype, counts, want)
+		}
+	}
+}
@@ -3,8 +3,14 @@
 package schema
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/yourusername/graphjin-extracted/util"
 )
@@ -29,8 +35,35 @@ type DBSchema struct {
 	tindex            map[string]nodeInfo     // table index
 	tableAliasIndex   map[string]nodeInfo     // table alias index
 	edgesIndex        map[string][]edgeInfo   // edges index
-	allEdges          map[int32]TEdge         // all edges
+	allEdges          map[int32]edge          // all edges
 	relationshipGraph *util.Graph             // relationship graph
+	costPaths         bool                    // pick paths by join cost
+	blockedTables     []string                // tables left out of the graph
+	blockedRels       map[string]struct{}     // fk columns left out of the graph
+	ambiguousPaths    bool                    // error on equally short paths
+	pathCache         *pathCache              // memoized paths, nil when disabled
+	maxPathDepth      int                     // most joins of a path, zero is no limit
+	pathTimeout       time.Duration           // longest search of a path, zero is no limit
+	roles             map[string]*role        // access of roles by name
+	tableFilters      map[string][]string     // filters of tables by 'schema:table'
+	softDeletes       map[string]DBColumn     // soft delete columns by 'schema:table'
+	lazy              *lazyTables             // loaded partial tables, nil unless lazy
+	exactNames        bool                    // no singular and plural lookups
+	removed           map[int32]struct{}      // nodes of removed tables
+	fingerprint       string                  // hash of the tables and relationships
+	onRel             func(*DBRel) bool       // relationship hook, see WithHooks
+	tracer            trace.Tracer            // records spans, nil unless WithTracing
+	metrics           Metrics                 // nil unless WithMetrics
+	log               logger                  // debug events, see WithLogger
+	tenantTemplate    string                  // schema of the tenant tables, see WithTenantTemplate
+	auditUser         string                  // variable of the session user, see WithAuditUser
+	policy            Policy                  // handling of sensitive columns, see WithPolicy
+	plugins           map[string]RelPlugin    // plugins of remote tables by name, see WithRelPlugins
+
+	// live holds the current snapshot of the graph, the lookups read it
+	// and AddTable, AddRelationship and RemoveTable swap in a new one.
+	// It is nil on a snapshot
+	live *snapshots
 }
 
 type RelType int
@@ -44,12 +77,14 @@ const (
 	RelEmbedded
 	RelRemote
 	RelSkip
+	RelManyToMany
 )
 
 // DBRelLeft represents database information
 type DBRelLeft struct {
-	Ti  DBTable
-	Col DBColumn
+	Ti   DBTable
+	Col  DBColumn
+	Cols []DBColumn // all columns of a composite key
 }
 
 // DBRelRight represents a database relationship
@@ -57,20 +92,81 @@ type DBRelRight struct {
 	VTable string
 	Ti     DBTable
 	Col    DBColumn
+	Cols   []DBColumn // all columns of a composite key
 }
 
 // DBRel represents a database relationship
 type DBRel struct {
-	Type  RelType
-	Left  DBRelLeft
-	Right DBRelRight
+	Type    RelType
+	Left    DBRelLeft
+	Right   DBRelRight
+	Poly    DBRelPoly
+	Through DBRelThrough
+}
+
+// DBRelThrough represents the join table of a many-to-many relationship
+type DBRelThrough struct {
+	Ti   DBTable
+	ColL DBColumn // join table column pointing to the left table
+	ColR DBColumn // join table column pointing to the right table
+}
+
+// DBRelPoly represents the discriminator of a polymorphic relationship
+type DBRelPoly struct {
+	TypeCol   DBColumn          // column holding the type value
+	TypeValue string            // type value that selects this relationship
+	Types     map[string]string // all type values mapped to their tables
 }
 
 // NewDBSchema creates a new database schema
 func NewDBSchema(
 	info *DBInfo,
 	aliases map[string][]string,
+	opts ...Option,
 ) (*DBSchema, error) {
+	var so schemaOptions
+	for _, fn := range opts {
+		fn(&so)
+	}
+
+	ctx := so.traceCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := startSpan(ctx, so.tracer, "schema.NewDBSchema",
+		attribute.String("db.system", info.Type),
+		attribute.Int("schema.tables", len(info.Tables)))
+
+	s, err := newDBSchema(info, aliases, &so)
+	if err == nil {
+		span.SetAttributes(attribute.Int("schema.relationships", len(s.allEdges)))
+	}
+	endSpan(span, err)
+	return s, err
+}
+
+// newDBSchema is NewDBSchema once the options are set
+func newDBSchema(info *DBInfo, aliases map[string][]string, so *schemaOptions) (*DBSchema, error) {
+	if so.inferRels {
+		for _, r := range InferRels(info) {
+			args := []any{
+				slog.String("from", r.Schema+"."+r.Table+"."+r.Column),
+				slog.String("to", r.FKeySchema+"."+r.FKeyTable+"."+r.FKeyCol),
+				slog.Float64("confidence", r.Confidence),
+			}
+			if r.Confidence < so.minConf {
+				so.log.debug("inferred relationship skipped", append(args, slog.String("reason", "low confidence"))...)
+				continue
+			}
+			if so.acceptRel != nil && !so.acceptRel(r) {
+				so.log.debug("inferred relationship skipped", append(args, slog.String("reason", "rejected"))...)
+				continue
+			}
+			so.log.debug("relationship inferred", args...)
+			so.virtualRels = append(so.virtualRels, r.VirtualRel)
+		}
+	}
+
 	schema := &DBSchema{
 		dbType:            info.Type,
 		version:           info.Version,
@@ -81,15 +177,88 @@ func NewDBSchema(
 		tindex:            make(map[string]nodeInfo),
 		tableAliasIndex:   make(map[string]nodeInfo),
 		edgesIndex:        make(map[string][]edgeInfo),
-		allEdges:          make(map[int32]TEdge),
+		allEdges:          make(map[int32]edge),
 		relationshipGraph: util.NewGraph(),
+		costPaths:         so.costPaths,
+		ambiguousPaths:    so.ambiguous,
+		exactNames:        so.exactNames,
+		maxPathDepth:      so.maxDepth,
+		pathTimeout:       so.pathTimeout,
+		onRel:             so.hooks.OnRelationshipDiscovered,
+		tracer:            so.tracer,
+		metrics:           so.metrics,
+		log:               so.log,
+		tenantTemplate:    so.template,
+		auditUser:         so.auditUser,
+		policy:            so.policy,
+	}
+
+	if so.pathCache {
+		schema.pathCache = newPathCache()
+	}
+
+	if err := schema.setBlocklist(so.blockTables, so.blockRels); err != nil {
+		return nil, err
+	}
+
+	tables := info.Tables
+	if so.lazy != nil {
+		tables = keyTables(tables)
 	}
 
-	for _, t := range info.Tables {
+	for _, t := range tables {
 		nid := schema.addNode(t)
 		schema.addAliases(schema.tables[nid], nid, aliases[t.Name])
 	}
 
+	if so.lazy != nil {
+		schema.lazy = &lazyTables{load: so.lazy, tables: make(map[int32]DBTable)}
+		if err := schema.loadOptionTables(so); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := schema.checkTenantTemplate(); err != nil {
+		return nil, err
+	}
+
+	pts, err := schema.pluginTables(info, so.plugins)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := schema.addRemoteTables(append(so.remotes[:len(so.remotes):len(so.remotes)], pts...)); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addColumnAliases(so.aliases); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addVirtualRels(so.virtualRels); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addJSONPaths(so.jsonPaths); err != nil {
+		return nil, err
+	}
+
+	if err := schema.checkPolicy(); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addRoles(so.roles); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addTableFilters(so.filters); err != nil {
+		return nil, err
+	}
+
+	if err := schema.addSoftDeletes(so.softDeletes); err != nil {
+		return nil, err
+	}
+
 	for _, t := range info.VTables {
 		if err := schema.addVirtual(t); err != nil {
 			return nil, err
@@ -103,6 +272,18 @@ func NewDBSchema(
 		}
 	}
 
+	for _, t := range schema.tables {
+		if err := schema.addManyToManyRels(t); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pr := range so.polyRels {
+		if err := schema.addPolymorphicRels(pr); err != nil {
+			return nil, err
+		}
+	}
+
 	// add aliases to edge index by duplicating
 	for t, al := range aliases {
 		for _, alias := range al {
@@ -115,6 +296,10 @@ func NewDBSchema(
 		}
 	}
 
+	if err := schema.addAliasOpts(so.aliases); err != nil {
+		return nil, err
+	}
+
 	// add some standard common functions into the schema
 	for _, v := range funcList {
 		info.Functions = append(info.Functions, DBFunction{
@@ -132,10 +317,14 @@ func NewDBSchema(
 		// as those are considered selector functions
 		if f.Type != "record" {
 			schema.dbFunctions[f.Name] = info.Functions[k]
+		} else {
+			schema.log.debug("function skipped", slog.String("function", f.Name), slog.String("reason", "returns record"))
 		}
 	}
 
-	return schema, nil
+	schema.setFingerprint()
+	schema.graphChanged()
+	return schema.handle(), nil
 }
 
 // addRels adds relationships to the schema
@@ -159,7 +348,7 @@ func (s *DBSchema) addRels(t DBTable) error {
 
 // addJsonRel adds a json relationship to the schema
 func (s *DBSchema) addJsonRel(t DBTable) error {
-	st, err := s.Find(t.SecondaryCol.Schema, t.SecondaryCol.Table)
+	st, err := s.find(t.SecondaryCol.Schema, t.SecondaryCol.Table)
 	if err != nil {
 		return err
 	}
@@ -174,7 +363,7 @@ func (s *DBSchema) addJsonRel(t DBTable) error {
 
 // addPolymorphicRel adds a polymorphic relationship to the schema
 func (s *DBSchema) addPolymorphicRel(t DBTable) error {
-	pt, err := s.Find(t.PrimaryCol.FKeySchema, t.PrimaryCol.FKeyTable)
+	pt, err := s.find(t.PrimaryCol.FKeySchema, t.PrimaryCol.FKeyTable)
 	if err != nil {
 		return err
 	}
@@ -194,7 +383,7 @@ func (s *DBSchema) addPolymorphicRel(t DBTable) error {
 
 // addRemoteRel adds a remote relationship to the schema
 func (s *DBSchema) addRemoteRel(t DBTable) error {
-	pt, err := s.Find(t.PrimaryCol.FKeySchema, t.PrimaryCol.FKeyTable)
+	pt, err := s.find(t.PrimaryCol.FKeySchema, t.PrimaryCol.FKeyTable)
 	if err != nil {
 		return err
 	}
@@ -209,48 +398,111 @@ func (s *DBSchema) addRemoteRel(t DBTable) error {
 
 // addColumnRels adds column relationships to the schema
 func (s *DBSchema) addColumnRels(t DBTable) error {
-	var err error
+	composite := compositeFKeys(t)
 
 	for _, c := range t.Columns {
-		if c.FKeyTable == "" {
-			continue
+		if err := s.addColumnRel(t, c, composite); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		if c.FKeySchema == "" {
-			c.FKeySchema = t.Schema
-		}
+// addColumnRel adds the relationship of a foreign key column
+func (s *DBSchema) addColumnRel(t DBTable, c DBColumn, composite map[string][]DBColumn) error {
+	if c.FKeyTable == "" {
+		return nil
+	}
 
-		v, ok := s.tindex[(c.FKeySchema + ":" + c.FKeyTable)]
-		if !ok {
-			return fmt.Errorf("foreign key table not found: %s.%s", c.FKeySchema, c.FKeyTable)
-		}
-		ft := s.tables[v.nodeID]
+	cols, isComposite := composite[c.FKeyName]
+	if isComposite && c.Name != cols[0].Name {
+		return nil
+	}
 
-		if c.FKeyCol == "" {
-			continue
+	if c.FKeySchema == "" {
+		c.FKeySchema = t.Schema
+	}
+
+	v, ok := s.tindex[(c.FKeySchema + ":" + c.FKeyTable)]
+	if !ok {
+		return fmt.Errorf("foreign key table not found: %s.%s", c.FKeySchema, c.FKeyTable)
+	}
+	ft := s.tables[v.nodeID]
+
+	if c.FKeyCol == "" {
+		return nil
+	}
+
+	fc, ok := ft.getColumn(c.FKeyCol)
+	if !ok {
+		return fmt.Errorf("foreign key column not found: %s.%s", c.FKeyTable, c.FKeyCol)
+	}
+
+	var rt RelType
+
+	switch {
+	case c.FKRecursive: // t.Name == c.FKeyTable:
+		rt = RelRecursive
+	case fc.UniqueKey:
+		rt = RelOneToOne
+	default:
+		rt = RelOneToMany
+	}
+
+	var ex relExtra
+	if isComposite {
+		for _, cc := range cols {
+			fcc, ok := ft.getColumn(cc.FKeyCol)
+			if !ok {
+				return fmt.Errorf("foreign key column not found: %s.%s", cc.FKeyTable, cc.FKeyCol)
+			}
+			ex.lcols = append(ex.lcols, cc)
+			ex.rcols = append(ex.rcols, fcc)
 		}
+	}
 
-		fc, ok := ft.getColumn(c.FKeyCol)
-		if !ok {
-			return fmt.Errorf("foreign key column not found: %s.%s", c.FKeyTable, c.FKeyCol)
+	// a foreign key that is also unique joins at most one row to each
+	// row it references
+	if rt == RelOneToOne && !c.Array {
+		names := []string{c.Name}
+		if isComposite {
+			names = names[:0]
+			for _, cc := range cols {
+				names = append(names, cc.Name)
+			}
 		}
+		ex.unique = t.IsUnique(names...)
+	}
 
-		var rt RelType
+	return s.addRelToGraph(t, c, ft, fc, rt, ex)
+}
 
-		switch {
-		case c.FKRecursive: // t.Name == c.FKeyTable:
-			rt = RelRecursive
-		case fc.UniqueKey:
-			rt = RelOneToOne
-		default:
-			rt = RelOneToMany
+// compositeFKeys returns the columns of each multi-column foreign key
+// of a table keyed by constraint name, the first column returned is
+// the one used to name the relationship, preferring the column that
+// does not have the same name as the column it references
+// eg. order_id -> orders.id over tenant_id -> orders.tenant_id
+func compositeFKeys(t DBTable) map[string][]DBColumn {
+	fks := make(map[string][]DBColumn)
+	for _, c := range t.Columns {
+		if c.FKeyName != "" && c.FKeyTable != "" && c.FKeyCol != "" {
+			fks[c.FKeyName] = append(fks[c.FKeyName], c)
 		}
+	}
 
-		if err = s.addToGraph(t, c, ft, fc, rt); err != nil {
-			return err
+	for k, cols := range fks {
+		if len(cols) < 2 {
+			delete(fks, k)
+			continue
+		}
+		for i, c := range cols {
+			if c.Name != c.FKeyCol {
+				cols[0], cols[i] = cols[i], cols[0]
+				break
+			}
 		}
 	}
-	return nil
+	return fks
 }
 
 // addVirtual adds a virtual table to the schema
@@ -308,9 +560,20 @@ func (s *DBSchema) addVirtual(vt VirtualTable) error {
 	return nil
 }
 
-// GetTables returns a table from the schema
+// GetTables returns the tables of the schema
 func (s *DBSchema) GetTables() []DBTable {
-	return s.tables
+	s = s.current()
+
+	if len(s.removed) == 0 {
+		return s.tables[:len(s.tables):len(s.tables)]
+	}
+	tables := make([]DBTable, 0, len(s.tables)-len(s.removed))
+	for i, t := range s.tables {
+		if _, ok := s.removed[int32(i)]; !ok {
+			tables = append(tables, t)
+		}
+	}
+	return tables
 }
 
 // RelNode represents a relationship node
@@ -322,6 +585,8 @@ type RelNode struct {
 
 // GetFirstDegree returns the first degree relationships of a table
 func (s *DBSchema) GetFirstDegree(t DBTable) (items []RelNode, err error) {
+	s = s.current()
+
 	currNode, ok := s.tindex[(t.Schema + ":" + t.Name)]
 	if !ok {
 		return nil, fmt.Errorf("table not found: %s", t.String())
@@ -336,6 +601,8 @@ func (s *DBSchema) GetFirstDegree(t DBTable) (items []RelNode, err error) {
 
 // GetSecondDegree returns the second degree relationships of a table
 func (s *DBSchema) GetSecondDegree(t DBTable) (items []RelNode, err error) {
+	s = s.current()
+
 	currNode, ok := s.tindex[(t.Schema + ":" + t.Name)]
 	if !ok {
 		return nil, fmt.Errorf("table not found: %s", t.String())
@@ -360,7 +627,7 @@ func (s *DBSchema) getRelNodes(fromID, toID int32) (items []RelNode) {
 		if e1.name == "" {
 			continue
 		}
-		item := RelNode{Name: e1.name, Type: e1.Type, Table: e1.LT}
+		item := RelNode{Name: e1.name, Type: e1.Type, Table: s.tables[e1.From]}
 		items = append(items, item)
 	}
 	return
@@ -0,0 +1,24 @@
+package schema
+
+// SelfRef is a foreign key of a table to the table itself, such as the
+// parent_id of a category or the manager_id of an employee
+type SelfRef struct {
+	Col DBColumn // column holding the reference
+	Key DBColumn // column it references
+}
+
+// SelfRefs returns the foreign keys of a table that reference the table
+// itself, array columns are left out as they cannot be followed one
+// row at a time
+func (ti *DBTable) SelfRefs() []SelfRef {
+	var refs []SelfRef
+	for _, c := range ti.Columns {
+		if !c.FKRecursive || c.Array || c.Blocked {
+			continue
+		}
+		if k, ok := ti.getColumn(c.FKeyCol); ok {
+			refs = append(refs, SelfRef{Col: c, Key: k})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,210 @@
+package schema
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DefaultSensitiveTags are the comment tags of WithSensitiveColumns when
+// it is given none
+var DefaultSensitiveTags = []string{"pii", "sensitive", "secret", "encrypted"}
+
+// SensitiveColumns marks the columns holding sensitive data with tags,
+// eg. pii, from their comments and from patterns
+type SensitiveColumns struct {
+	// Tags are the tags read from column comments written as @tag eg.
+	// 'email of the user @pii', nil uses DefaultSensitiveTags
+	Tags []string
+
+	// Columns are the tags of the columns matching patterns like "ssn"
+	// or "users.email" matched with path.Match
+	Columns map[string][]string
+}
+
+// WithSensitiveColumns sets the Sensitive tags of the columns, the tools
+// of the schema treat them as the policy of WithPolicy says
+func WithSensitiveColumns(sc SensitiveColumns) InfoOption {
+	return func(o *infoOptions) {
+		o.sensitive = &sc
+	}
+}
+
+// check returns an error for an invalid pattern
+func (sc SensitiveColumns) check() error {
+	for p := range sc.Columns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid sensitive column pattern: %s", p)
+		}
+	}
+	return nil
+}
+
+// MarkSensitiveColumns sets the Sensitive tags of the columns of every
+// table from their comments and the patterns, and clears them on the
+// others. Tags are in lower case and sorted
+func (di *DBInfo) MarkSensitiveColumns(sc SensitiveColumns) {
+	known := sc.Tags
+	if known == nil {
+		known = DefaultSensitiveTags
+	}
+
+	for i := range di.Tables {
+		t := &di.Tables[i]
+		for j := range t.Columns {
+			c := &t.Columns[j]
+			c.Sensitive = nil
+
+			tags := make(map[string]struct{})
+			for _, tag := range commentTags(c.Comment) {
+				if inFold(known, tag) {
+					tags[tag] = struct{}{}
+				}
+			}
+			for p, v := range sc.Columns {
+				// the table of a column is matched like the schema of a table
+				if matchTable([]string{p}, c.Table, c.Name) {
+					for _, tag := range v {
+						tags[strings.ToLower(tag)] = struct{}{}
+					}
+				}
+			}
+			for tag := range tags {
+				c.Sensitive = append(c.Sensitive, tag)
+			}
+			sort.Strings(c.Sensitive)
+		}
+	}
+}
+
+// commentTags returns the @tags of a comment in lower case
+func commentTags(comment string) []string {
+	var tags []string
+	for _, w := range strings.Fields(comment) {
+		w = strings.TrimLeft(w, "(")
+		if !strings.HasPrefix(w, "@") {
+			continue
+		}
+		tag := strings.TrimRight(w[1:], ".,;:)")
+		if tag != "" {
+			tags = append(tags, strings.ToLower(tag))
+		}
+	}
+	return tags
+}
+
+// inFold returns true when a value is in a list ignoring case
+func inFold(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSensitive returns true if the column has Sensitive tags
+func (col DBColumn) IsSensitive() bool {
+	return len(col.Sensitive) != 0
+}
+
+// Handling is how the tools of the schema treat a sensitive column
+type Handling struct {
+	// Redact leaves the values out of logs and serialized models, the
+	// compiler marks the params bound to the column
+	Redact bool
+
+	// Mask is how roles read the column unless they mask it themselves,
+	// empty reads it as it is
+	Mask Mask
+
+	// Omit leaves the column out of generated models
+	Omit bool
+}
+
+// Policy returns the handling of a sensitive column, it decides in one
+// place what the compiler, code generators and the linter do with the
+// columns tagged by WithSensitiveColumns
+//
+//	func(c schema.DBColumn) schema.Handling {
+//		h := schema.Handling{Redact: true}
+//		if slices.Contains(c.Sensitive, "pii") {
+//			h.Mask = schema.MaskPartial
+//		}
+//		return h
+//	}
+type Policy func(c DBColumn) Handling
+
+// DefaultPolicy redacts the sensitive columns and neither masks nor
+// omits them
+func DefaultPolicy(c DBColumn) Handling {
+	return Handling{Redact: true}
+}
+
+// Handling returns the handling of a column by the policy, a nil policy
+// is DefaultPolicy and columns without tags are not handled
+func (p Policy) Handling(c DBColumn) Handling {
+	switch {
+	case !c.IsSensitive():
+		return Handling{}
+	case p == nil:
+		return DefaultPolicy(c)
+	}
+	return p(c)
+}
+
+// WithPolicy sets the policy of the sensitive columns, see Policy
+func WithPolicy(p Policy) Option {
+	return func(o *schemaOptions) {
+		o.policy = p
+	}
+}
+
+// Policy returns the policy of the sensitive columns
+func (s *DBSchema) Policy() Policy {
+	return s.policy
+}
+
+// Handling returns the handling of a column by the policy of the schema
+func (s *DBSchema) Handling(c DBColumn) Handling {
+	return s.policy.Handling(c)
+}
+
+// checkPolicy returns an error when the policy masks a column that
+// cannot be masked
+func (s *DBSchema) checkPolicy() error {
+	for _, t := range s.tables {
+		for _, c := range t.Columns {
+			if h := s.Handling(c); h.Mask != "" {
+				if err := s.checkMask(t, ColumnMask{Column: c.Name, Mask: h.Mask}); err != nil {
+					return fmt.Errorf("policy: %s", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// policyMasks returns the masks of a role table and the masks the policy
+// sets on the sensitive columns the role does not mask
+func (s *DBSchema) policyMasks(t DBTable, masks []ColumnMask) []ColumnMask {
+	var out []ColumnMask
+	for _, c := range t.Columns {
+		h := s.Handling(c)
+		if h.Mask == "" || c.JSONCol != "" {
+			continue
+		}
+		masked := false
+		for _, cm := range masks {
+			masked = masked || cm.Column == c.Name
+		}
+		if !masked {
+			out = append(out, ColumnMask{Column: c.Name, Mask: h.Mask})
+		}
+	}
+	if out == nil {
+		return masks
+	}
+	return append(out, masks...)
+}
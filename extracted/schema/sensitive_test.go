@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMarkSensitiveColumns(t *testing.T) {
+	di := &DBInfo{Tables: []DBTable{
+		NewDBTable("public", "users", "", []DBColumn{
+			{Name: "id", Comment: "@pii"},
+			{Name: "email", Comment: "login of the user (@PII)."},
+			{Name: "ssn"},
+			{Name: "note", Comment: "free text @todo", Sensitive: []string{"old"}},
+		}),
+		NewDBTable("public", "orders", "", []DBColumn{{Name: "ssn"}}),
+	}}
+
+	di.MarkSensitiveColumns(SensitiveColumns{Columns: map[string][]string{
+		"ssn":        {"Secret"},
+		"users.ssn":  {"pii"},
+		"orders.n*":  {"pii"},
+		"accounts.*": {"secret"},
+	}})
+	tests := []struct {
+		table, col, want string
+	}{
+		{"users", "id", "pii"},
+		{"users", "email", "pii"},
+		{"users", "ssn", "pii secret"},
+		// unknown tags are not sensitive and old tags are cleared
+		{"users", "note", ""},
+		{"orders", "ssn", "secret"},
+	}
+	for _, tt := range tests {
+		var got string
+		for _, ti := range di.Tables {
+			if c, ok := ti.getColumn(tt.col); ok && ti.Name == tt.table {
+				got = strings.Join(c.Sensitive, " ")
+			}
+		}
+		if got != tt.want {
+			t.Errorf("%s.%s: got %q, want %q", tt.table, tt.col, got, tt.want)
+		}
+	}
+	cols := di.Tables[0].Columns
+
+	// only the tags given are read from the comments
+	di.MarkSensitiveColumns(SensitiveColumns{Tags: []string{"todo"}})
+	if got := strings.Join(cols[3].Sensitive, " "); got != "todo" || cols[1].IsSensitive() {
+		t.Errorf("got %q and %q", got, cols[1].Sensitive)
+	}
+}
+
+func TestWithSensitiveColumns(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "users", "email", "varchar", true, false),
+		},
+	}}
+	sc := SensitiveColumns{Columns: map[string][]string{"email": {"pii"}}}
+	di, err := GetDBInfoFrom(context.Background(), q, "mysql", nil, WithSensitiveColumns(sc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := di.GetColumn("shop", "users", "email"); !c.IsSensitive() {
+		t.Errorf("got email %+v, want it tagged", c)
+	}
+
+	sc.Columns = map[string][]string{"[": {"pii"}}
+	if _, err := GetDBInfoFrom(context.Background(), q, "mysql", nil, WithSensitiveColumns(sc)); err == nil {
+		t.Error("want an error for an invalid pattern")
+	}
+}
+
+// sensitiveInfo has users with a tagged email and a tagged id the
+// orders reference
+func sensitiveInfo(t *testing.T) *DBInfo {
+	t.Helper()
+	di, err := NewTestSchema().
+		Table("users", "id pk", "email text notnull", "name text").
+		Table("orders", "id pk", "user_id notnull").
+		FK("orders.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.MarkSensitiveColumns(SensitiveColumns{Columns: map[string][]string{"users.email": {"pii"}}})
+	return di
+}
+
+func TestPolicy(t *testing.T) {
+	di := sensitiveInfo(t)
+	email, _ := di.GetColumn("public", "users", "email")
+	name, _ := di.GetColumn("public", "users", "name")
+
+	var p Policy
+	if h := p.Handling(*email); !h.Redact || h.Mask != "" || h.Omit {
+		t.Errorf("got %+v, want the default policy", h)
+	}
+	p = func(c DBColumn) Handling { return Handling{Omit: true} }
+	if h := p.Handling(*name); h != (Handling{}) {
+		t.Errorf("got %+v of a column without tags", h)
+	}
+
+	s, err := NewDBSchema(di, nil, WithPolicy(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.Handling(*email).Omit || s.Policy() == nil {
+		t.Errorf("got %+v, want the policy of the schema", s.Handling(*email))
+	}
+}
+
+func TestPolicyMasks(t *testing.T) {
+	mask := func(c DBColumn) Handling { return Handling{Mask: MaskPartial} }
+	roles := WithRoles(
+		Role{Name: "support", Tables: []RoleTable{{Table: "orders"}}},
+		Role{Name: "admin", Tables: []RoleTable{{Table: "users", Masks: []ColumnMask{{Column: "email", Mask: MaskHash}}}}},
+	)
+	s, err := NewDBSchema(sensitiveInfo(t), nil, WithPolicy(mask), roles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users, err := s.Find("", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		role string
+		want Mask
+	}{
+		// a role without the users reads them masked by the policy
+		{"support", MaskPartial},
+		// the mask of a role is its own
+		{"admin", MaskHash},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		rt, _, err := s.RoleTable(tt.role, users)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, _ := rt.getColumn("email")
+		if c.Mask != tt.want {
+			t.Errorf("role %q: got mask %q, want %q", tt.role, c.Mask, tt.want)
+		}
+	}
+
+	// the id is a key the orders join on
+	di := sensitiveInfo(t)
+	di.MarkSensitiveColumns(SensitiveColumns{Columns: map[string][]string{"users.id": {"pii"}}})
+	if _, err := NewDBSchema(di, nil, WithPolicy(mask)); err == nil || !strings.Contains(err.Error(), "policy:") {
+		t.Errorf("got %v, want an error for a masked key", err)
+	}
+}
+
+// the tags are read from fixtures and kept in the JSON of a DBInfo
+func TestSensitiveFixture(t *testing.T) {
+	di, err := LoadFixture(strings.NewReader("tables:\n  - name: users\n    columns:\n      - id pk\n" +
+		"      - name: email\n        sensitive: [pii]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := di.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"sensitive":["pii"]`) {
+		t.Fatalf("got JSON %s", b)
+	}
+	var got DBInfo
+	if err := got.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := got.GetColumn("public", "users", "email"); err != nil || !c.IsSensitive() {
+		t.Errorf("got %+v, %v", c, err)
+	}
+}
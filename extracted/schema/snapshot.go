@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"errors"
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrReadOnly is returned by AddTable, AddRelationship and RemoveTable
+// on a snapshot
+var ErrReadOnly = errors.New("schema snapshot is read only")
+
+// snapshots holds the current snapshot of a schema. Lookups load it
+// without a lock, changes are made to a copy that is swapped in once
+// the change succeeds
+type snapshots struct {
+	mu  sync.Mutex // serializes the changes
+	cur atomic.Pointer[DBSchema]
+}
+
+// handle returns the schema returned by NewDBSchema, the schema it is
+// called on is its first snapshot and is not changed after this
+func (s *DBSchema) handle() *DBSchema {
+	h := *s
+	h.live = &snapshots{}
+	h.live.cur.Store(s)
+	return &h
+}
+
+// current returns the snapshot the lookups read, a snapshot is its own
+func (s *DBSchema) current() *DBSchema {
+	if s.live == nil {
+		return s
+	}
+	return s.live.cur.Load()
+}
+
+// Snapshot returns the schema as it is now. A snapshot never changes so
+// the lookups made on it agree with each other, eg. while compiling a
+// query, when the schema is changed meanwhile. AddTable,
+// AddRelationship and RemoveTable return ErrReadOnly on a snapshot
+func (s *DBSchema) Snapshot() *DBSchema {
+	return s.current()
+}
+
+// update makes a change to a copy of the current snapshot and swaps it
+// in, a failed change leaves the schema as it is. Readers keep the
+// snapshot they loaded until their lookup returns
+func (s *DBSchema) update(fn func(n *DBSchema) error) error {
+	if s.live == nil {
+		return ErrReadOnly
+	}
+	s.live.mu.Lock()
+	defer s.live.mu.Unlock()
+
+	n := s.live.cur.Load().clone()
+	if err := fn(n); err != nil {
+		return err
+	}
+	n.changed()
+	s.live.cur.Store(n)
+	return nil
+}
+
+// clone returns a copy of a snapshot with its own copy of the state the
+// changes write to, the rest is shared as it is never written
+func (s *DBSchema) clone() *DBSchema {
+	n := *s
+	n.live = nil
+	n.tables = append([]DBTable(nil), s.tables...)
+	n.tindex = maps.Clone(s.tindex)
+	n.tableAliasIndex = maps.Clone(s.tableAliasIndex)
+	n.allEdges = maps.Clone(s.allEdges)
+	n.relationshipGraph = s.relationshipGraph.Clone()
+	n.tableFilters = maps.Clone(s.tableFilters)
+	n.softDeletes = maps.Clone(s.softDeletes)
+	n.removed = maps.Clone(s.removed)
+	n.pathCache = s.pathCache.next()
+	if s.lazy != nil {
+		n.lazy = s.lazy.clone()
+	}
+
+	n.edgesIndex = make(map[string][]edgeInfo, len(s.edgesIndex))
+	for k, list := range s.edgesIndex {
+		nl := make([]edgeInfo, len(list))
+		for i, ei := range list {
+			nl[i] = edgeInfo{nodeID: ei.nodeID, edgeIDs: append([]int32(nil), ei.edgeIDs...)}
+		}
+		n.edgesIndex[k] = nl
+	}
+	return &n
+}
@@ -0,0 +1,218 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testLoader returns a loader of the tables of the test schema and the
+// number of tables it loaded
+func testLoader() (TableLoader, *atomic.Int32) {
+	di := GetTestDBInfo()
+	var n atomic.Int32
+	return func(schema, table string) (DBTable, error) {
+		n.Add(1)
+		for _, t := range di.Tables {
+			if t.Schema == schema && t.Name == table {
+				return t, nil
+			}
+		}
+		return DBTable{}, fmt.Errorf("table not found: %s.%s", schema, table)
+	}, &n
+}
+
+// noteTable is a table with a foreign key to users added and removed
+// by the tests
+func noteTable() DBTable {
+	return NewDBTable("public", "notes", "", []DBColumn{
+		{Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true},
+		{Name: "user_id", Type: "bigint", FKeySchema: "public", FKeyTable: "users", FKeyCol: "id"},
+		{Name: "body", Type: "text"},
+	})
+}
+
+// lookups run on snapshots while tables are added and removed, run it
+// with -race
+func TestConcurrentUpdates(t *testing.T) {
+	load, _ := testLoader()
+	s, err := NewDBSchema(GetTestDBInfo(), nil, WithLazyTables(load))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*n)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				if _, err := s.FindPath("comments", "users", ""); err != nil {
+					errs <- err
+				}
+				if _, err := s.FindPath("purchases", "users", ""); err != nil {
+					errs <- err
+				}
+				ti, err := s.Find("", "products")
+				if err != nil {
+					errs <- err
+				} else if ti.Partial {
+					errs <- fmt.Errorf("products is partial")
+				}
+				// notes comes and goes
+				if _, err := s.FindPath("notes", "users", ""); err == nil {
+					_, _ = s.Find("", "notes")
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < n; j++ {
+			if err := s.AddTable(noteTable()); err != nil {
+				errs <- err
+				return
+			}
+			if err := s.RemoveTable("notes"); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if _, err := s.Find("", "notes"); err == nil {
+		t.Error("notes found after it was removed")
+	}
+}
+
+// a snapshot keeps the tables it loaded when a later snapshot removes
+// them
+func TestSnapshotLazyTables(t *testing.T) {
+	load, loads := testLoader()
+	s, err := NewDBSchema(GetTestDBInfo(), nil, WithLazyTables(load))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Find("", "products"); err != nil {
+		t.Fatal(err)
+	}
+	old := s.Snapshot()
+	before := loads.Load()
+
+	if err := s.RemoveTable("products"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Find("", "products"); err == nil {
+		t.Error("products found after it was removed")
+	}
+
+	ti, err := old.Find("", "products")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Partial {
+		t.Error("products of the old snapshot is partial")
+	}
+	if got := loads.Load(); got != before {
+		t.Errorf("products loaded again by the old snapshot: %d loads, want %d", got, before)
+	}
+}
+
+// a snapshot does not see the changes made after it was taken and
+// cannot be changed itself
+func TestSnapshot(t *testing.T) {
+	s := blogTestSchema(t, WithPathCache())
+	old := s.Snapshot()
+	if old.Snapshot() != old {
+		t.Error("got another snapshot of a snapshot")
+	}
+	if _, err := old.FindPath("comments", "users", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddTable(noteTable()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.Find("", "notes"); err == nil {
+		t.Error("notes found on the snapshot taken before it was added")
+	}
+	if _, err := s.Snapshot().Find("", "notes"); err != nil {
+		t.Error(err)
+	}
+	if old.Fingerprint() == s.Fingerprint() {
+		t.Error("got the fingerprint of the old snapshot")
+	}
+	if len(old.GetTables()) != len(s.GetTables())-1 {
+		t.Errorf("got %d tables on the old snapshot and %d now", len(old.GetTables()), len(s.GetTables()))
+	}
+
+	for name, err := range map[string]error{
+		"AddTable":        old.AddTable(noteTable()),
+		"AddRelationship": old.AddRelationship(VirtualRel{}),
+		"RemoveTable":     old.RemoveTable("comments"),
+	} {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: got %v, want ErrReadOnly", name, err)
+		}
+	}
+}
+
+// the lookups made on a snapshot agree with each other while tables are
+// added and removed, run it with -race
+func TestConcurrentSnapshots(t *testing.T) {
+	s := blogTestSchema(t, WithPathCache())
+
+	const n = 200
+	var wg sync.WaitGroup
+	errs := make(chan error, 4*n)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				snap := s.Snapshot()
+				_, findErr := snap.Find("", "notes")
+				_, pathErr := snap.FindPath("notes", "users", "")
+				if (findErr == nil) != (pathErr == nil) {
+					errs <- fmt.Errorf("find: %v, path: %v", findErr, pathErr)
+				}
+			}
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < n; j++ {
+			if err := s.AddTable(noteTable()); err != nil {
+				errs <- err
+				return
+			}
+			if err := s.RemoveTable("notes"); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// snowflakeTypes maps snowflake type names to the type names
+// used across the rest of the schema package
+var snowflakeTypes = map[string]string{
+	"integer":       "bigint",
+	"number":        "numeric",
+	"decimal":       "numeric",
+	"numeric":       "numeric",
+	"float":         "double precision",
+	"real":          "double precision",
+	"double":        "double precision",
+	"boolean":       "boolean",
+	"text":          "text",
+	"varchar":       "text",
+	"char":          "character",
+	"binary":        "bytea",
+	"date":          "date",
+	"time":          "time without time zone",
+	"timestamp_ntz": "timestamp without time zone",
+	"timestamp_ltz": "timestamp with time zone",
+	"timestamp_tz":  "timestamp with time zone",
+	"variant":       "json",
+	"object":        "json",
+	"array":         "json",
+	"geography":     "geography",
+	"geometry":      "geometry",
+}
+
+// snowflakeType returns the normalized name for a snowflake type
+func snowflakeType(t string) string {
+	name := strings.ToLower(t)
+	if i := strings.IndexByte(name, '('); i != -1 {
+		name = name[:i]
+	}
+	if v, ok := snowflakeTypes[name]; ok {
+		return v
+	}
+	return t
+}
+
+// discoverSnowflakeColumns returns the columns of a snowflake database,
+// the primary and foreign keys are not in information_schema so they
+// are read from the show commands and merged into the columns
+func discoverSnowflakeColumns(ctx context.Context, db Querier, blockList []string) ([]DBColumn, error) {
+	var rows concatRows
+	defer rows.Close()
+
+	for _, stmt := range []string{
+		snowflakeColumnsStmt,
+		snowflakePrimaryKeysStmt,
+		snowflakeForeignKeysStmt,
+	} {
+		r, err := db.Query(ctx, stmt)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching columns: %s", err)
+		}
+		rows = append(rows, r)
+	}
+
+	return scanColumns(&rows, "snowflake", blockList)
+}
+
+// concatRows reads the rows of several queries one after the other
+type concatRows []Rows
+
+func (r *concatRows) Next() bool {
+	for len(*r) != 0 {
+		if (*r)[0].Next() {
+			return true
+		}
+		if (*r)[0].Err() != nil {
+			return false
+		}
+		(*r)[0].Close()
+		*r = (*r)[1:]
+	}
+	return false
+}
+
+func (r *concatRows) Scan(dest ...interface{}) error {
+	return (*r)[0].Scan(dest...)
+}
+
+func (r *concatRows) Err() error {
+	if len(*r) != 0 {
+		return (*r)[0].Err()
+	}
+	return nil
+}
+
+func (r *concatRows) Close() error {
+	for _, rs := range *r {
+		rs.Close()
+	}
+	*r = nil
+	return nil
+}
@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetDBInfoSnowflake(t *testing.T) {
+	fk := columnRow("PUBLIC", "ORDERS", "CUSTOMER_ID", "", false, false, "PUBLIC", "CUSTOMERS", "ID")
+	fk[12], fk[13] = "CASCADE", "ORDERS_CUSTOMER_FK"
+
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		snowflakeInfo: {{8150, "PUBLIC", "SHOP"}},
+		snowflakeColumnsStmt: {
+			columnRow("PUBLIC", "CUSTOMERS", "ID", "INTEGER", true, false),
+			columnRow("PUBLIC", "CUSTOMERS", "PROFILE", "VARIANT", false, false),
+			columnRow("PUBLIC", "ORDERS", "ID", "INTEGER", true, false),
+			columnRow("PUBLIC", "ORDERS", "CUSTOMER_ID", "INTEGER", true, false),
+			columnRow("PUBLIC", "ORDERS", "TOTAL", "NUMBER(10,2)", false, false),
+		},
+		snowflakePrimaryKeysStmt: {
+			columnRow("PUBLIC", "CUSTOMERS", "ID", "", true, true),
+			columnRow("PUBLIC", "ORDERS", "ID", "", true, true),
+		},
+		snowflakeForeignKeysStmt: {fk},
+		snowflakeRowCountsStmt:   {{"PUBLIC", "ORDERS", int64(1200)}},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "snowflake", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{snowflakeColumnsStmt, snowflakePrimaryKeysStmt, snowflakeForeignKeysStmt} {
+		if !q.ran(stmt) {
+			t.Errorf("snowflake statement not run:\n%.60s", stmt)
+		}
+	}
+	if q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("postgres catalog statement run")
+	}
+	if di.Type != "snowflake" || di.Version != 8150 || di.Schema != "PUBLIC" {
+		t.Errorf("got database %s %d %s", di.Type, di.Version, di.Schema)
+	}
+
+	// the keys of the show commands are merged into the columns keeping
+	// their types
+	c, err := di.GetColumn("PUBLIC", "ORDERS", "CUSTOMER_ID")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Type != "bigint" || !c.NotNull || c.FKeyTable != "CUSTOMERS" || c.FKeyOnDelete != "CASCADE" || c.FKeyName != "ORDERS_CUSTOMER_FK" {
+		t.Errorf("got column %+v", c)
+	}
+	for col, want := range map[string]string{"ID": "bigint", "TOTAL": "numeric"} {
+		c, err := di.GetColumn("PUBLIC", "ORDERS", col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Type, want)
+		}
+	}
+	if c, _ := di.GetColumn("PUBLIC", "CUSTOMERS", "PROFILE"); c.Type != "json" {
+		t.Errorf("got type %s, want json", c.Type)
+	}
+	orders, err := di.GetTable("PUBLIC", "ORDERS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if orders.RowCount != 1200 {
+		t.Errorf("got row count %d", orders.RowCount)
+	}
+
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("ORDERS", "CUSTOMERS", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := joinString(path); got != "ORDERS.CUSTOMER_ID -> CUSTOMERS.ID" {
+		t.Errorf("got path %s", got)
+	}
+}
+
+func TestSnowflakeType(t *testing.T) {
+	for in, want := range map[string]string{
+		"INTEGER":           "bigint",
+		"NUMBER(38,2)":      "numeric",
+		"VARCHAR(16777216)": "text",
+		"TIMESTAMP_NTZ(9)":  "timestamp without time zone",
+		"TIMESTAMP_TZ":      "timestamp with time zone",
+		"ARRAY":             "json",
+		"VECTOR":            "VECTOR",
+	} {
+		if got := snowflakeType(in); got != want {
+			t.Errorf("snowflakeType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// SoftDelete marks a table whose rows are deleted by setting a column,
+// a timestamp like deleted_at or a boolean like is_deleted. The compiler
+// leaves those rows out of the selections and joins of the table unless
+// a selection asks for them with with_deleted. The column is looked for
+// by those two names when it is not set
+type SoftDelete struct {
+	Schema string
+	Table  string
+	Column string
+}
+
+// softDeleteCols are the columns looked for when a SoftDelete has none
+var softDeleteCols = []string{"deleted_at", "is_deleted"}
+
+// addSoftDeletes checks the columns of the soft deleted tables and
+// indexes them
+func (s *DBSchema) addSoftDeletes(sds []SoftDelete) error {
+	if len(sds) == 0 {
+		return nil
+	}
+	s.softDeletes = make(map[string]DBColumn, len(sds))
+
+	for _, sd := range sds {
+		if sd.Schema == "" {
+			sd.Schema = s.schema
+		}
+		t, err := s.find(sd.Schema, sd.Table)
+		if err != nil {
+			return fmt.Errorf("soft delete: %w", err)
+		}
+
+		cols := softDeleteCols
+		if sd.Column != "" {
+			cols = []string{sd.Column}
+		}
+
+		var col DBColumn
+		var found bool
+		for _, name := range cols {
+			if col, found = t.ColumnExists(name); found {
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("soft delete: column not found: %s.%s.%s", sd.Schema, sd.Table, cols[0])
+		}
+
+		switch logicalType(col) {
+		case TypeBoolean, TypeTime, TypeTimestamp, TypeDate:
+		default:
+			return fmt.Errorf("soft delete: column %s.%s must be a timestamp or a boolean", sd.Table, col.Name)
+		}
+		s.softDeletes[(sd.Schema + ":" + sd.Table)] = col
+	}
+	return nil
+}
+
+// SoftDelete returns the column marking the deleted rows of a table
+func (s *DBSchema) SoftDelete(t DBTable) (DBColumn, bool) {
+	s = s.current()
+	c, ok := s.softDeletes[t.Schema+":"+t.Name]
+	return c, ok
+}
+
+// SoftDeleteFilter returns the where expression leaving out the deleted
+// rows of a table, empty when the table is not soft deleted. A row is
+// deleted once its timestamp is set or its boolean is true
+func (s *DBSchema) SoftDeleteFilter(t DBTable) string {
+	c, ok := s.SoftDelete(t)
+	if !ok {
+		return ""
+	}
+	if logicalType(c) == TypeBoolean {
+		return fmt.Sprintf("{ or: [{ %s: { is_null: true } }, { %s: { eq: false } }] }", c.Name, c.Name)
+	}
+	return fmt.Sprintf("{ %s: { is_null: true } }", c.Name)
+}
+
+// logicalType returns the logical type of a column
+func logicalType(c DBColumn) LogicalType {
+	if c.Logical != "" {
+		return c.Logical
+	}
+	lt, _ := DefaultTypes.Lookup(c.Type)
+	return lt
+}
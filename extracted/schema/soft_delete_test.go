@@ -0,0 +1,50 @@
+package schema
+
+import "testing"
+
+func TestSoftDelete(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk", "is_deleted boolean").
+		Table("posts", "id pk", "deleted_at timestamptz", "removed_on date").
+		Table("tags", "id pk").
+		BuildSchema(WithSoftDeletes(
+			SoftDelete{Table: "users"},
+			SoftDelete{Table: "posts", Column: "removed_on"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		table, col, filter string
+	}{
+		{"users", "is_deleted", "{ or: [{ is_deleted: { is_null: true } }, { is_deleted: { eq: false } }] }"},
+		{"posts", "removed_on", "{ removed_on: { is_null: true } }"},
+		{"tags", "", ""},
+	}
+	for _, tt := range tests {
+		ti, err := s.Find("", tt.table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, ok := s.SoftDelete(ti)
+		if ok != (tt.col != "") || c.Name != tt.col {
+			t.Errorf("%s: got column %q, %v, want %q", tt.table, c.Name, ok, tt.col)
+		}
+		if f := s.SoftDeleteFilter(ti); f != tt.filter {
+			t.Errorf("%s: got filter %q, want %q", tt.table, f, tt.filter)
+		}
+	}
+}
+
+func TestSoftDeleteErrors(t *testing.T) {
+	b := NewTestSchema().Table("users", "id pk", "name text", "deleted_at timestamptz")
+	for _, sd := range []SoftDelete{
+		{Table: "people"},
+		{Table: "users", Column: "gone"},
+		{Table: "users", Column: "name"},
+	} {
+		if _, err := b.BuildSchema(WithSoftDeletes(sd)); err == nil {
+			t.Errorf("%+v: want an error", sd)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DBInfoSource is a catalog the information of a database is read from,
+// it can be a live database (see NewSQLSource) or any other service
+// describing one
+type DBInfoSource interface {
+	// DiscoverDatabase returns the type, version, default schema and
+	// name of the database
+	DiscoverDatabase(ctx context.Context) (DBDatabase, error)
+
+	// DiscoverTables returns the tables and views with their table level
+	// details, their columns come from DiscoverColumns. Tables that only
+	// have columns don't need to be returned and tables without columns
+	// are left out
+	DiscoverTables(ctx context.Context) ([]DBTable, error)
+
+	// DiscoverColumns returns the columns of every table
+	DiscoverColumns(ctx context.Context) ([]DBColumn, error)
+
+	// DiscoverRelationships returns the foreign keys between columns,
+	// they are added to the foreign keys the columns already have
+	DiscoverRelationships(ctx context.Context) ([]DBForeignKey, error)
+}
+
+// FunctionSource is implemented by the sources that can list the
+// functions of the database
+type FunctionSource interface {
+	DiscoverFunctions(ctx context.Context) ([]DBFunction, error)
+}
+
+// DBDatabase holds the details of a database
+type DBDatabase struct {
+	Type    string
+	Version int
+	Schema  string
+	Name    string
+}
+
+// DBForeignKey holds a foreign key column and the column it points to,
+// the columns of a composite key share the same name
+type DBForeignKey struct {
+	Name       string
+	Schema     string
+	Table      string
+	Column     string
+	FKeySchema string
+	FKeyTable  string
+	FKeyCol    string
+	OnDelete   string
+}
+
+// NewDBInfoFromSource returns the database schema information read
+// from a source, the source calls run concurrently. The block list is
+// applied to the tables, columns and functions of the source
+func NewDBInfoFromSource(ctx context.Context, src DBInfoSource, blockList []string) (*DBInfo, error) {
+	var db DBDatabase
+	var tables []DBTable
+	var cols []DBColumn
+	var fks []DBForeignKey
+	var funcs []DBFunction
+
+	g, gctx := errgroup.WithContext(ctx)
+
+	g.Go(func() (err error) { db, err = src.DiscoverDatabase(gctx); return })
+	g.Go(func() (err error) { tables, err = src.DiscoverTables(gctx); return })
+	g.Go(func() (err error) { cols, err = src.DiscoverColumns(gctx); return })
+	g.Go(func() (err error) { fks, err = src.DiscoverRelationships(gctx); return })
+
+	if fs, ok := src.(FunctionSource); ok {
+		g.Go(func() (err error) { funcs, err = fs.DiscoverFunctions(gctx); return })
+	}
+
+	if err := g.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if db.Schema == "" {
+		return nil, fmt.Errorf("source: database schema not set")
+	}
+
+	blocked := newMatcher(blockList)
+
+	// the columns are copied before the foreign keys are added so the
+	// ones returned by the source are not modified
+	cols = append([]DBColumn{}, cols...)
+	ci := make(map[string]int, len(cols))
+	for i := range cols {
+		c := &cols[i]
+		if c.Schema == "" {
+			c.Schema = db.Schema
+		}
+		c.ID = int32(i)
+		c.Blocked = c.Blocked || blocked.match(c.Name)
+		ci[(c.Schema + ":" + c.Table + ":" + c.Name)] = i
+	}
+
+	for _, fk := range fks {
+		if fk.Schema == "" {
+			fk.Schema = db.Schema
+		}
+		if fk.FKeySchema == "" {
+			fk.FKeySchema = fk.Schema
+		}
+		i, ok := ci[(fk.Schema + ":" + fk.Table + ":" + fk.Column)]
+		if !ok {
+			return nil, fmt.Errorf("source: foreign key column not found: %s.%s.%s",
+				fk.Schema, fk.Table, fk.Column)
+		}
+		c := &cols[i]
+		c.FKeySchema = fk.FKeySchema
+		c.FKeyTable = fk.FKeyTable
+		c.FKeyCol = fk.FKeyCol
+		c.FKeyOnDelete = fk.OnDelete
+		c.FKeyName = fk.Name
+		c.FKRecursive = (c.FKeySchema == c.Schema && c.FKeyTable == c.Table)
+	}
+
+	var ff []DBFunction
+	for _, f := range funcs {
+		if !blocked.match(f.Name) {
+			ff = append(ff, f)
+		}
+	}
+
+	di := NewDBInfo(db.Type, db.Version, db.Schema, db.Name, cols, ff, blockList)
+
+	for _, st := range tables {
+		if st.Schema == "" {
+			st.Schema = db.Schema
+		}
+		if t, err := di.GetTable(st.Schema, st.Name); err == nil {
+			sourceTable(t, st)
+		}
+	}
+	return di, nil
+}
+
+// NewDBSchemaFromSource reads the database schema information from a
+// source and builds the schema with it
+func NewDBSchemaFromSource(
+	ctx context.Context,
+	src DBInfoSource,
+	blockList []string,
+	aliases map[string][]string,
+	opts ...Option,
+) (*DBSchema, error) {
+	di, err := NewDBInfoFromSource(ctx, src, blockList)
+	if err != nil {
+		return nil, err
+	}
+	return NewDBSchema(di, aliases, append([]Option{withTraceContext(ctx)}, opts...)...)
+}
+
+// sourceTable sets the table level details of a source table on a table
+// built from its columns
+func sourceTable(t *DBTable, st DBTable) {
+	if st.Type != "" {
+		t.Type = st.Type
+	}
+	if st.Comment != "" {
+		t.Comment = st.Comment
+	}
+	t.Blocked = t.Blocked || st.Blocked
+	t.Definition = st.Definition
+	t.Materialized = st.Materialized
+	t.Populated = st.Populated
+	t.RefreshedAt = st.RefreshedAt
+	t.RowCount = st.RowCount
+	t.PartitionKey = st.PartitionKey
+	t.Indexes = st.Indexes
+	t.Checks = st.Checks
+	t.Partitions = st.Partitions
+	t.Foreign = st.Foreign
+	t.Distribution = st.Distribution
+}
+
+// SQLSource reads the information of a database from its catalog with
+// the queries GetDBInfo uses, GetDBInfo also reads the enums, checks,
+// generated columns, indexes, partitions and row counts
+type SQLSource struct {
+	db     Querier
+	dbType string
+}
+
+// NewSQLSource returns a source reading the catalog of a database
+func NewSQLSource(db *sql.DB, dbType string) *SQLSource {
+	return NewSQLSourceFrom(SQLQuerier(db), dbType)
+}
+
+// NewSQLSourceFrom returns a source reading the catalog with a Querier
+func NewSQLSourceFrom(db Querier, dbType string) *SQLSource {
+	return &SQLSource{db: db, dbType: dbType}
+}
+
+// DiscoverDatabase returns the details of the database
+func (s *SQLSource) DiscoverDatabase(ctx context.Context) (DBDatabase, error) {
+	var row Row
+
+	switch s.dbType {
+	case "mysql", "mariadb":
+		row = s.db.QueryRow(ctx, mysqlInfo)
+	case "sqlite":
+		row = s.db.QueryRow(ctx, sqliteInfo)
+	case "mssql":
+		row = s.db.QueryRow(ctx, mssqlInfo)
+	case "bigquery":
+		row = s.db.QueryRow(ctx, bigqueryInfo)
+	case "snowflake":
+		row = s.db.QueryRow(ctx, snowflakeInfo)
+	case "clickhouse":
+		row = s.db.QueryRow(ctx, clickhouseInfo)
+	case "duckdb":
+		row = s.db.QueryRow(ctx, duckdbInfo)
+	case "trino":
+		row = s.db.QueryRow(ctx, trinoInfo)
+	case "redshift":
+		row = s.db.QueryRow(ctx, redshiftInfo)
+	default:
+		row = s.db.QueryRow(ctx, postgresInfo)
+	}
+
+	d := DBDatabase{Type: s.dbType}
+	err := row.Scan(&d.Version, &d.Schema, &d.Name)
+	return d, err
+}
+
+// DiscoverTables returns the views, the foreign tables and the tables
+// with a comment
+func (s *SQLSource) DiscoverTables(ctx context.Context) ([]DBTable, error) {
+	views, err := DiscoverViews(ctx, s.db, s.dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	foreign, err := DiscoverForeignTables(ctx, s.db, s.dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := DiscoverComments(ctx, s.db, s.dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []DBTable
+	tm := make(map[string]int)
+
+	for _, v := range views {
+		tm[(v.Schema + ":" + v.Name)] = len(tables)
+		tables = append(tables, DBTable{
+			Schema:       v.Schema,
+			Name:         v.Name,
+			Type:         "view",
+			Definition:   v.Definition,
+			Materialized: v.Materialized,
+			Populated:    v.Populated,
+			RefreshedAt:  v.RefreshedAt,
+		})
+	}
+
+	for _, ft := range foreign {
+		tm[(ft.Schema + ":" + ft.Name)] = len(tables)
+		tables = append(tables, DBTable{
+			Schema:  ft.Schema,
+			Name:    ft.Name,
+			Type:    "foreign",
+			Foreign: ft,
+		})
+	}
+
+	for _, c := range comments {
+		if c.Column != "" {
+			continue
+		}
+		k := c.Schema + ":" + c.Table
+		if i, ok := tm[k]; ok {
+			tables[i].Comment = c.Comment
+			continue
+		}
+		tm[k] = len(tables)
+		tables = append(tables, DBTable{Schema: c.Schema, Name: c.Table, Comment: c.Comment})
+	}
+	return tables, nil
+}
+
+// DiscoverColumns returns the columns of the database, the foreign keys
+// are read along with them
+func (s *SQLSource) DiscoverColumns(ctx context.Context) ([]DBColumn, error) {
+	return DiscoverColumns(ctx, s.db, s.dbType, nil)
+}
+
+// DiscoverRelationships returns no foreign keys since the columns
+// already have them
+func (s *SQLSource) DiscoverRelationships(ctx context.Context) ([]DBForeignKey, error) {
+	return nil, nil
+}
+
+// DiscoverFunctions returns the functions of the database
+func (s *SQLSource) DiscoverFunctions(ctx context.Context) ([]DBFunction, error) {
+	return DiscoverFunctions(ctx, s.db, s.dbType, nil)
+}
@@ -0,0 +1,95 @@
+package schema
+
+import (
+	"context"
+	"testing"
+)
+
+// staticSource is a source describing a blog, a catalog service say
+type staticSource struct {
+	fks []DBForeignKey
+}
+
+func (s staticSource) DiscoverDatabase(ctx context.Context) (DBDatabase, error) {
+	return DBDatabase{Type: "postgres", Version: 140000, Schema: "public", Name: "blog"}, nil
+}
+
+func (s staticSource) DiscoverTables(ctx context.Context) ([]DBTable, error) {
+	return []DBTable{
+		{Name: "posts", Comment: "Blog posts", RowCount: 42},
+		{Name: "missing", Comment: "No columns"},
+	}, nil
+}
+
+func (s staticSource) DiscoverColumns(ctx context.Context) ([]DBColumn, error) {
+	return []DBColumn{
+		{Table: "users", Name: "id", Type: "bigint", PrimaryKey: true, NotNull: true},
+		{Table: "users", Name: "password", Type: "text"},
+		{Table: "posts", Name: "id", Type: "bigint", PrimaryKey: true, NotNull: true},
+		{Table: "posts", Name: "user_id", Type: "bigint", NotNull: true},
+	}, nil
+}
+
+func (s staticSource) DiscoverRelationships(ctx context.Context) ([]DBForeignKey, error) {
+	return s.fks, nil
+}
+
+func TestNewDBInfoFromSource(t *testing.T) {
+	src := staticSource{fks: []DBForeignKey{
+		{Name: "posts_user_fk", Table: "posts", Column: "user_id", FKeyTable: "users", FKeyCol: "id", OnDelete: "CASCADE"},
+	}}
+	di, err := NewDBInfoFromSource(context.Background(), src, []string{"password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.Type != "postgres" || di.Schema != "public" || len(di.Tables) != 2 {
+		t.Fatalf("got %s %s tables %v", di.Type, di.Schema, di.Tables)
+	}
+
+	tbl, _ := di.GetTable("public", "posts")
+	if tbl.Comment != "Blog posts" || tbl.RowCount != 42 {
+		t.Errorf("got table %s %d", tbl.Comment, tbl.RowCount)
+	}
+	c, _ := di.GetColumn("public", "posts", "user_id")
+	if c.FKeySchema != "public" || c.FKeyTable != "users" || c.FKeyOnDelete != "CASCADE" {
+		t.Errorf("got foreign key %s.%s on delete %s", c.FKeySchema, c.FKeyTable, c.FKeyOnDelete)
+	}
+	if c, _ := di.GetColumn("public", "users", "password"); !c.Blocked {
+		t.Error("blocked column not blocked")
+	}
+
+	s, err := NewDBSchemaFromSource(context.Background(), src, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("posts", "users", ""); err != nil {
+		t.Error(err)
+	}
+
+	src.fks = []DBForeignKey{{Table: "posts", Column: "missing", FKeyTable: "users", FKeyCol: "id"}}
+	if _, err := NewDBInfoFromSource(context.Background(), src, nil); err == nil {
+		t.Error("want an error for a foreign key of a missing column")
+	}
+}
+
+func TestSQLSource(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo: {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {
+			columnRow("shop", "users", "id", "bigint", true, true),
+			columnRow("shop", "orders", "id", "bigint", true, true),
+			columnRow("shop", "orders", "user_id", "bigint", false, false, "shop", "users", "id"),
+		},
+	}}
+
+	di, err := NewDBInfoFromSource(context.Background(), NewSQLSourceFrom(q, "mysql"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.Type != "mysql" || di.Version != 80022 || len(di.Tables) != 2 {
+		t.Fatalf("got %s %d tables %v", di.Type, di.Version, di.Tables)
+	}
+	if c, _ := di.GetColumn("shop", "orders", "user_id"); c.FKeyTable != "users" {
+		t.Errorf("got foreign key to %s", c.FKeyTable)
+	}
+}
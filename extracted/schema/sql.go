@@ -17,5 +17,224 @@ var postgresColumnsStmt string
 //go:embed sql/mysql_info.sql
 var mysqlInfo string
 
-//go:embed ../sql/mysql_columns.sql
-var mysqlColumnsStmt string
\ No newline at end of file
+//go:embed sql/mysql_columns.sql
+var mysqlColumnsStmt string
+
+//go:embed sql/sqlite_info.sql
+var sqliteInfo string
+
+//go:embed sql/sqlite_columns.sql
+var sqliteColumnsStmt string
+
+//go:embed sql/mssql_info.sql
+var mssqlInfo string
+
+//go:embed sql/mssql_columns.sql
+var mssqlColumnsStmt string
+
+//go:embed sql/mssql_functions.sql
+var mssqlFunctionsStmt string
+
+//go:embed sql/cockroach_columns.sql
+var cockroachColumnsStmt string
+
+//go:embed sql/cockroach_functions.sql
+var cockroachFunctionsStmt string
+
+//go:embed sql/postgres_ddl_notify.sql
+var postgresDDLNotifyStmt string
+
+//go:embed sql/postgres_views.sql
+var postgresViewsStmt string
+
+//go:embed sql/postgres_matviews.sql
+var postgresMatViewsStmt string
+
+//go:embed sql/postgres_function_returns.sql
+var postgresFunctionReturnsStmt string
+
+//go:embed sql/postgres_enums.sql
+var postgresEnumsStmt string
+
+//go:embed sql/mysql_enums.sql
+var mysqlEnumsStmt string
+
+//go:embed sql/postgres_comments.sql
+var postgresCommentsStmt string
+
+//go:embed sql/mysql_comments.sql
+var mysqlCommentsStmt string
+
+//go:embed sql/mssql_comments.sql
+var mssqlCommentsStmt string
+
+//go:embed sql/postgres_checks.sql
+var postgresChecksStmt string
+
+//go:embed sql/mysql_checks.sql
+var mysqlChecksStmt string
+
+//go:embed sql/mssql_checks.sql
+var mssqlChecksStmt string
+
+//go:embed sql/postgres_generated.sql
+var postgresGeneratedStmt string
+
+//go:embed sql/mysql_generated.sql
+var mysqlGeneratedStmt string
+
+//go:embed sql/mssql_generated.sql
+var mssqlGeneratedStmt string
+
+//go:embed sql/sqlite_generated.sql
+var sqliteGeneratedStmt string
+
+//go:embed sql/cockroach_generated.sql
+var cockroachGeneratedStmt string
+
+//go:embed sql/postgres_defaults.sql
+var postgresDefaultsStmt string
+
+//go:embed sql/mysql_defaults.sql
+var mysqlDefaultsStmt string
+
+//go:embed sql/mssql_defaults.sql
+var mssqlDefaultsStmt string
+
+//go:embed sql/sqlite_defaults.sql
+var sqliteDefaultsStmt string
+
+//go:embed sql/postgres_partitions.sql
+var postgresPartitionsStmt string
+
+//go:embed sql/postgres_foreign_tables.sql
+var postgresForeignTablesStmt string
+
+//go:embed sql/postgres_indexes.sql
+var postgresIndexesStmt string
+
+//go:embed sql/mysql_indexes.sql
+var mysqlIndexesStmt string
+
+//go:embed sql/mssql_indexes.sql
+var mssqlIndexesStmt string
+
+//go:embed sql/sqlite_indexes.sql
+var sqliteIndexesStmt string
+
+//go:embed sql/cockroach_indexes.sql
+var cockroachIndexesStmt string
+
+//go:embed sql/postgres_row_counts.sql
+var postgresRowCountsStmt string
+
+//go:embed sql/mysql_row_counts.sql
+var mysqlRowCountsStmt string
+
+//go:embed sql/mssql_row_counts.sql
+var mssqlRowCountsStmt string
+
+//go:embed sql/cockroach_row_counts.sql
+var cockroachRowCountsStmt string
+
+//go:embed sql/bigquery_info.sql
+var bigqueryInfo string
+
+//go:embed sql/bigquery_columns.sql
+var bigqueryColumnsStmt string
+
+//go:embed sql/bigquery_comments.sql
+var bigqueryCommentsStmt string
+
+//go:embed sql/bigquery_defaults.sql
+var bigqueryDefaultsStmt string
+
+//go:embed sql/snowflake_info.sql
+var snowflakeInfo string
+
+//go:embed sql/snowflake_columns.sql
+var snowflakeColumnsStmt string
+
+//go:embed sql/snowflake_primary_keys.sql
+var snowflakePrimaryKeysStmt string
+
+//go:embed sql/snowflake_foreign_keys.sql
+var snowflakeForeignKeysStmt string
+
+//go:embed sql/snowflake_comments.sql
+var snowflakeCommentsStmt string
+
+//go:embed sql/snowflake_defaults.sql
+var snowflakeDefaultsStmt string
+
+//go:embed sql/snowflake_row_counts.sql
+var snowflakeRowCountsStmt string
+
+//go:embed sql/clickhouse_info.sql
+var clickhouseInfo string
+
+//go:embed sql/clickhouse_columns.sql
+var clickhouseColumnsStmt string
+
+//go:embed sql/clickhouse_comments.sql
+var clickhouseCommentsStmt string
+
+//go:embed sql/clickhouse_defaults.sql
+var clickhouseDefaultsStmt string
+
+//go:embed sql/clickhouse_generated.sql
+var clickhouseGeneratedStmt string
+
+//go:embed sql/clickhouse_indexes.sql
+var clickhouseIndexesStmt string
+
+//go:embed sql/clickhouse_row_counts.sql
+var clickhouseRowCountsStmt string
+
+//go:embed sql/duckdb_info.sql
+var duckdbInfo string
+
+//go:embed sql/duckdb_columns.sql
+var duckdbColumnsStmt string
+
+//go:embed sql/duckdb_views.sql
+var duckdbViewsStmt string
+
+//go:embed sql/duckdb_comments.sql
+var duckdbCommentsStmt string
+
+//go:embed sql/duckdb_defaults.sql
+var duckdbDefaultsStmt string
+
+//go:embed sql/duckdb_checks.sql
+var duckdbChecksStmt string
+
+//go:embed sql/duckdb_row_counts.sql
+var duckdbRowCountsStmt string
+
+//go:embed sql/trino_info.sql
+var trinoInfo string
+
+//go:embed sql/trino_columns.sql
+var trinoColumnsStmt string
+
+//go:embed sql/trino_comments.sql
+var trinoCommentsStmt string
+
+//go:embed sql/redshift_info.sql
+var redshiftInfo string
+
+//go:embed sql/redshift_columns.sql
+var redshiftColumnsStmt string
+
+//go:embed sql/redshift_views.sql
+var redshiftViewsStmt string
+
+//go:embed sql/redshift_defaults.sql
+var redshiftDefaultsStmt string
+
+//go:embed sql/redshift_row_counts.sql
+var redshiftRowCountsStmt string
+
+//go:embed sql/redshift_distribution.sql
+var redshiftDistributionStmt string
@@ -0,0 +1,52 @@
+SELECT c.table_schema AS `schema`,
+	c.table_name AS `table`,
+	c.column_name AS `column`,
+	c.data_type AS `type`,
+	(c.is_nullable = 'NO') AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	STARTS_WITH(c.data_type, 'ARRAY<') AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM INFORMATION_SCHEMA.COLUMNS c
+	JOIN INFORMATION_SCHEMA.TABLES t ON t.table_schema = c.table_schema
+	AND t.table_name = c.table_name
+WHERE t.table_type IN ('BASE TABLE', 'VIEW', 'MATERIALIZED VIEW', 'SNAPSHOT')
+	AND c.table_schema NOT IN ('_graphjin')
+	AND c.is_hidden = 'NO'
+	AND c.is_system_defined = 'NO'
+UNION ALL
+SELECT k.table_schema AS `schema`,
+	k.table_name AS `table`,
+	k.column_name AS `column`,
+	c.data_type AS `type`,
+	(c.is_nullable = 'NO') AS not_null,
+	(tc.constraint_type = 'PRIMARY KEY') AS primary_key,
+	false AS unique_key,
+	STARTS_WITH(c.data_type, 'ARRAY<') AS is_array,
+	false AS full_text,
+	COALESCE(cu.table_schema, '') AS foreignkey_schema,
+	COALESCE(cu.table_name, '') AS foreignkey_table,
+	COALESCE(cu.column_name, '') AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	(
+		CASE
+			WHEN tc.constraint_type = 'FOREIGN KEY' THEN tc.constraint_name
+			ELSE ''
+		END
+	) AS foreignkey_name
+FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS tc
+	JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k ON k.constraint_schema = tc.constraint_schema
+	AND k.constraint_name = tc.constraint_name
+	JOIN INFORMATION_SCHEMA.COLUMNS c ON c.table_schema = k.table_schema
+	AND c.table_name = k.table_name
+	AND c.column_name = k.column_name
+	LEFT JOIN INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE cu ON tc.constraint_type = 'FOREIGN KEY'
+	AND cu.constraint_schema = tc.constraint_schema
+	AND cu.constraint_name = tc.constraint_name
+WHERE tc.constraint_type IN ('PRIMARY KEY', 'FOREIGN KEY')
+	AND k.table_schema NOT IN ('_graphjin');
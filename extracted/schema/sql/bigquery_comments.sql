@@ -0,0 +1,16 @@
+SELECT t.table_schema AS `schema`,
+	t.table_name AS `table`,
+	'' AS `column`,
+	COALESCE(JSON_VALUE(t.option_value), t.option_value) AS `comment`
+FROM INFORMATION_SCHEMA.TABLE_OPTIONS t
+WHERE t.option_name = 'description'
+	AND t.table_schema NOT IN ('_graphjin')
+UNION ALL
+SELECT c.table_schema AS `schema`,
+	c.table_name AS `table`,
+	c.column_name AS `column`,
+	c.description AS `comment`
+FROM INFORMATION_SCHEMA.COLUMN_FIELD_PATHS c
+WHERE c.field_path = c.column_name
+	AND c.description IS NOT NULL
+	AND c.table_schema NOT IN ('_graphjin');
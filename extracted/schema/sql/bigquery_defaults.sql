@@ -0,0 +1,10 @@
+SELECT c.table_schema AS `schema`,
+	c.table_name AS `table`,
+	c.column_name AS `column`,
+	c.column_default AS `default`,
+	'' AS `identity`,
+	'' AS `sequence`
+FROM INFORMATION_SCHEMA.COLUMNS c
+WHERE c.column_default IS NOT NULL
+	AND c.column_default != 'NULL'
+	AND c.table_schema NOT IN ('_graphjin');
@@ -0,0 +1,35 @@
+SELECT c.database AS "schema",
+	c.table AS "table",
+	c.name AS "column",
+	c.type AS "type",
+	NOT startsWith(
+		replaceOne(c.type, 'LowCardinality(', ''),
+		'Nullable('
+	) AS not_null,
+	(
+		c.is_in_primary_key = 1
+		AND k.key_columns = 1
+	) AS primary_key,
+	false AS unique_key,
+	startsWith(c.type, 'Array(') AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM system.columns c
+	JOIN (
+		SELECT database,
+			table,
+			countIf(is_in_primary_key = 1) AS key_columns
+		FROM system.columns
+		GROUP BY database,
+			table
+	) k ON k.database = c.database
+	AND k.table = c.table
+WHERE c.default_kind != 'EPHEMERAL'
+	AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema')
+ORDER BY c.database,
+	c.table,
+	c.position;
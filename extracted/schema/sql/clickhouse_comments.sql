@@ -0,0 +1,15 @@
+SELECT t.database AS "schema",
+	t.name AS "table",
+	'' AS "column",
+	t.comment AS "comment"
+FROM system.tables t
+WHERE t.comment != ''
+	AND t.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema')
+UNION ALL
+SELECT c.database AS "schema",
+	c.table AS "table",
+	c.name AS "column",
+	c.comment AS "comment"
+FROM system.columns c
+WHERE c.comment != ''
+	AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema');
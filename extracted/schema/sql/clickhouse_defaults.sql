@@ -0,0 +1,9 @@
+SELECT c.database AS "schema",
+	c.table AS "table",
+	c.name AS "column",
+	c.default_expression AS "default",
+	'' AS "identity",
+	'' AS "sequence"
+FROM system.columns c
+WHERE c.default_kind = 'DEFAULT'
+	AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema');
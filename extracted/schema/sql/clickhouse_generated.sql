@@ -0,0 +1,13 @@
+SELECT c.database AS "schema",
+	c.table AS "table",
+	c.name AS "column",
+	(
+		CASE
+			WHEN c.default_kind = 'MATERIALIZED' THEN 'stored'
+			ELSE 'virtual'
+		END
+	) AS "kind",
+	c.default_expression AS "expr"
+FROM system.columns c
+WHERE c.default_kind IN ('MATERIALIZED', 'ALIAS')
+	AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema');
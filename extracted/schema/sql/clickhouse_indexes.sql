@@ -0,0 +1,60 @@
+SELECT "schema",
+	"table",
+	"name",
+	"method",
+	is_unique,
+	is_primary,
+	is_constraint,
+	"predicate",
+	"column"
+FROM (
+		SELECT c.database AS "schema",
+			c.table AS "table",
+			'primary' AS "name",
+			lower(t.engine) AS "method",
+			false AS is_unique,
+			true AS is_primary,
+			false AS is_constraint,
+			'' AS "predicate",
+			c.name AS "column",
+			position(t.primary_key, c.name) AS ord
+		FROM system.columns c
+			JOIN system.tables t ON t.database = c.database
+			AND t.name = c.table
+		WHERE c.is_in_primary_key = 1
+			AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema')
+		UNION ALL
+		SELECT c.database AS "schema",
+			c.table AS "table",
+			'sorting_key' AS "name",
+			lower(t.engine) AS "method",
+			false AS is_unique,
+			false AS is_primary,
+			false AS is_constraint,
+			'' AS "predicate",
+			c.name AS "column",
+			position(t.sorting_key, c.name) AS ord
+		FROM system.columns c
+			JOIN system.tables t ON t.database = c.database
+			AND t.name = c.table
+		WHERE c.is_in_sorting_key = 1
+			AND t.sorting_key != t.primary_key
+			AND c.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema')
+		UNION ALL
+		SELECT i.database AS "schema",
+			i.table AS "table",
+			i.name AS "name",
+			i.type AS "method",
+			false AS is_unique,
+			false AS is_primary,
+			false AS is_constraint,
+			'' AS "predicate",
+			i.expr AS "column",
+			toUInt64(0) AS ord
+		FROM system.data_skipping_indices i
+		WHERE i.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema')
+	)
+ORDER BY "schema",
+	"table",
+	"name",
+	ord;
@@ -0,0 +1,3 @@
+SELECT toInt32(splitByChar('.', version()) [1]) * 10000 + toInt32(splitByChar('.', version()) [2]) * 100 AS db_version,
+	currentDatabase() AS db_schema,
+	currentDatabase() AS db_name;
@@ -0,0 +1,6 @@
+SELECT t.database AS "schema",
+	t.name AS "table",
+	toInt64(t.total_rows) AS "rows"
+FROM system.tables t
+WHERE t.total_rows IS NOT NULL
+	AND t.database NOT IN ('_graphjin', 'system', 'INFORMATION_SCHEMA', 'information_schema');
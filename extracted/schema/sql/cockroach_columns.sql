@@ -0,0 +1,91 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	(
+		CASE
+			WHEN col.data_type = 'ARRAY' THEN substr(col.udt_name, 2) || '[]'
+			ELSE col.data_type
+		END
+	) as "type",
+	(
+		CASE
+			WHEN col.is_nullable = 'NO' THEN true
+			ELSE false
+		END
+	) AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	(
+		CASE
+			WHEN col.data_type = 'ARRAY' THEN true
+			ELSE false
+		END
+	) AS is_array,
+	(
+		CASE
+			WHEN col.data_type = 'tsvector' THEN true
+			ELSE false
+		END
+	) AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM information_schema.columns col
+WHERE col.is_hidden = 'NO'
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'pg_catalog',
+		'pg_extension',
+		'crdb_internal'
+	)
+UNION ALL
+SELECT kcu.table_schema as "schema",
+	kcu.table_name as "table",
+	kcu.column_name as "column",
+	'' as "type",
+	false AS not_null,
+	(
+		CASE
+			WHEN tc.constraint_type = 'PRIMARY KEY' THEN true
+			ELSE false
+		END
+	) AS primary_key,
+	(
+		CASE
+			WHEN tc.constraint_type = 'UNIQUE' THEN true
+			ELSE false
+		END
+	) AS unique_key,
+	false AS is_array,
+	false AS full_text,
+	COALESCE(rkcu.table_schema, '') AS foreignkey_schema,
+	COALESCE(rkcu.table_name, '') AS foreignkey_table,
+	COALESCE(rkcu.column_name, '') AS foreignkey_column,
+	COALESCE(rc.delete_rule, '') AS foreignkey_on_delete,
+	COALESCE(rc.constraint_name, '') AS foreignkey_name
+FROM information_schema.key_column_usage kcu
+	JOIN information_schema.table_constraints tc ON tc.constraint_schema = kcu.constraint_schema
+	AND tc.table_name = kcu.table_name
+	AND tc.constraint_name = kcu.constraint_name
+	JOIN information_schema.columns col ON col.table_schema = kcu.table_schema
+	AND col.table_name = kcu.table_name
+	AND col.column_name = kcu.column_name
+	AND col.is_hidden = 'NO'
+	LEFT JOIN information_schema.referential_constraints rc ON tc.constraint_type = 'FOREIGN KEY'
+	AND rc.constraint_schema = kcu.constraint_schema
+	AND rc.table_name = kcu.table_name
+	AND rc.constraint_name = kcu.constraint_name
+	LEFT JOIN information_schema.key_column_usage rkcu ON rkcu.constraint_schema = rc.unique_constraint_schema
+	AND rkcu.table_name = rc.referenced_table_name
+	AND rkcu.constraint_name = rc.unique_constraint_name
+	AND rkcu.ordinal_position = kcu.position_in_unique_constraint
+WHERE kcu.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'pg_catalog',
+		'pg_extension',
+		'crdb_internal'
+	);
@@ -0,0 +1,16 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	'stored' as "kind",
+	COALESCE(col.generation_expression, '') as "expr"
+FROM information_schema.columns col
+WHERE col.is_generated = 'ALWAYS'
+	AND col.generation_expression != ''
+	AND col.is_hidden = 'NO'
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'pg_catalog',
+		'pg_extension',
+		'crdb_internal'
+	);
@@ -0,0 +1,45 @@
+SELECT stat.table_schema as "schema",
+	stat.table_name as "table",
+	stat.index_name as "name",
+	COALESCE(substring(pi.indexdef FROM 'USING ([a-z_]+)'), 'btree') as "method",
+	(
+		CASE
+			WHEN stat.non_unique = 'NO' THEN TRUE
+			ELSE FALSE
+		END
+	) as is_unique,
+	EXISTS (
+		SELECT 1
+		FROM information_schema.table_constraints tc
+		WHERE tc.table_schema = stat.table_schema
+			AND tc.table_name = stat.table_name
+			AND tc.constraint_name = stat.index_name
+			AND tc.constraint_type = 'PRIMARY KEY'
+	) as is_primary,
+	EXISTS (
+		SELECT 1
+		FROM information_schema.table_constraints tc
+		WHERE tc.table_schema = stat.table_schema
+			AND tc.table_name = stat.table_name
+			AND tc.constraint_name = stat.index_name
+			AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+	) as is_constraint,
+	COALESCE(substring(pi.indexdef FROM ' WHERE (.*)$'), '') as "predicate",
+	stat.column_name as "column"
+FROM information_schema.statistics stat
+	LEFT JOIN pg_catalog.pg_indexes pi ON pi.schemaname = stat.table_schema
+	AND pi.tablename = stat.table_name
+	AND pi.indexname = stat.index_name
+WHERE stat.storing = 'NO'
+	AND stat.implicit = 'NO'
+	AND stat.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'pg_catalog',
+		'pg_extension',
+		'crdb_internal'
+	)
+ORDER BY stat.table_schema,
+	stat.table_name,
+	stat.index_name,
+	stat.seq_in_index;
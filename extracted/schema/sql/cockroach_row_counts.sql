@@ -0,0 +1,17 @@
+SELECT it.table_schema as "schema",
+	it.table_name as "table",
+	COALESCE(s.estimated_row_count, 0)::INT8 as "rows"
+FROM information_schema.tables it
+	JOIN crdb_internal.tables t ON t.database_name = current_database()
+	AND t.schema_name = it.table_schema
+	AND t.name = it.table_name
+	AND t.drop_time IS NULL
+	LEFT JOIN crdb_internal.table_row_statistics s ON s.table_id = t.table_id
+WHERE it.table_type = 'BASE TABLE'
+	AND it.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'pg_catalog',
+		'pg_extension',
+		'crdb_internal'
+	);
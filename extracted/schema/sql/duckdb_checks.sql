@@ -0,0 +1,21 @@
+SELECT (
+		CASE
+			WHEN k.database_name = current_database() THEN k.schema_name
+			ELSE k.database_name
+		END
+	) AS "schema",
+	k.table_name AS "table",
+	k.constraint_name AS "name",
+	k.expression AS "expr",
+	UNNEST(k.constraint_column_names) AS "column"
+FROM duckdb_constraints() k
+WHERE k.constraint_type = 'CHECK'
+	AND (
+		k.database_name = current_database()
+		OR k.schema_name = 'main'
+	)
+	AND k.database_name NOT IN ('system', 'temp')
+	AND k.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY 1,
+	2,
+	3;
@@ -0,0 +1,84 @@
+SELECT (
+		CASE
+			WHEN c.database_name = current_database() THEN c.schema_name
+			ELSE c.database_name
+		END
+	) AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.data_type AS "type",
+	NOT c.is_nullable AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	ends_with(c.data_type, ']') AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM duckdb_columns() c
+WHERE NOT c.internal
+	AND (
+		c.database_name = current_database()
+		OR c.schema_name = 'main'
+	)
+	AND c.database_name NOT IN ('system', 'temp')
+	AND c.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+UNION ALL
+SELECT (
+		CASE
+			WHEN k.database_name = current_database() THEN k.schema_name
+			ELSE k.database_name
+		END
+	) AS "schema",
+	k.table_name AS "table",
+	k.column_name AS "column",
+	'' AS "type",
+	false AS not_null,
+	k.constraint_type = 'PRIMARY KEY' AS primary_key,
+	(
+		k.constraint_type = 'UNIQUE'
+		AND k.key_columns = 1
+	) AS unique_key,
+	false AS is_array,
+	false AS full_text,
+	(
+		CASE
+			WHEN k.constraint_type = 'FOREIGN KEY' THEN (
+				CASE
+			WHEN k.database_name = current_database() THEN k.schema_name
+			ELSE k.database_name
+		END
+			)
+			ELSE ''
+		END
+	) AS foreignkey_schema,
+	COALESCE(k.referenced_table, '') AS foreignkey_table,
+	COALESCE(k.referenced_column, '') AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	(
+		CASE
+			WHEN k.constraint_type = 'FOREIGN KEY' THEN k.constraint_name
+			ELSE ''
+		END
+	) AS foreignkey_name
+FROM (
+		SELECT database_name,
+			schema_name,
+			table_name,
+			constraint_type,
+			constraint_name,
+			referenced_table,
+			len(constraint_column_names) AS key_columns,
+			UNNEST(constraint_column_names) AS column_name,
+			UNNEST(referenced_column_names) AS referenced_column
+		FROM duckdb_constraints()
+		WHERE constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+	) k
+WHERE (
+		k.database_name = current_database()
+		OR k.schema_name = 'main'
+	)
+	AND k.database_name NOT IN ('system', 'temp')
+	AND k.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,36 @@
+SELECT (
+		CASE
+			WHEN t.database_name = current_database() THEN t.schema_name
+			ELSE t.database_name
+		END
+	) AS "schema",
+	t.table_name AS "table",
+	'' AS "column",
+	t.comment AS "comment"
+FROM duckdb_tables() t
+WHERE t.comment IS NOT NULL
+	AND (
+		t.database_name = current_database()
+		OR t.schema_name = 'main'
+	)
+	AND t.database_name NOT IN ('system', 'temp')
+	AND t.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+UNION ALL
+SELECT (
+		CASE
+			WHEN c.database_name = current_database() THEN c.schema_name
+			ELSE c.database_name
+		END
+	) AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.comment AS "comment"
+FROM duckdb_columns() c
+WHERE c.comment IS NOT NULL
+	AND NOT c.internal
+	AND (
+		c.database_name = current_database()
+		OR c.schema_name = 'main'
+	)
+	AND c.database_name NOT IN ('system', 'temp')
+	AND c.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,23 @@
+SELECT (
+		CASE
+			WHEN c.database_name = current_database() THEN c.schema_name
+			ELSE c.database_name
+		END
+	) AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.column_default AS "default",
+	'' AS "identity",
+	COALESCE(
+		regexp_extract(c.column_default, '^nextval\(''([^'']+)''\)', 1),
+		''
+	) AS "sequence"
+FROM duckdb_columns() c
+WHERE c.column_default IS NOT NULL
+	AND NOT c.internal
+	AND (
+		c.database_name = current_database()
+		OR c.schema_name = 'main'
+	)
+	AND c.database_name NOT IN ('system', 'temp')
+	AND c.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,5 @@
+SELECT CAST(
+		regexp_replace(version(), '[^0-9]', '', 'g') AS integer
+	) AS db_version,
+	current_schema() AS db_schema,
+	current_database() AS db_name;
@@ -0,0 +1,16 @@
+SELECT (
+		CASE
+			WHEN t.database_name = current_database() THEN t.schema_name
+			ELSE t.database_name
+		END
+	) AS "schema",
+	t.table_name AS "table",
+	CAST(t.estimated_size AS bigint) AS "rows"
+FROM duckdb_tables() t
+WHERE NOT t.internal
+	AND (
+		t.database_name = current_database()
+		OR t.schema_name = 'main'
+	)
+	AND t.database_name NOT IN ('system', 'temp')
+	AND t.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
@@ -0,0 +1,20 @@
+SELECT (
+		CASE
+			WHEN v.database_name = current_database() THEN v.schema_name
+			ELSE v.database_name
+		END
+	) AS "schema",
+	v.view_name AS "name",
+	COALESCE(v.sql, '') AS "definition",
+	'' AS "column",
+	'' AS "base_schema",
+	'' AS "base_table"
+FROM duckdb_views() v
+WHERE NOT v.internal
+	AND NOT v.temporary
+	AND (
+		v.database_name = current_database()
+		OR v.schema_name = 'main'
+	)
+	AND v.database_name NOT IN ('system', 'temp')
+	AND v.schema_name NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
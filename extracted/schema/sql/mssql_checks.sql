@@ -0,0 +1,15 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	cc.name as "name",
+	cc.definition as "expr",
+	COALESCE(c.name, '') as "column"
+FROM sys.check_constraints cc
+	JOIN sys.objects o ON o.object_id = cc.parent_object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	LEFT JOIN sys.columns c ON c.object_id = cc.parent_object_id
+	AND c.column_id = cc.parent_column_id
+WHERE o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+ORDER BY s.name,
+	o.name,
+	cc.name;
@@ -0,0 +1,93 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	c.name as "column",
+	ty.name as "type",
+	CAST(
+		(
+			CASE
+				WHEN c.is_nullable = 0 THEN 1
+				ELSE 0
+			END
+		) AS bit
+	) AS not_null,
+	CAST(0 AS bit) AS primary_key,
+	CAST(0 AS bit) AS unique_key,
+	CAST(0 AS bit) AS is_array,
+	CAST(
+		(
+			CASE
+				WHEN fic.column_id IS NOT NULL THEN 1
+				ELSE 0
+			END
+		) AS bit
+	) AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM sys.columns c
+	JOIN sys.objects o ON o.object_id = c.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	JOIN sys.types ty ON ty.user_type_id = c.user_type_id
+	LEFT JOIN sys.fulltext_index_columns fic ON fic.object_id = c.object_id
+	AND fic.column_id = c.column_id
+WHERE o.type IN ('U', 'V')
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+UNION ALL
+SELECT s.name as "schema",
+	o.name as "table",
+	c.name as "column",
+	'' as "type",
+	CAST(0 AS bit) AS not_null,
+	i.is_primary_key AS primary_key,
+	i.is_unique AS unique_key,
+	CAST(0 AS bit) AS is_array,
+	CAST(0 AS bit) AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM sys.indexes i
+	JOIN sys.index_columns ic ON ic.object_id = i.object_id
+	AND ic.index_id = i.index_id
+	JOIN sys.columns c ON c.object_id = ic.object_id
+	AND c.column_id = ic.column_id
+	JOIN sys.objects o ON o.object_id = i.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE (
+		i.is_primary_key = 1
+		OR i.is_unique = 1
+	)
+	AND ic.is_included_column = 0
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+UNION ALL
+SELECT s.name as "schema",
+	o.name as "table",
+	c.name as "column",
+	'' as "type",
+	CAST(0 AS bit) AS not_null,
+	CAST(0 AS bit) AS primary_key,
+	CAST(0 AS bit) AS unique_key,
+	CAST(0 AS bit) AS is_array,
+	CAST(0 AS bit) AS full_text,
+	rs.name AS foreignkey_schema,
+	ro.name AS foreignkey_table,
+	rc.name AS foreignkey_column,
+	REPLACE(fk.delete_referential_action_desc, '_', ' ') AS foreignkey_on_delete,
+	fk.name AS foreignkey_name
+FROM sys.foreign_key_columns fkc
+	JOIN sys.foreign_keys fk ON fk.object_id = fkc.constraint_object_id
+	JOIN sys.objects o ON o.object_id = fkc.parent_object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	JOIN sys.columns c ON c.object_id = fkc.parent_object_id
+	AND c.column_id = fkc.parent_column_id
+	JOIN sys.objects ro ON ro.object_id = fkc.referenced_object_id
+	JOIN sys.schemas rs ON rs.schema_id = ro.schema_id
+	JOIN sys.columns rc ON rc.object_id = fkc.referenced_object_id
+	AND rc.column_id = fkc.referenced_column_id
+WHERE o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA');
@@ -0,0 +1,14 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	COALESCE(c.name, '') as "column",
+	CAST(ep.value AS nvarchar(4000)) as "comment"
+FROM sys.extended_properties ep
+	JOIN sys.objects o ON o.object_id = ep.major_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	LEFT JOIN sys.columns c ON c.object_id = ep.major_id
+	AND c.column_id = ep.minor_id
+WHERE ep.class = 1
+	AND ep.name = 'MS_Description'
+	AND o.type IN ('U', 'V')
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA');
@@ -0,0 +1,22 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	c.name as "column",
+	COALESCE(dc.definition, '') as "default",
+	(
+		CASE
+			WHEN c.is_identity = 1 THEN 'always'
+			ELSE ''
+		END
+	) as "identity",
+	'' as "sequence"
+FROM sys.columns c
+	JOIN sys.objects o ON o.object_id = c.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	LEFT JOIN sys.default_constraints dc ON dc.object_id = c.default_object_id
+WHERE (
+		c.default_object_id != 0
+		OR c.is_identity = 1
+	)
+	AND o.type = 'U'
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA');
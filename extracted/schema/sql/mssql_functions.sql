@@ -0,0 +1,51 @@
+SELECT CAST(o.object_id AS nvarchar(20)) as func_id,
+	s.name as func_schema,
+	o.name as func_name,
+	(
+		CASE
+			WHEN o.type IN ('IF', 'TF') THEN 'record'
+			ELSE COALESCE(
+				(
+					SELECT rt.name
+					FROM sys.parameters rp
+						JOIN sys.types rt ON rt.user_type_id = rp.user_type_id
+					WHERE rp.object_id = o.object_id
+						AND rp.parameter_id = 0
+				),
+				''
+			)
+		END
+	) as data_type,
+	p.parameter_id as param_id,
+	REPLACE(p.name, '@', '') as param_name,
+	pt.name as param_type,
+	(
+		CASE
+			WHEN p.is_output = 1 THEN 'OUT'
+			ELSE 'IN'
+		END
+	) as param_kind
+FROM sys.objects o
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	JOIN sys.parameters p ON p.object_id = o.object_id
+	JOIN sys.types pt ON pt.user_type_id = p.user_type_id
+WHERE o.type IN ('FN', 'IF', 'TF')
+	AND o.is_ms_shipped = 0
+	AND p.parameter_id > 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+UNION ALL
+SELECT CAST(o.object_id AS nvarchar(20)) as func_id,
+	s.name as func_schema,
+	o.name as func_name,
+	'record' as data_type,
+	c.column_id as param_id,
+	c.name as param_name,
+	ct.name as param_type,
+	'OUT' as param_kind
+FROM sys.objects o
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	JOIN sys.columns c ON c.object_id = o.object_id
+	JOIN sys.types ct ON ct.user_type_id = c.user_type_id
+WHERE o.type IN ('IF', 'TF')
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA');
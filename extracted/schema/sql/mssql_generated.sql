@@ -0,0 +1,16 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	cc.name as "column",
+	(
+		CASE
+			WHEN cc.is_persisted = 1 THEN 'stored'
+			ELSE 'virtual'
+		END
+	) as "kind",
+	COALESCE(cc.definition, '') as "expr"
+FROM sys.computed_columns cc
+	JOIN sys.objects o ON o.object_id = cc.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE o.type = 'U'
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA');
@@ -0,0 +1,31 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	i.name as "name",
+	lower(i.type_desc) as "method",
+	i.is_unique as is_unique,
+	i.is_primary_key as is_primary,
+	CAST(
+		CASE
+			WHEN i.is_primary_key = 1
+			OR i.is_unique_constraint = 1 THEN 1
+			ELSE 0
+		END AS bit
+	) as is_constraint,
+	COALESCE(i.filter_definition, '') as "predicate",
+	c.name as "column"
+FROM sys.indexes i
+	JOIN sys.objects o ON o.object_id = i.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+	JOIN sys.index_columns ix ON ix.object_id = i.object_id
+	AND ix.index_id = i.index_id
+	AND ix.key_ordinal > 0
+	JOIN sys.columns c ON c.object_id = ix.object_id
+	AND c.column_id = ix.column_id
+WHERE o.type IN ('U', 'V')
+	AND o.is_ms_shipped = 0
+	AND i.name IS NOT NULL
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+ORDER BY s.name,
+	o.name,
+	i.name,
+	ix.key_ordinal;
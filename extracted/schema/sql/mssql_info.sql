@@ -0,0 +1,13 @@
+SELECT CAST(
+		PARSENAME(
+			CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128)),
+			4
+		) AS int
+	) * 10000 + CAST(
+		PARSENAME(
+			CAST(SERVERPROPERTY('ProductVersion') AS nvarchar(128)),
+			3
+		) AS int
+	) as db_version,
+	COALESCE(SCHEMA_NAME(), 'dbo') as db_schema,
+	COALESCE(DB_NAME(), '') as db_name;
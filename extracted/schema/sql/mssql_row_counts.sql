@@ -0,0 +1,12 @@
+SELECT s.name as "schema",
+	o.name as "table",
+	CAST(SUM(p.rows) AS bigint) as "rows"
+FROM sys.partitions p
+	JOIN sys.objects o ON o.object_id = p.object_id
+	JOIN sys.schemas s ON s.schema_id = o.schema_id
+WHERE p.index_id IN (0, 1)
+	AND o.type = 'U'
+	AND o.is_ms_shipped = 0
+	AND s.name NOT IN ('_graphjin', 'sys', 'INFORMATION_SCHEMA')
+GROUP BY s.name,
+	o.name;
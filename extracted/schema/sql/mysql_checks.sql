@@ -0,0 +1,19 @@
+SELECT tc.table_schema as "schema",
+	tc.table_name as "table",
+	cc.constraint_name as "name",
+	cc.check_clause as "expr",
+	'' as "column"
+FROM information_schema.check_constraints cc
+	JOIN information_schema.table_constraints tc ON tc.constraint_schema = cc.constraint_schema
+	AND tc.constraint_name = cc.constraint_name
+	AND tc.constraint_type = 'CHECK'
+WHERE tc.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	)
+ORDER BY tc.table_schema,
+	tc.table_name,
+	cc.constraint_name;
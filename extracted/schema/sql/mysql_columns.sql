@@ -4,7 +4,7 @@ SELECT col.table_schema as "schema",
 	col.data_type as "type",
 	(
 		CASE
-			WHEN col.is_nullable = 'YES' THEN TRUE
+			WHEN col.is_nullable = 'NO' THEN TRUE
 			ELSE FALSE
 		END
 	) AS not_null,
@@ -24,7 +24,9 @@ SELECT col.table_schema as "schema",
 	) AS full_text,
 	'' AS foreignkey_schema,
 	'' AS foreignkey_table,
-	'' AS foreignkey_column
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
 FROM information_schema.columns col
 	LEFT JOIN information_schema.statistics stat ON col.table_schema = stat.table_schema
 	AND col.table_name = stat.table_name
@@ -74,11 +76,25 @@ SELECT kcu.table_schema as "schema",
 			WHEN tc.constraint_type = 'FOREIGN KEY' THEN kcu.referenced_column_name
 			ELSE ''
 		END
-	) AS foreignkey_column
+	) AS foreignkey_column,
+	(
+		CASE
+			WHEN tc.constraint_type = 'FOREIGN KEY' THEN COALESCE(rc.delete_rule, '')
+			ELSE ''
+		END
+	) AS foreignkey_on_delete,
+	(
+		CASE
+			WHEN tc.constraint_type = 'FOREIGN KEY' THEN kcu.constraint_name
+			ELSE ''
+		END
+	) AS foreignkey_name
 FROM information_schema.key_column_usage kcu
 	JOIN information_schema.table_constraints tc ON kcu.table_schema = tc.table_schema
 	AND kcu.table_name = tc.table_name
 	AND kcu.constraint_name = tc.constraint_name
+	LEFT JOIN information_schema.referential_constraints rc ON kcu.constraint_schema = rc.constraint_schema
+	AND kcu.constraint_name = rc.constraint_name
 WHERE kcu.constraint_schema NOT IN (
 		'_graphjin',
 		'information_schema',
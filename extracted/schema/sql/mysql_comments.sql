@@ -0,0 +1,28 @@
+SELECT t.table_schema as "schema",
+	t.table_name as "table",
+	'' as "column",
+	t.table_comment as "comment"
+FROM information_schema.tables t
+WHERE t.table_comment != ''
+	AND t.table_type = 'BASE TABLE'
+	AND t.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	)
+UNION ALL
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	col.column_comment as "comment"
+FROM information_schema.columns col
+WHERE col.column_comment != ''
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	);
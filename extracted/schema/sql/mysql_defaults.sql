@@ -0,0 +1,24 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	COALESCE(col.column_default, '') as "default",
+	(
+		CASE
+			WHEN col.extra LIKE '%auto_increment%' THEN 'by default'
+			ELSE ''
+		END
+	) as "identity",
+	'' as "sequence"
+FROM information_schema.columns col
+WHERE (
+		col.column_default IS NOT NULL
+		OR col.extra LIKE '%auto_increment%'
+	)
+	AND COALESCE(col.generation_expression, '') = ''
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	);
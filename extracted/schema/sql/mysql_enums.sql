@@ -0,0 +1,16 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	col.column_type as "value"
+FROM information_schema.columns col
+WHERE col.data_type = 'enum'
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	)
+ORDER BY col.table_schema,
+	col.table_name,
+	col.column_name;
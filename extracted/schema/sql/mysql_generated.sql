@@ -0,0 +1,20 @@
+SELECT col.table_schema as "schema",
+	col.table_name as "table",
+	col.column_name as "column",
+	(
+		CASE
+			WHEN col.extra LIKE '%STORED GENERATED%' THEN 'stored'
+			ELSE 'virtual'
+		END
+	) as "kind",
+	COALESCE(col.generation_expression, '') as "expr"
+FROM information_schema.columns col
+WHERE col.extra LIKE '%GENERATED%'
+	AND col.extra NOT LIKE '%DEFAULT_GENERATED%'
+	AND col.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	);
@@ -0,0 +1,36 @@
+SELECT stat.table_schema as "schema",
+	stat.table_name as "table",
+	stat.index_name as "name",
+	lower(stat.index_type) as "method",
+	(
+		CASE
+			WHEN stat.non_unique = 0 THEN TRUE
+			ELSE FALSE
+		END
+	) as is_unique,
+	(
+		CASE
+			WHEN stat.index_name = 'PRIMARY' THEN TRUE
+			ELSE FALSE
+		END
+	) as is_primary,
+	(
+		CASE
+			WHEN stat.non_unique = 0 THEN TRUE
+			ELSE FALSE
+		END
+	) as is_constraint,
+	'' as "predicate",
+	COALESCE(stat.column_name, '') as "column"
+FROM information_schema.statistics stat
+WHERE stat.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	)
+ORDER BY stat.table_schema,
+	stat.table_name,
+	stat.index_name,
+	stat.seq_in_index;
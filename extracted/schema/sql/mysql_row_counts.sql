@@ -0,0 +1,12 @@
+SELECT t.table_schema as "schema",
+	t.table_name as "table",
+	COALESCE(t.table_rows, 0) as "rows"
+FROM information_schema.tables t
+WHERE t.table_type = 'BASE TABLE'
+	AND t.table_schema NOT IN (
+		'_graphjin',
+		'information_schema',
+		'performance_schema',
+		'mysql',
+		'sys'
+	);
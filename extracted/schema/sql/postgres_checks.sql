@@ -0,0 +1,16 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	co.conname as "name",
+	pg_get_constraintdef(co.oid) as "expr",
+	COALESCE(f.attname, '') as "column"
+FROM pg_constraint co
+	JOIN pg_class c ON c.oid = co.conrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attribute f ON f.attrelid = c.oid
+	AND f.attnum = ANY (co.conkey)
+WHERE co.contype = 'c'
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY n.nspname,
+	c.relname,
+	co.conname,
+	f.attnum;
@@ -55,14 +55,35 @@ SELECT n.nspname as "schema",
 	(
 		CASE
 			WHEN co.contype = ('f'::char) THEN (
-				SELECT f.attname
-				FROM pg_attribute f
-				WHERE f.attnum = co.confkey [1]
-					and f.attrelid = co.confrelid
+				SELECT rf.attname
+				FROM pg_attribute rf
+				WHERE rf.attnum = co.confkey [array_position(co.conkey, f.attnum)]
+					and rf.attrelid = co.confrelid
 			)
 			ELSE ''::text
 		END
-	) AS foreignkey_column
+	) AS foreignkey_column,
+	(
+		CASE
+			WHEN co.contype = ('f'::char) THEN (
+				CASE
+					co.confdeltype
+					WHEN 'c' THEN 'CASCADE'
+					WHEN 'n' THEN 'SET NULL'
+					WHEN 'd' THEN 'SET DEFAULT'
+					WHEN 'r' THEN 'RESTRICT'
+					ELSE 'NO ACTION'
+				END
+			)
+			ELSE ''::text
+		END
+	) AS foreignkey_on_delete,
+	(
+		CASE
+			WHEN co.contype = ('f'::char) THEN co.conname::text
+			ELSE ''::text
+		END
+	) AS foreignkey_name
 FROM pg_attribute f
 	JOIN pg_class c ON c.oid = f.attrelid
 	LEFT JOIN pg_attrdef d ON d.adrelid = c.oid
@@ -71,6 +92,7 @@ FROM pg_attribute f
 	LEFT JOIN pg_constraint co ON co.conrelid = c.oid
 	AND f.attnum = ANY (co.conkey)
 WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	AND c.relispartition = false
 	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
 	AND c.relname != 'schema_version'
 	AND f.attnum > 0
@@ -0,0 +1,17 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	COALESCE(f.attname, '') as "column",
+	d.description as "comment"
+FROM pg_description d
+	JOIN pg_class c ON c.oid = d.objoid
+	AND d.classoid = 'pg_class'::regclass
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attribute f ON f.attrelid = c.oid
+	AND f.attnum = d.objsubid
+	AND d.objsubid > 0
+WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND (
+		d.objsubid = 0
+		OR f.attisdropped = false
+	);
@@ -0,0 +1,12 @@
+CREATE SCHEMA IF NOT EXISTS _graphjin;
+
+CREATE OR REPLACE FUNCTION _graphjin.notify_ddl() RETURNS event_trigger LANGUAGE plpgsql AS $$
+BEGIN
+	PERFORM pg_notify('graphjin_ddl', tg_tag);
+END;
+$$;
+
+DROP EVENT TRIGGER IF EXISTS _graphjin_notify_ddl;
+
+CREATE EVENT TRIGGER _graphjin_notify_ddl ON ddl_command_end
+	EXECUTE PROCEDURE _graphjin.notify_ddl();
@@ -0,0 +1,35 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	f.attname as "column",
+	COALESCE(pg_get_expr(d.adbin, d.adrelid), '') as "default",
+	(
+		CASE
+			f.attidentity
+			WHEN 'a' THEN 'always'
+			WHEN 'd' THEN 'by default'
+			ELSE ''
+		END
+	) as "identity",
+	(
+		CASE
+			WHEN f.attidentity = '' THEN COALESCE(
+				pg_get_serial_sequence(format('%I.%I', n.nspname, c.relname), f.attname),
+				''
+			)
+			ELSE ''
+		END
+	) as "sequence"
+FROM pg_attribute f
+	JOIN pg_class c ON c.oid = f.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attrdef d ON d.adrelid = c.oid
+	AND d.adnum = f.attnum
+WHERE (
+		d.adbin IS NOT NULL
+		OR f.attidentity != ''
+	)
+	AND f.attgenerated = ''
+	AND c.relkind IN ('r', 'p', 'f')
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND f.attnum > 0
+	AND f.attisdropped = false;
@@ -0,0 +1,17 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	f.attname as "column",
+	e.enumlabel as "value"
+FROM pg_attribute f
+	JOIN pg_class c ON c.oid = f.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_type t ON t.oid = f.atttypid
+	JOIN pg_enum e ON e.enumtypid = COALESCE(NULLIF(t.typelem, 0), t.oid)
+WHERE c.relkind IN ('r', 'v', 'm', 'f', 'p')
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND f.attnum > 0
+	AND f.attisdropped = false
+ORDER BY n.nspname,
+	c.relname,
+	f.attname,
+	e.enumsortorder;
@@ -0,0 +1,20 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	s.srvname as server,
+	w.fdwname as wrapper,
+	COALESCE((
+		SELECT json_object_agg(split_part(o, '=', 1), substr(o, strpos(o, '=') + 1))
+		FROM unnest(ft.ftoptions) o
+	), '{}')::text as options,
+	COALESCE((
+		SELECT json_object_agg(split_part(o, '=', 1), substr(o, strpos(o, '=') + 1))
+		FROM unnest(s.srvoptions) o
+	), '{}')::text as server_options
+FROM pg_foreign_table ft
+	JOIN pg_class c ON c.oid = ft.ftrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_foreign_server s ON s.oid = ft.ftserver
+	JOIN pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+WHERE n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY n.nspname,
+	c.relname;
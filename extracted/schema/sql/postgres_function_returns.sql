@@ -0,0 +1,16 @@
+SELECT p.proname || '_' || p.oid as func_id,
+	p.proretset as returns_set,
+	COALESCE(tn.nspname, '') as return_schema,
+	COALESCE(tc.relname, '') as return_table
+FROM pg_proc p
+	JOIN pg_namespace n ON n.oid = p.pronamespace
+	LEFT JOIN pg_type t ON t.oid = p.prorettype
+	LEFT JOIN pg_class tc ON tc.oid = t.typrelid
+	AND tc.relkind IN ('r', 'v', 'm', 'f', 'p')
+	LEFT JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+WHERE p.prokind = 'f'
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND (
+		p.proretset
+		OR tc.oid IS NOT NULL
+	);
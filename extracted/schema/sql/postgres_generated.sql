@@ -0,0 +1,15 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	f.attname as "column",
+	'stored' as "kind",
+	COALESCE(pg_get_expr(d.adbin, d.adrelid), '') as "expr"
+FROM pg_attribute f
+	JOIN pg_class c ON c.oid = f.attrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attrdef d ON d.adrelid = c.oid
+	AND d.adnum = f.attnum
+WHERE f.attgenerated = 's'
+	AND c.relkind IN ('r', 'p', 'f')
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND f.attnum > 0
+	AND f.attisdropped = false;
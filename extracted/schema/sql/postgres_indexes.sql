@@ -0,0 +1,36 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	ic.relname as "name",
+	am.amname as "method",
+	i.indisunique as is_unique,
+	i.indisprimary as is_primary,
+	EXISTS (
+		SELECT 1
+		FROM pg_constraint con
+		WHERE con.conindid = i.indexrelid
+			AND con.conrelid = i.indrelid
+			AND con.contype IN ('p', 'u')
+	) as is_constraint,
+	COALESCE(pg_get_expr(i.indpred, i.indrelid), '') as "predicate",
+	(
+		CASE
+			WHEN k.attnum = 0 THEN pg_get_indexdef(i.indexrelid, k.ord::int, true)
+			ELSE f.attname::text
+		END
+	) as "column"
+FROM pg_index i
+	JOIN pg_class c ON c.oid = i.indrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_class ic ON ic.oid = i.indexrelid
+	JOIN pg_am am ON am.oid = ic.relam
+	CROSS JOIN LATERAL unnest(i.indkey::int2 []) WITH ORDINALITY k(attnum, ord)
+	LEFT JOIN pg_attribute f ON f.attrelid = c.oid
+	AND f.attnum = k.attnum
+WHERE c.relkind IN ('r', 'm', 'p')
+	AND c.relispartition = false
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+	AND k.ord <= i.indnkeyatts
+ORDER BY n.nspname,
+	c.relname,
+	ic.relname,
+	k.ord;
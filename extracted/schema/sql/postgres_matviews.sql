@@ -0,0 +1,15 @@
+SELECT n.nspname as "schema",
+	c.relname as "view",
+	COALESCE(pg_get_viewdef(c.oid), '') as "definition",
+	c.relispopulated as populated,
+	(
+		CASE
+			WHEN current_setting('track_commit_timestamp') = 'on' THEN pg_xact_commit_timestamp(c.xmin)
+		END
+	) as refreshed_at
+FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind = 'm'
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY n.nspname,
+	c.relname;
@@ -0,0 +1,17 @@
+SELECT pn.nspname as parent_schema,
+	p.relname as parent_table,
+	COALESCE(pg_get_partkeydef(p.oid), '') as partition_key,
+	cn.nspname as "schema",
+	c.relname as "table",
+	COALESCE(pg_get_expr(c.relpartbound, c.oid), '') as partition_bound
+FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	JOIN pg_namespace cn ON cn.oid = c.relnamespace
+	JOIN pg_class p ON p.oid = i.inhparent
+	JOIN pg_namespace pn ON pn.oid = p.relnamespace
+WHERE c.relispartition = true
+	AND pn.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY pn.nspname,
+	p.relname,
+	cn.nspname,
+	c.relname;
@@ -0,0 +1,21 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	(
+		CASE
+			WHEN c.relkind = 'p' THEN COALESCE(
+				(
+					SELECT sum(GREATEST(pc.reltuples, 0))
+					FROM pg_inherits i
+						JOIN pg_class pc ON pc.oid = i.inhrelid
+					WHERE i.inhparent = c.oid
+				),
+				0
+			)
+			ELSE GREATEST(c.reltuples, 0)
+		END
+	)::bigint as "rows"
+FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE c.relkind IN ('r', 'm', 'p', 'f')
+	AND c.relispartition = false
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog');
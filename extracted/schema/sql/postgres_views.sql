@@ -0,0 +1,22 @@
+SELECT v.table_schema as "schema",
+	v.table_name as "view",
+	COALESCE(v.view_definition, '') as "definition",
+	COALESCE(vcu.column_name, '') as "column",
+	COALESCE(vcu.table_schema, '') as base_schema,
+	COALESCE(vcu.table_name, '') as base_table
+FROM information_schema.views v
+	LEFT JOIN information_schema.view_column_usage vcu ON vcu.view_schema = v.table_schema
+	AND vcu.view_name = v.table_name
+	AND EXISTS (
+		SELECT 1
+		FROM information_schema.columns c
+		WHERE c.table_schema = v.table_schema
+			AND c.table_name = v.table_name
+			AND c.column_name = vcu.column_name
+	)
+WHERE v.table_schema NOT IN ('_graphjin', 'information_schema', 'pg_catalog')
+ORDER BY v.table_schema,
+	v.table_name,
+	vcu.column_name,
+	vcu.table_schema,
+	vcu.table_name;
@@ -0,0 +1,127 @@
+SELECT "schema",
+	"table",
+	"column",
+	"type",
+	not_null,
+	primary_key,
+	unique_key,
+	is_array,
+	full_text,
+	foreignkey_schema,
+	foreignkey_table,
+	foreignkey_column,
+	foreignkey_on_delete,
+	foreignkey_name
+FROM (
+		SELECT n.nspname as "schema",
+			c.relname as "table",
+			f.attname AS "column",
+			(
+				CASE
+					WHEN f.atttypid = 'super'::regtype THEN 'json'
+					ELSE pg_catalog.format_type(f.atttypid, f.atttypmod)
+				END
+			) AS "type",
+			f.attnotnull AS not_null,
+			COALESCE(co.contype = 'p', false) AS primary_key,
+			COALESCE(co.contype = 'u', false) AS unique_key,
+			false AS is_array,
+			false AS full_text,
+			(
+				CASE
+					WHEN co.contype = 'f' THEN (
+						SELECT rn.nspname
+						FROM pg_class rc
+							JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+						WHERE rc.oid = co.confrelid
+					)
+					ELSE ''
+				END
+			) AS foreignkey_schema,
+			(
+				CASE
+					WHEN co.contype = 'f' THEN (
+						SELECT relname
+						FROM pg_class
+						WHERE oid = co.confrelid
+					)
+					ELSE ''
+				END
+			) AS foreignkey_table,
+			(
+				CASE
+					WHEN co.contype = 'f' THEN (
+						SELECT rf.attname
+						FROM pg_attribute rf,
+							generate_series(1, array_upper(co.conkey, 1)) AS s(i)
+						WHERE co.conkey [s.i] = f.attnum
+							AND rf.attrelid = co.confrelid
+							AND rf.attnum = co.confkey [s.i]
+					)
+					ELSE ''
+				END
+			) AS foreignkey_column,
+			(
+				CASE
+					WHEN co.contype = 'f' THEN (
+						CASE
+							co.confdeltype
+							WHEN 'c' THEN 'CASCADE'
+							WHEN 'n' THEN 'SET NULL'
+							WHEN 'd' THEN 'SET DEFAULT'
+							WHEN 'r' THEN 'RESTRICT'
+							ELSE 'NO ACTION'
+						END
+					)
+					ELSE ''
+				END
+			) AS foreignkey_on_delete,
+			(
+				CASE
+					WHEN co.contype = 'f' THEN co.conname
+					ELSE ''
+				END
+			) AS foreignkey_name,
+			f.attnum AS ord
+		FROM pg_attribute f
+			JOIN pg_class c ON c.oid = f.attrelid
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_constraint co ON co.conrelid = c.oid
+			AND f.attnum = ANY (co.conkey)
+		WHERE c.relkind IN ('r', 'v')
+			AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv')
+			AND f.attnum > 0
+			AND f.attisdropped = false
+		UNION ALL
+		SELECT lb.view_schema AS "schema",
+			lb.view_name AS "table",
+			lb.col_name AS "column",
+			(
+				CASE
+					WHEN lb.col_type = 'super' THEN 'json'
+					ELSE lb.col_type
+				END
+			) AS "type",
+			false AS not_null,
+			false AS primary_key,
+			false AS unique_key,
+			false AS is_array,
+			false AS full_text,
+			'' AS foreignkey_schema,
+			'' AS foreignkey_table,
+			'' AS foreignkey_column,
+			'' AS foreignkey_on_delete,
+			'' AS foreignkey_name,
+			lb.col_num AS ord
+		FROM pg_get_late_binding_view_cols() lb(
+				view_schema name,
+				view_name name,
+				col_name name,
+				col_type varchar,
+				col_num int
+			)
+		WHERE lb.view_schema NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv')
+	) cols
+ORDER BY "schema",
+	"table",
+	ord;
@@ -0,0 +1,26 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	f.attname as "column",
+	(
+		CASE
+			WHEN d.adsrc LIKE '"identity"(%'
+			OR d.adsrc LIKE '"default_identity"(%' THEN ''
+			ELSE d.adsrc
+		END
+	) as "default",
+	(
+		CASE
+			WHEN d.adsrc LIKE '"identity"(%' THEN 'always'
+			WHEN d.adsrc LIKE '"default_identity"(%' THEN 'by default'
+			ELSE ''
+		END
+	) as "identity",
+	'' as "sequence"
+FROM pg_attrdef d
+	JOIN pg_class c ON c.oid = d.adrelid
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	JOIN pg_attribute f ON f.attrelid = d.adrelid
+	AND f.attnum = d.adnum
+WHERE c.relkind = 'r'
+	AND f.attisdropped = false
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv');
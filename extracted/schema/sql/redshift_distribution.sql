@@ -0,0 +1,31 @@
+SELECT n.nspname as "schema",
+	c.relname as "table",
+	(
+		CASE
+			c.reldiststyle
+			WHEN 0 THEN 'EVEN'
+			WHEN 1 THEN 'KEY'
+			WHEN 8 THEN 'ALL'
+			WHEN 10 THEN 'AUTO(ALL)'
+			WHEN 11 THEN 'AUTO(EVEN)'
+			WHEN 12 THEN 'AUTO(KEY)'
+			ELSE ''
+		END
+	) as "style",
+	COALESCE(f.attname, '') as "column",
+	COALESCE(f.attisdistkey, false) as is_distkey,
+	COALESCE(f.attsortkeyord, 0) as sortkey_ord
+FROM pg_class c
+	JOIN pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_attribute f ON f.attrelid = c.oid
+	AND f.attnum > 0
+	AND f.attisdropped = false
+	AND (
+		f.attisdistkey
+		OR f.attsortkeyord != 0
+	)
+WHERE c.relkind = 'r'
+	AND n.nspname NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv')
+ORDER BY n.nspname,
+	c.relname,
+	abs(COALESCE(f.attsortkeyord, 0));
@@ -0,0 +1,11 @@
+SELECT CAST(
+		COALESCE(
+			substring(
+				version()
+				FROM 'Redshift [0-9]+\.[0-9]+\.([0-9]+)'
+			),
+			'0'
+		) AS integer
+	) as db_version,
+	COALESCE(current_schema(), '') as db_schema,
+	COALESCE(current_database(), '') as db_name;
@@ -0,0 +1,5 @@
+SELECT t."schema" as "schema",
+	t."table" as "table",
+	CAST(t.tbl_rows AS bigint) as "rows"
+FROM svv_table_info t
+WHERE t."schema" NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv');
@@ -0,0 +1,8 @@
+SELECT v.schemaname as "schema",
+	v.viewname as "name",
+	COALESCE(v.definition, '') as "definition",
+	'' as "column",
+	'' as "base_schema",
+	'' as "base_table"
+FROM pg_views v
+WHERE v.schemaname NOT IN ('_graphjin', 'information_schema', 'pg_catalog', 'pg_internal', 'pg_automv');
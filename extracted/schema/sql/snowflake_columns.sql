@@ -0,0 +1,25 @@
+SELECT c.table_schema AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	(
+		CASE
+			WHEN c.data_type = 'NUMBER'
+			AND c.numeric_scale = 0 THEN 'INTEGER'
+			ELSE c.data_type
+		END
+	) AS "type",
+	(c.is_nullable = 'NO') AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	false AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM information_schema.columns c
+WHERE c.table_schema NOT IN ('_graphjin', 'INFORMATION_SCHEMA')
+ORDER BY c.table_schema,
+	c.table_name,
+	c.ordinal_position;
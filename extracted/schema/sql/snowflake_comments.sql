@@ -0,0 +1,15 @@
+SELECT t.table_schema AS "schema",
+	t.table_name AS "table",
+	'' AS "column",
+	t.comment AS "comment"
+FROM information_schema.tables t
+WHERE t.comment IS NOT NULL
+	AND t.table_schema NOT IN ('_graphjin', 'INFORMATION_SCHEMA')
+UNION ALL
+SELECT c.table_schema AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.comment AS "comment"
+FROM information_schema.columns c
+WHERE c.comment IS NOT NULL
+	AND c.table_schema NOT IN ('_graphjin', 'INFORMATION_SCHEMA');
@@ -0,0 +1,17 @@
+SELECT c.table_schema AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	COALESCE(c.column_default, '') AS "default",
+	(
+		CASE
+			WHEN c.is_identity = 'YES' THEN 'by default'
+			ELSE ''
+		END
+	) AS "identity",
+	'' AS "sequence"
+FROM information_schema.columns c
+WHERE (
+		c.column_default IS NOT NULL
+		OR c.is_identity = 'YES'
+	)
+	AND c.table_schema NOT IN ('_graphjin', 'INFORMATION_SCHEMA');
@@ -0,0 +1,18 @@
+SHOW IMPORTED KEYS IN DATABASE
+->> SELECT "fk_schema_name" AS "schema",
+	"fk_table_name" AS "table",
+	"fk_column_name" AS "column",
+	'' AS "type",
+	false AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	false AS is_array,
+	false AS full_text,
+	"pk_schema_name" AS foreignkey_schema,
+	"pk_table_name" AS foreignkey_table,
+	"pk_column_name" AS foreignkey_column,
+	COALESCE("delete_rule", '') AS foreignkey_on_delete,
+	COALESCE("fk_name", '') AS foreignkey_name
+FROM $1
+WHERE "fk_database_name" = "pk_database_name"
+	AND "fk_schema_name" NOT IN ('_graphjin', 'INFORMATION_SCHEMA');
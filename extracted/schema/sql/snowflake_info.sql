@@ -0,0 +1,5 @@
+SELECT CAST(
+		REPLACE(SPLIT_PART(CURRENT_VERSION(), ' ', 1), '.', '') AS integer
+	) AS db_version,
+	COALESCE(CURRENT_SCHEMA(), 'PUBLIC') AS db_schema,
+	CURRENT_DATABASE() AS db_name;
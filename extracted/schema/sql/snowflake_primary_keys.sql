@@ -0,0 +1,17 @@
+SHOW PRIMARY KEYS IN DATABASE
+->> SELECT "schema_name" AS "schema",
+	"table_name" AS "table",
+	"column_name" AS "column",
+	'' AS "type",
+	true AS not_null,
+	true AS primary_key,
+	false AS unique_key,
+	false AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM $1
+WHERE "schema_name" NOT IN ('_graphjin', 'INFORMATION_SCHEMA');
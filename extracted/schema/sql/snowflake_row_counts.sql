@@ -0,0 +1,7 @@
+SELECT t.table_schema AS "schema",
+	t.table_name AS "table",
+	CAST(t.row_count AS bigint) AS "rows"
+FROM information_schema.tables t
+WHERE t.row_count IS NOT NULL
+	AND t.table_type = 'BASE TABLE'
+	AND t.table_schema NOT IN ('_graphjin', 'INFORMATION_SCHEMA');
@@ -0,0 +1,107 @@
+SELECT 'main' as "schema",
+	m.name as "table",
+	p.name as "column",
+	(
+		CASE
+			WHEN p.hidden IN (2, 3) THEN lower(trim(replace(upper(p.type), 'GENERATED ALWAYS', '')))
+			ELSE lower(p.type)
+		END
+	) as "type",
+	(
+		CASE
+			WHEN p."notnull" != 0 THEN 1
+			ELSE 0
+		END
+	) AS not_null,
+	(
+		CASE
+			WHEN p.pk > 0 THEN 1
+			ELSE 0
+		END
+	) AS primary_key,
+	0 AS unique_key,
+	0 AS is_array,
+	0 AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM sqlite_master m
+	JOIN pragma_table_xinfo(m.name) p
+WHERE m.type IN ('table', 'view')
+	AND m.name NOT LIKE 'sqlite_%'
+	AND p.hidden != 1
+UNION ALL
+SELECT 'main' as "schema",
+	m.name as "table",
+	'rowid' as "column",
+	'integer' as "type",
+	1 AS not_null,
+	1 AS primary_key,
+	1 AS unique_key,
+	0 AS is_array,
+	0 AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM sqlite_master m
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%'
+	AND upper(m.sql) NOT LIKE '%WITHOUT ROWID%'
+	AND NOT EXISTS (
+		SELECT 1
+		FROM pragma_table_info(m.name) p
+		WHERE p.pk > 0
+	)
+UNION ALL
+SELECT 'main' as "schema",
+	m.name as "table",
+	ii.name as "column",
+	'' as "type",
+	0 AS not_null,
+	0 AS primary_key,
+	1 AS unique_key,
+	0 AS is_array,
+	0 AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM sqlite_master m
+	JOIN pragma_index_list(m.name) il
+	JOIN pragma_index_info(il.name) ii
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%'
+	AND il."unique" = 1
+	AND ii.name IS NOT NULL
+UNION ALL
+SELECT 'main' as "schema",
+	m.name as "table",
+	fk."from" as "column",
+	'' as "type",
+	0 AS not_null,
+	0 AS primary_key,
+	0 AS unique_key,
+	0 AS is_array,
+	0 AS full_text,
+	'main' AS foreignkey_schema,
+	fk."table" AS foreignkey_table,
+	COALESCE(
+		fk."to",
+		(
+			SELECT p.name
+			FROM pragma_table_info(fk."table") p
+			WHERE p.pk = fk.seq + 1
+		),
+		'rowid'
+	) AS foreignkey_column,
+	fk.on_delete AS foreignkey_on_delete,
+	'fk_' || m.name || '_' || fk.id AS foreignkey_name
+FROM sqlite_master m
+	JOIN pragma_foreign_key_list(m.name) fk
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%';
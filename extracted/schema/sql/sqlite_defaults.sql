@@ -0,0 +1,28 @@
+SELECT 'main' as "schema",
+	m.name as "table",
+	p.name as "column",
+	COALESCE(p.dflt_value, '') as "default",
+	(
+		CASE
+			WHEN p.pk = 1
+			AND lower(p.type) = 'integer'
+			AND (
+				SELECT count(*)
+				FROM pragma_table_info(m.name) k
+				WHERE k.pk > 0
+			) = 1 THEN 'by default'
+			ELSE ''
+		END
+	) as "identity",
+	'' as "sequence"
+FROM sqlite_master m
+	JOIN pragma_table_info(m.name) p
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%'
+	AND (
+		p.dflt_value IS NOT NULL
+		OR (
+			p.pk = 1
+			AND lower(p.type) = 'integer'
+		)
+	);
@@ -0,0 +1,15 @@
+SELECT 'main' as "schema",
+	m.name as "table",
+	p.name as "column",
+	(
+		CASE
+			WHEN p.hidden = 3 THEN 'stored'
+			ELSE 'virtual'
+		END
+	) as "kind",
+	'' as "expr"
+FROM sqlite_master m
+	JOIN pragma_table_xinfo(m.name) p
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%'
+	AND p.hidden IN (2, 3);
@@ -0,0 +1,36 @@
+SELECT 'main' as "schema",
+	m.name as "table",
+	il.name as "name",
+	'btree' as "method",
+	il."unique" as is_unique,
+	(
+		CASE
+			WHEN il.origin = 'pk' THEN 1
+			ELSE 0
+		END
+	) as is_primary,
+	(
+		CASE
+			WHEN il.origin IN ('pk', 'u') THEN 1
+			ELSE 0
+		END
+	) as is_constraint,
+	(
+		CASE
+			WHEN il.partial = 1 THEN trim(
+				substr(im.sql, instr(upper(im.sql), ' WHERE ') + 7)
+			)
+			ELSE ''
+		END
+	) as "predicate",
+	COALESCE(ii.name, '') as "column"
+FROM sqlite_master m
+	JOIN pragma_index_list(m.name) il
+	JOIN pragma_index_info(il.name) ii
+	LEFT JOIN sqlite_master im ON im.type = 'index'
+	AND im.name = il.name
+WHERE m.type = 'table'
+	AND m.name NOT LIKE 'sqlite_%'
+ORDER BY m.name,
+	il.name,
+	ii.seqno;
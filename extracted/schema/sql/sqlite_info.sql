@@ -0,0 +1,3 @@
+SELECT CAST(REPLACE(sqlite_version(), '.', '') AS integer) as db_version,
+	'main' as db_schema,
+	'main' as db_name;
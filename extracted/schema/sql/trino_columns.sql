@@ -0,0 +1,21 @@
+SELECT c.table_cat || ':' || c.table_schem AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.type_name AS "type",
+	(c.is_nullable = 'NO') AS not_null,
+	false AS primary_key,
+	false AS unique_key,
+	starts_with(c.type_name, 'array(') AS is_array,
+	false AS full_text,
+	'' AS foreignkey_schema,
+	'' AS foreignkey_table,
+	'' AS foreignkey_column,
+	'' AS foreignkey_on_delete,
+	'' AS foreignkey_name
+FROM system.jdbc.columns c
+WHERE c.table_cat != 'system'
+	AND c.table_schem NOT IN ('_graphjin', 'information_schema')
+ORDER BY c.table_cat,
+	c.table_schem,
+	c.table_name,
+	c.ordinal_position
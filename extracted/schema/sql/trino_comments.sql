@@ -0,0 +1,19 @@
+SELECT t.table_cat || ':' || t.table_schem AS "schema",
+	t.table_name AS "table",
+	'' AS "column",
+	t.remarks AS "comment"
+FROM system.jdbc.tables t
+WHERE t.remarks IS NOT NULL
+	AND t.remarks != ''
+	AND t.table_cat != 'system'
+	AND t.table_schem NOT IN ('_graphjin', 'information_schema')
+UNION ALL
+SELECT c.table_cat || ':' || c.table_schem AS "schema",
+	c.table_name AS "table",
+	c.column_name AS "column",
+	c.remarks AS "comment"
+FROM system.jdbc.columns c
+WHERE c.remarks IS NOT NULL
+	AND c.remarks != ''
+	AND c.table_cat != 'system'
+	AND c.table_schem NOT IN ('_graphjin', 'information_schema')
@@ -0,0 +1,3 @@
+SELECT CAST(regexp_extract(version(), '^[0-9]+') AS integer) AS db_version,
+	COALESCE(current_catalog || ':' || current_schema, '') AS db_schema,
+	COALESCE(current_catalog, '') AS db_name
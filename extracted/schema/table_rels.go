@@ -0,0 +1,165 @@
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// TableRel is a relationship from a table to a related table named so
+// it can be used as a field of the table
+type TableRel struct {
+	DBRel
+
+	// Name is unique among the relationships and columns of the table
+	Name string
+
+	// Many is true when the table has a list of the related rows
+	Many bool
+}
+
+// GetTableRels returns the relationships of a table sorted by name,
+// a relationship to a parent table is named after its foreign key
+// column (commenter_id -> commenter) and one to child tables after
+// the child table, names that clash are qualified with the table or
+// the column of the relationship
+func (s *DBSchema) GetTableRels(t DBTable) ([]TableRel, error) {
+	return s.current().tableRels(t)
+}
+
+// tableRels is GetTableRels on the snapshot it is called on
+func (s *DBSchema) tableRels(t DBTable) ([]TableRel, error) {
+	n, ok := s.tindex[(t.Schema + ":" + t.Name)]
+	if !ok {
+		return nil, fmt.Errorf("table not found: %s", t.String())
+	}
+
+	var edges []TEdge
+	for _, m := range s.relationshipGraph.Connections(n.nodeID) {
+		for _, ge := range s.relationshipGraph.GetEdges(n.nodeID, m) {
+			if ge.OppID == -1 {
+				continue
+			}
+			edges = append(edges, s.getEdge(ge.ID))
+		}
+	}
+
+	sort.SliceStable(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if fa, fb := isParentEdge(a), isParentEdge(b); fa != fb {
+			return fa
+		}
+		if a.RT.Name != b.RT.Name {
+			return a.RT.Name < b.RT.Name
+		}
+		return a.CName < b.CName
+	})
+
+	used := make(map[string]struct{}, len(t.Columns)+len(edges))
+	for _, c := range t.Columns {
+		used[c.Name] = struct{}{}
+	}
+
+	rels := make([]TableRel, 0, len(edges))
+	for _, e := range edges {
+		tr := TableRel{DBRel: edgeRel(e)}
+
+		var name, alt string
+		switch {
+		case e.Type == RelManyToMany:
+			name, alt = e.RT.Name, (e.RT.Name + "_via_" + e.Through.Ti.Name)
+			tr.Many = true
+		case isParentEdge(e):
+			name, alt = GetRelName(e.CName), (GetRelName(e.CName) + "_" + e.RT.Name)
+			tr.Many = e.L.Array
+		default:
+			name, alt = e.RT.Name, (e.RT.Name + "_by_" + e.CName)
+			tr.Many = (e.Type != RelRemote && !isUniqueFKey(e)) || e.R.Array
+		}
+
+		tr.Name = uniqueName(used, name, alt)
+		used[tr.Name] = struct{}{}
+		rels = append(rels, tr)
+	}
+
+	sort.Slice(rels, func(i, j int) bool { return rels[i].Name < rels[j].Name })
+	return rels, nil
+}
+
+// isParentEdge returns true if the edge goes from the table holding the
+// foreign key column to the table it references
+func isParentEdge(e TEdge) bool {
+	switch e.Type {
+	case RelManyToMany:
+		return false
+	case RelEmbedded, RelPolymorphic:
+		return true
+	case RelRemote:
+		return e.LT.Type == "remote"
+	}
+	return e.L.Name == e.CName
+}
+
+// ToMany returns true if a row of the left table of the relationship
+// joins a list of rows of the right table. A relationship to the parent
+// row joins one row unless the foreign key is an array, one to the
+// child rows joins a list unless the foreign key is unique on them
+func (rel DBRel) ToMany() bool {
+	l, r := rel.Left.Col, rel.Right.Col
+	switch rel.Type {
+	case RelManyToMany:
+		return true
+	case RelEmbedded, RelPolymorphic:
+		return l.Array
+	case RelRemote:
+		return l.Array || r.Array
+	}
+	if l.FKeyTable == rel.Right.Ti.Name && l.FKeyCol == r.Name {
+		return l.Array
+	}
+	if r.Array {
+		return true
+	}
+
+	cols := make([]string, 0, len(rel.Right.Cols))
+	for _, c := range rel.Right.Cols {
+		cols = append(cols, c.Name)
+	}
+	if len(cols) == 0 {
+		cols = append(cols, r.Name)
+	}
+	return !rel.Right.Ti.IsUnique(cols...)
+}
+
+// isUniqueFKey returns true if the foreign key columns of an edge to
+// the child table are unique on it so a parent row has one child row
+func isUniqueFKey(e TEdge) bool {
+	if e.R.Array {
+		return false
+	}
+	cols := make([]string, 0, len(e.RCs))
+	for _, c := range e.RCs {
+		cols = append(cols, c.Name)
+	}
+	if len(cols) == 0 {
+		cols = append(cols, e.R.Name)
+	}
+	return e.RT.IsUnique(cols...)
+}
+
+// uniqueName returns the first of the names that is not used, numbering
+// the alternative name when both are taken
+func uniqueName(used map[string]struct{}, name, alt string) string {
+	if _, ok := used[name]; !ok {
+		return name
+	}
+	if _, ok := used[alt]; !ok {
+		return alt
+	}
+	for i := 2; ; i++ {
+		v := alt + "_" + strconv.Itoa(i)
+		if _, ok := used[v]; !ok {
+			return v
+		}
+	}
+}
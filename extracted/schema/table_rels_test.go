@@ -0,0 +1,54 @@
+package schema
+
+import "testing"
+
+// the relationships of a table and the paths found to the same tables
+// agree on which side is a list
+func TestTableRelsToMany(t *testing.T) {
+	s, err := GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ti := range s.GetTables() {
+		rels, err := s.GetTableRels(ti)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range rels {
+			if r.Many != r.ToMany() {
+				t.Errorf("%s.%s: Many %v, ToMany %v", ti.Name, r.Name, r.Many, r.ToMany())
+			}
+		}
+	}
+}
+
+func TestToMany(t *testing.T) {
+	s, err := GetTestSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		from, to string
+		many     bool
+	}{
+		{"purchases", "customers", false},
+		{"customers", "purchases", true},
+		{"comments", "users", false},
+		{"users", "comments", true},
+		{"products", "tags", true},
+		{"tags", "products", true},
+	}
+	for _, tt := range tests {
+		path, err := s.FindPath(tt.from, tt.to, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(path) != 1 {
+			t.Fatalf("%s to %s: %d hops", tt.from, tt.to, len(path))
+		}
+		if got := PathToRel(path[0]).ToMany(); got != tt.many {
+			t.Errorf("%s to %s: ToMany %v, want %v", tt.from, tt.to, got, tt.many)
+		}
+	}
+}
@@ -1,12 +1,17 @@
 package schema
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"hash/fnv"
+	"log/slog"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -20,7 +25,6 @@ type DBInfo struct {
 	Tables    []DBTable
 	Functions []DBFunction
 	VTables   []VirtualTable `json:"-"`
-	colMap    map[string]int
 	tableMap  map[string]int
 	hash      int
 }
@@ -36,7 +40,19 @@ type DBTable struct {
 	SecondaryCol DBColumn
 	FullText     []DBColumn
 	Blocked      bool
+	Partial      bool // only the key columns are loaded, see WithLazyTables
 	Func         DBFunction
+	Definition   string
+	Materialized bool
+	Populated    bool
+	RefreshedAt  time.Time
+	Indexes      []DBIndex
+	Checks       []DBCheck
+	PartitionKey string
+	Partitions   []DBPartition
+	Foreign      DBForeignTable // server of a foreign table, Type is "foreign"
+	RowCount     int64
+	Distribution DBDistribution // distribution and sort keys eg. of redshift
 	colMap       map[string]int
 }
 
@@ -48,51 +64,160 @@ type VirtualTable struct {
 	FKeyColumn string
 }
 
-// GetDBInfo returns the database schema information
+// GetDBInfo returns the database schema information, the catalog
+// queries are cancelled when the context is done. Each catalog is read
+// with a single query and the queries run concurrently on a bounded
+// number of connections (see WithWorkers), the result is the same
+// whatever order they finish in. WithSchemas, WithTables,
+// WithIncludeTables and WithExcludeTables limit the tables discovered,
+// WithCache skips the catalog queries while a saved result is fresh
 func GetDBInfo(
+	ctx context.Context,
 	db *sql.DB,
 	dbType string,
 	blockList []string,
+	opts ...InfoOption,
 ) (*DBInfo, error) {
-	var dbVersion int
-	var dbSchema, dbName string
-	var cols []DBColumn
-	var funcs []DBFunction
+	return GetDBInfoFrom(ctx, SQLQuerier(db), dbType, blockList, opts...)
+}
 
-	g := errgroup.Group{}
+// GetDBInfoFrom is GetDBInfo reading the catalog with a Querier, such as
+// a pgx pool adapted by the pgxdb package
+func GetDBInfoFrom(
+	ctx context.Context,
+	db Querier,
+	dbType string,
+	blockList []string,
+	opts ...InfoOption,
+) (*DBInfo, error) {
+	io := infoOptions{workers: DefaultWorkers}
+	for _, fn := range opts {
+		fn(&io)
+	}
+	if err := io.checkPatterns(); err != nil {
+		return nil, err
+	}
 
-	g.Go(func() error {
-		var row *sql.Row
+	start := time.Now()
+	ctx, span := startSpan(ctx, io.tracer, "schema.GetDBInfo", attribute.String("db.system", dbType))
+	di, hit, err := io.dbInfo(ctx, db, dbType, blockList)
+	if di != nil {
+		span.SetAttributes(infoAttributes(di)...)
+	}
+	span.SetAttributes(attribute.Bool("schema.cache.hit", hit))
+	endSpan(span, err)
 
-		switch dbType {
-		case "mysql":
-			row = db.QueryRow(mysqlInfo)
-		default:
-			row = db.QueryRow(postgresInfo)
-		}
+	if io.metrics != nil {
+		io.metrics.Introspected(dbType, time.Since(start), hit, err)
+	}
+	return di, err
+}
 
-		if err := row.Scan(&dbVersion, &dbSchema, &dbName); err != nil {
-			return err
+// dbInfo reads the catalog for GetDBInfoFrom, or the saved DBInfo when
+// the cache has one and true
+func (o *infoOptions) dbInfo(
+	ctx context.Context,
+	db Querier,
+	dbType string,
+	blockList []string,
+) (*DBInfo, bool, error) {
+	var cachePath string
+	if o.cache != nil {
+		cachePath = o.cache.path(dbType, blockList, o)
+		if di, ok := o.cache.load(cachePath); ok {
+			if o.types != nil {
+				di.MapTypes(o.types)
+			}
+			if o.audit != nil {
+				di.MarkAuditColumns(*o.audit)
+			}
+			if o.sensitive != nil {
+				di.MarkSensitiveColumns(*o.sensitive)
+			}
+			o.log.debug("dbinfo read from cache", slog.String("path", cachePath), slog.Int("tables", len(di.Tables)))
+			return di, true, nil
 		}
-		return nil
-	})
+	}
 
-	g.Go(func() error {
-		var err error
-		if cols, err = DiscoverColumns(db, dbType, blockList); err != nil {
-			return err
-		}
+	var dbVersion int
+	var dbSchema, dbName string
+	var cols []DBColumn
+	var funcs []DBFunction
+	var views []DBView
+	var enums []DBEnum
+	var comments []DBComment
+	var checks []DBCheck
+	var gens []DBGenerated
+	var defs []DBDefault
+	var parts []DBPartition
+	var foreign []DBForeignTable
+	var indexes []DBIndex
+	var counts []DBRowCount
+	var dists []DBDistribution
+
+	g, gctx := errgroup.WithContext(ctx)
+	if o.workers > 0 {
+		g.SetLimit(o.workers)
+	}
 
-		if funcs, err = DiscoverFunctions(db, dbType, blockList); err != nil {
-			return err
-		}
-		return nil
-	})
+	// every task sets its own result so they can run in any order
+	tasks := []func() error{
+		func() error {
+			var row Row
+
+			switch dbType {
+			case "mysql", "mariadb":
+				row = db.QueryRow(gctx, mysqlInfo)
+			case "sqlite":
+				row = db.QueryRow(gctx, sqliteInfo)
+			case "mssql":
+				row = db.QueryRow(gctx, mssqlInfo)
+			case "bigquery":
+				row = db.QueryRow(gctx, bigqueryInfo)
+			case "snowflake":
+				row = db.QueryRow(gctx, snowflakeInfo)
+			case "clickhouse":
+				row = db.QueryRow(gctx, clickhouseInfo)
+			case "duckdb":
+				row = db.QueryRow(gctx, duckdbInfo)
+			case "trino":
+				row = db.QueryRow(gctx, trinoInfo)
+			case "redshift":
+				row = db.QueryRow(gctx, redshiftInfo)
+			default:
+				row = db.QueryRow(gctx, postgresInfo)
+			}
+			return row.Scan(&dbVersion, &dbSchema, &dbName)
+		},
+		func() (err error) { cols, err = DiscoverColumns(gctx, db, dbType, blockList); return },
+		func() (err error) { funcs, err = DiscoverFunctions(gctx, db, dbType, blockList); return },
+		func() (err error) { views, err = DiscoverViews(gctx, db, dbType); return },
+		func() (err error) { enums, err = DiscoverEnums(gctx, db, dbType); return },
+		func() (err error) { comments, err = DiscoverComments(gctx, db, dbType); return },
+		func() (err error) { checks, err = DiscoverChecks(gctx, db, dbType); return },
+		func() (err error) { gens, err = DiscoverGenerated(gctx, db, dbType); return },
+		func() (err error) { defs, err = DiscoverDefaults(gctx, db, dbType); return },
+		func() (err error) { parts, err = DiscoverPartitions(gctx, db, dbType); return },
+		func() (err error) { foreign, err = DiscoverForeignTables(gctx, db, dbType); return },
+		func() (err error) { indexes, err = DiscoverIndexes(gctx, db, dbType); return },
+		func() (err error) { counts, err = DiscoverRowCounts(gctx, db, dbType); return },
+		func() (err error) { dists, err = DiscoverDistribution(gctx, db, dbType); return },
+	}
+	for _, fn := range tasks {
+		g.Go(fn)
+	}
 
 	if err := g.Wait(); err != nil {
-		return nil, err
+		// the queries fail with the error of the driver when cancelled
+		if ctx.Err() != nil {
+			return nil, false, ctx.Err()
+		}
+		return nil, false, err
 	}
 
+	cols = o.hooks.columnHook(o.filterColumns(cols), o.log)
+	funcs = o.filterFunctions(funcs)
+
 	di := NewDBInfo(
 		dbType,
 		dbVersion,
@@ -102,7 +227,40 @@ func GetDBInfo(
 		funcs,
 		blockList)
 
-	return di, nil
+	di.addViews(views)
+	di.addForeignTables(foreign)
+	di.addEnums(enums)
+	di.addComments(comments)
+	di.addChecks(checks)
+	di.addGenerated(gens)
+	di.addDefaults(defs)
+	di.addPartitions(parts)
+	di.addIndexes(indexes)
+	di.addRowCounts(counts)
+	di.addDistribution(dists)
+
+	if o.types != nil {
+		di.MapTypes(o.types)
+	}
+	if o.audit != nil {
+		di.MarkAuditColumns(*o.audit)
+	}
+	if o.sensitive != nil {
+		di.MarkSensitiveColumns(*o.sensitive)
+	}
+	o.hooks.tableHook(di, o.log)
+
+	if o.log.enabled() {
+		for _, t := range di.Tables {
+			o.log.debug("table discovered", slog.String("table", t.String()), slog.String("type", t.Type),
+				slog.Int("columns", len(t.Columns)), slog.Bool("blocked", t.Blocked))
+		}
+	}
+
+	if o.cache != nil {
+		o.saveCache(cachePath, di)
+	}
+	return di, false, nil
 }
 
 // NewDBInfo returns a new DBInfo object
@@ -121,7 +279,6 @@ func NewDBInfo(
 		Schema:    dbSchema,
 		Name:      dbName,
 		Functions: funcs,
-		colMap:    make(map[string]int),
 		tableMap:  make(map[string]int),
 	}
 
@@ -130,55 +287,157 @@ func NewDBInfo(
 		table  string
 	}
 
-	tm := make(map[st][]DBColumn)
-	for i, c := range cols {
-		di.colMap[(c.Schema + ":" + c.Table + ":" + c.Name)] = i
+	// tables are added in the order of their first column so the
+	// same columns always give the same tables
+	var tables []st
+	counts := make(map[st]int)
+	grouped := true
+	in := make(interner)
+	for i := range cols {
+		in.column(&cols[i])
+		c := cols[i]
 
 		k := st{c.Schema, c.Table}
-		tm[k] = append(tm[k], c)
+		if _, ok := counts[k]; !ok {
+			tables = append(tables, k)
+		} else if tables[len(tables)-1] != k {
+			grouped = false
+		}
+		counts[k]++
+	}
+
+	// the columns of a table are slices of cols when they are listed
+	// together, capped so appending to them does not overwrite the next
+	tm := make(map[st][]DBColumn, len(tables))
+	if grouped {
+		i := 0
+		for _, k := range tables {
+			n := counts[k]
+			tm[k] = cols[i : i+n : i+n]
+			i += n
+		}
+	} else {
+		for _, c := range cols {
+			k := st{c.Schema, c.Table}
+			if tm[k] == nil {
+				tm[k] = make([]DBColumn, 0, counts[k])
+			}
+			tm[k] = append(tm[k], c)
+		}
 	}
 
-	for k, tcols := range tm {
+	blocked := newMatcher(blockList)
+	for _, k := range tables {
+		tcols := tm[k]
 		ti := NewDBTable(k.schema, k.table, "", tcols)
 		if strings.HasPrefix(ti.Name, "_gj_") {
 			continue
 		}
-		ti.Blocked = isInList(ti.Name, blockList)
+		for i := range ti.Columns {
+			setTemporal(&ti.Columns[i], dbType)
+		}
+		setTemporal(&ti.PrimaryCol, dbType)
+		ti.Blocked = blocked.match(ti.Name)
 		di.AddTable(ti)
 	}
 
-	for _, f := range funcs {
-		if f.Type != "record" || len(f.Outputs) == 0 {
+	for i := range funcs {
+		f := &funcs[i]
+		if f.Type != "record" {
 			continue
 		}
 
 		var cols []DBColumn
-		for _, v := range f.Outputs {
-			cols = append(cols, DBColumn{
-				ID:   int32(v.ID),
-				Name: v.Name,
-				Type: v.Type,
-			})
+		if len(f.Outputs) == 0 && f.ReturnTable != "" {
+			cols = di.returnTableCols(f)
+		} else {
+			for _, v := range f.Outputs {
+				cols = append(cols, DBColumn{
+					ID:   int32(v.ID),
+					Name: v.Name,
+					Type: v.Type,
+				})
+			}
 		}
+
+		if len(cols) == 0 {
+			continue
+		}
+		if _, ok := di.tableMap[(f.Schema + ":" + f.Name)]; ok {
+			continue
+		}
+
 		t := NewDBTable(f.Schema, f.Name, "function", cols)
-		t.Func = f
+		t.Func = *f
 		di.AddTable(t)
 	}
 
-	h := fnv.New128()
-	hv := fmt.Sprintf("%s%d%s%s", dbType, dbVersion, dbSchema, dbName)
+	// the columns of the tables so a DBInfo read from JSON has the hash
+	di.setHash(infoColumns(di))
+	return di
+}
+
+// setHash computes the hash of the DBInfo object
+func (di *DBInfo) setHash(cols []DBColumn) {
+	h := fnv.New64a()
+	hv := fmt.Sprintf("%s%d%s%s", di.Type, di.Version, di.Schema, di.Name)
 	h.Write([]byte(hv))
 
 	for _, c := range cols {
 		h.Write([]byte(c.String()))
 	}
 
-	for _, fn := range funcs {
+	for _, fn := range di.Functions {
 		h.Write([]byte(fn.String()))
 	}
 
-	di.hash = h.Size()
-	return di
+	di.hash = int(h.Sum64())
+}
+
+// returnTableCols returns the columns of a function that returns rows of
+// a table type and sets them as its outputs, the columns are related to
+// the table so the function results can be joined with it
+func (di *DBInfo) returnTableCols(f *DBFunction) []DBColumn {
+	rt, err := di.GetTable(f.ReturnSchema, f.ReturnTable)
+	if err != nil {
+		return nil
+	}
+
+	cols := make([]DBColumn, 0, len(rt.Columns))
+	for i, bc := range rt.Columns {
+		c := DBColumn{
+			ID:      int32(i + 1),
+			Name:    bc.Name,
+			Type:    bc.Type,
+			Array:   bc.Array,
+			NotNull: bc.NotNull,
+		}
+		inheritFKey(&c, bc)
+		cols = append(cols, c)
+
+		f.Outputs = append(f.Outputs, DBFuncParam{
+			ID:    int(c.ID),
+			Name:  c.Name,
+			Type:  c.Type,
+			Array: c.Array,
+		})
+	}
+	return cols
+}
+
+// inheritFKey copies the foreign key of a base column to a column derived
+// from it or makes it point to the base column when that is a key
+func inheritFKey(c *DBColumn, bc DBColumn) {
+	switch {
+	case bc.FKeyTable != "":
+		c.FKeySchema = bc.FKeySchema
+		c.FKeyTable = bc.FKeyTable
+		c.FKeyCol = bc.FKeyCol
+	case bc.PrimaryKey || bc.UniqueKey:
+		c.FKeySchema = bc.Schema
+		c.FKeyTable = bc.Table
+		c.FKeyCol = bc.Name
+	}
 }
 
 // NewDBTable returns a new DBTable object
@@ -191,10 +450,17 @@ func NewDBTable(schema, name, _type string, cols []DBColumn) DBTable {
 		colMap:  make(map[string]int, len(cols)),
 	}
 
-	for i, c := range cols {
+	for i := range cols {
 		cols[i].Schema = schema
 		cols[i].Table = name
+		if cols[i].Logical == "" {
+			cols[i].Logical, _ = DefaultTypes.Lookup(cols[i].Type)
+		}
+		setTemporal(&cols[i], "")
+		setArrayType(&cols[i])
+		setGeometry(&cols[i])
 
+		c := cols[i]
 		switch {
 		case c.FullText:
 			ti.FullText = append(ti.FullText, c)
@@ -208,12 +474,19 @@ func NewDBTable(schema, name, _type string, cols []DBColumn) DBTable {
 	return ti
 }
 
-// AddTable adds a table to the DBInfo object
-func (di *DBInfo) AddTable(t DBTable) {
-	for i, c := range t.Columns {
-		di.colMap[(c.Schema + ":" + c.Table + ":" + c.Name)] = i
+// setArrayType flags the columns with an array type eg. text[] as arrays
+// and sets the type of their elements
+func setArrayType(c *DBColumn) {
+	if strings.HasSuffix(c.Type, "[]") {
+		c.Array = true
 	}
+	if c.Array && c.ElemType == "" {
+		c.ElemType = strings.TrimSuffix(c.Type, "[]")
+	}
+}
 
+// AddTable adds a table to the DBInfo object
+func (di *DBInfo) AddTable(t DBTable) {
 	i := len(di.Tables)
 	di.Tables = append(di.Tables, t)
 	di.tableMap[(t.Schema + ":" + t.Name)] = i
@@ -246,42 +519,111 @@ func (di *DBInfo) GetTable(schema, table string) (*DBTable, error) {
 
 // DBColumn returns the column as a string
 type DBColumn struct {
-	Comment     string
-	ID          int32
-	Name        string
-	Type        string
-	Array       bool
-	NotNull     bool
-	PrimaryKey  bool
-	UniqueKey   bool
-	FullText    bool
-	FKRecursive bool
-	FKeySchema  string
-	FKeyTable   string
-	FKeyCol     string
-	Blocked     bool
-	Table       string
-	Schema      string
+	Comment      string
+	ID           int32
+	Name         string
+	Type         string
+	Logical      LogicalType // database independent type, see TypeMap
+	Array        bool
+	ElemType     string // type of the elements of an array column
+	TimeZone     bool   // a time or timestamp with a time zone
+	Precision    int    // digits of the fractional seconds of a time or timestamp
+	NotNull      bool
+	PrimaryKey   bool
+	UniqueKey    bool
+	FullText     bool
+	Enum         []string
+	Generated    string
+	GenExpr      string
+	Default      string // default expression eg. now()
+	Identity     string // "always" or "by default" for identity and auto increment columns
+	Sequence     string // sequence owned by a serial column
+	GeoType      string // PostGIS geometry type eg. Point
+	SRID         int    // spatial reference of a PostGIS column
+	FKRecursive  bool
+	FKeySchema   string
+	FKeyTable    string
+	FKeyCol      string
+	FKeyOnDelete string
+	FKeyName     string
+	BaseSchema   string
+	BaseTable    string
+	BaseCol      string
+	JSONCol      string   // json column of a JSON path pseudo-column
+	JSONPath     []string // keys from JSONCol to the value
+	Mask         Mask     // how the column is shown to a role
+	Audit        Audit    // what the column records of the writes, see WithAuditColumns
+	Sensitive    []string // tags of a column holding sensitive data eg. pii, see WithSensitiveColumns
+	Blocked      bool
+	Table        string
+	Schema       string
 }
 
 // DiscoverColumns returns the columns of a table
-func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn, error) {
-	var sqlStmt string
-
-	switch dbtype {
-	case "mysql":
-		sqlStmt = mysqlColumnsStmt
-	default:
-		sqlStmt = postgresColumnsStmt
+func DiscoverColumns(ctx context.Context, db Querier, dbtype string, blockList []string) ([]DBColumn, error) {
+	if dbtype == "snowflake" {
+		return discoverSnowflakeColumns(ctx, db, blockList)
 	}
 
-	rows, err := db.Query(sqlStmt)
+	rows, err := db.Query(ctx, columnsStmt(dbtype))
 	if err != nil {
 		return nil, fmt.Errorf("error fetching columns: %s", err)
 	}
 	defer rows.Close()
 
+	return scanColumns(rows, dbtype, blockList)
+}
+
+// columnsStmt returns the query reading the columns of a database
+func columnsStmt(dbtype string) string {
+	switch dbtype {
+	case "mysql", "mariadb":
+		return mysqlColumnsStmt
+	case "sqlite":
+		return sqliteColumnsStmt
+	case "mssql":
+		return mssqlColumnsStmt
+	case "bigquery":
+		// the dataset is the default dataset of the connection
+		return bigqueryColumnsStmt
+	case "snowflake":
+		// the keys are only listed by show commands, they are read
+		// by discoverSnowflakeColumns
+		return snowflakeColumnsStmt
+	case "clickhouse":
+		// a single column primary key of an engine is taken as the
+		// primary key of the table, there are no foreign keys
+		return clickhouseColumnsStmt
+	case "duckdb":
+		// the main schema of an attached database is named after the
+		// database so its tables are qualified with the database name
+		return duckdbColumnsStmt
+	case "trino":
+		// the columns of every catalog, the catalog is the namespace
+		// of the schema eg. "hive:sales"
+		return trinoColumnsStmt
+	case "redshift":
+		// redshift is postgres 8 without array_position or partitions
+		// and the columns of late binding views are not in pg_attribute
+		return redshiftColumnsStmt
+	case "cockroach", "cockroachdb":
+		// pg_catalog on cockroach includes hidden columns like rowid
+		// so we use information_schema where they can be filtered out
+		return cockroachColumnsStmt
+	default:
+		return postgresColumnsStmt
+	}
+}
+
+// scanColumns reads the rows of a columns query, a column can be
+// returned more than once (once per constraint) and the rows of a
+// column are merged
+func scanColumns(rows Rows, dbtype string, blockList []string) ([]DBColumn, error) {
+	var err error
+
 	cmap := make(map[string]DBColumn)
+	var keys []string
+	blocked := newMatcher(blockList)
 
 	i := 0
 	// we have to rescan and update columns to overcome
@@ -302,12 +644,33 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 			&c.FullText,
 			&c.FKeySchema,
 			&c.FKeyTable,
-			&c.FKeyCol)
+			&c.FKeyCol,
+			&c.FKeyOnDelete,
+			&c.FKeyName)
 
 		if err != nil {
 			return nil, err
 		}
 
+		if dbtype == "mssql" && c.Type != "" {
+			c.Type = mssqlType(c.Type)
+		}
+		if dbtype == "bigquery" && c.Type != "" {
+			c.Type = bigqueryType(c.Type)
+		}
+		if dbtype == "snowflake" && c.Type != "" {
+			c.Type = snowflakeType(c.Type)
+		}
+		if dbtype == "clickhouse" && c.Type != "" {
+			c.Type = clickhouseType(c.Type)
+		}
+		if dbtype == "duckdb" && c.Type != "" {
+			c.Type = duckdbType(c.Type)
+		}
+		if dbtype == "trino" && c.Type != "" {
+			c.Type = trinoType(c.Type)
+		}
+
 		k := (c.Schema + ":" + c.Table + ":" + c.Name)
 		v, ok := cmap[k]
 		if !ok {
@@ -316,7 +679,8 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 			if strings.HasPrefix(v.Table, "_gj_") {
 				continue
 			}
-			v.Blocked = isInList(v.Name, blockList)
+			v.Blocked = blocked.match(v.Name)
+			keys = append(keys, k)
 		}
 		if c.Type != "" {
 			v.Type = c.Type
@@ -346,6 +710,12 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 		if c.FKeyCol != "" {
 			v.FKeyCol = c.FKeyCol
 		}
+		if c.FKeyOnDelete != "" {
+			v.FKeyOnDelete = c.FKeyOnDelete
+		}
+		if c.FKeyName != "" {
+			v.FKeyName = c.FKeyName
+		}
 		if v.FKeySchema == v.Schema && v.FKeyTable == v.Table {
 			v.FKRecursive = true
 		}
@@ -353,11 +723,25 @@ func DiscoverColumns(db *sql.DB, dbtype string, blockList []string) ([]DBColumn,
 		i++
 	}
 
-	var cols []DBColumn
-	for _, c := range cmap {
-		cols = append(cols, c)
+	if err = rows.Err(); err != nil {
+		return nil, err
 	}
 
+	// columns are sorted by table keeping the order they were read in
+	// within a table, the ids are the same for the same columns
+	cols := make([]DBColumn, len(keys))
+	for j, k := range keys {
+		cols[j] = cmap[k]
+	}
+	sort.SliceStable(cols, func(a, b int) bool {
+		if cols[a].Schema != cols[b].Schema {
+			return cols[a].Schema < cols[b].Schema
+		}
+		return cols[a].Table < cols[b].Table
+	})
+	for j := range cols {
+		cols[j].ID = int32(j)
+	}
 	return cols, nil
 }
 
@@ -370,6 +754,14 @@ type DBFunction struct {
 	Agg     bool
 	Inputs  []DBFuncParam
 	Outputs []DBFuncParam
+
+	// ReturnsSet is true for functions that return a set of rows
+	ReturnsSet bool
+
+	// ReturnSchema and ReturnTable are set when the function returns
+	// rows of a table type
+	ReturnSchema string
+	ReturnTable  string
 }
 
 // DBFuncParam holds the database function parameter information
@@ -381,17 +773,41 @@ type DBFuncParam struct {
 }
 
 // DiscoverFunctions returns the functions of a database
-func DiscoverFunctions(db *sql.DB, dbtype string, blockList []string) ([]DBFunction, error) {
+func DiscoverFunctions(ctx context.Context, db Querier, dbtype string, blockList []string) ([]DBFunction, error) {
 	var sqlStmt string
+	var postgres bool
 
 	switch dbtype {
-	case "mysql":
+	case "mysql", "mariadb":
 		sqlStmt = mysqlFunctionsStmt
+	case "mssql":
+		sqlStmt = mssqlFunctionsStmt
+	case "cockroach", "cockroachdb":
+		sqlStmt = cockroachFunctionsStmt
+	case "sqlite":
+		// sqlite has no stored functions
+		return nil, nil
+	case "bigquery":
+		// routines of a dataset can not be called from a select list
+		return nil, nil
+	case "snowflake":
+		// the arguments of functions are only listed by show commands
+		return nil, nil
+	case "clickhouse", "duckdb":
+		// user defined functions are macros without typed arguments
+		return nil, nil
+	case "trino":
+		// the functions of a catalog are not listed by system.jdbc
+		return nil, nil
+	case "redshift":
+		// user defined functions are scalar python or sql functions
+		return nil, nil
 	default:
 		sqlStmt = postgresFunctionsStmt
+		postgres = true
 	}
 
-	rows, err := db.Query(sqlStmt)
+	rows, err := db.Query(ctx, sqlStmt)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching functions: %s", err)
 	}
@@ -399,6 +815,7 @@ func DiscoverFunctions(db *sql.DB, dbtype string, blockList []string) ([]DBFunct
 
 	var funcs []DBFunction
 	fm := make(map[string]int)
+	blocked := newMatcher(blockList)
 
 	for rows.Next() {
 		var fid, fs, fn, ft string
@@ -410,10 +827,15 @@ func DiscoverFunctions(db *sql.DB, dbtype string, blockList []string) ([]DBFunct
 			return nil, err
 		}
 
-		if isInList(fn, blockList) {
+		if blocked.match(fn) {
 			continue
 		}
 
+		if dbtype == "mssql" {
+			ft = mssqlType(ft)
+			pt = mssqlType(pt)
+		}
+
 		i, ok := fm[fid]
 		if !ok {
 			funcs = append(funcs, DBFunction{Schema: fs, Name: fn, Type: ft})
@@ -434,9 +856,48 @@ func DiscoverFunctions(db *sql.DB, dbtype string, blockList []string) ([]DBFunct
 		}
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if postgres {
+		if err = discoverFunctionReturns(ctx, db, funcs, fm); err != nil {
+			return nil, err
+		}
+	}
+
 	return funcs, nil
 }
 
+// discoverFunctionReturns sets the return set and table type info of
+// postgres functions
+func discoverFunctionReturns(ctx context.Context, db Querier, funcs []DBFunction, fm map[string]int) error {
+	rows, err := db.Query(ctx, postgresFunctionReturnsStmt)
+	if err != nil {
+		return fmt.Errorf("error fetching function returns: %s", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fid, rs, rt string
+		var set bool
+
+		if err = rows.Scan(&fid, &set, &rs, &rt); err != nil {
+			return err
+		}
+
+		i, ok := fm[fid]
+		if !ok {
+			continue
+		}
+		funcs[i].ReturnsSet = set
+		funcs[i].ReturnSchema = rs
+		funcs[i].ReturnTable = rt
+	}
+
+	return rows.Err()
+}
+
 // GetInput returns the input of a function
 func (fn *DBFunction) GetInput(name string) (ret DBFuncParam, err error) {
 	for _, in := range fn.Inputs {
@@ -454,9 +915,29 @@ func (di *DBInfo) Hash() int {
 
 // isInList checks if a value is in a list
 func isInList(val string, s []string) bool {
+	return newMatcher(s).match(val)
+}
+
+// matcher matches values against a list of patterns compiled once, it
+// is used when many values are checked against the same list
+type matcher []*regexp.Regexp
+
+// newMatcher compiles a list of patterns, the patterns that are not
+// valid regular expressions never match
+func newMatcher(s []string) matcher {
+	m := make(matcher, 0, len(s))
 	for _, v := range s {
-		regex := fmt.Sprintf("^%s$", v)
-		if matched, _ := regexp.MatchString(regex, val); matched {
+		if re, err := regexp.Compile(fmt.Sprintf("^%s$", v)); err == nil {
+			m = append(m, re)
+		}
+	}
+	return m
+}
+
+// match checks if a value matches any of the patterns
+func (m matcher) match(val string) bool {
+	for _, re := range m {
+		if re.MatchString(val) {
 			return true
 		}
 	}
@@ -0,0 +1,129 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingQuerier blocks every query until its context is done and
+// fails it with a driver error, the statement fail fails at once
+type blockingQuerier struct {
+	fail string
+}
+
+var errDriver = errors.New("driver: bad connection")
+
+func (q *blockingQuerier) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	if query == q.fail {
+		return nil, errDriver
+	}
+	<-ctx.Done()
+	return nil, errDriver
+}
+
+func (q *blockingQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) Row {
+	_, err := q.Query(ctx, query, args...)
+	return errRow{err}
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(dest ...interface{}) error { return r.err }
+
+func TestGetDBInfoCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := GetDBInfoFrom(ctx, &blockingQuerier{}, "postgres", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestGetDBInfoQueryError(t *testing.T) {
+	// a failed query stops the others
+	done := make(chan error, 1)
+	go func() {
+		_, err := GetDBInfoFrom(context.Background(), &blockingQuerier{fail: postgresColumnsStmt}, "postgres", nil)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), errDriver.Error()) {
+			t.Errorf("got error %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the other queries were not cancelled")
+	}
+}
+
+// countingQuerier keeps the most queries run at the same time
+type countingQuerier struct {
+	routeQuerier
+	mu     sync.Mutex
+	active int
+	most   int
+}
+
+func (q *countingQuerier) Query(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	q.mu.Lock()
+	q.active++
+	q.most = max(q.most, q.active)
+	q.mu.Unlock()
+
+	time.Sleep(2 * time.Millisecond)
+
+	q.mu.Lock()
+	q.active--
+	q.mu.Unlock()
+	return q.routeQuerier.Query(ctx, query, args...)
+}
+
+func TestGetDBInfoWorkers(t *testing.T) {
+	rows := map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "blog"}},
+		postgresColumnsStmt: {
+			columnRow("public", "users", "id", "bigint", true, true),
+			columnRow("public", "posts", "id", "bigint", true, true),
+			columnRow("public", "posts", "user_id", "bigint", true, false, "public", "users", "id"),
+		},
+		postgresEnumsStmt: {{"public", "posts", "id", "a"}},
+	}
+
+	var hash int
+	for _, n := range []int{1, 2, 0} {
+		q := &countingQuerier{routeQuerier: routeQuerier{rows: rows}}
+		di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil, WithWorkers(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 0 && q.most > n {
+			t.Errorf("%d workers: got %d queries at once", n, q.most)
+		}
+		if n == 0 && q.most <= DefaultWorkers {
+			t.Errorf("unlimited workers: got %d queries at once", q.most)
+		}
+
+		// the result does not depend on the order the queries finish in
+		if hash != 0 && di.Hash() != hash {
+			t.Errorf("%d workers: got another hash", n)
+		}
+		hash = di.Hash()
+		if c, _ := di.GetColumn("public", "posts", "id"); len(c.Enum) != 1 {
+			t.Errorf("%d workers: got enum %v", n, c.Enum)
+		}
+	}
+
+	q := &countingQuerier{routeQuerier: routeQuerier{rows: rows}}
+	if _, err := GetDBInfoFrom(context.Background(), q, "postgres", nil); err != nil {
+		t.Fatal(err)
+	}
+	if q.most > DefaultWorkers {
+		t.Errorf("default workers: got %d queries at once", q.most)
+	}
+}
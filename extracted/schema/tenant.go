@@ -0,0 +1,47 @@
+package schema
+
+import "fmt"
+
+// WithTenantTemplate builds the schema once for all the tenants of a
+// schema per tenant deployment. The tables of the template schema, eg.
+// the schema of one of the tenants, are the tables of every tenant and
+// the SQL of a tenant reads them from its own schema, see TenantSchema.
+// The tables of the other schemas are shared by the tenants
+func WithTenantTemplate(schema string) Option {
+	return func(o *schemaOptions) {
+		o.template = schema
+	}
+}
+
+// TenantTemplate returns the template schema of the tenants, it is empty
+// unless WithTenantTemplate is used
+func (s *DBSchema) TenantTemplate() string {
+	return s.tenantTemplate
+}
+
+// TenantSchema returns the schema a table of the schema is read from for
+// a tenant, the schema of the tenant for the template schema and the
+// schema itself for the shared ones
+//
+//	s.TenantSchema("tenant_template", "tenant_123") // "tenant_123"
+//	s.TenantSchema("public", "tenant_123")          // "public"
+func (s *DBSchema) TenantSchema(schema, tenant string) string {
+	if tenant != "" && s.tenantTemplate != "" && schema == s.tenantTemplate {
+		return tenant
+	}
+	return schema
+}
+
+// checkTenantTemplate returns an error when the template schema has no
+// tables
+func (s *DBSchema) checkTenantTemplate() error {
+	if s.tenantTemplate == "" {
+		return nil
+	}
+	for _, t := range s.tables {
+		if t.Schema == s.tenantTemplate {
+			return nil
+		}
+	}
+	return fmt.Errorf("tenant template: schema not found: %s", s.tenantTemplate)
+}
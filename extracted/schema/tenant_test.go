@@ -0,0 +1,74 @@
+package schema
+
+import (
+	"strings"
+	"testing"
+)
+
+// tenantSchema has users and posts of the tenants on plans shared by
+// all of them
+func tenantSchema(t *testing.T, opts ...Option) *DBSchema {
+	t.Helper()
+	s, err := NewTestSchema().
+		Table("plans", "id pk").
+		Table("tenant_template.users", "id pk", "plan_id notnull").
+		Table("tenant_template.posts", "id pk", "user_id notnull").
+		FK("tenant_template.users.plan_id", "plans.id").
+		FK("tenant_template.posts.user_id", "tenant_template.users.id").
+		BuildSchema(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTenantSchema(t *testing.T) {
+	s := tenantSchema(t, WithTenantTemplate("tenant_template"))
+	if s.TenantTemplate() != "tenant_template" {
+		t.Errorf("got template %q", s.TenantTemplate())
+	}
+	for _, tt := range []struct{ schema, tenant, want string }{
+		{"tenant_template", "tenant_123", "tenant_123"},
+		{"public", "tenant_123", "public"},
+		// without a tenant the template is read
+		{"tenant_template", "", "tenant_template"},
+	} {
+		if got := s.TenantSchema(tt.schema, tt.tenant); got != tt.want {
+			t.Errorf("TenantSchema(%q, %q) = %q, want %q", tt.schema, tt.tenant, got, tt.want)
+		}
+	}
+
+	// without a template every table is read from its own schema
+	if got := tenantSchema(t).TenantSchema("tenant_template", "tenant_123"); got != "tenant_template" {
+		t.Errorf("got %q without a template", got)
+	}
+
+	if _, err := NewTestSchema().Table("users", "id pk").BuildSchema(WithTenantTemplate("missing")); err == nil {
+		t.Error("want an error for a template schema without tables")
+	}
+}
+
+// the paths are shared by the tenants, their joins read the tables of
+// the tenant
+func TestTenantJoins(t *testing.T) {
+	s := tenantSchema(t, WithTenantTemplate("tenant_template"))
+	path, err := s.FindPath("posts", "plans", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sql, _, err := PathToSQL(path, JoinOptions{
+		Schema: func(sn string) string { return s.TenantSchema(sn, "tenant_123") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"tenant_123"."posts"`, `"tenant_123"."users"`, `"public"."plans"`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("no %s in:\n%s", want, sql)
+		}
+	}
+	if strings.Contains(sql, "tenant_template") {
+		t.Errorf("got the template schema in:\n%s", sql)
+	}
+}
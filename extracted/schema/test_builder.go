@@ -0,0 +1,207 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TestSchema builds the DBInfo of a made up database so tests can use
+// schemas of any shape without connecting to a database
+//
+//	di, err := schema.NewTestSchema().
+//		Table("users", "id pk", "email text notnull unique").
+//		Table("comments", "id pk", "user_id", "body").
+//		FK("comments.user_id", "users.id").
+//		Build()
+type TestSchema struct {
+	dbType  string
+	version int
+	name    string
+	schema  string
+	tables  []testTable
+	fks     [][2]string
+	vts     []VirtualTable
+	err     error
+}
+
+type testTable struct {
+	schema string
+	name   string
+	cols   []DBColumn
+}
+
+// NewTestSchema returns an empty test schema, tables without a schema
+// go in public
+func NewTestSchema() *TestSchema {
+	return &TestSchema{version: 110000, name: "db", schema: "public"}
+}
+
+// Table adds a table given as 'table' or 'schema.table'. A column is
+// given as 'name [type] [flags]', the flags are pk, unique, notnull,
+// array and fulltext. Columns named id or ending in _id are bigint
+// unless a type is given, the others are text
+func (ts *TestSchema) Table(name string, cols ...string) *TestSchema {
+	if ts.err != nil {
+		return ts
+	}
+
+	var dc []DBColumn
+	for _, spec := range cols {
+		c, err := testColumn(spec)
+		if err != nil {
+			ts.err = err
+			return ts
+		}
+		dc = append(dc, c)
+	}
+	ts.err = ts.addTable(name, dc)
+	return ts
+}
+
+// addTable adds a table given as 'table' or 'schema.table'
+func (ts *TestSchema) addTable(name string, cols []DBColumn) error {
+	schema, table := ts.schema, name
+	if i := strings.IndexByte(name, '.'); i != -1 {
+		schema, table = name[:i], name[i+1:]
+	}
+	if _, ok := ts.table(schema, table); ok {
+		return fmt.Errorf("test schema: duplicate table: %s.%s", schema, table)
+	}
+
+	for i := range cols {
+		cols[i].Schema, cols[i].Table = schema, table
+	}
+	ts.tables = append(ts.tables, testTable{schema: schema, name: table, cols: cols})
+	return nil
+}
+
+// FK adds a foreign key from a column to the column it references, both
+// given as 'table.column' or 'schema.table.column'. Foreign keys are
+// checked by Build so they can be added before their tables
+func (ts *TestSchema) FK(from, to string) *TestSchema {
+	ts.fks = append(ts.fks, [2]string{from, to})
+	return ts
+}
+
+// VirtualTable adds a virtual table for polymorphic relationships
+func (ts *TestSchema) VirtualTable(vt VirtualTable) *TestSchema {
+	ts.vts = append(ts.vts, vt)
+	return ts
+}
+
+// Build returns the DBInfo of the tables or the first error found
+// while adding them
+func (ts *TestSchema) Build() (*DBInfo, error) {
+	if ts.err != nil {
+		return nil, ts.err
+	}
+
+	for _, fk := range ts.fks {
+		if err := ts.addFK(fk[0], fk[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	var cols []DBColumn
+	for _, tt := range ts.tables {
+		cols = append(cols, tt.cols...)
+	}
+
+	di := NewDBInfo(ts.dbType, ts.version, ts.schema, ts.name, cols, nil, nil)
+	di.VTables = ts.vts
+	return di, nil
+}
+
+// BuildSchema returns the schema of the tables built with the options
+func (ts *TestSchema) BuildSchema(opts ...Option) (*DBSchema, error) {
+	di, err := ts.Build()
+	if err != nil {
+		return nil, err
+	}
+	return NewDBSchema(di, nil, opts...)
+}
+
+// addFK sets the foreign key of a column
+func (ts *TestSchema) addFK(from, to string) error {
+	c, err := ts.column(from)
+	if err != nil {
+		return err
+	}
+	fc, err := ts.column(to)
+	if err != nil {
+		return err
+	}
+	c.FKeySchema = fc.Schema
+	c.FKeyTable = fc.Table
+	c.FKeyCol = fc.Name
+	c.FKRecursive = (c.Schema == fc.Schema && c.Table == fc.Table)
+	return nil
+}
+
+// column returns a column given as 'table.column' or
+// 'schema.table.column'
+func (ts *TestSchema) column(name string) (*DBColumn, error) {
+	schema, table, column, err := splitColumnName(name)
+	if err != nil {
+		return nil, fmt.Errorf("test schema: %w", err)
+	}
+	if schema == "" {
+		schema = ts.schema
+	}
+
+	i, ok := ts.table(schema, table)
+	if !ok {
+		return nil, fmt.Errorf("test schema: table not found: %s.%s", schema, table)
+	}
+	tt := &ts.tables[i]
+	for j := range tt.cols {
+		if tt.cols[j].Name == column {
+			return &tt.cols[j], nil
+		}
+	}
+	return nil, fmt.Errorf("test schema: column not found: %s.%s", table, column)
+}
+
+// table returns the index of a table
+func (ts *TestSchema) table(schema, name string) (int, bool) {
+	for i, tt := range ts.tables {
+		if tt.schema == schema && tt.name == name {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// testColumn returns the column of a 'name [type] [flags]' spec
+func testColumn(spec string) (DBColumn, error) {
+	f := strings.Fields(spec)
+	if len(f) == 0 {
+		return DBColumn{}, fmt.Errorf("test schema: empty column")
+	}
+
+	c := DBColumn{Name: f[0], Type: "text"}
+	if c.Name == "id" || strings.HasSuffix(c.Name, "_id") {
+		c.Type = "bigint"
+	}
+
+	for i, v := range f[1:] {
+		switch v {
+		case "pk":
+			c.PrimaryKey, c.UniqueKey, c.NotNull = true, true, true
+		case "unique":
+			c.UniqueKey = true
+		case "notnull":
+			c.NotNull = true
+		case "array":
+			c.Array = true
+		case "fulltext":
+			c.FullText = true
+		default:
+			if i != 0 {
+				return c, fmt.Errorf("test schema: unknown flag %s in column: %s", v, spec)
+			}
+			c.Type = v
+		}
+	}
+	return c, nil
+}
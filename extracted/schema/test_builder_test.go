@@ -0,0 +1,77 @@
+package schema
+
+import "testing"
+
+func TestTestSchema(t *testing.T) {
+	di, err := NewTestSchema().
+		FK("comments.user_id", "users.id").
+		Table("users", "id pk", "email text notnull unique", "tags text[] array").
+		Table("comments", "id pk", "user_id", "body fulltext", "parent_id").
+		Table("billing.invoices", "id pk", "user_id notnull", "total numeric").
+		FK("comments.parent_id", "comments.id").
+		FK("billing.invoices.user_id", "public.users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.Schema != "public" || len(di.Tables) != 3 {
+		t.Fatalf("got schema %s tables %v", di.Schema, di.Tables)
+	}
+
+	for _, tt := range []struct {
+		schema, table, col string
+		want               DBColumn
+	}{
+		{"public", "users", "id", DBColumn{Type: "bigint", PrimaryKey: true, UniqueKey: true, NotNull: true}},
+		{"public", "users", "email", DBColumn{Type: "text", UniqueKey: true, NotNull: true}},
+		{"public", "users", "tags", DBColumn{Type: "text[]", Array: true}},
+		{"public", "comments", "user_id", DBColumn{Type: "bigint", FKeySchema: "public", FKeyTable: "users", FKeyCol: "id"}},
+		{"public", "comments", "parent_id", DBColumn{Type: "bigint", FKeySchema: "public", FKeyTable: "comments", FKeyCol: "id", FKRecursive: true}},
+		{"billing", "invoices", "user_id", DBColumn{Type: "bigint", NotNull: true, FKeySchema: "public", FKeyTable: "users", FKeyCol: "id"}},
+	} {
+		c, err := di.GetColumn(tt.schema, tt.table, tt.col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := tt.want
+		if c.Type != w.Type || c.PrimaryKey != w.PrimaryKey || c.UniqueKey != w.UniqueKey ||
+			c.NotNull != w.NotNull || c.Array != w.Array || c.FKeySchema != w.FKeySchema ||
+			c.FKeyTable != w.FKeyTable || c.FKeyCol != w.FKeyCol || c.FKRecursive != w.FKRecursive {
+			t.Errorf("%s.%s: got %+v", tt.table, tt.col, c)
+		}
+	}
+
+	ti, err := di.GetTable("public", "comments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ti.FullText) != 1 || ti.FullText[0].Name != "body" {
+		t.Errorf("got full text columns %v", ti.FullText)
+	}
+
+	s, err := NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("billing.invoices", "comments", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTestSchemaErrors(t *testing.T) {
+	for name, ts := range map[string]*TestSchema{
+		"duplicate table": NewTestSchema().Table("users", "id pk").Table("users", "id pk"),
+		"empty column":    NewTestSchema().Table("users", "id pk", " "),
+		"unknown flag":    NewTestSchema().Table("users", "id bigint primary"),
+		"missing table":   NewTestSchema().Table("users", "id pk").FK("posts.user_id", "users.id"),
+		"missing column":  NewTestSchema().Table("users", "id pk").FK("users.org_id", "users.id"),
+		"bad name":        NewTestSchema().Table("users", "id pk").FK("user_id", "users.id"),
+	} {
+		if _, err := ts.Build(); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+		if _, err := ts.BuildSchema(); err == nil {
+			t.Errorf("%s: want an error building the schema", name)
+		}
+	}
+}
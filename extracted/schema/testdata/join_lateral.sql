@@ -0,0 +1 @@
+SELECT json_build_object('id', "users"."id", 'email', "users"."email", 'posts', "__sj_posts"."json") AS "json" FROM "public"."users" AS "users" LEFT JOIN LATERAL (SELECT coalesce(json_agg(json_build_object('id', "posts"."id", 'user_id', "posts"."user_id", 'title', "posts"."title", 'comments', "__sj_comments"."json")), '[]') AS "json" FROM "public"."posts" AS "posts" LEFT JOIN LATERAL (SELECT coalesce(json_agg(json_build_object('id', "comments"."id", 'post_id', "comments"."post_id", 'body', "comments"."body")), '[]') AS "json" FROM "public"."comments" AS "comments" WHERE "posts"."id" = "comments"."post_id") AS "__sj_comments" ON true WHERE "users"."id" = "posts"."user_id") AS "__sj_posts" ON true
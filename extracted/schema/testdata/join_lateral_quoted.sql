@@ -0,0 +1 @@
+SELECT json_build_object('id', "users"."id", 'email', "users"."email", 'my"notes', "__sj_my""notes"."json") AS "json" FROM "public"."users" AS "users" LEFT JOIN LATERAL (SELECT coalesce(json_agg(json_build_object('id', "my""notes"."id", 'user_id', "my""notes"."user_id", 'it''s', "my""notes"."it's", 'say"hi"', "my""notes"."say""hi""")), '[]') AS "json" FROM "public"."my""notes" AS "my""notes" WHERE "users"."id" = "my""notes"."user_id") AS "__sj_my""notes" ON true
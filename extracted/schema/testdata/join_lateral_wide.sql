@@ -0,0 +1 @@
+SELECT json_build_object('id', "users"."id", 'events', "__sj_events"."json") AS "json" FROM "public"."users" AS "users" LEFT JOIN LATERAL (SELECT coalesce(json_agg((left(json_build_object('id', "events"."id", 'user_id', "events"."user_id", 'c0', "events"."c0", 'c1', "events"."c1", 'c2', "events"."c2", 'c3', "events"."c3", 'c4', "events"."c4", 'c5', "events"."c5", 'c6', "events"."c6", 'c7', "events"."c7", 'c8', "events"."c8", 'c9', "events"."c9", 'c10', "events"."c10", 'c11', "events"."c11", 'c12', "events"."c12", 'c13', "events"."c13", 'c14', "events"."c14", 'c15', "events"."c15", 'c16', "events"."c16", 'c17', "events"."c17", 'c18', "events"."c18", 'c19', "events"."c19", 'c20', "events"."c20", 'c21', "events"."c21", 'c22', "events"."c22", 'c23', "events"."c23", 'c24', "events"."c24", 'c25', "events"."c25", 'c26', "events"."c26", 'c27', "events"."c27", 'c28', "events"."c28", 'c29', "events"."c29", 'c30', "events"."c30", 'c31', "events"."c31", 'c32', "events"."c32", 'c33', "events"."c33", 'c34', "events"."c34", 'c35', "events"."c35", 'c36', "events"."c36", 'c37', "events"."c37", 'c38', "events"."c38", 'c39', "events"."c39", 'c40', "events"."c40", 'c41', "events"."c41", 'c42', "events"."c42", 'c43', "events"."c43", 'c44', "events"."c44", 'c45', "events"."c45", 'c46', "events"."c46", 'c47', "events"."c47")::text, -1) || ', ' || substr(json_build_object('c48', "events"."c48", 'c49', "events"."c49", 'c50', "events"."c50", 'c51', "events"."c51", 'c52', "events"."c52", 'c53', "events"."c53", 'c54', "events"."c54", 'c55', "events"."c55", 'c56', "events"."c56", 'c57', "events"."c57", 'c58', "events"."c58", 'c59', "events"."c59")::text, 2))::json), '[]') AS "json" FROM "public"."events" AS "events" WHERE "users"."id" = "events"."user_id") AS "__sj_events" ON true
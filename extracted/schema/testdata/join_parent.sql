@@ -0,0 +1 @@
+FROM "public"."comments" AS "c" LEFT JOIN "public"."posts" AS "posts" ON "c"."post_id" = "posts"."id" LEFT JOIN "public"."users" AS "users" ON "posts"."user_id" = "users"."id"
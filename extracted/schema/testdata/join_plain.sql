@@ -0,0 +1 @@
+FROM "public"."users" AS "users" JOIN "public"."posts" AS "posts" ON "users"."id" = "posts"."user_id" JOIN "public"."comments" AS "comments" ON "posts"."id" = "comments"."post_id"
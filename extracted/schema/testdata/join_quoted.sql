@@ -0,0 +1 @@
+FROM "public"."users" AS "users" JOIN "public"."my""notes" AS "my""notes" ON "users"."id" = "my""notes"."user_id"
@@ -0,0 +1,112 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+)
+
+// tieTables are tables with equally short paths between them, likes
+// belong to posts and threads both belonging to users, orders have an
+// editor and a user and invoices a seller and a buyer. Users are also in
+// the audit schema
+var tieTables = [][]string{
+	{"users", "id pk"},
+	{"posts", "id pk", "user_id notnull"},
+	{"threads", "id pk", "user_id notnull"},
+	{"likes", "id pk", "post_id notnull", "thread_id notnull"},
+	{"orders", "id pk", "editor_id notnull", "user_id notnull"},
+	{"invoices", "id pk", "seller_id notnull", "buyer_id notnull"},
+	{"audit.users", "id pk"},
+	{"audit.entries", "id pk", "user_id notnull"},
+}
+
+var tieFKs = [][2]string{
+	{"posts.user_id", "users.id"},
+	{"threads.user_id", "users.id"},
+	{"likes.post_id", "posts.id"},
+	{"likes.thread_id", "threads.id"},
+	{"orders.editor_id", "users.id"},
+	{"orders.user_id", "users.id"},
+	{"invoices.seller_id", "users.id"},
+	{"invoices.buyer_id", "users.id"},
+	{"audit.entries.user_id", "audit.users.id"},
+}
+
+// tieSchema adds the tables and foreign keys in order or in reverse
+func tieSchema(t *testing.T, reverse bool) *DBSchema {
+	t.Helper()
+	ts := NewTestSchema()
+	for i := range tieTables {
+		tt := tieTables[i]
+		if reverse {
+			tt = tieTables[len(tieTables)-1-i]
+		}
+		ts.Table(tt[0], tt[1:]...)
+	}
+	for i := range tieFKs {
+		fk := tieFKs[i]
+		if reverse {
+			fk = tieFKs[len(tieFKs)-1-i]
+		}
+		ts.FK(fk[0], fk[1])
+	}
+	s, err := ts.BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestPathTieBreak(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		// posts sorts before threads
+		{"likes", "users", "likes.post_id -> posts.id, posts.user_id -> users.id"},
+		// a column named after the table it references comes first
+		{"orders", "users", "orders.user_id -> users.id"},
+		// then the columns by name
+		{"invoices", "users", "invoices.buyer_id -> users.id"},
+	}
+
+	for _, tt := range tests {
+		for _, reverse := range []bool{false, true} {
+			path, err := tieSchema(t, reverse).FindPath(tt.from, tt.to, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := joinString(path); got != tt.want {
+				t.Errorf("%s -> %s (reverse %v): got %s, want %s", tt.from, tt.to, reverse, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFindTieBreak(t *testing.T) {
+	for _, reverse := range []bool{false, true} {
+		s := tieSchema(t, reverse)
+		ti, err := s.Find("", "users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ti.Schema != "public" {
+			t.Errorf("reverse %v: got %s, want the users of the default schema", reverse, ti.String())
+		}
+		if _, err := s.FindPath("audit.entries", "audit.users", ""); err != nil {
+			t.Errorf("reverse %v: %v", reverse, err)
+		}
+	}
+}
+
+// joinString returns the columns a path joins on
+func joinString(path []TPath) string {
+	var s string
+	for i, p := range path {
+		if i != 0 {
+			s += ", "
+		}
+		s += fmt.Sprintf("%s.%s -> %s.%s", p.LT.Name, p.LC.Name, p.RT.Name, p.RC.Name)
+	}
+	return s
+}
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the spans of the package
+const tracerName = "github.com/yourusername/graphjin-extracted/schema"
+
+// WithInfoTracing records a schema.GetDBInfo span with the tracer
+// provider, a child of the span of the context passed to GetDBInfo
+func WithInfoTracing(tp trace.TracerProvider) InfoOption {
+	return func(o *infoOptions) {
+		o.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// WithTracing records a schema.NewDBSchema span and a schema.FindPath
+// span for each path looked up with the tracer provider
+func WithTracing(tp trace.TracerProvider) Option {
+	return func(o *schemaOptions) {
+		o.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// withTraceContext is the context the NewDBSchema span is a child of
+func withTraceContext(ctx context.Context) Option {
+	return func(o *schemaOptions) {
+		o.traceCtx = ctx
+	}
+}
+
+// Tracer returns the tracer of the schema so the phases built on it, eg.
+// compiling and running queries, record their spans with the same
+// provider. It does nothing unless WithTracing is used
+func (s *DBSchema) Tracer() trace.Tracer {
+	if s.tracer == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return s.tracer
+}
+
+// startSpan starts a span when there is a tracer, otherwise the span
+// returned does nothing
+func startSpan(
+	ctx context.Context,
+	t trace.Tracer,
+	name string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return t.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends a span recording the error if any
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// infoAttributes returns the span attributes of a DBInfo
+func infoAttributes(di *DBInfo) []attribute.KeyValue {
+	var cols int
+	for _, t := range di.Tables {
+		cols += len(t.Columns)
+	}
+	return []attribute.KeyValue{
+		attribute.Int("schema.tables", len(di.Tables)),
+		attribute.Int("schema.columns", cols),
+		attribute.Int("schema.functions", len(di.Functions)),
+	}
+}
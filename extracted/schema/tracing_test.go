@@ -0,0 +1,141 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recorder is a tracer provider keeping the spans ended with their
+// attributes and the name of their parent
+type recorder struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []*recSpan
+}
+
+func (r *recorder) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return recTracer{r: r}
+}
+
+type recTracer struct {
+	noop.Tracer
+	r *recorder
+}
+
+func (t recTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	sp := &recSpan{name: name, attrs: map[string]string{}}
+	if p, ok := trace.SpanFromContext(ctx).(*recSpan); ok {
+		sp.parent = p.name
+	}
+	cfg := trace.NewSpanStartConfig(opts...)
+	sp.SetAttributes(cfg.Attributes()...)
+
+	t.r.mu.Lock()
+	t.r.spans = append(t.r.spans, sp)
+	t.r.mu.Unlock()
+	return trace.ContextWithSpan(ctx, sp), sp
+}
+
+type recSpan struct {
+	noop.Span
+	name, parent string
+	attrs        map[string]string
+	err          bool
+	ended        bool
+}
+
+func (s *recSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[string(a.Key)] = a.Value.Emit()
+	}
+}
+
+func (s *recSpan) SetStatus(c codes.Code, msg string) { s.err = c == codes.Error }
+func (s *recSpan) End(opts ...trace.SpanEndOption)    { s.ended = true }
+
+// span returns the last span of a name
+func (r *recorder) span(t *testing.T, name string) *recSpan {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.spans) - 1; i >= 0; i-- {
+		if r.spans[i].name == name {
+			return r.spans[i]
+		}
+	}
+	t.Fatalf("no span %s", name)
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	rec := &recorder{}
+	s := blogTestSchema(t, WithTracing(rec), WithPathCache())
+
+	ns := rec.span(t, "schema.NewDBSchema")
+	if !ns.ended || ns.attrs["schema.tables"] != "3" || ns.attrs["schema.relationships"] == "" {
+		t.Errorf("got span %+v", ns)
+	}
+
+	for _, hit := range []string{"false", "true"} {
+		if _, err := s.FindPath("comments", "users", ""); err != nil {
+			t.Fatal(err)
+		}
+		sp := rec.span(t, "schema.FindPath")
+		if sp.attrs["schema.path.from"] != "comments" || sp.attrs["schema.cache.hit"] != hit {
+			t.Errorf("got span %+v", sp)
+		}
+	}
+
+	if _, err := s.FindPath("comments", "missing", ""); err == nil {
+		t.Fatal("want an error")
+	}
+	if sp := rec.span(t, "schema.FindPath"); !sp.err || !sp.ended {
+		t.Errorf("got span %+v, want the error recorded", sp)
+	}
+
+	if _, ok := s.Tracer().(recTracer); !ok {
+		t.Errorf("got tracer %T", s.Tracer())
+	}
+	if _, ok := blogTestSchema(t).Tracer().(recTracer); ok {
+		t.Error("got the tracer without WithTracing")
+	}
+}
+
+// the spans are children of the span of the context
+func TestTracingContext(t *testing.T) {
+	rec := &recorder{}
+	ctx, root := rec.Tracer("").Start(context.Background(), "request")
+	defer root.End()
+
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		mysqlInfo:        {{80022, "shop", "shop"}},
+		mysqlColumnsStmt: {columnRow("shop", "users", "id", "bigint", true, true)},
+	}}
+	di, err := GetDBInfoFrom(ctx, q, "mysql", nil, WithInfoTracing(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := rec.span(t, "schema.GetDBInfo")
+	if sp.parent != "request" || sp.attrs["db.system"] != "mysql" || sp.attrs["schema.tables"] != fmt.Sprint(len(di.Tables)) {
+		t.Errorf("got span %+v", sp)
+	}
+
+	s, err := NewDBSchema(di, nil, WithTracing(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the users are not related to themselves
+	if _, err := s.FindPathContext(ctx, "users", "users", ""); err == nil {
+		t.Error("want an error")
+	}
+	if sp := rec.span(t, "schema.FindPath"); sp.parent != "request" {
+		t.Errorf("got span %+v", sp)
+	}
+}
@@ -0,0 +1,53 @@
+package schema
+
+import "strings"
+
+// trinoTypes maps trino type names to the type names
+// used across the rest of the schema package
+var trinoTypes = map[string]string{
+	"boolean":                  "boolean",
+	"tinyint":                  "smallint",
+	"smallint":                 "smallint",
+	"integer":                  "integer",
+	"bigint":                   "bigint",
+	"real":                     "real",
+	"double":                   "double precision",
+	"decimal":                  "numeric",
+	"varchar":                  "character varying",
+	"char":                     "character",
+	"varbinary":                "bytea",
+	"json":                     "json",
+	"date":                     "date",
+	"time":                     "time without time zone",
+	"time with time zone":      "time with time zone",
+	"timestamp":                "timestamp without time zone",
+	"timestamp with time zone": "timestamp with time zone",
+	"uuid":                     "uuid",
+	"ipaddress":                "inet",
+	"map":                      "json",
+	"row":                      "json",
+}
+
+// trinoType returns the normalized name for a trino type, the precision
+// of times and timestamps is dropped and arrays become arrays of the
+// element type
+func trinoType(t string) string {
+	if e, ok := strings.CutPrefix(t, "array("); ok && strings.HasSuffix(e, ")") {
+		return trinoType(e[:len(e)-1]) + "[]"
+	}
+
+	name := strings.ToLower(t)
+	if name == "varchar" {
+		return "text"
+	}
+	if i := strings.IndexByte(name, '('); i != -1 {
+		// timestamp(3) with time zone
+		if j := strings.IndexByte(name[i:], ')'); j != -1 {
+			name = name[:i] + name[i+j+1:]
+		}
+	}
+	if v, ok := trinoTypes[name]; ok {
+		return v
+	}
+	return t
+}
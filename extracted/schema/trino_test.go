@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetDBInfoTrino(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		trinoInfo: {{435, "hive:sales", "hive"}},
+		trinoColumnsStmt: {
+			columnRow("hive:sales", "orders", "id", "bigint", false, false),
+			columnRow("hive:sales", "orders", "customer_id", "bigint", false, false),
+			columnRow("hive:sales", "orders", "placed_at", "timestamp(3) with time zone", false, false),
+			columnRow("hive:sales", "order_items", "order_id", "bigint", false, false),
+			columnRow("postgresql:public", "customers", "id", "bigint", true, false),
+			columnRow("postgresql:public", "customers", "tags", "array(varchar)", false, false),
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "trino", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.ran(trinoColumnsStmt) || q.ran(postgresColumnsStmt) || q.ran(postgresFunctionsStmt) {
+		t.Error("trino columns not read with the trino statements")
+	}
+	if di.Type != "trino" || di.Version != 435 || di.Schema != "hive:sales" {
+		t.Errorf("got database %s %d %s", di.Type, di.Version, di.Schema)
+	}
+	for _, tt := range []struct{ schema, table, col, want string }{
+		{"hive:sales", "orders", "placed_at", "timestamp with time zone"},
+		{"postgresql:public", "customers", "tags", "text[]"},
+	} {
+		c, err := di.GetColumn(tt.schema, tt.table, tt.col)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Type != tt.want {
+			t.Errorf("%s: got type %s, want %s", tt.col, c.Type, tt.want)
+		}
+	}
+
+	// the relationships across catalogs are declared with the catalog
+	// and inferred within a schema
+	vr, err := NewVirtualRel("hive.sales.orders.customer_id", "postgresql.public.customers.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewDBSchema(di, nil, WithVirtualRels(vr), WithInferredRels(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.FindPath("order_items", "orders", ""); err != nil {
+		t.Error(err)
+	}
+
+	path, err := s.FindPath("orders", "customers", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	joins, err := PathToJoins(path, JoinOptions{Catalogs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joins) != 1 || !strings.HasPrefix(joins[0].SQL, `JOIN "postgresql"."public"."customers" AS "customers" ON`) {
+		t.Errorf("got joins %+v", joins)
+	}
+}
+
+func TestTrinoType(t *testing.T) {
+	for in, want := range map[string]string{
+		"varchar":                     "text",
+		"varchar(255)":                "character varying",
+		"decimal(10,2)":               "numeric",
+		"timestamp(6)":                "timestamp without time zone",
+		"timestamp(3) with time zone": "timestamp with time zone",
+		"array(bigint)":               "bigint[]",
+		"map(varchar, bigint)":        "json",
+		"row(a bigint)":               "json",
+		"hyperloglog":                 "hyperloglog",
+	} {
+		if got := trinoType(in); got != want {
+			t.Errorf("trinoType(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package schema
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LogicalType is the type of a column independent of the database, it is
+// what code generators map to their own types
+type LogicalType string
+
+const (
+	TypeString    LogicalType = "string"
+	TypeInt       LogicalType = "int"
+	TypeFloat     LogicalType = "float"
+	TypeBoolean   LogicalType = "boolean"
+	TypeJSON      LogicalType = "json"
+	TypeTime      LogicalType = "time"      // a point in time, a timestamp with a time zone
+	TypeTimestamp LogicalType = "timestamp" // a date and time of day without a time zone
+	TypeDate      LogicalType = "date"
+	TypeTimeOfDay LogicalType = "timeofday" // a time of day without a date, see DBColumn.TimeZone
+	TypeUUID      LogicalType = "uuid"
+	TypeBytes     LogicalType = "bytes"
+	TypeGeometry  LogicalType = "geometry"
+)
+
+// builtinTypes maps the types of the supported databases to their
+// logical types
+var builtinTypes = map[string]LogicalType{
+	"text":              TypeString,
+	"varchar":           TypeString,
+	"character varying": TypeString,
+	"character":         TypeString,
+	"char":              TypeString,
+	"bpchar":            TypeString,
+	"nchar":             TypeString,
+	"nvarchar":          TypeString,
+	"name":              TypeString,
+	"citext":            TypeString,
+	"tinytext":          TypeString,
+	"mediumtext":        TypeString,
+	"longtext":          TypeString,
+	"xml":               TypeString,
+	"inet":              TypeString,
+	"cidr":              TypeString,
+	"macaddr":           TypeString,
+	"interval":          TypeString,
+
+	"smallint":    TypeInt,
+	"integer":     TypeInt,
+	"int":         TypeInt,
+	"int2":        TypeInt,
+	"int4":        TypeInt,
+	"int8":        TypeInt,
+	"bigint":      TypeInt,
+	"tinyint":     TypeInt,
+	"mediumint":   TypeInt,
+	"serial":      TypeInt,
+	"serial4":     TypeInt,
+	"serial8":     TypeInt,
+	"smallserial": TypeInt,
+	"bigserial":   TypeInt,
+
+	"real":             TypeFloat,
+	"float":            TypeFloat,
+	"float4":           TypeFloat,
+	"float8":           TypeFloat,
+	"double":           TypeFloat,
+	"double precision": TypeFloat,
+	"numeric":          TypeFloat,
+	"decimal":          TypeFloat,
+	"money":            TypeFloat,
+
+	"boolean": TypeBoolean,
+	"bool":    TypeBoolean,
+	"bit":     TypeBoolean,
+
+	"json":  TypeJSON,
+	"jsonb": TypeJSON,
+
+	"timestamptz":                 TypeTime,
+	"timestamp with time zone":    TypeTime,
+	"datetimeoffset":              TypeTime,
+	"timestamp":                   TypeTimestamp,
+	"timestamp without time zone": TypeTimestamp,
+	"datetime":                    TypeTimestamp,
+	"datetime2":                   TypeTimestamp,
+	"smalldatetime":               TypeTimestamp,
+	"time":                        TypeTimeOfDay,
+	"timetz":                      TypeTimeOfDay,
+	"time with time zone":         TypeTimeOfDay,
+	"time without time zone":      TypeTimeOfDay,
+	"date":                        TypeDate,
+
+	"uuid":             TypeUUID,
+	"uniqueidentifier": TypeUUID,
+
+	"bytea":      TypeBytes,
+	"blob":       TypeBytes,
+	"longblob":   TypeBytes,
+	"binary":     TypeBytes,
+	"varbinary":  TypeBytes,
+	"image":      TypeBytes,
+	"geometry":   TypeGeometry,
+	"geography":  TypeGeometry,
+	"point":      TypeGeometry,
+	"line":       TypeGeometry,
+	"lseg":       TypeGeometry,
+	"box":        TypeGeometry,
+	"path":       TypeGeometry,
+	"polygon":    TypeGeometry,
+	"circle":     TypeGeometry,
+	"linestring": TypeGeometry,
+}
+
+// TypeMap maps database types to logical types, it starts with the
+// types of the supported databases and more can be registered for
+// extension types and custom domains. It is safe for concurrent use
+type TypeMap struct {
+	mu    sync.RWMutex
+	types map[string]LogicalType
+}
+
+// DefaultTypes is the type map used for the columns of new tables,
+// types registered with RegisterType are added to it
+var DefaultTypes = NewTypeMap()
+
+// NewTypeMap returns a type map holding the built-in types
+func NewTypeMap() *TypeMap {
+	tm := &TypeMap{types: make(map[string]LogicalType, len(builtinTypes))}
+	for k, v := range builtinTypes {
+		tm.types[k] = v
+	}
+	return tm
+}
+
+// RegisterType maps a database type to a logical type in the default
+// type map eg. RegisterType("ltree", TypeString)
+func RegisterType(dbType string, lt LogicalType) {
+	DefaultTypes.Register(dbType, lt)
+}
+
+// Register maps a database type to a logical type, the type can be
+// schema qualified eg. "public.email" and replaces a built-in one
+func (tm *TypeMap) Register(dbType string, lt LogicalType) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.types[normalizeType(dbType)] = lt
+}
+
+// Lookup returns the logical type of a database type and whether it is
+// known, unknown types are strings. The type parameters and the array
+// suffix eg. "varchar(20)[]" are ignored, array types have the logical
+// type of their elements
+func (tm *TypeMap) Lookup(dbType string) (LogicalType, bool) {
+	t := normalizeType(dbType)
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	// the unqualified name of a schema qualified type, the element
+	// type of a postgres array type and the first word of a type
+	// with modifiers eg. "int unsigned"
+	keys := []string{t}
+	if i := strings.LastIndexByte(t, '.'); i != -1 {
+		keys = append(keys, t[i+1:])
+	}
+	if strings.HasPrefix(t, "_") {
+		keys = append(keys, t[1:])
+	}
+	if i := strings.IndexByte(t, ' '); i != -1 {
+		keys = append(keys, t[:i])
+	}
+
+	for _, k := range keys {
+		if lt, ok := tm.types[k]; ok {
+			return lt, true
+		}
+	}
+	return TypeString, false
+}
+
+// MapTypes sets the logical type of every column with the type map
+func (di *DBInfo) MapTypes(tm *TypeMap) {
+	for i := range di.Tables {
+		t := &di.Tables[i]
+		for j := range t.Columns {
+			t.Columns[j].Logical, _ = tm.Lookup(t.Columns[j].Type)
+			setTemporal(&t.Columns[j], di.Type)
+		}
+		for j := range t.FullText {
+			t.FullText[j].Logical, _ = tm.Lookup(t.FullText[j].Type)
+		}
+		t.PrimaryCol.Logical, _ = tm.Lookup(t.PrimaryCol.Type)
+		setTemporal(&t.PrimaryCol, di.Type)
+	}
+}
+
+// setTemporal sets the time zone and precision of a time or timestamp
+// column from its type, the precision is the default of the database
+// when the type does not set it eg. 6 for timestamp and 3 for
+// timestamp(3) on postgres
+func setTemporal(c *DBColumn, dbType string) {
+	c.TimeZone, c.Precision = false, 0
+
+	t := strings.ToLower(strings.TrimSpace(c.Type))
+	t = strings.TrimSuffix(strings.TrimPrefix(t, "array of "), "[]")
+
+	switch c.Logical {
+	case TypeTime:
+		c.TimeZone = true
+	case TypeTimeOfDay:
+		name := normalizeType(t)
+		c.TimeZone = name == "timetz" || name == "time with time zone"
+	case TypeTimestamp:
+	default:
+		return
+	}
+
+	i := strings.IndexByte(t, '(')
+	j := strings.IndexByte(t, ')')
+	if i != -1 && j > i {
+		if n, err := strconv.Atoi(strings.TrimSpace(t[i+1 : j])); err == nil {
+			c.Precision = n
+			return
+		}
+	}
+
+	switch dbType {
+	case "mysql", "mariadb", "sqlite":
+		c.Precision = 0
+	default:
+		c.Precision = 6
+	}
+}
+
+// normalizeType returns a type name in lower case without its parameters
+// and array suffix
+func normalizeType(dbType string) string {
+	t := strings.ToLower(strings.TrimSpace(dbType))
+	t = strings.TrimPrefix(t, "array of ")
+	for strings.HasSuffix(t, "[]") {
+		t = strings.TrimSpace(strings.TrimSuffix(t, "[]"))
+	}
+
+	// parameters can be in the middle eg. timestamp(3) with time zone
+	for {
+		i := strings.IndexByte(t, '(')
+		if i == -1 {
+			break
+		}
+		j := strings.IndexByte(t[i:], ')')
+		if j == -1 {
+			t = t[:i]
+			break
+		}
+		t = t[:i] + t[i+j+1:]
+	}
+	return strings.Join(strings.Fields(t), " ")
+}
@@ -0,0 +1,142 @@
+package schema
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestTypeMapLookup(t *testing.T) {
+	tm := NewTypeMap()
+	for dbType, want := range map[string]LogicalType{
+		"varchar(20)":                 TypeString,
+		"CITEXT":                      TypeString,
+		"integer[]":                   TypeInt,
+		"_int4":                       TypeInt,
+		"array of bigint":             TypeInt,
+		"numeric(10, 2)":              TypeFloat,
+		"int unsigned":                TypeInt,
+		"timestamp(3) with time zone": TypeTime,
+		"pg_catalog.uuid":             TypeUUID,
+		"geography(Point,4326)":       TypeGeometry,
+	} {
+		got, ok := tm.Lookup(dbType)
+		if !ok || got != want {
+			t.Errorf("Lookup(%s) = %s, %v, want %s", dbType, got, ok, want)
+		}
+	}
+
+	// unknown types are strings until they are registered
+	if lt, ok := tm.Lookup("ltree"); ok || lt != TypeString {
+		t.Errorf("got %s, %v for an unknown type", lt, ok)
+	}
+	tm.Register("public.email", TypeString)
+	tm.Register("LTREE", TypeJSON)
+	tm.Register("text", TypeBytes)
+	for dbType, want := range map[string]LogicalType{
+		"ltree[]":      TypeJSON,
+		"public.email": TypeString,
+		"text":         TypeBytes,
+	} {
+		if got, ok := tm.Lookup(dbType); !ok || got != want {
+			t.Errorf("Lookup(%s) = %s, %v, want %s", dbType, got, ok, want)
+		}
+	}
+
+	// the default type map is not changed
+	if lt, _ := DefaultTypes.Lookup("text"); lt != TypeString {
+		t.Errorf("default type map changed, got %s", lt)
+	}
+}
+
+func TestGetDBInfoTypeMap(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresInfo: {{140000, "public", "db"}},
+		postgresColumnsStmt: {
+			columnRow("public", "places", "id", "bigint", true, true),
+			columnRow("public", "places", "path", "ltree", false, false),
+			columnRow("public", "places", "tags", "text[]", false, false),
+		},
+	}}
+
+	di, err := GetDBInfoFrom(context.Background(), q, "postgres", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := di.GetColumn("public", "places", "tags"); c.Logical != TypeString {
+		t.Errorf("got type %s for text[]", c.Logical)
+	}
+
+	tm := NewTypeMap()
+	tm.Register("ltree", TypeJSON)
+	di, err = GetDBInfoFrom(context.Background(), q, "postgres", nil, WithTypeMap(tm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for col, want := range map[string]LogicalType{"id": TypeInt, "path": TypeJSON} {
+		if c, _ := di.GetColumn("public", "places", col); c.Logical != want {
+			t.Errorf("%s: got type %s, want %s", col, c.Logical, want)
+		}
+	}
+	if di.Tables[0].PrimaryCol.Logical != TypeInt {
+		t.Errorf("got primary key type %s", di.Tables[0].PrimaryCol.Logical)
+	}
+}
+
+func TestTemporal(t *testing.T) {
+	tests := []struct {
+		typ       string
+		logical   LogicalType
+		timeZone  bool
+		precision int
+	}{
+		{"timestamptz", TypeTime, true, 6},
+		{"timestamp(3) with time zone", TypeTime, true, 3},
+		{"timestamp", TypeTimestamp, false, 6},
+		{"timestamp without time zone", TypeTimestamp, false, 6},
+		{"timestamp(0)[]", TypeTimestamp, false, 0},
+		{"date", TypeDate, false, 0},
+		{"time", TypeTimeOfDay, false, 6},
+		{"time with time zone", TypeTimeOfDay, true, 6},
+		{"timetz(2)", TypeTimeOfDay, true, 2},
+		{"text", TypeString, false, 0},
+	}
+	cols := make([]DBColumn, len(tests))
+	for i, tt := range tests {
+		cols[i] = DBColumn{Name: fmt.Sprint("c", i), Type: tt.typ}
+	}
+	ti := NewDBTable("public", "events", "", cols)
+	for i, tt := range tests {
+		c := ti.Columns[i]
+		if c.Logical != tt.logical || c.TimeZone != tt.timeZone || c.Precision != tt.precision {
+			t.Errorf("%s: got %s time zone %v precision %d", tt.typ, c.Logical, c.TimeZone, c.Precision)
+		}
+	}
+
+	// mysql types have no fractional seconds unless they set them
+	di := &DBInfo{Type: "mysql", Tables: []DBTable{NewDBTable("shop", "events", "", []DBColumn{
+		{Name: "at", Type: "datetime"},
+		{Name: "at3", Type: "datetime(3)"},
+	})}}
+	di.MapTypes(DefaultTypes)
+	if p0, p3 := di.Tables[0].Columns[0].Precision, di.Tables[0].Columns[1].Precision; p0 != 0 || p3 != 3 {
+		t.Errorf("got precisions %d and %d", p0, p3)
+	}
+}
+
+func TestTemporalJSON(t *testing.T) {
+	di := &DBInfo{Type: "postgres", Tables: []DBTable{NewDBTable("public", "events", "", []DBColumn{
+		{Name: "at", Type: "timestamptz(3)"},
+	})}}
+	b, err := di.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got DBInfo
+	if err := got.UnmarshalJSON(b); err != nil {
+		t.Fatal(err)
+	}
+	if c := got.Tables[0].Columns[0]; !c.TimeZone || c.Precision != 3 {
+		t.Errorf("got %+v from %s", c, b)
+	}
+}
@@ -0,0 +1,82 @@
+package schema
+
+import "testing"
+
+func TestIsUnique(t *testing.T) {
+	ti := DBTable{
+		Columns: []DBColumn{
+			{Name: "org_id", PrimaryKey: true},
+			{Name: "id", PrimaryKey: true},
+			{Name: "email"},
+			{Name: "handle"},
+			{Name: "slug"},
+		},
+		Indexes: []DBIndex{
+			{Columns: []string{"email"}, Unique: true},
+			{Columns: []string{"handle"}, Unique: true, Predicate: "deleted_at IS NULL"},
+			{Columns: []string{"slug"}},
+		},
+	}
+
+	tests := []struct {
+		cols []string
+		want bool
+	}{
+		{[]string{"org_id", "id"}, true},
+		{[]string{"id"}, false},
+		{[]string{"id", "org_id", "slug"}, true},
+		{[]string{"email"}, true},
+		{[]string{"handle"}, false}, // partial
+		{[]string{"slug"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := ti.IsUnique(tt.cols...); got != tt.want {
+			t.Errorf("IsUnique(%v) = %v, want %v", tt.cols, got, tt.want)
+		}
+	}
+
+	// without indexes a unique constraint of a column is unique
+	ti = NewDBTable("public", "profiles", "table", []DBColumn{{Name: "user_id", UniqueKey: true}, {Name: "bio"}})
+	if !ti.IsUnique("user_id") || ti.IsUnique("bio") || ti.IsUnique("user_id", "bio") {
+		t.Error("got the unique constraint of a column without indexes wrong")
+	}
+}
+
+// a foreign key with a unique constraint joins one row to its parent
+func TestUniqueForeignKey(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("profiles", "id pk", "user_id notnull unique").
+		Table("posts", "id pk", "user_id notnull").
+		FK("profiles.user_id", "users.id").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := s.Find("", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rels, err := s.GetTableRels(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]RelType{}
+	for _, r := range rels {
+		got[r.Right.Ti.Name] = r.Type
+	}
+	if got["profiles"] != RelOneToOne || got["posts"] != RelOneToMany {
+		t.Errorf("got relationships %v", got)
+	}
+
+	path, err := s.FindPath("users", "profiles", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 1 || path[0].Rel != RelOneToOne {
+		t.Errorf("got path %s", pathString(path))
+	}
+}
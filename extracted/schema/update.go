@@ -0,0 +1,163 @@
+package schema
+
+import (
+	"fmt"
+)
+
+// AddTable adds a table and its foreign key relationships to the graph
+// of a built schema, the tables the foreign keys point to must already
+// be in the schema. It is safe to call while the schema is in use, the
+// table is added to a new snapshot with its own fingerprint and path
+// cache, lookups already running finish on the snapshot they started on
+func (s *DBSchema) AddTable(t DBTable) error {
+	return s.update(func(n *DBSchema) error {
+		return n.addTable(t)
+	})
+}
+
+// addTable is AddTable on the snapshot being built
+func (s *DBSchema) addTable(t DBTable) error {
+	if t.Schema == "" {
+		t.Schema = s.schema
+	}
+	if _, ok := s.tindex[(t.Schema + ":" + t.Name)]; ok {
+		return fmt.Errorf("table already exists: %s", t.String())
+	}
+
+	if t.colMap == nil {
+		t.colMap = make(map[string]int, len(t.Columns))
+		for i, c := range t.Columns {
+			t.colMap[c.Name] = i
+		}
+	}
+
+	s.addNode(t)
+	return s.addTableRels(t)
+}
+
+// addTableRels adds the relationships of a new table
+func (s *DBSchema) addTableRels(t DBTable) error {
+	if err := s.addRels(t); err != nil {
+		return err
+	}
+	return s.addManyToManyRels(t)
+}
+
+// AddRelationship adds a virtual relationship to the graph of a built
+// schema, the column must not already have a foreign key. A table that
+// becomes a join table does not get many-to-many relationships, add it
+// with AddTable for those
+func (s *DBSchema) AddRelationship(vr VirtualRel) error {
+	return s.update(func(n *DBSchema) error {
+		return n.addRelationship(vr)
+	})
+}
+
+// addRelationship is AddRelationship on the snapshot being built
+func (s *DBSchema) addRelationship(vr VirtualRel) error {
+	if vr.Schema == "" {
+		vr.Schema = s.schema
+	}
+
+	v, ok := s.tindex[(vr.Schema + ":" + vr.Table)]
+	if !ok {
+		return fmt.Errorf("virtual relationship: table not found: %s.%s",
+			vr.Schema, vr.Table)
+	}
+	if c, ok := s.tables[v.nodeID].getColumn(vr.Column); ok && c.FKeyTable != "" {
+		return fmt.Errorf("virtual relationship: column already has a foreign key: %s.%s",
+			vr.Table, vr.Column)
+	}
+
+	if err := s.addVirtualRels([]VirtualRel{vr}); err != nil {
+		return err
+	}
+
+	t := s.tables[v.nodeID]
+	c, _ := t.getColumn(vr.Column)
+	return s.addColumnRel(t, c, nil)
+}
+
+// RemoveTable removes a table and all the relationships to and from it
+// from the graph of a built schema, its aliases are removed with it
+func (s *DBSchema) RemoveTable(name string) error {
+	return s.update(func(n *DBSchema) error {
+		schema, tn := n.splitTableName(name)
+		v, ok := n.tindex[(schema + ":" + tn)]
+		if !ok {
+			return n.tableNotFound(name, nil)
+		}
+		n.removeNode(v.nodeID)
+		return nil
+	})
+}
+
+// removeNode takes a node out of the indexes and the graph, the table
+// is left in place so the ids of the other nodes do not change
+func (s *DBSchema) removeNode(nid int32) {
+	t := s.tables[nid]
+
+	for k, v := range s.tindex {
+		if v.nodeID == nid {
+			delete(s.tindex, k)
+		}
+	}
+	for k, v := range s.tableAliasIndex {
+		if v.nodeID == nid {
+			delete(s.tableAliasIndex, k)
+		}
+	}
+
+	edges := make(map[int32]struct{})
+	for id, e := range s.allEdges {
+		if e.From == nid || e.To == nid {
+			edges[id] = struct{}{}
+			delete(s.allEdges, id)
+		}
+	}
+
+	for k, eiList := range s.edgesIndex {
+		var list []edgeInfo
+		for _, ei := range eiList {
+			if ei.nodeID == nid {
+				continue
+			}
+			var ids []int32
+			for _, id := range ei.edgeIDs {
+				if _, ok := edges[id]; !ok {
+					ids = append(ids, id)
+				}
+			}
+			if len(ids) != 0 {
+				list = append(list, edgeInfo{nodeID: ei.nodeID, edgeIDs: ids})
+			}
+		}
+		if len(list) == 0 {
+			delete(s.edgesIndex, k)
+		} else {
+			s.edgesIndex[k] = list
+		}
+	}
+
+	s.relationshipGraph.RemoveEdges(nid)
+	delete(s.tableFilters, (t.Schema + ":" + t.Name))
+	delete(s.softDeletes, (t.Schema + ":" + t.Name))
+
+	if s.lazy != nil {
+		s.lazy.mu.Lock()
+		delete(s.lazy.tables, nid)
+		s.lazy.mu.Unlock()
+	}
+
+	if s.removed == nil {
+		s.removed = make(map[int32]struct{})
+	}
+	s.removed[nid] = struct{}{}
+}
+
+// changed updates the fingerprint and reports the size of the graph of
+// a new snapshot
+func (s *DBSchema) changed() {
+	s.setFingerprint()
+	s.graphChanged()
+}
@@ -0,0 +1,119 @@
+package schema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAddTable(t *testing.T) {
+	s := blogTestSchema(t, WithPathCache())
+	fp := s.Fingerprint()
+
+	// a path missing before the table is added is not kept in the cache
+	if _, err := s.FindPath("notes", "users", ""); err == nil {
+		t.Fatal("want an error before notes is added")
+	}
+	if err := s.AddTable(noteTable()); err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("notes", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 1 || path[0].LC.Name != "user_id" {
+		t.Errorf("got path %s", pathString(path))
+	}
+	if s.Fingerprint() == fp {
+		t.Error("fingerprint not changed")
+	}
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Error(err)
+	}
+
+	if err := s.AddTable(noteTable()); err == nil {
+		t.Error("want an error adding notes again")
+	}
+	bad := NewDBTable("public", "tags", "", []DBColumn{
+		{Name: "id", Type: "bigint", PrimaryKey: true},
+		{Name: "note_id", Type: "bigint", FKeySchema: "public", FKeyTable: "missing", FKeyCol: "id"},
+	})
+	if err := s.AddTable(bad); err == nil {
+		t.Error("want an error for a foreign key to a missing table")
+	}
+	if _, err := s.Find("", "tags"); err == nil {
+		t.Error("table of a failed update added")
+	}
+}
+
+func TestAddRelationship(t *testing.T) {
+	s, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("events", "id pk", "actor_id", "user_id").
+		FK("events.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FindPath("events", "actor", ""); err == nil {
+		t.Fatal("want an error before the relationship is added")
+	}
+	vr := VirtualRel{Table: "events", Column: "actor_id", FKeyTable: "users", FKeyCol: "id"}
+	if err := s.AddRelationship(vr); err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("events", "actor", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LC.Name != "actor_id" {
+		t.Errorf("got path on %s", path[0].LC.Name)
+	}
+
+	for _, vr := range []VirtualRel{
+		{Table: "events", Column: "user_id", FKeyTable: "users", FKeyCol: "id"},
+		{Table: "missing", Column: "user_id", FKeyTable: "users", FKeyCol: "id"},
+		{Table: "events", Column: "id", FKeyTable: "missing", FKeyCol: "id"},
+	} {
+		if err := s.AddRelationship(vr); err == nil {
+			t.Errorf("%+v: want an error", vr)
+		}
+	}
+}
+
+func TestRemoveTable(t *testing.T) {
+	s := blogTestSchema(t, WithPathCache(), WithAliases(Alias{Name: "articles", Table: "posts"}))
+
+	if _, err := s.FindPath("comments", "posts", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveTable("posts"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"posts", "articles"} {
+		if _, err := s.Find("", name); !errors.Is(err, &ErrTableNotFound{}) {
+			t.Errorf("%s: got error %v", name, err)
+		}
+	}
+	if _, err := s.FindPath("comments", "posts", ""); err == nil {
+		t.Error("want an error for a path to a removed table")
+	}
+	if _, err := s.FindPath("comments", "users", ""); err != nil {
+		t.Error(err)
+	}
+	ti, _ := s.Find("", "users")
+	rels, err := s.GetTableRels(ti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rels {
+		if r.Right.Ti.Name == "posts" {
+			t.Errorf("relationship %s to a removed table", r.Name)
+		}
+	}
+
+	if err := s.RemoveTable("posts"); !errors.Is(err, &ErrTableNotFound{}) {
+		t.Errorf("got error %v removing posts again", err)
+	}
+}
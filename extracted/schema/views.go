@@ -0,0 +1,183 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DBView holds the definition of a view and the base table columns
+// its columns come from
+type DBView struct {
+	Schema       string
+	Name         string
+	Definition   string
+	Lineage      []DBViewColumn
+	Materialized bool
+	Populated    bool
+	RefreshedAt  time.Time
+}
+
+// DBViewColumn maps a view column to the base table column it comes from
+type DBViewColumn struct {
+	Name       string
+	BaseSchema string
+	BaseTable  string
+	BaseCol    string
+}
+
+// DiscoverViews returns the views and materialized views of a database,
+// column lineage is only set for view columns that come from a single
+// base table column with the same name
+func DiscoverViews(ctx context.Context, db Querier, dbtype string) ([]DBView, error) {
+	switch dbtype {
+	case "", "postgres":
+	case "duckdb":
+		// duckdb has no materialized views or lineage, its views are
+		// often over parquet or csv files
+		return discoverViews(ctx, db, duckdbViewsStmt)
+	case "redshift":
+		// late binding views have no dependencies to find lineage with
+		return discoverViews(ctx, db, redshiftViewsStmt)
+	default:
+		return nil, nil
+	}
+
+	views, err := discoverViews(ctx, db, postgresViewsStmt)
+	if err != nil {
+		return nil, err
+	}
+
+	mviews, err := discoverMatViews(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(views, mviews...), nil
+}
+
+func discoverViews(ctx context.Context, db Querier, sqlStmt string) ([]DBView, error) {
+	rows, err := db.Query(ctx, sqlStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching views: %s", err)
+	}
+	defer rows.Close()
+
+	var views []DBView
+	vm := make(map[string]int)
+	seen := make(map[string]int)
+
+	for rows.Next() {
+		var vs, vn, def, cn, bs, bt string
+
+		if err = rows.Scan(&vs, &vn, &def, &cn, &bs, &bt); err != nil {
+			return nil, err
+		}
+
+		k := (vs + ":" + vn)
+		i, ok := vm[k]
+		if !ok {
+			views = append(views, DBView{Schema: vs, Name: vn, Definition: def})
+			i = len(views) - 1
+			vm[k] = i
+		}
+
+		if cn == "" {
+			continue
+		}
+		views[i].Lineage = append(views[i].Lineage, DBViewColumn{
+			Name:       cn,
+			BaseSchema: bs,
+			BaseTable:  bt,
+			BaseCol:    cn,
+		})
+		seen[(k+":"+cn)]++
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// drop lineage for columns found in more than one base table
+	for i, v := range views {
+		var lineage []DBViewColumn
+		for _, vc := range v.Lineage {
+			if seen[(v.Schema+":"+v.Name+":"+vc.Name)] == 1 {
+				lineage = append(lineage, vc)
+			}
+		}
+		views[i].Lineage = lineage
+	}
+
+	return views, nil
+}
+
+// discoverMatViews returns materialized views, the refresh time is only
+// known when track_commit_timestamp is enabled and is not updated by a
+// concurrent refresh
+func discoverMatViews(ctx context.Context, db Querier) ([]DBView, error) {
+	rows, err := db.Query(ctx, postgresMatViewsStmt)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching materialized views: %s", err)
+	}
+	defer rows.Close()
+
+	var views []DBView
+
+	for rows.Next() {
+		var refreshed sql.NullTime
+		v := DBView{Materialized: true}
+
+		err = rows.Scan(&v.Schema, &v.Name, &v.Definition, &v.Populated, &refreshed)
+		if err != nil {
+			return nil, err
+		}
+		v.RefreshedAt = refreshed.Time
+		views = append(views, v)
+	}
+
+	return views, rows.Err()
+}
+
+// addViews marks tables that are views and sets the lineage of their
+// columns, view columns inherit the foreign key of their base column
+// and point to the base column when it is a primary or unique key so
+// paths can be found through a view to its base tables
+func (di *DBInfo) addViews(views []DBView) {
+	for _, v := range views {
+		tid, ok := di.tableMap[(v.Schema + ":" + v.Name)]
+		if !ok {
+			continue
+		}
+		t := &di.Tables[tid]
+		t.Type = "view"
+		t.Definition = v.Definition
+		t.Materialized = v.Materialized
+		t.Populated = v.Populated
+		t.RefreshedAt = v.RefreshedAt
+
+		for _, vc := range v.Lineage {
+			cid, ok := t.colMap[vc.Name]
+			if !ok {
+				continue
+			}
+			c := &t.Columns[cid]
+			c.BaseSchema = vc.BaseSchema
+			c.BaseTable = vc.BaseTable
+			c.BaseCol = vc.BaseCol
+
+			if c.FKeyTable != "" {
+				continue
+			}
+
+			bc, err := di.GetColumn(vc.BaseSchema, vc.BaseTable, vc.BaseCol)
+			if err != nil {
+				continue
+			}
+
+			inheritFKey(c, *bc)
+			c.FKRecursive = (c.FKeySchema == c.Schema && c.FKeyTable == c.Table)
+		}
+	}
+}
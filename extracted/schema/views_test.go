@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDiscoverViews(t *testing.T) {
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresViewsStmt: {
+			{"public", "post_list", "SELECT ...", "id", "public", "posts"},
+			{"public", "post_list", "SELECT ...", "user_id", "public", "posts"},
+			// a column of two base tables has no lineage
+			{"public", "post_list", "SELECT ...", "created_at", "public", "posts"},
+			{"public", "post_list", "SELECT ...", "created_at", "public", "users"},
+			{"public", "constants", "SELECT 1 AS one", "", "", ""},
+		},
+	}}
+
+	views, err := DiscoverViews(context.Background(), q, "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("got views %v", views)
+	}
+	v := views[0]
+	if v.Name != "post_list" || v.Definition != "SELECT ..." || len(v.Lineage) != 2 {
+		t.Fatalf("got view %+v", v)
+	}
+	if vc := v.Lineage[1]; vc.Name != "user_id" || vc.BaseTable != "posts" || vc.BaseCol != "user_id" {
+		t.Errorf("got lineage %+v", vc)
+	}
+
+	// other databases have no views
+	if views, err := DiscoverViews(context.Background(), q, "mysql"); err != nil || views != nil {
+		t.Errorf("got views %v, %v", views, err)
+	}
+}
+
+func TestViewLineagePaths(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id notnull").
+		Table("post_list", "id", "user_id").
+		FK("posts.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.addViews([]DBView{{Schema: "public", Name: "post_list", Lineage: []DBViewColumn{
+		{Name: "id", BaseSchema: "public", BaseTable: "posts", BaseCol: "id"},
+		{Name: "user_id", BaseSchema: "public", BaseTable: "posts", BaseCol: "user_id"},
+	}}})
+
+	tbl, _ := info.GetTable("public", "post_list")
+	if tbl.Type != "view" {
+		t.Errorf("got type %q", tbl.Type)
+	}
+	c, _ := info.GetColumn("public", "post_list", "user_id")
+	if c.BaseTable != "posts" || c.FKeyTable != "users" {
+		t.Errorf("got base %s and foreign key %s", c.BaseTable, c.FKeyTable)
+	}
+
+	s, err := NewDBSchema(info, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, to := range []string{"users", "posts"} {
+		if _, err := s.FindPath("post_list", to, ""); err != nil {
+			t.Errorf("post_list -> %s: %s", to, err)
+		}
+	}
+}
+
+func TestDiscoverMatViews(t *testing.T) {
+	refreshed := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	q := &routeQuerier{rows: map[string][][]interface{}{
+		postgresMatViewsStmt: {
+			{"public", "user_stats", "SELECT ...", true, refreshed},
+			{"public", "empty_stats", "SELECT ...", false, nil},
+		},
+	}}
+
+	views, err := DiscoverViews(context.Background(), q, "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 2 {
+		t.Fatalf("got views %v", views)
+	}
+	if v := views[0]; !v.Materialized || !v.Populated || !v.RefreshedAt.Equal(refreshed) {
+		t.Errorf("got view %+v", v)
+	}
+	if v := views[1]; !v.Materialized || v.Populated || !v.RefreshedAt.IsZero() {
+		t.Errorf("got view %+v", v)
+	}
+}
+
+func TestMatViewUniqueIndex(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("user_stats", "user_id", "day date", "posts integer").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.addViews([]DBView{{Schema: "public", Name: "user_stats", Materialized: true, Populated: true}})
+	info.addIndexes([]DBIndex{
+		{Schema: "public", Table: "user_stats", Name: "user_stats_user", Columns: []string{"user_id"}, Unique: true},
+		{Schema: "public", Table: "user_stats", Name: "user_stats_day", Columns: []string{"day"}, Unique: true, Predicate: "posts > 0"},
+	})
+
+	tbl, _ := info.GetTable("public", "user_stats")
+	if !tbl.Materialized || len(tbl.Indexes) != 2 {
+		t.Fatalf("got table %+v", tbl)
+	}
+	// a matview has no constraints, its unique index is its key
+	for col, want := range map[string]bool{"user_id": true, "day": false} {
+		c, _ := info.GetColumn("public", "user_stats", col)
+		if c.UniqueKey != want {
+			t.Errorf("%s: got unique key %t, want %t", col, c.UniqueKey, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VirtualRel declares a relationship between two columns that is
+// not backed by a foreign key constraint in the database
+type VirtualRel struct {
+	Schema     string
+	Table      string
+	Column     string
+	FKeySchema string
+	FKeyTable  string
+	FKeyCol    string
+}
+
+// NewVirtualRel returns a virtual relationship between two columns
+// given as 'table.column', 'schema.table.column' or for the catalogs
+// of trino 'catalog.schema.table.column'
+func NewVirtualRel(from, to string) (VirtualRel, error) {
+	var vr VirtualRel
+	var err error
+
+	if vr.Schema, vr.Table, vr.Column, err = splitColumnName(from); err != nil {
+		return vr, err
+	}
+	if vr.FKeySchema, vr.FKeyTable, vr.FKeyCol, err = splitColumnName(to); err != nil {
+		return vr, err
+	}
+	return vr, nil
+}
+
+// String returns a string representation of the VirtualRel
+func (vr VirtualRel) String() string {
+	return fmt.Sprintf("%s.%s.%s -> %s.%s.%s",
+		vr.Schema, vr.Table, vr.Column,
+		vr.FKeySchema, vr.FKeyTable, vr.FKeyCol)
+}
+
+// splitColumnName splits a 'table.column' or 'schema.table.column' name
+func splitColumnName(name string) (schema, table, column string, err error) {
+	v := strings.Split(name, ".")
+	switch len(v) {
+	case 2:
+		return "", v[0], v[1], nil
+	case 3:
+		return v[0], v[1], v[2], nil
+	case 4:
+		return nsSchema(v[0], v[1]), v[2], v[3], nil
+	}
+	return "", "", "", fmt.Errorf("invalid column name: '%s'", name)
+}
+
+// addVirtualRels sets the foreign key of the columns declared in
+// virtual relationships before the graph edges are added
+func (s *DBSchema) addVirtualRels(rels []VirtualRel) error {
+	for _, vr := range rels {
+		if vr.Schema == "" {
+			vr.Schema = s.schema
+		}
+		if vr.FKeySchema == "" {
+			vr.FKeySchema = vr.Schema
+		}
+
+		v, ok := s.tindex[(vr.Schema + ":" + vr.Table)]
+		if !ok {
+			return fmt.Errorf("virtual relationship: table not found: %s.%s",
+				vr.Schema, vr.Table)
+		}
+
+		ft, ok := s.tindex[(vr.FKeySchema + ":" + vr.FKeyTable)]
+		if !ok {
+			return fmt.Errorf("virtual relationship: foreign key table not found: %s.%s",
+				vr.FKeySchema, vr.FKeyTable)
+		}
+
+		if _, ok := s.tables[ft.nodeID].getColumn(vr.FKeyCol); !ok {
+			return fmt.Errorf("virtual relationship: foreign key column not found: %s.%s",
+				vr.FKeyTable, vr.FKeyCol)
+		}
+
+		t := &s.tables[v.nodeID]
+		i, ok := t.colMap[vr.Column]
+		if !ok {
+			return fmt.Errorf("virtual relationship: column not found: %s.%s",
+				vr.Table, vr.Column)
+		}
+
+		// copy the columns so the DBInfo passed in is not modified
+		t.Columns = append([]DBColumn{}, t.Columns...)
+
+		c := &t.Columns[i]
+		c.FKeySchema = s.tables[ft.nodeID].Schema
+		c.FKeyTable = s.tables[ft.nodeID].Name
+		c.FKeyCol = vr.FKeyCol
+		c.FKRecursive = (c.FKeySchema == t.Schema && c.FKeyTable == t.Name)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package schema
+
+import "testing"
+
+func TestNewVirtualRel(t *testing.T) {
+	vr, err := NewVirtualRel("posts.author", "app.users.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VirtualRel{Table: "posts", Column: "author", FKeySchema: "app", FKeyTable: "users", FKeyCol: "id"}
+	if vr != want {
+		t.Errorf("got %+v, want %+v", vr, want)
+	}
+
+	for _, name := range []string{"author", "a.b.c.d.e"} {
+		if _, err := NewVirtualRel(name, "users.id"); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestVirtualRels(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "author notnull").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vr, _ := NewVirtualRel("posts.author", "users.id")
+	s, err := NewDBSchema(info, nil, WithVirtualRels(vr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := s.FindPath("posts", "users", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path[0].LC.Name != "author" || path[0].RC.Name != "id" {
+		t.Errorf("got path %s.%s -> %s.%s", path[0].LT.Name, path[0].LC.Name, path[0].RT.Name, path[0].RC.Name)
+	}
+
+	// the DBInfo is not modified
+	c, _ := info.GetColumn("public", "posts", "author")
+	if c.FKeyTable != "" {
+		t.Errorf("dbinfo column got foreign key %s", c.FKeyTable)
+	}
+	if _, err := NewDBSchema(info, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVirtualRelErrors(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "author notnull").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vr := range []VirtualRel{
+		{Table: "comments", Column: "author", FKeyTable: "users", FKeyCol: "id"},
+		{Table: "posts", Column: "author", FKeyTable: "people", FKeyCol: "id"},
+		{Table: "posts", Column: "author", FKeyTable: "users", FKeyCol: "uid"},
+		{Table: "posts", Column: "writer", FKeyTable: "users", FKeyCol: "id"},
+	} {
+		if _, err := NewDBSchema(info, nil, WithVirtualRels(vr)); err == nil {
+			t.Errorf("%s: want an error", vr)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DDLNotifyChannel is the postgres channel notified on schema changes
+// once InstallDDLNotify has been run against the database
+const DDLNotifyChannel = "graphjin_ddl"
+
+// WatcherConfig holds the configuration for a schema watcher
+type WatcherConfig struct {
+	DBType    string
+	BlockList []string
+	Aliases   map[string][]string
+	VTables   []VirtualTable
+
+	// Interval between introspection runs, polling is disabled when zero
+	Interval time.Duration
+
+	// Notify triggers an immediate reload, pgxdb.ListenDDL returns a
+	// channel forwarding the notifications of the DDLNotifyChannel
+	Notify <-chan struct{}
+
+	// OnError is called with errors from background reloads
+	OnError func(error)
+
+	// Metrics receives the introspection, graph and reload metrics of
+	// the schemas built by the watcher
+	Metrics Metrics
+
+	// Options are passed to NewDBSchema and InfoOptions to GetDBInfo on
+	// every reload, eg. the roles, filters and virtual relationships of
+	// the schema. A cache of WithCache hides the changes made within its
+	// TTL
+	Options     []Option
+	InfoOptions []InfoOption
+}
+
+// SchemaChangeFunc is called with the new schema and the changes
+// that caused it to be rebuilt
+type SchemaChangeFunc func(s *DBSchema, d *DBInfoDiff)
+
+// Watcher keeps a DBSchema up to date with the database
+type Watcher struct {
+	db   *sql.DB
+	conf WatcherConfig
+
+	mu     sync.Mutex
+	info   *DBInfo
+	schema atomic.Pointer[DBSchema]
+	subs   []SchemaChangeFunc
+}
+
+// NewWatcher introspects the database and returns a watcher
+// holding the initial schema
+func NewWatcher(ctx context.Context, db *sql.DB, conf WatcherConfig) (*Watcher, error) {
+	w := &Watcher{db: db, conf: conf}
+
+	info, s, err := w.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	w.info = info
+	w.schema.Store(s)
+	return w, nil
+}
+
+// Schema returns the current schema
+func (w *Watcher) Schema() *DBSchema {
+	return w.schema.Load()
+}
+
+// OnChange registers a function to be called after the schema changes
+func (w *Watcher) OnChange(fn SchemaChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subs = append(w.subs, fn)
+}
+
+// Reload re-runs introspection and swaps in a new schema if the
+// database changed, the returned diff is empty when nothing changed
+func (w *Watcher) Reload(ctx context.Context) (*DBInfoDiff, error) {
+	d, err := w.reload(ctx)
+	if w.conf.Metrics != nil {
+		w.conf.Metrics.Reloaded(d != nil && !d.Empty(), err)
+	}
+	return d, err
+}
+
+// reload is Reload without the metrics, the subscribers are called
+// after the lock is released so they can use the watcher
+func (w *Watcher) reload(ctx context.Context) (*DBInfoDiff, error) {
+	s, d, subs, err := w.swap(ctx)
+	if err != nil || s == nil {
+		return d, err
+	}
+
+	for _, fn := range subs {
+		fn(s, d)
+	}
+	return d, nil
+}
+
+// swap introspects the database and swaps in a new schema if it
+// changed, it returns the schema and the subscribers to call with it or
+// a nil schema when nothing changed
+func (w *Watcher) swap(ctx context.Context) (*DBSchema, *DBInfoDiff, []SchemaChangeFunc, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := w.discover(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	d := Diff(w.info, info)
+	if d.Empty() {
+		return nil, d, nil, nil
+	}
+
+	s, err := w.build(info)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	w.info = info
+	w.schema.Store(s)
+	return s, d, append([]SchemaChangeFunc(nil), w.subs...), nil
+}
+
+// Run reloads the schema on every interval tick or notification
+// until the context is cancelled
+func (w *Watcher) Run(ctx context.Context) error {
+	var tick <-chan time.Time
+
+	if w.conf.Interval > 0 {
+		t := time.NewTicker(w.conf.Interval)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick:
+		case <-w.conf.Notify:
+		}
+
+		if _, err := w.Reload(ctx); err != nil && ctx.Err() == nil && w.conf.OnError != nil {
+			w.conf.OnError(err)
+		}
+	}
+}
+
+// load discovers the database and builds the schema
+func (w *Watcher) load(ctx context.Context) (*DBInfo, *DBSchema, error) {
+	info, err := w.discover(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, err := w.build(info)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, s, nil
+}
+
+// discover runs introspection against the database
+func (w *Watcher) discover(ctx context.Context) (*DBInfo, error) {
+	opts := append([]InfoOption(nil), w.conf.InfoOptions...)
+	if w.conf.Metrics != nil {
+		opts = append(opts, WithInfoMetrics(w.conf.Metrics))
+	}
+
+	info, err := GetDBInfo(ctx, w.db, w.conf.DBType, w.conf.BlockList, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("schema watcher: %w", err)
+	}
+	info.VTables = w.conf.VTables
+	return info, nil
+}
+
+// build creates a schema from a copy of the DBInfo object since
+// NewDBSchema adds the standard functions to the one its given
+func (w *Watcher) build(info *DBInfo) (*DBSchema, error) {
+	ic := *info
+	ic.Functions = append([]DBFunction{}, info.Functions...)
+
+	opts := append([]Option(nil), w.conf.Options...)
+	if w.conf.Metrics != nil {
+		opts = append(opts, WithMetrics(w.conf.Metrics))
+	}
+
+	s, err := NewDBSchema(&ic, w.conf.Aliases, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("schema watcher: %w", err)
+	}
+	return s, nil
+}
+
+// InstallDDLNotify creates a postgres event trigger that sends
+// a notification on the DDLNotifyChannel after every DDL command
+func InstallDDLNotify(db *sql.DB) error {
+	if _, err := db.Exec(postgresDDLNotifyStmt); err != nil {
+		return fmt.Errorf("error installing ddl notify trigger: %w", err)
+	}
+	return nil
+}
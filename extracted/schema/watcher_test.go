@@ -0,0 +1,32 @@
+package schema
+
+import "testing"
+
+func TestWatcherBuildOptions(t *testing.T) {
+	info, err := NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("posts", "id pk", "author notnull").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &Watcher{conf: WatcherConfig{Options: []Option{
+		WithRoles(Role{Name: "user"}),
+		WithVirtualRels(VirtualRel{Table: "posts", Column: "author", FKeyTable: "users", FKeyCol: "id"}),
+	}}}
+
+	// each reload builds with the options again
+	for i := 0; i < 2; i++ {
+		s, err := w.build(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.HasRole("user") {
+			t.Errorf("build %d without the role", i)
+		}
+		if _, err := s.FindPath("posts", "users", ""); err != nil {
+			t.Errorf("build %d without the virtual relationship: %s", i, err)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package sdl generates a GraphQL schema definition from a DBSchema
+package sdl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+// Generate returns a GraphQL SDL with a type for each table of the schema,
+// a field for each column, relationship and aggregate of related rows
+// and a Query type listing every table
+func Generate(s *schema.DBSchema, opts ...Option) (string, error) {
+	sc, err := Build(s, opts...)
+	if err != nil {
+		return "", err
+	}
+	return sc.String(), nil
+}
+
+// Build returns the type system of the schema, the types Generate writes
+func Build(s *schema.DBSchema, opts ...Option) (*Schema, error) {
+	g := &generator{
+		s:       s,
+		types:   typemap.Default,
+		names:   make(map[string]string),
+		enums:   make(map[string][]string),
+		scalars: make(map[string]struct{}),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+
+	var tables []schema.DBTable
+	for _, t := range s.GetTables() {
+		if !t.Blocked {
+			tables = append(tables, t)
+		}
+	}
+	for _, t := range tables {
+		g.names[t.String()] = g.typeName(t)
+	}
+	sort.Slice(tables, func(i, j int) bool {
+		return g.names[tables[i].String()] < g.names[tables[j].String()]
+	})
+
+	var objects []Type
+	for _, t := range tables {
+		ot, err := g.objectType(t)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, ot)
+	}
+	query := g.queryType(tables)
+
+	sc := &Schema{}
+	for _, name := range sortedKeys(g.scalars) {
+		sc.Types = append(sc.Types, Type{Kind: KindScalar, Name: name})
+	}
+	for _, name := range sortedKeys(g.enums) {
+		sc.Types = append(sc.Types, Type{Kind: KindEnum, Name: name, Values: g.enums[name]})
+	}
+	sc.Types = append(sc.Types, objects...)
+	sc.Types = append(sc.Types, query)
+	return sc, nil
+}
+
+// Option configures the generated schema
+type Option func(*generator)
+
+// WithTypes maps the columns to GraphQL types with a mapper holding
+// overrides instead of typemap.Default
+func WithTypes(m *typemap.Mapper) Option {
+	return func(g *generator) {
+		g.types = m
+	}
+}
+
+// generator holds the state of a single SDL generation
+type generator struct {
+	s       *schema.DBSchema
+	types   *typemap.Mapper
+	names   map[string]string   // table to type names
+	enums   map[string][]string // enum types to their values
+	scalars map[string]struct{} // custom scalars used
+}
+
+// objectType returns the type of a table
+func (g *generator) objectType(t schema.DBTable) (Type, error) {
+	ot := Type{Kind: KindObject, Name: g.names[t.String()], Description: t.Comment}
+
+	rels, err := g.s.GetTableRels(t)
+	if err != nil {
+		return ot, err
+	}
+
+	for _, c := range t.Columns {
+		if c.Blocked {
+			continue
+		}
+		ot.Fields = append(ot.Fields, Field{
+			Name:        fieldName(c.Name),
+			Description: c.Comment,
+			Type:        g.columnType(t, c),
+		})
+	}
+
+	for _, r := range rels {
+		rt, ok := g.names[r.Right.Ti.String()]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case r.Many:
+			rt = "[" + rt + "!]!"
+		case r.Left.Col.NotNull && r.Left.Col.FKeyTable != "":
+			rt += "!"
+		}
+		ot.Fields = append(ot.Fields, Field{Name: fieldName(r.Name), Type: rt})
+	}
+
+	aggs, err := g.s.GetRelAggregates(t)
+	if err != nil {
+		return ot, err
+	}
+	for _, a := range aggs {
+		ot.Fields = append(ot.Fields, Field{Name: fieldName(a.Name), Type: g.aggregateType(a)})
+	}
+	return ot, nil
+}
+
+// queryType returns the Query type with a field for each table, function
+// tables take the function inputs as arguments and virtual tables are
+// left out as they can only be reached through a relationship
+func (g *generator) queryType(tables []schema.DBTable) Type {
+	qt := Type{Kind: KindObject, Name: "Query"}
+
+	for _, t := range tables {
+		if t.Type == "virtual" || t.Type == "remote" {
+			continue
+		}
+		f := Field{Name: fieldName(t.Name), Type: "[" + g.names[t.String()] + "!]!"}
+		if t.Schema != g.s.DBSchema() {
+			f.Name = fieldName(t.Schema + "_" + t.Name)
+		}
+
+		if t.Type == "function" {
+			for i, in := range t.Func.Inputs {
+				an := in.Name
+				if an == "" {
+					an = fmt.Sprintf("arg%d", i+1)
+				}
+				f.Args = append(f.Args, Arg{Name: fieldName(an), Type: g.argType(in)})
+			}
+		}
+		qt.Fields = append(qt.Fields, f)
+	}
+	return qt
+}
+
+// argType returns the GraphQL type of a function argument
+func (g *generator) argType(in schema.DBFuncParam) string {
+	c := schema.DBColumn{Name: in.Name, Type: in.Type, Array: in.Array}
+	return g.scalarType(g.types.GraphQL(c))
+}
+
+// typeName returns the type name of a table, tables outside the default
+// schema are prefixed with their schema
+func (g *generator) typeName(t schema.DBTable) string {
+	if t.Schema == "" || t.Schema == g.s.DBSchema() {
+		return pascalCase(t.Name)
+	}
+	return pascalCase(t.Schema + "_" + t.Name)
+}
+
+// columnType returns the GraphQL type of a column
+func (g *generator) columnType(t schema.DBTable, c schema.DBColumn) string {
+	if len(c.Enum) == 0 || !validNames(c.Enum) || (c.PrimaryKey && !c.Array) {
+		return g.scalarType(g.types.GraphQL(c))
+	}
+
+	v := g.enumType(t, c)
+	if c.Array {
+		v = "[" + v + "!]"
+	}
+	if c.NotNull || c.PrimaryKey {
+		v += "!"
+	}
+	return v
+}
+
+// aggregateType returns the GraphQL type of an aggregate, only a count
+// has a value when there are no related rows
+func (g *generator) aggregateType(a schema.RelAggregate) string {
+	switch a.Func {
+	case schema.AggCount:
+		return "Int!"
+	case schema.AggAvg:
+		return "Float"
+	}
+	c := schema.DBColumn{Name: a.Col.Name, Table: a.Col.Table, Type: a.Col.Type, Logical: a.Col.Logical}
+	return g.scalarType(g.types.GraphQL(c))
+}
+
+// enumType returns the name of the enum type of a column, columns with
+// the same values share the enum type of the first one
+func (g *generator) enumType(t schema.DBTable, c schema.DBColumn) string {
+	name := g.names[t.String()] + pascalCase(c.Name)
+
+	for k, values := range g.enums {
+		if equalValues(values, c.Enum) {
+			return k
+		}
+	}
+	g.enums[name] = c.Enum
+	return name
+}
+
+// scalarType returns a GraphQL type of the type mapping and declares
+// the custom scalar it is made of
+func (g *generator) scalarType(v string) string {
+	if name := strings.Trim(v, "[]!"); !typemap.BuiltinScalar(name) {
+		g.scalars[name] = struct{}{}
+	}
+	return v
+}
+
+// writeDescription writes a block string description when there is one
+func writeDescription(sb *strings.Builder, indent, desc string) {
+	if desc == "" {
+		return
+	}
+	desc = strings.ReplaceAll(desc, `"""`, `\"""`)
+	fmt.Fprintf(sb, "%s\"\"\"\n%s%s\n%s\"\"\"\n", indent, indent, desc, indent)
+}
+
+// fieldName replaces the characters that are not allowed in GraphQL names
+func fieldName(s string) string {
+	v := strings.Map(func(r rune) rune {
+		if r == '_' || (r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			return r
+		}
+		return '_'
+	}, s)
+
+	if v == "" || unicode.IsDigit(rune(v[0])) {
+		return "_" + v
+	}
+	return v
+}
+
+// pascalCase returns a name in PascalCase eg. line_items -> LineItems
+func pascalCase(s string) string {
+	var sb strings.Builder
+	up := true
+
+	for _, r := range fieldName(s) {
+		switch {
+		case r == '_':
+			up = true
+		case up:
+			sb.WriteRune(unicode.ToUpper(r))
+			up = false
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "T"
+	}
+	return sb.String()
+}
+
+// validNames returns true if all the values can be used as enum values
+func validNames(values []string) bool {
+	for _, v := range values {
+		if v == "" || fieldName(v) != v {
+			return false
+		}
+		switch v {
+		case "true", "false", "null":
+			return false
+		}
+	}
+	return true
+}
+
+// equalValues returns true if the value lists are the same
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedKeys returns the sorted keys of a map
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
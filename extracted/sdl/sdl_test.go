@@ -0,0 +1,162 @@
+package sdl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/internal/golden"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// blogSchema has a parent and a child side of every kind of
+// relationship: posts and comments belong to users, a user has one
+// profile and a post an array of tags
+func blogSchema(t *testing.T, pkUnique bool) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique").
+		Table("profiles", "id pk", "user_id notnull unique", "bio").
+		Table("posts", "id pk", "user_id notnull", "title text notnull", "tag_ids bigint[] array").
+		Table("comments", "id pk", "post_id", "user_id", "body").
+		Table("tags", "id pk", "name").
+		FK("profiles.user_id", "users.id").
+		FK("posts.user_id", "users.id").
+		FK("posts.tag_ids", "tags.id").
+		FK("comments.post_id", "posts.id").
+		FK("comments.user_id", "users.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the primary keys of an introspected Postgres schema are not
+	// marked as unique keys
+	if !pkUnique {
+		for i, ti := range di.Tables {
+			for j, c := range ti.Columns {
+				if c.PrimaryKey {
+					di.Tables[i].Columns[j].UniqueKey = false
+				}
+			}
+			di.Tables[i].PrimaryCol.UniqueKey = false
+		}
+	}
+
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestGenerate(t *testing.T) {
+	for _, pkUnique := range []bool{true, false} {
+		got, err := Generate(blogSchema(t, pkUnique))
+		if err != nil {
+			t.Fatal(err)
+		}
+		golden.Check(t, "blog.graphql", got)
+	}
+}
+
+func TestRelCardinality(t *testing.T) {
+	for _, pkUnique := range []bool{true, false} {
+		sc, err := Build(blogSchema(t, pkUnique))
+		if err != nil {
+			t.Fatal(err)
+		}
+		testRelCardinality(t, sc)
+	}
+}
+
+func testRelCardinality(t *testing.T, sc *Schema) {
+	t.Helper()
+
+	tests := []struct {
+		typ, field, want string
+	}{
+		{"Posts", "user", "Users!"},
+		{"Comments", "user", "Users"},
+		{"Comments", "post", "Posts"},
+		{"Users", "posts", "[Posts!]!"},
+		{"Users", "comments", "[Comments!]!"},
+		{"Users", "profiles", "Profiles"},
+		{"Profiles", "user", "Users!"},
+		{"Posts", "tag", "[Tags!]!"},
+		{"Tags", "posts", "[Posts!]!"},
+	}
+	for _, tt := range tests {
+		got, ok := fieldType(sc, tt.typ, tt.field)
+		if !ok {
+			t.Errorf("%s.%s not found", tt.typ, tt.field)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s.%s: got %s, want %s", tt.typ, tt.field, got, tt.want)
+		}
+	}
+}
+
+// fieldType returns the type of a field of an object type
+func fieldType(sc *Schema, typ, field string) (string, bool) {
+	ot, ok := sc.Type(typ)
+	if !ok {
+		return "", false
+	}
+	for _, f := range ot.Fields {
+		if f.Name == field {
+			return f.Type, true
+		}
+	}
+	return "", false
+}
+
+// names and comments with quotes are escaped in the schema
+func TestGenerateQuoted(t *testing.T) {
+	di, err := schema.NewTestSchema().
+		Table(`my"notes`, "id pk", `it's`, `say"hi"`, "2nd").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	di.Tables[0].Comment = `Notes with "quotes" and """block""" quotes`
+	for i, c := range di.Tables[0].Columns {
+		if c.Name == `it's` {
+			di.Tables[0].Columns[i].Comment = `the user's "note"`
+		}
+	}
+
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden.Check(t, "quoted.graphql", got)
+}
+
+// the scalars of the columns are of their logical types
+func TestGenerateLogicalTypes(t *testing.T) {
+	schema.RegisterType("test_geo", schema.TypeGeometry)
+	di, err := schema.NewTestSchema().
+		Table("places", "id pk", "area test_geo", "path ltree", "seen timestamptz", "scores numeric(5,2)[] array").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Generate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"scalar Geometry", "scalar Time", "area: Geometry", "path: String", "seen: Time", "scores: [Float]"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("schema without %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sdl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind is the kind of a type of the generated schema
+type Kind string
+
+const (
+	KindScalar Kind = "SCALAR"
+	KindEnum   Kind = "ENUM"
+	KindObject Kind = "OBJECT"
+)
+
+// Schema is the type system generated from a DBSchema, the custom
+// scalars come first then the enums, the object types and Query
+type Schema struct {
+	Types []Type
+}
+
+// Type is a scalar, enum or object type
+type Type struct {
+	Kind        Kind
+	Name        string
+	Description string
+	Fields      []Field  // object fields
+	Values      []string // enum values
+}
+
+// Field is a field of an object type, its type is a GraphQL type
+// reference eg. [Users!]!
+type Field struct {
+	Name        string
+	Description string
+	Type        string
+	Args        []Arg
+}
+
+// Arg is an argument of a field
+type Arg struct {
+	Name string
+	Type string
+}
+
+// Type returns the type with the name
+func (sc *Schema) Type(name string) (Type, bool) {
+	for _, t := range sc.Types {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// String returns the schema in the GraphQL SDL
+func (sc *Schema) String() string {
+	var sb strings.Builder
+
+	for i, t := range sc.Types {
+		if i != 0 {
+			sb.WriteString("\n")
+		}
+
+		switch t.Kind {
+		case KindScalar:
+			fmt.Fprintf(&sb, "scalar %s\n", t.Name)
+
+		case KindEnum:
+			fmt.Fprintf(&sb, "enum %s {\n", t.Name)
+			for _, v := range t.Values {
+				fmt.Fprintf(&sb, "  %s\n", v)
+			}
+			sb.WriteString("}\n")
+
+		case KindObject:
+			writeDescription(&sb, "", t.Description)
+			fmt.Fprintf(&sb, "type %s {\n", t.Name)
+			for _, f := range t.Fields {
+				writeDescription(&sb, "  ", f.Description)
+				if len(f.Args) == 0 {
+					fmt.Fprintf(&sb, "  %s: %s\n", f.Name, f.Type)
+					continue
+				}
+				args := make([]string, len(f.Args))
+				for i, a := range f.Args {
+					args[i] = a.Name + ": " + a.Type
+				}
+				fmt.Fprintf(&sb, "  %s(%s): %s\n", f.Name, strings.Join(args, ", "), f.Type)
+			}
+			sb.WriteString("}\n")
+		}
+	}
+	return sb.String()
+}
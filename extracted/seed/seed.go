@@ -0,0 +1,453 @@
+// Package seed generates test data for the tables of a DBSchema. Tables
+// are filled in foreign key order so every row references rows already
+// generated, and the values follow the type, nullability, uniqueness and
+// enum values of each column
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// DefaultRows is the number of rows of a table unless it is set with
+// WithRows or WithTableRows
+const DefaultRows = 10
+
+// retries is the number of times a row breaking a unique key is made
+// again before it is left out
+const retries = 10
+
+// ValueFunc returns the value of a column for a row, rows are numbered
+// from 0
+type ValueFunc func(r *rand.Rand, row int) any
+
+// Option configures the generated data
+type Option func(*generator)
+
+// WithRows sets the number of rows of every table
+func WithRows(n int) Option {
+	return func(g *generator) {
+		g.rows = n
+	}
+}
+
+// WithTableRows sets the number of rows of a table named 'table' or
+// 'schema.table'
+func WithTableRows(table string, n int) Option {
+	return func(g *generator) {
+		g.tableRows[table] = n
+	}
+}
+
+// WithSeed sets the seed of the random values, the same seed and schema
+// always generate the same data
+func WithSeed(seed int64) Option {
+	return func(g *generator) {
+		g.seed = seed
+	}
+}
+
+// WithValue generates the values of a column with a function, the table
+// is named 'table' or 'schema.table'
+func WithValue(table, column string, fn ValueFunc) Option {
+	return func(g *generator) {
+		g.values[table+"."+column] = fn
+	}
+}
+
+// Table holds the rows generated for a table, the values of each row are
+// in the order of Columns
+type Table struct {
+	Table   schema.DBTable
+	Columns []schema.DBColumn
+	Rows    [][]any
+}
+
+// generator holds the state of a single generation
+type generator struct {
+	s         *schema.DBSchema
+	r         *rand.Rand
+	seed      int64
+	rows      int
+	tableRows map[string]int
+	values    map[string]ValueFunc
+
+	tables   map[string]schema.DBTable
+	deferred map[string]bool             // foreign keys left null to break a cycle
+	done     map[string]map[string][]any // values of the columns of the tables generated
+}
+
+// Generate returns the rows of the tables of a schema in the order they
+// can be inserted. A foreign key references a row of its table picked at
+// random, or a row of its own when it is unique. The foreign keys of a
+// cycle are left null where they can be, a cycle of foreign keys that
+// cannot be null is an error. Views, virtual, remote and function tables
+// are left out
+func Generate(s *schema.DBSchema, opts ...Option) ([]Table, error) {
+	g := &generator{
+		s:         s,
+		seed:      1,
+		rows:      DefaultRows,
+		tableRows: make(map[string]int),
+		values:    make(map[string]ValueFunc),
+		tables:    make(map[string]schema.DBTable),
+		deferred:  make(map[string]bool),
+		done:      make(map[string]map[string][]any),
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	g.r = rand.New(rand.NewSource(g.seed))
+
+	for _, t := range s.GetTables() {
+		if !t.Blocked && (t.Type == "" || t.Type == "table") {
+			g.tables[tableKey(t)] = t
+		}
+	}
+
+	order, err := g.order()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Table, 0, len(order))
+	for _, k := range order {
+		tr, err := g.table(g.tables[k])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, tr)
+	}
+	return out, nil
+}
+
+// tableKey returns the schema qualified name of a table
+func tableKey(t schema.DBTable) string {
+	return t.Schema + "." + t.Name
+}
+
+// parentKey returns the table a foreign key column references
+func parentKey(t schema.DBTable, c schema.DBColumn) string {
+	sn := c.FKeySchema
+	if sn == "" {
+		sn = t.Schema
+	}
+	return sn + "." + c.FKeyTable
+}
+
+// order returns the tables sorted so the tables a table references come
+// before it, foreign keys are deferred to break cycles
+func (g *generator) order() ([]string, error) {
+	keys := make([]string, 0, len(g.tables))
+	for k := range g.tables {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// refs are the foreign key columns of each table to other tables
+	refs := make(map[string][]schema.DBColumn, len(keys))
+	for _, k := range keys {
+		t := g.tables[k]
+		for _, c := range insertColumns(t) {
+			if c.FKeyTable == "" {
+				continue
+			}
+			pk := parentKey(t, c)
+			if _, ok := g.tables[pk]; !ok {
+				if c.NotNull {
+					return nil, fmt.Errorf("%s.%s references %s which is not seeded", t.Name, c.Name, pk)
+				}
+				g.deferred[k+"."+c.Name] = true
+				continue
+			}
+			if pk != k {
+				refs[k] = append(refs[k], c)
+			}
+		}
+	}
+
+	var order []string
+	placed := make(map[string]bool, len(keys))
+
+	for len(order) != len(keys) {
+		progress := false
+		for _, k := range keys {
+			if placed[k] || !g.ready(k, refs[k], placed) {
+				continue
+			}
+			order = append(order, k)
+			placed[k] = true
+			progress = true
+		}
+		if progress {
+			continue
+		}
+
+		// a cycle, the first nullable foreign key left is deferred
+		if !g.breakCycle(keys, refs, placed) {
+			var left []string
+			for _, k := range keys {
+				if !placed[k] {
+					left = append(left, k)
+				}
+			}
+			return nil, fmt.Errorf("foreign key cycle between %s cannot be seeded, none of its foreign keys can be null",
+				strings.Join(left, ", "))
+		}
+	}
+	return order, nil
+}
+
+// ready returns true if the tables a table references are placed
+func (g *generator) ready(k string, refs []schema.DBColumn, placed map[string]bool) bool {
+	t := g.tables[k]
+	for _, c := range refs {
+		if !g.deferred[k+"."+c.Name] && !placed[parentKey(t, c)] {
+			return false
+		}
+	}
+	return true
+}
+
+// breakCycle defers a nullable foreign key between tables not placed
+func (g *generator) breakCycle(keys []string, refs map[string][]schema.DBColumn, placed map[string]bool) bool {
+	for _, k := range keys {
+		if placed[k] {
+			continue
+		}
+		t := g.tables[k]
+		for _, c := range refs[k] {
+			fk := k + "." + c.Name
+			if !c.NotNull && !g.deferred[fk] && !placed[parentKey(t, c)] {
+				g.deferred[fk] = true
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insertColumns returns the columns of a table generated, generated and
+// blocked columns and JSON paths are left out. The columns the database
+// always sets are generated too so foreign keys can reference them
+func insertColumns(t schema.DBTable) []schema.DBColumn {
+	var cols []schema.DBColumn
+	for _, c := range t.Columns {
+		if c.Generated == "" && !c.Blocked && len(c.JSONPath) == 0 {
+			cols = append(cols, c)
+		}
+	}
+	return cols
+}
+
+// table generates the rows of a table
+func (g *generator) table(t schema.DBTable) (Table, error) {
+	k := tableKey(t)
+	tr := Table{Table: t, Columns: insertColumns(t)}
+
+	n, ok := g.tableRows[k]
+	if !ok {
+		n, ok = g.tableRows[t.Name]
+	}
+	if !ok {
+		n = g.rows
+	}
+
+	// a unique foreign key cannot have more rows than the table it
+	// references
+	for _, c := range tr.Columns {
+		if c.FKeyTable != "" && !g.deferred[k+"."+c.Name] && parentKey(t, c) != k && t.IsUnique(c.Name) {
+			n = min(n, len(g.done[parentKey(t, c)][c.FKeyCol]))
+		}
+	}
+
+	sets := uniqueSets(t, tr.Columns)
+	seen := make([]map[string]bool, len(sets))
+	for i := range seen {
+		seen[i] = make(map[string]bool)
+	}
+
+	vals := make(map[string][]any, len(tr.Columns))
+	for i := 0; i < n; i++ {
+		var row []any
+		var err error
+		for try := 0; try < retries; try++ {
+			if row, err = g.row(t, tr.Columns, i, vals); err != nil {
+				return tr, err
+			}
+			if fresh(sets, seen, row) {
+				break
+			}
+			row = nil
+		}
+		if row == nil {
+			continue
+		}
+
+		for _, s := range sets {
+			seen[s.id][s.key(row)] = true
+		}
+		for j, c := range tr.Columns {
+			vals[c.Name] = append(vals[c.Name], row[j])
+		}
+		tr.Rows = append(tr.Rows, row)
+	}
+
+	g.done[k] = vals
+	return tr, nil
+}
+
+// row generates a row, the columns are set before the foreign keys so a
+// foreign key to the table can reference the row itself
+func (g *generator) row(t schema.DBTable, cols []schema.DBColumn, i int, vals map[string][]any) ([]any, error) {
+	k := tableKey(t)
+	row := make([]any, len(cols))
+
+	for j, c := range cols {
+		if fn, ok := g.values[k+"."+c.Name]; ok {
+			row[j] = fn(g.r, i)
+			continue
+		}
+		if fn, ok := g.values[t.Name+"."+c.Name]; ok {
+			row[j] = fn(g.r, i)
+			continue
+		}
+		if c.FKeyTable == "" {
+			row[j] = g.value(t, c, i)
+		}
+	}
+
+	for j, c := range cols {
+		if c.FKeyTable == "" || row[j] != nil || g.hasValue(t, c) {
+			continue
+		}
+		if g.deferred[k+"."+c.Name] {
+			continue
+		}
+
+		pk := parentKey(t, c)
+		var keys []any
+		if pk == k {
+			// a row of a tree is a root now and then
+			if !c.NotNull && (i == 0 || g.r.Intn(4) == 0) {
+				continue
+			}
+			// the rows before this one, a row that must have a parent
+			// can be its own
+			keys = vals[c.FKeyCol]
+			if c.NotNull {
+				keys = append(keys[:len(keys):len(keys)], ownValue(cols, row, c.FKeyCol))
+			}
+		} else {
+			keys = g.done[pk][c.FKeyCol]
+		}
+
+		switch {
+		case len(keys) == 0 && c.NotNull:
+			return nil, fmt.Errorf("%s.%s references %s which has no rows", t.Name, c.Name, pk)
+		case len(keys) == 0:
+		case pk != k && t.IsUnique(c.Name):
+			row[j] = keys[i]
+		case !c.NotNull && g.r.Intn(10) == 0:
+		default:
+			row[j] = keys[g.r.Intn(len(keys))]
+		}
+	}
+	return row, nil
+}
+
+// hasValue returns true if a column has a ValueFunc
+func (g *generator) hasValue(t schema.DBTable, c schema.DBColumn) bool {
+	_, ok := g.values[tableKey(t)+"."+c.Name]
+	if !ok {
+		_, ok = g.values[t.Name+"."+c.Name]
+	}
+	return ok
+}
+
+// ownValue returns the value of a column of a row
+func ownValue(cols []schema.DBColumn, row []any, name string) any {
+	for j, c := range cols {
+		if c.Name == name {
+			return row[j]
+		}
+	}
+	return nil
+}
+
+// uniqueSet is a set of columns that is unique, by their index in the
+// columns generated
+type uniqueSet struct {
+	id   int
+	cols []int
+}
+
+// key returns the values of the set in a row, nil if one of them is
+// null as nulls are not equal
+func (s uniqueSet) key(row []any) string {
+	var sb strings.Builder
+	for _, j := range s.cols {
+		if row[j] == nil {
+			return ""
+		}
+		fmt.Fprintf(&sb, "%v\x00", row[j])
+	}
+	return sb.String()
+}
+
+// uniqueSets returns the primary key, unique columns and unique indexes
+// of a table
+func uniqueSets(t schema.DBTable, cols []schema.DBColumn) []uniqueSet {
+	index := make(map[string]int, len(cols))
+	for j, c := range cols {
+		index[c.Name] = j
+	}
+
+	var names [][]string
+	var pk []string
+	for _, c := range cols {
+		if c.PrimaryKey {
+			pk = append(pk, c.Name)
+		}
+		if c.UniqueKey && !c.PrimaryKey {
+			names = append(names, []string{c.Name})
+		}
+	}
+	if len(pk) != 0 {
+		names = append(names, pk)
+	}
+	for _, idx := range t.Indexes {
+		if (idx.Unique || idx.Primary) && idx.Predicate == "" {
+			names = append(names, idx.Columns)
+		}
+	}
+
+	var sets []uniqueSet
+next:
+	for _, ns := range names {
+		s := uniqueSet{id: len(sets)}
+		for _, n := range ns {
+			j, ok := index[n]
+			if !ok {
+				continue next
+			}
+			s.cols = append(s.cols, j)
+		}
+		sets = append(sets, s)
+	}
+	return sets
+}
+
+// fresh returns true if a row does not repeat the values of a unique set
+func fresh(sets []uniqueSet, seen []map[string]bool, row []any) bool {
+	for _, s := range sets {
+		if k := s.key(row); k != "" && seen[s.id][k] {
+			return false
+		}
+	}
+	return true
+}
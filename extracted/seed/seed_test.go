@@ -0,0 +1,237 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// shopSchema has orders of users, a profile for each user, categories
+// in a tree and users with a status out of an enum
+func shopSchema(t *testing.T) *schema.DBSchema {
+	t.Helper()
+	di, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull unique", "status text notnull").
+		Table("orders", "id pk", "user_id notnull", "qty integer notnull", "note text").
+		Table("profiles", "id pk", "user_id notnull unique").
+		Table("categories", "id pk", "parent_id", "name varchar(8) notnull").
+		FK("orders.user_id", "users.id").
+		FK("profiles.user_id", "users.id").
+		FK("categories.parent_id", "categories.id").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, c := range di.Tables[0].Columns {
+		if c.Name == "status" {
+			di.Tables[0].Columns[i].Enum = []string{"active", "banned"}
+		}
+	}
+	s, err := schema.NewDBSchema(di, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// values returns the values of a column of a table generated
+func values(t *testing.T, tables []Table, table, col string) []any {
+	t.Helper()
+	for _, tr := range tables {
+		if tr.Table.Name != table {
+			continue
+		}
+		var vals []any
+		for j, c := range tr.Columns {
+			if c.Name != col {
+				continue
+			}
+			for _, row := range tr.Rows {
+				vals = append(vals, row[j])
+			}
+			return vals
+		}
+	}
+	t.Fatalf("no column %s.%s", table, col)
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	tables, err := Generate(shopSchema(t), WithTableRows("profiles", 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var order []string
+	for _, tr := range tables {
+		order = append(order, tr.Table.Name)
+	}
+	if got := strings.Join(order, " "); got != "categories users orders profiles" {
+		t.Errorf("got order %s", got)
+	}
+
+	users := make(map[any]bool)
+	for _, id := range values(t, tables, "users", "id") {
+		users[id] = true
+	}
+	if len(users) != DefaultRows {
+		t.Errorf("got %d users", len(users))
+	}
+	for _, id := range values(t, tables, "orders", "user_id") {
+		if !users[id] {
+			t.Errorf("got an order of user %v which is not seeded", id)
+		}
+	}
+
+	// a user has a single profile
+	profiles := values(t, tables, "profiles", "user_id")
+	seen := make(map[any]bool)
+	for _, id := range profiles {
+		if seen[id] || !users[id] {
+			t.Errorf("got a profile of user %v", id)
+		}
+		seen[id] = true
+	}
+	if len(profiles) != DefaultRows {
+		t.Errorf("got %d profiles, want one for each user", len(profiles))
+	}
+
+	emails := make(map[any]bool)
+	for _, v := range values(t, tables, "users", "email") {
+		if emails[v] {
+			t.Errorf("got email %v twice", v)
+		}
+		emails[v] = true
+	}
+	for _, v := range values(t, tables, "users", "status") {
+		if v != "active" && v != "banned" {
+			t.Errorf("got status %v", v)
+		}
+	}
+	for _, v := range values(t, tables, "categories", "name") {
+		if s, _ := v.(string); s == "" || len(s) > 8 {
+			t.Errorf("got name %q of a varchar(8)", v)
+		}
+	}
+
+	// a category has a parent generated before it or none
+	ids := values(t, tables, "categories", "id")
+	for i, p := range values(t, tables, "categories", "parent_id") {
+		if p == nil {
+			continue
+		}
+		found := false
+		for _, id := range ids[:i+1] {
+			found = found || id == p
+		}
+		if !found {
+			t.Errorf("got parent %v of category %v", p, ids[i])
+		}
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	s := shopSchema(t)
+	a, err := Generate(s, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Generate(s, WithSeed(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(Statements(a), Statements(b)) {
+		t.Error("got other rows with the same seed")
+	}
+	c, err := Generate(s, WithSeed(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reflect.DeepEqual(Statements(a), Statements(c)) {
+		t.Error("got the same rows with another seed")
+	}
+}
+
+func TestGenerateOptions(t *testing.T) {
+	tables, err := Generate(shopSchema(t), WithRows(3), WithTableRows("public.orders", 5),
+		WithValue("users", "email", func(r *rand.Rand, row int) any {
+			return fmt.Sprintf("user%d@test", row)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := values(t, tables, "users", "email"); !reflect.DeepEqual(got, []any{"user0@test", "user1@test", "user2@test"}) {
+		t.Errorf("got emails %v", got)
+	}
+	if got := len(values(t, tables, "orders", "id")); got != 5 {
+		t.Errorf("got %d orders", got)
+	}
+}
+
+func TestGenerateCycle(t *testing.T) {
+	// teams have a nullable owner which is left null
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "team_id notnull").
+		Table("teams", "id pk", "owner_id").
+		FK("users.team_id", "teams.id").
+		FK("teams.owner_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := Generate(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tables[0].Table.Name != "teams" {
+		t.Errorf("got %s first", tables[0].Table.Name)
+	}
+	for _, v := range values(t, tables, "teams", "owner_id") {
+		if v != nil {
+			t.Errorf("got owner %v", v)
+		}
+	}
+
+	s, err = schema.NewTestSchema().
+		Table("users", "id pk", "team_id notnull").
+		Table("teams", "id pk", "owner_id notnull").
+		FK("users.team_id", "teams.id").
+		FK("teams.owner_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Generate(s); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("got %v, want an error for the cycle", err)
+	}
+}
+
+// a time without a time zone is written without an offset
+func TestGenerateTemporal(t *testing.T) {
+	s, err := schema.NewTestSchema().
+		Table("events", "id pk", "at timestamptz notnull", "local timestamp notnull", "day date notnull", "opens time notnull").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := Generate(s, WithRows(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := tables[0].Rows[0]
+	if _, ok := row[1].(time.Time); !ok {
+		t.Errorf("got %T %v of a timestamptz", row[1], row[1])
+	}
+	for i, layout := range map[int]string{2: "2006-01-02 15:04:05", 3: "2006-01-02", 4: "15:04:05"} {
+		if v, _ := row[i].(string); v == "" {
+			t.Errorf("got %T %v of a %s", row[i], row[i], tables[0].Columns[i].Type)
+		} else if _, err := time.Parse(layout, v); err != nil {
+			t.Error(err)
+		}
+	}
+}
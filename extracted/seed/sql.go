@@ -0,0 +1,181 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+// batchRows is the number of rows of an insert statement
+const batchRows = 500
+
+// Statements returns the Postgres statements inserting the rows of the
+// tables, in their order. The values are written as literals so the
+// statements can be saved to a file. The sequences of the identity and
+// serial columns are moved past the values inserted
+//
+//	INSERT INTO "public"."users" ("id", "email") OVERRIDING SYSTEM VALUE VALUES
+//	  (1, 'ada.lovelace1@example.com'),
+//	  (2, 'alan.turing2@example.com')
+func Statements(tables []Table) []string {
+	var stmts []string
+	for _, t := range tables {
+		if len(t.Rows) == 0 || len(t.Columns) == 0 {
+			continue
+		}
+		cols := make([]string, len(t.Columns))
+		override := false
+		for i, c := range t.Columns {
+			cols[i] = quoteIdent(c.Name)
+			override = override || c.Identity == "always"
+		}
+
+		for i := 0; i < len(t.Rows); i += batchRows {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "INSERT INTO %s (%s)", tableRef(t.Table), strings.Join(cols, ", "))
+			if override {
+				sb.WriteString(" OVERRIDING SYSTEM VALUE")
+			}
+			sb.WriteString(" VALUES")
+			for j, row := range t.Rows[i:min(i+batchRows, len(t.Rows))] {
+				if j != 0 {
+					sb.WriteByte(',')
+				}
+				sb.WriteString("\n  (")
+				for k, v := range row {
+					if k != 0 {
+						sb.WriteString(", ")
+					}
+					sb.WriteString(literal(v))
+				}
+				sb.WriteByte(')')
+			}
+			stmts = append(stmts, sb.String())
+		}
+
+		for _, c := range t.Columns {
+			if (c.Identity == "" && c.Sequence == "") || typemap.Logical(c) != schema.TypeInt {
+				continue
+			}
+			stmts = append(stmts, fmt.Sprintf("SELECT setval(pg_get_serial_sequence(%s, %s), (SELECT max(%s) FROM %s))",
+				quoteLiteral(tableRef(t.Table)), quoteLiteral(c.Name), quoteIdent(c.Name), tableRef(t.Table)))
+		}
+	}
+	return stmts
+}
+
+// WriteSQL writes the statements of the tables to a file, eg. seed.sql
+func WriteSQL(w io.Writer, tables []Table) error {
+	for _, s := range Statements(tables) {
+		if _, err := io.WriteString(w, s+";\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert inserts the rows of the tables in a transaction, none of them
+// are inserted if a statement fails
+func Insert(ctx context.Context, db *sql.DB, tables []Table) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range Statements(tables) {
+		if _, err := tx.ExecContext(ctx, s); err != nil {
+			return fmt.Errorf("error seeding: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// literal returns the SQL literal of a value generated
+func literal(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return quoteLiteral(v)
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return quoteLiteral(v.Format(time.RFC3339))
+	case []byte:
+		return `'\x` + hex.EncodeToString(v) + `'`
+	case []any:
+		return quoteLiteral(arrayLiteral(v))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return quoteLiteral(fmt.Sprint(v))
+	}
+	return quoteLiteral(string(b))
+}
+
+// arrayLiteral returns the text of an array, it takes the type of the
+// column where an ARRAY[...] of text is not cast to an array of enums
+func arrayLiteral(items []any) string {
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, v := range items {
+		if i != 0 {
+			sb.WriteByte(',')
+		}
+		var s string
+		switch v := v.(type) {
+		case nil:
+			sb.WriteString("NULL")
+			continue
+		case string:
+			s = v
+		case time.Time:
+			s = v.Format(time.RFC3339)
+		case []byte:
+			s = `\x` + hex.EncodeToString(v)
+		default:
+			s = fmt.Sprint(v)
+		}
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		s = strings.ReplaceAll(s, `"`, `\"`)
+		sb.WriteString(`"` + s + `"`)
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// tableRef returns the schema qualified name of a table
+func tableRef(t schema.DBTable) string {
+	if t.Schema == "" {
+		return quoteIdent(t.Name)
+	}
+	return quoteIdent(t.Schema) + "." + quoteIdent(t.Name)
+}
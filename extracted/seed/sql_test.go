@@ -0,0 +1,61 @@
+package seed
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+func TestStatements(t *testing.T) {
+	cols := []schema.DBColumn{
+		{Name: "id", Type: "bigint", PrimaryKey: true, Identity: "always"},
+		{Name: "name", Type: "text"},
+		{Name: "tags", Type: "text[]", Array: true},
+		{Name: "at", Type: "timestamptz"},
+		{Name: "raw", Type: "bytea"},
+		{Name: "meta", Type: "jsonb"},
+		{Name: "ok", Type: "boolean"},
+	}
+	at := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	tables := []Table{
+		{Table: schema.NewDBTable("public", "users", "", cols), Columns: cols, Rows: [][]any{
+			{1, "it's", []any{"a", `b"c`, nil}, at, []byte{0xca, 0xfe}, map[string]any{"k": 1}, true},
+			{2, nil, []any{}, nil, nil, nil, false},
+		}},
+		{Table: schema.NewDBTable("public", "empty", "", cols), Columns: cols},
+	}
+
+	want := []string{
+		`INSERT INTO "public"."users" ("id", "name", "tags", "at", "raw", "meta", "ok") OVERRIDING SYSTEM VALUE VALUES` +
+			"\n  (1, 'it''s', '{\"a\",\"b\\\"c\",NULL}', '2023-05-01T12:00:00Z', '\\xcafe', '{\"k\":1}', true)," +
+			"\n  (2, NULL, '{}', NULL, NULL, NULL, false)",
+		`SELECT setval(pg_get_serial_sequence('"public"."users"', 'id'), (SELECT max("id") FROM "public"."users"))`,
+	}
+	got := Statements(tables)
+	if strings.Join(got, "\n\n") != strings.Join(want, "\n\n") {
+		t.Errorf("got\n%s\nwant\n%s", strings.Join(got, "\n\n"), strings.Join(want, "\n\n"))
+	}
+
+	var sb strings.Builder
+	if err := WriteSQL(&sb, tables); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != want[0]+";\n\n"+want[1]+";\n\n" {
+		t.Errorf("got file\n%s", sb.String())
+	}
+}
+
+// a statement inserts at most batchRows rows
+func TestStatementsBatch(t *testing.T) {
+	cols := []schema.DBColumn{{Name: "id", Type: "bigint", PrimaryKey: true}}
+	tr := Table{Table: schema.NewDBTable("", "ids", "", cols), Columns: cols}
+	for i := 0; i < batchRows+1; i++ {
+		tr.Rows = append(tr.Rows, []any{i})
+	}
+	got := Statements([]Table{tr})
+	if len(got) != 2 || !strings.HasPrefix(got[1], `INSERT INTO "ids" ("id") VALUES`+"\n  (500)") {
+		t.Errorf("got %d statements, the last\n%s", len(got), got[len(got)-1])
+	}
+}
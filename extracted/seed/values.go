@@ -0,0 +1,219 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+	"github.com/yourusername/graphjin-extracted/typemap"
+)
+
+// base is the latest time generated, times are in the year before it so
+// the same seed gives the same data on any day
+var base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken", "Frances", "Edsger"}
+	lastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson", "Allen", "Dijkstra"}
+	cities     = []string{"Lisbon", "Oslo", "Nairobi", "Osaka", "Lima", "Toronto", "Hanoi", "Dublin", "Perth", "Quito"}
+	countries  = []string{"Portugal", "Norway", "Kenya", "Japan", "Peru", "Canada", "Vietnam", "Ireland", "Australia", "Ecuador"}
+	colors     = []string{"red", "green", "blue", "black", "white", "orange", "purple", "teal"}
+	words      = []string{
+		"amber", "birch", "cedar", "delta", "ember", "fjord", "grove", "harbor", "iris", "juniper",
+		"kelp", "lumen", "maple", "nova", "orbit", "pine", "quartz", "river", "slate", "tide",
+	}
+)
+
+// value returns a value for a column that is not a foreign key, unique
+// columns get a value of their own for each row
+func (g *generator) value(t schema.DBTable, c schema.DBColumn, row int) any {
+	if !c.NotNull && !c.PrimaryKey && !c.UniqueKey && g.r.Intn(10) == 0 {
+		return nil
+	}
+	unique := c.PrimaryKey || c.UniqueKey || t.IsUnique(c.Name)
+
+	if typemap.IsArray(c) {
+		// the logical type of an array is the type of its elements
+		ec := c
+		ec.Array, ec.Type = false, strings.TrimSuffix(strings.TrimSpace(c.Type), "[]")
+		if c.ElemType != "" {
+			ec.Type = c.ElemType
+		}
+		items := make([]any, 1+g.r.Intn(3))
+		for i := range items {
+			items[i] = g.scalar(ec, row, false)
+		}
+		return items
+	}
+	return g.scalar(c, row, unique)
+}
+
+// scalar returns a value that is not an array
+func (g *generator) scalar(c schema.DBColumn, row int, unique bool) any {
+	r := g.r
+	if len(c.Enum) != 0 {
+		if unique {
+			return c.Enum[row%len(c.Enum)]
+		}
+		return c.Enum[r.Intn(len(c.Enum))]
+	}
+
+	name := strings.ToLower(c.Name)
+
+	switch typemap.Logical(c) {
+	case schema.TypeInt:
+		switch {
+		case unique:
+			return row + 1
+		case strings.Contains(name, "age"):
+			return 18 + r.Intn(70)
+		case strings.Contains(name, "qty"), strings.Contains(name, "quantity"), strings.Contains(name, "count"):
+			return 1 + r.Intn(20)
+		}
+		return 1 + r.Intn(1000)
+	case schema.TypeFloat:
+		if unique {
+			return float64(row + 1)
+		}
+		return float64(r.Intn(100000)) / 100
+	case schema.TypeBoolean:
+		return r.Intn(2) == 0
+	case schema.TypeJSON:
+		return map[string]any{"id": row + 1, "tag": pick(r, words)}
+	case schema.TypeTime:
+		return base.Add(-time.Duration(r.Int63n(int64(365 * 24 * time.Hour)))).Truncate(time.Second)
+	case schema.TypeTimestamp:
+		// a timestamp without a time zone is written without an offset
+		return base.Add(-time.Duration(r.Int63n(int64(365 * 24 * time.Hour)))).Format("2006-01-02 15:04:05")
+	case schema.TypeTimeOfDay:
+		return time.Time{}.Add(time.Duration(r.Int63n(int64(24 * time.Hour)))).Format("15:04:05")
+	case schema.TypeDate:
+		return base.AddDate(0, 0, -r.Intn(365)).Format("2006-01-02")
+	case schema.TypeUUID:
+		return uuid(r)
+	case schema.TypeBytes:
+		b := make([]byte, 8)
+		r.Read(b)
+		return b
+	case schema.TypeGeometry:
+		srid := c.SRID
+		if srid == 0 {
+			srid = 4326
+		}
+		return fmt.Sprintf("SRID=%d;POINT(%.5f %.5f)", srid, r.Float64()*360-180, r.Float64()*180-90)
+	}
+	return fitString(g.text(name, row, unique), c.Type, unique, row)
+}
+
+// text returns a string for a column, its name hints at what it holds
+func (g *generator) text(name string, row int, unique bool) string {
+	r := g.r
+	first, last := pick(r, firstNames), pick(r, lastNames)
+
+	var v string
+	switch {
+	case strings.Contains(name, "email"):
+		v = strings.ToLower(first + "." + last)
+		if unique {
+			v += strconv.Itoa(row + 1)
+		}
+		return v + "@example.com"
+	case strings.Contains(name, "first_name"), name == "firstname":
+		v = first
+	case strings.Contains(name, "last_name"), name == "lastname", name == "surname":
+		v = last
+	case strings.Contains(name, "username"), name == "login", name == "handle":
+		v = strings.ToLower(first) + "_" + strings.ToLower(last)
+	case strings.Contains(name, "name"):
+		v = first + " " + last
+	case strings.Contains(name, "phone"), strings.Contains(name, "mobile"):
+		v = fmt.Sprintf("+1-555-%03d-%04d", r.Intn(1000), r.Intn(10000))
+	case strings.Contains(name, "url"), strings.Contains(name, "website"), strings.Contains(name, "link"):
+		v = "https://example.com/" + pick(r, words)
+	case strings.Contains(name, "city"):
+		v = pick(r, cities)
+	case strings.Contains(name, "country"):
+		v = pick(r, countries)
+	case strings.Contains(name, "color"), strings.Contains(name, "colour"):
+		v = pick(r, colors)
+	case strings.Contains(name, "slug"):
+		v = pick(r, words) + "-" + pick(r, words)
+	case strings.Contains(name, "zip"), strings.Contains(name, "postal"):
+		v = fmt.Sprintf("%05d", r.Intn(100000))
+	case strings.Contains(name, "address"), strings.Contains(name, "street"):
+		v = fmt.Sprintf("%d %s Street", 1+r.Intn(999), capitalize(pick(r, words)))
+	case strings.Contains(name, "password"), strings.Contains(name, "hash"), strings.Contains(name, "token"):
+		v = fmt.Sprintf("%016x", r.Uint64())
+	case strings.Contains(name, "title"), strings.Contains(name, "subject"):
+		v = capitalize(pick(r, words)) + " " + capitalize(pick(r, words))
+	case strings.Contains(name, "description"), strings.Contains(name, "body"),
+		strings.Contains(name, "content"), strings.Contains(name, "bio"), strings.Contains(name, "comment"):
+		ws := make([]string, 8+r.Intn(8))
+		for i := range ws {
+			ws[i] = pick(r, words)
+		}
+		ws[0] = capitalize(ws[0])
+		v = strings.Join(ws, " ") + "."
+	default:
+		v = pick(r, words)
+	}
+
+	if unique {
+		v += "-" + strconv.Itoa(row+1)
+	}
+	return v
+}
+
+// fitString cuts a string to the length of a varchar(n) or char(n)
+// column, the number that makes a unique value unique is kept
+func fitString(v, dbType string, unique bool, row int) string {
+	n := typeLength(dbType)
+	if n <= 0 || len(v) <= n {
+		return v
+	}
+	if !unique {
+		return v[:n]
+	}
+	suffix := strconv.Itoa(row + 1)
+	if len(suffix) >= n {
+		return suffix[len(suffix)-n:]
+	}
+	return v[:n-len(suffix)] + suffix
+}
+
+// typeLength returns the length of a type such as varchar(20), 0 if it
+// has none
+func typeLength(dbType string) int {
+	i := strings.IndexByte(dbType, '(')
+	j := strings.IndexByte(dbType, ')')
+	if i == -1 || j < i {
+		return 0
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(dbType[i+1 : j]))
+	return n
+}
+
+// capitalize returns a word with its first letter in upper case
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// pick returns a random item of a list
+func pick(r *rand.Rand, list []string) string {
+	return list[r.Intn(len(list))]
+}
+
+// uuid returns a random version 4 UUID
+func uuid(r *rand.Rand) string {
+	var b [16]byte
+	r.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -1,13 +1,14 @@
 package extracted
 
 import (
-	"database/sql"
+	"testing"
+
 	_ "github.com/lib/pq" // postgres driver
 
 	"github.com/yourusername/graphjin-extracted/schema"
 )
 
-func TestUsageExample() {
+func TestUsageExample(t *testing.T) {
 	// 1. Discover schema
 	// For demonstration purposes, we'll use a dummy DBInfo.
 	// In a real scenario, you'd connect to a database:
@@ -16,7 +17,7 @@ func TestUsageExample() {
 	// 	panic(err)
 	// }
 	// defer db.Close()
-	// dbInfo, err := schema.GetDBInfo(db, "postgres", nil)
+	// dbInfo, err := schema.GetDBInfo(context.Background(), db, "postgres", nil)
 	// if err != nil {
 	// 	panic(err)
 	// }
@@ -27,13 +28,13 @@ func TestUsageExample() {
 	// 2. Build relationship graph
 	dbSchema, err := schema.NewDBSchema(dbInfo, nil)
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
 	// 3. Find path between tables
 	path, err := dbSchema.FindPath("comments", "users", "")
 	if err != nil {
-		panic(err)
+		t.Fatal(err)
 	}
 
 	// 4. Use relationship info
@@ -41,7 +42,7 @@ func TestUsageExample() {
 
 	// For a real test, you'd assert on the values of rel
 	// For this example, we'll just print them.
-	println("Relationship Type:", rel.Type)
-	println("Left Table:", rel.LT.Name, "Column:", rel.LC.Name)
-	println("Right Table:", rel.RT.Name, "Column:", rel.RC.Name)
+	t.Log("Relationship Type:", rel.Type)
+	t.Log("Left Table:", rel.Left.Ti.Name, "Column:", rel.Left.Col.Name)
+	t.Log("Right Table:", rel.Right.Ti.Name, "Column:", rel.Right.Col.Name)
 }
@@ -0,0 +1,311 @@
+// Package txn runs mutations a write at a time in a transaction, each
+// write in a savepoint of its own with hooks run before and after it.
+// A failed or vetoed write is reported with the nested entity it wrote
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+)
+
+// ErrSkipped is the error of a write that did not run as a write it
+// depends on failed, its parent or a nested write run before it
+var ErrSkipped = errors.New("skipped as a write it depends on failed")
+
+// Step is a write of a mutation as seen by the hooks
+type Step struct {
+	ID       int32 // of the write in QCode.Mutates
+	ParentID int32 // -1 for root writes
+	Type     qcode.MType
+	Table    string
+
+	// Rows is the number of rows written, zero before the write runs,
+	// and Written the temporary table holding them
+	Rows    int64
+	Written string
+}
+
+// Hook runs before or after a write in the savepoint of the write, an
+// error vetoes the write and rolls it back. An after hook can read the
+// rows written from Step.Written
+//
+//	SELECT count(*) FROM "orders_m0" WHERE total > 10000
+type Hook func(ctx context.Context, tx *sql.Tx, s Step) error
+
+// Option configures a Coordinator
+type Option func(*Coordinator)
+
+// WithBefore adds a hook run before each write
+func WithBefore(h Hook) Option {
+	return func(co *Coordinator) {
+		co.before = append(co.before, h)
+	}
+}
+
+// WithAfter adds a hook run after each write
+func WithAfter(h Hook) Option {
+	return func(co *Coordinator) {
+		co.after = append(co.after, h)
+	}
+}
+
+// WithPartial commits the writes that succeed when others fail, the
+// result is returned with an *Error listing the failed ones. Without it
+// the first failure rolls back the mutation
+func WithPartial() Option {
+	return func(co *Coordinator) {
+		co.partial = true
+	}
+}
+
+// StepError is the failure of a write
+type StepError struct {
+	Step Step
+	Err  error
+}
+
+// Error returns the error of the write with the write and its table
+func (e *StepError) Error() string {
+	return fmt.Sprintf("%s %s (write %d): %s", typeName(e.Step.Type), e.Step.Table, e.Step.ID, e.Err)
+}
+
+// Unwrap returns the error of the write
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
+
+// Error is returned when writes of a mutation failed
+type Error struct {
+	Steps []*StepError
+}
+
+// Error returns the errors of the failed writes
+func (e *Error) Error() string {
+	msgs := make([]string, len(e.Steps))
+	for i, se := range e.Steps {
+		msgs[i] = se.Error()
+	}
+	return "mutation failed: " + strings.Join(msgs, "; ")
+}
+
+// Coordinator runs mutations a write at a time
+type Coordinator struct {
+	db      *sql.DB
+	qcc     *qcode.Compiler
+	pcc     *psql.Compiler
+	before  []Hook
+	after   []Hook
+	partial bool
+}
+
+// New returns a coordinator running mutations on the database
+func New(db *sql.DB, qcc *qcode.Compiler, pcc *psql.Compiler, opts ...Option) *Coordinator {
+	co := &Coordinator{db: db, qcc: qcc, pcc: pcc}
+	for _, fn := range opts {
+		fn(co)
+	}
+	return co
+}
+
+// Mutate runs a mutation with the variables and returns its JSON result
+func (co *Coordinator) Mutate(ctx context.Context, query []byte, opName string, vars map[string]json.RawMessage) (json.RawMessage, error) {
+	return co.mutate(ctx, psql.Session{}, false, query, opName, vars)
+}
+
+// MutateSession runs a mutation as a session, see prepared.QuerySession
+func (co *Coordinator) MutateSession(
+	ctx context.Context,
+	sess psql.Session,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (json.RawMessage, error) {
+	return co.mutate(ctx, sess, true, query, opName, vars)
+}
+
+// mutate runs a mutation, the filter variables are read from the
+// session when bound is true and from the variables otherwise
+func (co *Coordinator) mutate(
+	ctx context.Context,
+	sess psql.Session,
+	bound bool,
+	query []byte,
+	opName string,
+	vars map[string]json.RawMessage,
+) (json.RawMessage, error) {
+	qc, err := co.qcc.CompileRole(query, opName, sess.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := co.pcc.CompileSteps(qc, sess.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	argsOf := func(md psql.Metadata) ([]interface{}, error) {
+		if bound {
+			return psql.SessionArgs(qc, md, vars, sess)
+		}
+		return psql.Args(qc, md, vars)
+	}
+
+	tx, err := co.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if set, setArgs := psql.SessionSQL(sess); set != "" {
+		if _, err := tx.ExecContext(ctx, set, setArgs...); err != nil {
+			return nil, err
+		}
+	}
+
+	var failed []*StepError
+	done := make(map[int32]bool, len(st.Steps)) // writes run, true if ok
+
+	for _, ps := range st.Steps {
+		m := &qc.Mutates[ps.ID]
+		s := Step{ID: m.ID, ParentID: m.ParentID, Type: m.Type, Table: m.Ti.Name, Written: ps.Table}
+
+		if _, err := tx.ExecContext(ctx, ps.Setup); err != nil {
+			return nil, err
+		}
+
+		err := ErrSkipped
+		if !dependsOnFailed(m, done) {
+			err = co.step(ctx, tx, ps, &s, argsOf)
+		}
+		done[m.ID] = err == nil
+		if err == nil {
+			continue
+		}
+
+		// a cancelled request ends the mutation
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		se := &StepError{Step: s, Err: err}
+		if !co.partial {
+			return nil, &Error{Steps: []*StepError{se}}
+		}
+		failed = append(failed, se)
+	}
+
+	args, err := argsOf(st.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if err := tx.QueryRowContext(ctx, st.SQL, args...).Scan(&data); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if len(failed) != 0 {
+		return data, &Error{Steps: failed}
+	}
+	return data, nil
+}
+
+// step runs a write and its hooks in a savepoint, the savepoint is
+// rolled back when any of them fails
+func (co *Coordinator) step(
+	ctx context.Context,
+	tx *sql.Tx,
+	ps psql.Step,
+	s *Step,
+	argsOf func(psql.Metadata) ([]interface{}, error),
+) error {
+	args, err := argsOf(ps.Metadata)
+	if err != nil {
+		return err
+	}
+
+	sp := `"__sp_` + strconv.Itoa(int(ps.ID)) + `"`
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT `+sp); err != nil {
+		return err
+	}
+
+	if err = co.write(ctx, tx, ps, s, args); err != nil {
+		if _, rerr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT `+sp); rerr != nil {
+			return rerr
+		}
+		s.Rows = 0
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT `+sp)
+	return err
+}
+
+// write runs the before hooks, the write and the after hooks
+func (co *Coordinator) write(ctx context.Context, tx *sql.Tx, ps psql.Step, s *Step, args []interface{}) error {
+	for _, h := range co.before {
+		if err := h(ctx, tx, *s); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, ps.SQL, args...)
+	if err != nil {
+		return err
+	}
+	if s.Rows, err = res.RowsAffected(); err != nil {
+		return err
+	}
+
+	for _, h := range co.after {
+		if err := h(ctx, tx, *s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependsOnFailed returns true when the parent of a write or a nested
+// write run before it failed
+func dependsOnFailed(m *qcode.Mutate, done map[int32]bool) bool {
+	if m.ParentID != -1 {
+		if ok, ran := done[m.ParentID]; ran && !ok {
+			return true
+		}
+	}
+	for _, id := range m.Children {
+		if ok, ran := done[id]; ran && !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// typeName returns the name of the type of a write
+func typeName(t qcode.MType) string {
+	switch t {
+	case qcode.MTInsert:
+		return "insert"
+	case qcode.MTUpdate:
+		return "update"
+	case qcode.MTUpsert:
+		return "upsert"
+	case qcode.MTDelete:
+		return "delete"
+	case qcode.MTConnect:
+		return "connect"
+	case qcode.MTDisconnect:
+		return "disconnect"
+	}
+	return "write"
+}
@@ -0,0 +1,210 @@
+package txn
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/psql"
+	"github.com/yourusername/graphjin-extracted/qcode"
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// fakeDB is a database keeping the statements it ran, the writes to a
+// table in fail return an error and every query returns {}
+type fakeDB struct {
+	mu    sync.Mutex
+	stmts []string
+	fail  string
+}
+
+func (d *fakeDB) ran(stmt string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stmts = append(d.stmts, stmt)
+}
+
+// count returns the number of statements run starting with a prefix
+func (d *fakeDB) count(prefix string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, s := range d.stmts {
+		if strings.HasPrefix(s, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *fakeDB) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{d}, nil }
+func (d *fakeDB) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{ d *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+func (c fakeConn) Close() error              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error) { return fakeTx(c), nil }
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.d.ran(query)
+	if c.d.fail != "" && strings.Contains(query, `INSERT INTO "public"."`+c.d.fail+`"`) {
+		return nil, errors.New("constraint violated")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.ran(query)
+	return &fakeRows{}, nil
+}
+
+type fakeTx fakeConn
+
+func (tx fakeTx) Commit() error   { tx.d.ran("COMMIT"); return nil }
+func (tx fakeTx) Rollback() error { tx.d.ran("ROLLBACK"); return nil }
+
+type fakeRows struct{ done bool }
+
+func (r *fakeRows) Columns() []string { return []string{"data"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = []byte(`{}`)
+	return nil
+}
+
+// newCoordinator returns a coordinator on posts belonging to users
+func newCoordinator(t *testing.T, d *fakeDB, opts ...Option) *Coordinator {
+	t.Helper()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk", "email text notnull").
+		Table("posts", "id pk", "user_id notnull", "title text notnull").
+		FK("posts.user_id", "users.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return New(db, qcode.NewCompiler(s), psql.NewCompiler(s), opts...)
+}
+
+const postWithUser = `mutation { posts(insert: {title: "a", user: {email: "b"}}) { id } }`
+
+func TestMutate(t *testing.T) {
+	d := &fakeDB{}
+	var before, after []Step
+	co := newCoordinator(t, d,
+		WithBefore(func(ctx context.Context, tx *sql.Tx, s Step) error {
+			before = append(before, s)
+			return nil
+		}),
+		WithAfter(func(ctx context.Context, tx *sql.Tx, s Step) error {
+			after = append(after, s)
+			return nil
+		}))
+
+	data, err := co.Mutate(context.Background(), []byte(postWithUser), "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{}` {
+		t.Errorf("got %s", data)
+	}
+
+	// the user is written before the post pointing to it
+	if len(before) != 2 || before[0].Table != "users" || before[1].Table != "posts" || before[0].Rows != 0 {
+		t.Errorf("got before %+v", before)
+	}
+	if len(after) != 2 || after[0].Rows != 1 || after[0].Written == "" || after[0].ParentID != after[1].ID {
+		t.Errorf("got after %+v", after)
+	}
+	for prefix, want := range map[string]int{
+		"CREATE TEMPORARY TABLE": 2,
+		"SAVEPOINT":              2,
+		"RELEASE SAVEPOINT":      2,
+		"ROLLBACK TO SAVEPOINT":  0,
+		"COMMIT":                 1,
+	} {
+		if n := d.count(prefix); n != want {
+			t.Errorf("got %d %s, want %d", n, prefix, want)
+		}
+	}
+}
+
+func TestMutateVeto(t *testing.T) {
+	veto := WithBefore(func(ctx context.Context, tx *sql.Tx, s Step) error {
+		if s.Table == "users" {
+			return errors.New("no new users")
+		}
+		return nil
+	})
+
+	// the first failure rolls back the mutation
+	d := &fakeDB{}
+	_, err := newCoordinator(t, d, veto).Mutate(context.Background(), []byte(postWithUser), "", nil)
+	var me *Error
+	if !errors.As(err, &me) || len(me.Steps) != 1 || me.Steps[0].Step.Table != "users" {
+		t.Fatalf("got error %v", err)
+	}
+	if !strings.Contains(err.Error(), "insert users (write ") || !strings.Contains(err.Error(), "no new users") {
+		t.Errorf("got error %v", err)
+	}
+	if d.count("ROLLBACK TO SAVEPOINT") != 1 || d.count("COMMIT") != 0 {
+		t.Errorf("got statements %q", d.stmts)
+	}
+
+	// the other writes are committed and the post of the user skipped
+	d = &fakeDB{}
+	data, err := newCoordinator(t, d, veto, WithPartial()).Mutate(context.Background(), []byte(postWithUser), "", nil)
+	if !errors.As(err, &me) || len(me.Steps) != 2 || data == nil {
+		t.Fatalf("got %s, %v", data, err)
+	}
+	if !errors.Is(me.Steps[1], ErrSkipped) || me.Steps[1].Step.Table != "posts" {
+		t.Errorf("got step error %v", me.Steps[1])
+	}
+	if d.count("COMMIT") != 1 {
+		t.Errorf("got statements %q", d.stmts)
+	}
+}
+
+func TestMutateError(t *testing.T) {
+	d := &fakeDB{fail: "posts"}
+	_, err := newCoordinator(t, d).Mutate(context.Background(), []byte(postWithUser), "", nil)
+	var me *Error
+	if !errors.As(err, &me) || me.Steps[0].Step.Table != "posts" || me.Steps[0].Step.Rows != 0 {
+		t.Fatalf("got error %v", err)
+	}
+	if d.count("ROLLBACK TO SAVEPOINT") != 1 || d.count("COMMIT") != 0 {
+		t.Errorf("got statements %q", d.stmts)
+	}
+
+	// only mutations run in steps
+	if _, err := newCoordinator(t, &fakeDB{}).Mutate(context.Background(), []byte(`{ users { id } }`), "", nil); err == nil {
+		t.Error("want an error for a query")
+	}
+}
+
+// the settings of a session are set before the writes
+func TestMutateSession(t *testing.T) {
+	d := &fakeDB{}
+	sess := psql.Session{Settings: map[string]string{"app.user_id": "7"}}
+	if _, err := newCoordinator(t, d).MutateSession(context.Background(), sess, []byte(postWithUser), "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.stmts) == 0 || d.stmts[0] != `SELECT set_config($1, $2, true)` {
+		t.Errorf("got statements %q", d.stmts)
+	}
+}
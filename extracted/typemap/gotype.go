@@ -0,0 +1,61 @@
+package typemap
+
+import (
+	"strings"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Go returns the Go type of a column and the package it needs, nullable
+// columns are pointers unless the type can already be nil
+func (m *Mapper) Go(c schema.DBColumn) (string, string) {
+	t := m.Type(Go, c)
+
+	switch {
+	case t.Array:
+		return "[]" + t.Name, t.Import
+	case t.Nullable && !nilable(t.Name):
+		return "*" + t.Name, t.Import
+	}
+	return t.Name, t.Import
+}
+
+// nilable returns true for the Go types that are nil without a pointer
+func nilable(name string) bool {
+	return name == "json.RawMessage" || name == "any" ||
+		strings.HasPrefix(name, "[]") || strings.HasPrefix(name, "*") ||
+		strings.HasPrefix(name, "map[")
+}
+
+// goType returns the Go type of a column
+func goType(c schema.DBColumn) Type {
+	switch Logical(c) {
+	case schema.TypeInt:
+		return Type{Name: intType(c.Type)}
+	case schema.TypeFloat:
+		if n := typeName(c.Type); n == "real" || n == "float4" {
+			return Type{Name: "float32"}
+		}
+		return Type{Name: "float64"}
+	case schema.TypeBoolean:
+		return Type{Name: "bool"}
+	case schema.TypeJSON:
+		return Type{Name: "json.RawMessage", Import: "encoding/json"}
+	case schema.TypeTime, schema.TypeTimestamp, schema.TypeDate:
+		return Type{Name: "time.Time", Import: "time"}
+	case schema.TypeBytes:
+		return Type{Name: "[]byte"}
+	}
+	return Type{Name: "string"}
+}
+
+// intType returns the Go integer type of a database integer type
+func intType(dbType string) string {
+	switch typeName(dbType) {
+	case "smallint", "int2", "tinyint", "smallserial":
+		return "int16"
+	case "integer", "int", "int4", "mediumint", "serial":
+		return "int32"
+	}
+	return "int64"
+}
@@ -0,0 +1,73 @@
+package typemap
+
+import "github.com/yourusername/graphjin-extracted/schema"
+
+// graphqlScalars are the scalars every GraphQL schema has, the others a
+// column is mapped to are declared by the schema
+var graphqlScalars = map[string]struct{}{
+	"Int": {}, "Float": {}, "String": {}, "Boolean": {}, "ID": {},
+}
+
+// BuiltinScalar returns true if a GraphQL type is a built-in scalar
+func BuiltinScalar(name string) bool {
+	_, ok := graphqlScalars[name]
+	return ok
+}
+
+// GraphQL returns the GraphQL type of a column eg. Int!, [String] or ID!
+// for a primary key, the elements of arrays can be null
+func (m *Mapper) GraphQL(c schema.DBColumn) string {
+	t := m.Type(GraphQL, c)
+	return graphqlType(t, !t.Nullable)
+}
+
+// GraphQLInput returns the GraphQL type of a column in an insert input,
+// only the columns an insert must set are non-null
+func (m *Mapper) GraphQLInput(c schema.DBColumn) string {
+	t := m.Type(GraphQL, c)
+	return graphqlType(t, !t.Optional)
+}
+
+// graphqlType returns a type as written in a GraphQL schema
+func graphqlType(t Type, nonNull bool) string {
+	v := t.Name
+	if t.Array {
+		v = "[" + v + "]"
+	}
+	if nonNull {
+		v += "!"
+	}
+	return v
+}
+
+// graphqlScalar returns the GraphQL scalar of a column
+func graphqlScalar(c schema.DBColumn) Type {
+	if c.PrimaryKey && !IsArray(c) {
+		return Type{Name: "ID"}
+	}
+
+	switch Logical(c) {
+	case schema.TypeInt:
+		return Type{Name: "Int"}
+	case schema.TypeFloat:
+		return Type{Name: "Float"}
+	case schema.TypeBoolean:
+		return Type{Name: "Boolean"}
+	case schema.TypeJSON:
+		return Type{Name: "JSON"}
+	case schema.TypeTime:
+		return Type{Name: "Time"}
+	case schema.TypeTimestamp:
+		return Type{Name: "LocalDateTime"}
+	case schema.TypeDate:
+		return Type{Name: "Date"}
+	case schema.TypeTimeOfDay:
+		if c.TimeZone {
+			return Type{Name: "OffsetTime"}
+		}
+		return Type{Name: "LocalTime"}
+	case schema.TypeGeometry:
+		return Type{Name: "Geometry"}
+	}
+	return Type{Name: "String"}
+}
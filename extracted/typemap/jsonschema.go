@@ -0,0 +1,161 @@
+package typemap
+
+import "github.com/yourusername/graphjin-extracted/schema"
+
+// JSONSchema returns the JSON Schema of a column, the type of a nullable
+// column is a list with "null" and a JSON column can hold any value
+func (m *Mapper) JSONSchema(c schema.DBColumn) map[string]any {
+	t := m.Type(JSONSchema, c)
+
+	v := map[string]any{}
+	if t.Name != "" {
+		v["type"] = t.Name
+	}
+	if t.Format != "" {
+		v["format"] = t.Format
+	}
+	if t.Array {
+		v = map[string]any{"type": "array", "items": v}
+	}
+	if t.Nullable {
+		if n, ok := v["type"].(string); ok {
+			v["type"] = []string{n, "null"}
+		}
+	}
+	if c.Comment != "" {
+		v["description"] = c.Comment
+	}
+	if t.ReadOnly {
+		v["readOnly"] = true
+	}
+	return v
+}
+
+// TableSchema returns the JSON Schema of a row of a table, the required
+// properties are the columns an insert must set, the ones that cannot be
+// null and have no default
+func (m *Mapper) TableSchema(ti schema.DBTable) map[string]any {
+	props := make(map[string]any, len(ti.Columns))
+	var required []string
+
+	for _, c := range ti.Columns {
+		if c.Blocked {
+			continue
+		}
+		props[c.Name] = m.JSONSchema(c)
+		if !m.Type(JSONSchema, c).Optional {
+			required = append(required, c.Name)
+		}
+	}
+
+	v := map[string]any{
+		"title":      ti.Name,
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) != 0 {
+		v["required"] = required
+	}
+	if ti.Comment != "" {
+		v["description"] = ti.Comment
+	}
+	return v
+}
+
+// draft is the JSON Schema dialect of the documents
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Document returns a JSON Schema document with the schema of a row of
+// each table in $defs, under the name of the table or schema.table when
+// tables of two schemas share a name
+func (m *Mapper) Document(tables []schema.DBTable) map[string]any {
+	names := make(map[string]int, len(tables))
+	for _, ti := range tables {
+		names[ti.Name]++
+	}
+
+	defs := make(map[string]any, len(tables))
+	for _, ti := range tables {
+		if ti.Blocked {
+			continue
+		}
+		name := ti.Name
+		if names[name] > 1 && ti.Schema != "" {
+			name = ti.Schema + "." + name
+		}
+		defs[name] = m.TableSchema(ti)
+	}
+	return map[string]any{"$schema": draft, "$defs": defs}
+}
+
+// PathSchema returns the JSON Schema of the rows of the first table of a
+// join path with the rows each hop joins to nested in them, under the
+// name of the table joined. The rows of a hop to many rows are a list,
+// a hop to one row is an object that can be null
+//
+//	users: {..., "posts": [{..., "comments": [{...}]}]}
+func (m *Mapper) PathSchema(path []schema.TPath) map[string]any {
+	if len(path) == 0 {
+		return nil
+	}
+
+	v := m.TableSchema(path[0].LT)
+	v["$schema"] = draft
+
+	obj := v
+	for _, p := range path {
+		child := m.TableSchema(p.RT)
+		delete(child, "title")
+
+		props := obj["properties"].(map[string]any)
+		if pathMany(p) {
+			props[p.RT.Name] = map[string]any{"type": "array", "items": child}
+		} else {
+			child["type"] = []string{"object", "null"}
+			props[p.RT.Name] = child
+		}
+		obj = child
+	}
+	return v
+}
+
+// pathMany returns true if a hop of a path joins a row to many rows, the
+// way the compiler decides a nested selection is a list
+func pathMany(p schema.TPath) bool {
+	switch {
+	case p.Rel == schema.RelOneToMany, p.Rel == schema.RelManyToMany:
+		return true
+	case p.LC.Array, p.RC.Array:
+		return true
+	}
+	return false
+}
+
+// jsonSchemaType returns the JSON Schema type and format of a column
+func jsonSchemaType(c schema.DBColumn) Type {
+	switch Logical(c) {
+	case schema.TypeInt:
+		return Type{Name: "integer"}
+	case schema.TypeFloat:
+		return Type{Name: "number"}
+	case schema.TypeBoolean:
+		return Type{Name: "boolean"}
+	case schema.TypeJSON:
+		return Type{}
+	case schema.TypeTime:
+		return Type{Name: "string", Format: "date-time"}
+	case schema.TypeDate:
+		return Type{Name: "string", Format: "date"}
+	case schema.TypeTimeOfDay:
+		// the date-time and time formats require an offset, timestamps
+		// and times without a time zone have none
+		if c.TimeZone {
+			return Type{Name: "string", Format: "time"}
+		}
+	case schema.TypeUUID:
+		return Type{Name: "string", Format: "uuid"}
+	case schema.TypeGeometry:
+		return Type{Name: "object"}
+	}
+	return Type{Name: "string"}
+}
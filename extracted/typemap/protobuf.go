@@ -0,0 +1,56 @@
+package typemap
+
+import "github.com/yourusername/graphjin-extracted/schema"
+
+// Proto returns the Protobuf type of a field holding a column with its
+// label and the .proto file it needs, arrays are repeated and nullable
+// scalars optional. Messages such as google.protobuf.Timestamp already
+// tell a missing value apart
+func (m *Mapper) Proto(c schema.DBColumn) (string, string) {
+	t := m.Type(Protobuf, c)
+
+	switch {
+	case t.Array:
+		return "repeated " + t.Name, t.Import
+	case t.Nullable && protoScalar(t.Name):
+		return "optional " + t.Name, t.Import
+	}
+	return t.Name, t.Import
+}
+
+// protoScalar returns true for the scalar types of Protobuf, the others
+// are messages
+func protoScalar(name string) bool {
+	switch name {
+	case "double", "float", "int32", "int64", "uint32", "uint64", "sint32", "sint64",
+		"fixed32", "fixed64", "sfixed32", "sfixed64", "bool", "string", "bytes":
+		return true
+	}
+	return false
+}
+
+// protoType returns the Protobuf type of a column
+func protoType(c schema.DBColumn) Type {
+	switch Logical(c) {
+	case schema.TypeInt:
+		if intType(c.Type) == "int64" {
+			return Type{Name: "int64"}
+		}
+		return Type{Name: "int32"}
+	case schema.TypeFloat:
+		if n := typeName(c.Type); n == "real" || n == "float4" {
+			return Type{Name: "float"}
+		}
+		return Type{Name: "double"}
+	case schema.TypeBoolean:
+		return Type{Name: "bool"}
+	case schema.TypeJSON:
+		return Type{Name: "google.protobuf.Value", Import: "google/protobuf/struct.proto"}
+	case schema.TypeTime:
+		// a timestamp without a time zone is not an instant, it is a string
+		return Type{Name: "google.protobuf.Timestamp", Import: "google/protobuf/timestamp.proto"}
+	case schema.TypeBytes:
+		return Type{Name: "bytes"}
+	}
+	return Type{Name: "string"}
+}
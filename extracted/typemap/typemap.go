@@ -0,0 +1,162 @@
+// Package typemap maps the columns of a DBInfo to GraphQL, JSON Schema,
+// Go and Protobuf types. The code generators share it so a column is
+// nullable, an array or optional on insert the same way in everything
+// generated from a schema
+package typemap
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// Kind is a type system columns are mapped to
+type Kind int
+
+const (
+	GraphQL Kind = iota
+	JSONSchema
+	Go
+	Protobuf
+)
+
+// Type is the type of a column in a type system. Overrides set the name,
+// format and import, the rest comes from the column
+type Type struct {
+	Name     string // eg. Int, integer or int32, of the elements of an array
+	Format   string // JSON Schema format eg. date-time
+	Import   string // Go package of the type eg. time, or the .proto file of a Protobuf message
+	Array    bool
+	Nullable bool // the column can be null
+	Optional bool // an insert can leave the column out, it is nullable, has a default or is generated
+	ReadOnly bool // the database always sets the column, an identity always or generated column
+}
+
+// Mapper maps columns to types with a table of overrides for database
+// types and columns. It is safe for concurrent use
+type Mapper struct {
+	mu        sync.RWMutex
+	overrides map[key]Type
+}
+
+type key struct {
+	kind Kind
+	name string
+}
+
+// Default is the mapper used by the code generators unless they are
+// given another one
+var Default = New()
+
+// New returns a mapper without overrides
+func New() *Mapper {
+	return &Mapper{overrides: make(map[key]Type)}
+}
+
+// Override maps a database type to a type of a type system, the type can
+// be schema qualified eg. Override(typemap.Go, "uuid", typemap.Type{Name:
+// "uuid.UUID", Import: "github.com/google/uuid"})
+func (m *Mapper) Override(k Kind, dbType string, t Type) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides[key{k, normalizeType(dbType)}] = t
+}
+
+// OverrideColumn maps a column of a table to a type of a type system, it
+// wins over the overrides of database types
+func (m *Mapper) OverrideColumn(k Kind, table, column string, t Type) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides[key{k, table + "." + column}] = t
+}
+
+// Type returns the type of a column in a type system
+func (m *Mapper) Type(k Kind, c schema.DBColumn) Type {
+	t, ok := m.override(k, c)
+	if !ok {
+		t = builtinType(k, c)
+	}
+	t.Array = IsArray(c)
+	t.Nullable = !c.NotNull && !c.PrimaryKey
+	t.Optional = t.Nullable || c.Default != "" || c.AutoGenerated()
+	t.ReadOnly = c.Identity == "always" || c.Generated != ""
+	return t
+}
+
+// builtinType returns the type of a column without overrides
+func builtinType(k Kind, c schema.DBColumn) Type {
+	switch k {
+	case JSONSchema:
+		return jsonSchemaType(c)
+	case Go:
+		return goType(c)
+	case Protobuf:
+		return protoType(c)
+	}
+	return graphqlScalar(c)
+}
+
+// override returns the override of the column or of its type
+func (m *Mapper) override(k Kind, c schema.DBColumn) (Type, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.overrides) == 0 {
+		return Type{}, false
+	}
+	if t, ok := m.overrides[key{k, c.Table + "." + c.Name}]; ok {
+		return t, true
+	}
+	// the type, its unqualified name and its first word eg. "int" of
+	// "int unsigned"
+	n := normalizeType(c.Type)
+	names := []string{n}
+	if i := strings.LastIndexByte(n, '.'); i != -1 {
+		names = append(names, n[i+1:])
+	}
+	if i := strings.IndexByte(n, ' '); i != -1 {
+		names = append(names, n[:i])
+	}
+	for _, name := range names {
+		if t, ok := m.overrides[key{k, name}]; ok {
+			return t, true
+		}
+	}
+	return Type{}, false
+}
+
+// IsArray returns true if the column is an array
+func IsArray(c schema.DBColumn) bool {
+	return c.Array || strings.HasSuffix(strings.TrimSpace(c.Type), "[]")
+}
+
+// Logical returns the logical type of a column
+func Logical(c schema.DBColumn) schema.LogicalType {
+	if c.Logical != "" {
+		return c.Logical
+	}
+	lt, _ := schema.DefaultTypes.Lookup(c.Type)
+	return lt
+}
+
+// normalizeType returns a type name in lower case without its
+// parameters and array suffix
+func normalizeType(dbType string) string {
+	t := strings.ToLower(strings.TrimSpace(dbType))
+	t = strings.TrimPrefix(t, "array of ")
+	if i := strings.IndexAny(t, "(["); i != -1 {
+		t = t[:i]
+	}
+	return strings.TrimSpace(t)
+}
+
+// typeName returns the lowercase name of a database type without its
+// parameters, modifiers or array suffix
+func typeName(dbType string) string {
+	t := strings.ToLower(strings.TrimSpace(dbType))
+	if i := strings.IndexAny(t, "( ["); i != -1 {
+		t = t[:i]
+	}
+	return t
+}
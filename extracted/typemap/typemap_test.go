@@ -0,0 +1,290 @@
+package typemap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/graphjin-extracted/schema"
+)
+
+// orderColumns are columns of every kind of nullability
+var orderColumns = []schema.DBColumn{
+	{Table: "orders", Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true, Identity: "always"},
+	{Table: "orders", Name: "number", Type: "integer", NotNull: true},
+	{Table: "orders", Name: "total", Type: "numeric", NotNull: true, Default: "0"},
+	{Table: "orders", Name: "note", Type: "text"},
+	{Table: "orders", Name: "tags", Type: "text[]", NotNull: true, Array: true},
+	{Table: "orders", Name: "placed_at", Type: "timestamptz"},
+	{Table: "orders", Name: "meta", Type: "jsonb"},
+	{Table: "orders", Name: "total_cents", Type: "bigint", NotNull: true, Generated: "stored"},
+}
+
+func column(name string) schema.DBColumn {
+	for _, c := range orderColumns {
+		if c.Name == name {
+			return c
+		}
+	}
+	panic("no column " + name)
+}
+
+func TestType(t *testing.T) {
+	m := New()
+	tests := []struct {
+		col                          string
+		nullable, optional, readOnly bool
+	}{
+		{"id", false, true, true},
+		{"number", false, false, false},
+		{"total", false, true, false},
+		{"note", true, true, false},
+		{"total_cents", false, true, true},
+	}
+	for _, tt := range tests {
+		got := m.Type(GraphQL, column(tt.col))
+		if got.Nullable != tt.nullable || got.Optional != tt.optional || got.ReadOnly != tt.readOnly {
+			t.Errorf("%s: got %+v", tt.col, got)
+		}
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+	m := New()
+	tests := []struct {
+		col, typ, input string
+	}{
+		{"id", "ID!", "ID"},
+		{"number", "Int!", "Int!"},
+		{"total", "Float!", "Float"},
+		{"note", "String", "String"},
+		{"tags", "[String]!", "[String]!"},
+		{"meta", "JSON", "JSON"},
+	}
+	for _, tt := range tests {
+		c := column(tt.col)
+		if got := m.GraphQL(c); got != tt.typ {
+			t.Errorf("%s: got %s, want %s", tt.col, got, tt.typ)
+		}
+		if got := m.GraphQLInput(c); got != tt.input {
+			t.Errorf("%s: got input %s, want %s", tt.col, got, tt.input)
+		}
+	}
+	if !BuiltinScalar("Int") || BuiltinScalar("JSON") {
+		t.Error("got the built-in scalars wrong")
+	}
+}
+
+func TestGo(t *testing.T) {
+	m := New()
+	tests := []struct {
+		col, typ, imp string
+	}{
+		{"id", "int64", ""},
+		{"number", "int32", ""},
+		{"note", "*string", ""},
+		{"tags", "[]string", ""},
+		{"placed_at", "*time.Time", "time"},
+		{"meta", "json.RawMessage", "encoding/json"},
+	}
+	for _, tt := range tests {
+		typ, imp := m.Go(column(tt.col))
+		if typ != tt.typ || imp != tt.imp {
+			t.Errorf("%s: got %s %q, want %s %q", tt.col, typ, imp, tt.typ, tt.imp)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	m := New()
+	ti := schema.NewDBTable("public", "orders", "", orderColumns)
+	ti.Comment = "orders placed"
+
+	b, err := json.Marshal(m.TableSchema(ti))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"description":"orders placed","properties":{` +
+		`"id":{"readOnly":true,"type":"integer"},` +
+		`"meta":{},` +
+		`"note":{"type":["string","null"]},` +
+		`"number":{"type":"integer"},` +
+		`"placed_at":{"format":"date-time","type":["string","null"]},` +
+		`"tags":{"items":{"type":"string"},"type":"array"},` +
+		`"total":{"type":"number"},` +
+		`"total_cents":{"readOnly":true,"type":"integer"}},` +
+		`"required":["number","tags"],"title":"orders","type":"object"}`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	m := New()
+	m.Override(Go, "uuid", Type{Name: "uuid.UUID", Import: "github.com/google/uuid"})
+	m.Override(Go, "int", Type{Name: "int"})
+	m.Override(GraphQL, "users.email", Type{Name: "wrong"})
+	m.OverrideColumn(GraphQL, "users", "email", Type{Name: "Email"})
+
+	tests := []struct {
+		kind Kind
+		col  schema.DBColumn
+		want string
+	}{
+		// schema qualified, with parameters and by the first word
+		{Go, schema.DBColumn{Table: "users", Name: "id", Type: "public.uuid", NotNull: true}, "uuid.UUID"},
+		{Go, schema.DBColumn{Table: "users", Name: "ref", Type: "UUID(16)"}, "*uuid.UUID"},
+		{Go, schema.DBColumn{Table: "users", Name: "n", Type: "int unsigned", NotNull: true}, "int"},
+		{GraphQL, schema.DBColumn{Table: "users", Name: "email", Type: "text", NotNull: true}, "Email!"},
+		{GraphQL, schema.DBColumn{Table: "posts", Name: "email", Type: "text", NotNull: true}, "String!"},
+	}
+	for _, tt := range tests {
+		var got string
+		if tt.kind == Go {
+			got, _ = m.Go(tt.col)
+		} else {
+			got = m.GraphQL(tt.col)
+		}
+		if got != tt.want {
+			t.Errorf("%s %s: got %s, want %s", tt.col.Name, tt.col.Type, got, tt.want)
+		}
+	}
+
+	// the overrides of a mapper are its own
+	if got, _ := Default.Go(tests[0].col); got != "string" {
+		t.Errorf("got %s with the default mapper", got)
+	}
+}
+
+func TestDocument(t *testing.T) {
+	m := New()
+	users := []schema.DBColumn{{Name: "id", Type: "bigint", NotNull: true, PrimaryKey: true}}
+	secrets := schema.NewDBTable("public", "secrets", "", users)
+	secrets.Blocked = true
+
+	doc := m.Document([]schema.DBTable{
+		schema.NewDBTable("public", "users", "", users),
+		schema.NewDBTable("audit", "users", "", users),
+		schema.NewDBTable("public", "orders", "", orderColumns),
+		secrets,
+	})
+	if doc["$schema"] != draft {
+		t.Errorf("got $schema %v", doc["$schema"])
+	}
+	defs := doc["$defs"].(map[string]any)
+	for _, name := range []string{"public.users", "audit.users", "orders"} {
+		if _, ok := defs[name]; !ok {
+			t.Errorf("no definition of %s", name)
+		}
+	}
+	if len(defs) != 3 {
+		t.Errorf("got %d definitions, want the blocked table left out", len(defs))
+	}
+}
+
+func TestPathSchema(t *testing.T) {
+	m := New()
+	s, err := schema.NewTestSchema().
+		Table("users", "id pk").
+		Table("posts", "id pk", "user_id notnull").
+		Table("comments", "id pk", "post_id notnull", "body text").
+		FK("posts.user_id", "users.id").
+		FK("comments.post_id", "posts.id").
+		BuildSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := s.FindPath("users", "comments", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(m.PathSchema(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"$schema":"` + draft + `","properties":{` +
+		`"id":{"type":"integer"},` +
+		`"posts":{"items":{"properties":{` +
+		`"comments":{"items":{"properties":{` +
+		`"body":{"type":["string","null"]},"id":{"type":"integer"},"post_id":{"type":"integer"}},` +
+		`"required":["id","post_id"],"type":"object"},"type":"array"},` +
+		`"id":{"type":"integer"},"user_id":{"type":"integer"}},` +
+		`"required":["id","user_id"],"type":"object"},"type":"array"}},` +
+		`"required":["id"],"title":"users","type":"object"}`
+	if string(b) != want {
+		t.Errorf("got\n%s\nwant\n%s", b, want)
+	}
+
+	// the comments belong to a single post
+	path, err = s.FindPath("comments", "posts", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := m.PathSchema(path)["properties"].(map[string]any)
+	post := props["posts"].(map[string]any)
+	if typ, ok := post["type"].([]string); !ok || len(typ) != 2 || typ[1] != "null" {
+		t.Errorf("got post type %v, want a nullable object", post["type"])
+	}
+	if _, ok := post["title"]; ok {
+		t.Error("got a title on a nested object")
+	}
+
+	if m.PathSchema(nil) != nil {
+		t.Error("got a schema of an empty path")
+	}
+}
+
+func TestProto(t *testing.T) {
+	m := New()
+	tests := []struct {
+		col, typ, imp string
+	}{
+		{"id", "int64", ""},
+		{"number", "int32", ""},
+		{"total", "double", ""},
+		{"note", "optional string", ""},
+		{"tags", "repeated string", ""},
+		{"placed_at", "google.protobuf.Timestamp", "google/protobuf/timestamp.proto"},
+		{"meta", "google.protobuf.Value", "google/protobuf/struct.proto"},
+	}
+	for _, tt := range tests {
+		typ, imp := m.Proto(column(tt.col))
+		if typ != tt.typ || imp != tt.imp {
+			t.Errorf("%s: got %s %q, want %s %q", tt.col, typ, imp, tt.typ, tt.imp)
+		}
+	}
+
+	m.Override(Protobuf, "uuid", Type{Name: "bytes"})
+	if typ, _ := m.Proto(schema.DBColumn{Name: "ref", Type: "uuid"}); typ != "optional bytes" {
+		t.Errorf("got %s with an override", typ)
+	}
+}
+
+func TestTemporal(t *testing.T) {
+	m := New()
+	tests := []struct {
+		typ, graphql, jsonSchema, goType, proto string
+	}{
+		{"timestamptz", "Time", "string date-time", "time.Time", "google.protobuf.Timestamp"},
+		{"timestamp", "LocalDateTime", "string", "time.Time", "string"},
+		{"date", "Date", "string date", "time.Time", "string"},
+		{"timetz", "OffsetTime", "string time", "string", "string"},
+		{"time", "LocalTime", "string", "string", "string"},
+	}
+	cols := make([]schema.DBColumn, len(tests))
+	for i, tt := range tests {
+		cols[i] = schema.DBColumn{Name: tt.typ, Type: tt.typ, NotNull: true}
+	}
+	ti := schema.NewDBTable("public", "events", "", cols)
+
+	for i, tt := range tests {
+		c := ti.Columns[i]
+		js := m.Type(JSONSchema, c)
+		got := []string{m.Type(GraphQL, c).Name, strings.TrimSpace(js.Name + " " + js.Format), m.Type(Go, c).Name, m.Type(Protobuf, c).Name}
+		want := []string{tt.graphql, tt.jsonSchema, tt.goType, tt.proto}
+		if strings.Join(got, ", ") != strings.Join(want, ", ") {
+			t.Errorf("%s: got %q, want %q", tt.typ, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -22,6 +23,23 @@ func NewGraph() *Graph {
 	return &Graph{edges: make(map[[2]int32][]Edge)}
 }
 
+// Clone returns a copy of the graph, changes to the copy leave the
+// graph as it is
+func (g *Graph) Clone() *Graph {
+	c := &Graph{
+		edgeID: g.edgeID,
+		edges:  make(map[[2]int32][]Edge, len(g.edges)),
+		graph:  make([][]int32, len(g.graph)),
+	}
+	for k, v := range g.edges {
+		c.edges[k] = append([]Edge(nil), v...)
+	}
+	for i, v := range g.graph {
+		c.graph[i] = append([]int32(nil), v...)
+	}
+	return c
+}
+
 // AddNode adds a new node to the graph
 func (g *Graph) AddNode() int32 {
 	id := int32(len(g.graph))
@@ -78,6 +96,29 @@ func (g *Graph) UpdateEdge(
 	return fmt.Errorf("edge not found: %d", edgeID)
 }
 
+// RemoveEdges removes all edges to and from a node, the node is kept
+// so the ids of the other nodes do not change
+func (g *Graph) RemoveEdges(n int32) {
+	if n >= int32(len(g.graph)) {
+		return
+	}
+	for _, c := range g.graph[n] {
+		delete(g.edges, [2]int32{n, c})
+	}
+	g.graph[n] = []int32{}
+
+	for i, conns := range g.graph {
+		for j, c := range conns {
+			if c != n {
+				continue
+			}
+			delete(g.edges, [2]int32{int32(i), n})
+			g.graph[i] = append(conns[:j:j], conns[j+1:]...)
+			break
+		}
+	}
+}
+
 // GetEdges returns all edges between the two nodes
 func (g *Graph) GetEdges(from, to int32) []Edge {
 	return g.edges[[2]int32{from, to}]
@@ -85,8 +126,17 @@ func (g *Graph) GetEdges(from, to int32) []Edge {
 
 // AllPaths returns all paths between two nodes
 func (g *Graph) AllPaths(from, to int32) [][]int32 {
+	paths, _, _ := g.AllPathsLimit(context.Background(), from, to, 0)
+	return paths
+}
+
+// AllPathsLimit is AllPaths leaving out the paths of more than maxHops
+// edges, zero is no limit. It returns true when a path was cut at the
+// limit and the error of the context once the context is done
+func (g *Graph) AllPathsLimit(ctx context.Context, from, to int32, maxHops int) ([][]int32, bool, error) {
 	var paths [][]int32
 	var limit int
+	var cut bool
 
 	h := newHeap()
 	h.push(path{weight: 0, parent: from, nodes: []int32{from}})
@@ -94,7 +144,12 @@ func (g *Graph) AllPaths(from, to int32) [][]int32 {
 
 	for len(*h.paths) > 0 {
 		if limit > 3000 {
-			return paths
+			return paths, cut, nil
+		}
+		if limit%64 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, cut, err
+			}
 		}
 		limit++
 
@@ -109,13 +164,18 @@ func (g *Graph) AllPaths(from, to int32) [][]int32 {
 		if pnode == to && len(p.nodes) > 1 {
 			for _, v := range paths {
 				if equals(v, p.nodes) {
-					return paths
+					return paths, cut, nil
 				}
 			}
 			paths = append(paths, p.nodes)
 			continue
 		}
 
+		if maxHops > 0 && len(p.nodes) > maxHops {
+			cut = cut || len(g.graph[pnode]) != 0
+			continue
+		}
+
 		for _, e := range g.graph[pnode] {
 			if _, ok := p.visited[e]; ok && e != to {
 				continue
@@ -138,7 +198,29 @@ func (g *Graph) AllPaths(from, to int32) [][]int32 {
 			h.push(p1)
 		}
 	}
-	return paths
+	return paths, cut, nil
+}
+
+// Connected returns true if there is a path between two nodes
+func (g *Graph) Connected(from, to int32) bool {
+	seen := map[int32]struct{}{from: {}}
+	queue := []int32{from}
+
+	for len(queue) != 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, e := range g.graph[n] {
+			if e == to {
+				return true
+			}
+			if _, ok := seen[e]; !ok {
+				seen[e] = struct{}{}
+				queue = append(queue, e)
+			}
+		}
+	}
+	return false
 }
 
 // Connections returns all connections for a given node